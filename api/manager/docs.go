@@ -241,6 +241,126 @@ const docTemplate = `{
                 }
             }
         },
+        "/audits": {
+            "get": {
+                "description": "Get Audits of the mutating api calls, the latest audit is the first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Get Audits",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "current page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 50,
+                        "minimum": 2,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "return max item count, default 10, max 50",
+                        "name": "per_page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "user id",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "http method",
+                        "name": "method",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "resource type, e.g. scheduler-clusters",
+                        "name": "resource",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "resource id",
+                        "name": "resource_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.Audit"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/audits/{id}": {
+            "get": {
+                "description": "Get Audit by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Audit"
+                ],
+                "summary": "Get Audit",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.Audit"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
         "/buckets": {
             "get": {
                 "description": "Get Buckets",
@@ -862,6 +982,116 @@ const docTemplate = `{
                 }
             }
         },
+        "/inventory/hosts": {
+            "get": {
+                "description": "Get the active hosts reported by schedulers",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Inventory"
+                ],
+                "summary": "Get Inventory Hosts",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "current page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 50,
+                        "minimum": 2,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "return max item count, default 10, max 50",
+                        "name": "per_page",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.InventoryHost"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/inventory/peers": {
+            "get": {
+                "description": "Get the active peers reported by schedulers",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Inventory"
+                ],
+                "summary": "Get Inventory Peers",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "current page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 50,
+                        "minimum": 2,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "return max item count, default 10, max 50",
+                        "name": "per_page",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.InventoryPeer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
         "/jobs": {
             "get": {
                 "description": "Get Jobs",
@@ -958,6 +1188,49 @@ const docTemplate = `{
                 }
             }
         },
+        "/jobs/bulk-preheat": {
+            "post": {
+                "description": "Create preheat job of the images whose tags in repository match the tag pattern, tag pattern type is glob or regex",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Job"
+                ],
+                "summary": "Create Bulk Preheat Job",
+                "parameters": [
+                    {
+                        "description": "Job",
+                        "name": "Job",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.CreateBulkPreheatJobRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.Job"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
         "/jobs/{id}": {
             "get": {
                 "description": "Get Job by id",
@@ -1569,9 +1842,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/preheats": {
-            "post": {
-                "description": "Create by json config",
+        "/personal-access-tokens": {
+            "get": {
+                "description": "Get PersonalAccessTokens",
                 "consumes": [
                     "application/json"
                 ],
@@ -1579,24 +1852,245 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Preheat"
+                    "PersonalAccessToken"
                 ],
-                "summary": "Create V1 Preheat",
+                "summary": "Get PersonalAccessTokens",
                 "parameters": [
                     {
-                        "description": "Preheat",
-                        "name": "Preheat",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.CreateV1PreheatRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "current page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 50,
+                        "minimum": 2,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "return max item count, default 10, max 50",
+                        "name": "per_page",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.PersonalAccessToken"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "description": "Create by json config, the token is only returned in the response of creation. Type is personal or service, service token is created by root and is only restricted by its scopes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PersonalAccessToken"
+                ],
+                "summary": "Create PersonalAccessToken",
+                "parameters": [
+                    {
+                        "description": "PersonalAccessToken",
+                        "name": "PersonalAccessToken",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.CreatePersonalAccessTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.PersonalAccessToken"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/personal-access-tokens/{id}": {
+            "get": {
+                "description": "Get PersonalAccessToken by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PersonalAccessToken"
+                ],
+                "summary": "Get PersonalAccessToken",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.PersonalAccessToken"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "description": "Destroy by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PersonalAccessToken"
+                ],
+                "summary": "Destroy PersonalAccessToken",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update by json config",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PersonalAccessToken"
+                ],
+                "summary": "Update PersonalAccessToken",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "PersonalAccessToken",
+                        "name": "PersonalAccessToken",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.UpdatePersonalAccessTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.PersonalAccessToken"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/preheats": {
+            "post": {
+                "description": "Create by json config",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Preheat"
+                ],
+                "summary": "Create V1 Preheat",
+                "parameters": [
+                    {
+                        "description": "Preheat",
+                        "name": "Preheat",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.CreateV1PreheatRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
                             "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.CreateV1PreheatResponse"
                         }
                     },
@@ -2089,9 +2583,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/scheduler-clusters/{id}/schedulers/{scheduler_id}": {
-            "put": {
-                "description": "Add Scheduler to schedulerCluster",
+        "/scheduler-clusters/{id}/config-version-reports": {
+            "get": {
+                "description": "Get the configuration versions running in the consumers of cluster, which are reported by schedulers",
                 "consumes": [
                     "application/json"
                 ],
@@ -2099,9 +2593,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "SchedulerCluster"
+                    "ConfigVersion"
                 ],
-                "summary": "Add Scheduler to schedulerCluster",
+                "summary": "Get Config Version Reports",
                 "parameters": [
                     {
                         "type": "string",
@@ -2109,18 +2603,17 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "scheduler id",
-                        "name": "scheduler_id",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.ConfigVersionReport"
+                            }
+                        }
                     },
                     "400": {
                         "description": "Bad Request"
@@ -2134,9 +2627,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/schedulers": {
+        "/scheduler-clusters/{id}/config-versions": {
             "get": {
-                "description": "Get Schedulers",
+                "description": "Get the configuration versions of cluster, the latest version is first",
                 "consumes": [
                     "application/json"
                 ],
@@ -2144,10 +2637,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Scheduler"
+                    "ConfigVersion"
                 ],
-                "summary": "Get Schedulers",
+                "summary": "Get Config Versions",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "integer",
                         "default": 0,
@@ -2173,7 +2673,7 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.Scheduler"
+                                "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.ConfigVersion"
                             }
                         }
                     },
@@ -2187,9 +2687,11 @@ const docTemplate = `{
                         "description": "Internal Server Error"
                     }
                 }
-            },
-            "post": {
-                "description": "Create by json config",
+            }
+        },
+        "/scheduler-clusters/{id}/config-versions/diff": {
+            "get": {
+                "description": "Diff the configurations between two versions of cluster",
                 "consumes": [
                     "application/json"
                 ],
@@ -2197,25 +2699,40 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Scheduler"
+                    "ConfigVersion"
                 ],
-                "summary": "Create Scheduler",
+                "summary": "Diff Config Versions",
                 "parameters": [
                     {
-                        "description": "Scheduler",
-                        "name": "Scheduler",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.CreateSchedulerRequest"
-                        }
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "version diff from",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "version diff to",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.Scheduler"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/d7y_io_dragonfly_v2_pkg_structure.Diff"
+                            }
                         }
                     },
                     "400": {
@@ -2230,9 +2747,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/schedulers/{id}": {
+        "/scheduler-clusters/{id}/config-versions/{version}": {
             "get": {
-                "description": "Get Scheduler by id",
+                "description": "Get the configuration version of cluster",
                 "consumes": [
                     "application/json"
                 ],
@@ -2240,9 +2757,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Scheduler"
+                    "ConfigVersion"
                 ],
-                "summary": "Get Scheduler",
+                "summary": "Get Config Version",
                 "parameters": [
                     {
                         "type": "string",
@@ -2250,13 +2767,20 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "version",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.Scheduler"
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.ConfigVersion"
                         }
                     },
                     "400": {
@@ -2269,9 +2793,11 @@ const docTemplate = `{
                         "description": "Internal Server Error"
                     }
                 }
-            },
-            "delete": {
-                "description": "Destroy by id",
+            }
+        },
+        "/scheduler-clusters/{id}/config-versions/{version}/rollback": {
+            "post": {
+                "description": "Rollback the configuration of cluster to the version, which is recorded as a new version",
                 "consumes": [
                     "application/json"
                 ],
@@ -2279,9 +2805,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Scheduler"
+                    "ConfigVersion"
                 ],
-                "summary": "Destroy Scheduler",
+                "summary": "Rollback Config Version",
                 "parameters": [
                     {
                         "type": "string",
@@ -2289,11 +2815,21 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "version",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.ConfigVersion"
+                        }
                     },
                     "400": {
                         "description": "Bad Request"
@@ -2305,9 +2841,11 @@ const docTemplate = `{
                         "description": "Internal Server Error"
                     }
                 }
-            },
-            "patch": {
-                "description": "Update by json config",
+            }
+        },
+        "/scheduler-clusters/{id}/schedulers/{scheduler_id}": {
+            "put": {
+                "description": "Add Scheduler to schedulerCluster",
                 "consumes": [
                     "application/json"
                 ],
@@ -2315,9 +2853,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Scheduler"
+                    "SchedulerCluster"
                 ],
-                "summary": "Update Scheduler",
+                "summary": "Add Scheduler to schedulerCluster",
                 "parameters": [
                     {
                         "type": "string",
@@ -2327,20 +2865,56 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Scheduler",
-                        "name": "Scheduler",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.UpdateSchedulerRequest"
-                        }
+                        "type": "string",
+                        "description": "scheduler id",
+                        "name": "scheduler_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/scheduler-clusters/{id}/seed-peer-saturation": {
+            "get": {
+                "description": "Get the saturation of seed peers in schedulerCluster, which is reported by schedulers for autoscaling seed peers",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SchedulerCluster"
+                ],
+                "summary": "Get Seed Peer Saturation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.Scheduler"
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.SeedPeerSaturation"
                         }
                     },
                     "400": {
@@ -2355,9 +2929,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/seed-peer-clusters": {
+        "/schedulers": {
             "get": {
-                "description": "Get SeedPeerClusters",
+                "description": "Get Schedulers",
                 "consumes": [
                     "application/json"
                 ],
@@ -2365,9 +2939,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "SeedPeerCluster"
+                    "Scheduler"
                 ],
-                "summary": "Get SeedPeerClusters",
+                "summary": "Get Schedulers",
                 "parameters": [
                     {
                         "type": "integer",
@@ -2394,7 +2968,7 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.SeedPeerCluster"
+                                "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.Scheduler"
                             }
                         }
                     },
@@ -2418,17 +2992,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "SeedPeerCluster"
+                    "Scheduler"
                 ],
-                "summary": "Create SeedPeerCluster",
+                "summary": "Create Scheduler",
                 "parameters": [
                     {
-                        "description": "DNCluster",
-                        "name": "SeedPeerCluster",
+                        "description": "Scheduler",
+                        "name": "Scheduler",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.CreateSeedPeerClusterRequest"
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.CreateSchedulerRequest"
                         }
                     }
                 ],
@@ -2436,7 +3010,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.SeedPeerCluster"
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.Scheduler"
                         }
                     },
                     "400": {
@@ -2451,9 +3025,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/seed-peer-clusters/{id}": {
+        "/schedulers/{id}": {
             "get": {
-                "description": "Get SeedPeerCluster by id",
+                "description": "Get Scheduler by id",
                 "consumes": [
                     "application/json"
                 ],
@@ -2461,9 +3035,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "SeedPeerCluster"
+                    "Scheduler"
                 ],
-                "summary": "Get SeedPeerCluster",
+                "summary": "Get Scheduler",
                 "parameters": [
                     {
                         "type": "string",
@@ -2477,7 +3051,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.SeedPeerCluster"
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.Scheduler"
                         }
                     },
                     "400": {
@@ -2500,9 +3074,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "SeedPeerCluster"
+                    "Scheduler"
                 ],
-                "summary": "Destroy SeedPeerCluster",
+                "summary": "Destroy Scheduler",
                 "parameters": [
                     {
                         "type": "string",
@@ -2536,9 +3110,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "SeedPeerCluster"
+                    "Scheduler"
                 ],
-                "summary": "Update SeedPeerCluster",
+                "summary": "Update Scheduler",
                 "parameters": [
                     {
                         "type": "string",
@@ -2548,12 +3122,12 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "SeedPeerCluster",
-                        "name": "SeedPeerCluster",
+                        "description": "Scheduler",
+                        "name": "Scheduler",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.UpdateSeedPeerClusterRequest"
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.UpdateSchedulerRequest"
                         }
                     }
                 ],
@@ -2561,7 +3135,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.SeedPeerCluster"
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.Scheduler"
                         }
                     },
                     "400": {
@@ -2576,9 +3150,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/seed-peer-clusters/{id}/scheduler-clusters/{scheduler_cluster_id}": {
-            "put": {
-                "description": "Add SchedulerCluster to SeedPeerCluster",
+        "/searcher-rules": {
+            "get": {
+                "description": "Get SearcherRules",
                 "consumes": [
                     "application/json"
                 ],
@@ -2586,28 +3160,38 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "SeedPeerCluster"
+                    "SearcherRule"
                 ],
-                "summary": "Add SchedulerCluster to SeedPeerCluster",
+                "summary": "Get SearcherRules",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "id",
-                        "name": "id",
-                        "in": "path",
+                        "type": "integer",
+                        "default": 0,
+                        "description": "current page",
+                        "name": "page",
+                        "in": "query",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "scheduler cluster id",
-                        "name": "scheduler_cluster_id",
-                        "in": "path",
+                        "maximum": 50,
+                        "minimum": 2,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "return max item count, default 10, max 50",
+                        "name": "per_page",
+                        "in": "query",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.SearcherRule"
+                            }
+                        }
                     },
                     "400": {
                         "description": "Bad Request"
@@ -2619,11 +3203,9 @@ const docTemplate = `{
                         "description": "Internal Server Error"
                     }
                 }
-            }
-        },
-        "/seed-peer-clusters/{id}/seed-peers/{seed_peer_id}": {
-            "put": {
-                "description": "Add SeedPeer to SeedPeerCluster",
+            },
+            "post": {
+                "description": "Create by json config, the dfdaemon matching the cidrs and labels of rule prefers the scheduler cluster of rule, the rule of larger weight takes precedence",
                 "consumes": [
                     "application/json"
                 ],
@@ -2631,28 +3213,26 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "SeedPeerCluster"
+                    "SearcherRule"
                 ],
-                "summary": "Add Instance to SeedPeerCluster",
+                "summary": "Create SearcherRule",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "id",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "seed peer id",
-                        "name": "seed_peer_id",
-                        "in": "path",
-                        "required": true
+                        "description": "SearcherRule",
+                        "name": "SearcherRule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.CreateSearcherRuleRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.SearcherRule"
+                        }
                     },
                     "400": {
                         "description": "Bad Request"
@@ -2666,9 +3246,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/seed-peers": {
+        "/searcher-rules/{id}": {
             "get": {
-                "description": "Get SeedPeers",
+                "description": "Get SearcherRule by id",
                 "consumes": [
                     "application/json"
                 ],
@@ -2676,26 +3256,15 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "SeedPeer"
+                    "SearcherRule"
                 ],
-                "summary": "Get SeedPeers",
+                "summary": "Get SearcherRule",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "default": 0,
-                        "description": "current page",
-                        "name": "page",
-                        "in": "query",
-                        "required": true
-                    },
-                    {
-                        "maximum": 50,
-                        "minimum": 2,
-                        "type": "integer",
-                        "default": 10,
-                        "description": "return max item count, default 10, max 50",
-                        "name": "per_page",
-                        "in": "query",
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
                         "required": true
                     }
                 ],
@@ -2703,10 +3272,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.SeedPeer"
-                            }
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.SearcherRule"
                         }
                     },
                     "400": {
@@ -2720,8 +3286,8 @@ const docTemplate = `{
                     }
                 }
             },
-            "post": {
-                "description": "Create by json config",
+            "delete": {
+                "description": "Destroy by id",
                 "consumes": [
                     "application/json"
                 ],
@@ -2729,17 +3295,948 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "SeedPeer"
+                    "SearcherRule"
                 ],
-                "summary": "Create SeedPeer",
+                "summary": "Destroy SearcherRule",
                 "parameters": [
                     {
-                        "description": "SeedPeer",
-                        "name": "SeedPeer",
-                        "in": "body",
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update by json config",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SearcherRule"
+                ],
+                "summary": "Update SearcherRule",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "SearcherRule",
+                        "name": "SearcherRule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.UpdateSearcherRuleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.SearcherRule"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/seed-peer-clusters": {
+            "get": {
+                "description": "Get SeedPeerClusters",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SeedPeerCluster"
+                ],
+                "summary": "Get SeedPeerClusters",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "current page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 50,
+                        "minimum": 2,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "return max item count, default 10, max 50",
+                        "name": "per_page",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.SeedPeerCluster"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "description": "Create by json config",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SeedPeerCluster"
+                ],
+                "summary": "Create SeedPeerCluster",
+                "parameters": [
+                    {
+                        "description": "DNCluster",
+                        "name": "SeedPeerCluster",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.CreateSeedPeerClusterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.SeedPeerCluster"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/seed-peer-clusters/{id}": {
+            "get": {
+                "description": "Get SeedPeerCluster by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SeedPeerCluster"
+                ],
+                "summary": "Get SeedPeerCluster",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.SeedPeerCluster"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "description": "Destroy by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SeedPeerCluster"
+                ],
+                "summary": "Destroy SeedPeerCluster",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update by json config",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SeedPeerCluster"
+                ],
+                "summary": "Update SeedPeerCluster",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "SeedPeerCluster",
+                        "name": "SeedPeerCluster",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.UpdateSeedPeerClusterRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.SeedPeerCluster"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/seed-peer-clusters/{id}/config-version-reports": {
+            "get": {
+                "description": "Get the configuration versions running in the consumers of cluster, which are reported by schedulers",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ConfigVersion"
+                ],
+                "summary": "Get Config Version Reports",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.ConfigVersionReport"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/seed-peer-clusters/{id}/config-versions": {
+            "get": {
+                "description": "Get the configuration versions of cluster, the latest version is first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ConfigVersion"
+                ],
+                "summary": "Get Config Versions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "current page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 50,
+                        "minimum": 2,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "return max item count, default 10, max 50",
+                        "name": "per_page",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.ConfigVersion"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/seed-peer-clusters/{id}/config-versions/diff": {
+            "get": {
+                "description": "Diff the configurations between two versions of cluster",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ConfigVersion"
+                ],
+                "summary": "Diff Config Versions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "version diff from",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "version diff to",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/d7y_io_dragonfly_v2_pkg_structure.Diff"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/seed-peer-clusters/{id}/config-versions/{version}": {
+            "get": {
+                "description": "Get the configuration version of cluster",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ConfigVersion"
+                ],
+                "summary": "Get Config Version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "version",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.ConfigVersion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/seed-peer-clusters/{id}/config-versions/{version}/rollback": {
+            "post": {
+                "description": "Rollback the configuration of cluster to the version, which is recorded as a new version",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "ConfigVersion"
+                ],
+                "summary": "Rollback Config Version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "version",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.ConfigVersion"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/seed-peer-clusters/{id}/scheduler-clusters/{scheduler_cluster_id}": {
+            "put": {
+                "description": "Add SchedulerCluster to SeedPeerCluster",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SeedPeerCluster"
+                ],
+                "summary": "Add SchedulerCluster to SeedPeerCluster",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "scheduler cluster id",
+                        "name": "scheduler_cluster_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/seed-peer-clusters/{id}/seed-peers/{seed_peer_id}": {
+            "put": {
+                "description": "Add SeedPeer to SeedPeerCluster",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SeedPeerCluster"
+                ],
+                "summary": "Add Instance to SeedPeerCluster",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "seed peer id",
+                        "name": "seed_peer_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/seed-peers": {
+            "get": {
+                "description": "Get SeedPeers",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SeedPeer"
+                ],
+                "summary": "Get SeedPeers",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "current page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 50,
+                        "minimum": 2,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "return max item count, default 10, max 50",
+                        "name": "per_page",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.SeedPeer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "description": "Create by json config",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SeedPeer"
+                ],
+                "summary": "Create SeedPeer",
+                "parameters": [
+                    {
+                        "description": "SeedPeer",
+                        "name": "SeedPeer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.CreateSeedPeerRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.SeedPeer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/seed-peers/{id}": {
+            "get": {
+                "description": "Get SeedPeer by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SeedPeer"
+                ],
+                "summary": "Get SeedPeer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.SeedPeer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "description": "Destroy by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SeedPeer"
+                ],
+                "summary": "Destroy SeedPeer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update by json config",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "SeedPeer"
+                ],
+                "summary": "Update SeedPeer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "SeedPeer",
+                        "name": "SeedPeer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.UpdateSeedPeerRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.SeedPeer"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/tenants": {
+            "get": {
+                "description": "Get Tenants",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tenant"
+                ],
+                "summary": "Get Tenants",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "current page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 50,
+                        "minimum": 2,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "return max item count, default 10, max 50",
+                        "name": "per_page",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.Tenant"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "description": "Create by json config, the scheduler clusters, seed peer clusters, preheat jobs and tokens are scoped to tenant",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tenant"
+                ],
+                "summary": "Create Tenant",
+                "parameters": [
+                    {
+                        "description": "Tenant",
+                        "name": "Tenant",
+                        "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.CreateSeedPeerRequest"
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.CreateTenantRequest"
                         }
                     }
                 ],
@@ -2747,7 +4244,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.SeedPeer"
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.Tenant"
                         }
                     },
                     "400": {
@@ -2762,9 +4259,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/seed-peers/{id}": {
+        "/tenants/{id}": {
             "get": {
-                "description": "Get SeedPeer by id",
+                "description": "Get Tenant by id",
                 "consumes": [
                     "application/json"
                 ],
@@ -2772,9 +4269,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "SeedPeer"
+                    "Tenant"
                 ],
-                "summary": "Get SeedPeer",
+                "summary": "Get Tenant",
                 "parameters": [
                     {
                         "type": "string",
@@ -2788,7 +4285,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.SeedPeer"
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.Tenant"
                         }
                     },
                     "400": {
@@ -2803,7 +4300,7 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Destroy by id",
+                "description": "Destroy by id, the tenant with clusters is not destroyed",
                 "consumes": [
                     "application/json"
                 ],
@@ -2811,9 +4308,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "SeedPeer"
+                    "Tenant"
                 ],
-                "summary": "Destroy SeedPeer",
+                "summary": "Destroy Tenant",
                 "parameters": [
                     {
                         "type": "string",
@@ -2847,9 +4344,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "SeedPeer"
+                    "Tenant"
                 ],
-                "summary": "Update SeedPeer",
+                "summary": "Update Tenant",
                 "parameters": [
                     {
                         "type": "string",
@@ -2859,12 +4356,12 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "SeedPeer",
-                        "name": "SeedPeer",
+                        "description": "Tenant",
+                        "name": "Tenant",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.UpdateSeedPeerRequest"
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.UpdateTenantRequest"
                         }
                     }
                 ],
@@ -2872,7 +4369,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.SeedPeer"
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.Tenant"
                         }
                     },
                     "400": {
@@ -3301,11 +4798,232 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/webhooks": {
+            "get": {
+                "description": "Get Webhooks",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "Get Webhooks",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 0,
+                        "description": "current page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "maximum": 50,
+                        "minimum": 2,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "return max item count, default 10, max 50",
+                        "name": "per_page",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.Webhook"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "post": {
+                "description": "Create by json config, the final states of jobs are posted to the url with the payload signed by secret",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "Create Webhook",
+                "parameters": [
+                    {
+                        "description": "Webhook",
+                        "name": "Webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.CreateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/webhooks/{id}": {
+            "get": {
+                "description": "Get Webhook by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "Get Webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "delete": {
+                "description": "Destroy by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "Destroy Webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "404": {
+                        "description": "Not Found"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            },
+            "patch": {
+                "description": "Update by json config",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Webhook"
+                ],
+                "summary": "Update Webhook",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Webhook",
+                        "name": "Webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.UpdateWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.Webhook"
+                        }
                     },
                     "400": {
                         "description": "Bad Request"
                     },
+                    "404": {
+                        "description": "Not Found"
+                    },
                     "500": {
                         "description": "Internal Server Error"
                     }
@@ -3346,6 +5064,50 @@ const docTemplate = `{
                 }
             }
         },
+        "d7y_io_dragonfly_v2_manager_models.Audit": {
+            "type": "object",
+            "properties": {
+                "after_state": {
+                    "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.JSONMap"
+                },
+                "before_state": {
+                    "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.JSONMap"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "method": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "request_body": {
+                    "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.JSONMap"
+                },
+                "resource": {
+                    "type": "string"
+                },
+                "resource_id": {
+                    "type": "integer"
+                },
+                "status_code": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
         "d7y_io_dragonfly_v2_manager_models.Config": {
             "type": "object",
             "properties": {
@@ -3372,6 +5134,38 @@ const docTemplate = `{
                 }
             }
         },
+        "d7y_io_dragonfly_v2_manager_models.ConfigVersion": {
+            "type": "object",
+            "properties": {
+                "cluster_id": {
+                    "type": "integer"
+                },
+                "cluster_type": {
+                    "type": "string"
+                },
+                "config": {
+                    "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.JSONMap"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "digest": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "rollback_version": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
         "d7y_io_dragonfly_v2_manager_models.JSONMap": {
             "type": "object",
             "additionalProperties": {}
@@ -3412,6 +5206,9 @@ const docTemplate = `{
                 "task_id": {
                     "type": "string"
                 },
+                "tenant_id": {
+                    "type": "integer"
+                },
                 "type": {
                     "type": "string"
                 },
@@ -3470,9 +5267,18 @@ const docTemplate = `{
                 "created_at": {
                     "type": "string"
                 },
+                "group_roles": {
+                    "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.JSONMap"
+                },
+                "groups_claim": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
+                "issuer_url": {
+                    "type": "string"
+                },
                 "name": {
                     "type": "string"
                 },
@@ -3484,12 +5290,68 @@ const docTemplate = `{
                 }
             }
         },
+        "d7y_io_dragonfly_v2_manager_models.PersonalAccessToken": {
+            "type": "object",
+            "properties": {
+                "bio": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "expired_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scheduler_clusters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.SchedulerCluster"
+                    }
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "state": {
+                    "type": "string"
+                },
+                "tenant_id": {
+                    "type": "integer"
+                },
+                "token": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
         "d7y_io_dragonfly_v2_manager_models.Scheduler": {
             "type": "object",
             "properties": {
+                "cpu_count": {
+                    "type": "integer"
+                },
                 "created_at": {
                     "type": "string"
                 },
+                "disk_free": {
+                    "type": "integer"
+                },
                 "features": {
                     "type": "array",
                     "items": {
@@ -3508,9 +5370,18 @@ const docTemplate = `{
                 "ip": {
                     "type": "string"
                 },
+                "ipv4": {
+                    "type": "string"
+                },
+                "ipv6": {
+                    "type": "string"
+                },
                 "location": {
                     "type": "string"
                 },
+                "memory_total": {
+                    "type": "integer"
+                },
                 "port": {
                     "type": "integer"
                 },
@@ -3520,6 +5391,24 @@ const docTemplate = `{
                 "state": {
                     "type": "string"
                 },
+                "train_budget_limited": {
+                    "type": "boolean"
+                },
+                "train_download_bytes": {
+                    "type": "integer"
+                },
+                "train_download_succeeded": {
+                    "type": "boolean"
+                },
+                "train_network_topology_bytes": {
+                    "type": "integer"
+                },
+                "train_network_topology_succeeded": {
+                    "type": "boolean"
+                },
+                "trained_at": {
+                    "type": "string"
+                },
                 "updated_at": {
                     "type": "string"
                 }
@@ -3564,8 +5453,46 @@ const docTemplate = `{
                         "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.SeedPeerCluster"
                     }
                 },
+                "tenant_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "d7y_io_dragonfly_v2_manager_models.SearcherRule": {
+            "type": "object",
+            "properties": {
+                "bio": {
+                    "type": "string"
+                },
+                "cidrs": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "labels": {
+                    "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.JSONMap"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scheduler_cluster_id": {
+                    "type": "integer"
+                },
                 "updated_at": {
                     "type": "string"
+                },
+                "weight": {
+                    "type": "integer"
                 }
             }
         },
@@ -3643,6 +5570,50 @@ const docTemplate = `{
                         "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.SchedulerCluster"
                     }
                 },
+                "tenant_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "d7y_io_dragonfly_v2_manager_models.Tenant": {
+            "type": "object",
+            "properties": {
+                "bio": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "preheat_bandwidth_quota": {
+                    "type": "integer"
+                },
+                "preheat_job_quota": {
+                    "type": "integer"
+                },
+                "scheduler_clusters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.SchedulerCluster"
+                    }
+                },
+                "seed_peer_clusters": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_models.SeedPeerCluster"
+                    }
+                },
+                "state": {
+                    "type": "string"
+                },
                 "updated_at": {
                     "type": "string"
                 }
@@ -3683,6 +5654,47 @@ const docTemplate = `{
                 }
             }
         },
+        "d7y_io_dragonfly_v2_manager_models.Webhook": {
+            "type": "object",
+            "properties": {
+                "bio": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "job_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
         "d7y_io_dragonfly_v2_manager_permission_rbac.Permission": {
             "type": "object",
             "required": [
@@ -3721,6 +5733,72 @@ const docTemplate = `{
                 }
             }
         },
+        "d7y_io_dragonfly_v2_manager_types.BulkPreheatArgs": {
+            "type": "object",
+            "required": [
+                "repository",
+                "tag_pattern"
+            ],
+            "properties": {
+                "filter": {
+                    "type": "string"
+                },
+                "headers": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "max_bandwidth": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "repository": {
+                    "type": "string"
+                },
+                "tag": {
+                    "type": "string"
+                },
+                "tag_pattern": {
+                    "type": "string"
+                },
+                "tag_pattern_type": {
+                    "type": "string"
+                },
+                "time_window": {
+                    "type": "string"
+                }
+            }
+        },
+        "d7y_io_dragonfly_v2_manager_types.ConfigVersionReport": {
+            "type": "object",
+            "properties": {
+                "digest": {
+                    "description": "Digest is the digest of configuration running in consumer.",
+                    "type": "string"
+                },
+                "drifted": {
+                    "description": "Drifted is whether the configuration running in consumer differs from the current configuration of cluster.",
+                    "type": "boolean"
+                },
+                "hostname": {
+                    "description": "Hostname is the hostname of consumer.",
+                    "type": "string"
+                },
+                "ip": {
+                    "description": "IP is the ip of consumer.",
+                    "type": "string"
+                },
+                "updated_at": {
+                    "description": "UpdatedAt is the time of reporting.",
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version is the version of configuration running in consumer, it is zero if the digest is unknown.",
+                    "type": "integer"
+                }
+            }
+        },
         "d7y_io_dragonfly_v2_manager_types.CreateApplicationRequest": {
             "type": "object",
             "required": [
@@ -3758,6 +5836,32 @@ const docTemplate = `{
                 }
             }
         },
+        "d7y_io_dragonfly_v2_manager_types.CreateBulkPreheatJobRequest": {
+            "type": "object",
+            "required": [
+                "args"
+            ],
+            "properties": {
+                "args": {
+                    "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.BulkPreheatArgs"
+                },
+                "bio": {
+                    "type": "string"
+                },
+                "scheduler_cluster_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "tenant_id": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
         "d7y_io_dragonfly_v2_manager_types.CreateClusterRequest": {
             "type": "object",
             "required": [
@@ -3933,11 +6037,24 @@ const docTemplate = `{
                 "client_secret": {
                     "type": "string"
                 },
+                "group_roles": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "groups_claim": {
+                    "type": "string"
+                },
+                "issuer_url": {
+                    "type": "string"
+                },
                 "name": {
                     "type": "string",
                     "enum": [
                         "github",
-                        "google"
+                        "google",
+                        "oidc"
                     ]
                 },
                 "redirect_url": {
@@ -3945,6 +6062,47 @@ const docTemplate = `{
                 }
             }
         },
+        "d7y_io_dragonfly_v2_manager_types.CreatePersonalAccessTokenRequest": {
+            "type": "object",
+            "required": [
+                "expired_at",
+                "name",
+                "scopes"
+            ],
+            "properties": {
+                "bio": {
+                    "type": "string"
+                },
+                "expired_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scheduler_cluster_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "tenant_id": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "personal",
+                        "service"
+                    ]
+                }
+            }
+        },
         "d7y_io_dragonfly_v2_manager_types.CreateRoleRequest": {
             "type": "object",
             "required": [
@@ -3991,6 +6149,9 @@ const docTemplate = `{
                 },
                 "seed_peer_cluster_id": {
                     "type": "integer"
+                },
+                "tenant_id": {
+                    "type": "integer"
                 }
             }
         },
@@ -4029,6 +6190,41 @@ const docTemplate = `{
                 }
             }
         },
+        "d7y_io_dragonfly_v2_manager_types.CreateSearcherRuleRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "scheduler_cluster_id"
+            ],
+            "properties": {
+                "bio": {
+                    "type": "string"
+                },
+                "cidrs": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "scheduler_cluster_id": {
+                    "type": "integer"
+                },
+                "weight": {
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 1
+                }
+            }
+        },
         "d7y_io_dragonfly_v2_manager_types.CreateSeedPeerClusterRequest": {
             "type": "object",
             "required": [
@@ -4044,6 +6240,9 @@ const docTemplate = `{
                 },
                 "name": {
                     "type": "string"
+                },
+                "tenant_id": {
+                    "type": "integer"
                 }
             }
         },
@@ -4092,6 +6291,28 @@ const docTemplate = `{
                 }
             }
         },
+        "d7y_io_dragonfly_v2_manager_types.CreateTenantRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "bio": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "preheat_bandwidth_quota": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "preheat_job_quota": {
+                    "type": "integer",
+                    "minimum": 0
+                }
+            }
+        },
         "d7y_io_dragonfly_v2_manager_types.CreateV1PreheatRequest": {
             "type": "object",
             "required": [
@@ -4128,6 +6349,45 @@ const docTemplate = `{
                 }
             }
         },
+        "d7y_io_dragonfly_v2_manager_types.CreateWebhookRequest": {
+            "type": "object",
+            "required": [
+                "name",
+                "secret",
+                "url",
+                "user_id"
+            ],
+            "properties": {
+                "bio": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "job_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "secret": {
+                    "type": "string",
+                    "minLength": 16
+                },
+                "url": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
         "d7y_io_dragonfly_v2_manager_types.DeletePermissionForRoleRequest": {
             "type": "object",
             "required": [
@@ -4142,65 +6402,159 @@ const docTemplate = `{
                         "*"
                     ]
                 },
-                "object": {
+                "object": {
+                    "type": "string"
+                }
+            }
+        },
+        "d7y_io_dragonfly_v2_manager_types.GetClusterResponse": {
+            "type": "object",
+            "properties": {
+                "bio": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "isDefault": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "peerClusterConfig": {
+                    "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.SchedulerClusterClientConfig"
+                },
+                "schedulerClusterConfig": {
+                    "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.SchedulerClusterConfig"
+                },
+                "schedulerClusterID": {
+                    "type": "integer"
+                },
+                "scopes": {
+                    "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.SchedulerClusterScopes"
+                },
+                "seedPeerClusterConfig": {
+                    "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.SeedPeerClusterConfig"
+                },
+                "seedPeerClusterID": {
+                    "type": "integer"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "d7y_io_dragonfly_v2_manager_types.GetV1PreheatResponse": {
+            "type": "object",
+            "properties": {
+                "finishTime": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "startTime": {
+                    "type": "string"
+                },
+                "status": {
                     "type": "string"
                 }
             }
         },
-        "d7y_io_dragonfly_v2_manager_types.GetClusterResponse": {
+        "d7y_io_dragonfly_v2_manager_types.InventoryHost": {
             "type": "object",
             "properties": {
-                "bio": {
+                "concurrent_upload_count": {
+                    "type": "integer"
+                },
+                "created_at": {
                     "type": "string"
                 },
-                "createdAt": {
+                "download_port": {
+                    "type": "integer"
+                },
+                "health": {
+                    "type": "string"
+                },
+                "hostname": {
                     "type": "string"
                 },
                 "id": {
-                    "type": "integer"
+                    "type": "string"
                 },
-                "isDefault": {
-                    "type": "boolean"
+                "idc": {
+                    "type": "string"
                 },
-                "name": {
+                "ip": {
                     "type": "string"
                 },
-                "peerClusterConfig": {
-                    "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.SchedulerClusterClientConfig"
+                "location": {
+                    "type": "string"
                 },
-                "schedulerClusterConfig": {
-                    "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.SchedulerClusterConfig"
+                "peer_count": {
+                    "type": "integer"
                 },
-                "schedulerClusterID": {
+                "port": {
                     "type": "integer"
                 },
-                "scopes": {
-                    "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.SchedulerClusterScopes"
+                "scheduler_cluster_id": {
+                    "type": "integer"
                 },
-                "seedPeerClusterConfig": {
-                    "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.SeedPeerClusterConfig"
+                "type": {
+                    "type": "string"
                 },
-                "seedPeerClusterID": {
+                "updated_at": {
+                    "type": "string"
+                },
+                "upload_count": {
                     "type": "integer"
                 },
-                "updatedAt": {
-                    "type": "string"
+                "upload_failed_count": {
+                    "type": "integer"
                 }
             }
         },
-        "d7y_io_dragonfly_v2_manager_types.GetV1PreheatResponse": {
+        "d7y_io_dragonfly_v2_manager_types.InventoryPeer": {
             "type": "object",
             "properties": {
-                "finishTime": {
+                "created_at": {
+                    "type": "string"
+                },
+                "finished_piece_count": {
+                    "type": "integer"
+                },
+                "health": {
+                    "type": "string"
+                },
+                "host_id": {
+                    "type": "string"
+                },
+                "hostname": {
                     "type": "string"
                 },
                 "id": {
                     "type": "string"
                 },
-                "startTime": {
+                "idc": {
                     "type": "string"
                 },
-                "status": {
+                "ip": {
+                    "type": "string"
+                },
+                "scheduler_cluster_id": {
+                    "type": "integer"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "task_id": {
+                    "type": "string"
+                },
+                "updated_at": {
                     "type": "string"
                 }
             }
@@ -4283,12 +6637,28 @@ const docTemplate = `{
                     "type": "integer",
                     "maximum": 2000,
                     "minimum": 1
+                },
+                "probe_protocol": {
+                    "type": "string",
+                    "enum": [
+                        "icmp",
+                        "tcp",
+                        "quic"
+                    ]
+                },
+                "quic": {
+                    "type": "boolean"
                 }
             }
         },
         "d7y_io_dragonfly_v2_manager_types.SchedulerClusterConfig": {
             "type": "object",
             "properties": {
+                "back_to_source_count": {
+                    "type": "integer",
+                    "maximum": 1000,
+                    "minimum": 1
+                },
                 "candidate_parent_limit": {
                     "type": "integer",
                     "maximum": 20,
@@ -4298,6 +6668,40 @@ const docTemplate = `{
                     "type": "integer",
                     "maximum": 1000,
                     "minimum": 10
+                },
+                "gc": {
+                    "$ref": "#/definitions/d7y_io_dragonfly_v2_manager_types.SchedulerClusterGCConfig"
+                },
+                "retry_back_to_source_limit": {
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 1
+                },
+                "retry_limit": {
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 1
+                }
+            }
+        },
+        "d7y_io_dragonfly_v2_manager_types.SchedulerClusterGCConfig": {
+            "type": "object",
+            "properties": {
+                "host_ttl": {
+                    "type": "integer",
+                    "minimum": 60
+                },
+                "max_task_count": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "peer_ttl": {
+                    "type": "integer",
+                    "minimum": 60
+                },
+                "task_ttl": {
+                    "type": "integer",
+                    "minimum": 60
                 }
             }
         },
@@ -4318,6 +6722,41 @@ const docTemplate = `{
                 }
             }
         },
+        "d7y_io_dragonfly_v2_manager_types.SeedPeerSaturation": {
+            "type": "object",
+            "properties": {
+                "active_task_count": {
+                    "type": "integer"
+                },
+                "back_to_source_peer_count": {
+                    "type": "integer"
+                },
+                "back_to_source_rate": {
+                    "type": "number"
+                },
+                "concurrent_upload_count": {
+                    "type": "integer"
+                },
+                "concurrent_upload_limit": {
+                    "type": "integer"
+                },
+                "peer_count": {
+                    "type": "integer"
+                },
+                "saturation": {
+                    "type": "number"
+                },
+                "scheduler_count": {
+                    "type": "integer"
+                },
+                "seed_peer_count": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
         "d7y_io_dragonfly_v2_manager_types.SeedPeerClusterConfig": {
             "type": "object",
             "properties": {
@@ -4531,11 +6970,24 @@ const docTemplate = `{
                 "client_secret": {
                     "type": "string"
                 },
+                "group_roles": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "groups_claim": {
+                    "type": "string"
+                },
+                "issuer_url": {
+                    "type": "string"
+                },
                 "name": {
                     "type": "string",
                     "enum": [
                         "github",
-                        "google"
+                        "google",
+                        "oidc"
                     ]
                 },
                 "redirect_url": {
@@ -4543,6 +6995,36 @@ const docTemplate = `{
                 }
             }
         },
+        "d7y_io_dragonfly_v2_manager_types.UpdatePersonalAccessTokenRequest": {
+            "type": "object",
+            "properties": {
+                "bio": {
+                    "type": "string"
+                },
+                "expired_at": {
+                    "type": "string"
+                },
+                "scheduler_cluster_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "scopes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "state": {
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "inactive"
+                    ]
+                }
+            }
+        },
         "d7y_io_dragonfly_v2_manager_types.UpdateSchedulerClusterRequest": {
             "type": "object",
             "properties": {
@@ -4566,6 +7048,9 @@ const docTemplate = `{
                 },
                 "seed_peer_cluster_id": {
                     "type": "integer"
+                },
+                "tenant_id": {
+                    "type": "integer"
                 }
             }
         },
@@ -4598,6 +7083,34 @@ const docTemplate = `{
                 }
             }
         },
+        "d7y_io_dragonfly_v2_manager_types.UpdateSearcherRuleRequest": {
+            "type": "object",
+            "properties": {
+                "bio": {
+                    "type": "string"
+                },
+                "cidrs": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "scheduler_cluster_id": {
+                    "type": "integer"
+                },
+                "weight": {
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 1
+                }
+            }
+        },
         "d7y_io_dragonfly_v2_manager_types.UpdateSeedPeerClusterRequest": {
             "type": "object",
             "properties": {
@@ -4609,6 +7122,9 @@ const docTemplate = `{
                 },
                 "name": {
                     "type": "string"
+                },
+                "tenant_id": {
+                    "type": "integer"
                 }
             }
         },
@@ -4646,6 +7162,29 @@ const docTemplate = `{
                 }
             }
         },
+        "d7y_io_dragonfly_v2_manager_types.UpdateTenantRequest": {
+            "type": "object",
+            "properties": {
+                "bio": {
+                    "type": "string"
+                },
+                "preheat_bandwidth_quota": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "preheat_job_quota": {
+                    "type": "integer",
+                    "minimum": 0
+                },
+                "state": {
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "inactive"
+                    ]
+                }
+            }
+        },
         "d7y_io_dragonfly_v2_manager_types.UpdateUserRequest": {
             "type": "object",
             "properties": {
@@ -4666,6 +7205,43 @@ const docTemplate = `{
                 }
             }
         },
+        "d7y_io_dragonfly_v2_manager_types.UpdateWebhookRequest": {
+            "type": "object",
+            "properties": {
+                "bio": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "job_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "secret": {
+                    "type": "string",
+                    "minLength": 16
+                },
+                "state": {
+                    "type": "string",
+                    "enum": [
+                        "active",
+                        "inactive"
+                    ]
+                },
+                "url": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
         "d7y_io_dragonfly_v2_pkg_objectstorage.BucketMetadata": {
             "type": "object",
             "properties": {
@@ -4678,6 +7254,25 @@ const docTemplate = `{
                     "type": "string"
                 }
             }
+        },
+        "d7y_io_dragonfly_v2_pkg_structure.Diff": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "description": "From is the value in the source map."
+                },
+                "path": {
+                    "description": "Path is the dot separated keys of the value.",
+                    "type": "string"
+                },
+                "to": {
+                    "description": "To is the value in the target map."
+                },
+                "type": {
+                    "description": "Type is the type of diff.",
+                    "type": "string"
+                }
+            }
         }
     }
 }`
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"d7y.io/dragonfly/v2/pkg/net/ip"
+	"d7y.io/dragonfly/v2/pkg/net/ping"
 	"d7y.io/dragonfly/v2/pkg/unit"
 )
 
@@ -75,6 +76,29 @@ const (
 	AdvanceLocalTaskStoreStrategy = StoreStrategy("io.d7y.storage.v2.advance")
 )
 
+// Disk gc policy.
+const (
+	// DiskGCPolicyLRU reclaims the tasks by the time of last access.
+	DiskGCPolicyLRU = DiskGCPolicy("lru")
+
+	// DiskGCPolicyLRUK reclaims the tasks by the time of k-th most recent access, the tasks
+	// accessed less than k times are reclaimed first, so that the tasks accessed once during
+	// image churn do not evict the tasks accessed frequently.
+	DiskGCPolicyLRUK = DiskGCPolicy("lru-k")
+
+	// DefaultDiskGCLRUK is the default k of lru-k disk gc policy.
+	DefaultDiskGCLRUK = 2
+)
+
+// Tiered storage.
+const (
+	// DefaultTieredStoragePromoteAccessCount is the default number of accesses to promote the task to hot tier.
+	DefaultTieredStoragePromoteAccessCount = 3
+
+	// DefaultTieredStoragePromoteWindow is the default window of counting the accesses to promote the task.
+	DefaultTieredStoragePromoteWindow = time.Hour
+)
+
 // Dfcache subcommand names.
 const (
 	CmdStat   = "stat"
@@ -110,4 +134,10 @@ var (
 var (
 	// DefaultAnnouncerSchedulerInterval is default interface of announcing scheduler.
 	DefaultAnnouncerSchedulerInterval = 30 * time.Second
+
+	// DefaultProbeInterval is default interval of probing hosts.
+	DefaultProbeInterval = 20 * time.Minute
+
+	// DefaultProbeProtocol is default protocol of probing hosts.
+	DefaultProbeProtocol = ping.ProtocolICMP
 )
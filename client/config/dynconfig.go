@@ -48,6 +48,16 @@ type DynconfigData struct {
 	ObjectStorage *managerv1.ObjectStorage
 }
 
+// SchedulerClusterClientConfig is the client config of scheduler cluster in manager.
+type SchedulerClusterClientConfig struct {
+	// QUIC indicates to download the pieces via quic in the scheduler cluster.
+	QUIC bool `json:"quic"`
+
+	// ProbeProtocol is the protocol of probing hosts in the scheduler cluster,
+	// empty means the local option decides.
+	ProbeProtocol string `json:"probe_protocol"`
+}
+
 type Dynconfig interface {
 	// Get the dynamic schedulers resolve addrs.
 	GetResolveSchedulerAddrs() ([]resolver.Address, error)
@@ -272,16 +272,20 @@ func newManagerClient(client managerclient.V1, cfg *DaemonOption) internaldyncon
 }
 
 func (mc *managerClient) Get() (any, error) {
+	hostInfo := make(map[string]string, len(mc.config.Host.Labels)+2)
+	for k, v := range mc.config.Host.Labels {
+		hostInfo[k] = v
+	}
+	hostInfo[searcher.ConditionIDC] = mc.config.Host.IDC
+	hostInfo[searcher.ConditionLocation] = mc.config.Host.Location
+
 	listSchedulersResp, err := mc.managerClient.ListSchedulers(context.Background(), &managerv1.ListSchedulersRequest{
 		SourceType: managerv1.SourceType_PEER_SOURCE,
 		Hostname:   mc.config.Host.Hostname,
 		Ip:         mc.config.Host.AdvertiseIP.String(),
 		Version:    version.GitVersion,
 		Commit:     version.GitCommit,
-		HostInfo: map[string]string{
-			searcher.ConditionIDC:      mc.config.Host.IDC,
-			searcher.ConditionLocation: mc.config.Host.Location,
-		},
+		HostInfo:   hostInfo,
 	})
 	if err != nil {
 		return nil, err
@@ -37,7 +37,9 @@ import (
 	"d7y.io/dragonfly/v2/client/util"
 	"d7y.io/dragonfly/v2/cmd/dependency/base"
 	"d7y.io/dragonfly/v2/pkg/dfnet"
+	"d7y.io/dragonfly/v2/pkg/digest"
 	"d7y.io/dragonfly/v2/pkg/net/ip"
+	"d7y.io/dragonfly/v2/pkg/net/ping"
 	"d7y.io/dragonfly/v2/pkg/types"
 	"d7y.io/dragonfly/v2/pkg/unit"
 )
@@ -59,18 +61,19 @@ type DaemonOption struct {
 	DataDir     string `mapstructure:"dataDir" yaml:"dataDir"`
 	KeepStorage bool   `mapstructure:"keepStorage" yaml:"keepStorage"`
 
-	Security      GlobalSecurityOption `mapstructure:"security" yaml:"security"`
-	Scheduler     SchedulerOption      `mapstructure:"scheduler" yaml:"scheduler"`
-	Host          HostOption           `mapstructure:"host" yaml:"host"`
-	Download      DownloadOption       `mapstructure:"download" yaml:"download"`
-	Proxy         *ProxyOption         `mapstructure:"proxy" yaml:"proxy"`
-	Upload        UploadOption         `mapstructure:"upload" yaml:"upload"`
-	ObjectStorage ObjectStorageOption  `mapstructure:"objectStorage" yaml:"objectStorage"`
-	Storage       StorageOption        `mapstructure:"storage" yaml:"storage"`
-	Health        *HealthOption        `mapstructure:"health" yaml:"health"`
-	Reload        ReloadOption         `mapstructure:"reload" yaml:"reload"`
-	Network       *NetworkOption       `mapstructure:"network" yaml:"network"`
-	Announcer     AnnouncerOption      `mapstructure:"announcer" yaml:"announcer"`
+	Security        GlobalSecurityOption  `mapstructure:"security" yaml:"security"`
+	Scheduler       SchedulerOption       `mapstructure:"scheduler" yaml:"scheduler"`
+	Host            HostOption            `mapstructure:"host" yaml:"host"`
+	Download        DownloadOption        `mapstructure:"download" yaml:"download"`
+	Proxy           *ProxyOption          `mapstructure:"proxy" yaml:"proxy"`
+	Upload          UploadOption          `mapstructure:"upload" yaml:"upload"`
+	ObjectStorage   ObjectStorageOption   `mapstructure:"objectStorage" yaml:"objectStorage"`
+	Storage         StorageOption         `mapstructure:"storage" yaml:"storage"`
+	Health          *HealthOption         `mapstructure:"health" yaml:"health"`
+	Reload          ReloadOption          `mapstructure:"reload" yaml:"reload"`
+	Network         *NetworkOption        `mapstructure:"network" yaml:"network"`
+	Announcer       AnnouncerOption       `mapstructure:"announcer" yaml:"announcer"`
+	NetworkTopology NetworkTopologyOption `mapstructure:"networkTopology" yaml:"networkTopology"`
 }
 
 func NewDaemonConfig() *DaemonOption {
@@ -195,6 +198,52 @@ func (p *DaemonOption) Validate() error {
 		return errors.New("gcInterval must be greater than 0")
 	}
 
+	if p.Download.RangeAlignment < 0 {
+		return errors.New("rangeAlignment must be greater than or equal to 0")
+	}
+
+	switch p.Download.PieceDigestAlgorithm {
+	case "", digest.AlgorithmMD5, digest.AlgorithmSHA256, digest.AlgorithmSHA512:
+	default:
+		return fmt.Errorf("pieceDigestAlgorithm %s is not supported", p.Download.PieceDigestAlgorithm)
+	}
+
+	if p.Storage.DiskGCLowThreshold > p.Storage.DiskGCThreshold {
+		return errors.New("diskGCLowThreshold must be less than or equal to diskGCThreshold")
+	}
+
+	if p.Storage.DiskGCLowThresholdPercent > p.Storage.DiskGCThresholdPercent {
+		return errors.New("diskGCLowThresholdPercent must be less than or equal to diskGCThresholdPercent")
+	}
+
+	switch p.Storage.DiskGCPolicy {
+	case DiskGCPolicy(""), DiskGCPolicyLRU:
+	case DiskGCPolicyLRUK:
+		if p.Storage.DiskGCLRUK < 2 {
+			return errors.New("diskGCLRUK must be greater than 1")
+		}
+	default:
+		return fmt.Errorf("not support disk gc policy: %s", p.Storage.DiskGCPolicy)
+	}
+
+	if p.Storage.Tiered.Enable {
+		if p.Storage.Tiered.ColdDataPath == "" {
+			return errors.New("tiered storage requires parameter coldDataPath")
+		}
+
+		if p.Storage.Tiered.HotQuota <= 0 {
+			return errors.New("tiered storage requires parameter hotQuota")
+		}
+
+		if p.Storage.Tiered.PromoteAccessCount <= 0 {
+			return errors.New("tiered storage requires parameter promoteAccessCount")
+		}
+
+		if p.Storage.Tiered.PromoteWindow.Duration <= 0 {
+			return errors.New("tiered storage requires parameter promoteWindow")
+		}
+	}
+
 	if p.Security.AutoIssueCert {
 		if p.Security.CACert == "" {
 			return errors.New("security requires parameter caCert")
@@ -213,6 +262,22 @@ func (p *DaemonOption) Validate() error {
 		}
 	}
 
+	if p.NetworkTopology.Enable {
+		if p.NetworkTopology.Probe.Interval <= 0 {
+			return errors.New("probe requires parameter interval")
+		}
+
+		switch p.NetworkTopology.Probe.Protocol {
+		case ping.ProtocolICMP, ping.ProtocolTCP, ping.ProtocolQUIC:
+		default:
+			return fmt.Errorf("probe protocol %s is not supported", p.NetworkTopology.Probe.Protocol)
+		}
+
+		// The SyncProbes of scheduler does not store the probes yet,
+		// so probing is kept disabled until scheduler supports it.
+		return errors.New("network topology is not supported by scheduler")
+	}
+
 	return nil
 }
 
@@ -285,6 +350,9 @@ type HostOption struct {
 	IDC string `mapstructure:"idc" yaml:"idc"`
 	// Location for scheduler
 	Location string `mapstructure:"location" yaml:"location"`
+	// Labels are reported to manager with idc and location, e.g. the cloud region,
+	// they are matched by the searcher rules of manager to select scheduler cluster
+	Labels map[string]string `mapstructure:"labels" yaml:"labels"`
 	// Hostname is daemon host name
 	Hostname string `mapstructure:"hostname" yaml:"hostname"`
 	// The ip report to scheduler, normal same with listen ip
@@ -307,6 +375,12 @@ type DownloadOption struct {
 	Concurrent           *ConcurrentOption `mapstructure:"concurrent" yaml:"concurrent"`
 	SyncPieceViaHTTPS    bool              `mapstructure:"syncPieceViaHTTPS" yaml:"syncPieceViaHTTPS"`
 	SplitRunningTasks    bool              `mapstructure:"splitRunningTasks" yaml:"splitRunningTasks"`
+	// RangeAlignment indicates to split the ranged requests into the chunks aligned with it,
+	// every chunk is downloaded as a standalone ranged task and shared by the requests hit it, 0 is disabled
+	RangeAlignment unit.Bytes `mapstructure:"rangeAlignment" yaml:"rangeAlignment"`
+	// PieceDigestAlgorithm is the algorithm of digest calculated for every piece downloaded from source,
+	// the digest is carried in piece metadata and validated by the peers, supports md5, sha256 and sha512, default is md5
+	PieceDigestAlgorithm string `mapstructure:"pieceDigestAlgorithm" yaml:"pieceDigestAlgorithm"`
 	// resource clients option
 	ResourceClients ResourceClientsOption `mapstructure:"resourceClients" yaml:"resourceClients"`
 
@@ -474,6 +548,14 @@ func (p *ProxyOption) unmarshal(unmarshal func(in []byte, out any) (err error),
 type UploadOption struct {
 	ListenOption `yaml:",inline" mapstructure:",squash"`
 	RateLimit    util.RateLimit `mapstructure:"rateLimit" yaml:"rateLimit"`
+	QUIC         QUICOption     `mapstructure:"quic" yaml:"quic"`
+}
+
+type QUICOption struct {
+	// Enable serves the pieces via http3 over quic on the udp port same with upload port,
+	// and downloads the pieces from other peers via quic, when the daemon is managed by manager,
+	// downloading via quic is switched by the client config of scheduler cluster
+	Enable bool `mapstructure:"enable" yaml:"enable"`
 }
 
 type ObjectStorageOption struct {
@@ -617,13 +699,48 @@ type StorageOption struct {
 	// DiskGCThresholdPercent indicates the threshold to gc the oldest tasks according the disk usage
 	// Eg, DiskGCThresholdPercent=80, when the disk usage is above 80%, start to gc the oldest tasks
 	DiskGCThresholdPercent float64 `mapstructure:"diskGCThresholdPercent" yaml:"diskGCThresholdPercent"`
+	// DiskGCLowThreshold indicates the low watermark of DiskGCThreshold, when DiskGCThreshold is exceeded,
+	// the tasks are reclaimed until the quota of all tasks is below DiskGCLowThreshold, default is DiskGCThreshold
+	DiskGCLowThreshold unit.Bytes `mapstructure:"diskGCLowThreshold" yaml:"diskGCLowThreshold"`
+	// DiskGCLowThresholdPercent indicates the low watermark of DiskGCThresholdPercent, when DiskGCThresholdPercent
+	// is exceeded, the tasks are reclaimed until the disk usage is below DiskGCLowThresholdPercent,
+	// default is DiskGCThresholdPercent
+	DiskGCLowThresholdPercent float64 `mapstructure:"diskGCLowThresholdPercent" yaml:"diskGCLowThresholdPercent"`
+	// DiskGCPolicy indicates the policy to choose the tasks reclaimed when the disk gc threshold is exceeded,
+	// it can be lru or lru-k, default is lru
+	DiskGCPolicy DiskGCPolicy `mapstructure:"diskGCPolicy" yaml:"diskGCPolicy"`
+	// DiskGCLRUK indicates the k of lru-k disk gc policy
+	DiskGCLRUK int `mapstructure:"diskGCLRUK" yaml:"diskGCLRUK"`
+	// Tiered indicates the tiered storage of task data, it is usually used by seed peer
+	Tiered TieredStorageOption `mapstructure:"tiered" yaml:"tiered"`
 	// Multiplex indicates reusing underlying storage for same task id
 	Multiplex     bool          `mapstructure:"multiplex" yaml:"multiplex"`
 	StoreStrategy StoreStrategy `mapstructure:"strategy" yaml:"strategy"`
 }
 
+type TieredStorageOption struct {
+	// Enable demotes the data of completed tasks from DataPath, the hot tier on SSD usually,
+	// to ColdDataPath, the cold tier on HDD usually, and promotes it back when it is accessed frequently
+	Enable bool `mapstructure:"enable" yaml:"enable"`
+	// ColdDataPath indicates directory which stores the data of tasks demoted to cold tier
+	ColdDataPath string `mapstructure:"coldDataPath" yaml:"coldDataPath"`
+	// HotQuota indicates the quota of task data in hot tier, when it is exceeded,
+	// the tasks chosen by DiskGCPolicy are demoted to cold tier
+	HotQuota unit.Bytes `mapstructure:"hotQuota" yaml:"hotQuota"`
+	// PromoteAccessCount indicates the number of uncorrelated accesses in PromoteWindow
+	// to promote the task in cold tier to hot tier
+	PromoteAccessCount int `mapstructure:"promoteAccessCount" yaml:"promoteAccessCount"`
+	// PromoteWindow indicates the window of counting the accesses to promote the task
+	PromoteWindow util.Duration `mapstructure:"promoteWindow" yaml:"promoteWindow"`
+	// MemoryCacheSize indicates the size of memory tier which caches the recently read pieces of tasks,
+	// zero disables the memory tier
+	MemoryCacheSize unit.Bytes `mapstructure:"memoryCacheSize" yaml:"memoryCacheSize"`
+}
+
 type StoreStrategy string
 
+type DiskGCPolicy string
+
 type HealthOption struct {
 	ListenOption `yaml:",inline" mapstructure:",squash"`
 	Path         string `mapstructure:"path" yaml:"path"`
@@ -935,3 +1052,20 @@ type AnnouncerOption struct {
 	// SchedulerInterval is the interval of announcing scheduler.
 	SchedulerInterval time.Duration `mapstructure:"schedulerInterval" yaml:"schedulerInterval"`
 }
+
+type NetworkTopologyOption struct {
+	// Enable probes the hosts assigned by scheduler and syncs the probes to scheduler,
+	// it is not supported until scheduler stores the probes.
+	Enable bool `mapstructure:"enable" yaml:"enable"`
+	// Probe is the option of probing hosts.
+	Probe ProbeOption `mapstructure:"probe" yaml:"probe"`
+}
+
+type ProbeOption struct {
+	// Interval is the interval of probing hosts, it is overridden by the probe interval returned by scheduler.
+	Interval time.Duration `mapstructure:"interval" yaml:"interval"`
+	// Protocol is the protocol of probing hosts, icmp, tcp or quic. tcp and quic probe the upload port of hosts,
+	// quic requires the hosts to enable upload.quic. When the daemon is managed by manager, the protocol is
+	// switched by the client config of scheduler cluster.
+	Protocol string `mapstructure:"protocol" yaml:"protocol"`
+}
@@ -141,6 +141,15 @@ var peerHostConfig = func() *DaemonOption {
 			StoreStrategy:          SimpleLocalTaskStoreStrategy,
 			Multiplex:              false,
 			DiskGCThresholdPercent: 95,
+			DiskGCPolicy:           DiskGCPolicyLRU,
+			DiskGCLRUK:             DefaultDiskGCLRUK,
+			Tiered: TieredStorageOption{
+				Enable:             false,
+				PromoteAccessCount: DefaultTieredStoragePromoteAccessCount,
+				PromoteWindow: util.Duration{
+					Duration: DefaultTieredStoragePromoteWindow,
+				},
+			},
 		},
 		Health: &HealthOption{
 			ListenOption: ListenOption{
@@ -179,5 +188,12 @@ var peerHostConfig = func() *DaemonOption {
 		Announcer: AnnouncerOption{
 			SchedulerInterval: DefaultAnnouncerSchedulerInterval,
 		},
+		NetworkTopology: NetworkTopologyOption{
+			Enable: false,
+			Probe: ProbeOption{
+				Interval: DefaultProbeInterval,
+				Protocol: DefaultProbeProtocol,
+			},
+		},
 	}
 }
@@ -288,6 +288,7 @@ func TestPeerHostOption_Load(t *testing.T) {
 			Hostname:    "d7y.io",
 			Location:    "0.0.0.0",
 			IDC:         "d7y",
+			Labels:      map[string]string{"region": "us-east-1"},
 			AdvertiseIP: net.IPv4zero,
 		},
 		Download: DownloadOption{
@@ -400,10 +401,24 @@ func TestPeerHostOption_Load(t *testing.T) {
 			TaskExpireTime: util.Duration{
 				Duration: 180000000000,
 			},
-			StoreStrategy:          StoreStrategy("io.d7y.storage.v2.simple"),
-			DiskGCThreshold:        60 * unit.MB,
-			DiskGCThresholdPercent: 0.6,
-			Multiplex:              true,
+			StoreStrategy:             StoreStrategy("io.d7y.storage.v2.simple"),
+			DiskGCThreshold:           60 * unit.MB,
+			DiskGCThresholdPercent:    0.6,
+			DiskGCLowThreshold:        50 * unit.MB,
+			DiskGCLowThresholdPercent: 0.5,
+			DiskGCPolicy:              DiskGCPolicyLRUK,
+			DiskGCLRUK:                3,
+			Tiered: TieredStorageOption{
+				Enable:             true,
+				ColdDataPath:       "/tmp/storage/cold",
+				HotQuota:           40 * unit.MB,
+				PromoteAccessCount: 2,
+				PromoteWindow: util.Duration{
+					Duration: 30 * time.Minute,
+				},
+				MemoryCacheSize: 10 * unit.MB,
+			},
+			Multiplex: true,
 		},
 		Health: &HealthOption{
 			Path: "/health",
@@ -512,6 +527,13 @@ func TestPeerHostOption_Load(t *testing.T) {
 		Announcer: AnnouncerOption{
 			SchedulerInterval: 1000000000,
 		},
+		NetworkTopology: NetworkTopologyOption{
+			Enable: true,
+			Probe: ProbeOption{
+				Interval: time.Minute,
+				Protocol: "quic",
+			},
+		},
 	}
 
 	peerHostOptionYAML := &DaemonOption{}
@@ -751,6 +773,259 @@ func TestPeerHostOption_Validate(t *testing.T) {
 				assert.EqualError(err, "certSpec requires parameter validityPeriod")
 			},
 		},
+		{
+			name:   "rangeAlignment must be greater than or equal to 0",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.Download.RangeAlignment = -1
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "rangeAlignment must be greater than or equal to 0")
+			},
+		},
+		{
+			name:   "pieceDigestAlgorithm is not supported",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.Download.PieceDigestAlgorithm = "sha1"
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "pieceDigestAlgorithm sha1 is not supported")
+			},
+		},
+		{
+			name:   "diskGCLowThreshold must be less than or equal to diskGCThreshold",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.Storage.DiskGCThreshold = 10 * unit.GB
+				cfg.Storage.DiskGCLowThreshold = 20 * unit.GB
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "diskGCLowThreshold must be less than or equal to diskGCThreshold")
+			},
+		},
+		{
+			name:   "diskGCLowThresholdPercent must be less than or equal to diskGCThresholdPercent",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.Storage.DiskGCLowThresholdPercent = 96
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "diskGCLowThresholdPercent must be less than or equal to diskGCThresholdPercent")
+			},
+		},
+		{
+			name:   "diskGCLRUK must be greater than 1",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.Storage.DiskGCPolicy = DiskGCPolicyLRUK
+				cfg.Storage.DiskGCLRUK = 1
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "diskGCLRUK must be greater than 1")
+			},
+		},
+		{
+			name:   "not support disk gc policy",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.Storage.DiskGCPolicy = DiskGCPolicy("arc")
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "not support disk gc policy: arc")
+			},
+		},
+		{
+			name:   "tiered storage requires parameter coldDataPath",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.Storage.Tiered = TieredStorageOption{
+					Enable:             true,
+					ColdDataPath:       "/tmp/storage/cold",
+					HotQuota:           unit.GB,
+					PromoteAccessCount: 1,
+					PromoteWindow:      util.Duration{Duration: time.Hour},
+				}
+				cfg.Storage.Tiered.ColdDataPath = ""
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "tiered storage requires parameter coldDataPath")
+			},
+		},
+		{
+			name:   "tiered storage requires parameter hotQuota",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.Storage.Tiered = TieredStorageOption{
+					Enable:             true,
+					ColdDataPath:       "/tmp/storage/cold",
+					HotQuota:           unit.GB,
+					PromoteAccessCount: 1,
+					PromoteWindow:      util.Duration{Duration: time.Hour},
+				}
+				cfg.Storage.Tiered.HotQuota = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "tiered storage requires parameter hotQuota")
+			},
+		},
+		{
+			name:   "tiered storage requires parameter promoteAccessCount",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.Storage.Tiered = TieredStorageOption{
+					Enable:             true,
+					ColdDataPath:       "/tmp/storage/cold",
+					HotQuota:           unit.GB,
+					PromoteAccessCount: 1,
+					PromoteWindow:      util.Duration{Duration: time.Hour},
+				}
+				cfg.Storage.Tiered.PromoteAccessCount = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "tiered storage requires parameter promoteAccessCount")
+			},
+		},
+		{
+			name:   "tiered storage requires parameter promoteWindow",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.Storage.Tiered = TieredStorageOption{
+					Enable:             true,
+					ColdDataPath:       "/tmp/storage/cold",
+					HotQuota:           unit.GB,
+					PromoteAccessCount: 1,
+					PromoteWindow:      util.Duration{Duration: time.Hour},
+				}
+				cfg.Storage.Tiered.PromoteWindow.Duration = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "tiered storage requires parameter promoteWindow")
+			},
+		},
+		{
+			name:   "probe requires parameter interval",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.NetworkTopology.Enable = true
+				cfg.NetworkTopology.Probe.Interval = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "probe requires parameter interval")
+			},
+		},
+		{
+			name:   "probe protocol is not supported",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.NetworkTopology.Enable = true
+				cfg.NetworkTopology.Probe.Protocol = "udp"
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "probe protocol udp is not supported")
+			},
+		},
+		{
+			name:   "network topology is not supported",
+			config: NewDaemonConfig(),
+			mock: func(cfg *DaemonConfig) {
+				cfg.Scheduler.NetAddrs = []dfnet.NetAddr{
+					{
+						Type: dfnet.TCP,
+						Addr: "127.0.0.1:8002",
+					},
+				}
+				cfg.NetworkTopology.Enable = true
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "network topology is not supported by scheduler")
+			},
+		},
 	}
 
 	for _, tc := range tests {
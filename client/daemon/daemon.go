@@ -41,12 +41,14 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 	zapadapter "logur.dev/adapter/zap"
 
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
 	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
 
 	"d7y.io/dragonfly/v2/client/config"
 	"d7y.io/dragonfly/v2/client/daemon/announcer"
 	"d7y.io/dragonfly/v2/client/daemon/gc"
 	"d7y.io/dragonfly/v2/client/daemon/metrics"
+	"d7y.io/dragonfly/v2/client/daemon/networktopology"
 	"d7y.io/dragonfly/v2/client/daemon/objectstorage"
 	"d7y.io/dragonfly/v2/client/daemon/peer"
 	"d7y.io/dragonfly/v2/client/daemon/proxy"
@@ -107,6 +109,7 @@ type clientDaemon struct {
 	schedulerClient schedulerclient.V1
 	certifyClient   *certify.Certify
 	announcer       announcer.Announcer
+	prober          networktopology.Prober
 }
 
 func New(opt *config.DaemonOption, d dfpath.Dfpath) (Daemon, error) {
@@ -248,8 +251,10 @@ func New(opt *config.DaemonOption, d dfpath.Dfpath) (Daemon, error) {
 	pmOpts := []peer.PieceManagerOption{
 		peer.WithLimiter(rate.NewLimiter(opt.Download.TotalRateLimit.Limit, int(opt.Download.TotalRateLimit.Limit))),
 		peer.WithCalculateDigest(opt.Download.CalculateDigest),
+		peer.WithPieceDigestAlgorithm(opt.Download.PieceDigestAlgorithm),
 		peer.WithTransportOption(opt.Download.Transport),
 		peer.WithConcurrentOption(opt.Download.Concurrent),
+		peer.WithQUICPieceDownloader(opt.Upload.QUIC.Enable),
 	}
 
 	if opt.Download.SyncPieceViaHTTPS && opt.Scheduler.Manager.Enable {
@@ -261,6 +266,11 @@ func New(opt *config.DaemonOption, d dfpath.Dfpath) (Daemon, error) {
 		return nil, err
 	}
 
+	// register notify for switching quic piece downloader by scheduler cluster
+	if observer, ok := pieceManager.(config.Observer); ok && opt.Upload.QUIC.Enable {
+		dynconfig.Register(observer)
+	}
+
 	peerTaskManagerOption := &peer.TaskManagerOption{
 		TaskOption: peer.TaskOption{
 			PeerHost:        host,
@@ -280,6 +290,7 @@ func New(opt *config.DaemonOption, d dfpath.Dfpath) (Daemon, error) {
 		Prefetch:          opt.Download.Prefetch,
 		GetPiecesMaxRetry: opt.Download.GetPiecesMaxRetry,
 		SplitRunningTasks: opt.Download.SplitRunningTasks,
+		RangeAlignment:    int64(opt.Download.RangeAlignment),
 	}
 	peerTaskManager, err := peer.NewPeerTaskManager(peerTaskManagerOption)
 	if err != nil {
@@ -319,6 +330,7 @@ func New(opt *config.DaemonOption, d dfpath.Dfpath) (Daemon, error) {
 
 	uploadOpts := []upload.Option{
 		upload.WithLimiter(rate.NewLimiter(opt.Upload.RateLimit.Limit, int(opt.Upload.RateLimit.Limit))),
+		upload.WithQUIC(opt.Upload.QUIC.Enable),
 	}
 
 	if opt.Security.AutoIssueCert && opt.Scheduler.Manager.Enable {
@@ -683,6 +695,40 @@ func (cd *clientDaemon) Serve() error {
 		}
 	}()
 
+	// serve prober
+	if cd.Option.NetworkTopology.Enable {
+		var proberOptions []networktopology.Option
+		// The hosts serve quic with the certificates issued by manager if certify is present,
+		// which is the same with the upload server, so they are verified by the ca of manager.
+		if cd.certifyClient != nil {
+			caCertPool := x509.NewCertPool()
+			caCertPool.AppendCertsFromPEM([]byte(cd.Option.Security.CACert))
+			proberOptions = append(proberOptions, networktopology.WithCACertPool(caCertPool))
+		}
+
+		cd.prober = networktopology.New(&cd.Option, &commonv1.Host{
+			Id:           cd.schedPeerHost.Id,
+			Ip:           cd.schedPeerHost.Ip,
+			Hostname:     cd.schedPeerHost.Hostname,
+			Port:         cd.schedPeerHost.RpcPort,
+			DownloadPort: cd.schedPeerHost.DownPort,
+			Location:     cd.schedPeerHost.Location,
+			Idc:          cd.schedPeerHost.Idc,
+		}, cd.schedulerClient, proberOptions...)
+
+		// register notify for switching probe protocol by scheduler cluster
+		if observer, ok := cd.prober.(config.Observer); ok {
+			cd.dynconfig.Register(observer)
+		}
+
+		go func() {
+			logger.Info("serve prober")
+			if err := cd.prober.Serve(); err != nil {
+				logger.Errorf("failed to serve for prober: %v", err)
+			}
+		}()
+	}
+
 	if cd.Option.AliveTime.Duration > 0 {
 		g.Go(func() error {
 			for {
@@ -834,6 +880,12 @@ func (cd *clientDaemon) Stop() {
 			logger.Errorf("announcer stop failed %s", err)
 		}
 
+		if cd.prober != nil {
+			if err := cd.prober.Stop(); err != nil {
+				logger.Errorf("prober stop failed %s", err)
+			}
+		}
+
 		if err := cd.dynconfig.Stop(); err != nil {
 			logger.Errorf("dynconfig client closed failed %s", err)
 		} else {
@@ -0,0 +1,242 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+//go:generate mockgen -destination mocks/prober_mock.go -source prober.go -package mocks
+
+package networktopology
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/quic-go/quic-go/http3"
+	"go.uber.org/atomic"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
+
+	"d7y.io/dragonfly/v2/client/config"
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/pkg/net/ping"
+	schedulerclient "d7y.io/dragonfly/v2/pkg/rpc/scheduler/client"
+)
+
+// Prober is the interface used for probing hosts.
+type Prober interface {
+	// Started prober server.
+	Serve() error
+
+	// Stop prober server.
+	Stop() error
+}
+
+// prober probes the hosts assigned by scheduler and syncs the probes to scheduler.
+type prober struct {
+	config          *config.DaemonOption
+	host            *commonv1.Host
+	schedulerClient schedulerclient.V1
+	protocol        *atomic.String
+	tlsConfig       *tls.Config
+	done            chan struct{}
+}
+
+// Option is a functional option for configuring the prober.
+type Option func(p *prober)
+
+// WithCACertPool sets the ca cert pool to verify the hosts probed via quic.
+func WithCACertPool(caCertPool *x509.CertPool) Option {
+	return func(p *prober) {
+		p.tlsConfig.RootCAs = caCertPool
+		p.tlsConfig.InsecureSkipVerify = false
+	}
+}
+
+// New returns a new Prober interface.
+func New(cfg *config.DaemonOption, host *commonv1.Host, schedulerClient schedulerclient.V1, options ...Option) Prober {
+	p := &prober{
+		config:          cfg,
+		host:            host,
+		schedulerClient: schedulerClient,
+		protocol:        atomic.NewString(cfg.NetworkTopology.Probe.Protocol),
+		// Without the ca, the hosts serve quic with self-signed certificates which are unable to be
+		// verified, the same with downloading pieces via quic. Only the handshake is measured and
+		// no data is exchanged, so the hosts are not verified unless WithCACertPool is set.
+		tlsConfig: &tls.Config{
+			InsecureSkipVerify: true,
+			NextProtos:         []string{http3.NextProtoH3},
+		},
+		done: make(chan struct{}),
+	}
+
+	for _, opt := range options {
+		opt(p)
+	}
+
+	return p
+}
+
+// Started prober server.
+func (p *prober) Serve() error {
+	interval := p.config.NetworkTopology.Probe.Interval
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-tick.C:
+			probeInterval, err := p.syncProbes()
+			if err != nil {
+				logger.Errorf("sync probes failed: %s", err.Error())
+				break
+			}
+
+			if probeInterval > 0 && probeInterval != interval {
+				logger.Infof("change probe interval from %s to %s by scheduler", interval, probeInterval)
+				interval = probeInterval
+				tick.Reset(interval)
+			}
+		case <-p.done:
+			return nil
+		}
+	}
+}
+
+// Stop prober server.
+func (p *prober) Stop() error {
+	close(p.done)
+	return nil
+}
+
+// OnNotify switches the protocol of probing hosts by the client config of scheduler cluster.
+func (p *prober) OnNotify(data *config.DynconfigData) {
+	for _, scheduler := range data.Schedulers {
+		if len(scheduler.GetSchedulerCluster().GetClientConfig()) == 0 {
+			continue
+		}
+
+		var clientConfig config.SchedulerClusterClientConfig
+		if err := json.Unmarshal(scheduler.SchedulerCluster.ClientConfig, &clientConfig); err != nil {
+			logger.Errorf("unmarshal client config of scheduler cluster %d error: %s", scheduler.SchedulerClusterId, err)
+			continue
+		}
+
+		protocol := clientConfig.ProbeProtocol
+		switch protocol {
+		case "":
+			protocol = p.config.NetworkTopology.Probe.Protocol
+		case ping.ProtocolICMP, ping.ProtocolTCP, ping.ProtocolQUIC:
+		default:
+			logger.Errorf("probe protocol %s of scheduler cluster %d is not supported", protocol, scheduler.SchedulerClusterId)
+			return
+		}
+
+		if old := p.protocol.Swap(protocol); old != protocol {
+			logger.Infof("switch probe protocol from %s to %s by scheduler cluster %d", old, protocol, scheduler.SchedulerClusterId)
+		}
+		return
+	}
+}
+
+// syncProbes probes the hosts assigned by scheduler and sends the probes to scheduler,
+// it returns the probe interval of scheduler.
+func (p *prober) syncProbes() (time.Duration, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := p.schedulerClient.SyncProbes(ctx, &schedulerv1.SyncProbesRequest{
+		ProbesOfHost: &schedulerv1.ProbesOfHost{Host: p.host},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		if err == io.EOF {
+			logger.Debug("scheduler assigns no hosts to probe")
+			return 0, nil
+		}
+
+		return 0, err
+	}
+
+	protocol := p.protocol.Load()
+	var probes []*schedulerv1.Probe
+	for _, host := range resp.Hosts {
+		rtt, err := p.probe(ctx, protocol, host)
+		if err != nil {
+			logger.Warnf("probe host %s %s via %s failed: %s", host.Id, host.Ip, protocol, err.Error())
+			continue
+		}
+
+		probes = append(probes, &schedulerv1.Probe{
+			Host:      host,
+			Rtt:       durationpb.New(rtt),
+			CreatedAt: timestamppb.Now(),
+		})
+	}
+
+	if len(probes) > 0 {
+		if err := stream.Send(&schedulerv1.SyncProbesRequest{
+			ProbesOfHost: &schedulerv1.ProbesOfHost{Host: p.host, Probes: probes},
+		}); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := stream.CloseSend(); err != nil {
+		return 0, err
+	}
+
+	// Wait for scheduler to receive the probes before the stream is canceled.
+	for {
+		if _, err := stream.Recv(); err != nil {
+			if err == io.EOF {
+				break
+			}
+
+			return 0, err
+		}
+	}
+
+	return resp.GetProbeInterval().AsDuration(), nil
+}
+
+// probe returns the round-trip time to host via protocol, tcp and quic probe the upload port of host.
+func (p *prober) probe(ctx context.Context, protocol string, host *commonv1.Host) (time.Duration, error) {
+	addr := net.JoinHostPort(host.Ip, fmt.Sprint(host.DownloadPort))
+	switch protocol {
+	case ping.ProtocolTCP:
+		return ping.PingTCP(ctx, addr)
+	case ping.ProtocolQUIC:
+		return ping.PingQUIC(ctx, addr, p.tlsConfig)
+	default:
+		stats, err := ping.Ping(host.Ip)
+		if err != nil {
+			return 0, err
+		}
+
+		return stats.AvgRtt, nil
+	}
+}
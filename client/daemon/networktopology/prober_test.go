@@ -0,0 +1,218 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package networktopology
+
+import (
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	managerv1 "d7y.io/api/pkg/apis/manager/v1"
+	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
+	schedulerv1mocks "d7y.io/api/pkg/apis/scheduler/v1/mocks"
+
+	"d7y.io/dragonfly/v2/client/config"
+	schedulerclientmocks "d7y.io/dragonfly/v2/pkg/rpc/scheduler/client/mocks"
+)
+
+func TestProber_SyncProbes(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	host := &commonv1.Host{Id: "foo", Ip: "127.0.0.1", DownloadPort: int32(listener.Addr().(*net.TCPAddr).Port)}
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedHost := &commonv1.Host{Id: "bar", Ip: "127.0.0.1", DownloadPort: int32(closed.Addr().(*net.TCPAddr).Port)}
+	closed.Close()
+
+	tests := []struct {
+		name   string
+		mock   func(mc *schedulerclientmocks.MockV1MockRecorder, stream *schedulerv1mocks.MockScheduler_SyncProbesClient, ms *schedulerv1mocks.MockScheduler_SyncProbesClientMockRecorder)
+		expect func(t *testing.T, interval time.Duration, err error)
+	}{
+		{
+			name: "probe hosts via tcp",
+			mock: func(mc *schedulerclientmocks.MockV1MockRecorder, stream *schedulerv1mocks.MockScheduler_SyncProbesClient, ms *schedulerv1mocks.MockScheduler_SyncProbesClientMockRecorder) {
+				gomock.InOrder(
+					mc.SyncProbes(gomock.Any(), gomock.Any()).Return(stream, nil).Times(1),
+					ms.Recv().Return(&schedulerv1.SyncProbesResponse{
+						Hosts:         []*commonv1.Host{host, closedHost},
+						ProbeInterval: durationpb.New(time.Minute),
+					}, nil).Times(1),
+					ms.Send(gomock.Any()).DoAndReturn(func(req *schedulerv1.SyncProbesRequest) error {
+						assert := assert.New(t)
+						assert.Equal("baz", req.ProbesOfHost.Host.Id)
+						assert.Len(req.ProbesOfHost.Probes, 1)
+						assert.Equal("foo", req.ProbesOfHost.Probes[0].Host.Id)
+						assert.Greater(req.ProbesOfHost.Probes[0].Rtt.AsDuration(), time.Duration(0))
+						return nil
+					}).Times(1),
+					ms.CloseSend().Return(nil).Times(1),
+					ms.Recv().Return(nil, io.EOF).Times(1),
+				)
+			},
+			expect: func(t *testing.T, interval time.Duration, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(time.Minute, interval)
+			},
+		},
+		{
+			name: "probe hosts failed",
+			mock: func(mc *schedulerclientmocks.MockV1MockRecorder, stream *schedulerv1mocks.MockScheduler_SyncProbesClient, ms *schedulerv1mocks.MockScheduler_SyncProbesClientMockRecorder) {
+				gomock.InOrder(
+					mc.SyncProbes(gomock.Any(), gomock.Any()).Return(stream, nil).Times(1),
+					ms.Recv().Return(&schedulerv1.SyncProbesResponse{Hosts: []*commonv1.Host{closedHost}}, nil).Times(1),
+					ms.CloseSend().Return(nil).Times(1),
+					ms.Recv().Return(nil, io.EOF).Times(1),
+				)
+			},
+			expect: func(t *testing.T, interval time.Duration, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(time.Duration(0), interval)
+			},
+		},
+		{
+			name: "scheduler assigns no hosts",
+			mock: func(mc *schedulerclientmocks.MockV1MockRecorder, stream *schedulerv1mocks.MockScheduler_SyncProbesClient, ms *schedulerv1mocks.MockScheduler_SyncProbesClientMockRecorder) {
+				gomock.InOrder(
+					mc.SyncProbes(gomock.Any(), gomock.Any()).Return(stream, nil).Times(1),
+					ms.Recv().Return(nil, io.EOF).Times(1),
+				)
+			},
+			expect: func(t *testing.T, interval time.Duration, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(time.Duration(0), interval)
+			},
+		},
+		{
+			name: "sync probes failed",
+			mock: func(mc *schedulerclientmocks.MockV1MockRecorder, stream *schedulerv1mocks.MockScheduler_SyncProbesClient, ms *schedulerv1mocks.MockScheduler_SyncProbesClientMockRecorder) {
+				mc.SyncProbes(gomock.Any(), gomock.Any()).Return(nil, errors.New("foo")).Times(1)
+			},
+			expect: func(t *testing.T, interval time.Duration, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "foo")
+			},
+		},
+		{
+			name: "send probes failed",
+			mock: func(mc *schedulerclientmocks.MockV1MockRecorder, stream *schedulerv1mocks.MockScheduler_SyncProbesClient, ms *schedulerv1mocks.MockScheduler_SyncProbesClientMockRecorder) {
+				gomock.InOrder(
+					mc.SyncProbes(gomock.Any(), gomock.Any()).Return(stream, nil).Times(1),
+					ms.Recv().Return(&schedulerv1.SyncProbesResponse{Hosts: []*commonv1.Host{host}}, nil).Times(1),
+					ms.Send(gomock.Any()).Return(errors.New("foo")).Times(1),
+				)
+			},
+			expect: func(t *testing.T, interval time.Duration, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "foo")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockSchedulerClient := schedulerclientmocks.NewMockV1(ctl)
+			stream := schedulerv1mocks.NewMockScheduler_SyncProbesClient(ctl)
+			tc.mock(mockSchedulerClient.EXPECT(), stream, stream.EXPECT())
+
+			cfg := config.NewDaemonConfig()
+			cfg.NetworkTopology.Probe.Protocol = "tcp"
+			p := New(cfg, &commonv1.Host{Id: "baz"}, mockSchedulerClient)
+			interval, err := p.(*prober).syncProbes()
+			tc.expect(t, interval, err)
+		})
+	}
+}
+
+func TestProber_OnNotify(t *testing.T) {
+	tests := []struct {
+		name         string
+		clientConfig []byte
+		expect       func(t *testing.T, p *prober)
+	}{
+		{
+			name:         "switch protocol by scheduler cluster",
+			clientConfig: []byte(`{"probe_protocol":"quic"}`),
+			expect: func(t *testing.T, p *prober) {
+				assert := assert.New(t)
+				assert.Equal("quic", p.protocol.Load())
+			},
+		},
+		{
+			name:         "scheduler cluster does not set protocol",
+			clientConfig: []byte(`{"quic":true}`),
+			expect: func(t *testing.T, p *prober) {
+				assert := assert.New(t)
+				assert.Equal("tcp", p.protocol.Load())
+			},
+		},
+		{
+			name:         "protocol of scheduler cluster is not supported",
+			clientConfig: []byte(`{"probe_protocol":"udp"}`),
+			expect: func(t *testing.T, p *prober) {
+				assert := assert.New(t)
+				assert.Equal("icmp", p.protocol.Load())
+			},
+		},
+		{
+			name:         "client config of scheduler cluster is invalid",
+			clientConfig: []byte(`{`),
+			expect: func(t *testing.T, p *prober) {
+				assert := assert.New(t)
+				assert.Equal("icmp", p.protocol.Load())
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			cfg := config.NewDaemonConfig()
+			cfg.NetworkTopology.Probe.Protocol = "tcp"
+			p := New(cfg, &commonv1.Host{Id: "foo"}, schedulerclientmocks.NewMockV1(ctl)).(*prober)
+			p.protocol.Store("icmp")
+			p.OnNotify(&config.DynconfigData{
+				Schedulers: []*managerv1.Scheduler{{
+					SchedulerClusterId: 1,
+					SchedulerCluster:   &managerv1.SchedulerCluster{Id: 1, ClientConfig: tc.clientConfig},
+				}},
+			})
+			tc.expect(t, p)
+		})
+	}
+}
@@ -32,6 +32,7 @@ import (
 	"golang.org/x/time/rate"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
@@ -178,9 +179,12 @@ func (ptm *peerTaskManager) newPeerTaskConductor(
 	parent *peerTaskConductor,
 	rg *nethttp.Range,
 	seed bool) *peerTaskConductor {
-	// use a new context with span info
-	ctx = trace.ContextWithSpan(context.Background(), trace.SpanFromContext(ctx))
-	ctx, span := tracer.Start(ctx, config.SpanPeerTask, trace.WithSpanKind(trace.SpanKindClient))
+	// use a new context with span info and outgoing metadata, e.g. the priority class of task
+	taskCtx := trace.ContextWithSpan(context.Background(), trace.SpanFromContext(ctx))
+	if md, ok := metadata.FromOutgoingContext(ctx); ok {
+		taskCtx = metadata.NewOutgoingContext(taskCtx, md)
+	}
+	ctx, span := tracer.Start(taskCtx, config.SpanPeerTask, trace.WithSpanKind(trace.SpanKindClient))
 	span.SetAttributes(config.AttributePeerHost.String(ptm.PeerHost.Id))
 	span.SetAttributes(semconv.NetHostIPKey.String(ptm.PeerHost.Ip))
 	span.SetAttributes(config.AttributePeerID.String(request.PeerId))
@@ -554,6 +558,7 @@ func (pt *peerTaskConductor) pullPiecesWithP2P() {
 		peerTaskConductor: pt,
 		pieceRequestQueue: pieceRequestQueue,
 		workers:           map[string]*pieceTaskSynchronizer{},
+		blockedPeers:      map[string]struct{}{},
 	}
 	pt.receivePeerPacket(pieceRequestQueue)
 }
@@ -1005,6 +1010,17 @@ func (pt *peerTaskConductor) downloadPieceWorker(id int32, requests PieceDispatc
 			continue
 		}
 		pt.readyPiecesLock.RUnlock()
+		if pt.pieceTaskSyncManager.isBlocked(request.DstPid) {
+			pt.Log().Debugf("piece %d is from blocked peer %s, skip", request.piece.PieceNum, request.DstPid)
+			pt.pieceTaskSyncManager.acquire(
+				&commonv1.PieceTaskRequest{
+					Limit:    1,
+					TaskId:   pt.taskID,
+					SrcPid:   pt.peerID,
+					StartNum: uint32(request.piece.PieceNum),
+				})
+			continue
+		}
 		result := pt.downloadPiece(id, request)
 		if result != nil {
 			requests.Report(result)
@@ -1060,6 +1076,11 @@ func (pt *peerTaskConductor) downloadPiece(workerID int32, request *DownloadPiec
 	// result is always not nil, PieceManager will report begin and end time
 	result, err := pt.PieceManager.DownloadPiece(ctx, request)
 	if err != nil {
+		if isPieceDigestError(err) {
+			// the parent sent corrupted piece, block it immediately instead of failing the task at completion
+			pt.Warnf("piece %d from peer %s is corrupted, block it", request.piece.PieceNum, request.DstPid)
+			pt.pieceTaskSyncManager.blockPeer(request.DstPid)
+		}
 		pt.ReportPieceResult(request, result, err)
 		span.SetAttributes(config.AttributePieceSuccess.Bool(false))
 		span.End()
@@ -40,7 +40,9 @@ import (
 	"d7y.io/dragonfly/v2/internal/util"
 	"d7y.io/dragonfly/v2/pkg/idgen"
 	nethttp "d7y.io/dragonfly/v2/pkg/net/http"
+	"d7y.io/dragonfly/v2/pkg/rpc/common"
 	schedulerclient "d7y.io/dragonfly/v2/pkg/rpc/scheduler/client"
+	"d7y.io/dragonfly/v2/pkg/types"
 )
 
 // TaskManager processes all peer tasks request
@@ -137,6 +139,8 @@ type TaskManagerOption struct {
 	Prefetch          bool
 	GetPiecesMaxRetry int
 	SplitRunningTasks bool
+	// RangeAlignment indicates to split the ranged requests into the aligned chunk tasks, 0 is disabled
+	RangeAlignment int64
 }
 
 func NewPeerTaskManager(opt *TaskManagerOption) (TaskManager, error) {
@@ -291,7 +295,9 @@ func (ptm *peerTaskManager) prefetchParentTask(request *schedulerv1.PeerTaskRequ
 	}
 
 	logger.Infof("prefetch peer task %s/%s", taskID, req.PeerId)
-	prefetch, err := ptm.getPeerTaskConductor(context.Background(), taskID, req, limit, nil, nil, desiredLocation, false)
+	// prefetch is scheduled as best-effort task, so that it yields the parents to the higher priority tasks.
+	ctx := common.AppendPriorityClassToOutgoingContext(context.Background(), types.PriorityClassBestEffort)
+	prefetch, err := ptm.getPeerTaskConductor(ctx, taskID, req, limit, nil, nil, desiredLocation, false)
 	if err != nil {
 		logger.Errorf("prefetch peer task %s/%s error: %s", prefetch.taskID, prefetch.peerID, err)
 		return nil
@@ -314,6 +320,11 @@ func (ptm *peerTaskManager) StartFileTask(ctx context.Context, req *FileTaskRequ
 			return progress, nil
 		}
 	}
+
+	if ptm.enabledRangeFanOut(req.Range) {
+		return ptm.startRangeFanOutFileTask(ctx, req)
+	}
+
 	// TODO ensure scheduler is ok first
 	var limit = rate.Inf
 	if ptm.PerPeerRateLimit > 0 {
@@ -349,6 +360,10 @@ func (ptm *peerTaskManager) StartStreamTask(ctx context.Context, req *StreamTask
 		}
 	}
 
+	if ptm.enabledRangeFanOut(req.Range) {
+		return ptm.startRangeFanOutStreamTask(ctx, req)
+	}
+
 	pt, err := ptm.newStreamTask(ctx, peerTaskRequest, req.Range)
 	if err != nil {
 		return nil, nil, err
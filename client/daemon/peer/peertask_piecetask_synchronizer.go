@@ -48,7 +48,9 @@ type pieceTaskSyncManager struct {
 	peerTaskConductor *peerTaskConductor
 	pieceRequestQueue PieceDispatcher
 	workers           map[string]*pieceTaskSynchronizer
-	watchdog          *synchronizerWatchdog
+	// blockedPeers are the peers sent corrupted pieces, they are not synced any more
+	blockedPeers map[string]struct{}
+	watchdog     *synchronizerWatchdog
 }
 
 type pieceTaskSynchronizer struct {
@@ -87,7 +89,7 @@ func (s *pieceTaskSyncManager) syncPeers(destPeers []*schedulerv1.PeerPacket_Des
 		s.Unlock()
 	}()
 
-	peersToKeep, peersToAdd, peersToClose := s.diffPeers(destPeers)
+	peersToKeep, peersToAdd, peersToClose := s.diffPeers(s.filterBlockedPeers(destPeers))
 
 	for _, peer := range peersToAdd {
 		s.newPieceTaskSynchronizer(s.ctx, peer, desiredPiece)
@@ -117,6 +119,41 @@ func (s *pieceTaskSyncManager) syncPeers(destPeers []*schedulerv1.PeerPacket_Des
 	return
 }
 
+// filterBlockedPeers removes the blocked peers, the scheduler may schedule them again before it receives the failed piece results.
+func (s *pieceTaskSyncManager) filterBlockedPeers(peers []*schedulerv1.PeerPacket_DestPeer) []*schedulerv1.PeerPacket_DestPeer {
+	if len(s.blockedPeers) == 0 {
+		return peers
+	}
+
+	var availablePeers []*schedulerv1.PeerPacket_DestPeer
+	for _, p := range peers {
+		if _, ok := s.blockedPeers[p.PeerId]; ok {
+			s.peerTaskConductor.Warnf("peer %s is blocked, skip to sync pieces from it", p.PeerId)
+			continue
+		}
+		availablePeers = append(availablePeers, p)
+	}
+	return availablePeers
+}
+
+// blockPeer blocks the peer which sent corrupted pieces and closes the synchronizer of it.
+func (s *pieceTaskSyncManager) blockPeer(peerID string) {
+	s.Lock()
+	defer s.Unlock()
+	s.blockedPeers[peerID] = struct{}{}
+	if worker, ok := s.workers[peerID]; ok {
+		worker.close()
+		delete(s.workers, peerID)
+	}
+}
+
+func (s *pieceTaskSyncManager) isBlocked(peerID string) bool {
+	s.RLock()
+	defer s.RUnlock()
+	_, ok := s.blockedPeers[peerID]
+	return ok
+}
+
 func (s *pieceTaskSyncManager) diffPeers(peers []*schedulerv1.PeerPacket_DestPeer) (
 	peersToKeep []*schedulerv1.PeerPacket_DestPeer, peersToAdd []*schedulerv1.PeerPacket_DestPeer, peersToClose []string) {
 	if len(s.workers) == 0 {
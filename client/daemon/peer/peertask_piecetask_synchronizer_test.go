@@ -292,3 +292,81 @@ func Test_diffPeers(t *testing.T) {
 		})
 	}
 }
+
+func Test_blockPeer(t *testing.T) {
+	assert := testifyassert.New(t)
+
+	var testCases = []struct {
+		name         string
+		blockedPeers []string
+		peers        []*schedulerv1.PeerPacket_DestPeer
+		expect       []*schedulerv1.PeerPacket_DestPeer
+	}{
+		{
+			name: "no blocked peers",
+			peers: []*schedulerv1.PeerPacket_DestPeer{
+				{
+					PeerId: "peer-0",
+				},
+				{
+					PeerId: "peer-1",
+				},
+			},
+			expect: []*schedulerv1.PeerPacket_DestPeer{
+				{
+					PeerId: "peer-0",
+				},
+				{
+					PeerId: "peer-1",
+				},
+			},
+		},
+		{
+			name:         "filter blocked peers",
+			blockedPeers: []string{"peer-0", "peer-2"},
+			peers: []*schedulerv1.PeerPacket_DestPeer{
+				{
+					PeerId: "peer-0",
+				},
+				{
+					PeerId: "peer-1",
+				},
+			},
+			expect: []*schedulerv1.PeerPacket_DestPeer{
+				{
+					PeerId: "peer-1",
+				},
+			},
+		},
+		{
+			name:         "all peers are blocked",
+			blockedPeers: []string{"peer-0", "peer-1"},
+			peers: []*schedulerv1.PeerPacket_DestPeer{
+				{
+					PeerId: "peer-0",
+				},
+				{
+					PeerId: "peer-1",
+				},
+			},
+			expect: nil,
+		},
+	}
+
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &pieceTaskSyncManager{
+				peerTaskConductor: &peerTaskConductor{
+					SugaredLoggerOnWith: logger.With("test", "blockPeer"),
+				},
+				workers:      map[string]*pieceTaskSynchronizer{},
+				blockedPeers: map[string]struct{}{},
+			}
+			for _, peerID := range tt.blockedPeers {
+				s.blockPeer(peerID)
+				assert.True(s.isBlocked(peerID))
+			}
+			assert.Equal(tt.expect, s.filterBlockedPeers(tt.peers))
+		})
+	}
+}
@@ -0,0 +1,304 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-http-utils/headers"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/protobuf/proto"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+
+	"d7y.io/dragonfly/v2/client/config"
+	"d7y.io/dragonfly/v2/pkg/idgen"
+	"d7y.io/dragonfly/v2/pkg/net/http"
+)
+
+// maxRangeFanOutChunks is the max count of the chunks split from one ranged request,
+// the ranged requests with more chunks, like open-ended ranges, are downloaded as one task.
+const maxRangeFanOutChunks = 64
+
+// rangeChunk is an aligned chunk of the ranged request.
+type rangeChunk struct {
+	rg   http.Range
+	rc   io.ReadCloser
+	attr map[string]string
+}
+
+// alignRange splits the range into the chunks aligned with alignment.
+func alignRange(rg *http.Range, alignment int64) []http.Range {
+	var chunks []http.Range
+	end := rg.Start + rg.Length
+	for start := rg.Start / alignment * alignment; start < end; start += alignment {
+		chunks = append(chunks, http.Range{Start: start, Length: alignment})
+	}
+	return chunks
+}
+
+func (ptm *peerTaskManager) enabledRangeFanOut(rg *http.Range) bool {
+	if ptm.RangeAlignment <= 0 || rg == nil || rg.Length <= 0 {
+		return false
+	}
+
+	first, last := rg.Start/ptm.RangeAlignment, (rg.Start+rg.Length-1)/ptm.RangeAlignment
+	if last-first+1 > maxRangeFanOutChunks {
+		return false
+	}
+
+	// the range is aligned already, download it as normal ranged task
+	return !(first == last && rg.Start == first*ptm.RangeAlignment && rg.Length == ptm.RangeAlignment)
+}
+
+// newRangeChunkRequest generates the request of the aligned chunk, it is a standalone ranged task,
+// so that the same chunk of the different ranged requests is deduplicated by scheduler and seed peer.
+func (ptm *peerTaskManager) newRangeChunkRequest(request *StreamTaskRequest, chunk http.Range) *StreamTaskRequest {
+	meta := proto.Clone(request.URLMeta).(*commonv1.UrlMeta)
+	meta.Range = chunk.URLMetaString()
+	if _, ok := meta.Header[headers.Range]; ok {
+		meta.Header[headers.Range] = chunk.String()
+	}
+
+	return &StreamTaskRequest{
+		URL:     request.URL,
+		URLMeta: meta,
+		Range:   &chunk,
+		PeerID:  idgen.PeerIDV1(ptm.PeerHost.Ip),
+	}
+}
+
+// startRangeFanOutStreamTask starts the aligned chunk tasks of the ranged request concurrently,
+// and returns the reader of the requested range stitched from the chunks.
+func (ptm *peerTaskManager) startRangeFanOutStreamTask(ctx context.Context, request *StreamTaskRequest) (io.ReadCloser, map[string]string, error) {
+	aligned := alignRange(request.Range, ptm.RangeAlignment)
+	chunks := make([]*rangeChunk, len(aligned))
+
+	var eg errgroup.Group
+	for i, rg := range aligned {
+		i, rg := i, rg
+		eg.Go(func() error {
+			rc, attr, err := ptm.StartStreamTask(ctx, ptm.newRangeChunkRequest(request, rg))
+			if err != nil {
+				return fmt.Errorf("start chunk %s error: %w", rg.URLMetaString(), err)
+			}
+
+			chunks[i] = &rangeChunk{rg: rg, rc: rc, attr: attr}
+			return nil
+		})
+	}
+
+	if err := eg.Wait(); err != nil {
+		for _, chunk := range chunks {
+			if chunk != nil {
+				chunk.rc.Close()
+			}
+		}
+		return nil, nil, err
+	}
+
+	rc, length, total := newRangeFanOutReader(request.Range, chunks)
+	attr := map[string]string{}
+	for k, v := range chunks[0].attr {
+		attr[k] = v
+	}
+
+	attr[config.HeaderDragonflyRange] = request.URLMeta.Range
+	if length < 0 {
+		delete(attr, headers.ContentLength)
+		delete(attr, headers.ContentRange)
+		attr[headers.TransferEncoding] = "chunked"
+		return rc, attr, nil
+	}
+
+	delete(attr, headers.TransferEncoding)
+	attr[headers.ContentLength] = strconv.FormatInt(length, 10)
+	attr[headers.ContentRange] = fmt.Sprintf("bytes %d-%d/%s", request.Range.Start, request.Range.Start+length-1, total)
+	return rc, attr, nil
+}
+
+// startRangeFanOutFileTask downloads the ranged request by the aligned chunk tasks and writes it to the output.
+func (ptm *peerTaskManager) startRangeFanOutFileTask(ctx context.Context, request *FileTaskRequest) (chan *FileTaskProgress, error) {
+	rc, attr, err := ptm.startRangeFanOutStreamTask(ctx, &StreamTaskRequest{
+		URL:     request.Url,
+		URLMeta: request.UrlMeta,
+		Range:   request.Range,
+		PeerID:  request.PeerId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	progressCh := make(chan *FileTaskProgress, 1)
+	go func() {
+		defer rc.Close()
+
+		pg := &FileTaskProgress{
+			State: &ProgressState{
+				Success: true,
+				Code:    commonv1.Code_Success,
+				Msg:     "Success",
+			},
+			TaskID:       attr[config.HeaderDragonflyTask],
+			PeerID:       request.PeerId,
+			PeerTaskDone: true,
+			DoneCallback: func() {},
+		}
+
+		n, err := writeRangeFanOutFile(request, rc)
+		if err == nil {
+			if l, ok := attr[headers.ContentLength]; ok && l != strconv.FormatInt(n, 10) {
+				err = fmt.Errorf("written length %d is not same with target length %s", n, l)
+			}
+		}
+
+		if err != nil {
+			pg.State = &ProgressState{
+				Success: false,
+				Code:    commonv1.Code_ClientError,
+				Msg:     err.Error(),
+			}
+		}
+
+		pg.ContentLength, pg.CompletedLength = n, n
+		progressCh <- pg
+	}()
+
+	return progressCh, nil
+}
+
+// writeRangeFanOutFile writes the data of ranged request to the output, keeps the original offset if required.
+func writeRangeFanOutFile(request *FileTaskRequest, r io.Reader) (int64, error) {
+	flag := os.O_CREATE | os.O_RDWR
+	if !request.KeepOriginalOffset {
+		flag |= os.O_TRUNC
+	}
+
+	f, err := os.OpenFile(request.Output, flag, 0644)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	if request.KeepOriginalOffset {
+		if _, err := f.Seek(request.Range.Start, io.SeekStart); err != nil {
+			return 0, err
+		}
+	}
+
+	return io.Copy(f, r)
+}
+
+// newRangeFanOutReader stitches the requested range from the chunks, returns the reader,
+// the length of the range and the total length of the content, the length is -1 when it is unknown,
+// and the total length is "*" when it is unknown.
+func newRangeFanOutReader(rg *http.Range, chunks []*rangeChunk) (io.ReadCloser, int64, string) {
+	var (
+		readers = make([]io.Reader, 0, len(chunks))
+		closers = make([]io.Closer, 0, len(chunks))
+		length  int64
+		total   = "*"
+		end     = rg.Start + rg.Length
+	)
+
+	for _, chunk := range chunks {
+		closers = append(closers, chunk.rc)
+
+		from, to := int64(0), chunk.rg.Length
+		if rg.Start > chunk.rg.Start {
+			from = rg.Start - chunk.rg.Start
+		}
+
+		if end < chunk.rg.Start+chunk.rg.Length {
+			to = end - chunk.rg.Start
+		}
+
+		if cr, ok := chunk.attr[headers.ContentRange]; ok {
+			if i := strings.LastIndex(cr, "/"); i >= 0 && cr[i+1:] != "*" {
+				total = cr[i+1:]
+			}
+		}
+
+		chunkLength := int64(-1)
+		if l, ok := chunk.attr[headers.ContentLength]; ok {
+			if n, err := strconv.ParseInt(l, 10, 64); err == nil {
+				chunkLength = n
+			}
+		}
+
+		// the chunk is truncated by the end of the content
+		if chunkLength >= 0 && chunkLength < to {
+			to = chunkLength
+			total = strconv.FormatInt(chunk.rg.Start+chunkLength, 10)
+		}
+
+		if from > to {
+			from = to
+		}
+
+		if length >= 0 && chunkLength >= 0 {
+			length += to - from
+		} else {
+			length = -1
+		}
+
+		readers = append(readers, &skipReader{Reader: io.LimitReader(chunk.rc, to), skip: from})
+	}
+
+	return &rangeFanOutReadCloser{Reader: io.MultiReader(readers...), closers: closers}, length, total
+}
+
+// skipReader skips the leading bytes of the reader.
+type skipReader struct {
+	io.Reader
+	skip int64
+}
+
+func (s *skipReader) Read(p []byte) (int, error) {
+	if s.skip > 0 {
+		n, err := io.CopyN(io.Discard, s.Reader, s.skip)
+		s.skip -= n
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return s.Reader.Read(p)
+}
+
+// rangeFanOutReadCloser closes the readers of all chunks.
+type rangeFanOutReadCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r *rangeFanOutReadCloser) Close() error {
+	var errs []error
+	for _, closer := range r.closers {
+		if err := closer.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
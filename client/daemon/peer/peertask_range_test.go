@@ -0,0 +1,200 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peer
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/go-http-utils/headers"
+	testifyassert "github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/pkg/net/http"
+)
+
+func TestAlignRange(t *testing.T) {
+	testCases := []struct {
+		name   string
+		rg     http.Range
+		expect []http.Range
+	}{
+		{
+			name:   "range in one chunk",
+			rg:     http.Range{Start: 10, Length: 20},
+			expect: []http.Range{{Start: 0, Length: 100}},
+		},
+		{
+			name:   "range across chunks",
+			rg:     http.Range{Start: 90, Length: 120},
+			expect: []http.Range{{Start: 0, Length: 100}, {Start: 100, Length: 100}, {Start: 200, Length: 100}},
+		},
+		{
+			name:   "aligned range",
+			rg:     http.Range{Start: 100, Length: 200},
+			expect: []http.Range{{Start: 100, Length: 100}, {Start: 200, Length: 100}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := testifyassert.New(t)
+			assert.Equal(tc.expect, alignRange(&tc.rg, 100))
+		})
+	}
+}
+
+func TestPeerTaskManager_enabledRangeFanOut(t *testing.T) {
+	testCases := []struct {
+		name      string
+		alignment int64
+		rg        *http.Range
+		expect    bool
+	}{
+		{
+			name:      "disabled",
+			alignment: 0,
+			rg:        &http.Range{Start: 10, Length: 20},
+			expect:    false,
+		},
+		{
+			name:      "not ranged request",
+			alignment: 100,
+			rg:        nil,
+			expect:    false,
+		},
+		{
+			name:      "unaligned range",
+			alignment: 100,
+			rg:        &http.Range{Start: 10, Length: 20},
+			expect:    true,
+		},
+		{
+			name:      "aligned chunk",
+			alignment: 100,
+			rg:        &http.Range{Start: 100, Length: 100},
+			expect:    false,
+		},
+		{
+			name:      "too many chunks",
+			alignment: 100,
+			rg:        &http.Range{Start: 100, Length: 100 * (maxRangeFanOutChunks + 1)},
+			expect:    false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := testifyassert.New(t)
+			ptm := &peerTaskManager{TaskManagerOption: TaskManagerOption{RangeAlignment: tc.alignment}}
+			assert.Equal(tc.expect, ptm.enabledRangeFanOut(tc.rg))
+		})
+	}
+}
+
+func TestNewRangeFanOutReader(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 25)
+	newChunk := func(start, length int64, attr map[string]string) *rangeChunk {
+		end := start + length
+		if end > int64(len(content)) {
+			end = int64(len(content))
+		}
+		return &rangeChunk{
+			rg:   http.Range{Start: start, Length: length},
+			rc:   io.NopCloser(bytes.NewReader(content[start:end])),
+			attr: attr,
+		}
+	}
+
+	testCases := []struct {
+		name         string
+		rg           http.Range
+		chunks       []*rangeChunk
+		expectLength int64
+		expectTotal  string
+	}{
+		{
+			name: "range across chunks",
+			rg:   http.Range{Start: 90, Length: 20},
+			chunks: []*rangeChunk{
+				newChunk(0, 100, map[string]string{headers.ContentLength: "100"}),
+				newChunk(100, 100, map[string]string{headers.ContentLength: "100", headers.ContentRange: "bytes 100-199/250"}),
+			},
+			expectLength: 20,
+			expectTotal:  "250",
+		},
+		{
+			name: "range truncated by the end of content",
+			rg:   http.Range{Start: 190, Length: 100},
+			chunks: []*rangeChunk{
+				newChunk(100, 100, map[string]string{headers.ContentLength: "100"}),
+				newChunk(200, 100, map[string]string{headers.ContentLength: "50"}),
+			},
+			expectLength: 60,
+			expectTotal:  "250",
+		},
+		{
+			name: "unknown length",
+			rg:   http.Range{Start: 10, Length: 20},
+			chunks: []*rangeChunk{
+				newChunk(0, 100, map[string]string{}),
+			},
+			expectLength: -1,
+			expectTotal:  "*",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := testifyassert.New(t)
+			rc, length, total := newRangeFanOutReader(&tc.rg, tc.chunks)
+			assert.Equal(tc.expectLength, length)
+			assert.Equal(tc.expectTotal, total)
+
+			data, err := io.ReadAll(rc)
+			assert.Nil(err)
+			end := tc.rg.Start + tc.rg.Length
+			if end > int64(len(content)) {
+				end = int64(len(content))
+			}
+			assert.Equal(content[tc.rg.Start:end], data)
+			assert.Nil(rc.Close())
+		})
+	}
+}
+
+func TestWriteRangeFanOutFile(t *testing.T) {
+	assert := testifyassert.New(t)
+	output := path.Join(t.TempDir(), "output")
+
+	request := &FileTaskRequest{Output: output, Range: &http.Range{Start: 4, Length: 4}}
+	n, err := writeRangeFanOutFile(request, bytes.NewBufferString("data"))
+	assert.Nil(err)
+	assert.Equal(int64(4), n)
+	data, err := os.ReadFile(output)
+	assert.Nil(err)
+	assert.Equal("data", string(data))
+
+	request.KeepOriginalOffset = true
+	_, err = writeRangeFanOutFile(request, bytes.NewBufferString("DATA"))
+	assert.Nil(err)
+	data, err = os.ReadFile(output)
+	assert.Nil(err)
+	assert.Equal("dataDATA", string(data))
+}
@@ -22,19 +22,26 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
+	"go.uber.org/atomic"
 	"google.golang.org/grpc/status"
 
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
 
+	"d7y.io/dragonfly/v2/client/config"
 	"d7y.io/dragonfly/v2/client/daemon/storage"
 	logger "d7y.io/dragonfly/v2/internal/dflog"
 	"d7y.io/dragonfly/v2/pkg/digest"
@@ -73,6 +80,15 @@ type PieceDownloaderOption func(*pieceDownloader) error
 type pieceDownloader struct {
 	scheme     string
 	httpClient *http.Client
+	timeout    time.Duration
+
+	// quicClient downloads pieces via http3 over quic, it is nil when quic is disabled
+	quicClient *http.Client
+	// quicEnabled indicates to download pieces via quic, it is switched by the client config of scheduler cluster
+	quicEnabled *atomic.Bool
+	// quicFallbacks records the peers failed to connect via quic,
+	// key is the address of peer and value is the time to retry quic
+	quicFallbacks sync.Map
 }
 
 type pieceDownloadError struct {
@@ -112,6 +128,11 @@ func isBackSourceError(err error) bool {
 	return false
 }
 
+// isPieceDigestError returns whether the piece is corrupted, it is reported by storage or digest reader.
+func isPieceDigestError(err error) bool {
+	return errors.Is(err, storage.ErrPieceDigestNotMatch) || errors.Is(err, digest.ErrEncodedNotMatch)
+}
+
 func (e *pieceDownloadError) Error() string {
 	if e.connectionError {
 		return fmt.Sprintf("connect with %s with error: %s", e.target, e.err)
@@ -142,13 +163,42 @@ var defaultTransport http.RoundTripper = &http.Transport{
 	ExpectContinueTimeout: 2 * time.Second,
 }
 
-func NewPieceDownloader(timeout time.Duration, caCertPool *x509.CertPool) PieceDownloader {
+// quicFallbackPeriod is the period of downloading pieces via tcp from the peer failed to connect via quic.
+const quicFallbackPeriod = 5 * time.Minute
+
+// WithQUIC enables to download pieces via http3 over quic, peers are verified by caCertPool,
+// and not verified when it is nil, which is the same with downloading pieces via plain http.
+func WithQUIC(caCertPool *x509.CertPool) PieceDownloaderOption {
+	return func(pd *pieceDownloader) error {
+		tlsConfig := &tls.Config{RootCAs: caCertPool}
+		if caCertPool == nil {
+			tlsConfig.InsecureSkipVerify = true
+		}
+
+		pd.quicClient = &http.Client{
+			Transport: &http3.RoundTripper{
+				TLSClientConfig: tlsConfig,
+				QuicConfig: &quic.Config{
+					HandshakeIdleTimeout: 2 * time.Second,
+					MaxIdleTimeout:       90 * time.Second,
+					KeepAlivePeriod:      30 * time.Second,
+				},
+			},
+			Timeout: pd.timeout,
+		}
+		pd.quicEnabled = atomic.NewBool(true)
+		return nil
+	}
+}
+
+func NewPieceDownloader(timeout time.Duration, caCertPool *x509.CertPool, opts ...PieceDownloaderOption) PieceDownloader {
 	pd := &pieceDownloader{
 		scheme: "http",
 		httpClient: &http.Client{
 			Transport: defaultTransport,
 			Timeout:   timeout,
 		},
+		timeout: timeout,
 	}
 
 	if caCertPool != nil {
@@ -156,12 +206,78 @@ func NewPieceDownloader(timeout time.Duration, caCertPool *x509.CertPool) PieceD
 		defaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{ClientCAs: caCertPool}
 	}
 
+	for _, opt := range opts {
+		if err := opt(pd); err != nil {
+			logger.Errorf("apply piece downloader option error: %s", err)
+		}
+	}
+
 	return pd
 }
 
+// OnNotify switches downloading pieces via quic by the client config of scheduler cluster.
+func (p *pieceDownloader) OnNotify(data *config.DynconfigData) {
+	if p.quicClient == nil {
+		return
+	}
+
+	for _, scheduler := range data.Schedulers {
+		if len(scheduler.GetSchedulerCluster().GetClientConfig()) == 0 {
+			continue
+		}
+
+		var clientConfig config.SchedulerClusterClientConfig
+		if err := json.Unmarshal(scheduler.SchedulerCluster.ClientConfig, &clientConfig); err != nil {
+			logger.Errorf("unmarshal client config of scheduler cluster %d error: %s", scheduler.SchedulerClusterId, err)
+			continue
+		}
+
+		if p.quicEnabled.Swap(clientConfig.QUIC) != clientConfig.QUIC {
+			logger.Infof("switch downloading pieces via quic to %t by scheduler cluster %d", clientConfig.QUIC, scheduler.SchedulerClusterId)
+		}
+		return
+	}
+}
+
+// useQUIC returns whether to download pieces from the peer via quic.
+func (p *pieceDownloader) useQUIC(addr string) bool {
+	if p.quicClient == nil || !p.quicEnabled.Load() {
+		return false
+	}
+
+	if retryAt, ok := p.quicFallbacks.Load(addr); ok {
+		if time.Now().Before(retryAt.(time.Time)) {
+			return false
+		}
+
+		p.quicFallbacks.Delete(addr)
+	}
+
+	return true
+}
+
 func (p *pieceDownloader) DownloadPiece(ctx context.Context, req *DownloadPieceRequest) (io.Reader, io.Closer, error) {
-	httpRequest := p.buildDownloadPieceHTTPRequest(ctx, req)
-	resp, err := p.httpClient.Do(httpRequest)
+	var (
+		resp *http.Response
+		err  error
+	)
+
+	httpRequest := p.buildDownloadPieceHTTPRequest(ctx, req, p.scheme)
+	if p.useQUIC(req.DstAddr) {
+		quicRequest := p.buildDownloadPieceHTTPRequest(ctx, req, "https")
+		if resp, err = p.quicClient.Do(quicRequest); err == nil || ctx.Err() != nil {
+			httpRequest = quicRequest
+		} else {
+			// the peer may not serve quic or udp is blocked, fall back to tcp
+			logger.Warnf("task id: %s, piece num: %d, dst: %s, download piece via quic failed, fall back to tcp: %s",
+				req.TaskID, req.piece.PieceNum, req.DstAddr, err)
+			p.quicFallbacks.Store(req.DstAddr, time.Now().Add(quicFallbackPeriod))
+			resp, err = p.httpClient.Do(httpRequest)
+		}
+	} else {
+		resp, err = p.httpClient.Do(httpRequest)
+	}
+
 	if err != nil {
 		logger.Errorf("task id: %s, piece num: %d, dst: %s, download piece failed: %s",
 			req.TaskID, req.piece.PieceNum, req.DstAddr, err)
@@ -185,7 +301,8 @@ func (p *pieceDownloader) DownloadPiece(ctx context.Context, req *DownloadPieceR
 	reader, closer := resp.Body.(io.Reader), resp.Body.(io.Closer)
 	if req.CalcDigest {
 		req.log.Debugf("calculate digest for piece %d, digest: %s", req.piece.PieceNum, req.piece.PieceMd5)
-		reader, err = digest.NewReader(digest.AlgorithmMD5, io.LimitReader(resp.Body, int64(req.piece.RangeSize)), digest.WithEncoded(req.piece.PieceMd5), digest.WithLogger(req.log))
+		d := digest.ParsePiece(req.piece.PieceMd5)
+		reader, err = newPieceDigestReader(d.Algorithm, io.LimitReader(resp.Body, int64(req.piece.RangeSize)), digest.WithEncoded(d.Encoded), digest.WithLogger(req.log))
 		if err != nil {
 			_ = closer.Close()
 			req.log.Errorf("init digest reader error: %s", err.Error())
@@ -195,10 +312,10 @@ func (p *pieceDownloader) DownloadPiece(ctx context.Context, req *DownloadPieceR
 	return reader, closer, nil
 }
 
-func (p *pieceDownloader) buildDownloadPieceHTTPRequest(ctx context.Context, d *DownloadPieceRequest) *http.Request {
+func (p *pieceDownloader) buildDownloadPieceHTTPRequest(ctx context.Context, d *DownloadPieceRequest, scheme string) *http.Request {
 	// FIXME switch to https when tls enabled
 	targetURL := url.URL{
-		Scheme:   p.scheme,
+		Scheme:   scheme,
 		Host:     d.DstAddr,
 		Path:     fmt.Sprintf("download/%s/%s", d.TaskID[:3], d.TaskID),
 		RawQuery: fmt.Sprintf("peerId=%s", d.DstPid),
@@ -35,9 +35,12 @@ import (
 	"github.com/stretchr/testify/require"
 
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
+	managerv1 "d7y.io/api/pkg/apis/manager/v1"
 
+	"d7y.io/dragonfly/v2/client/config"
 	"d7y.io/dragonfly/v2/client/daemon/test"
 	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/pkg/digest"
 	nethttp "d7y.io/dragonfly/v2/pkg/net/http"
 	"d7y.io/dragonfly/v2/pkg/source"
 	"d7y.io/dragonfly/v2/pkg/source/clients/httpprotocol"
@@ -154,3 +157,143 @@ func TestPieceDownloader_DownloadPiece(t *testing.T) {
 		server.Close()
 	}
 }
+
+func TestPieceDownloader_DownloadPieceDigest(t *testing.T) {
+	data := []byte("test test ")
+	tests := []struct {
+		name   string
+		digest string
+		expect func(t *testing.T, reader io.Reader, err error)
+	}{
+		{
+			name:   "md5 digest",
+			digest: digest.MD5FromBytes(data),
+			expect: func(t *testing.T, reader io.Reader, err error) {
+				assert := testifyassert.New(t)
+				assert.Nil(err)
+				assert.Equal(digest.MD5FromBytes(data), reader.(digest.Reader).Encoded())
+			},
+		},
+		{
+			name:   "sha256 digest",
+			digest: digest.New(digest.AlgorithmSHA256, digest.SHA256FromStrings(string(data))).String(),
+			expect: func(t *testing.T, reader io.Reader, err error) {
+				assert := testifyassert.New(t)
+				assert.Nil(err)
+				assert.Equal(digest.New(digest.AlgorithmSHA256, digest.SHA256FromStrings(string(data))).String(), reader.(digest.Reader).Encoded())
+			},
+		},
+		{
+			name:   "corrupted piece",
+			digest: digest.New(digest.AlgorithmSHA256, digest.SHA256FromStrings("corrupted")).String(),
+			expect: func(t *testing.T, reader io.Reader, err error) {
+				assert := testifyassert.New(t)
+				assert.True(isPieceDigestError(err))
+			},
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headers.ContentLength, fmt.Sprintf("%d", len(data)))
+		if _, err := w.Write(data); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+	addr, _ := url.Parse(server.URL)
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pd := NewPieceDownloader(30*time.Second, nil)
+			r, c, err := pd.DownloadPiece(context.Background(), &DownloadPieceRequest{
+				TaskID:     "task-0",
+				DstAddr:    addr.Host,
+				CalcDigest: true,
+				piece: &commonv1.PieceInfo{
+					RangeStart: 0,
+					RangeSize:  uint32(len(data)),
+					PieceMd5:   tc.digest,
+				},
+				log: logger.With("test", "test"),
+			})
+			testifyassert.Nil(t, err)
+			defer c.Close()
+
+			_, err = io.ReadAll(r)
+			tc.expect(t, r, err)
+		})
+	}
+}
+
+func TestPieceDownloader_DownloadPieceFallbackFromQUIC(t *testing.T) {
+	assert := testifyassert.New(t)
+	data := []byte("test test ")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(headers.ContentLength, fmt.Sprintf("%d", len(data)))
+		if _, err := w.Write(data); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer server.Close()
+
+	addr, _ := url.Parse(server.URL)
+	pd := NewPieceDownloader(30*time.Second, nil, WithQUIC(nil)).(*pieceDownloader)
+	assert.True(pd.useQUIC(addr.Host))
+
+	r, c, err := pd.DownloadPiece(context.Background(), &DownloadPieceRequest{
+		TaskID:  "task-0",
+		DstAddr: addr.Host,
+		piece: &commonv1.PieceInfo{
+			RangeStart: 0,
+			RangeSize:  uint32(len(data)),
+		},
+		log: logger.With("test", "test"),
+	})
+	assert.Nil(err, "downloaded piece should fall back to tcp")
+
+	result, err := io.ReadAll(r)
+	assert.Nil(err)
+	c.Close()
+	assert.Equal(data, result)
+	assert.False(pd.useQUIC(addr.Host))
+}
+
+func TestPieceDownloader_OnNotify(t *testing.T) {
+	tests := []struct {
+		name         string
+		clientConfig []byte
+		expect       bool
+	}{
+		{
+			name:         "scheduler cluster enables quic",
+			clientConfig: []byte(`{"load_limit":50,"quic":true}`),
+			expect:       true,
+		},
+		{
+			name:         "scheduler cluster disables quic",
+			clientConfig: []byte(`{"load_limit":50}`),
+			expect:       false,
+		},
+		{
+			name:         "scheduler cluster without client config",
+			clientConfig: nil,
+			expect:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert := testifyassert.New(t)
+			pd := NewPieceDownloader(30*time.Second, nil, WithQUIC(nil)).(*pieceDownloader)
+			pd.OnNotify(&config.DynconfigData{
+				Schedulers: []*managerv1.Scheduler{
+					{
+						SchedulerClusterId: 1,
+						SchedulerCluster:   &managerv1.SchedulerCluster{Id: 1, ClientConfig: tt.clientConfig},
+					},
+				},
+			})
+			assert.Equal(tt.expect, pd.useQUIC("127.0.0.1:65002"))
+		})
+	}
+}
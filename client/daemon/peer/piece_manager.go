@@ -61,27 +61,35 @@ type PieceManager interface {
 
 type pieceManager struct {
 	*rate.Limiter
-	pieceDownloader   PieceDownloader
-	computePieceSize  func(contentLength int64) uint32
-	calculateDigest   bool
-	concurrentOption  *config.ConcurrentOption
-	syncPieceViaHTTPS bool
-	certPool          *x509.CertPool
+	pieceDownloader      PieceDownloader
+	computePieceSize     func(contentLength int64) uint32
+	calculateDigest      bool
+	pieceDigestAlgorithm string
+	concurrentOption     *config.ConcurrentOption
+	syncPieceViaHTTPS    bool
+	certPool             *x509.CertPool
+	enableQUIC           bool
 }
 
 type PieceManagerOption func(*pieceManager)
 
 func NewPieceManager(pieceDownloadTimeout time.Duration, opts ...PieceManagerOption) (PieceManager, error) {
 	pm := &pieceManager{
-		computePieceSize: util.ComputePieceSize,
-		calculateDigest:  true,
+		computePieceSize:     util.ComputePieceSize,
+		calculateDigest:      true,
+		pieceDigestAlgorithm: digest.AlgorithmMD5,
 	}
 
 	for _, opt := range opts {
 		opt(pm)
 	}
 
-	pm.pieceDownloader = NewPieceDownloader(pieceDownloadTimeout, pm.certPool)
+	var pdOpts []PieceDownloaderOption
+	if pm.enableQUIC {
+		pdOpts = append(pdOpts, WithQUIC(pm.certPool))
+	}
+
+	pm.pieceDownloader = NewPieceDownloader(pieceDownloadTimeout, pm.certPool, pdOpts...)
 
 	return pm, nil
 }
@@ -93,6 +101,17 @@ func WithCalculateDigest(enable bool) func(*pieceManager) {
 	}
 }
 
+// WithPieceDigestAlgorithm sets the algorithm of digest calculated for the pieces from source.
+func WithPieceDigestAlgorithm(algorithm string) func(*pieceManager) {
+	return func(pm *pieceManager) {
+		if algorithm == "" {
+			return
+		}
+		logger.Infof("set pieceDigestAlgorithm to %s for piece manager", algorithm)
+		pm.pieceDigestAlgorithm = algorithm
+	}
+}
+
 // WithLimiter sets upload rate limiter, the burst size must be bigger than piece size
 func WithLimiter(limiter *rate.Limiter) func(*pieceManager) {
 	return func(manager *pieceManager) {
@@ -166,6 +185,21 @@ func WithSyncPieceViaHTTPS(caCertPEM string) func(*pieceManager) {
 	}
 }
 
+// WithQUICPieceDownloader enables to download pieces via http3 over quic.
+func WithQUICPieceDownloader(enable bool) func(*pieceManager) {
+	return func(pm *pieceManager) {
+		logger.Infof("set quic piece downloader to %t for piece manager", enable)
+		pm.enableQUIC = enable
+	}
+}
+
+// OnNotify switches downloading pieces via quic by the client config of scheduler cluster.
+func (pm *pieceManager) OnNotify(data *config.DynconfigData) {
+	if observer, ok := pm.pieceDownloader.(config.Observer); ok {
+		observer.OnNotify(data)
+	}
+}
+
 func (pm *pieceManager) DownloadPiece(ctx context.Context, request *DownloadPieceRequest) (*DownloadPieceResult, error) {
 	var result = &DownloadPieceResult{
 		Size:       -1,
@@ -259,7 +293,7 @@ func (pm *pieceManager) processPieceFromSource(pt Task,
 	}
 	if pm.calculateDigest {
 		pt.Log().Debugf("piece %d calculate digest", pieceNum)
-		reader, _ = digest.NewReader(digest.AlgorithmMD5, reader, digest.WithLogger(pt.Log()))
+		reader, _ = newPieceDigestReader(pm.pieceDigestAlgorithm, reader, digest.WithLogger(pt.Log()))
 	}
 	var n int64
 	result.Size, err = pt.GetStorage().WritePiece(
@@ -628,7 +662,7 @@ func (pm *pieceManager) processPieceFromFile(ctx context.Context, ptm storage.Pe
 
 	if pm.calculateDigest {
 		log.Debugf("calculate digest in processPieceFromFile")
-		reader, _ = digest.NewReader(digest.AlgorithmMD5, r, digest.WithLogger(log))
+		reader, _ = newPieceDigestReader(pm.pieceDigestAlgorithm, r, digest.WithLogger(log))
 	}
 	n, err := tsd.WritePiece(ctx,
 		&storage.WritePieceRequest{
@@ -947,3 +981,31 @@ func (pm *pieceManager) downloadPieceFromSource(ctx context.Context,
 	pt.PublishPieceInfo(num, uint32(result.Size))
 	return nil
 }
+
+// pieceDigestReader calculates the digest of piece with the given algorithm, the encoded of it
+// is in the form of algorithm:encoded except md5 for compatibility with the legacy peers.
+type pieceDigestReader struct {
+	digest.Reader
+	algorithm string
+}
+
+func newPieceDigestReader(algorithm string, r io.Reader, options ...digest.Option) (digest.Reader, error) {
+	if algorithm == "" {
+		algorithm = digest.AlgorithmMD5
+	}
+
+	reader, err := digest.NewReader(algorithm, r, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pieceDigestReader{
+		Reader:    reader,
+		algorithm: algorithm,
+	}, nil
+}
+
+// Encoded returns the digest string of piece.
+func (r *pieceDigestReader) Encoded() string {
+	return digest.New(r.algorithm, r.Reader.Encoded()).PieceString()
+}
@@ -56,6 +56,7 @@ func genLeafCert(ca *tls.Certificate, leafCertSpec *LeafCertSpec, host string) (
 		NotBefore:             now,
 		NotAfter:              now.Add(24 * time.Hour),
 		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment | x509.KeyUsageDataEncipherment | x509.KeyUsageKeyAgreement,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
 		BasicConstraintsValid: true,
 		SignatureAlgorithm:    leafCertSpec.signatureAlgorithm,
 	}
@@ -76,3 +77,60 @@ func genLeafCert(ca *tls.Certificate, leafCertSpec *LeafCertSpec, host string) (
 	cert.Leaf, _ = x509.ParseCertificate(newCert)
 	return cert, nil
 }
+
+// serverTLSConfig returns the tls.Config used to hijack the tls connections from clients,
+// when the cert is a CA, the leaf cert is generated for the server name of client hello,
+// and host is used if client hello does not carry the server name.
+func (proxy *Proxy) serverTLSConfig(host string) *tls.Config {
+	if proxy.cert.Leaf == nil || !proxy.cert.Leaf.IsCA {
+		return &tls.Config{Certificates: []tls.Certificate{*proxy.cert}}
+	}
+
+	return &tls.Config{
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			serverName := hello.ServerName
+			if serverName == "" {
+				serverName = host
+			}
+
+			return proxy.getLeafCert(serverName)
+		},
+	}
+}
+
+// getLeafCert returns the leaf cert of host signed by CA, the generated certs are cached until they expire.
+func (proxy *Proxy) getLeafCert(host string) (*tls.Certificate, error) {
+	proxy.certCacheLock.Lock()
+	cached, hit := proxy.certCache.Get(host)
+	proxy.certCacheLock.Unlock()
+	// If cache hit and the cert is not expired
+	if hit && time.Now().Before(cached.(*tls.Certificate).Leaf.NotAfter) {
+		logger.Debugf("TLS cert cache hit, cacheKey = <%s>", host)
+		return cached.(*tls.Certificate), nil
+	}
+
+	cert, err, _ := proxy.certGroup.Do(host, func() (any, error) {
+		logger.Debugf("Generate temporal leaf TLS cert for host <%s>", host)
+		cert, err := genLeafCert(proxy.cert, &LeafCertSpec{
+			publicKey:          proxy.cert.Leaf.PublicKey,
+			privateKey:         proxy.cert.PrivateKey,
+			signatureAlgorithm: proxy.cert.Leaf.SignatureAlgorithm,
+		}, host)
+		if err != nil {
+			// Unrecoverable error happened in genLeafCert(...)
+			return nil, err
+		}
+
+		// Put cert in cache only if there is no error. So all certs in cache are always valid.
+		// But certs in cache maybe expired (After 24 hours, see the default duration of generated certs)
+		proxy.certCacheLock.Lock()
+		proxy.certCache.Add(host, cert)
+		proxy.certCacheLock.Unlock()
+		return cert, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cert.(*tls.Certificate), nil
+}
@@ -38,6 +38,7 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/atomic"
 	"golang.org/x/sync/semaphore"
+	"golang.org/x/sync/singleflight"
 
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
 	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
@@ -73,9 +74,12 @@ type Proxy struct {
 	// cert is the certificate used to hijack https proxy requests
 	cert *tls.Certificate
 
-	// certCache is an in-memory cache store for TLS certs used in HTTPS hijack
-	certCache    *lru.Cache
-	cacheRWMutex sync.RWMutex
+	// certCache is an in-memory cache store for TLS certs used in HTTPS hijack,
+	// lru.Cache.Get modifies the cache, so it is guarded by a mutex instead of a rw mutex
+	certCache     *lru.Cache
+	certCacheLock sync.Mutex
+	// certGroup merges the concurrent generations of the TLS cert for the same host
+	certGroup singleflight.Group
 
 	// directHandler are used to handle non-proxy requests
 	directHandler http.Handler
@@ -429,39 +433,9 @@ func (proxy *Proxy) handleHTTPS(w http.ResponseWriter, r *http.Request) {
 
 	logger.Debugf("hijack https request to %s", r.Host)
 
-	sConfig := new(tls.Config)
-	if proxy.cert.Leaf != nil && proxy.cert.Leaf.IsCA {
-		leafCertSpec := LeafCertSpec{
-			proxy.cert.Leaf.PublicKey,
-			proxy.cert.PrivateKey,
-			proxy.cert.Leaf.SignatureAlgorithm}
-		host, _, _ := net.SplitHostPort(r.Host)
-		sConfig.GetCertificate = func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
-			cConfig.ServerName = host
-			// It's assumed that `hello.ServerName` is always same as `host`, in practice.
-			cacheKey := host
-			proxy.cacheRWMutex.RLock()
-			cached, hit := proxy.certCache.Get(cacheKey)
-			proxy.cacheRWMutex.RUnlock()
-			if hit && time.Now().Before(cached.(*tls.Certificate).Leaf.NotAfter) { // If cache hit and the cert is not expired
-				logger.Debugf("TLS cert cache hit, cacheKey = <%s>", cacheKey)
-				return cached.(*tls.Certificate), nil
-			}
-			logger.Debugf("Generate temporal leaf TLS cert for ServerName <%s>, host <%s>", hello.ServerName, host)
-			cert, err := genLeafCert(proxy.cert, &leafCertSpec, host)
-			if err == nil {
-				// Put cert in cache only if there is no error. So all certs in cache are always valid.
-				// But certs in cache maybe expired (After 24 hours, see the default duration of generated certs)
-				proxy.cacheRWMutex.Lock()
-				proxy.certCache.Add(cacheKey, cert)
-				proxy.cacheRWMutex.Unlock()
-			}
-			// If err != nil, means unrecoverable error happened in genLeafCert(...)
-			return cert, err
-		}
-	} else {
-		sConfig.Certificates = []tls.Certificate{*proxy.cert}
-	}
+	host, _, _ := net.SplitHostPort(r.Host)
+	cConfig.ServerName = host
+	sConfig := proxy.serverTLSConfig(host)
 
 	// TODO support http2 by set sConfig.NextProtos = []string{"http/1.1", "h2"}
 	// then check conn.ConnectionState().NegotiatedProtocol in handshake(w, sConfig)
@@ -658,29 +632,34 @@ func tunnelHTTPS(w http.ResponseWriter, r *http.Request) {
 	clientConn, _, err := hijacker.Hijack()
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		dst.Close()
+		return
 	}
 
+	tunnel(clientConn, dst)
+}
+
+// tunnel copies the streams between the hijacked client connection and the destination.
+func tunnel(clientConn net.Conn, dst net.Conn) {
 	wg := sync.WaitGroup{}
 	wg.Add(1)
 	go func() {
 		if _, err := io.Copy(dst, clientConn); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
 			logger.Errorf("copy hijacked stream from client to destination error: %s", err)
 		}
 		wg.Done()
 	}()
 
 	if _, err := io.Copy(clientConn, dst); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
 		logger.Errorf("copy hijacked stream from destination to client error: %s", err)
 	}
 	wg.Wait()
 
 	// Close() will close both read and write, we need wait all stream is done, then close connections
-	if err = dst.Close(); err != nil {
+	if err := dst.Close(); err != nil {
 		logger.Errorf("close hijacked destination error: %s", err)
 	}
-	if err = clientConn.Close(); err != nil {
+	if err := clientConn.Close(); err != nil {
 		logger.Errorf("close hijacked client error: %s", err)
 	}
 }
@@ -17,18 +17,28 @@
 package proxy
 
 import (
+	"bytes"
 	"crypto/tls"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/http/httputil"
+	"strconv"
 	"sync"
 	"time"
 
+	"d7y.io/dragonfly/v2/client/daemon/metrics"
 	logger "d7y.io/dragonfly/v2/internal/dflog"
 )
 
+// clientHelloTimeout is the timeout of reading client hello from the connection.
+const clientHelloTimeout = 10 * time.Second
+
+// errClientHelloPeeked is returned to abort the handshake after the client hello is read.
+var errClientHelloPeeked = errors.New("client hello peeked")
+
 func (proxy *Proxy) ServeSNI(l net.Listener) error {
 	if proxy.cert == nil {
 		return errors.New("empty cert")
@@ -54,6 +64,58 @@ func (proxy *Proxy) ServeSNI(l net.Listener) error {
 	}
 }
 
+// peekClientHello reads the server name from the client hello of conn, the returned conn
+// replays the read bytes, so it can be hijacked or tunneled as a new connection.
+func peekClientHello(conn net.Conn) (string, net.Conn, error) {
+	var (
+		serverName string
+		peeked     bool
+		buf        bytes.Buffer
+	)
+	if err := conn.SetReadDeadline(time.Now().Add(clientHelloTimeout)); err != nil {
+		return "", conn, err
+	}
+	defer conn.SetReadDeadline(time.Time{})
+
+	err := tls.Server(&readOnlyConn{Conn: conn, reader: io.TeeReader(conn, &buf)}, &tls.Config{
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			serverName, peeked = hello.ServerName, true
+			return nil, errClientHelloPeeked
+		},
+	}).Handshake()
+
+	replayConn := &replayConn{Conn: conn, reader: io.MultiReader(&buf, conn)}
+	if !peeked {
+		return "", replayConn, err
+	}
+
+	if serverName == "" {
+		return "", replayConn, errors.New("empty server name in client hello")
+	}
+
+	return serverName, replayConn, nil
+}
+
+// readOnlyConn reads from reader and discards the writes, it is used to peek the client hello.
+type readOnlyConn struct {
+	net.Conn
+	reader io.Reader
+}
+
+func (c *readOnlyConn) Read(p []byte) (int, error) { return c.reader.Read(p) }
+
+func (c *readOnlyConn) Write(p []byte) (int, error) { return 0, io.ErrClosedPipe }
+
+func (c *readOnlyConn) Close() error { return nil }
+
+// replayConn replays the peeked bytes before reading from the underlying conn.
+type replayConn struct {
+	net.Conn
+	reader io.Reader
+}
+
+func (c *replayConn) Read(p []byte) (int, error) { return c.reader.Read(p) }
+
 // handshakeTLSConn performs the TLS handshake.
 func handshakeTLSConn(clientConn net.Conn, config *tls.Config) (net.Conn, error) {
 	conn := tls.Server(clientConn, config)
@@ -66,40 +128,36 @@ func handshakeTLSConn(clientConn net.Conn, config *tls.Config) (net.Conn, error)
 }
 
 func (proxy *Proxy) handleTLSConn(clientConn net.Conn, port int) {
-	var serverName string
-	sConfig := new(tls.Config)
-	if !proxy.cert.Leaf.IsCA {
-		sConfig.Certificates = []tls.Certificate{*proxy.cert}
-	} else {
-		leafCertSpec := LeafCertSpec{
-			proxy.cert.Leaf.PublicKey,
-			proxy.cert.PrivateKey,
-			proxy.cert.Leaf.SignatureAlgorithm}
-		sConfig.GetCertificate = func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
-			// It's assumed that `hello.ServerName` is always same as `host`, in practice.
-			serverName = hello.ServerName
-
-			proxy.cacheRWMutex.RLock()
-			cached, hit := proxy.certCache.Get(serverName)
-			proxy.cacheRWMutex.RUnlock()
-			if hit && time.Now().Before(cached.(*tls.Certificate).Leaf.NotAfter) { // If cache hit and the cert is not expired
-				logger.Debugf("TLS Cache hit, cacheKey = <%s>", serverName)
-				return cached.(*tls.Certificate), nil
-			}
-			logger.Debugf("Generate temporal leaf TLS cert for ServerName <%s>", hello.ServerName)
-			cert, err := genLeafCert(proxy.cert, &leafCertSpec, serverName)
-			if err == nil {
-				// Put cert in cache only if there is no error. So all certs in cache are always valid.
-				// But certs in cache maybe expired (After 24 hours, see the default duration of generated certs)
-				proxy.cacheRWMutex.Lock()
-				proxy.certCache.Add(serverName, cert)
-				proxy.cacheRWMutex.Unlock()
-			}
-			// If err != nil, means unrecoverable error happened in genLeafCert(...)
-			return cert, err
+	serverName, clientConn, err := peekClientHello(clientConn)
+	if err != nil {
+		logger.Errorf("read client hello error: %s", err)
+		clientConn.Close()
+		return
+	}
+
+	// hijack rules may match the host with port as the CONNECT requests
+	hostPort := net.JoinHostPort(serverName, strconv.Itoa(port))
+	cConfig := proxy.remoteConfig(hostPort)
+	if cConfig == nil {
+		cConfig = proxy.remoteConfig(serverName)
+	}
+
+	// the hosts not matched by hijack rules are tunneled to the origin server without terminating tls
+	if len(proxy.httpsHosts) > 0 && cConfig == nil {
+		logger.Debugf("hijackHTTPS hosts not match, tunneling sni request for %s", serverName)
+		metrics.ProxyRequestNotViaDragonflyCount.Add(1)
+		dst, err := net.DialTimeout("tcp", hostPort, 10*time.Second)
+		if err != nil {
+			logger.Errorf("dial failed for %s: %v", serverName, err)
+			clientConn.Close()
+			return
 		}
+
+		tunnel(clientConn, dst)
+		return
 	}
 
+	sConfig := proxy.serverTLSConfig(serverName)
 	tlsConn, err := handshakeTLSConn(clientConn, sConfig)
 	if err != nil {
 		logger.Errorf("handshake failed for %s: %v", serverName, err)
@@ -122,7 +180,7 @@ func (proxy *Proxy) handleTLSConn(clientConn net.Conn, port int) {
 				}
 			}
 		},
-		Transport: proxy.newTransport(proxy.remoteConfig(serverName)),
+		Transport: proxy.newTransport(cConfig),
 	}
 
 	// We have to wait until the connection is closed
@@ -17,11 +17,22 @@
 package proxy
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"fmt"
+	"math/big"
+	"net"
 	"net/http"
 	"net/url"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/golang/groupcache/lru"
 	"github.com/stretchr/testify/assert"
 
 	"d7y.io/dragonfly/v2/client/config"
@@ -235,3 +246,93 @@ func TestMatchWithRedirect(t *testing.T) {
 		TestMirror(t)
 
 }
+
+func newTestCA(t *testing.T) *tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "dragonfly test ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func TestProxy_getLeafCert(t *testing.T) {
+	assert := assert.New(t)
+	ca := newTestCA(t)
+	proxy := &Proxy{cert: ca, certCache: lru.New(100)}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Leaf)
+
+	var (
+		wg    sync.WaitGroup
+		certs = make([]*tls.Certificate, 8)
+	)
+	for i := range certs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cert, err := proxy.getLeafCert("index.docker.io")
+			assert.Nil(err)
+			certs[i] = cert
+		}(i)
+	}
+	wg.Wait()
+
+	cert, err := proxy.getLeafCert("index.docker.io")
+	assert.Nil(err)
+	assert.Same(certs[0], cert)
+	_, err = cert.Leaf.Verify(x509.VerifyOptions{DNSName: "index.docker.io", Roots: roots})
+	assert.Nil(err)
+
+	ipCert, err := proxy.getLeafCert("127.0.0.1")
+	assert.Nil(err)
+	assert.NotSame(cert, ipCert)
+	_, err = ipCert.Leaf.Verify(x509.VerifyOptions{DNSName: "127.0.0.1", Roots: roots})
+	assert.Nil(err)
+}
+
+func TestProxy_peekClientHello(t *testing.T) {
+	assert := assert.New(t)
+	ca := newTestCA(t)
+	proxy := &Proxy{cert: ca, certCache: lru.New(100)}
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.Leaf)
+
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	defer serverConn.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		conn := tls.Client(clientConn, &tls.Config{ServerName: "index.docker.io", RootCAs: roots})
+		done <- conn.Handshake()
+	}()
+
+	serverName, conn, err := peekClientHello(serverConn)
+	assert.Nil(err)
+	assert.Equal("index.docker.io", serverName)
+
+	// the peeked client hello is replayed, so the handshake is able to go on with the returned conn
+	tlsConn := tls.Server(conn, proxy.serverTLSConfig(serverName))
+	assert.Nil(tlsConn.Handshake())
+	assert.Nil(<-done)
+}
@@ -19,6 +19,7 @@ package storage
 import (
 	"errors"
 	"os"
+	"time"
 )
 
 const (
@@ -27,6 +28,10 @@ const (
 
 	defaultFileMode      = os.FileMode(0644)
 	defaultDirectoryMode = os.FileMode(0755)
+
+	// correlatedAccessPeriod is the period in which the accesses of task are correlated,
+	// e.g. reading the pieces of a download, and they are recorded as one access of lru-k.
+	correlatedAccessPeriod = time.Minute
 )
 
 var (
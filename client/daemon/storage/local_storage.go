@@ -17,6 +17,7 @@
 package storage
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -54,6 +55,19 @@ type localTaskStore struct {
 	reclaimMarked atomic.Bool
 	gcCallback    func(CommonTaskRequest)
 
+	// accessHistorySize is the max number of uncorrelated accesses recorded for lru-k disk gc policy
+	// and promoting task to hot tier, the access history is not recorded if it is zero
+	accessHistorySize int
+	accessHistoryLock sync.Mutex
+	// accessHistory is the time of the recent uncorrelated accesses, the latest is the last
+	accessHistory []int64
+
+	// cold indicates the task data is demoted to cold tier, and the task data in hot tier is a symbol link to it
+	cold     atomic.Bool
+	tierLock sync.Mutex
+	// pieceCache is the memory tier caching the recently read pieces, it is nil if memory tier is disabled
+	pieceCache *pieceCache
+
 	// when digest not match, invalid will be set
 	invalid atomic.Bool
 
@@ -69,6 +83,41 @@ var _ Reclaimer = (*localTaskStore)(nil)
 func (t *localTaskStore) touch() {
 	access := time.Now().UnixNano()
 	t.lastAccess.Store(access)
+	t.recordAccess(access)
+}
+
+// recordAccess records the access into access history, the access correlated with
+// the latest access is merged into it.
+func (t *localTaskStore) recordAccess(access int64) {
+	if t.accessHistorySize <= 0 {
+		return
+	}
+
+	t.accessHistoryLock.Lock()
+	defer t.accessHistoryLock.Unlock()
+
+	if n := len(t.accessHistory); n > 0 && access-t.accessHistory[n-1] < int64(correlatedAccessPeriod) {
+		t.accessHistory[n-1] = access
+		return
+	}
+
+	t.accessHistory = append(t.accessHistory, access)
+	if len(t.accessHistory) > t.accessHistorySize {
+		t.accessHistory = t.accessHistory[len(t.accessHistory)-t.accessHistorySize:]
+	}
+}
+
+// kthAccess returns the time of the k-th most recent uncorrelated access,
+// it returns false if the task is accessed less than k times.
+func (t *localTaskStore) kthAccess(k int) (int64, bool) {
+	t.accessHistoryLock.Lock()
+	defer t.accessHistoryLock.Unlock()
+
+	if k < 1 || len(t.accessHistory) < k {
+		return 0, false
+	}
+
+	return t.accessHistory[len(t.accessHistory)-k], true
 }
 
 func (t *localTaskStore) SubTask(req *RegisterSubTaskRequest) *localSubTaskStore {
@@ -159,6 +208,15 @@ func (t *localTaskStore) WritePiece(ctx context.Context, req *WritePieceRequest)
 		}
 	}
 
+	// io.LimitReader stops reading before the digest reader reaches io.EOF,
+	// so validate the digest of piece here before saving the metadata
+	if req.PieceMetadata.Md5 != "" {
+		if get, ok := req.Reader.(digest.Reader); ok && get.Encoded() != req.PieceMetadata.Md5 {
+			t.Errorf("piece %d digest not match, desired: %s, actual: %s", req.PieceMetadata.Num, req.PieceMetadata.Md5, get.Encoded())
+			return n, ErrPieceDigestNotMatch
+		}
+	}
+
 	// when Md5 is empty, try to get md5 from reader, it's useful for back source
 	if req.PieceMetadata.Md5 == "" {
 		t.Debugf("piece %d md5 not found in metadata, read from reader", req.PieceMetadata.Num)
@@ -278,10 +336,6 @@ func (t *localTaskStore) ReadPiece(ctx context.Context, req *ReadPieceRequest) (
 	}
 
 	t.touch()
-	file, err := os.Open(t.DataFilePath)
-	if err != nil {
-		return nil, nil, err
-	}
 
 	// If req.Num is equal to -1, range has a fixed value.
 	if req.Num != -1 {
@@ -291,12 +345,21 @@ func (t *localTaskStore) ReadPiece(ctx context.Context, req *ReadPieceRequest) (
 			req.Range = piece.Range
 		} else {
 			t.RUnlock()
-			file.Close()
 			t.Errorf("invalid piece num: %d", req.Num)
 			return nil, nil, ErrPieceNotFound
 		}
 	}
 
+	// Only the pieces of completed task are cached, the data of them never changes.
+	if t.pieceCache != nil && t.Done && req.Range.Length <= t.pieceCache.capacity {
+		return t.readCachedPiece(req)
+	}
+
+	file, err := os.Open(t.DataFilePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	if _, err = file.Seek(req.Range.Start, io.SeekStart); err != nil {
 		file.Close()
 		t.Errorf("file seek failed: %v", err)
@@ -306,6 +369,35 @@ func (t *localTaskStore) ReadPiece(ctx context.Context, req *ReadPieceRequest) (
 	return io.LimitReader(file, req.Range.Length), file, nil
 }
 
+// readCachedPiece reads the piece from memory tier, the piece is read from task data
+// and cached if it is not in memory tier.
+func (t *localTaskStore) readCachedPiece(req *ReadPieceRequest) (io.Reader, io.Closer, error) {
+	key := pieceCacheKey{
+		taskID: t.TaskID,
+		peerID: t.PeerID,
+		start:  req.Range.Start,
+		length: req.Range.Length,
+	}
+	if data, ok := t.pieceCache.get(key); ok {
+		return bytes.NewReader(data), io.NopCloser(nil), nil
+	}
+
+	file, err := os.Open(t.DataFilePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	data := make([]byte, req.Range.Length)
+	if _, err := file.ReadAt(data, req.Range.Start); err != nil {
+		t.Errorf("read piece failed: %v", err)
+		return nil, nil, err
+	}
+
+	t.pieceCache.add(key, data)
+	return bytes.NewReader(data), io.NopCloser(nil), nil
+}
+
 func (t *localTaskStore) ReadAllPieces(ctx context.Context, req *ReadAllPiecesRequest) (io.ReadCloser, error) {
 	if t.invalid.Load() {
 		t.Errorf("invalid digest, refuse to read all pieces")
@@ -542,6 +634,10 @@ func (t *localTaskStore) MarkReclaim() {
 
 func (t *localTaskStore) Reclaim() error {
 	t.Infof("start gc task data")
+	if t.pieceCache != nil {
+		t.pieceCache.deleteTask(t.TaskID, t.PeerID)
+	}
+
 	err := t.reclaimData()
 	if err != nil && !os.IsNotExist(err) {
 		return err
@@ -576,6 +672,9 @@ func (t *localTaskStore) Reclaim() error {
 }
 
 func (t *localTaskStore) reclaimData() error {
+	t.tierLock.Lock()
+	defer t.tierLock.Unlock()
+
 	// remove data
 	data := path.Join(t.dataDir, taskData)
 	stat, err := os.Lstat(data)
@@ -592,6 +691,11 @@ func (t *localTaskStore) reclaimData() error {
 			} else {
 				t.Infof("remove data file %s", dest)
 			}
+
+			// remove the task directories in cold tier
+			if t.cold.Load() {
+				removeEmptyDirs(path.Dir(dest), 2)
+			}
 		}
 	} else { // remove cache file
 		if err = os.Remove(t.DataFilePath); err != nil && !os.IsNotExist(err) {
@@ -107,6 +107,15 @@ func (t *localSubTaskStore) WritePiece(ctx context.Context, req *WritePieceReque
 		}
 	}
 
+	// io.LimitReader stops reading before the digest reader reaches io.EOF,
+	// so validate the digest of piece here before saving the metadata
+	if req.PieceMetadata.Md5 != "" {
+		if get, ok := req.Reader.(digest.Reader); ok && get.Encoded() != req.PieceMetadata.Md5 {
+			t.Errorf("piece %d digest not match, desired: %s, actual: %s", req.PieceMetadata.Num, req.PieceMetadata.Md5, get.Encoded())
+			return n, ErrPieceDigestNotMatch
+		}
+	}
+
 	// when Md5 is empty, try to get md5 from reader, it's useful for back source
 	if req.PieceMetadata.Md5 == "" {
 		t.Debugf("piece %d md5 not found in metadata, read from reader", req.PieceMetadata.Num)
@@ -582,3 +582,318 @@ func TestLocalTaskStore_CanReclaim(t *testing.T) {
 		})
 	}
 }
+
+func TestLocalTaskStore_recordAccess(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		name     string
+		size     int
+		accesses []time.Duration
+		expect   []int64
+	}{
+		{
+			name:     "access history is not recorded",
+			size:     0,
+			accesses: []time.Duration{0, 2 * time.Minute},
+			expect:   nil,
+		},
+		{
+			name:     "correlated accesses are merged",
+			size:     2,
+			accesses: []time.Duration{0, 10 * time.Second, 20 * time.Second},
+			expect:   []int64{now.Add(20 * time.Second).UnixNano()},
+		},
+		{
+			name:     "oldest accesses are dropped",
+			size:     2,
+			accesses: []time.Duration{0, 2 * time.Minute, 4 * time.Minute},
+			expect:   []int64{now.Add(2 * time.Minute).UnixNano(), now.Add(4 * time.Minute).UnixNano()},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := testifyassert.New(t)
+			lts := &localTaskStore{accessHistorySize: tc.size}
+			for _, access := range tc.accesses {
+				lts.recordAccess(now.Add(access).UnixNano())
+			}
+
+			assert.Equal(tc.expect, lts.accessHistory)
+			for k := 1; k <= len(tc.expect); k++ {
+				access, ok := lts.kthAccess(k)
+				assert.True(ok)
+				assert.Equal(tc.expect[len(tc.expect)-k], access)
+			}
+
+			_, ok := lts.kthAccess(len(tc.expect) + 1)
+			assert.False(ok)
+		})
+	}
+}
+
+func TestStorageManager_sortReclaimTasks(t *testing.T) {
+	now := time.Now()
+	newTask := func(id string, accesses ...time.Duration) *localTaskStore {
+		lts := &localTaskStore{
+			persistentMetadata: persistentMetadata{TaskID: id},
+			accessHistorySize:  2,
+		}
+		for _, access := range accesses {
+			lts.lastAccess.Store(now.Add(access).UnixNano())
+			lts.recordAccess(now.Add(access).UnixNano())
+		}
+
+		return lts
+	}
+
+	testCases := []struct {
+		name   string
+		policy config.DiskGCPolicy
+		expect []string
+	}{
+		{
+			name:   "lru",
+			policy: config.DiskGCPolicyLRU,
+			expect: []string{"frequent", "once", "recent-once", "twice"},
+		},
+		{
+			name:   "lru-k",
+			policy: config.DiskGCPolicyLRUK,
+			expect: []string{"once", "recent-once", "frequent", "twice"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := testifyassert.New(t)
+			s := &storageManager{storeOption: &config.StorageOption{DiskGCPolicy: tc.policy, DiskGCLRUK: 2}}
+			tasks := []*localTaskStore{
+				newTask("twice", -30*time.Minute, -time.Minute),
+				newTask("recent-once", -5*time.Minute),
+				newTask("frequent", -60*time.Minute, -50*time.Minute, -40*time.Minute),
+				newTask("once", -20*time.Minute),
+			}
+
+			s.sortReclaimTasks(tasks)
+			var ids []string
+			for _, task := range tasks {
+				ids = append(ids, task.TaskID)
+			}
+			assert.Equal(tc.expect, ids)
+		})
+	}
+}
+
+func TestStorageManager_diskQuotaExceed(t *testing.T) {
+	testCases := []struct {
+		name        string
+		option      config.StorageOption
+		size        int64
+		expectOK    bool
+		expectBytes int64
+	}{
+		{
+			name:     "disk gc threshold is not set",
+			option:   config.StorageOption{},
+			size:     100,
+			expectOK: false,
+		},
+		{
+			name:     "disk gc threshold is not exceeded",
+			option:   config.StorageOption{DiskGCThreshold: 100},
+			size:     100,
+			expectOK: false,
+		},
+		{
+			name:        "reclaim to disk gc threshold",
+			option:      config.StorageOption{DiskGCThreshold: 100},
+			size:        120,
+			expectOK:    true,
+			expectBytes: 20,
+		},
+		{
+			name:        "reclaim to disk gc low threshold",
+			option:      config.StorageOption{DiskGCThreshold: 100, DiskGCLowThreshold: 60},
+			size:        120,
+			expectOK:    true,
+			expectBytes: 60,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := testifyassert.New(t)
+			s := &storageManager{storeOption: &tc.option}
+			ok, n := s.diskQuotaExceed(tc.size)
+			assert.Equal(tc.expectOK, ok)
+			assert.Equal(tc.expectBytes, n)
+		})
+	}
+}
+
+func TestPieceCache(t *testing.T) {
+	assert := testifyassert.New(t)
+	cache := newPieceCache(10)
+	keyA := pieceCacheKey{taskID: "task", peerID: "peer", start: 0, length: 4}
+	keyB := pieceCacheKey{taskID: "task", peerID: "peer", start: 4, length: 4}
+	keyC := pieceCacheKey{taskID: "other", peerID: "peer", start: 0, length: 4}
+
+	cache.add(keyA, []byte("aaaa"))
+	cache.add(keyB, []byte("bbbb"))
+	data, ok := cache.get(keyA)
+	assert.True(ok)
+	assert.Equal([]byte("aaaa"), data)
+
+	// keyB is the least recently read piece.
+	cache.add(keyC, []byte("cccc"))
+	_, ok = cache.get(keyB)
+	assert.False(ok)
+	assert.Equal(int64(8), cache.size)
+
+	// Piece larger than capacity is not cached.
+	cache.add(pieceCacheKey{taskID: "large"}, make([]byte, 11))
+	assert.Equal(2, cache.entries.Len())
+
+	cache.deleteTask("task", "peer")
+	_, ok = cache.get(keyA)
+	assert.False(ok)
+	_, ok = cache.get(keyC)
+	assert.True(ok)
+	assert.Equal(int64(4), cache.size)
+}
+
+func TestLocalTaskStore_demoteAndPromote(t *testing.T) {
+	assert := testifyassert.New(t)
+	hotDir, coldDir := t.TempDir(), t.TempDir()
+	dataDir := path.Join(hotDir, "task", "peer")
+	assert.Nil(os.MkdirAll(dataDir, defaultDirectoryMode))
+	data := path.Join(dataDir, taskData)
+	assert.Nil(os.WriteFile(data, []byte("hello"), defaultFileMode))
+
+	lts := &localTaskStore{
+		persistentMetadata: persistentMetadata{TaskID: "task", PeerID: "peer", DataFilePath: data},
+		dataDir:            dataDir,
+		accessHistory:      []int64{1, 2},
+	}
+	s := &storageManager{storeOption: &config.StorageOption{
+		DataPath: hotDir,
+		Tiered:   config.TieredStorageOption{ColdDataPath: coldDir},
+	}}
+	coldFilePath := s.coldDataFilePath(lts)
+	assert.Equal(path.Join(coldDir, "task", "peer", taskData), coldFilePath)
+
+	assert.Nil(lts.demote(coldFilePath))
+	assert.True(lts.cold.Load())
+	assert.Nil(lts.accessHistory)
+	stat, err := os.Lstat(data)
+	assert.Nil(err)
+	assert.True(stat.Mode()&os.ModeSymlink == os.ModeSymlink)
+	content, err := os.ReadFile(data)
+	assert.Nil(err)
+	assert.Equal("hello", string(content))
+
+	assert.Nil(lts.promote())
+	assert.False(lts.cold.Load())
+	stat, err = os.Lstat(data)
+	assert.Nil(err)
+	assert.True(stat.Mode().IsRegular())
+	content, err = os.ReadFile(data)
+	assert.Nil(err)
+	assert.Equal("hello", string(content))
+	_, err = os.Stat(path.Join(coldDir, "task"))
+	assert.True(os.IsNotExist(err))
+}
+
+func TestStorageManager_rebalanceTiers(t *testing.T) {
+	assert := testifyassert.New(t)
+	hotDir, coldDir := t.TempDir(), t.TempDir()
+	s := &storageManager{storeOption: &config.StorageOption{
+		DataPath:     hotDir,
+		DiskGCPolicy: config.DiskGCPolicyLRU,
+		Tiered: config.TieredStorageOption{
+			Enable:             true,
+			ColdDataPath:       coldDir,
+			HotQuota:           15,
+			PromoteAccessCount: 2,
+			PromoteWindow:      clientutil.Duration{Duration: time.Hour},
+		},
+	}}
+
+	now := time.Now()
+	newTask := func(id string, lastAccess time.Time) *localTaskStore {
+		dataDir := path.Join(hotDir, id, "peer")
+		assert.Nil(os.MkdirAll(dataDir, defaultDirectoryMode))
+		assert.Nil(os.WriteFile(path.Join(dataDir, taskData), []byte("0123456789"), defaultFileMode))
+		lts := &localTaskStore{
+			persistentMetadata: persistentMetadata{
+				TaskID:        id,
+				PeerID:        "peer",
+				StoreStrategy: string(config.SimpleLocalTaskStoreStrategy),
+				ContentLength: 10,
+				Done:          true,
+			},
+			dataDir:             dataDir,
+			accessHistorySize:   2,
+			SugaredLoggerOnWith: logger.With("task", id),
+		}
+		lts.lastAccess.Store(lastAccess.UnixNano())
+		s.tasks.Store(PeerTaskMetadata{TaskID: id, PeerID: "peer"}, lts)
+		return lts
+	}
+
+	older := newTask("older", now.Add(-time.Hour))
+	newer := newTask("newer", now)
+	s.rebalanceTiers()
+	assert.True(older.cold.Load())
+	assert.False(newer.cold.Load())
+
+	// The cold task is accessed frequently, but the hot quota does not allow to promote it.
+	older.recordAccess(now.Add(-10 * time.Minute).UnixNano())
+	older.recordAccess(now.UnixNano())
+	s.rebalanceTiers()
+	assert.True(older.cold.Load())
+
+	s.tasks.Delete(PeerTaskMetadata{TaskID: "newer", PeerID: "peer"})
+	s.rebalanceTiers()
+	assert.False(older.cold.Load())
+}
+
+func TestLocalTaskStore_WritePieceDigestNotMatch(t *testing.T) {
+	assert := testifyassert.New(t)
+	data := path.Join(t.TempDir(), taskData)
+	assert.Nil(os.WriteFile(data, make([]byte, 10), defaultFileMode))
+
+	lts := &localTaskStore{
+		SugaredLoggerOnWith: logger.With("test", "localTaskStore"),
+		persistentMetadata: persistentMetadata{
+			TaskID:       "task",
+			PeerID:       "peer",
+			Pieces:       map[int32]PieceMetadata{},
+			DataFilePath: data,
+		},
+	}
+
+	newRequest := func(md5 string) *WritePieceRequest {
+		reader, err := digest.NewReader(digest.AlgorithmMD5, bytes.NewBufferString("0123456789"))
+		assert.Nil(err)
+		return &WritePieceRequest{
+			PeerTaskMetadata: PeerTaskMetadata{TaskID: "task", PeerID: "peer"},
+			PieceMetadata: PieceMetadata{
+				Num:   0,
+				Md5:   md5,
+				Range: http.Range{Start: 0, Length: 10},
+			},
+			Reader: reader,
+		}
+	}
+
+	_, err := lts.WritePiece(context.Background(), newRequest(calcPieceMd5([]byte("9876543210"))))
+	assert.ErrorIs(err, ErrPieceDigestNotMatch)
+	assert.Empty(lts.Pieces)
+
+	n, err := lts.WritePiece(context.Background(), newRequest(calcPieceMd5([]byte("0123456789"))))
+	assert.Nil(err)
+	assert.Equal(int64(10), n)
+	assert.Len(lts.Pieces, 1)
+}
@@ -0,0 +1,111 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"container/list"
+	"sync"
+)
+
+// pieceCacheKey is the key of piece cached in memory tier.
+type pieceCacheKey struct {
+	taskID string
+	peerID string
+	start  int64
+	length int64
+}
+
+// pieceCacheEntry is the piece cached in memory tier.
+type pieceCacheEntry struct {
+	key  pieceCacheKey
+	data []byte
+}
+
+// pieceCache is the memory tier of storage, it caches the recently read pieces
+// of completed tasks and evicts the least recently read pieces when it is full.
+type pieceCache struct {
+	mu       sync.Mutex
+	capacity int64
+	size     int64
+	entries  *list.List
+	items    map[pieceCacheKey]*list.Element
+}
+
+// newPieceCache returns a piece cache which caches the pieces up to capacity bytes.
+func newPieceCache(capacity int64) *pieceCache {
+	return &pieceCache{
+		capacity: capacity,
+		entries:  list.New(),
+		items:    map[pieceCacheKey]*list.Element{},
+	}
+}
+
+// get returns the data of cached piece.
+func (c *pieceCache) get(key pieceCacheKey) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	c.entries.MoveToFront(elem)
+	return elem.Value.(*pieceCacheEntry).data, true
+}
+
+// add caches the data of piece, the piece larger than capacity is not cached.
+func (c *pieceCache) add(key pieceCacheKey, data []byte) {
+	if int64(len(data)) > c.capacity {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.entries.MoveToFront(elem)
+		return
+	}
+
+	c.items[key] = c.entries.PushFront(&pieceCacheEntry{key: key, data: data})
+	c.size += int64(len(data))
+	for c.size > c.capacity {
+		c.remove(c.entries.Back())
+	}
+}
+
+// deleteTask removes the cached pieces of task.
+func (c *pieceCache) deleteTask(taskID, peerID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for elem := c.entries.Front(); elem != nil; {
+		next := elem.Next()
+		if key := elem.Value.(*pieceCacheEntry).key; key.taskID == taskID && key.peerID == peerID {
+			c.remove(elem)
+		}
+		elem = next
+	}
+}
+
+// remove removes the element from cache, the caller must hold the lock.
+func (c *pieceCache) remove(elem *list.Element) {
+	entry := c.entries.Remove(elem).(*pieceCacheEntry)
+	delete(c.items, entry.key)
+	c.size -= int64(len(entry.data))
+}
@@ -114,6 +114,10 @@ var (
 	ErrDigestNotSet     = errors.New("digest not set")
 	ErrInvalidDigest    = errors.New("invalid digest")
 	ErrBadRequest       = errors.New("bad request")
+
+	// ErrPieceDigestNotMatch represents the content of piece is not match the digest in metadata,
+	// the piece is not saved and the parent peer is considered as corrupted.
+	ErrPieceDigestNotMatch = errors.New("piece digest not match")
 )
 
 const (
@@ -136,6 +140,7 @@ type storageManager struct {
 	dataPathStat       *syscall.Stat_t
 	gcCallback         func(CommonTaskRequest)
 	gcInterval         time.Duration
+	pieceCache         *pieceCache
 
 	indexRWMutex       sync.RWMutex
 	indexTask2PeerTask map[string][]*localTaskStore // key: task id, value: slice of localTaskStore
@@ -192,6 +197,10 @@ func NewStorageManager(storeStrategy config.StoreStrategy, opt *config.StorageOp
 		}
 	}
 
+	if s.storeOption.Tiered.MemoryCacheSize > 0 {
+		s.pieceCache = newPieceCache(int64(s.storeOption.Tiered.MemoryCacheSize))
+	}
+
 	if err := s.ReloadPersistentTask(gcCallback); err != nil {
 		logger.Warnf("reload tasks error: %s", err)
 	}
@@ -401,11 +410,13 @@ func (s *storageManager) CreateTask(req *RegisterTaskRequest) (TaskStorageDriver
 			PeerID:        req.PeerID,
 			Pieces:        map[int32]PieceMetadata{},
 		},
-		gcCallback:       s.gcCallback,
-		dataDir:          dataDir,
-		metadataFilePath: path.Join(dataDir, taskMetadata),
-		expireTime:       s.storeOption.TaskExpireTime.Duration,
-		subtasks:         map[PeerTaskMetadata]*localSubTaskStore{},
+		gcCallback:        s.gcCallback,
+		dataDir:           dataDir,
+		metadataFilePath:  path.Join(dataDir, taskMetadata),
+		expireTime:        s.storeOption.TaskExpireTime.Duration,
+		accessHistorySize: s.accessHistorySize(),
+		pieceCache:        s.pieceCache,
+		subtasks:          map[PeerTaskMetadata]*localSubTaskStore{},
 
 		SugaredLoggerOnWith: logger.With("task", req.TaskID, "peer", req.PeerID, "component", "localTaskStore"),
 	}
@@ -681,6 +692,8 @@ func (s *storageManager) ReloadPersistentTask(gcCallback GCCallback) error {
 				dataDir:             dataDir,
 				metadataFilePath:    path.Join(dataDir, taskMetadata),
 				expireTime:          s.storeOption.TaskExpireTime.Duration,
+				accessHistorySize:   s.accessHistorySize(),
+				pieceCache:          s.pieceCache,
 				gcCallback:          gcCallback,
 				SugaredLoggerOnWith: logger.With("task", taskID, "peer", peerID, "component", s.storeStrategy),
 			}
@@ -712,6 +725,13 @@ func (s *storageManager) ReloadPersistentTask(gcCallback GCCallback) error {
 					Warnf("load task from disk error: %s, data base64 encode: %s", err0, base64.StdEncoding.EncodeToString(bytes))
 				continue
 			}
+			// the data of simple strategy task is a symbol link only if it is demoted to cold tier
+			if t.StoreStrategy == string(config.SimpleLocalTaskStoreStrategy) {
+				if stat, err := os.Lstat(path.Join(dataDir, taskData)); err == nil && stat.Mode()&os.ModeSymlink == os.ModeSymlink {
+					t.cold.Store(true)
+				}
+			}
+
 			logger.Debugf("load task %s/%s from disk, metadata %s, last access: %v, expire time: %s",
 				t.persistentMetadata.TaskID, t.persistentMetadata.PeerID, t.metadataFilePath, time.Unix(0, t.lastAccess.Load()), t.expireTime)
 			s.tasks.Store(PeerTaskMetadata{
@@ -792,8 +812,7 @@ func (s *storageManager) TryGC() (bool, error) {
 		return true
 	})
 
-	quotaBytesExceed := totalNotMarkedSize - int64(s.storeOption.DiskGCThreshold)
-	quotaExceed := s.storeOption.DiskGCThreshold > 0 && quotaBytesExceed > 0
+	quotaExceed, quotaBytesExceed := s.diskQuotaExceed(totalNotMarkedSize)
 	usageExceed, usageBytesExceed := s.diskUsageExceed()
 
 	if quotaExceed || usageExceed {
@@ -822,10 +841,7 @@ func (s *storageManager) TryGC() (bool, error) {
 			tasks = append(tasks, task)
 			return true
 		})
-		// sort by access time
-		sort.SliceStable(tasks, func(i, j int) bool {
-			return tasks[i].lastAccess.Load() < tasks[j].lastAccess.Load()
-		})
+		s.sortReclaimTasks(tasks)
 		for _, task := range tasks {
 			task.MarkReclaim()
 			markedTasks = append(markedTasks, PeerTaskMetadata{task.PeerID, task.TaskID})
@@ -880,6 +896,10 @@ func (s *storageManager) TryGC() (bool, error) {
 	}
 	logger.Infof("marked %d task(s), reclaimed %d task(s)", len(markedTasks), len(s.markedReclaimTasks))
 	s.markedReclaimTasks = markedTasks
+
+	if s.storeOption.Tiered.Enable {
+		s.rebalanceTiers()
+	}
 	return true, nil
 }
 
@@ -925,6 +945,21 @@ func (s *storageManager) forceGC() (bool, error) {
 	return true, nil
 }
 
+// diskQuotaExceed returns whether the quota of all tasks exceeds DiskGCThreshold,
+// and the bytes to reclaim to get below DiskGCLowThreshold.
+func (s *storageManager) diskQuotaExceed(size int64) (exceed bool, bytes int64) {
+	if s.storeOption.DiskGCThreshold <= 0 || size <= int64(s.storeOption.DiskGCThreshold) {
+		return false, 0
+	}
+
+	low := s.storeOption.DiskGCThreshold
+	if s.storeOption.DiskGCLowThreshold > 0 {
+		low = s.storeOption.DiskGCLowThreshold
+	}
+
+	return true, size - int64(low)
+}
+
 func (s *storageManager) diskUsageExceed() (exceed bool, bytes int64) {
 	if s.storeOption.DiskGCThresholdPercent <= 0 {
 		return false, 0
@@ -939,8 +974,55 @@ func (s *storageManager) diskUsageExceed() (exceed bool, bytes int64) {
 		return false, 0
 	}
 
-	bs := (usage.UsedPercent - s.storeOption.DiskGCThresholdPercent) * float64(usage.Total) / 100.0
-	logger.Infof("disk used percent %f, exceed threshold percent %f, %d bytes to reclaim",
-		usage.UsedPercent, s.storeOption.DiskGCThresholdPercent, int64(bs))
+	low := s.storeOption.DiskGCThresholdPercent
+	if s.storeOption.DiskGCLowThresholdPercent > 0 {
+		low = s.storeOption.DiskGCLowThresholdPercent
+	}
+
+	bs := (usage.UsedPercent - low) * float64(usage.Total) / 100.0
+	logger.Infof("disk used percent %f, exceed threshold percent %f, %d bytes to reclaim to low threshold percent %f",
+		usage.UsedPercent, s.storeOption.DiskGCThresholdPercent, int64(bs), low)
 	return true, int64(bs)
 }
+
+// accessHistorySize returns the size of access history recorded by tasks, it is k of lru-k disk gc policy,
+// or the access count to promote the task to hot tier if it is larger.
+func (s *storageManager) accessHistorySize() int {
+	var size int
+	if s.storeOption.DiskGCPolicy == config.DiskGCPolicyLRUK {
+		size = s.storeOption.DiskGCLRUK
+	}
+
+	if s.storeOption.Tiered.Enable && s.storeOption.Tiered.PromoteAccessCount > size {
+		size = s.storeOption.Tiered.PromoteAccessCount
+	}
+
+	return size
+}
+
+// sortReclaimTasks sorts the tasks in the order of reclaiming by disk gc policy.
+func (s *storageManager) sortReclaimTasks(tasks []*localTaskStore) {
+	if s.storeOption.DiskGCPolicy != config.DiskGCPolicyLRUK {
+		// sort by access time
+		sort.SliceStable(tasks, func(i, j int) bool {
+			return tasks[i].lastAccess.Load() < tasks[j].lastAccess.Load()
+		})
+		return
+	}
+
+	// The tasks accessed less than k times have infinite backward k-distance, they are
+	// reclaimed first by access time, then the others by the time of k-th most recent access.
+	k := s.storeOption.DiskGCLRUK
+	sort.SliceStable(tasks, func(i, j int) bool {
+		ki, oki := tasks[i].kthAccess(k)
+		kj, okj := tasks[j].kthAccess(k)
+		switch {
+		case !oki && !okj:
+			return tasks[i].lastAccess.Load() < tasks[j].lastAccess.Load()
+		case oki != okj:
+			return !oki
+		default:
+			return ki < kj
+		}
+	})
+}
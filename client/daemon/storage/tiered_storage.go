@@ -0,0 +1,246 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/docker/go-units"
+
+	"d7y.io/dragonfly/v2/client/config"
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+)
+
+// rebalanceTiers demotes the completed tasks chosen by disk gc policy to cold tier until the data in hot tier
+// is below the hot quota, then promotes the tasks in cold tier accessed frequently if the hot quota allows.
+func (s *storageManager) rebalanceTiers() {
+	var (
+		hotSize       int64
+		hotTasks      []*localTaskStore
+		coldTasks     []*localTaskStore
+		hotQuota      = int64(s.storeOption.Tiered.HotQuota)
+		promoteSince  = time.Now().Add(-s.storeOption.Tiered.PromoteWindow.Duration).UnixNano()
+		promoteAccess = s.storeOption.Tiered.PromoteAccessCount
+	)
+
+	s.tasks.Range(func(_, val any) bool {
+		task, ok := val.(*localTaskStore)
+		if !ok || task.StoreStrategy != string(config.SimpleLocalTaskStoreStrategy) || task.reclaimMarked.Load() {
+			return true
+		}
+
+		if task.cold.Load() {
+			if task.accessCount(promoteSince) >= promoteAccess {
+				coldTasks = append(coldTasks, task)
+			}
+			return true
+		}
+
+		hotSize += task.ContentLength
+		if task.Done && !task.invalid.Load() {
+			hotTasks = append(hotTasks, task)
+		}
+		return true
+	})
+
+	if hotSize > hotQuota {
+		s.sortReclaimTasks(hotTasks)
+		for _, task := range hotTasks {
+			if hotSize <= hotQuota {
+				break
+			}
+
+			if err := task.demote(s.coldDataFilePath(task)); err != nil {
+				task.Errorf("demote task to cold tier error: %s", err)
+				continue
+			}
+
+			hotSize -= task.ContentLength
+			task.Infof("task is demoted to cold tier, size: %s", units.BytesSize(float64(task.ContentLength)))
+		}
+	}
+
+	// Promote the most recently accessed tasks first.
+	sort.SliceStable(coldTasks, func(i, j int) bool {
+		return coldTasks[i].lastAccess.Load() > coldTasks[j].lastAccess.Load()
+	})
+
+	for _, task := range coldTasks {
+		if hotSize+task.ContentLength > hotQuota {
+			continue
+		}
+
+		if err := task.promote(); err != nil {
+			task.Errorf("promote task to hot tier error: %s", err)
+			continue
+		}
+
+		hotSize += task.ContentLength
+		task.Infof("task is promoted to hot tier, size: %s", units.BytesSize(float64(task.ContentLength)))
+	}
+}
+
+// coldDataFilePath returns the path of task data in cold tier, the layout of cold tier is the same as data path.
+func (s *storageManager) coldDataFilePath(t *localTaskStore) string {
+	rel, err := filepath.Rel(s.storeOption.DataPath, t.dataDir)
+	if err != nil {
+		rel = path.Join(t.TaskID, t.PeerID)
+	}
+
+	return path.Join(s.storeOption.Tiered.ColdDataPath, rel, taskData)
+}
+
+// demote moves the task data to cold tier, and replaces the task data in hot tier by the symbol link to it.
+// The access history is reset, so the task is promoted only if it is accessed frequently after demoting.
+func (t *localTaskStore) demote(coldFilePath string) error {
+	t.tierLock.Lock()
+	defer t.tierLock.Unlock()
+
+	if t.cold.Load() || t.reclaimMarked.Load() {
+		return nil
+	}
+
+	if err := os.MkdirAll(path.Dir(coldFilePath), defaultDirectoryMode); err != nil {
+		return err
+	}
+
+	data := path.Join(t.dataDir, taskData)
+	if err := copyFile(data, coldFilePath+".tmp"); err != nil {
+		os.Remove(coldFilePath + ".tmp")
+		return err
+	}
+
+	if err := os.Rename(coldFilePath+".tmp", coldFilePath); err != nil {
+		os.Remove(coldFilePath + ".tmp")
+		return err
+	}
+
+	// Replace the task data atomically, the readers opened task data keep reading the hot data.
+	os.Remove(data + ".tmp")
+	if err := os.Symlink(coldFilePath, data+".tmp"); err != nil {
+		os.Remove(coldFilePath)
+		return err
+	}
+
+	if err := os.Rename(data+".tmp", data); err != nil {
+		os.Remove(data + ".tmp")
+		os.Remove(coldFilePath)
+		return err
+	}
+
+	t.cold.Store(true)
+	t.accessHistoryLock.Lock()
+	t.accessHistory = nil
+	t.accessHistoryLock.Unlock()
+	return nil
+}
+
+// promote moves the task data back to hot tier.
+func (t *localTaskStore) promote() error {
+	t.tierLock.Lock()
+	defer t.tierLock.Unlock()
+
+	if !t.cold.Load() || t.reclaimMarked.Load() {
+		return nil
+	}
+
+	data := path.Join(t.dataDir, taskData)
+	coldFilePath, err := os.Readlink(data)
+	if err != nil {
+		return err
+	}
+
+	if err := copyFile(coldFilePath, data+".tmp"); err != nil {
+		os.Remove(data + ".tmp")
+		return err
+	}
+
+	if err := os.Rename(data+".tmp", data); err != nil {
+		os.Remove(data + ".tmp")
+		return err
+	}
+
+	t.cold.Store(false)
+	if err := os.Remove(coldFilePath); err != nil && !os.IsNotExist(err) {
+		t.Warnf("remove task data %s in cold tier error: %s", coldFilePath, err)
+	}
+	removeEmptyDirs(path.Dir(coldFilePath), 2)
+	return nil
+}
+
+// accessCount returns the number of uncorrelated accesses since the time.
+func (t *localTaskStore) accessCount(since int64) int {
+	t.accessHistoryLock.Lock()
+	defer t.accessHistoryLock.Unlock()
+
+	var count int
+	for _, access := range t.accessHistory {
+		if access >= since {
+			count++
+		}
+	}
+
+	return count
+}
+
+// copyFile copies the file and syncs it to disk.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, defaultFileMode)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
+
+// removeEmptyDirs removes the directory and its parents up to depth if they are empty.
+func removeEmptyDirs(dir string, depth int) {
+	for i := 0; i < depth; i++ {
+		entries, err := os.ReadDir(dir)
+		if err != nil || len(entries) != 0 {
+			return
+		}
+
+		if err := os.Remove(dir); err != nil {
+			logger.Warnf("remove empty directory %s error: %s", dir, err)
+			return
+		}
+
+		dir = path.Dir(dir)
+	}
+}
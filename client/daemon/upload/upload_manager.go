@@ -20,10 +20,16 @@ package upload
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"fmt"
 	"io"
 	"math"
+	"math/big"
 	"net"
 	"net/http"
 	"strings"
@@ -34,6 +40,7 @@ import (
 	"github.com/go-http-utils/headers"
 	"github.com/johanbrandhorst/certify"
 	ginprometheus "github.com/mcuadros/go-gin-prometheus"
+	"github.com/quic-go/quic-go/http3"
 	"github.com/soheilhy/cmux"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 	"golang.org/x/time/rate"
@@ -70,6 +77,9 @@ type uploadManager struct {
 	*rate.Limiter
 	storageManager storage.Manager
 	certify        *certify.Certify
+
+	// quicServer serves the pieces via http3 over quic, it is nil when quic is disabled.
+	quicServer *http3.Server
 }
 
 // Option is a functional option for configuring the upload manager.
@@ -88,6 +98,15 @@ func WithCertify(ct *certify.Certify) func(manager *uploadManager) {
 	}
 }
 
+// WithQUIC serves the pieces via http3 over quic on the udp port same with upload port.
+func WithQUIC(enable bool) func(manager *uploadManager) {
+	return func(manager *uploadManager) {
+		if enable {
+			manager.quicServer = &http3.Server{}
+		}
+	}
+}
+
 // New returns a new Manager instence.
 func NewUploadManager(cfg *config.DaemonOption, storageManager storage.Manager, logDir string, opts ...Option) (Manager, error) {
 	um := &uploadManager{
@@ -103,11 +122,21 @@ func NewUploadManager(cfg *config.DaemonOption, storageManager storage.Manager,
 		opt(um)
 	}
 
+	if um.quicServer != nil {
+		um.quicServer.Handler = router
+	}
+
 	return um, nil
 }
 
 // Started upload manager server.
 func (um *uploadManager) Serve(listener net.Listener) error {
+	if um.quicServer != nil {
+		if err := um.serveQUIC(listener.Addr()); err != nil {
+			return err
+		}
+	}
+
 	if um.certify == nil {
 		return um.Server.Serve(listener)
 	}
@@ -140,8 +169,52 @@ func (um *uploadManager) Serve(listener net.Listener) error {
 	return m.Serve()
 }
 
+// serveQUIC serves the pieces via http3 over quic on the udp address same with the tcp address.
+func (um *uploadManager) serveQUIC(addr net.Addr) error {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return fmt.Errorf("invalid upload tcp address %s", addr)
+	}
+
+	tlsConfig := &tls.Config{}
+	if um.certify != nil {
+		tlsConfig.GetCertificate = func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			// FIXME peers need pure ip cert, certify checks the ServerName, so workaround here
+			hello.ServerName = "peer"
+			return um.certify.GetCertificate(hello)
+		}
+	} else {
+		// quic requires tls, peers do not verify the certificate without ca, same with plain http
+		cert, err := generateSelfSignedCert()
+		if err != nil {
+			return err
+		}
+		tlsConfig.Certificates = []tls.Certificate{*cert}
+	}
+	um.quicServer.TLSConfig = http3.ConfigureTLSConfig(tlsConfig)
+
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: tcpAddr.IP, Port: tcpAddr.Port})
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		logger.Infof("serve upload service via quic at udp://%s", conn.LocalAddr().String())
+		if err := um.quicServer.Serve(conn); err != nil {
+			logger.Debugf("upload quic server exit: %s", err)
+		}
+	}()
+	return nil
+}
+
 // Stop upload manager server.
 func (um *uploadManager) Stop() error {
+	if um.quicServer != nil {
+		if err := um.quicServer.Close(); err != nil {
+			logger.Errorf("upload quic server close error: %s", err)
+		}
+	}
+
 	return um.Server.Shutdown(context.Background())
 }
 
@@ -268,3 +341,32 @@ func (um *uploadManager) getDownload(ctx *gin.Context) {
 		return
 	}
 }
+
+// generateSelfSignedCert generates the self-signed certificate for serving quic.
+func generateSelfSignedCert() (*tls.Certificate, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject:      pkix.Name{CommonName: "peer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().AddDate(10, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, nil
+}
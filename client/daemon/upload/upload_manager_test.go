@@ -19,6 +19,7 @@ package upload
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
 	"fmt"
 	"io"
 	"net"
@@ -27,6 +28,7 @@ import (
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/quic-go/quic-go/http3"
 	testifyassert "github.com/stretchr/testify/assert"
 	"golang.org/x/time/rate"
 
@@ -104,3 +106,50 @@ func TestUploadManager_Serve(t *testing.T) {
 		assert.Equal(tt.targetPieceData, data)
 	}
 }
+
+func TestUploadManager_ServeQUIC(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	assert := testifyassert.New(t)
+	testData, err := os.ReadFile(test.File)
+	assert.Nil(err, "load test file")
+
+	mockStorageManager := mocks.NewMockManager(ctrl)
+	mockStorageManager.EXPECT().ReadPiece(gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(ctx context.Context, req *storage.ReadPieceRequest) (io.Reader, io.Closer, error) {
+			return bytes.NewBuffer(testData[req.Range.Start : req.Range.Start+req.Range.Length]),
+				io.NopCloser(nil), nil
+		})
+
+	um, err := NewUploadManager(config.NewDaemonConfig(), mockStorageManager, os.TempDir(),
+		WithLimiter(rate.NewLimiter(16*1024, 16*1024)), WithQUIC(true))
+	assert.Nil(err, "NewUploadManager")
+
+	listen, err := net.Listen("tcp4", "127.0.0.1:0")
+	assert.Nil(err, "Listen")
+	addr := listen.Addr().String()
+
+	go func() {
+		if err := um.Serve(listen); err != nil && err != http.ErrServerClosed {
+			t.Error(err)
+		}
+	}()
+	defer um.Stop()
+
+	client := &http.Client{
+		Transport: &http3.RoundTripper{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+	}
+
+	req, _ := http.NewRequest(http.MethodGet,
+		fmt.Sprintf("https://%s/%s/%s/%s?peerId=%s", addr, "download", "666", "task-0", "peer-0"), nil)
+	req.Header.Add("Range", "bytes=512-1023")
+
+	resp, err := client.Do(req)
+	assert.Nil(err, "get piece data via quic")
+
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(testData[512:1024], data)
+	assert.Equal(3, resp.ProtoMajor)
+}
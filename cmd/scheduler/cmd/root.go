@@ -130,6 +130,6 @@ func runScheduler(ctx context.Context, d dfpath.Dfpath) error {
 		return err
 	}
 
-	dependency.SetupQuitSignalHandler(func() { svr.Stop() })
+	dependency.SetupQuitSignalHandler(func() { svr.Drain() })
 	return svr.Serve()
 }
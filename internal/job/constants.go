@@ -24,7 +24,8 @@ const (
 
 // Job Name.
 const (
-	PreheatJob = "preheat"
+	PreheatJob  = "preheat"
+	GCDryRunJob = "gc_dry_run"
 )
 
 // Machinery server configuration.
@@ -104,7 +104,9 @@ type GroupJobState struct {
 	GroupUUID string
 	State     string
 	CreatedAt time.Time
-	JobStates []*machineryv1tasks.TaskState
+	// ScheduledAt is the time to run the last job of group, it is nil if jobs run immediately.
+	ScheduledAt *time.Time `json:",omitempty"`
+	JobStates   []*machineryv1tasks.TaskState
 }
 
 func (t *Job) GetGroupJobState(groupID string) (*GroupJobState, error) {
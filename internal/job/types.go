@@ -16,6 +16,8 @@
 
 package job
 
+import "time"
+
 type PreheatRequest struct {
 	URL         string            `json:"url" validate:"required,url"`
 	Tag         string            `json:"tag" validate:"omitempty"`
@@ -28,3 +30,29 @@ type PreheatRequest struct {
 
 type PreheatResponse struct {
 }
+
+// GCDryRunRequest overrides the gc policy of scheduler, the ttls are in seconds
+// and the zero values fall back to the gc policy in use.
+type GCDryRunRequest struct {
+	PeerTTL      uint32 `json:"peer_ttl" validate:"omitempty"`
+	TaskTTL      uint32 `json:"task_ttl" validate:"omitempty"`
+	HostTTL      uint32 `json:"host_ttl" validate:"omitempty"`
+	MaxTaskCount uint32 `json:"max_task_count" validate:"omitempty"`
+}
+
+// GCDryRunResponse reports the peers, tasks and hosts reclaimed by the gc policy,
+// the ids are truncated and the counts are exact.
+type GCDryRunResponse struct {
+	Hostname     string        `json:"hostname"`
+	IP           string        `json:"ip"`
+	PeerTTL      time.Duration `json:"peer_ttl"`
+	TaskTTL      time.Duration `json:"task_ttl"`
+	HostTTL      time.Duration `json:"host_ttl"`
+	MaxTaskCount int           `json:"max_task_count"`
+	PeerCount    int           `json:"peer_count"`
+	TaskCount    int           `json:"task_count"`
+	HostCount    int           `json:"host_count"`
+	PeerIDs      []string      `json:"peer_ids"`
+	TaskIDs      []string      `json:"task_ids"`
+	HostIDs      []string      `json:"host_ids"`
+}
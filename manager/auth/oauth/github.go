@@ -20,6 +20,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"fmt"
 
 	"github.com/google/go-github/github"
 	"golang.org/x/oauth2"
@@ -74,6 +75,7 @@ func (g *oauthGithub) GetUser(token *oauth2.Token) (*User, error) {
 	}
 
 	return &User{
+		ID:     fmt.Sprint(user.GetID()),
 		Name:   *user.Name,
 		Email:  *user.Email,
 		Avatar: *user.AvatarURL,
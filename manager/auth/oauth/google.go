@@ -79,6 +79,7 @@ func (g *oauthGoogle) GetUser(token *oauth2.Token) (*User, error) {
 	}
 
 	return &User{
+		ID:     user.Id,
 		Name:   user.Name,
 		Email:  user.Email,
 		Avatar: user.Picture,
@@ -32,12 +32,23 @@ const (
 const (
 	Google = "google"
 	Github = "github"
+	OIDC   = "oidc"
+)
+
+const (
+	// DefaultGroupsClaim is the default claim of user groups in oidc userinfo.
+	DefaultGroupsClaim = "groups"
 )
 
 type User struct {
+	// ID is the subject of user in the identity provider, it identifies the user in the provider.
+	ID     string
 	Name   string
 	Email  string
 	Avatar string
+
+	// Groups are the groups of user, only oidc provides them.
+	Groups []string
 }
 
 type Oauth interface {
@@ -50,13 +61,53 @@ type oauth struct {
 	Oauth Oauth
 }
 
-func New(name, clientID, clientSecret, redirectURL string) (Oauth, error) {
+// Option is a functional option for configuring the oauth.
+type Option func(o *options)
+
+// options are the options of oauth.
+type options struct {
+	// issuerURL is the issuer url of oidc provider.
+	issuerURL string
+
+	// groupsClaim is the claim of user groups in oidc userinfo.
+	groupsClaim string
+}
+
+// WithIssuerURL sets the issuer url of oidc provider.
+func WithIssuerURL(issuerURL string) Option {
+	return func(o *options) {
+		o.issuerURL = issuerURL
+	}
+}
+
+// WithGroupsClaim sets the claim of user groups in oidc userinfo.
+func WithGroupsClaim(groupsClaim string) Option {
+	return func(o *options) {
+		if groupsClaim != "" {
+			o.groupsClaim = groupsClaim
+		}
+	}
+}
+
+func New(name, clientID, clientSecret, redirectURL string, opts ...Option) (Oauth, error) {
+	options := &options{
+		groupsClaim: DefaultGroupsClaim,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+
 	var o Oauth
 	switch name {
 	case Google:
 		o = newGoogle(name, clientID, clientSecret, redirectURL)
 	case Github:
 		o = newGithub(name, clientID, clientSecret, redirectURL)
+	case OIDC:
+		var err error
+		if o, err = newOIDC(clientID, clientSecret, redirectURL, options.issuerURL, options.groupsClaim); err != nil {
+			return nil, err
+		}
 	default:
 		return nil, errors.New("invalid oauth name")
 	}
@@ -0,0 +1,209 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+// oidcDiscoveryPath is the path of oidc discovery document relative to the issuer url,
+// refer to https://openid.net/specs/openid-connect-discovery-1_0.html.
+const oidcDiscoveryPath = "/.well-known/openid-configuration"
+
+var oidcScopes = []string{
+	"openid",
+	"profile",
+	"email",
+	"groups",
+}
+
+// oidcDiscovery is the discovery document of oidc provider.
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+type oauthOIDC struct {
+	*oauth2.Config
+
+	// userinfoEndpoint is the userinfo endpoint of oidc provider.
+	userinfoEndpoint string
+
+	// groupsClaim is the claim of user groups in userinfo.
+	groupsClaim string
+}
+
+func newOIDC(clientID, clientSecret, redirectURL, issuerURL, groupsClaim string) (*oauthOIDC, error) {
+	if issuerURL == "" {
+		return nil, errors.New("invalid oidc issuer url")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	discovery, err := discoverOIDC(ctx, issuerURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &oauthOIDC{
+		Config: &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			Scopes:       oidcScopes,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  discovery.AuthorizationEndpoint,
+				TokenURL: discovery.TokenEndpoint,
+			},
+			RedirectURL: redirectURL,
+		},
+		userinfoEndpoint: discovery.UserinfoEndpoint,
+		groupsClaim:      groupsClaim,
+	}, nil
+}
+
+// discoverOIDC fetches the discovery document of oidc provider.
+func discoverOIDC(ctx context.Context, issuerURL string) (*oidcDiscovery, error) {
+	issuerURL = strings.TrimSuffix(issuerURL, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, issuerURL+oidcDiscoveryPath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oidc discovery failed: %s", resp.Status)
+	}
+
+	discovery := &oidcDiscovery{}
+	if err := json.NewDecoder(resp.Body).Decode(discovery); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSuffix(discovery.Issuer, "/") != issuerURL {
+		return nil, fmt.Errorf("oidc issuer %s does not match %s", discovery.Issuer, issuerURL)
+	}
+
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.UserinfoEndpoint == "" {
+		return nil, errors.New("oidc discovery misses endpoints")
+	}
+
+	return discovery, nil
+}
+
+func (o *oauthOIDC) AuthCodeURL() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return o.Config.AuthCodeURL(base64.URLEncoding.EncodeToString(b)), nil
+}
+
+func (o *oauthOIDC) Exchange(code string) (*oauth2.Token, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	return o.Config.Exchange(ctx, code)
+}
+
+// GetUser gets the user from the userinfo endpoint, which is authenticated
+// by the access token, so the id token needs no verification.
+func (o *oauthOIDC) GetUser(token *oauth2.Token) (*User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, o.userinfoEndpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := o.Client(ctx, token).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get oidc userinfo failed: %s", resp.Status)
+	}
+
+	claims := map[string]any{}
+	if err := json.NewDecoder(resp.Body).Decode(&claims); err != nil {
+		return nil, err
+	}
+
+	user := &User{
+		ID:     claimString(claims, "sub"),
+		Name:   claimString(claims, "preferred_username"),
+		Email:  claimString(claims, "email"),
+		Avatar: claimString(claims, "picture"),
+		Groups: claimStrings(claims, o.groupsClaim),
+	}
+
+	if user.Name == "" {
+		user.Name = claimString(claims, "name")
+	}
+
+	if user.ID == "" || user.Name == "" || user.Email == "" {
+		return nil, errors.New("oidc userinfo misses sub, name or email")
+	}
+
+	return user, nil
+}
+
+// claimString returns the string claim, or empty string if the claim is not a string.
+func claimString(claims map[string]any, key string) string {
+	value, _ := claims[key].(string)
+	return value
+}
+
+// claimStrings returns the claim of string array, a single string is
+// regarded as an array with one element.
+func claimStrings(claims map[string]any, key string) []string {
+	switch value := claims[key].(type) {
+	case string:
+		return []string{value}
+	case []any:
+		var values []string
+		for _, v := range value {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+
+		return values
+	default:
+		return nil
+	}
+}
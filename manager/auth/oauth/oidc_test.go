@@ -0,0 +1,139 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package oauth
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOIDC(t *testing.T) {
+	tests := []struct {
+		name     string
+		userinfo string
+		issuer   func(url string) string
+		expect   func(t *testing.T, o Oauth, err error)
+	}{
+		{
+			name:     "get user with groups",
+			userinfo: `{"sub":"1","preferred_username":"foo","email":"foo@example.com","picture":"http://example.com/foo.png","groups":["admin","dev"]}`,
+			expect: func(t *testing.T, o Oauth, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+
+				authURL, err := o.AuthCodeURL()
+				assert.NoError(err)
+				assert.Contains(authURL, "/auth?")
+				assert.Contains(authURL, "scope=openid+profile+email+groups")
+
+				token, err := o.Exchange("bar")
+				assert.NoError(err)
+				user, err := o.GetUser(token)
+				assert.NoError(err)
+				assert.Equal(&User{ID: "1", Name: "foo", Email: "foo@example.com", Avatar: "http://example.com/foo.png", Groups: []string{"admin", "dev"}}, user)
+			},
+		},
+		{
+			name:     "get user with single group and name",
+			userinfo: `{"sub":"1","name":"foo","email":"foo@example.com","groups":"admin"}`,
+			expect: func(t *testing.T, o Oauth, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+
+				token, err := o.Exchange("bar")
+				assert.NoError(err)
+				user, err := o.GetUser(token)
+				assert.NoError(err)
+				assert.Equal(&User{ID: "1", Name: "foo", Email: "foo@example.com", Groups: []string{"admin"}}, user)
+			},
+		},
+		{
+			name:     "get user without email",
+			userinfo: `{"sub":"1","preferred_username":"foo"}`,
+			expect: func(t *testing.T, o Oauth, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+
+				token, err := o.Exchange("bar")
+				assert.NoError(err)
+				_, err = o.GetUser(token)
+				assert.EqualError(err, "oidc userinfo misses sub, name or email")
+			},
+		},
+		{
+			name:     "get user without sub",
+			userinfo: `{"preferred_username":"foo","email":"foo@example.com"}`,
+			expect: func(t *testing.T, o Oauth, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+
+				token, err := o.Exchange("bar")
+				assert.NoError(err)
+				_, err = o.GetUser(token)
+				assert.EqualError(err, "oidc userinfo misses sub, name or email")
+			},
+		},
+		{
+			name: "issuer mismatch",
+			issuer: func(url string) string {
+				return "https://example.com"
+			},
+			expect: func(t *testing.T, o Oauth, err error) {
+				assert := assert.New(t)
+				assert.ErrorContains(err, "does not match")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var server *httptest.Server
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				switch r.URL.Path {
+				case oidcDiscoveryPath:
+					issuer := server.URL
+					if tc.issuer != nil {
+						issuer = tc.issuer(server.URL)
+					}
+
+					fmt.Fprintf(w, `{"issuer":%q,"authorization_endpoint":"%s/auth","token_endpoint":"%s/token","userinfo_endpoint":"%s/userinfo"}`,
+						issuer, server.URL, server.URL, server.URL)
+				case "/token":
+					fmt.Fprint(w, `{"access_token":"baz","token_type":"Bearer"}`)
+				case "/userinfo":
+					if r.Header.Get("Authorization") != "Bearer baz" {
+						w.WriteHeader(http.StatusUnauthorized)
+						return
+					}
+
+					fmt.Fprint(w, tc.userinfo)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			o, err := New(OIDC, "foo", "bar", "http://127.0.0.1:8080/callback", WithIssuerURL(server.URL+"/"), WithGroupsClaim(""))
+			tc.expect(t, o, err)
+		})
+	}
+}
@@ -57,6 +57,12 @@ type Config struct {
 
 	// Network configuration.
 	Network NetworkConfig `yaml:"network" mapstructure:"network"`
+
+	// Audit configuration.
+	Audit AuditConfig `yaml:"audit" mapstructure:"audit"`
+
+	// Webhook configuration.
+	Webhook WebhookConfig `yaml:"webhook" mapstructure:"webhook"`
 }
 
 type ServerConfig struct {
@@ -85,6 +91,10 @@ type ServerConfig struct {
 type AuthConfig struct {
 	// JWT configuration.
 	JWT JWTConfig `yaml:"jwt" mapstructure:"jwt"`
+
+	// AnonymousJob allows the job apis without token for compatibility, the token is still authenticated
+	// and its permissions are checked if it is presented. Disable it to require the token for the job apis.
+	AnonymousJob bool `yaml:"anonymousJob" mapstructure:"anonymousJob"`
 }
 
 type JWTConfig struct {
@@ -326,6 +336,25 @@ type CertSpec struct {
 	ValidityPeriod time.Duration `mapstructure:"validityPeriod" yaml:"validityPeriod"`
 }
 
+type AuditConfig struct {
+	// Enable records the mutating api calls in audit logs.
+	Enable bool `yaml:"enable" mapstructure:"enable"`
+
+	// Retention is the retention period of audit logs, the expired audit logs are deleted.
+	Retention time.Duration `yaml:"retention" mapstructure:"retention"`
+
+	// GCInterval is the interval of deleting the expired audit logs.
+	GCInterval time.Duration `yaml:"gcInterval" mapstructure:"gcInterval"`
+}
+
+type WebhookConfig struct {
+	// Timeout is the timeout of sending webhook.
+	Timeout time.Duration `yaml:"timeout" mapstructure:"timeout"`
+
+	// MaxAttempts is the max attempts of sending webhook, when the receiver is unavailable.
+	MaxAttempts int `yaml:"maxAttempts" mapstructure:"maxAttempts"`
+}
+
 type NetworkConfig struct {
 	// EnableIPv6 enables ipv6 for server.
 	EnableIPv6 bool `mapstructure:"enableIPv6" yaml:"enableIPv6"`
@@ -353,6 +382,7 @@ func New() *Config {
 				Timeout:    DefaultJWTTimeout,
 				MaxRefresh: DefaultJWTMaxRefresh,
 			},
+			AnonymousJob: true,
 		},
 		Database: DatabaseConfig{
 			Type: DatabaseTypeMysql,
@@ -404,6 +434,15 @@ func New() *Config {
 		Network: NetworkConfig{
 			EnableIPv6: DefaultNetworkEnableIPv6,
 		},
+		Audit: AuditConfig{
+			Enable:     true,
+			Retention:  DefaultAuditRetention,
+			GCInterval: DefaultAuditGCInterval,
+		},
+		Webhook: WebhookConfig{
+			Timeout:     DefaultWebhookTimeout,
+			MaxAttempts: DefaultWebhookMaxAttempts,
+		},
 	}
 }
 
@@ -563,6 +602,24 @@ func (cfg *Config) Validate() error {
 		}
 	}
 
+	if cfg.Audit.Enable {
+		if cfg.Audit.Retention <= 0 {
+			return errors.New("audit requires parameter retention")
+		}
+
+		if cfg.Audit.GCInterval <= 0 {
+			return errors.New("audit requires parameter gcInterval")
+		}
+	}
+
+	if cfg.Webhook.Timeout <= 0 {
+		return errors.New("webhook requires parameter timeout")
+	}
+
+	if cfg.Webhook.MaxAttempts <= 0 {
+		return errors.New("webhook requires parameter maxAttempts")
+	}
+
 	if cfg.Security.AutoIssueCert {
 		if cfg.Security.CACert == "" {
 			return errors.New("security requires parameter caCert")
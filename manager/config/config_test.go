@@ -133,6 +133,7 @@ func TestConfig_Load(t *testing.T) {
 				Timeout:    30 * time.Second,
 				MaxRefresh: 1 * time.Minute,
 			},
+			AnonymousJob: true,
 		},
 		Database: DatabaseConfig{
 			Type: "mysql",
@@ -207,6 +208,15 @@ func TestConfig_Load(t *testing.T) {
 		Network: NetworkConfig{
 			EnableIPv6: true,
 		},
+		Audit: AuditConfig{
+			Enable:     true,
+			Retention:  720 * time.Hour,
+			GCInterval: 1 * time.Minute,
+		},
+		Webhook: WebhookConfig{
+			Timeout:     5 * time.Second,
+			MaxAttempts: 5,
+		},
 	}
 
 	managerConfigYAML := &Config{}
@@ -742,6 +752,66 @@ func TestConfig_Validate(t *testing.T) {
 				assert.EqualError(err, "metrics requires parameter addr")
 			},
 		},
+		{
+			name:   "audit requires parameter retention",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Auth.JWT = mockJWTConfig
+				cfg.Database.Type = DatabaseTypeMysql
+				cfg.Database.Mysql = mockMysqlConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Audit.Retention = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "audit requires parameter retention")
+			},
+		},
+		{
+			name:   "audit requires parameter gcInterval",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Auth.JWT = mockJWTConfig
+				cfg.Database.Type = DatabaseTypeMysql
+				cfg.Database.Mysql = mockMysqlConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Audit.GCInterval = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "audit requires parameter gcInterval")
+			},
+		},
+		{
+			name:   "webhook requires parameter timeout",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Auth.JWT = mockJWTConfig
+				cfg.Database.Type = DatabaseTypeMysql
+				cfg.Database.Mysql = mockMysqlConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Webhook.Timeout = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "webhook requires parameter timeout")
+			},
+		},
+		{
+			name:   "webhook requires parameter maxAttempts",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Auth.JWT = mockJWTConfig
+				cfg.Database.Type = DatabaseTypeMysql
+				cfg.Database.Mysql = mockMysqlConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Webhook.MaxAttempts = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "webhook requires parameter maxAttempts")
+			},
+		},
 		{
 			name:   "security requires parameter caCert",
 			config: New(),
@@ -22,10 +22,15 @@ const (
 	AttributeID          = attribute.Key("d7y.manager.id")
 	AttributePreheatType = attribute.Key("d7y.manager.preheat.type")
 	AttributePreheatURL  = attribute.Key("d7y.manager.preheat.url")
+
+	AttributePreheatRepository = attribute.Key("d7y.manager.preheat.repository")
+	AttributePreheatTagPattern = attribute.Key("d7y.manager.preheat.tag_pattern")
 )
 
 const (
 	SpanPreheat          = "preheat"
 	SpanGetLayers        = "get-layers"
 	SpanAuthWithRegistry = "auth-with-registry"
+	SpanBulkPreheat      = "bulk-preheat"
+	SpanGetTags          = "get-tags"
 )
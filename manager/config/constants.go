@@ -108,6 +108,22 @@ const (
 	DefaultMetricsAddr = ":8000"
 )
 
+const (
+	// DefaultAuditRetention is default retention period of audit logs.
+	DefaultAuditRetention = 90 * 24 * time.Hour
+
+	// DefaultAuditGCInterval is default interval of deleting the expired audit logs.
+	DefaultAuditGCInterval = 1 * time.Hour
+)
+
+const (
+	// DefaultWebhookTimeout is default timeout of sending webhook.
+	DefaultWebhookTimeout = 10 * time.Second
+
+	// DefaultWebhookMaxAttempts is default max attempts of sending webhook.
+	DefaultWebhookMaxAttempts = 3
+)
+
 var (
 	// DefaultCertIPAddresses is default ip addresses of certificate.
 	DefaultCertIPAddresses = []net.IP{ip.IPv4, ip.IPv6}
@@ -93,9 +93,16 @@ func migrate(db *gorm.DB) error {
 		&models.Scheduler{},
 		&models.User{},
 		&models.Oauth{},
+		&models.UserOauth{},
 		&models.Config{},
 		&models.Application{},
 		&models.Model{},
+		&models.Audit{},
+		&models.PersonalAccessToken{},
+		&models.SearcherRule{},
+		&models.ConfigVersion{},
+		&models.Webhook{},
+		&models.Tenant{},
 	)
 }
 
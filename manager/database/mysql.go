@@ -65,6 +65,7 @@ func newMyqsl(cfg *config.Config) (*gorm.DB, error) {
 			SingularTable: true,
 		},
 		DisableForeignKeyConstraintWhenMigrating: true,
+		TranslateError:                           true,
 		Logger:                                   gormLogger,
 	})
 	if err != nil {
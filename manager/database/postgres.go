@@ -21,11 +21,13 @@ import (
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
 	"gorm.io/gorm/schema"
 	"moul.io/zapgorm2"
 
 	logger "d7y.io/dragonfly/v2/internal/dflog"
 	"d7y.io/dragonfly/v2/manager/config"
+	"d7y.io/dragonfly/v2/manager/models"
 )
 
 func newPostgres(cfg *config.Config) (*gorm.DB, error) {
@@ -34,13 +36,21 @@ func newPostgres(cfg *config.Config) (*gorm.DB, error) {
 	// Format dsn string.
 	dsn := formatPostgresDSN(postgresCfg)
 
-	// Connect to mysql.
+	// Initialize gorm logger.
+	logLevel := gormlogger.Info
+	if !cfg.Verbose {
+		logLevel = gormlogger.Warn
+	}
+	gormLogger := zapgorm2.New(logger.CoreLogger.Desugar()).LogMode(logLevel)
+
+	// Connect to postgres.
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
 		NamingStrategy: schema.NamingStrategy{
 			SingularTable: true,
 		},
 		DisableForeignKeyConstraintWhenMigrating: true,
-		Logger:                                   zapgorm2.New(logger.CoreLogger.Desugar()),
+		TranslateError:                           true,
+		Logger:                                   gormLogger,
 	})
 	if err != nil {
 		return nil, err
@@ -58,9 +68,34 @@ func newPostgres(cfg *config.Config) (*gorm.DB, error) {
 		return nil, err
 	}
 
+	// Seed creates the default clusters with explicit ids.
+	if err := resetPostgresSequences(db, &models.SchedulerCluster{}, &models.SeedPeerCluster{}); err != nil {
+		return nil, err
+	}
+
 	return db, nil
 }
 
+// resetPostgresSequences sets the id sequences of tables next to the max ids. Postgres
+// does not advance the sequence when a row is created with explicit id, then the next
+// row created without id conflicts with it.
+func resetPostgresSequences(db *gorm.DB, values ...any) error {
+	for _, value := range values {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(value); err != nil {
+			return err
+		}
+
+		table := stmt.Schema.Table
+		if err := db.Exec(fmt.Sprintf("SELECT setval(pg_get_serial_sequence(?, 'id'), COALESCE((SELECT MAX(id) FROM %s), 0) + 1, false)",
+			stmt.Quote(table)), table).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func formatPostgresDSN(cfg *config.PostgresConfig) string {
 	return fmt.Sprintf("host=%v user=%v password=%v dbname=%v port=%v sslmode=%v TimeZone=%v",
 		cfg.Host,
@@ -0,0 +1,56 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package manager
+
+import (
+	"context"
+	"time"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/manager/service"
+)
+
+const (
+	// auditGCID is the id of audit GC task.
+	auditGCID = "audit"
+)
+
+// auditGC deletes the audits older than the retention period.
+type auditGC struct {
+	// service is the manager service.
+	service service.Service
+
+	// retention is the retention period of audits.
+	retention time.Duration
+
+	// timeout is the timeout of deleting audits.
+	timeout time.Duration
+}
+
+// RunGC deletes the expired audits.
+func (g *auditGC) RunGC() error {
+	ctx, cancel := context.WithTimeout(context.Background(), g.timeout)
+	defer cancel()
+
+	count, err := g.service.DestroyAuditsBefore(ctx, time.Now().Add(-g.retention))
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("audit GC deleted %d expired audits", count)
+	return nil
+}
@@ -0,0 +1,199 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	// nolint
+	_ "d7y.io/dragonfly/v2/manager/models"
+	"d7y.io/dragonfly/v2/manager/types"
+	// nolint
+	_ "d7y.io/dragonfly/v2/pkg/structure"
+)
+
+// @Summary Get Config Versions
+// @Description Get the configuration versions of cluster, the latest version is first
+// @Tags ConfigVersion
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param page query int true "current page" default(0)
+// @Param per_page query int true "return max item count, default 10, max 50" default(10) minimum(2) maximum(50)
+// @Success 200 {object} []models.ConfigVersion
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /scheduler-clusters/{id}/config-versions [get]
+// @Router /seed-peer-clusters/{id}/config-versions [get]
+func (h *Handlers) GetConfigVersions(clusterType string) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		var params types.ConfigVersionsParams
+		if err := ctx.ShouldBindUri(&params); err != nil {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+			return
+		}
+
+		var query types.GetConfigVersionsQuery
+		if err := ctx.ShouldBindQuery(&query); err != nil {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+			return
+		}
+
+		h.setPaginationDefault(&query.Page, &query.PerPage)
+		configVersions, count, err := h.service.GetConfigVersions(ctx.Request.Context(), clusterType, params.ID, query)
+		if err != nil {
+			ctx.Error(err) // nolint: errcheck
+			return
+		}
+
+		h.setPaginationLinkHeader(ctx, query.Page, query.PerPage, int(count))
+		ctx.JSON(http.StatusOK, configVersions)
+	}
+}
+
+// @Summary Get Config Version
+// @Description Get the configuration version of cluster
+// @Tags ConfigVersion
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param version path string true "version"
+// @Success 200 {object} models.ConfigVersion
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /scheduler-clusters/{id}/config-versions/{version} [get]
+// @Router /seed-peer-clusters/{id}/config-versions/{version} [get]
+func (h *Handlers) GetConfigVersion(clusterType string) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		var params types.ConfigVersionParams
+		if err := ctx.ShouldBindUri(&params); err != nil {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+			return
+		}
+
+		configVersion, err := h.service.GetConfigVersion(ctx.Request.Context(), clusterType, params.ID, params.Version)
+		if err != nil {
+			ctx.Error(err) // nolint: errcheck
+			return
+		}
+
+		ctx.JSON(http.StatusOK, configVersion)
+	}
+}
+
+// @Summary Diff Config Versions
+// @Description Diff the configurations between two versions of cluster
+// @Tags ConfigVersion
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param from query int true "version diff from"
+// @Param to query int true "version diff to"
+// @Success 200 {object} []structure.Diff
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /scheduler-clusters/{id}/config-versions/diff [get]
+// @Router /seed-peer-clusters/{id}/config-versions/diff [get]
+func (h *Handlers) DiffConfigVersions(clusterType string) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		var params types.ConfigVersionsParams
+		if err := ctx.ShouldBindUri(&params); err != nil {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+			return
+		}
+
+		var query types.DiffConfigVersionsQuery
+		if err := ctx.ShouldBindQuery(&query); err != nil {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+			return
+		}
+
+		diffs, err := h.service.DiffConfigVersions(ctx.Request.Context(), clusterType, params.ID, query)
+		if err != nil {
+			ctx.Error(err) // nolint: errcheck
+			return
+		}
+
+		ctx.JSON(http.StatusOK, diffs)
+	}
+}
+
+// @Summary Rollback Config Version
+// @Description Rollback the configuration of cluster to the version, which is recorded as a new version
+// @Tags ConfigVersion
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param version path string true "version"
+// @Success 200 {object} models.ConfigVersion
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /scheduler-clusters/{id}/config-versions/{version}/rollback [post]
+// @Router /seed-peer-clusters/{id}/config-versions/{version}/rollback [post]
+func (h *Handlers) RollbackConfigVersion(clusterType string) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		var params types.ConfigVersionParams
+		if err := ctx.ShouldBindUri(&params); err != nil {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+			return
+		}
+
+		configVersion, err := h.service.RollbackConfigVersion(ctx.Request.Context(), clusterType, params.ID, params.Version)
+		if err != nil {
+			ctx.Error(err) // nolint: errcheck
+			return
+		}
+
+		ctx.JSON(http.StatusOK, configVersion)
+	}
+}
+
+// @Summary Get Config Version Reports
+// @Description Get the configuration versions running in the consumers of cluster, which are reported by schedulers
+// @Tags ConfigVersion
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} []types.ConfigVersionReport
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /scheduler-clusters/{id}/config-version-reports [get]
+// @Router /seed-peer-clusters/{id}/config-version-reports [get]
+func (h *Handlers) GetConfigVersionReports(clusterType string) func(*gin.Context) {
+	return func(ctx *gin.Context) {
+		var params types.ConfigVersionsParams
+		if err := ctx.ShouldBindUri(&params); err != nil {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+			return
+		}
+
+		reports, err := h.service.GetConfigVersionReports(ctx.Request.Context(), clusterType, params.ID)
+		if err != nil {
+			ctx.Error(err) // nolint: errcheck
+			return
+		}
+
+		ctx.JSON(http.StatusOK, reports)
+	}
+}
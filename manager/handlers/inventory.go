@@ -0,0 +1,99 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+// @Summary Get Inventory Hosts
+// @Description Get the active hosts reported by schedulers
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Param page query int true "current page" default(0)
+// @Param per_page query int true "return max item count, default 10, max 50" default(10) minimum(2) maximum(50)
+// @Success 200 {object} []types.InventoryHost
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /inventory/hosts [get]
+func (h *Handlers) GetInventoryHosts(ctx *gin.Context) {
+	var query types.GetInventoryHostsQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	tenantID, ok := restrictTenantID(ctx, query.TenantID)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"message": "permission deny"})
+		return
+	}
+	query.TenantID = tenantID
+
+	h.setPaginationDefault(&query.Page, &query.PerPage)
+	hosts, count, err := h.service.GetInventoryHosts(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	h.setPaginationLinkHeader(ctx, query.Page, query.PerPage, int(count))
+	ctx.JSON(http.StatusOK, hosts)
+}
+
+// @Summary Get Inventory Peers
+// @Description Get the active peers reported by schedulers
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Param page query int true "current page" default(0)
+// @Param per_page query int true "return max item count, default 10, max 50" default(10) minimum(2) maximum(50)
+// @Success 200 {object} []types.InventoryPeer
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /inventory/peers [get]
+func (h *Handlers) GetInventoryPeers(ctx *gin.Context) {
+	var query types.GetInventoryPeersQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	tenantID, ok := restrictTenantID(ctx, query.TenantID)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"message": "permission deny"})
+		return
+	}
+	query.TenantID = tenantID
+
+	h.setPaginationDefault(&query.Page, &query.PerPage)
+	peers, count, err := h.service.GetInventoryPeers(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	h.setPaginationLinkHeader(ctx, query.Page, query.PerPage, int(count))
+	ctx.JSON(http.StatusOK, peers)
+}
@@ -37,18 +37,94 @@ func (h *Handlers) CreateJob(ctx *gin.Context) {
 			return
 		}
 
+		schedulerClusterIDs, ok := restrictSchedulerClusterIDs(ctx, json.SchedulerClusterIDs)
+		if !ok {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"message": "permission deny"})
+			return
+		}
+		json.SchedulerClusterIDs = schedulerClusterIDs
+
+		tenantID, ok := bindTenantID(ctx, json.TenantID)
+		if !ok {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"message": "permission deny"})
+			return
+		}
+		json.TenantID = tenantID
+
 		job, err := h.service.CreatePreheatJob(ctx.Request.Context(), json)
 		if err != nil {
 			ctx.Error(err) // nolint: errcheck
 			return
 		}
 
+		ctx.JSON(http.StatusOK, job)
+	case job.GCDryRunJob:
+		var json types.CreateGCDryRunJobRequest
+		if err := ctx.ShouldBindBodyWith(&json, binding.JSON); err != nil {
+			ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+			return
+		}
+
+		schedulerClusterIDs, ok := restrictSchedulerClusterIDs(ctx, json.SchedulerClusterIDs)
+		if !ok {
+			ctx.JSON(http.StatusUnauthorized, gin.H{"message": "permission deny"})
+			return
+		}
+		json.SchedulerClusterIDs = schedulerClusterIDs
+
+		job, err := h.service.CreateGCDryRunJob(ctx.Request.Context(), json)
+		if err != nil {
+			ctx.Error(err) // nolint: errcheck
+			return
+		}
+
 		ctx.JSON(http.StatusOK, job)
 	default:
 		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": "Unknow type"})
 	}
 }
 
+// @Summary Create Bulk Preheat Job
+// @Description Create preheat job of the images whose tags in repository match the tag pattern, tag pattern type is glob or regex
+// @Tags Job
+// @Accept json
+// @Produce json
+// @Param Job body types.CreateBulkPreheatJobRequest true "Job"
+// @Success 200 {object} models.Job
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /jobs/bulk-preheat [post]
+func (h *Handlers) CreateBulkPreheatJob(ctx *gin.Context) {
+	var json types.CreateBulkPreheatJobRequest
+	if err := ctx.ShouldBindJSON(&json); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	schedulerClusterIDs, ok := restrictSchedulerClusterIDs(ctx, json.SchedulerClusterIDs)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"message": "permission deny"})
+		return
+	}
+	json.SchedulerClusterIDs = schedulerClusterIDs
+
+	tenantID, ok := bindTenantID(ctx, json.TenantID)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"message": "permission deny"})
+		return
+	}
+	json.TenantID = tenantID
+
+	job, err := h.service.CreateBulkPreheatJob(ctx.Request.Context(), json)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, job)
+}
+
 // @Summary Destroy Job
 // @Description Destroy by id
 // @Tags Job
@@ -67,6 +143,14 @@ func (h *Handlers) DestroyJob(ctx *gin.Context) {
 		return
 	}
 
+	if ok, err := h.allowJob(ctx, params.ID); err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	} else if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"message": "permission deny"})
+		return
+	}
+
 	if err := h.service.DestroyJob(ctx.Request.Context(), params.ID); err != nil {
 		ctx.Error(err) // nolint: errcheck
 		return
@@ -100,6 +184,14 @@ func (h *Handlers) UpdateJob(ctx *gin.Context) {
 		return
 	}
 
+	if ok, err := h.allowJob(ctx, params.ID); err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	} else if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"message": "permission deny"})
+		return
+	}
+
 	job, err := h.service.UpdateJob(ctx.Request.Context(), params.ID, json)
 	if err != nil {
 		ctx.Error(err) // nolint: errcheck
@@ -133,9 +225,28 @@ func (h *Handlers) GetJob(ctx *gin.Context) {
 		return
 	}
 
+	if !allowTenantID(ctx, job.TenantID) {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"message": "permission deny"})
+		return
+	}
+
 	ctx.JSON(http.StatusOK, job)
 }
 
+// allowJob returns whether the job is accessible by the tenant of personal access token authenticating the request.
+func (h *Handlers) allowJob(ctx *gin.Context, id uint) (bool, error) {
+	if allowTenantID(ctx, 0) {
+		return true, nil
+	}
+
+	job, err := h.service.GetJob(ctx.Request.Context(), id)
+	if err != nil {
+		return false, err
+	}
+
+	return allowTenantID(ctx, job.TenantID), nil
+}
+
 // @Summary Get Jobs
 // @Description Get Jobs
 // @Tags Job
@@ -155,6 +266,13 @@ func (h *Handlers) GetJobs(ctx *gin.Context) {
 		return
 	}
 
+	tenantID, ok := restrictTenantID(ctx, query.TenantID)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"message": "permission deny"})
+		return
+	}
+	query.TenantID = tenantID
+
 	h.setPaginationDefault(&query.Page, &query.PerPage)
 	jobs, count, err := h.service.GetJobs(ctx.Request.Context(), query)
 	if err != nil {
@@ -0,0 +1,269 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"d7y.io/dragonfly/v2/manager/middlewares"
+	"d7y.io/dragonfly/v2/manager/models"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+// @Summary Create PersonalAccessToken
+// @Description Create by json config, the token is only returned in the response of creation. Type is personal or service, service token is created by root and is only restricted by its scopes
+// @Tags PersonalAccessToken
+// @Accept json
+// @Produce json
+// @Param PersonalAccessToken body types.CreatePersonalAccessTokenRequest true "PersonalAccessToken"
+// @Success 200 {object} models.PersonalAccessToken
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /personal-access-tokens [post]
+func (h *Handlers) CreatePersonalAccessToken(ctx *gin.Context) {
+	var json types.CreatePersonalAccessTokenRequest
+	if err := ctx.ShouldBindJSON(&json); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	rawID, ok := ctx.Get("id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"message": "Unavailable token: require user id"})
+		return
+	}
+
+	personalAccessToken, err := h.service.CreatePersonalAccessToken(ctx.Request.Context(), uint(rawID.(float64)), json)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, personalAccessToken)
+}
+
+// @Summary Destroy PersonalAccessToken
+// @Description Destroy by id
+// @Tags PersonalAccessToken
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /personal-access-tokens/{id} [delete]
+func (h *Handlers) DestroyPersonalAccessToken(ctx *gin.Context) {
+	var params types.PersonalAccessTokenParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	rawID, ok := ctx.Get("id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"message": "Unavailable token: require user id"})
+		return
+	}
+
+	if err := h.service.DestroyPersonalAccessToken(ctx.Request.Context(), uint(rawID.(float64)), params.ID); err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+// @Summary Update PersonalAccessToken
+// @Description Update by json config
+// @Tags PersonalAccessToken
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param PersonalAccessToken body types.UpdatePersonalAccessTokenRequest true "PersonalAccessToken"
+// @Success 200 {object} models.PersonalAccessToken
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /personal-access-tokens/{id} [patch]
+func (h *Handlers) UpdatePersonalAccessToken(ctx *gin.Context) {
+	var params types.PersonalAccessTokenParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	var json types.UpdatePersonalAccessTokenRequest
+	if err := ctx.ShouldBindJSON(&json); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	rawID, ok := ctx.Get("id")
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"message": "Unavailable token: require user id"})
+		return
+	}
+
+	personalAccessToken, err := h.service.UpdatePersonalAccessToken(ctx.Request.Context(), uint(rawID.(float64)), params.ID, json)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, personalAccessToken)
+}
+
+// @Summary Get PersonalAccessToken
+// @Description Get PersonalAccessToken by id
+// @Tags PersonalAccessToken
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} models.PersonalAccessToken
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /personal-access-tokens/{id} [get]
+func (h *Handlers) GetPersonalAccessToken(ctx *gin.Context) {
+	var params types.PersonalAccessTokenParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	personalAccessToken, err := h.service.GetPersonalAccessToken(ctx.Request.Context(), params.ID)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, personalAccessToken)
+}
+
+// @Summary Get PersonalAccessTokens
+// @Description Get PersonalAccessTokens
+// @Tags PersonalAccessToken
+// @Accept json
+// @Produce json
+// @Param page query int true "current page" default(0)
+// @Param per_page query int true "return max item count, default 10, max 50" default(10) minimum(2) maximum(50)
+// @Success 200 {object} []models.PersonalAccessToken
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /personal-access-tokens [get]
+func (h *Handlers) GetPersonalAccessTokens(ctx *gin.Context) {
+	var query types.GetPersonalAccessTokensQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	h.setPaginationDefault(&query.Page, &query.PerPage)
+	personalAccessTokens, count, err := h.service.GetPersonalAccessTokens(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	h.setPaginationLinkHeader(ctx, query.Page, query.PerPage, int(count))
+	ctx.JSON(http.StatusOK, personalAccessTokens)
+}
+
+// restrictSchedulerClusterIDs restricts the scheduler clusters of job to the ones
+// of the personal access token authenticating the request. It returns the scheduler
+// clusters of token if ids is empty, and false if any of ids is out of them.
+func restrictSchedulerClusterIDs(ctx *gin.Context, ids []uint) ([]uint, bool) {
+	rawToken, ok := ctx.Get(middlewares.PersonalAccessTokenKey)
+	if !ok {
+		return ids, true
+	}
+
+	token, ok := rawToken.(*models.PersonalAccessToken)
+	if !ok || len(token.SchedulerClusters) == 0 {
+		return ids, true
+	}
+
+	allowed := make(map[uint]struct{}, len(token.SchedulerClusters))
+	for _, schedulerCluster := range token.SchedulerClusters {
+		allowed[schedulerCluster.ID] = struct{}{}
+	}
+
+	if len(ids) == 0 {
+		for id := range allowed {
+			ids = append(ids, id)
+		}
+
+		return ids, true
+	}
+
+	for _, id := range ids {
+		if _, ok := allowed[id]; !ok {
+			return nil, false
+		}
+	}
+
+	return ids, true
+}
+
+// restrictTenantID restricts the tenant to the tenant of personal access token,
+// the tenant is not restricted if the request is not authenticated by a token of tenant.
+func restrictTenantID(ctx *gin.Context, id uint) (uint, bool) {
+	rawToken, ok := ctx.Get(middlewares.PersonalAccessTokenKey)
+	if !ok {
+		return id, true
+	}
+
+	token, ok := rawToken.(*models.PersonalAccessToken)
+	if !ok || token.TenantID == 0 {
+		return id, true
+	}
+
+	if id != 0 && id != token.TenantID {
+		return 0, false
+	}
+
+	return token.TenantID, true
+}
+
+// bindTenantID returns the tenant of the job created by the request, which is the tenant of personal access token.
+// The tenant in request is supplied by the caller, so it must be the tenant of token or be empty. The job created
+// without a token of tenant is not scoped to any tenant, and it is unable to run in the scheduler clusters of tenants.
+func bindTenantID(ctx *gin.Context, id uint) (uint, bool) {
+	var tenantID uint
+	if rawToken, ok := ctx.Get(middlewares.PersonalAccessTokenKey); ok {
+		if token, ok := rawToken.(*models.PersonalAccessToken); ok {
+			tenantID = token.TenantID
+		}
+	}
+
+	if id != 0 && id != tenantID {
+		return 0, false
+	}
+
+	return tenantID, true
+}
+
+// allowTenantID returns whether the resource of tenant is accessible by the request. The request authenticated
+// by a token of tenant is only allowed to access the resources of its tenant, the same as listing them.
+func allowTenantID(ctx *gin.Context, id uint) bool {
+	tenantID, _ := restrictTenantID(ctx, 0)
+	return tenantID == 0 || tenantID == id
+}
@@ -159,6 +159,13 @@ func (h *Handlers) GetSchedulerClusters(ctx *gin.Context) {
 		return
 	}
 
+	tenantID, ok := restrictTenantID(ctx, query.TenantID)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"message": "permission deny"})
+		return
+	}
+	query.TenantID = tenantID
+
 	h.setPaginationDefault(&query.Page, &query.PerPage)
 	schedulerClusters, count, err := h.service.GetSchedulerClusters(ctx.Request.Context(), query)
 	if err != nil {
@@ -197,3 +204,30 @@ func (h *Handlers) AddSchedulerToSchedulerCluster(ctx *gin.Context) {
 
 	ctx.Status(http.StatusOK)
 }
+
+// @Summary Get Seed Peer Saturation
+// @Description Get the saturation of seed peers in schedulerCluster, which is reported by schedulers for autoscaling seed peers
+// @Tags SchedulerCluster
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} types.SeedPeerSaturation
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /scheduler-clusters/{id}/seed-peer-saturation [get]
+func (h *Handlers) GetSeedPeerSaturation(ctx *gin.Context) {
+	var params types.SchedulerClusterParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	saturation, err := h.service.GetSeedPeerSaturation(ctx.Request.Context(), params.ID)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, saturation)
+}
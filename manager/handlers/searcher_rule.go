@@ -0,0 +1,171 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	// nolint
+	_ "d7y.io/dragonfly/v2/manager/models"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+// @Summary Create SearcherRule
+// @Description Create by json config, the dfdaemon matching the cidrs and labels of rule prefers the scheduler cluster of rule, the rule of larger weight takes precedence
+// @Tags SearcherRule
+// @Accept json
+// @Produce json
+// @Param SearcherRule body types.CreateSearcherRuleRequest true "SearcherRule"
+// @Success 200 {object} models.SearcherRule
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /searcher-rules [post]
+func (h *Handlers) CreateSearcherRule(ctx *gin.Context) {
+	var json types.CreateSearcherRuleRequest
+	if err := ctx.ShouldBindJSON(&json); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	searcherRule, err := h.service.CreateSearcherRule(ctx.Request.Context(), json)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, searcherRule)
+}
+
+// @Summary Destroy SearcherRule
+// @Description Destroy by id
+// @Tags SearcherRule
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /searcher-rules/{id} [delete]
+func (h *Handlers) DestroySearcherRule(ctx *gin.Context) {
+	var params types.SearcherRuleParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	if err := h.service.DestroySearcherRule(ctx.Request.Context(), params.ID); err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+// @Summary Update SearcherRule
+// @Description Update by json config
+// @Tags SearcherRule
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param SearcherRule body types.UpdateSearcherRuleRequest true "SearcherRule"
+// @Success 200 {object} models.SearcherRule
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /searcher-rules/{id} [patch]
+func (h *Handlers) UpdateSearcherRule(ctx *gin.Context) {
+	var params types.SearcherRuleParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	var json types.UpdateSearcherRuleRequest
+	if err := ctx.ShouldBindJSON(&json); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	searcherRule, err := h.service.UpdateSearcherRule(ctx.Request.Context(), params.ID, json)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, searcherRule)
+}
+
+// @Summary Get SearcherRule
+// @Description Get SearcherRule by id
+// @Tags SearcherRule
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} models.SearcherRule
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /searcher-rules/{id} [get]
+func (h *Handlers) GetSearcherRule(ctx *gin.Context) {
+	var params types.SearcherRuleParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	searcherRule, err := h.service.GetSearcherRule(ctx.Request.Context(), params.ID)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, searcherRule)
+}
+
+// @Summary Get SearcherRules
+// @Description Get SearcherRules
+// @Tags SearcherRule
+// @Accept json
+// @Produce json
+// @Param page query int true "current page" default(0)
+// @Param per_page query int true "return max item count, default 10, max 50" default(10) minimum(2) maximum(50)
+// @Success 200 {object} []models.SearcherRule
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /searcher-rules [get]
+func (h *Handlers) GetSearcherRules(ctx *gin.Context) {
+	var query types.GetSearcherRulesQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	h.setPaginationDefault(&query.Page, &query.PerPage)
+	searcherRules, count, err := h.service.GetSearcherRules(ctx.Request.Context(), query)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	h.setPaginationLinkHeader(ctx, query.Page, query.PerPage, int(count))
+	ctx.JSON(http.StatusOK, searcherRules)
+}
@@ -159,6 +159,13 @@ func (h *Handlers) GetSeedPeerClusters(ctx *gin.Context) {
 		return
 	}
 
+	tenantID, ok := restrictTenantID(ctx, query.TenantID)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"message": "permission deny"})
+		return
+	}
+	query.TenantID = tenantID
+
 	h.setPaginationDefault(&query.Page, &query.PerPage)
 	seedPeers, count, err := h.service.GetSeedPeerClusters(ctx.Request.Context(), query)
 	if err != nil {
@@ -292,6 +292,41 @@ func (h *Handlers) AddRoleToUser(ctx *gin.Context) {
 	ctx.Status(http.StatusOK)
 }
 
+// @Summary Add Oauth For User
+// @Description link the user to its subject in the oauth provider, the user signs in with the oauth provider after linked
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param oauth_id path string true "oauth id"
+// @Param UserOauth body types.AddOauthForUserRequest true "UserOauth"
+// @Success 200 {object} models.UserOauth
+// @Failure 400
+// @Failure 404
+// @Failure 500
+// @Router /users/{id}/oauth/{oauth_id} [put]
+func (h *Handlers) AddOauthForUser(ctx *gin.Context) {
+	var params types.AddOauthForUserParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	var json types.AddOauthForUserRequest
+	if err := ctx.ShouldBindJSON(&json); err != nil {
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"errors": err.Error()})
+		return
+	}
+
+	userOauth, err := h.service.AddOauthForUser(ctx.Request.Context(), params, json)
+	if err != nil {
+		ctx.Error(err) // nolint: errcheck
+		return
+	}
+
+	ctx.JSON(http.StatusOK, userOauth)
+}
+
 // @Summary Delete Role For User
 // @Description delete role by uri config
 // @Tags Users
@@ -0,0 +1,88 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+//go:generate mockgen -destination mocks/gc_mock.go -source gc.go -package mocks
+
+package job
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	machineryv1tasks "github.com/RichardKnop/machinery/v1/tasks"
+	"github.com/google/uuid"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	internaljob "d7y.io/dragonfly/v2/internal/job"
+	"d7y.io/dragonfly/v2/manager/models"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+type GC interface {
+	CreateGCDryRun(context.Context, []models.Scheduler, types.SchedulerClusterGCConfig) (*internaljob.GroupJobState, error)
+}
+
+type gc struct {
+	job *internaljob.Job
+}
+
+func newGC(job *internaljob.Job) GC {
+	return &gc{
+		job: job,
+	}
+}
+
+// CreateGCDryRun sends the gc dry run job to every scheduler, because
+// the resources of schedulers in the same cluster are not shared.
+func (g *gc) CreateGCDryRun(ctx context.Context, schedulers []models.Scheduler, json types.SchedulerClusterGCConfig) (*internaljob.GroupJobState, error) {
+	args, err := internaljob.MarshalRequest(internaljob.GCDryRunRequest{
+		PeerTTL:      json.PeerTTL,
+		TaskTTL:      json.TaskTTL,
+		HostTTL:      json.HostTTL,
+		MaxTaskCount: json.MaxTaskCount,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var signatures []*machineryv1tasks.Signature
+	for _, queue := range getSchedulerQueues(schedulers) {
+		signatures = append(signatures, &machineryv1tasks.Signature{
+			UUID:       fmt.Sprintf("task_%s", uuid.New().String()),
+			Name:       internaljob.GCDryRunJob,
+			RoutingKey: queue.String(),
+			Args:       args,
+		})
+	}
+
+	group, err := machineryv1tasks.NewGroup(signatures...)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("create gc dry run group %s in %d queues", group.GroupUUID, len(signatures))
+	if _, err := g.job.Server.SendGroupWithContext(ctx, group, 0); err != nil {
+		logger.Errorf("create gc dry run group %s failed: %s", group.GroupUUID, err.Error())
+		return nil, err
+	}
+
+	return &internaljob.GroupJobState{
+		GroupUUID: group.GroupUUID,
+		State:     machineryv1tasks.StatePending,
+		CreatedAt: time.Now(),
+	}, nil
+}
@@ -24,6 +24,7 @@ import (
 type Job struct {
 	*internaljob.Job
 	Preheat
+	GC
 }
 
 func New(cfg *config.Config) (*Job, error) {
@@ -47,5 +48,6 @@ func New(cfg *config.Config) (*Job, error) {
 	return &Job{
 		Job:     j,
 		Preheat: p,
+		GC:      newGC(j),
 	}, nil
 }
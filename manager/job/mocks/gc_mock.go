@@ -0,0 +1,53 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: gc.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+
+	job "d7y.io/dragonfly/v2/internal/job"
+	models "d7y.io/dragonfly/v2/manager/models"
+	types "d7y.io/dragonfly/v2/manager/types"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockGC is a mock of GC interface.
+type MockGC struct {
+	ctrl     *gomock.Controller
+	recorder *MockGCMockRecorder
+}
+
+// MockGCMockRecorder is the mock recorder for MockGC.
+type MockGCMockRecorder struct {
+	mock *MockGC
+}
+
+// NewMockGC creates a new mock instance.
+func NewMockGC(ctrl *gomock.Controller) *MockGC {
+	mock := &MockGC{ctrl: ctrl}
+	mock.recorder = &MockGCMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockGC) EXPECT() *MockGCMockRecorder {
+	return m.recorder
+}
+
+// CreateGCDryRun mocks base method.
+func (m *MockGC) CreateGCDryRun(arg0 context.Context, arg1 []models.Scheduler, arg2 types.SchedulerClusterGCConfig) (*job.GroupJobState, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateGCDryRun", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*job.GroupJobState)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateGCDryRun indicates an expected call of CreateGCDryRun.
+func (mr *MockGCMockRecorder) CreateGCDryRun(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGCDryRun", reflect.TypeOf((*MockGC)(nil).CreateGCDryRun), arg0, arg1, arg2)
+}
@@ -37,6 +37,22 @@ func (m *MockPreheat) EXPECT() *MockPreheatMockRecorder {
 	return m.recorder
 }
 
+// CreateBulkPreheat mocks base method.
+func (m *MockPreheat) CreateBulkPreheat(arg0 context.Context, arg1 []models.Scheduler, arg2 types.BulkPreheatArgs) (*job.GroupJobState, []string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBulkPreheat", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*job.GroupJobState)
+	ret1, _ := ret[1].([]string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CreateBulkPreheat indicates an expected call of CreateBulkPreheat.
+func (mr *MockPreheatMockRecorder) CreateBulkPreheat(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBulkPreheat", reflect.TypeOf((*MockPreheat)(nil).CreateBulkPreheat), arg0, arg1, arg2)
+}
+
 // CreatePreheat mocks base method.
 func (m *MockPreheat) CreatePreheat(arg0 context.Context, arg1 []models.Scheduler, arg2 types.PreheatArgs) (*job.GroupJobState, error) {
 	m.ctrl.T.Helper()
@@ -26,6 +26,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"path"
 	"regexp"
 	"strings"
 	"time"
@@ -58,21 +59,45 @@ const (
 	PreheatFileType PreheatType = "file"
 )
 
+type TagPatternType string
+
+const (
+	// TagPatternGlobType is glob type of tag pattern.
+	TagPatternGlobType TagPatternType = "glob"
+
+	// TagPatternRegexType is regex type of tag pattern.
+	TagPatternRegexType TagPatternType = "regex"
+)
+
 const (
 	// defaultHTTPRequesttimeout is the default timeout of http client.
 	defaultHTTPRequesttimeout = 1 * time.Minute
+
+	// maxBulkPreheatTags is the max number of tags matched by bulk preheat.
+	maxBulkPreheatTags = 100
 )
 
-var accessURLPattern, _ = regexp.Compile("^(.*)://(.*)/v2/(.*)/manifests/(.*)")
+var (
+	accessURLPattern, _  = regexp.Compile("^(.*)://(.*)/v2/(.*)/manifests/(.*)")
+	repositoryPattern, _ = regexp.Compile("^(.*)://([^/]+)/(.+)$")
+)
 
 type Preheat interface {
 	CreatePreheat(context.Context, []models.Scheduler, types.PreheatArgs) (*internaljob.GroupJobState, error)
+	CreateBulkPreheat(context.Context, []models.Scheduler, types.BulkPreheatArgs) (*internaljob.GroupJobState, []string, error)
 }
 
 type preheat struct {
 	job *internaljob.Job
 }
 
+// preheatFile is the file to preheat, size is used to cap the bandwidth
+// and zero means the size is unknown.
+type preheatFile struct {
+	internaljob.PreheatRequest
+	size int64
+}
+
 type preheatImage struct {
 	protocol string
 	domain   string
@@ -98,11 +123,17 @@ func (p *preheat) CreatePreheat(ctx context.Context, schedulers []models.Schedul
 	filter := json.Filter
 	rawheader := json.Headers
 
+	// Initialize schedule of tasks
+	schedule, err := newPreheatSchedule(json.TimeWindow, json.MaxBandwidth, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
 	// Initialize queues
 	queues := getSchedulerQueues(schedulers)
 
 	// Generate download files
-	var files []internaljob.PreheatRequest
+	var files []preheatFile
 	switch PreheatType(json.Type) {
 	case PreheatImageType:
 		// Parse image manifest url
@@ -116,36 +147,107 @@ func (p *preheat) CreatePreheat(ctx context.Context, schedulers []models.Schedul
 			return nil, err
 		}
 	case PreheatFileType:
-		files = []internaljob.PreheatRequest{
+		files = []preheatFile{
 			{
-				URL:     url,
-				Tag:     tag,
-				Filter:  filter,
-				Headers: rawheader,
+				PreheatRequest: internaljob.PreheatRequest{
+					URL:     url,
+					Tag:     tag,
+					Filter:  filter,
+					Headers: rawheader,
+				},
 			},
 		}
 	default:
 		return nil, errors.New("unknow preheat type")
 	}
 
-	return p.createGroupJob(ctx, files, queues)
+	return p.createGroupJob(ctx, files, queues, schedule)
 }
 
-func (p *preheat) createGroupJob(ctx context.Context, files []internaljob.PreheatRequest, queues []internaljob.Queue) (*internaljob.GroupJobState, error) {
+// CreateBulkPreheat resolves the tags of repository matching the tag pattern, and preheats
+// the layers of all matched images in the schedulers. It returns the matched tags.
+func (p *preheat) CreateBulkPreheat(ctx context.Context, schedulers []models.Scheduler, json types.BulkPreheatArgs) (*internaljob.GroupJobState, []string, error) {
+	var span trace.Span
+	ctx, span = tracer.Start(ctx, config.SpanBulkPreheat, trace.WithSpanKind(trace.SpanKindProducer))
+	span.SetAttributes(config.AttributePreheatRepository.String(json.Repository))
+	span.SetAttributes(config.AttributePreheatTagPattern.String(json.TagPattern))
+	defer span.End()
+
+	schedule, err := newPreheatSchedule(json.TimeWindow, json.MaxBandwidth, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	image, err := parseRepository(json.Repository)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tags, err := p.getTags(ctx, tagsURL(image.protocol, image.domain, image.name), nethttp.MapToHeader(json.Headers))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tags, err = matchTags(tags, json.TagPattern, TagPatternType(json.TagPatternType))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(tags) == 0 {
+		return nil, nil, fmt.Errorf("no tags of %s match %s", json.Repository, json.TagPattern)
+	}
+
+	if len(tags) > maxBulkPreheatTags {
+		return nil, nil, fmt.Errorf("%d tags of %s match %s, exceeds the limit %d", len(tags), json.Repository, json.TagPattern, maxBulkPreheatTags)
+	}
+
+	// Images of the same repository share layers, so the layers are deduplicated.
+	var (
+		files   []preheatFile
+		visited = make(map[string]struct{})
+	)
+	for _, tag := range tags {
+		taggedImage := &preheatImage{protocol: image.protocol, domain: image.domain, name: image.name, tag: tag}
+		layers, err := p.getLayers(ctx, manifestURL(image.protocol, image.domain, image.name, tag), json.Tag, json.Filter, nethttp.MapToHeader(json.Headers), taggedImage)
+		if err != nil {
+			return nil, nil, fmt.Errorf("get layers of tag %s failed: %w", tag, err)
+		}
+
+		for _, layer := range layers {
+			if _, ok := visited[layer.URL]; ok {
+				continue
+			}
+
+			visited[layer.URL] = struct{}{}
+			files = append(files, layer)
+		}
+	}
+
+	groupJobState, err := p.createGroupJob(ctx, files, getSchedulerQueues(schedulers), schedule)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return groupJobState, tags, nil
+}
+
+func (p *preheat) createGroupJob(ctx context.Context, files []preheatFile, queues []internaljob.Queue, schedule *preheatSchedule) (*internaljob.GroupJobState, error) {
 	var signatures []*machineryv1tasks.Signature
 	for _, queue := range queues {
 		for _, file := range files {
-			args, err := internaljob.MarshalRequest(file)
+			args, err := internaljob.MarshalRequest(file.PreheatRequest)
 			if err != nil {
-				logger.Errorf("preheat marshal request: %v, error: %v", file, err)
+				logger.Errorf("preheat marshal request: %v, error: %v", file.PreheatRequest, err)
 				continue
 			}
 
+			// Each scheduler cluster downloads the file from source, so every task takes bandwidth.
 			signatures = append(signatures, &machineryv1tasks.Signature{
 				UUID:       fmt.Sprintf("task_%s", uuid.New().String()),
 				Name:       internaljob.PreheatJob,
 				RoutingKey: queue.String(),
 				Args:       args,
+				ETA:        schedule.next(file.size),
 			})
 		}
 	}
@@ -167,13 +269,14 @@ func (p *preheat) createGroupJob(ctx context.Context, files []internaljob.Prehea
 	}
 
 	return &internaljob.GroupJobState{
-		GroupUUID: group.GroupUUID,
-		State:     machineryv1tasks.StatePending,
-		CreatedAt: time.Now(),
+		GroupUUID:   group.GroupUUID,
+		State:       machineryv1tasks.StatePending,
+		CreatedAt:   time.Now(),
+		ScheduledAt: schedule.scheduledAt(),
 	}, nil
 }
 
-func (p *preheat) getLayers(ctx context.Context, url, tag, filter string, header http.Header, image *preheatImage) ([]internaljob.PreheatRequest, error) {
+func (p *preheat) getLayers(ctx context.Context, url, tag, filter string, header http.Header, image *preheatImage) ([]preheatFile, error) {
 	ctx, span := tracer.Start(ctx, config.SpanGetLayers, trace.WithSpanKind(trace.SpanKindProducer))
 	defer span.End()
 
@@ -232,7 +335,7 @@ func (p *preheat) getManifests(ctx context.Context, url string, header http.Head
 	return resp, nil
 }
 
-func (p *preheat) parseLayers(resp *http.Response, url, tag, filter string, header http.Header, image *preheatImage) ([]internaljob.PreheatRequest, error) {
+func (p *preheat) parseLayers(resp *http.Response, url, tag, filter string, header http.Header, image *preheatImage) ([]preheatFile, error) {
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, err
@@ -243,13 +346,16 @@ func (p *preheat) parseLayers(resp *http.Response, url, tag, filter string, head
 		return nil, err
 	}
 
-	var layers []internaljob.PreheatRequest
+	var layers []preheatFile
 	for _, v := range manifest.References() {
-		layer := internaljob.PreheatRequest{
-			URL:     layerURL(image.protocol, image.domain, image.name, v.Digest.String()),
-			Tag:     tag,
-			Filter:  filter,
-			Headers: nethttp.HeaderToMap(header),
+		layer := preheatFile{
+			PreheatRequest: internaljob.PreheatRequest{
+				URL:     layerURL(image.protocol, image.domain, image.name, v.Digest.String()),
+				Tag:     tag,
+				Filter:  filter,
+				Headers: nethttp.HeaderToMap(header),
+			},
+			size: v.Size,
 		}
 
 		layers = append(layers, layer)
@@ -258,6 +364,62 @@ func (p *preheat) parseLayers(resp *http.Response, url, tag, filter string, head
 	return layers, nil
 }
 
+func (p *preheat) getTags(ctx context.Context, url string, header http.Header) ([]string, error) {
+	ctx, span := tracer.Start(ctx, config.SpanGetTags, trace.WithSpanKind(trace.SpanKindProducer))
+	defer span.End()
+
+	resp, err := p.getRegistry(ctx, url, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		token, err := getAuthToken(ctx, resp.Header)
+		if err != nil {
+			return nil, err
+		}
+
+		header.Add(headers.Authorization, fmt.Sprintf("Bearer %s", token))
+		resp, err = p.getRegistry(ctx, url, header)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+	}
+
+	if resp.StatusCode/100 != 2 {
+		return nil, fmt.Errorf("request registry %d", resp.StatusCode)
+	}
+
+	var tagList struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tagList); err != nil {
+		return nil, err
+	}
+
+	return tagList.Tags, nil
+}
+
+func (p *preheat) getRegistry(ctx context.Context, url string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header = header
+	client := &http.Client{
+		Timeout: defaultHTTPRequesttimeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+
+	return client.Do(req)
+}
+
 func getAuthToken(ctx context.Context, header http.Header) (string, error) {
 	ctx, span := tracer.Start(ctx, config.SpanAuthWithRegistry, trace.WithSpanKind(trace.SpanKindProducer))
 	defer span.End()
@@ -321,6 +483,62 @@ func layerURL(protocol string, domain string, name string, digest string) string
 	return fmt.Sprintf("%s://%s/v2/%s/blobs/%s", protocol, domain, name, digest)
 }
 
+func manifestURL(protocol string, domain string, name string, tag string) string {
+	return fmt.Sprintf("%s://%s/v2/%s/manifests/%s", protocol, domain, name, tag)
+}
+
+func tagsURL(protocol string, domain string, name string) string {
+	return fmt.Sprintf("%s://%s/v2/%s/tags/list", protocol, domain, name)
+}
+
+func parseRepository(repository string) (*preheatImage, error) {
+	r := repositoryPattern.FindStringSubmatch(strings.TrimSuffix(repository, "/"))
+	if len(r) != 4 {
+		return nil, errors.New("parse repository failed")
+	}
+
+	return &preheatImage{
+		protocol: r[1],
+		domain:   r[2],
+		name:     strings.TrimPrefix(r[3], "v2/"),
+	}, nil
+}
+
+// matchTags returns the tags matching the pattern, the glob pattern is matched by path.Match
+// and the regex pattern must match the whole tag.
+func matchTags(tags []string, pattern string, patternType TagPatternType) ([]string, error) {
+	var match func(string) bool
+	switch patternType {
+	case TagPatternGlobType, "":
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob pattern %s: %w", pattern, err)
+		}
+
+		match = func(tag string) bool {
+			ok, _ := path.Match(pattern, tag)
+			return ok
+		}
+	case TagPatternRegexType:
+		re, err := regexp.Compile(fmt.Sprintf("^(?:%s)$", pattern))
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex pattern %s: %w", pattern, err)
+		}
+
+		match = re.MatchString
+	default:
+		return nil, fmt.Errorf("unknow tag pattern type %s", patternType)
+	}
+
+	var matchedTags []string
+	for _, tag := range tags {
+		if match(tag) {
+			matchedTags = append(matchedTags, tag)
+		}
+	}
+
+	return matchedTags, nil
+}
+
 func parseAccessURL(url string) (*preheatImage, error) {
 	r := accessURLPattern.FindStringSubmatch(url)
 	if len(r) != 5 {
@@ -0,0 +1,158 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// timeWindowLayout is the layout of the start and end of time window.
+const timeWindowLayout = "15:04"
+
+// timeWindow is the daily time window in local time, e.g. 02:00-05:00.
+// The window spans midnight if the end is before the start, e.g. 22:00-02:00.
+type timeWindow struct {
+	// start is the offset of window start from midnight.
+	start time.Duration
+
+	// end is the offset of window end from midnight.
+	end time.Duration
+}
+
+// parseTimeWindow parses the time window in format HH:MM-HH:MM.
+func parseTimeWindow(s string) (*timeWindow, error) {
+	fields := strings.Split(s, "-")
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("invalid time window %s", s)
+	}
+
+	start, err := time.Parse(timeWindowLayout, strings.TrimSpace(fields[0]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid time window %s: %w", s, err)
+	}
+
+	end, err := time.Parse(timeWindowLayout, strings.TrimSpace(fields[1]))
+	if err != nil {
+		return nil, fmt.Errorf("invalid time window %s: %w", s, err)
+	}
+
+	if start.Equal(end) {
+		return nil, fmt.Errorf("invalid time window %s: start equals end", s)
+	}
+
+	return &timeWindow{
+		start: time.Duration(start.Hour())*time.Hour + time.Duration(start.Minute())*time.Minute,
+		end:   time.Duration(end.Hour())*time.Hour + time.Duration(end.Minute())*time.Minute,
+	}, nil
+}
+
+// next returns t if t is in the window, otherwise the start of the next window after t.
+func (w *timeWindow) next(t time.Time) time.Time {
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	offset := t.Sub(midnight)
+
+	if w.start < w.end {
+		switch {
+		case offset < w.start:
+			return midnight.Add(w.start)
+		case offset < w.end:
+			return t
+		default:
+			return midnight.AddDate(0, 0, 1).Add(w.start)
+		}
+	}
+
+	// The window spans midnight.
+	if offset < w.end || offset >= w.start {
+		return t
+	}
+
+	return midnight.Add(w.start)
+}
+
+// preheatSchedule computes the time to run the preheat tasks. Tasks only start in the
+// time window, and they are spaced out by the time of downloading them with the max
+// bandwidth, so that the aggregate bandwidth of downloading from source is capped.
+type preheatSchedule struct {
+	// window is the time window to start tasks, nil means any time.
+	window *timeWindow
+
+	// maxBandwidth is the max aggregate bandwidth in bytes per second, zero means unlimited.
+	maxBandwidth int64
+
+	// now is the time of creating tasks.
+	now time.Time
+
+	// cursor is the earliest time to start the next task.
+	cursor time.Time
+
+	// last is the time to start the last task, zero means tasks start immediately.
+	last time.Time
+}
+
+// newPreheatSchedule returns a new preheat schedule by the time window and max bandwidth.
+func newPreheatSchedule(window string, maxBandwidth int64, now time.Time) (*preheatSchedule, error) {
+	s := &preheatSchedule{
+		maxBandwidth: maxBandwidth,
+		now:          now,
+		cursor:       now,
+	}
+
+	if window != "" {
+		w, err := parseTimeWindow(window)
+		if err != nil {
+			return nil, err
+		}
+
+		s.window = w
+	}
+
+	return s, nil
+}
+
+// next returns the time to run the task of the size, it returns nil if the task runs immediately.
+// Size of zero means the size is unknown and the task takes no bandwidth.
+func (s *preheatSchedule) next(size int64) *time.Time {
+	eta := s.cursor
+	if s.window != nil {
+		eta = s.window.next(eta)
+	}
+
+	s.cursor = eta
+	if s.maxBandwidth > 0 && size > 0 {
+		s.cursor = eta.Add(time.Duration(float64(size) / float64(s.maxBandwidth) * float64(time.Second)))
+	}
+
+	if !eta.After(s.now) {
+		return nil
+	}
+
+	s.last = eta
+	return &eta
+}
+
+// scheduledAt returns the time to run the last task, it returns nil if all tasks run immediately.
+func (s *preheatSchedule) scheduledAt() *time.Time {
+	if s.last.IsZero() {
+		return nil
+	}
+
+	last := s.last
+	return &last
+}
@@ -0,0 +1,224 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mockTime(day, hour, minute int) time.Time {
+	return time.Date(2023, time.March, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestPreheatSchedule_parseTimeWindow(t *testing.T) {
+	tests := []struct {
+		name   string
+		window string
+		expect func(t *testing.T, w *timeWindow, err error)
+	}{
+		{
+			name:   "parse time window",
+			window: "02:00-05:30",
+			expect: func(t *testing.T, w *timeWindow, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(&timeWindow{start: 2 * time.Hour, end: 5*time.Hour + 30*time.Minute}, w)
+			},
+		},
+		{
+			name:   "parse time window spans midnight",
+			window: "22:00 - 02:00",
+			expect: func(t *testing.T, w *timeWindow, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(&timeWindow{start: 22 * time.Hour, end: 2 * time.Hour}, w)
+			},
+		},
+		{
+			name:   "parse time window without end",
+			window: "02:00",
+			expect: func(t *testing.T, w *timeWindow, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "invalid time window 02:00")
+			},
+		},
+		{
+			name:   "parse time window with invalid time",
+			window: "02:00-25:00",
+			expect: func(t *testing.T, w *timeWindow, err error) {
+				assert := assert.New(t)
+				assert.ErrorContains(err, "invalid time window 02:00-25:00")
+			},
+		},
+		{
+			name:   "parse empty time window",
+			window: "02:00-02:00",
+			expect: func(t *testing.T, w *timeWindow, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "invalid time window 02:00-02:00: start equals end")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			w, err := parseTimeWindow(tc.window)
+			tc.expect(t, w, err)
+		})
+	}
+}
+
+func TestPreheatSchedule_timeWindowNext(t *testing.T) {
+	tests := []struct {
+		name   string
+		window timeWindow
+		t      time.Time
+		expect time.Time
+	}{
+		{
+			name:   "before window",
+			window: timeWindow{start: 2 * time.Hour, end: 5 * time.Hour},
+			t:      mockTime(1, 1, 0),
+			expect: mockTime(1, 2, 0),
+		},
+		{
+			name:   "in window",
+			window: timeWindow{start: 2 * time.Hour, end: 5 * time.Hour},
+			t:      mockTime(1, 3, 0),
+			expect: mockTime(1, 3, 0),
+		},
+		{
+			name:   "after window",
+			window: timeWindow{start: 2 * time.Hour, end: 5 * time.Hour},
+			t:      mockTime(1, 5, 0),
+			expect: mockTime(2, 2, 0),
+		},
+		{
+			name:   "in window spans midnight before midnight",
+			window: timeWindow{start: 22 * time.Hour, end: 2 * time.Hour},
+			t:      mockTime(1, 23, 0),
+			expect: mockTime(1, 23, 0),
+		},
+		{
+			name:   "in window spans midnight after midnight",
+			window: timeWindow{start: 22 * time.Hour, end: 2 * time.Hour},
+			t:      mockTime(2, 1, 0),
+			expect: mockTime(2, 1, 0),
+		},
+		{
+			name:   "out of window spans midnight",
+			window: timeWindow{start: 22 * time.Hour, end: 2 * time.Hour},
+			t:      mockTime(1, 12, 0),
+			expect: mockTime(1, 22, 0),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expect, tc.window.next(tc.t))
+		})
+	}
+}
+
+func TestPreheatSchedule_next(t *testing.T) {
+	tests := []struct {
+		name         string
+		window       string
+		maxBandwidth int64
+		now          time.Time
+		sizes        []int64
+		expect       func(t *testing.T, etas []*time.Time, scheduledAt *time.Time)
+	}{
+		{
+			name:  "run immediately without time window and max bandwidth",
+			now:   mockTime(1, 12, 0),
+			sizes: []int64{100, 100},
+			expect: func(t *testing.T, etas []*time.Time, scheduledAt *time.Time) {
+				assert := assert.New(t)
+				assert.Equal([]*time.Time{nil, nil}, etas)
+				assert.Nil(scheduledAt)
+			},
+		},
+		{
+			name:   "run immediately in time window",
+			window: "10:00-14:00",
+			now:    mockTime(1, 12, 0),
+			sizes:  []int64{100},
+			expect: func(t *testing.T, etas []*time.Time, scheduledAt *time.Time) {
+				assert := assert.New(t)
+				assert.Nil(etas[0])
+				assert.Nil(scheduledAt)
+			},
+		},
+		{
+			name:   "delay to time window",
+			window: "02:00-05:00",
+			now:    mockTime(1, 12, 0),
+			sizes:  []int64{100, 100},
+			expect: func(t *testing.T, etas []*time.Time, scheduledAt *time.Time) {
+				assert := assert.New(t)
+				assert.Equal(mockTime(2, 2, 0), *etas[0])
+				assert.Equal(mockTime(2, 2, 0), *etas[1])
+				assert.Equal(mockTime(2, 2, 0), *scheduledAt)
+			},
+		},
+		{
+			name:         "space out by max bandwidth",
+			maxBandwidth: 100,
+			now:          mockTime(1, 12, 0),
+			sizes:        []int64{1000, 0, 6000, 100},
+			expect: func(t *testing.T, etas []*time.Time, scheduledAt *time.Time) {
+				assert := assert.New(t)
+				assert.Nil(etas[0])
+				assert.Equal(mockTime(1, 12, 0).Add(10*time.Second), *etas[1])
+				assert.Equal(mockTime(1, 12, 0).Add(10*time.Second), *etas[2])
+				assert.Equal(mockTime(1, 12, 1).Add(10*time.Second), *etas[3])
+				assert.Equal(mockTime(1, 12, 1).Add(10*time.Second), *scheduledAt)
+			},
+		},
+		{
+			name:         "space out by max bandwidth to the next time window",
+			window:       "02:00-03:00",
+			maxBandwidth: 1,
+			now:          mockTime(1, 2, 0),
+			sizes:        []int64{3600, 10},
+			expect: func(t *testing.T, etas []*time.Time, scheduledAt *time.Time) {
+				assert := assert.New(t)
+				assert.Nil(etas[0])
+				assert.Equal(mockTime(2, 2, 0), *etas[1])
+				assert.Equal(mockTime(2, 2, 0), *scheduledAt)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			schedule, err := newPreheatSchedule(tc.window, tc.maxBandwidth, tc.now)
+			assert.NoError(t, err)
+
+			var etas []*time.Time
+			for _, size := range tc.sizes {
+				etas = append(etas, schedule.next(size))
+			}
+
+			tc.expect(t, etas, schedule.scheduledAt())
+		})
+	}
+}
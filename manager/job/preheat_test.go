@@ -0,0 +1,170 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package job
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreheat_matchTags(t *testing.T) {
+	tags := []string{"latest", "v1.0", "v1.1", "v1.10-rc", "v2.0"}
+	tests := []struct {
+		name        string
+		pattern     string
+		patternType TagPatternType
+		expect      func(t *testing.T, tags []string, err error)
+	}{
+		{
+			name:        "match tags by glob pattern",
+			pattern:     "v1.*",
+			patternType: TagPatternGlobType,
+			expect: func(t *testing.T, tags []string, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal([]string{"v1.0", "v1.1", "v1.10-rc"}, tags)
+			},
+		},
+		{
+			name:    "match tags by glob pattern without pattern type",
+			pattern: "v?.0",
+			expect: func(t *testing.T, tags []string, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal([]string{"v1.0", "v2.0"}, tags)
+			},
+		},
+		{
+			name:        "match tags by regex pattern",
+			pattern:     `v1\.\d+`,
+			patternType: TagPatternRegexType,
+			expect: func(t *testing.T, tags []string, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal([]string{"v1.0", "v1.1"}, tags)
+			},
+		},
+		{
+			name:        "match no tags",
+			pattern:     "v3.*",
+			patternType: TagPatternGlobType,
+			expect: func(t *testing.T, tags []string, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Empty(tags)
+			},
+		},
+		{
+			name:        "invalid glob pattern",
+			pattern:     "v1.[",
+			patternType: TagPatternGlobType,
+			expect: func(t *testing.T, tags []string, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "invalid glob pattern v1.[: syntax error in pattern")
+			},
+		},
+		{
+			name:        "invalid regex pattern",
+			pattern:     "v1.(",
+			patternType: TagPatternRegexType,
+			expect: func(t *testing.T, tags []string, err error) {
+				assert := assert.New(t)
+				assert.ErrorContains(err, "invalid regex pattern v1.(")
+			},
+		},
+		{
+			name:        "unknow pattern type",
+			pattern:     "v1.*",
+			patternType: "foo",
+			expect: func(t *testing.T, tags []string, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "unknow tag pattern type foo")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			matchedTags, err := matchTags(tags, tc.pattern, tc.patternType)
+			tc.expect(t, matchedTags, err)
+		})
+	}
+}
+
+func TestPreheat_parseRepository(t *testing.T) {
+	tests := []struct {
+		name       string
+		repository string
+		expect     func(t *testing.T, image *preheatImage, err error)
+	}{
+		{
+			name:       "parse repository",
+			repository: "https://index.docker.io/library/alpine",
+			expect: func(t *testing.T, image *preheatImage, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(&preheatImage{protocol: "https", domain: "index.docker.io", name: "library/alpine"}, image)
+			},
+		},
+		{
+			name:       "parse repository with api version",
+			repository: "http://127.0.0.1:5000/v2/app/",
+			expect: func(t *testing.T, image *preheatImage, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(&preheatImage{protocol: "http", domain: "127.0.0.1:5000", name: "app"}, image)
+			},
+		},
+		{
+			name:       "parse repository without name",
+			repository: "https://index.docker.io",
+			expect: func(t *testing.T, image *preheatImage, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "parse repository failed")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			image, err := parseRepository(tc.repository)
+			tc.expect(t, image, err)
+		})
+	}
+}
+
+func TestPreheat_getTags(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal("/v2/library/app/tags/list", r.URL.Path)
+		fmt.Fprint(w, `{"name":"library/app","tags":["v1.0","v1.1"]}`)
+	}))
+	defer server.Close()
+
+	image, err := parseRepository(fmt.Sprintf("%s/library/app", server.URL))
+	assert.NoError(err)
+
+	p := &preheat{}
+	tags, err := p.getTags(context.Background(), tagsURL(image.protocol, image.domain, image.name), http.Header{})
+	assert.NoError(err)
+	assert.Equal([]string{"v1.0", "v1.1"}, tags)
+}
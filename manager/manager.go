@@ -41,8 +41,10 @@ import (
 	"d7y.io/dragonfly/v2/manager/rpcserver"
 	"d7y.io/dragonfly/v2/manager/searcher"
 	"d7y.io/dragonfly/v2/manager/service"
+	"d7y.io/dragonfly/v2/manager/webhook"
 	pkgcache "d7y.io/dragonfly/v2/pkg/cache"
 	"d7y.io/dragonfly/v2/pkg/dfpath"
+	"d7y.io/dragonfly/v2/pkg/gc"
 	"d7y.io/dragonfly/v2/pkg/issuer"
 	"d7y.io/dragonfly/v2/pkg/objectstorage"
 	"d7y.io/dragonfly/v2/pkg/rpc"
@@ -96,6 +98,9 @@ type Server struct {
 
 	// Metrics server
 	metricsServer *http.Server
+
+	// GC server
+	gc gc.GC
 }
 
 func New(cfg *config.Config, d dfpath.Dfpath) (*Server, error) {
@@ -128,6 +133,9 @@ func New(cfg *config.Config, d dfpath.Dfpath) (*Server, error) {
 		return nil, err
 	}
 
+	// Initialize webhook
+	webhook := webhook.New(webhook.WithTimeout(cfg.Webhook.Timeout), webhook.WithMaxAttempts(cfg.Webhook.MaxAttempts))
+
 	// Initialize object storage
 	var objectStorage objectstorage.ObjectStorage
 	if cfg.ObjectStorage.Enable {
@@ -145,7 +153,7 @@ func New(cfg *config.Config, d dfpath.Dfpath) (*Server, error) {
 	}
 
 	// Initialize REST server
-	restService := service.New(db, cache, job, enforcer, objectStorage)
+	restService := service.New(db, cache, job, webhook, enforcer, objectStorage)
 	router, err := router.Init(cfg, d.LogDir(), restService, enforcer, EmbedFolder(assets, assetsTargetPath))
 	if err != nil {
 		return nil, err
@@ -155,6 +163,23 @@ func New(cfg *config.Config, d dfpath.Dfpath) (*Server, error) {
 		Handler: router,
 	}
 
+	// Initialize audit GC
+	if cfg.Audit.Enable {
+		s.gc = gc.New(gc.WithLogger(logger.GCLogger))
+		if err := s.gc.Add(gc.Task{
+			ID:       auditGCID,
+			Interval: cfg.Audit.GCInterval,
+			Timeout:  cfg.Audit.GCInterval,
+			Runner: &auditGC{
+				service:   restService,
+				retention: cfg.Audit.Retention,
+				timeout:   cfg.Audit.GCInterval,
+			},
+		}); err != nil {
+			return nil, err
+		}
+	}
+
 	// Initialize roles and check roles
 	err = rbac.InitRBAC(enforcer, router, db.DB)
 	if err != nil {
@@ -252,6 +277,12 @@ func (s *Server) Serve() error {
 		}()
 	}
 
+	// Started GC server
+	if s.gc != nil {
+		s.gc.Start()
+		logger.Info("started gc server")
+	}
+
 	// Generate GRPC listener
 	lis, _, err := rpc.ListenWithPortRange(s.config.Server.GRPC.ListenIP.String(), s.config.Server.GRPC.PortRange.Start, s.config.Server.GRPC.PortRange.End)
 	if err != nil {
@@ -286,6 +317,12 @@ func (s *Server) Stop() {
 		}
 	}
 
+	// Stop GC server
+	if s.gc != nil {
+		s.gc.Stop()
+		logger.Info("gc server closed under request")
+	}
+
 	// Stop GRPC server
 	stopped := make(chan struct{})
 	go func() {
@@ -53,6 +53,56 @@ var (
 		Help:      "Counter of the number of failed of searching scheduler cluster.",
 	}, []string{"version", "commit"})
 
+	JobCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.ManagerMetricsName,
+		Name:      "job_total",
+		Help:      "Counter of the number of the job.",
+	}, []string{"type"})
+
+	JobFailureCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.ManagerMetricsName,
+		Name:      "job_failure_total",
+		Help:      "Counter of the number of failed of the job.",
+	}, []string{"type"})
+
+	QueuedJobGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.ManagerMetricsName,
+		Name:      "queued_job_total",
+		Help:      "Gauge of the number of the job waiting to be received by schedulers.",
+	}, []string{"type"})
+
+	RunningJobGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.ManagerMetricsName,
+		Name:      "running_job_total",
+		Help:      "Gauge of the number of the job running in schedulers.",
+	}, []string{"type"})
+
+	JobDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.ManagerMetricsName,
+		Name:      "job_duration_milliseconds",
+		Help:      "Histogram of the time each job from creating to finishing.",
+		Buckets:   []float64{1000, 5 * 1000, 10 * 1000, 30 * 1000, 60 * 1000, 300 * 1000, 600 * 1000, 1800 * 1000, 3600 * 1000, 7200 * 1000, 21600 * 1000},
+	}, []string{"type", "state"})
+
+	PreheatLayerCount = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.ManagerMetricsName,
+		Name:      "preheat_layer_total",
+		Help:      "Counter of the number of the layer preheated in scheduler clusters.",
+	})
+
+	PreheatLayerFailureCount = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.ManagerMetricsName,
+		Name:      "preheat_layer_failure_total",
+		Help:      "Counter of the number of failed of the layer preheated in scheduler clusters.",
+	})
+
 	VersionGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: types.MetricsNamespace,
 		Subsystem: types.ManagerMetricsName,
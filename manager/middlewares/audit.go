@@ -0,0 +1,201 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/manager/models"
+	"d7y.io/dragonfly/v2/manager/permission/rbac"
+	"d7y.io/dragonfly/v2/manager/service"
+)
+
+const (
+	// maxAuditBodySize is the max size of request and response body recorded in audit.
+	maxAuditBodySize = 64 * 1024
+
+	// redactedValue replaces the sensitive values in audit.
+	redactedValue = "******"
+)
+
+// auditedMethods are the http methods of mutating api calls.
+var auditedMethods = map[string]struct{}{
+	http.MethodPost:   {},
+	http.MethodPut:    {},
+	http.MethodPatch:  {},
+	http.MethodDelete: {},
+}
+
+// unauditedPaths are the routes which mutate no resources, they only sign in and out users.
+var unauditedPaths = map[string]struct{}{
+	"/api/v1/users/signin":        {},
+	"/api/v1/users/signout":       {},
+	"/api/v1/users/refresh_token": {},
+}
+
+// sensitiveKeys are the substrings of keys whose values are redacted in audit.
+var sensitiveKeys = []string{"password", "secret", "token"}
+
+// auditResponseWriter records the response body written by handlers.
+type auditResponseWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+// Write writes the response body and records it if it does not exceed the max size.
+func (w *auditResponseWriter) Write(b []byte) (int, error) {
+	if w.body.Len()+len(b) <= maxAuditBodySize {
+		w.body.Write(b)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+// Audit records every mutating api call, with the user, the request and
+// the states of resource before and after the call.
+func Audit(service service.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := auditedMethods[c.Request.Method]; !ok {
+			c.Next()
+			return
+		}
+
+		path := c.FullPath()
+		if _, ok := unauditedPaths[path]; ok || path == "" {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		audit := models.Audit{
+			IP:       c.ClientIP(),
+			Method:   c.Request.Method,
+			Path:     c.Request.URL.Path,
+			Resource: auditResource(c.Request.URL.Path),
+		}
+
+		if c.Request.Body != nil {
+			body, err := io.ReadAll(c.Request.Body)
+			if err != nil {
+				logger.Errorf("audit read request body failed: %s", err.Error())
+			}
+
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+			if len(body) <= maxAuditBodySize {
+				audit.RequestBody = redact(unmarshalJSONMap(body))
+			}
+		}
+
+		if id, err := strconv.ParseUint(c.Param("id"), 10, 64); err == nil {
+			audit.ResourceID = uint(id)
+			if state, err := service.GetAuditResourceState(ctx, audit.Resource, audit.ResourceID); err == nil {
+				audit.BeforeState = redact(state)
+			}
+		}
+
+		w := &auditResponseWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = w
+		c.Next()
+
+		audit.StatusCode = c.Writer.Status()
+		if rawID, ok := c.Get(defaultIdentityKey); ok {
+			if id, ok := rawID.(float64); ok {
+				audit.UserID = uint(id)
+			}
+		}
+
+		// The id of created resource is in the response.
+		if audit.ResourceID == 0 && audit.StatusCode < http.StatusBadRequest {
+			if id, ok := unmarshalJSONMap(w.body.Bytes())["id"].(float64); ok {
+				audit.ResourceID = uint(id)
+			}
+		}
+
+		if audit.ResourceID > 0 && audit.StatusCode < http.StatusBadRequest {
+			if state, err := service.GetAuditResourceState(ctx, audit.Resource, audit.ResourceID); err == nil {
+				audit.AfterState = redact(state)
+			}
+		}
+
+		if err := service.CreateAudit(ctx, &audit); err != nil {
+			logger.Errorf("create audit of %s %s failed: %s", audit.Method, audit.Path, err.Error())
+		}
+	}
+}
+
+// auditResource returns the api group name of path, or the first segment of path
+// if it is not an api path.
+func auditResource(path string) string {
+	if resource, err := rbac.GetAPIGroupName(path); err == nil {
+		return resource
+	}
+
+	return strings.Split(strings.TrimPrefix(path, "/"), "/")[0]
+}
+
+// unmarshalJSONMap returns the json object in b, or nil if b is not a json object.
+func unmarshalJSONMap(b []byte) map[string]any {
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil
+	}
+
+	return m
+}
+
+// redact replaces the values of sensitive keys recursively.
+func redact(m map[string]any) map[string]any {
+	for k, v := range m {
+		if isSensitiveKey(k) {
+			m[k] = redactedValue
+			continue
+		}
+
+		switch v := v.(type) {
+		case map[string]any:
+			m[k] = redact(v)
+		case []any:
+			for _, item := range v {
+				if item, ok := item.(map[string]any); ok {
+					redact(item)
+				}
+			}
+		}
+	}
+
+	return m
+}
+
+// isSensitiveKey returns whether the value of key is sensitive.
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitiveKey := range sensitiveKeys {
+		if strings.Contains(key, sensitiveKey) {
+			return true
+		}
+	}
+
+	return false
+}
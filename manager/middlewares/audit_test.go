@@ -0,0 +1,160 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package middlewares
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/manager/models"
+	"d7y.io/dragonfly/v2/manager/service/mocks"
+)
+
+func TestAudit(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		mock   func(ms *mocks.MockServiceMockRecorder, audits *[]*models.Audit)
+		expect func(t *testing.T, audits []*models.Audit)
+	}{
+		{
+			name:   "audit updating resource",
+			method: http.MethodPatch,
+			path:   "/api/v1/schedulers/1",
+			body:   `{"bio":"bar","password":"baz"}`,
+			mock: func(ms *mocks.MockServiceMockRecorder, audits *[]*models.Audit) {
+				gomock.InOrder(
+					ms.GetAuditResourceState(gomock.Any(), "schedulers", uint(1)).Return(map[string]any{"id": float64(1), "bio": "foo"}, nil).Times(1),
+					ms.GetAuditResourceState(gomock.Any(), "schedulers", uint(1)).Return(map[string]any{"id": float64(1), "bio": "bar"}, nil).Times(1),
+					ms.CreateAudit(gomock.Any(), gomock.Any()).DoAndReturn(recordAudit(audits)).Times(1),
+				)
+			},
+			expect: func(t *testing.T, audits []*models.Audit) {
+				assert := assert.New(t)
+				assert.Len(audits, 1)
+				assert.Equal(uint(10), audits[0].UserID)
+				assert.Equal(http.MethodPatch, audits[0].Method)
+				assert.Equal("/api/v1/schedulers/1", audits[0].Path)
+				assert.Equal("schedulers", audits[0].Resource)
+				assert.Equal(uint(1), audits[0].ResourceID)
+				assert.Equal(http.StatusOK, audits[0].StatusCode)
+				assert.Equal(models.JSONMap{"bio": "bar", "password": redactedValue}, audits[0].RequestBody)
+				assert.Equal(models.JSONMap{"id": float64(1), "bio": "foo"}, audits[0].BeforeState)
+				assert.Equal(models.JSONMap{"id": float64(1), "bio": "bar"}, audits[0].AfterState)
+			},
+		},
+		{
+			name:   "audit creating resource",
+			method: http.MethodPost,
+			path:   "/api/v1/schedulers",
+			body:   `{"bio":"bar"}`,
+			mock: func(ms *mocks.MockServiceMockRecorder, audits *[]*models.Audit) {
+				gomock.InOrder(
+					ms.GetAuditResourceState(gomock.Any(), "schedulers", uint(2)).Return(map[string]any{"id": float64(2), "bio": "bar"}, nil).Times(1),
+					ms.CreateAudit(gomock.Any(), gomock.Any()).DoAndReturn(recordAudit(audits)).Times(1),
+				)
+			},
+			expect: func(t *testing.T, audits []*models.Audit) {
+				assert := assert.New(t)
+				assert.Len(audits, 1)
+				assert.Equal(uint(2), audits[0].ResourceID)
+				assert.Nil(audits[0].BeforeState)
+				assert.Equal(models.JSONMap{"id": float64(2), "bio": "bar"}, audits[0].AfterState)
+			},
+		},
+		{
+			name:   "audit failed call",
+			method: http.MethodDelete,
+			path:   "/api/v1/schedulers/3",
+			mock: func(ms *mocks.MockServiceMockRecorder, audits *[]*models.Audit) {
+				gomock.InOrder(
+					ms.GetAuditResourceState(gomock.Any(), "schedulers", uint(3)).Return(nil, errors.New("foo")).Times(1),
+					ms.CreateAudit(gomock.Any(), gomock.Any()).DoAndReturn(recordAudit(audits)).Times(1),
+				)
+			},
+			expect: func(t *testing.T, audits []*models.Audit) {
+				assert := assert.New(t)
+				assert.Len(audits, 1)
+				assert.Equal(http.StatusInternalServerError, audits[0].StatusCode)
+				assert.Nil(audits[0].BeforeState)
+				assert.Nil(audits[0].AfterState)
+			},
+		},
+		{
+			name:   "skip reading resource",
+			method: http.MethodGet,
+			path:   "/api/v1/schedulers/1",
+			mock:   func(ms *mocks.MockServiceMockRecorder, audits *[]*models.Audit) {},
+			expect: func(t *testing.T, audits []*models.Audit) {
+				assert := assert.New(t)
+				assert.Empty(audits)
+			},
+		},
+		{
+			name:   "skip signing in",
+			method: http.MethodPost,
+			path:   "/api/v1/users/signin",
+			body:   `{"name":"foo","password":"bar"}`,
+			mock:   func(ms *mocks.MockServiceMockRecorder, audits *[]*models.Audit) {},
+			expect: func(t *testing.T, audits []*models.Audit) {
+				assert := assert.New(t)
+				assert.Empty(audits)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			svc := mocks.NewMockService(ctl)
+
+			var audits []*models.Audit
+			tc.mock(svc.EXPECT(), &audits)
+
+			r := gin.New()
+			r.Use(Audit(svc))
+			identity := func(c *gin.Context) { c.Set(defaultIdentityKey, float64(10)) }
+			r.POST("/api/v1/schedulers", identity, func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"id": 2}) })
+			r.PATCH("/api/v1/schedulers/:id", identity, func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"id": 1}) })
+			r.DELETE("/api/v1/schedulers/:id", identity, func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+			r.GET("/api/v1/schedulers/:id", identity, func(c *gin.Context) { c.Status(http.StatusOK) })
+			r.POST("/api/v1/users/signin", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body)))
+			tc.expect(t, audits)
+		})
+	}
+}
+
+func recordAudit(audits *[]*models.Audit) func(any, *models.Audit) error {
+	return func(_ any, audit *models.Audit) error {
+		*audits = append(*audits, audit)
+		return nil
+	}
+}
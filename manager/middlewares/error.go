@@ -31,6 +31,7 @@ import (
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
 
 	"d7y.io/dragonfly/v2/internal/dferrors"
+	"d7y.io/dragonfly/v2/manager/service"
 )
 
 type ErrorResponse struct {
@@ -93,6 +94,15 @@ func Error() gin.HandlerFunc {
 			return
 		}
 
+		// Duplicated key error handler, translated from mysql and postgres errors.
+		if errors.Is(err.Err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Message: http.StatusText(http.StatusConflict),
+			})
+			c.Abort()
+			return
+		}
+
 		// Mysql error handler
 		var merr *mysql.MySQLError
 		if errors.As(err.Err, &merr) {
@@ -120,6 +130,24 @@ func Error() gin.HandlerFunc {
 			return
 		}
 
+		// Permission error handler
+		if errors.Is(err.Err, service.ErrPermissionDenied) {
+			c.JSON(http.StatusUnauthorized, ErrorResponse{
+				Message: "permission deny",
+			})
+			c.Abort()
+			return
+		}
+
+		// Quota error handler
+		if errors.Is(err.Err, service.ErrTenantQuotaExceeded) {
+			c.JSON(http.StatusTooManyRequests, ErrorResponse{
+				Message: err.Err.Error(),
+			})
+			c.Abort()
+			return
+		}
+
 		// Unknown error
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Message: err.Err.Error(),
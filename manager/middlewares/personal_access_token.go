@@ -0,0 +1,150 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package middlewares
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/manager/models"
+	"d7y.io/dragonfly/v2/manager/permission/rbac"
+	"d7y.io/dragonfly/v2/manager/service"
+)
+
+// PersonalAccessTokenKey is the context key of the authenticated personal access token.
+const PersonalAccessTokenKey = "personal_access_token"
+
+// clusterAdminAPIGroups are the api groups of cluster resources, managed by
+// the personal access token with the cluster-admin scope.
+var clusterAdminAPIGroups = map[string]struct{}{
+	"applications":       {},
+	"buckets":            {},
+	"clusters":           {},
+	"configs":            {},
+	"jobs":               {},
+	"models":             {},
+	"scheduler-clusters": {},
+	"schedulers":         {},
+	"searcher-rules":     {},
+	"seed-peer-clusters": {},
+	"seed-peers":         {},
+}
+
+// identityAPIGroups are the api groups of users and their permissions, which are
+// not allowed by any scope of the personal access token.
+var identityAPIGroups = map[string]struct{}{
+	"permissions":            {},
+	"personal-access-tokens": {},
+	"roles":                  {},
+	"users":                  {},
+}
+
+// PersonalAccessToken authenticates the request carrying the personal access token
+// in the authorization header, and rejects it if the scopes of token do not allow it.
+// The requests without personal access token are passed to the next handlers.
+func PersonalAccessToken(service service.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !strings.HasPrefix(token, models.PersonalAccessTokenPrefix) {
+			c.Next()
+			return
+		}
+
+		personalAccessToken, err := service.ValidatePersonalAccessToken(c.Request.Context(), token)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"message": "Unavailable token: " + err.Error(),
+			})
+			c.Abort()
+			return
+		}
+
+		apiGroupName, err := rbac.GetAPIGroupName(c.Request.URL.Path)
+		if err != nil || !scopesAllow(personalAccessToken.Scopes, apiGroupName, c.Request.Method) {
+			logger.Infof("personal access token %d is denied to %s %s", personalAccessToken.ID, c.Request.Method, c.Request.URL.Path)
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"message": "permission deny",
+			})
+			c.Abort()
+			return
+		}
+
+		c.Set(defaultIdentityKey, float64(personalAccessToken.UserID))
+		c.Set(PersonalAccessTokenKey, personalAccessToken)
+		c.Next()
+	}
+}
+
+// Auth skips the jwt authentication if the request is authenticated by personal access token.
+func Auth(jwt gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := c.Get(PersonalAccessTokenKey); ok {
+			c.Next()
+			return
+		}
+
+		jwt(c)
+	}
+}
+
+// JobAuth returns the handlers authenticating the job apis and checking their permissions. If anonymous
+// is true, the requests without token in the authorization header are passed to the job apis anonymously
+// for compatibility, and the requests with token are authenticated and checked the same as disabled.
+func JobAuth(anonymous bool, auth, rbac gin.HandlerFunc) []gin.HandlerFunc {
+	if !anonymous {
+		return []gin.HandlerFunc{auth, rbac}
+	}
+
+	return []gin.HandlerFunc{ifAuthorizationPresented(auth), ifAuthorizationPresented(rbac)}
+}
+
+// ifAuthorizationPresented runs the handler only if the request carries a token in the authorization header.
+func ifAuthorizationPresented(handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+
+		handler(c)
+	}
+}
+
+// scopesAllow returns whether the scopes allow the http method on the api group.
+func scopesAllow(scopes []string, apiGroupName, method string) bool {
+	action := rbac.HTTPMethodToAction(method)
+	for _, scope := range scopes {
+		switch scope {
+		case models.PersonalAccessTokenScopeRead:
+			if _, ok := identityAPIGroups[apiGroupName]; !ok && action == rbac.ReadAction {
+				return true
+			}
+		case models.PersonalAccessTokenScopePreheat:
+			if apiGroupName == "jobs" && (action == rbac.ReadAction || method == http.MethodPost) {
+				return true
+			}
+		case models.PersonalAccessTokenScopeClusterAdmin:
+			if _, ok := clusterAdminAPIGroups[apiGroupName]; ok {
+				return true
+			}
+		}
+	}
+
+	return false
+}
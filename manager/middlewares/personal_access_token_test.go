@@ -0,0 +1,317 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/manager/models"
+	"d7y.io/dragonfly/v2/manager/service"
+	"d7y.io/dragonfly/v2/manager/service/mocks"
+)
+
+func TestPersonalAccessToken(t *testing.T) {
+	mockToken := models.PersonalAccessTokenPrefix + "foo"
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		authorization string
+		mock          func(ms *mocks.MockServiceMockRecorder)
+		expect        func(t *testing.T, code int)
+	}{
+		{
+			name:          "pass request with jwt token",
+			method:        http.MethodDelete,
+			path:          "/api/v1/users/1",
+			authorization: "Bearer bar",
+			mock:          func(ms *mocks.MockServiceMockRecorder) {},
+			expect: func(t *testing.T, code int) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusTeapot, code)
+			},
+		},
+		{
+			name:          "reject invalid token",
+			method:        http.MethodGet,
+			path:          "/api/v1/schedulers/1",
+			authorization: "Bearer " + mockToken,
+			mock: func(ms *mocks.MockServiceMockRecorder) {
+				ms.ValidatePersonalAccessToken(gomock.Any(), mockToken).Return(nil, service.ErrInvalidPersonalAccessToken).Times(1)
+			},
+			expect: func(t *testing.T, code int) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusUnauthorized, code)
+			},
+		},
+		{
+			name:          "allow reading with read scope",
+			method:        http.MethodGet,
+			path:          "/api/v1/schedulers/1",
+			authorization: "Bearer " + mockToken,
+			mock: func(ms *mocks.MockServiceMockRecorder) {
+				ms.ValidatePersonalAccessToken(gomock.Any(), mockToken).Return(&models.PersonalAccessToken{
+					Scopes: []string{models.PersonalAccessTokenScopeRead},
+					UserID: 1,
+				}, nil).Times(1)
+			},
+			expect: func(t *testing.T, code int) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, code)
+			},
+		},
+		{
+			name:          "deny reading users with read scope",
+			method:        http.MethodGet,
+			path:          "/api/v1/users/1",
+			authorization: "Bearer " + mockToken,
+			mock: func(ms *mocks.MockServiceMockRecorder) {
+				ms.ValidatePersonalAccessToken(gomock.Any(), mockToken).Return(&models.PersonalAccessToken{
+					Scopes: []string{models.PersonalAccessTokenScopeRead},
+				}, nil).Times(1)
+			},
+			expect: func(t *testing.T, code int) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusUnauthorized, code)
+			},
+		},
+		{
+			name:          "deny reading personal access tokens with read scope",
+			method:        http.MethodGet,
+			path:          "/api/v1/personal-access-tokens",
+			authorization: "Bearer " + mockToken,
+			mock: func(ms *mocks.MockServiceMockRecorder) {
+				ms.ValidatePersonalAccessToken(gomock.Any(), mockToken).Return(&models.PersonalAccessToken{
+					Scopes: []string{models.PersonalAccessTokenScopeRead},
+				}, nil).Times(1)
+			},
+			expect: func(t *testing.T, code int) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusUnauthorized, code)
+			},
+		},
+		{
+			name:          "deny writing with read scope",
+			method:        http.MethodPatch,
+			path:          "/api/v1/schedulers/1",
+			authorization: "Bearer " + mockToken,
+			mock: func(ms *mocks.MockServiceMockRecorder) {
+				ms.ValidatePersonalAccessToken(gomock.Any(), mockToken).Return(&models.PersonalAccessToken{
+					Scopes: []string{models.PersonalAccessTokenScopeRead},
+				}, nil).Times(1)
+			},
+			expect: func(t *testing.T, code int) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusUnauthorized, code)
+			},
+		},
+		{
+			name:          "allow creating job with preheat scope",
+			method:        http.MethodPost,
+			path:          "/api/v1/jobs",
+			authorization: "Bearer " + mockToken,
+			mock: func(ms *mocks.MockServiceMockRecorder) {
+				ms.ValidatePersonalAccessToken(gomock.Any(), mockToken).Return(&models.PersonalAccessToken{
+					Scopes: []string{models.PersonalAccessTokenScopePreheat},
+				}, nil).Times(1)
+			},
+			expect: func(t *testing.T, code int) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, code)
+			},
+		},
+		{
+			name:          "deny destroying job with preheat scope",
+			method:        http.MethodDelete,
+			path:          "/api/v1/jobs/1",
+			authorization: "Bearer " + mockToken,
+			mock: func(ms *mocks.MockServiceMockRecorder) {
+				ms.ValidatePersonalAccessToken(gomock.Any(), mockToken).Return(&models.PersonalAccessToken{
+					Scopes: []string{models.PersonalAccessTokenScopePreheat},
+				}, nil).Times(1)
+			},
+			expect: func(t *testing.T, code int) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusUnauthorized, code)
+			},
+		},
+		{
+			name:          "allow managing cluster with cluster-admin scope",
+			method:        http.MethodPatch,
+			path:          "/api/v1/schedulers/1",
+			authorization: "Bearer " + mockToken,
+			mock: func(ms *mocks.MockServiceMockRecorder) {
+				ms.ValidatePersonalAccessToken(gomock.Any(), mockToken).Return(&models.PersonalAccessToken{
+					Scopes: []string{models.PersonalAccessTokenScopeClusterAdmin},
+				}, nil).Times(1)
+			},
+			expect: func(t *testing.T, code int) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, code)
+			},
+		},
+		{
+			name:          "deny managing user with cluster-admin scope",
+			method:        http.MethodDelete,
+			path:          "/api/v1/users/1",
+			authorization: "Bearer " + mockToken,
+			mock: func(ms *mocks.MockServiceMockRecorder) {
+				ms.ValidatePersonalAccessToken(gomock.Any(), mockToken).Return(&models.PersonalAccessToken{
+					Scopes: []string{models.PersonalAccessTokenScopeClusterAdmin},
+				}, nil).Times(1)
+			},
+			expect: func(t *testing.T, code int) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusUnauthorized, code)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			svc := mocks.NewMockService(ctl)
+			tc.mock(svc.EXPECT())
+
+			r := gin.New()
+			r.Use(PersonalAccessToken(svc))
+			jwt := func(c *gin.Context) { c.AbortWithStatus(http.StatusTeapot) }
+			handler := func(c *gin.Context) { c.Status(http.StatusOK) }
+			r.Any("/api/v1/:group", Auth(jwt), handler)
+			r.Any("/api/v1/:group/:id", Auth(jwt), handler)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			req.Header.Set("Authorization", tc.authorization)
+			r.ServeHTTP(w, req)
+			tc.expect(t, w.Code)
+		})
+	}
+}
+
+func TestJobAuth(t *testing.T) {
+	tests := []struct {
+		name          string
+		anonymous     bool
+		authorization string
+		allowed       bool
+		expect        func(t *testing.T, code int)
+	}{
+		{
+			name:      "pass request without token if anonymous is enabled",
+			anonymous: true,
+			expect: func(t *testing.T, code int) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, code)
+			},
+		},
+		{
+			name:          "authenticate request with token if anonymous is enabled",
+			anonymous:     true,
+			authorization: "Bearer bar",
+			expect: func(t *testing.T, code int) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusUnauthorized, code)
+			},
+		},
+		{
+			name:          "check permissions of request with token if anonymous is enabled",
+			anonymous:     true,
+			authorization: "Bearer foo",
+			expect: func(t *testing.T, code int) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusForbidden, code)
+			},
+		},
+		{
+			name:          "pass request with allowed token if anonymous is enabled",
+			anonymous:     true,
+			authorization: "Bearer foo",
+			allowed:       true,
+			expect: func(t *testing.T, code int) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, code)
+			},
+		},
+		{
+			name: "reject request without token if anonymous is disabled",
+			expect: func(t *testing.T, code int) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusUnauthorized, code)
+			},
+		},
+		{
+			name:          "check permissions of request with token if anonymous is disabled",
+			authorization: "Bearer foo",
+			expect: func(t *testing.T, code int) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusForbidden, code)
+			},
+		},
+		{
+			name:          "pass request with allowed token if anonymous is disabled",
+			authorization: "Bearer foo",
+			allowed:       true,
+			expect: func(t *testing.T, code int) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, code)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+
+			// The token foo is valid, and it is allowed to access the job apis if allowed is true.
+			auth := func(c *gin.Context) {
+				if c.GetHeader("Authorization") != "Bearer foo" {
+					c.AbortWithStatus(http.StatusUnauthorized)
+					return
+				}
+
+				c.Next()
+			}
+			rbac := func(c *gin.Context) {
+				if !tc.allowed {
+					c.AbortWithStatus(http.StatusForbidden)
+					return
+				}
+
+				c.Next()
+			}
+			handlers := append(JobAuth(tc.anonymous, auth, rbac), func(c *gin.Context) { c.Status(http.StatusOK) })
+			r.POST("/api/v1/jobs", handlers...)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/jobs", nil)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			r.ServeHTTP(w, req)
+			tc.expect(t, w.Code)
+		})
+	}
+}
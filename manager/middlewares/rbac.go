@@ -24,6 +24,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/manager/models"
 	"d7y.io/dragonfly/v2/manager/permission/rbac"
 )
 
@@ -49,6 +50,14 @@ func RBAC(e *casbin.Enforcer) gin.HandlerFunc {
 			return
 		}
 
+		// Service token is only restricted by its scopes.
+		if rawToken, ok := c.Get(PersonalAccessTokenKey); ok {
+			if token, ok := rawToken.(*models.PersonalAccessToken); ok && token.Type == models.PersonalAccessTokenTypeService {
+				c.Next()
+				return
+			}
+		}
+
 		if ok, err := e.Enforce(fmt.Sprint(id.(float64)), permission, action); err != nil {
 			logger.Errorf("RBAC validate error: %s", err)
 			c.JSON(http.StatusUnauthorized, gin.H{
@@ -0,0 +1,31 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+type Audit struct {
+	BaseModel
+	UserID      uint    `gorm:"column:user_id;index:idx_audit_user_id;comment:user id" json:"user_id"`
+	IP          string  `gorm:"column:ip;type:varchar(256);comment:client ip" json:"ip"`
+	Method      string  `gorm:"column:method;type:varchar(256);not null;comment:http method" json:"method"`
+	Path        string  `gorm:"column:path;type:varchar(1024);not null;comment:request path" json:"path"`
+	Resource    string  `gorm:"column:resource;type:varchar(256);index:idx_audit_resource;comment:resource type" json:"resource"`
+	ResourceID  uint    `gorm:"column:resource_id;index:idx_audit_resource;comment:resource id" json:"resource_id"`
+	StatusCode  int     `gorm:"column:status_code;comment:response status code" json:"status_code"`
+	RequestBody JSONMap `gorm:"column:request_body;comment:request body" json:"request_body"`
+	BeforeState JSONMap `gorm:"column:before_state;comment:resource state before request" json:"before_state"`
+	AfterState  JSONMap `gorm:"column:after_state;comment:resource state after request" json:"after_state"`
+}
@@ -0,0 +1,29 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+// ConfigVersion is the immutable snapshot of cluster configuration,
+// a new version is created whenever the configuration of cluster is changed.
+type ConfigVersion struct {
+	BaseModel
+	ClusterType     string  `gorm:"column:cluster_type;type:varchar(256);index:uk_config_version,unique;not null;comment:cluster type" json:"cluster_type"`
+	ClusterID       uint    `gorm:"column:cluster_id;index:uk_config_version,unique;not null;comment:cluster id" json:"cluster_id"`
+	Version         uint    `gorm:"column:version;index:uk_config_version,unique;not null;comment:version of cluster configuration" json:"version"`
+	Config          JSONMap `gorm:"column:config;not null;comment:configuration snapshot" json:"config"`
+	Digest          string  `gorm:"column:digest;type:varchar(256);index:idx_config_version_digest;not null;comment:digest of configuration delivered to consumers" json:"digest"`
+	RollbackVersion uint    `gorm:"column:rollback_version;comment:version rolled back to" json:"rollback_version"`
+}
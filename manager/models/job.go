@@ -26,6 +26,7 @@ type Job struct {
 	Result            JSONMap            `gorm:"column:result;comment:task result" json:"result"`
 	UserID            uint               `gorm:"column:user_id;comment:user id" json:"user_id"`
 	User              User               `json:"-"`
+	TenantID          uint               `gorm:"index:idx_job_tenant_id;comment:tenant id" json:"tenant_id"`
 	SeedPeerClusters  []SeedPeerCluster  `gorm:"many2many:job_seed_peer_cluster;" json:"seed_peer_clusters"`
 	SchedulerClusters []SchedulerCluster `gorm:"many2many:job_scheduler_cluster;" json:"scheduler_clusters"`
 }
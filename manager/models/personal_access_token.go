@@ -0,0 +1,62 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package models
+
+import "time"
+
+// PersonalAccessTokenPrefix is the prefix of personal access token,
+// which distinguishes the personal access token from the jwt token.
+const PersonalAccessTokenPrefix = "dfp_"
+
+const (
+	// PersonalAccessTokenTypePersonal is the token acts as its user, restricted by scopes.
+	PersonalAccessTokenTypePersonal = "personal"
+
+	// PersonalAccessTokenTypeService is the token of service, only restricted by scopes.
+	PersonalAccessTokenTypeService = "service"
+)
+
+const (
+	// PersonalAccessTokenScopeRead is the scope of reading all resources.
+	PersonalAccessTokenScopeRead = "read"
+
+	// PersonalAccessTokenScopePreheat is the scope of creating and reading jobs.
+	PersonalAccessTokenScopePreheat = "preheat"
+
+	// PersonalAccessTokenScopeClusterAdmin is the scope of managing cluster resources.
+	PersonalAccessTokenScopeClusterAdmin = "cluster-admin"
+)
+
+const (
+	PersonalAccessTokenStateActive   = "active"
+	PersonalAccessTokenStateInactive = "inactive"
+)
+
+type PersonalAccessToken struct {
+	BaseModel
+	Name              string             `gorm:"column:name;type:varchar(256);index:uk_personal_access_token_name,unique;not null;comment:name" json:"name"`
+	BIO               string             `gorm:"column:bio;type:varchar(1024);comment:biography" json:"bio"`
+	Type              string             `gorm:"column:type;type:varchar(256);not null;default:'personal';comment:type" json:"type"`
+	TokenHash         string             `gorm:"column:token_hash;type:varchar(256);index:uk_personal_access_token_hash,unique;not null;comment:sha256 of access token" json:"-"`
+	Token             string             `gorm:"-" json:"token,omitempty"`
+	Scopes            Array              `gorm:"column:scopes;not null;comment:scopes" json:"scopes"`
+	State             string             `gorm:"column:state;type:varchar(256);not null;default:'active';comment:state" json:"state"`
+	ExpiredAt         time.Time          `gorm:"column:expired_at;type:timestamp;not null;comment:expired at" json:"expired_at"`
+	UserID            uint               `gorm:"column:user_id;comment:user id" json:"user_id"`
+	User              User               `json:"-"`
+	TenantID          uint               `gorm:"column:tenant_id;comment:tenant id, the token only accesses the resources of tenant" json:"tenant_id"`
+	SchedulerClusters []SchedulerCluster `gorm:"many2many:personal_access_token_scheduler_cluster;" json:"scheduler_clusters"`
+}
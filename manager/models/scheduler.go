@@ -16,6 +16,11 @@
 
 package models
 
+import (
+	"net"
+	"time"
+)
+
 const (
 	// SchedulerStateActive represents the scheduler whose state is active.
 	SchedulerStateActive = "active"
@@ -26,14 +31,44 @@ const (
 
 type Scheduler struct {
 	BaseModel
-	Hostname           string           `gorm:"column:host_name;type:varchar(256);index:uk_scheduler,unique;not null;comment:hostname" json:"host_name"`
-	IDC                string           `gorm:"column:idc;type:varchar(1024);comment:internet data center" json:"idc"`
-	Location           string           `gorm:"column:location;type:varchar(1024);comment:location" json:"location"`
-	IP                 string           `gorm:"column:ip;type:varchar(256);not null;comment:ip address" json:"ip"`
-	Port               int32            `gorm:"column:port;not null;comment:grpc service listening port" json:"port"`
-	State              string           `gorm:"column:state;type:varchar(256);default:'inactive';comment:service state" json:"state"`
-	Features           Array            `gorm:"column:features;comment:feature flags" json:"features"`
-	SchedulerClusterID uint             `gorm:"index:uk_scheduler,unique;not null;comment:scheduler cluster id"`
-	SchedulerCluster   SchedulerCluster `json:"-"`
-	Models             []Model          `json:"-"`
+	Hostname                      string           `gorm:"column:host_name;type:varchar(256);index:uk_scheduler,unique;not null;comment:hostname" json:"host_name"`
+	IDC                           string           `gorm:"column:idc;type:varchar(1024);comment:internet data center" json:"idc"`
+	Location                      string           `gorm:"column:location;type:varchar(1024);comment:location" json:"location"`
+	IP                            string           `gorm:"column:ip;type:varchar(256);not null;comment:ip address" json:"ip"`
+	IPv4                          string           `gorm:"column:ipv4;type:varchar(256);comment:ipv4 advertise ip address" json:"ipv4"`
+	IPv6                          string           `gorm:"column:ipv6;type:varchar(256);comment:ipv6 advertise ip address" json:"ipv6"`
+	Port                          int32            `gorm:"column:port;not null;comment:grpc service listening port" json:"port"`
+	State                         string           `gorm:"column:state;type:varchar(256);default:'inactive';comment:service state" json:"state"`
+	Features                      Array            `gorm:"column:features;comment:feature flags" json:"features"`
+	CPUCount                      int              `gorm:"column:cpu_count;comment:logical cpu count of host" json:"cpu_count"`
+	MemoryTotal                   uint64           `gorm:"column:memory_total;comment:total memory in bytes of host" json:"memory_total"`
+	DiskFree                      uint64           `gorm:"column:disk_free;comment:free disk in bytes of storage volume" json:"disk_free"`
+	TrainDownloadBytes            int64            `gorm:"column:train_download_bytes;comment:download bytes sent in the last training cycle" json:"train_download_bytes"`
+	TrainNetworkTopologyBytes     int64            `gorm:"column:train_network_topology_bytes;comment:network topology bytes sent in the last training cycle" json:"train_network_topology_bytes"`
+	TrainBudgetLimited            bool             `gorm:"column:train_budget_limited;comment:whether the last training cycle is limited by budget" json:"train_budget_limited"`
+	TrainDownloadSucceeded        bool             `gorm:"column:train_download_succeeded;comment:whether download is uploaded in the last training cycle" json:"train_download_succeeded"`
+	TrainNetworkTopologySucceeded bool             `gorm:"column:train_network_topology_succeeded;comment:whether network topology is uploaded in the last training cycle" json:"train_network_topology_succeeded"`
+	TrainedAt                     *time.Time       `gorm:"column:trained_at;comment:time of reporting the last training cycle" json:"trained_at"`
+	SchedulerClusterID            uint             `gorm:"index:uk_scheduler,unique;not null;comment:scheduler cluster id"`
+	SchedulerCluster              SchedulerCluster `json:"-"`
+	Models                        []Model          `json:"-"`
+}
+
+// AdvertiseIP returns the ip of scheduler in the address family of peer ip,
+// it returns the registered ip if scheduler has no ip in the address family.
+func (s Scheduler) AdvertiseIP(peerIP string) string {
+	ip := net.ParseIP(peerIP)
+	if ip == nil {
+		return s.IP
+	}
+
+	if ip.To4() != nil && s.IPv4 != "" {
+		return s.IPv4
+	}
+
+	if ip.To4() == nil && s.IPv6 != "" {
+		return s.IPv6
+	}
+
+	return s.IP
 }
@@ -24,7 +24,9 @@ type SchedulerCluster struct {
 	ClientConfig     JSONMap           `gorm:"column:client_config;not null;comment:client configuration" json:"client_config"`
 	Scopes           JSONMap           `gorm:"column:scopes;comment:match scopes" json:"scopes"`
 	IsDefault        bool              `gorm:"column:is_default;not null;default:false;comment:default scheduler cluster" json:"is_default"`
+	TenantID         uint              `gorm:"index:idx_scheduler_cluster_tenant_id;comment:tenant id, 0 means shared by all tenants" json:"tenant_id"`
 	SeedPeerClusters []SeedPeerCluster `gorm:"many2many:seed_peer_cluster_scheduler_cluster;" json:"seed_peer_clusters"`
 	Schedulers       []Scheduler       `json:"-"`
 	Jobs             []Job             `gorm:"many2many:job_scheduler_cluster;" json:"jobs"`
+	SearcherRules    []SearcherRule    `json:"-"`
 }
@@ -0,0 +1,63 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduler_AdvertiseIP(t *testing.T) {
+	tests := []struct {
+		name      string
+		scheduler Scheduler
+		peerIP    string
+		expect    string
+	}{
+		{
+			name:      "ipv6 peer with dual-stack scheduler",
+			scheduler: Scheduler{IP: "10.0.0.1", IPv4: "10.0.0.1", IPv6: "fd00::1"},
+			peerIP:    "fd00::2",
+			expect:    "fd00::1",
+		},
+		{
+			name:      "ipv4 peer with dual-stack scheduler",
+			scheduler: Scheduler{IP: "fd00::1", IPv4: "10.0.0.1", IPv6: "fd00::1"},
+			peerIP:    "10.0.0.2",
+			expect:    "10.0.0.1",
+		},
+		{
+			name:      "ipv6 peer with single-stack scheduler",
+			scheduler: Scheduler{IP: "10.0.0.1"},
+			peerIP:    "fd00::2",
+			expect:    "10.0.0.1",
+		},
+		{
+			name:      "invalid peer ip",
+			scheduler: Scheduler{IP: "10.0.0.1", IPv6: "fd00::1"},
+			peerIP:    "foo",
+			expect:    "10.0.0.1",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expect, tc.scheduler.AdvertiseIP(tc.peerIP))
+		})
+	}
+}
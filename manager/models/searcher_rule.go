@@ -0,0 +1,27 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package models
+
+type SearcherRule struct {
+	BaseModel
+	Name               string           `gorm:"column:name;type:varchar(256);index:uk_searcher_rule_name,unique;not null;comment:name" json:"name"`
+	BIO                string           `gorm:"column:bio;type:varchar(1024);comment:biography" json:"bio"`
+	CIDRs              Array            `gorm:"column:cidrs;comment:cidrs of dfdaemon ip" json:"cidrs"`
+	Labels             JSONMap          `gorm:"column:labels;comment:labels of dfdaemon host info" json:"labels"`
+	Weight             int              `gorm:"column:weight;not null;default:1;comment:weight of rule" json:"weight"`
+	SchedulerClusterID uint             `gorm:"index:idx_searcher_rule_scheduler_cluster_id;comment:scheduler cluster id" json:"scheduler_cluster_id"`
+	SchedulerCluster   SchedulerCluster `json:"-"`
+}
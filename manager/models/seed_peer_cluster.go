@@ -21,6 +21,7 @@ type SeedPeerCluster struct {
 	Name              string             `gorm:"column:name;type:varchar(256);index:uk_seed_peer_cluster_name,unique;not null;comment:name" json:"name"`
 	BIO               string             `gorm:"column:bio;type:varchar(1024);comment:biography" json:"bio"`
 	Config            JSONMap            `gorm:"column:config;not null;comment:configuration" json:"config"`
+	TenantID          uint               `gorm:"index:idx_seed_peer_cluster_tenant_id;comment:tenant id, 0 means shared by all tenants" json:"tenant_id"`
 	SchedulerClusters []SchedulerCluster `gorm:"many2many:seed_peer_cluster_scheduler_cluster;" json:"scheduler_clusters"`
 	SeedPeers         []SeedPeer         `json:"-"`
 	Jobs              []Job              `gorm:"many2many:job_seed_peer_cluster;" json:"jobs"`
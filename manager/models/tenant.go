@@ -0,0 +1,36 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+const (
+	// TenantStateActive is the tenant is able to create jobs.
+	TenantStateActive = "active"
+
+	// TenantStateInactive is the tenant is unable to create jobs.
+	TenantStateInactive = "inactive"
+)
+
+type Tenant struct {
+	BaseModel
+	Name                  string             `gorm:"column:name;type:varchar(256);index:uk_tenant_name,unique;not null;comment:name" json:"name"`
+	BIO                   string             `gorm:"column:bio;type:varchar(1024);comment:biography" json:"bio"`
+	State                 string             `gorm:"column:state;type:varchar(256);not null;default:'active';comment:service state" json:"state"`
+	PreheatJobQuota       int                `gorm:"column:preheat_job_quota;not null;default:0;comment:max number of running preheat jobs, 0 means unlimited" json:"preheat_job_quota"`
+	PreheatBandwidthQuota int64              `gorm:"column:preheat_bandwidth_quota;not null;default:0;comment:max total bandwidth of running preheat jobs in bytes per second, 0 means unlimited" json:"preheat_bandwidth_quota"`
+	SchedulerClusters     []SchedulerCluster `json:"scheduler_clusters"`
+	SeedPeerClusters      []SeedPeerCluster  `json:"seed_peer_clusters"`
+}
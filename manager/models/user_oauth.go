@@ -0,0 +1,25 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+// UserOauth links the user to its subject in the oauth provider.
+type UserOauth struct {
+	BaseModel
+	UserID  uint   `gorm:"column:user_id;index:idx_user_oauth_user_id;not null;comment:user id" json:"user_id"`
+	OauthID uint   `gorm:"column:oauth_id;index:uk_user_oauth_subject,unique;not null;comment:oauth id" json:"oauth_id"`
+	Subject string `gorm:"column:subject;type:varchar(256);index:uk_user_oauth_subject,unique;not null;comment:subject of user in oauth provider" json:"subject"`
+}
@@ -0,0 +1,46 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package models
+
+const (
+	// WebhookEventJobSucceeded is the event that the job is succeeded.
+	WebhookEventJobSucceeded = "job.succeeded"
+
+	// WebhookEventJobFailed is the event that the job is failed.
+	WebhookEventJobFailed = "job.failed"
+)
+
+const (
+	// WebhookStateActive is the webhook receives events.
+	WebhookStateActive = "active"
+
+	// WebhookStateInactive is the webhook does not receive events.
+	WebhookStateInactive = "inactive"
+)
+
+type Webhook struct {
+	BaseModel
+	Name     string `gorm:"column:name;type:varchar(256);index:uk_webhook_name,unique;not null;comment:name" json:"name"`
+	BIO      string `gorm:"column:bio;type:varchar(1024);comment:biography" json:"bio"`
+	URL      string `gorm:"column:url;type:varchar(1024);not null;comment:url of receiver" json:"url"`
+	Secret   string `gorm:"column:secret;type:varchar(256);not null;comment:secret of signing payload" json:"-"`
+	Events   Array  `gorm:"column:events;comment:subscribed events, empty means all events" json:"events"`
+	JobTypes Array  `gorm:"column:job_types;comment:subscribed job types, empty means all job types" json:"job_types"`
+	State    string `gorm:"column:state;type:varchar(256);default:'active';comment:service state" json:"state"`
+	UserID   uint   `gorm:"column:user_id;comment:user id" json:"user_id"`
+	User     User   `json:"-"`
+}
@@ -34,6 +34,7 @@ import (
 	"d7y.io/dragonfly/v2/manager/handlers"
 	"d7y.io/dragonfly/v2/manager/middlewares"
 	"d7y.io/dragonfly/v2/manager/service"
+	"d7y.io/dragonfly/v2/manager/types"
 )
 
 const (
@@ -69,14 +70,23 @@ func Init(cfg *config.Config, logDir string, service service.Service, enforcer *
 	r.Use(gin.Recovery())
 	r.Use(ginzap.Ginzap(logger.GinLogger.Desugar(), time.RFC3339, true))
 	r.Use(ginzap.RecoveryWithZap(logger.GinLogger.Desugar(), true))
+
+	// Audit wraps the error middleware to record the status of failed api calls.
+	if cfg.Audit.Enable {
+		r.Use(middlewares.Audit(service))
+	}
+
 	r.Use(middlewares.Error())
 	r.Use(middlewares.CORS())
 
+	r.Use(middlewares.PersonalAccessToken(service))
+
 	rbac := middlewares.RBAC(enforcer)
 	jwt, err := middlewares.Jwt(cfg.Auth.JWT, service)
 	if err != nil {
 		return nil, err
 	}
+	auth := middlewares.Auth(jwt.MiddlewareFunc())
 
 	// Manager view.
 	r.Use(static.Serve("/", assets))
@@ -86,9 +96,9 @@ func Init(cfg *config.Config, logDir string, service service.Service, enforcer *
 
 	// User
 	u := apiv1.Group("/users")
-	u.PATCH(":id", jwt.MiddlewareFunc(), rbac, h.UpdateUser)
-	u.GET(":id", jwt.MiddlewareFunc(), rbac, h.GetUser)
-	u.GET("", jwt.MiddlewareFunc(), rbac, h.GetUsers)
+	u.PATCH(":id", auth, rbac, h.UpdateUser)
+	u.GET(":id", auth, rbac, h.GetUser)
+	u.GET("", auth, rbac, h.GetUsers)
 	u.POST("signin", jwt.LoginHandler)
 	u.POST("signout", jwt.LogoutHandler)
 	u.POST("signup", h.SignUp)
@@ -96,12 +106,13 @@ func Init(cfg *config.Config, logDir string, service service.Service, enforcer *
 	u.GET("signin/:name/callback", h.OauthSigninCallback(jwt))
 	u.POST("refresh_token", jwt.RefreshHandler)
 	u.POST(":id/reset_password", h.ResetPassword)
-	u.GET(":id/roles", jwt.MiddlewareFunc(), rbac, h.GetRolesForUser)
-	u.PUT(":id/roles/:role", jwt.MiddlewareFunc(), rbac, h.AddRoleToUser)
-	u.DELETE(":id/roles/:role", jwt.MiddlewareFunc(), rbac, h.DeleteRoleForUser)
+	u.GET(":id/roles", auth, rbac, h.GetRolesForUser)
+	u.PUT(":id/roles/:role", auth, rbac, h.AddRoleToUser)
+	u.DELETE(":id/roles/:role", auth, rbac, h.DeleteRoleForUser)
+	u.PUT(":id/oauth/:oauth_id", auth, rbac, h.AddOauthForUser)
 
 	// Role
-	re := apiv1.Group("/roles", jwt.MiddlewareFunc(), rbac)
+	re := apiv1.Group("/roles", auth, rbac)
 	re.POST("", h.CreateRole)
 	re.DELETE(":role", h.DestroyRole)
 	re.GET(":role", h.GetRole)
@@ -110,19 +121,19 @@ func Init(cfg *config.Config, logDir string, service service.Service, enforcer *
 	re.DELETE(":role/permissions", h.DeletePermissionForRole)
 
 	// Permission
-	pm := apiv1.Group("/permissions", jwt.MiddlewareFunc(), rbac)
+	pm := apiv1.Group("/permissions", auth, rbac)
 	pm.GET("", h.GetPermissions(r))
 
 	// Oauth
 	oa := apiv1.Group("/oauth")
-	oa.POST("", jwt.MiddlewareFunc(), rbac, h.CreateOauth)
-	oa.DELETE(":id", jwt.MiddlewareFunc(), rbac, h.DestroyOauth)
-	oa.PATCH(":id", jwt.MiddlewareFunc(), rbac, h.UpdateOauth)
+	oa.POST("", auth, rbac, h.CreateOauth)
+	oa.DELETE(":id", auth, rbac, h.DestroyOauth)
+	oa.PATCH(":id", auth, rbac, h.UpdateOauth)
 	oa.GET(":id", h.GetOauth)
 	oa.GET("", h.GetOauths)
 
 	// Cluster
-	c := apiv1.Group("/clusters", jwt.MiddlewareFunc(), rbac)
+	c := apiv1.Group("/clusters", auth, rbac)
 	c.POST("", h.CreateCluster)
 	c.DELETE(":id", h.DestroyCluster)
 	c.PATCH(":id", h.UpdateCluster)
@@ -130,16 +141,22 @@ func Init(cfg *config.Config, logDir string, service service.Service, enforcer *
 	c.GET("", h.GetClusters)
 
 	// Scheduler Cluster
-	sc := apiv1.Group("/scheduler-clusters", jwt.MiddlewareFunc(), rbac)
+	sc := apiv1.Group("/scheduler-clusters", auth, rbac)
 	sc.POST("", h.CreateSchedulerCluster)
 	sc.DELETE(":id", h.DestroySchedulerCluster)
 	sc.PATCH(":id", h.UpdateSchedulerCluster)
 	sc.GET(":id", h.GetSchedulerCluster)
 	sc.GET("", h.GetSchedulerClusters)
 	sc.PUT(":id/schedulers/:scheduler_id", h.AddSchedulerToSchedulerCluster)
+	sc.GET(":id/seed-peer-saturation", h.GetSeedPeerSaturation)
+	sc.GET(":id/config-versions", h.GetConfigVersions(types.ConfigVersionClusterTypeScheduler))
+	sc.GET(":id/config-versions/diff", h.DiffConfigVersions(types.ConfigVersionClusterTypeScheduler))
+	sc.GET(":id/config-versions/:version", h.GetConfigVersion(types.ConfigVersionClusterTypeScheduler))
+	sc.POST(":id/config-versions/:version/rollback", h.RollbackConfigVersion(types.ConfigVersionClusterTypeScheduler))
+	sc.GET(":id/config-version-reports", h.GetConfigVersionReports(types.ConfigVersionClusterTypeScheduler))
 
 	// Scheduler
-	s := apiv1.Group("/schedulers", jwt.MiddlewareFunc(), rbac)
+	s := apiv1.Group("/schedulers", auth, rbac)
 	s.POST("", h.CreateScheduler)
 	s.DELETE(":id", h.DestroyScheduler)
 	s.PATCH(":id", h.UpdateScheduler)
@@ -147,7 +164,7 @@ func Init(cfg *config.Config, logDir string, service service.Service, enforcer *
 	s.GET("", h.GetSchedulers)
 
 	// Application
-	cs := apiv1.Group("/applications", jwt.MiddlewareFunc(), rbac)
+	cs := apiv1.Group("/applications", auth, rbac)
 	cs.POST("", h.CreateApplication)
 	cs.DELETE(":id", h.DestroyApplication)
 	cs.PATCH(":id", h.UpdateApplication)
@@ -155,7 +172,7 @@ func Init(cfg *config.Config, logDir string, service service.Service, enforcer *
 	cs.GET("", h.GetApplications)
 
 	// Seed Peer Cluster
-	spc := apiv1.Group("/seed-peer-clusters", jwt.MiddlewareFunc(), rbac)
+	spc := apiv1.Group("/seed-peer-clusters", auth, rbac)
 	spc.POST("", h.CreateSeedPeerCluster)
 	spc.DELETE(":id", h.DestroySeedPeerCluster)
 	spc.PATCH(":id", h.UpdateSeedPeerCluster)
@@ -163,17 +180,27 @@ func Init(cfg *config.Config, logDir string, service service.Service, enforcer *
 	spc.GET("", h.GetSeedPeerClusters)
 	spc.PUT(":id/seed-peers/:seed_peer_id", h.AddSeedPeerToSeedPeerCluster)
 	spc.PUT(":id/scheduler-clusters/:scheduler_cluster_id", h.AddSchedulerClusterToSeedPeerCluster)
+	spc.GET(":id/config-versions", h.GetConfigVersions(types.ConfigVersionClusterTypeSeedPeer))
+	spc.GET(":id/config-versions/diff", h.DiffConfigVersions(types.ConfigVersionClusterTypeSeedPeer))
+	spc.GET(":id/config-versions/:version", h.GetConfigVersion(types.ConfigVersionClusterTypeSeedPeer))
+	spc.POST(":id/config-versions/:version/rollback", h.RollbackConfigVersion(types.ConfigVersionClusterTypeSeedPeer))
+	spc.GET(":id/config-version-reports", h.GetConfigVersionReports(types.ConfigVersionClusterTypeSeedPeer))
 
 	// Seed Peer
-	sp := apiv1.Group("/seed-peers", jwt.MiddlewareFunc(), rbac)
+	sp := apiv1.Group("/seed-peers", auth, rbac)
 	sp.POST("", h.CreateSeedPeer)
 	sp.DELETE(":id", h.DestroySeedPeer)
 	sp.PATCH(":id", h.UpdateSeedPeer)
 	sp.GET(":id", h.GetSeedPeer)
 	sp.GET("", h.GetSeedPeers)
 
+	// Inventory
+	inv := apiv1.Group("/inventory", auth, rbac)
+	inv.GET("hosts", h.GetInventoryHosts)
+	inv.GET("peers", h.GetInventoryPeers)
+
 	// Bucket
-	bucket := apiv1.Group("/buckets", jwt.MiddlewareFunc(), rbac)
+	bucket := apiv1.Group("/buckets", auth, rbac)
 	bucket.POST("", h.CreateBucket)
 	bucket.DELETE(":id", h.DestroyBucket)
 	bucket.GET(":id", h.GetBucket)
@@ -181,28 +208,66 @@ func Init(cfg *config.Config, logDir string, service service.Service, enforcer *
 
 	// Config
 	config := apiv1.Group("/configs")
-	config.POST("", jwt.MiddlewareFunc(), rbac, h.CreateConfig)
-	config.DELETE(":id", jwt.MiddlewareFunc(), rbac, h.DestroyConfig)
-	config.PATCH(":id", jwt.MiddlewareFunc(), rbac, h.UpdateConfig)
-	config.GET(":id", jwt.MiddlewareFunc(), rbac, h.GetConfig)
+	config.POST("", auth, rbac, h.CreateConfig)
+	config.DELETE(":id", auth, rbac, h.DestroyConfig)
+	config.PATCH(":id", auth, rbac, h.UpdateConfig)
+	config.GET(":id", auth, rbac, h.GetConfig)
 	config.GET("", h.GetConfigs)
 
 	// Job
-	job := apiv1.Group("/jobs")
+	job := apiv1.Group("/jobs", middlewares.JobAuth(cfg.Auth.AnonymousJob, auth, rbac)...)
 	job.POST("", h.CreateJob)
+	job.POST("bulk-preheat", h.CreateBulkPreheatJob)
 	job.DELETE(":id", h.DestroyJob)
 	job.PATCH(":id", h.UpdateJob)
 	job.GET(":id", h.GetJob)
 	job.GET("", h.GetJobs)
 
 	// Model
-	model := apiv1.Group("/models", jwt.MiddlewareFunc(), rbac)
+	model := apiv1.Group("/models", auth, rbac)
 	model.POST("", h.CreateModel)
 	model.DELETE(":id", h.DestroyModel)
 	model.PATCH(":id", h.UpdateModel)
 	model.GET(":id", h.GetModel)
 	model.GET("", h.GetModels)
 
+	// Audit
+	au := apiv1.Group("/audits", auth, rbac)
+	au.GET(":id", h.GetAudit)
+	au.GET("", h.GetAudits)
+
+	// Searcher Rule
+	sr := apiv1.Group("/searcher-rules", auth, rbac)
+	sr.POST("", h.CreateSearcherRule)
+	sr.DELETE(":id", h.DestroySearcherRule)
+	sr.PATCH(":id", h.UpdateSearcherRule)
+	sr.GET(":id", h.GetSearcherRule)
+	sr.GET("", h.GetSearcherRules)
+
+	// Personal Access Token
+	pat := apiv1.Group("/personal-access-tokens", auth, rbac)
+	pat.POST("", h.CreatePersonalAccessToken)
+	pat.DELETE(":id", h.DestroyPersonalAccessToken)
+	pat.PATCH(":id", h.UpdatePersonalAccessToken)
+	pat.GET(":id", h.GetPersonalAccessToken)
+	pat.GET("", h.GetPersonalAccessTokens)
+
+	// Webhook
+	wh := apiv1.Group("/webhooks", auth, rbac)
+	wh.POST("", h.CreateWebhook)
+	wh.DELETE(":id", h.DestroyWebhook)
+	wh.PATCH(":id", h.UpdateWebhook)
+	wh.GET(":id", h.GetWebhook)
+	wh.GET("", h.GetWebhooks)
+
+	// Tenant
+	tn := apiv1.Group("/tenants", auth, rbac)
+	tn.POST("", h.CreateTenant)
+	tn.DELETE(":id", h.DestroyTenant)
+	tn.PATCH(":id", h.UpdateTenant)
+	tn.GET(":id", h.GetTenant)
+	tn.GET("", h.GetTenants)
+
 	// Compatible with the V1 preheat.
 	pv1 := r.Group("/preheats")
 	r.GET("_ping", h.GetHealth)
@@ -497,7 +497,7 @@ func (s *managerServerV1) ListSchedulers(ctx context.Context, req *managerv1.Lis
 	// Cache miss and search scheduler cluster.
 	var schedulerClusters []models.SchedulerCluster
 	if err := s.db.WithContext(ctx).Preload("SeedPeerClusters.SeedPeers", "state = ?", "active").
-		Preload("Schedulers", "state = ?", "active").Find(&schedulerClusters).Error; err != nil {
+		Preload("Schedulers", "state = ?", "active").Preload("SearcherRules").Find(&schedulerClusters).Error; err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
@@ -569,17 +569,28 @@ func (s *managerServerV1) ListSchedulers(ctx context.Context, req *managerv1.Lis
 			return nil, status.Error(codes.DataLoss, err.Error())
 		}
 
+		// Marshal client config of scheduler cluster.
+		schedulerClusterClientConfig, err := scheduler.SchedulerCluster.ClientConfig.MarshalJSON()
+		if err != nil {
+			return nil, status.Error(codes.DataLoss, err.Error())
+		}
+
 		pbListSchedulersResponse.Schedulers = append(pbListSchedulersResponse.Schedulers, &managerv1.Scheduler{
 			Id:                 uint64(scheduler.ID),
 			Hostname:           scheduler.Hostname,
 			Idc:                scheduler.IDC,
 			Location:           scheduler.Location,
-			Ip:                 scheduler.IP,
+			Ip:                 scheduler.AdvertiseIP(req.Ip),
 			Port:               scheduler.Port,
 			State:              scheduler.State,
 			Features:           features,
 			SchedulerClusterId: uint64(scheduler.SchedulerClusterID),
-			SeedPeers:          seedPeers,
+			SchedulerCluster: &managerv1.SchedulerCluster{
+				Id:           uint64(scheduler.SchedulerCluster.ID),
+				Name:         scheduler.SchedulerCluster.Name,
+				ClientConfig: schedulerClusterClientConfig,
+			},
+			SeedPeers: seedPeers,
 		})
 	}
 
@@ -403,6 +403,12 @@ func (s *managerServerV2) UpdateScheduler(ctx context.Context, req *managerv2.Up
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
+	if columns := schedulerMetadataColumns(ctx); len(columns) > 0 {
+		if err := s.db.WithContext(ctx).Model(&scheduler).Updates(columns).Error; err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
 	if err := s.cache.Delete(
 		ctx,
 		pkgredis.MakeSchedulerKeyInManager(scheduler.SchedulerClusterID, scheduler.Hostname, scheduler.IP),
@@ -445,6 +451,12 @@ func (s *managerServerV2) createScheduler(ctx context.Context, req *managerv2.Up
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
+	if columns := schedulerMetadataColumns(ctx); len(columns) > 0 {
+		if err := s.db.WithContext(ctx).Model(&scheduler).Updates(columns).Error; err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
 	// Marshal features of scheduler.
 	features, err := scheduler.Features.MarshalJSON()
 	if err != nil {
@@ -496,7 +508,7 @@ func (s *managerServerV2) ListSchedulers(ctx context.Context, req *managerv2.Lis
 
 	// Cache miss and search scheduler cluster.
 	var schedulerClusters []models.SchedulerCluster
-	if err := s.db.WithContext(ctx).Preload("SeedPeerClusters.SeedPeers", "state = ?", "active").Preload("Schedulers", "state = ?", "active").Find(&schedulerClusters).Error; err != nil {
+	if err := s.db.WithContext(ctx).Preload("SeedPeerClusters.SeedPeers", "state = ?", "active").Preload("Schedulers", "state = ?", "active").Preload("SearcherRules").Find(&schedulerClusters).Error; err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
@@ -573,7 +585,7 @@ func (s *managerServerV2) ListSchedulers(ctx context.Context, req *managerv2.Lis
 			Hostname:           scheduler.Hostname,
 			Idc:                scheduler.IDC,
 			Location:           scheduler.Location,
-			Ip:                 scheduler.IP,
+			Ip:                 scheduler.AdvertiseIP(req.Ip),
 			Port:               scheduler.Port,
 			State:              scheduler.State,
 			Features:           features,
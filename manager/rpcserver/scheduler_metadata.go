@@ -0,0 +1,162 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpcserver
+
+import (
+	"context"
+	"math"
+	"net"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+// schedulerMetadataColumns returns the columns of scheduler reported as grpc metadata of
+// UpdateScheduler, because the request has no fields of them. Absent and invalid values are
+// skipped, so the columns reported before are kept.
+func schedulerMetadataColumns(ctx context.Context) map[string]any {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	columns := map[string]any{}
+	if v, ok := metadataUint(md, types.SchedulerCPUCountMetadataKey); ok {
+		columns["cpu_count"] = int(v)
+	}
+
+	if v, ok := metadataUint(md, types.SchedulerMemoryTotalMetadataKey); ok {
+		columns["memory_total"] = v
+	}
+
+	if v, ok := metadataUint(md, types.SchedulerDiskFreeMetadataKey); ok {
+		columns["disk_free"] = v
+	}
+
+	// The advertise ips are reported together, the absent one is cleared.
+	ipv4, okv4 := metadataIP(md, types.SchedulerAdvertiseIPv4MetadataKey, true)
+	ipv6, okv6 := metadataIP(md, types.SchedulerAdvertiseIPv6MetadataKey, false)
+	if okv4 || okv6 {
+		columns["ipv4"] = ipv4
+		columns["ipv6"] = ipv6
+	}
+
+	// The train result is reported after each training cycle, it is persisted only if complete.
+	if trainColumns, ok := trainMetadataColumns(md); ok {
+		for column, v := range trainColumns {
+			columns[column] = v
+		}
+	}
+
+	return columns
+}
+
+// trainMetadataColumns returns the train columns of scheduler, it returns false if any
+// train metadata is absent or invalid.
+func trainMetadataColumns(md metadata.MD) (map[string]any, bool) {
+	downloadBytes, ok := metadataInt(md, types.SchedulerTrainDownloadBytesMetadataKey)
+	if !ok {
+		return nil, false
+	}
+
+	networkTopologyBytes, ok := metadataInt(md, types.SchedulerTrainNetworkTopologyBytesMetadataKey)
+	if !ok {
+		return nil, false
+	}
+
+	budgetLimited, ok := metadataBool(md, types.SchedulerTrainBudgetLimitedMetadataKey)
+	if !ok {
+		return nil, false
+	}
+
+	downloadSucceeded, ok := metadataBool(md, types.SchedulerTrainDownloadSucceededMetadataKey)
+	if !ok {
+		return nil, false
+	}
+
+	networkTopologySucceeded, ok := metadataBool(md, types.SchedulerTrainNetworkTopologySucceededMetadataKey)
+	if !ok {
+		return nil, false
+	}
+
+	return map[string]any{
+		"train_download_bytes":             downloadBytes,
+		"train_network_topology_bytes":     networkTopologyBytes,
+		"train_budget_limited":             budgetLimited,
+		"train_download_succeeded":         downloadSucceeded,
+		"train_network_topology_succeeded": networkTopologySucceeded,
+		"trained_at":                       time.Now(),
+	}, true
+}
+
+// metadataInt returns the first value of key in md as non-negative int64.
+func metadataInt(md metadata.MD, key string) (int64, bool) {
+	v, ok := metadataUint(md, key)
+	if !ok || v > math.MaxInt64 {
+		return 0, false
+	}
+
+	return int64(v), true
+}
+
+// metadataBool returns the first value of key in md as bool.
+func metadataBool(md metadata.MD, key string) (bool, bool) {
+	values := md.Get(key)
+	if len(values) == 0 {
+		return false, false
+	}
+
+	v, err := strconv.ParseBool(values[0])
+	if err != nil {
+		return false, false
+	}
+
+	return v, true
+}
+
+// metadataIP returns the first value of key in md as ip in the address family.
+func metadataIP(md metadata.MD, key string, ipv4 bool) (string, bool) {
+	values := md.Get(key)
+	if len(values) == 0 {
+		return "", false
+	}
+
+	ip := net.ParseIP(values[0])
+	if ip == nil || (ip.To4() != nil) != ipv4 {
+		return "", false
+	}
+
+	return ip.String(), true
+}
+
+// metadataUint returns the first value of key in md as uint64.
+func metadataUint(md metadata.MD, key string) (uint64, bool) {
+	values := md.Get(key)
+	if len(values) == 0 {
+		return 0, false
+	}
+
+	v, err := strconv.ParseUint(values[0], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return v, true
+}
@@ -0,0 +1,135 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpcserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+func TestSchedulerMetadataColumns(t *testing.T) {
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		expect func(t *testing.T, columns map[string]any)
+	}{
+		{
+			name: "without metadata",
+			ctx:  context.Background(),
+			expect: func(t *testing.T, columns map[string]any) {
+				assert.Empty(t, columns)
+			},
+		},
+		{
+			name: "host stats",
+			ctx: metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+				types.SchedulerCPUCountMetadataKey, "8",
+				types.SchedulerMemoryTotalMetadataKey, "17179869184",
+				types.SchedulerDiskFreeMetadataKey, "1073741824",
+			)),
+			expect: func(t *testing.T, columns map[string]any) {
+				assert.Equal(t, map[string]any{
+					"cpu_count":    8,
+					"memory_total": uint64(17179869184),
+					"disk_free":    uint64(1073741824),
+				}, columns)
+			},
+		},
+		{
+			name: "dual-stack advertise ips",
+			ctx: metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+				types.SchedulerAdvertiseIPv4MetadataKey, "10.0.0.1",
+				types.SchedulerAdvertiseIPv6MetadataKey, "fd00::1",
+			)),
+			expect: func(t *testing.T, columns map[string]any) {
+				assert.Equal(t, map[string]any{"ipv4": "10.0.0.1", "ipv6": "fd00::1"}, columns)
+			},
+		},
+		{
+			name: "single-stack advertise ip clears the other address family",
+			ctx: metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+				types.SchedulerAdvertiseIPv4MetadataKey, "10.0.0.1",
+			)),
+			expect: func(t *testing.T, columns map[string]any) {
+				assert.Equal(t, map[string]any{"ipv4": "10.0.0.1", "ipv6": ""}, columns)
+			},
+		},
+		{
+			name: "advertise ip in wrong address family is skipped",
+			ctx: metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+				types.SchedulerAdvertiseIPv4MetadataKey, "fd00::1",
+			)),
+			expect: func(t *testing.T, columns map[string]any) {
+				assert.Empty(t, columns)
+			},
+		},
+		{
+			name: "invalid host stats are skipped",
+			ctx: metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+				types.SchedulerCPUCountMetadataKey, "foo",
+				types.SchedulerDiskFreeMetadataKey, "-1",
+			)),
+			expect: func(t *testing.T, columns map[string]any) {
+				assert.Empty(t, columns)
+			},
+		},
+		{
+			name: "train result",
+			ctx: metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+				types.SchedulerTrainDownloadBytesMetadataKey, "3",
+				types.SchedulerTrainNetworkTopologyBytesMetadataKey, "6",
+				types.SchedulerTrainBudgetLimitedMetadataKey, "false",
+				types.SchedulerTrainDownloadSucceededMetadataKey, "true",
+				types.SchedulerTrainNetworkTopologySucceededMetadataKey, "true",
+			)),
+			expect: func(t *testing.T, columns map[string]any) {
+				assert := assert.New(t)
+				assert.IsType(time.Time{}, columns["trained_at"])
+				delete(columns, "trained_at")
+				assert.Equal(map[string]any{
+					"train_download_bytes":             int64(3),
+					"train_network_topology_bytes":     int64(6),
+					"train_budget_limited":             false,
+					"train_download_succeeded":         true,
+					"train_network_topology_succeeded": true,
+				}, columns)
+			},
+		},
+		{
+			name: "incomplete train result is skipped",
+			ctx: metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+				types.SchedulerTrainDownloadBytesMetadataKey, "3",
+				types.SchedulerTrainBudgetLimitedMetadataKey, "foo",
+			)),
+			expect: func(t *testing.T, columns map[string]any) {
+				assert.Empty(t, columns)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expect(t, schedulerMetadataColumns(tc.ctx))
+		})
+	}
+}
@@ -125,6 +125,13 @@ func (s *searcher) FindSchedulerClusters(ctx context.Context, schedulerClusters
 				return false
 			}
 
+			// The clusters matching rules of larger weights are preferred.
+			ri := calculateRuleScore(ip, conditions, clusters[i].SearcherRules, log)
+			rj := calculateRuleScore(ip, conditions, clusters[j].SearcherRules, log)
+			if ri != rj {
+				return ri > rj
+			}
+
 			return Evaluate(ip, hostname, conditions, si, clusters[i], log) > Evaluate(ip, hostname, conditions, sj, clusters[j], log)
 		},
 	)
@@ -155,6 +162,39 @@ func Evaluate(ip, hostname string, conditions map[string]string, scopes Scopes,
 		clusterTypeWeight*calculateClusterTypeScore(cluster)
 }
 
+// calculateRuleScore returns the max weight of the rules matching dfdaemon, larger and better.
+func calculateRuleScore(ip string, conditions map[string]string, rules []models.SearcherRule, log *zap.SugaredLogger) int {
+	var score int
+	for _, rule := range rules {
+		if rule.Weight > score && matchRule(ip, conditions, rule, log) {
+			score = rule.Weight
+		}
+	}
+
+	return score
+}
+
+// matchRule returns whether dfdaemon matches the rule. Dfdaemon matches the rule if its ip
+// is in one of the cidrs, and its host info has all of the labels. Empty cidrs or labels
+// match any dfdaemon, but the rule without cidrs and labels matches nothing.
+func matchRule(ip string, conditions map[string]string, rule models.SearcherRule, log *zap.SugaredLogger) bool {
+	if len(rule.CIDRs) == 0 && len(rule.Labels) == 0 {
+		return false
+	}
+
+	if len(rule.CIDRs) > 0 && calculateCIDRAffinityScore(ip, rule.CIDRs, log) != maxScore {
+		return false
+	}
+
+	for key, value := range rule.Labels {
+		if conditions[key] != fmt.Sprint(value) {
+			return false
+		}
+	}
+
+	return true
+}
+
 // calculateCIDRAffinityScore 0.0~1.0 larger and better.
 func calculateCIDRAffinityScore(ip string, cidrs []string, log *zap.SugaredLogger) float64 {
 	// Construct CIDR ranger.
@@ -381,6 +381,145 @@ func TestSearcher_FindSchedulerClusters(t *testing.T) {
 				assert.Equal(len(data), 7)
 			},
 		},
+		{
+			name: "match according to rule cidrs",
+			schedulerClusters: []models.SchedulerCluster{
+				{
+					Name: "foo",
+					SearcherRules: []models.SearcherRule{
+						{
+							CIDRs:  []string{"10.0.0.0/8"},
+							Weight: 10,
+						},
+					},
+					Schedulers: []models.Scheduler{
+						{
+							Hostname: "foo",
+							State:    "active",
+						},
+					},
+				},
+				{
+					Name: "bar",
+					SearcherRules: []models.SearcherRule{
+						{
+							CIDRs:  []string{"128.168.1.0/24"},
+							Weight: 1,
+						},
+					},
+					Schedulers: []models.Scheduler{
+						{
+							Hostname: "bar",
+							State:    "active",
+						},
+					},
+				},
+			},
+			conditions: map[string]string{},
+			expect: func(t *testing.T, data []models.SchedulerCluster, err error) {
+				assert := assert.New(t)
+				assert.Equal(data[0].Name, "bar")
+				assert.Equal(data[1].Name, "foo")
+			},
+		},
+		{
+			name: "match according to rule labels and weights",
+			schedulerClusters: []models.SchedulerCluster{
+				{
+					Name: "foo",
+					SearcherRules: []models.SearcherRule{
+						{
+							Labels: map[string]any{"region": "us-east-1"},
+							Weight: 10,
+						},
+					},
+					Schedulers: []models.Scheduler{
+						{
+							Hostname: "foo",
+							State:    "active",
+						},
+					},
+				},
+				{
+					Name: "bar",
+					SearcherRules: []models.SearcherRule{
+						{
+							Labels: map[string]any{"region": "us-east-1", "zone": "a"},
+							Weight: 20,
+						},
+					},
+					Schedulers: []models.Scheduler{
+						{
+							Hostname: "bar",
+							State:    "active",
+						},
+					},
+				},
+				{
+					Name: "baz",
+					SearcherRules: []models.SearcherRule{
+						{
+							Labels: map[string]any{"region": "us-west-1"},
+							Weight: 30,
+						},
+					},
+					Schedulers: []models.Scheduler{
+						{
+							Hostname: "baz",
+							State:    "active",
+						},
+					},
+				},
+			},
+			conditions: map[string]string{"region": "us-east-1", "zone": "a"},
+			expect: func(t *testing.T, data []models.SchedulerCluster, err error) {
+				assert := assert.New(t)
+				assert.Equal(data[0].Name, "bar")
+				assert.Equal(data[1].Name, "foo")
+				assert.Equal(data[2].Name, "baz")
+			},
+		},
+		{
+			name: "rules take precedence over scopes",
+			schedulerClusters: []models.SchedulerCluster{
+				{
+					Name: "foo",
+					Scopes: map[string]any{
+						"idc":      "idc-1",
+						"location": "location-1",
+					},
+					Schedulers: []models.Scheduler{
+						{
+							Hostname: "foo",
+							State:    "active",
+						},
+					},
+					IsDefault: true,
+				},
+				{
+					Name: "bar",
+					SearcherRules: []models.SearcherRule{
+						{
+							CIDRs:  []string{"128.168.0.0/16"},
+							Labels: map[string]any{"region": "us-east-1"},
+							Weight: 1,
+						},
+					},
+					Schedulers: []models.Scheduler{
+						{
+							Hostname: "bar",
+							State:    "active",
+						},
+					},
+				},
+			},
+			conditions: map[string]string{"idc": "idc-1", "location": "location-1", "region": "us-east-1"},
+			expect: func(t *testing.T, data []models.SchedulerCluster, err error) {
+				assert := assert.New(t)
+				assert.Equal(data[0].Name, "bar")
+				assert.Equal(data[1].Name, "foo")
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -0,0 +1,98 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"d7y.io/dragonfly/v2/manager/models"
+	"d7y.io/dragonfly/v2/manager/types"
+	"d7y.io/dragonfly/v2/pkg/structure"
+)
+
+// auditResources are the models of resources whose states are recorded in audits,
+// the key is the api group name of resource.
+var auditResources = map[string]func() any{
+	"applications":           func() any { return &models.Application{} },
+	"configs":                func() any { return &models.Config{} },
+	"jobs":                   func() any { return &models.Job{} },
+	"models":                 func() any { return &models.Model{} },
+	"oauth":                  func() any { return &models.Oauth{} },
+	"personal-access-tokens": func() any { return &models.PersonalAccessToken{} },
+	"scheduler-clusters":     func() any { return &models.SchedulerCluster{} },
+	"schedulers":             func() any { return &models.Scheduler{} },
+	"searcher-rules":         func() any { return &models.SearcherRule{} },
+	"seed-peer-clusters":     func() any { return &models.SeedPeerCluster{} },
+	"seed-peers":             func() any { return &models.SeedPeer{} },
+	"tenants":                func() any { return &models.Tenant{} },
+	"users":                  func() any { return &models.User{} },
+	"webhooks":               func() any { return &models.Webhook{} },
+}
+
+func (s *service) CreateAudit(ctx context.Context, audit *models.Audit) error {
+	return s.db.WithContext(ctx).Create(audit).Error
+}
+
+func (s *service) DestroyAuditsBefore(ctx context.Context, before time.Time) (int64, error) {
+	// Expired audits are deleted permanently.
+	result := s.db.WithContext(ctx).Unscoped().Where("created_at < ?", before).Delete(&models.Audit{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
+
+func (s *service) GetAudit(ctx context.Context, id uint) (*models.Audit, error) {
+	audit := models.Audit{}
+	if err := s.db.WithContext(ctx).First(&audit, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &audit, nil
+}
+
+func (s *service) GetAudits(ctx context.Context, q types.GetAuditsQuery) ([]models.Audit, int64, error) {
+	var count int64
+	var audits []models.Audit
+	if err := s.db.WithContext(ctx).Scopes(models.Paginate(q.Page, q.PerPage)).Where(&models.Audit{
+		UserID:     q.UserID,
+		Method:     q.Method,
+		Resource:   q.Resource,
+		ResourceID: q.ResourceID,
+	}).Order("id DESC").Find(&audits).Limit(-1).Offset(-1).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return audits, count, nil
+}
+
+func (s *service) GetAuditResourceState(ctx context.Context, resource string, id uint) (map[string]any, error) {
+	newModel, ok := auditResources[resource]
+	if !ok {
+		return nil, fmt.Errorf("resource %s is not audited", resource)
+	}
+
+	model := newModel()
+	if err := s.db.WithContext(ctx).First(model, id).Error; err != nil {
+		return nil, err
+	}
+
+	return structure.StructToMap(model)
+}
@@ -0,0 +1,265 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/manager/models"
+	"d7y.io/dragonfly/v2/manager/types"
+	pkgredis "d7y.io/dragonfly/v2/pkg/redis"
+	"d7y.io/dragonfly/v2/pkg/structure"
+)
+
+const (
+	// configVersionConfigKey is the key of config in snapshot.
+	configVersionConfigKey = "config"
+
+	// configVersionClientConfigKey is the key of client config in snapshot.
+	configVersionClientConfigKey = "client_config"
+
+	// configVersionScopesKey is the key of scopes in snapshot.
+	configVersionScopesKey = "scopes"
+)
+
+func (s *service) GetConfigVersion(ctx context.Context, clusterType string, clusterID, version uint) (*models.ConfigVersion, error) {
+	configVersion := models.ConfigVersion{}
+	if err := s.db.WithContext(ctx).First(&configVersion, &models.ConfigVersion{
+		ClusterType: clusterType,
+		ClusterID:   clusterID,
+		Version:     version,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	return &configVersion, nil
+}
+
+func (s *service) GetConfigVersions(ctx context.Context, clusterType string, clusterID uint, q types.GetConfigVersionsQuery) ([]models.ConfigVersion, int64, error) {
+	var count int64
+	var configVersions []models.ConfigVersion
+	if err := s.db.WithContext(ctx).Scopes(models.Paginate(q.Page, q.PerPage)).Where(&models.ConfigVersion{
+		ClusterType: clusterType,
+		ClusterID:   clusterID,
+	}).Order("version DESC").Find(&configVersions).Limit(-1).Offset(-1).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return configVersions, count, nil
+}
+
+func (s *service) DiffConfigVersions(ctx context.Context, clusterType string, clusterID uint, q types.DiffConfigVersionsQuery) ([]structure.Diff, error) {
+	from, err := s.GetConfigVersion(ctx, clusterType, clusterID, q.From)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := s.GetConfigVersion(ctx, clusterType, clusterID, q.To)
+	if err != nil {
+		return nil, err
+	}
+
+	return structure.DiffMap(from.Config, to.Config), nil
+}
+
+// RollbackConfigVersion applies the configuration of version to cluster, and records it as a new version,
+// so the versions are never rewritten.
+func (s *service) RollbackConfigVersion(ctx context.Context, clusterType string, clusterID, version uint) (*models.ConfigVersion, error) {
+	configVersion, err := s.GetConfigVersion(ctx, clusterType, clusterID, version)
+	if err != nil {
+		return nil, err
+	}
+
+	switch clusterType {
+	case types.ConfigVersionClusterTypeScheduler:
+		schedulerCluster := models.SchedulerCluster{}
+		if err := s.db.WithContext(ctx).First(&schedulerCluster, clusterID).Select("config", "client_config", "scopes").Updates(models.SchedulerCluster{
+			Config:       snapshotJSONMap(configVersion.Config, configVersionConfigKey),
+			ClientConfig: snapshotJSONMap(configVersion.Config, configVersionClientConfigKey),
+			Scopes:       snapshotJSONMap(configVersion.Config, configVersionScopesKey),
+		}).Error; err != nil {
+			return nil, err
+		}
+	case types.ConfigVersionClusterTypeSeedPeer:
+		seedPeerCluster := models.SeedPeerCluster{}
+		if err := s.db.WithContext(ctx).First(&seedPeerCluster, clusterID).Select("config").Updates(models.SeedPeerCluster{
+			Config: snapshotJSONMap(configVersion.Config, configVersionConfigKey),
+		}).Error; err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("invalid cluster type %s", clusterType)
+	}
+
+	return s.createConfigVersion(ctx, clusterType, clusterID, version)
+}
+
+// GetConfigVersionReports returns the configuration versions running in the consumers of cluster,
+// the consumer is drifted if the digest of its configuration differs from the current configuration of cluster.
+func (s *service) GetConfigVersionReports(ctx context.Context, clusterType string, clusterID uint) ([]types.ConfigVersionReport, error) {
+	_, digest, err := s.makeConfigSnapshot(ctx, clusterType, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := s.rdb.Keys(ctx, pkgredis.MakeConfigVersionReportsKeyInManager(clusterType, clusterID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	reports := []types.ConfigVersionReport{}
+	for _, key := range keys {
+		data, err := s.rdb.Get(ctx, key).Bytes()
+		if err != nil {
+			logger.Warnf("get config version report %s failed: %s", key, err.Error())
+			continue
+		}
+
+		var report types.ConfigVersionReport
+		if err := json.Unmarshal(data, &report); err != nil {
+			logger.Warnf("unmarshal config version report %s failed: %s", key, err.Error())
+			continue
+		}
+
+		// The configuration may be changed out of versioning, e.g. by database directly,
+		// so the version of report is unknown.
+		configVersion := models.ConfigVersion{}
+		if err := s.db.WithContext(ctx).Where(&models.ConfigVersion{
+			ClusterType: clusterType,
+			ClusterID:   clusterID,
+			Digest:      report.Digest,
+		}).Order("version DESC").First(&configVersion).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+
+		report.Version = configVersion.Version
+		report.Drifted = report.Digest != digest
+		reports = append(reports, report)
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		if reports[i].Hostname != reports[j].Hostname {
+			return reports[i].Hostname < reports[j].Hostname
+		}
+
+		return reports[i].IP < reports[j].IP
+	})
+
+	return reports, nil
+}
+
+// createConfigVersion records the current configuration of cluster as a new version,
+// it is skipped if the configuration is not changed since the latest version.
+func (s *service) createConfigVersion(ctx context.Context, clusterType string, clusterID, rollbackVersion uint) (*models.ConfigVersion, error) {
+	config, digest, err := s.makeConfigSnapshot(ctx, clusterType, clusterID)
+	if err != nil {
+		return nil, err
+	}
+
+	latest := models.ConfigVersion{}
+	if err := s.db.WithContext(ctx).Where(&models.ConfigVersion{
+		ClusterType: clusterType,
+		ClusterID:   clusterID,
+	}).Order("version DESC").First(&latest).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	} else if latest.Digest == digest {
+		return &latest, nil
+	}
+
+	configVersion := models.ConfigVersion{
+		ClusterType:     clusterType,
+		ClusterID:       clusterID,
+		Version:         latest.Version + 1,
+		Config:          config,
+		Digest:          digest,
+		RollbackVersion: rollbackVersion,
+	}
+
+	if err := s.db.WithContext(ctx).Create(&configVersion).Error; err != nil {
+		return nil, err
+	}
+
+	return &configVersion, nil
+}
+
+// makeConfigSnapshot returns the snapshot of cluster configuration and its digest, the digest is made by
+// the configuration marshaled in the same way as it is delivered to consumers by grpc.
+func (s *service) makeConfigSnapshot(ctx context.Context, clusterType string, clusterID uint) (models.JSONMap, string, error) {
+	switch clusterType {
+	case types.ConfigVersionClusterTypeScheduler:
+		schedulerCluster := models.SchedulerCluster{}
+		if err := s.db.WithContext(ctx).First(&schedulerCluster, clusterID).Error; err != nil {
+			return nil, "", err
+		}
+
+		config, err := schedulerCluster.Config.MarshalJSON()
+		if err != nil {
+			return nil, "", err
+		}
+
+		clientConfig, err := schedulerCluster.ClientConfig.MarshalJSON()
+		if err != nil {
+			return nil, "", err
+		}
+
+		scopes, err := schedulerCluster.Scopes.MarshalJSON()
+		if err != nil {
+			return nil, "", err
+		}
+
+		return models.JSONMap{
+			configVersionConfigKey:       map[string]any(schedulerCluster.Config),
+			configVersionClientConfigKey: map[string]any(schedulerCluster.ClientConfig),
+			configVersionScopesKey:       map[string]any(schedulerCluster.Scopes),
+		}, types.MakeSchedulerClusterConfigDigest(config, clientConfig, scopes), nil
+	case types.ConfigVersionClusterTypeSeedPeer:
+		seedPeerCluster := models.SeedPeerCluster{}
+		if err := s.db.WithContext(ctx).First(&seedPeerCluster, clusterID).Error; err != nil {
+			return nil, "", err
+		}
+
+		config, err := seedPeerCluster.Config.MarshalJSON()
+		if err != nil {
+			return nil, "", err
+		}
+
+		return models.JSONMap{
+			configVersionConfigKey: map[string]any(seedPeerCluster.Config),
+		}, types.MakeSeedPeerClusterConfigDigest(config), nil
+	default:
+		return nil, "", fmt.Errorf("invalid cluster type %s", clusterType)
+	}
+}
+
+// snapshotJSONMap returns the configuration of key in snapshot.
+func snapshotJSONMap(snapshot models.JSONMap, key string) models.JSONMap {
+	m, ok := snapshot[key].(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	return models.JSONMap(m)
+}
@@ -0,0 +1,211 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/manager/models"
+	"d7y.io/dragonfly/v2/manager/types"
+	pkgredis "d7y.io/dragonfly/v2/pkg/redis"
+)
+
+// GetInventoryHosts lists the active hosts reported by the schedulers. The host reported by
+// multiple schedulers of the same scheduler cluster is merged into the latest report.
+func (s *service) GetInventoryHosts(ctx context.Context, q types.GetInventoryHostsQuery) ([]types.InventoryHost, int64, error) {
+	inventories, err := s.getInventories(ctx, q.SchedulerClusterID, q.TenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	type hostKey struct {
+		schedulerClusterID uint
+		id                 string
+	}
+
+	merged := make(map[hostKey]types.InventoryHost)
+	for _, inventory := range inventories {
+		for _, host := range inventory.Hosts {
+			if q.IDC != "" && host.IDC != q.IDC {
+				continue
+			}
+
+			if q.Type != "" && host.Type != q.Type {
+				continue
+			}
+
+			if q.Health != "" && host.Health != q.Health {
+				continue
+			}
+
+			host.SchedulerClusterID = inventory.SchedulerClusterID
+			key := hostKey{inventory.SchedulerClusterID, host.ID}
+			if h, ok := merged[key]; ok && h.UpdatedAt.After(host.UpdatedAt) {
+				continue
+			}
+
+			merged[key] = host
+		}
+	}
+
+	hosts := make([]types.InventoryHost, 0, len(merged))
+	for _, host := range merged {
+		hosts = append(hosts, host)
+	}
+
+	sort.Slice(hosts, func(i, j int) bool {
+		if hosts[i].Hostname != hosts[j].Hostname {
+			return hosts[i].Hostname < hosts[j].Hostname
+		}
+
+		if hosts[i].ID != hosts[j].ID {
+			return hosts[i].ID < hosts[j].ID
+		}
+
+		return hosts[i].SchedulerClusterID < hosts[j].SchedulerClusterID
+	})
+
+	start, end := paginateInventory(len(hosts), q.Page, q.PerPage)
+	return hosts[start:end], int64(len(hosts)), nil
+}
+
+// GetInventoryPeers lists the active peers reported by the schedulers, the most recently
+// created peers are listed first.
+func (s *service) GetInventoryPeers(ctx context.Context, q types.GetInventoryPeersQuery) ([]types.InventoryPeer, int64, error) {
+	inventories, err := s.getInventories(ctx, q.SchedulerClusterID, q.TenantID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	merged := make(map[string]types.InventoryPeer)
+	for _, inventory := range inventories {
+		for _, peer := range inventory.Peers {
+			if q.IDC != "" && peer.IDC != q.IDC {
+				continue
+			}
+
+			if q.TaskID != "" && peer.TaskID != q.TaskID {
+				continue
+			}
+
+			if q.HostID != "" && peer.HostID != q.HostID {
+				continue
+			}
+
+			if q.State != "" && peer.State != q.State {
+				continue
+			}
+
+			if q.Health != "" && peer.Health != q.Health {
+				continue
+			}
+
+			// Peers adopted from other schedulers by peer exchange are reported by multiple schedulers.
+			peer.SchedulerClusterID = inventory.SchedulerClusterID
+			if p, ok := merged[peer.ID]; ok && p.UpdatedAt.After(peer.UpdatedAt) {
+				continue
+			}
+
+			merged[peer.ID] = peer
+		}
+	}
+
+	peers := make([]types.InventoryPeer, 0, len(merged))
+	for _, peer := range merged {
+		peers = append(peers, peer)
+	}
+
+	sort.Slice(peers, func(i, j int) bool {
+		if !peers[i].CreatedAt.Equal(peers[j].CreatedAt) {
+			return peers[i].CreatedAt.After(peers[j].CreatedAt)
+		}
+
+		return peers[i].ID < peers[j].ID
+	})
+
+	start, end := paginateInventory(len(peers), q.Page, q.PerPage)
+	return peers[start:end], int64(len(peers)), nil
+}
+
+// getInventories returns the inventories reported by the schedulers of scheduler cluster
+// and tenant, the inventories of all schedulers are returned if both are zero.
+func (s *service) getInventories(ctx context.Context, schedulerClusterID, tenantID uint) ([]types.Inventory, error) {
+	var schedulerClusterIDs map[uint]struct{}
+	if tenantID != 0 {
+		var ids []uint
+		if err := s.db.WithContext(ctx).Model(&models.SchedulerCluster{}).Where("tenant_id = ?", tenantID).Pluck("id", &ids).Error; err != nil {
+			return nil, err
+		}
+
+		schedulerClusterIDs = make(map[uint]struct{}, len(ids))
+		for _, id := range ids {
+			schedulerClusterIDs[id] = struct{}{}
+		}
+	}
+
+	keys, err := s.rdb.Keys(ctx, pkgredis.MakeInventoriesKeyInManager()).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var inventories []types.Inventory
+	for _, key := range keys {
+		data, err := s.rdb.Get(ctx, key).Bytes()
+		if err != nil {
+			logger.Warnf("get inventory %s failed: %s", key, err.Error())
+			continue
+		}
+
+		var inventory types.Inventory
+		if err := json.Unmarshal(data, &inventory); err != nil {
+			logger.Warnf("unmarshal inventory %s failed: %s", key, err.Error())
+			continue
+		}
+
+		if schedulerClusterID != 0 && inventory.SchedulerClusterID != schedulerClusterID {
+			continue
+		}
+
+		if schedulerClusterIDs != nil {
+			if _, ok := schedulerClusterIDs[inventory.SchedulerClusterID]; !ok {
+				continue
+			}
+		}
+
+		inventories = append(inventories, inventory)
+	}
+
+	return inventories, nil
+}
+
+// paginateInventory returns the range of items in the page.
+func paginateInventory(count, page, perPage int) (int, int) {
+	start := (page - 1) * perPage
+	if start > count {
+		start = count
+	}
+
+	end := start + perPage
+	if end > count {
+		end = count
+	}
+
+	return start, end
+}
@@ -20,10 +20,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	machineryv1tasks "github.com/RichardKnop/machinery/v1/tasks"
+	"gorm.io/gorm"
 
 	logger "d7y.io/dragonfly/v2/internal/dflog"
+	internaljob "d7y.io/dragonfly/v2/internal/job"
 	"d7y.io/dragonfly/v2/manager/models"
 	"d7y.io/dragonfly/v2/manager/types"
 	"d7y.io/dragonfly/v2/pkg/retry"
@@ -32,19 +35,183 @@ import (
 )
 
 func (s *service) CreatePreheatJob(ctx context.Context, json types.CreatePreheatJobRequest) (*models.Job, error) {
-	candidateSchedulers, err := s.findCandidateSchedulers(ctx, json.SchedulerClusterIDs)
-	if err != nil {
+	var (
+		job                 models.Job
+		candidateSchedulers []models.Scheduler
+	)
+
+	// The quotas of tenant are reserved by creating the pending job in the same transaction,
+	// the tenant is locked until the job is created. The job is dispatched after the transaction
+	// is committed, so the tenant is not locked by fetching the manifests of images.
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		schedulerClusterIDs, maxBandwidth, err := s.applyTenantPreheatQuota(ctx, tx, json.TenantID, json.SchedulerClusterIDs, json.Args.MaxBandwidth)
+		if err != nil {
+			return err
+		}
+		json.SchedulerClusterIDs = schedulerClusterIDs
+		json.Args.MaxBandwidth = maxBandwidth
+
+		candidateSchedulers, err = s.findCandidateSchedulers(ctx, json.SchedulerClusterIDs)
+		if err != nil {
+			return err
+		}
+
+		var candidateSchedulerClusters []models.SchedulerCluster
+		for _, candidateScheduler := range candidateSchedulers {
+			candidateSchedulerClusters = append(candidateSchedulerClusters, candidateScheduler.SchedulerCluster)
+		}
+
+		args, err := structure.StructToMap(json.Args)
+		if err != nil {
+			return err
+		}
+
+		job = models.Job{
+			BIO:               json.BIO,
+			Type:              json.Type,
+			State:             machineryv1tasks.StatePending,
+			Args:              args,
+			UserID:            json.UserID,
+			TenantID:          json.TenantID,
+			SchedulerClusters: candidateSchedulerClusters,
+		}
+
+		return tx.Create(&job).Error
+	}); err != nil {
 		return nil, err
 	}
 
 	groupJobState, err := s.job.CreatePreheat(ctx, candidateSchedulers, json.Args)
 	if err != nil {
+		s.failJob(ctx, &job, err)
 		return nil, err
 	}
 
-	var candidateSchedulerClusters []models.SchedulerCluster
-	for _, candidateScheduler := range candidateSchedulers {
-		candidateSchedulerClusters = append(candidateSchedulerClusters, candidateScheduler.SchedulerCluster)
+	job.TaskID = groupJobState.GroupUUID
+	job.State = groupJobState.State
+	if err := s.db.WithContext(ctx).Model(&job).Updates(models.Job{
+		TaskID: job.TaskID,
+		State:  job.State,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	go s.pollingJob(context.Background(), job.ID, job.TaskID, job.Type, groupJobState.ScheduledAt)
+
+	return &job, nil
+}
+
+func (s *service) CreateBulkPreheatJob(ctx context.Context, json types.CreateBulkPreheatJobRequest) (*models.Job, error) {
+	var (
+		job                 models.Job
+		candidateSchedulers []models.Scheduler
+	)
+
+	// The quotas of tenant are reserved by creating the pending job in the same transaction,
+	// the tenant is locked until the job is created. The job is dispatched after the transaction
+	// is committed, so the tenant is not locked by listing the tags of repository.
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		schedulerClusterIDs, maxBandwidth, err := s.applyTenantPreheatQuota(ctx, tx, json.TenantID, json.SchedulerClusterIDs, json.Args.MaxBandwidth)
+		if err != nil {
+			return err
+		}
+		json.SchedulerClusterIDs = schedulerClusterIDs
+		json.Args.MaxBandwidth = maxBandwidth
+
+		candidateSchedulers, err = s.findCandidateSchedulers(ctx, json.SchedulerClusterIDs)
+		if err != nil {
+			return err
+		}
+
+		var candidateSchedulerClusters []models.SchedulerCluster
+		for _, candidateScheduler := range candidateSchedulers {
+			candidateSchedulerClusters = append(candidateSchedulerClusters, candidateScheduler.SchedulerCluster)
+		}
+
+		args, err := structure.StructToMap(json.Args)
+		if err != nil {
+			return err
+		}
+
+		job = models.Job{
+			BIO:               json.BIO,
+			Type:              internaljob.PreheatJob,
+			State:             machineryv1tasks.StatePending,
+			Args:              args,
+			UserID:            json.UserID,
+			TenantID:          json.TenantID,
+			SchedulerClusters: candidateSchedulerClusters,
+		}
+
+		return tx.Create(&job).Error
+	}); err != nil {
+		return nil, err
+	}
+
+	groupJobState, tags, err := s.job.CreateBulkPreheat(ctx, candidateSchedulers, json.Args)
+	if err != nil {
+		s.failJob(ctx, &job, err)
+		return nil, err
+	}
+
+	// Record the matched tags, as the tags of repository change over time.
+	job.Args["matched_tags"] = tags
+	job.TaskID = groupJobState.GroupUUID
+	job.State = groupJobState.State
+	if err := s.db.WithContext(ctx).Model(&job).Updates(models.Job{
+		TaskID: job.TaskID,
+		State:  job.State,
+		Args:   job.Args,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	go s.pollingJob(context.Background(), job.ID, job.TaskID, job.Type, groupJobState.ScheduledAt)
+
+	return &job, nil
+}
+
+// failJob marks the job failed if it is unable to be dispatched, which releases the quotas reserved by the job.
+func (s *service) failJob(ctx context.Context, job *models.Job, cause error) {
+	if err := s.db.WithContext(ctx).Model(job).Updates(models.Job{
+		State:  machineryv1tasks.StateFailure,
+		Result: models.JSONMap{"error": cause.Error()},
+	}).Error; err != nil {
+		logger.Errorf("mark job %d failed error: %s", job.ID, err.Error())
+	}
+}
+
+func (s *service) CreateGCDryRunJob(ctx context.Context, json types.CreateGCDryRunJobRequest) (*models.Job, error) {
+	var schedulers []models.Scheduler
+	query := s.db.WithContext(ctx).Preload("SchedulerCluster").Where("state = ?", models.SchedulerStateActive)
+	if len(json.SchedulerClusterIDs) != 0 {
+		query = query.Where("scheduler_cluster_id IN ?", json.SchedulerClusterIDs)
+	}
+
+	if err := query.Find(&schedulers).Error; err != nil {
+		return nil, err
+	}
+
+	if len(schedulers) == 0 {
+		return nil, errors.New("active schedulers not found")
+	}
+
+	groupJobState, err := s.job.CreateGCDryRun(ctx, schedulers, json.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		schedulerClusters   []models.SchedulerCluster
+		schedulerClusterIDs = map[uint]struct{}{}
+	)
+	for _, scheduler := range schedulers {
+		if _, ok := schedulerClusterIDs[scheduler.SchedulerClusterID]; ok {
+			continue
+		}
+
+		schedulerClusterIDs[scheduler.SchedulerClusterID] = struct{}{}
+		schedulerClusters = append(schedulerClusters, scheduler.SchedulerCluster)
 	}
 
 	args, err := structure.StructToMap(json.Args)
@@ -55,18 +222,18 @@ func (s *service) CreatePreheatJob(ctx context.Context, json types.CreatePreheat
 	job := models.Job{
 		TaskID:            groupJobState.GroupUUID,
 		BIO:               json.BIO,
-		Type:              json.Type,
+		Type:              internaljob.GCDryRunJob,
 		State:             groupJobState.State,
 		Args:              args,
 		UserID:            json.UserID,
-		SchedulerClusters: candidateSchedulerClusters,
+		SchedulerClusters: schedulerClusters,
 	}
 
 	if err := s.db.WithContext(ctx).Create(&job).Error; err != nil {
 		return nil, err
 	}
 
-	go s.pollingJob(context.Background(), job.ID, job.TaskID)
+	go s.pollingJob(context.Background(), job.ID, job.TaskID, job.Type, nil)
 
 	return &job, nil
 }
@@ -130,17 +297,33 @@ func (s *service) findCandidateSchedulers(ctx context.Context, schedulerClusterI
 	return candidateSchedulers, nil
 }
 
-func (s *service) pollingJob(ctx context.Context, id uint, groupID string) {
+func (s *service) pollingJob(ctx context.Context, id uint, groupID, jobType string, scheduledAt *time.Time) {
 	var (
-		job models.Job
-		log = logger.WithGroupAndJobID(groupID, fmt.Sprint(id))
+		job      models.Job
+		groupJob *internaljob.GroupJobState
+		log      = logger.WithGroupAndJobID(groupID, fmt.Sprint(id))
+		tracker  = newJobMetricsTracker(jobType)
 	)
+	defer tracker.release()
+
+	// Jobs scheduled in the future stay pending, so polling starts after the last job runs.
+	if scheduledAt != nil {
+		log.Infof("polling group is delayed until %s", scheduledAt.String())
+		select {
+		case <-time.After(time.Until(*scheduledAt)):
+		case <-ctx.Done():
+			return
+		}
+	}
+
 	if _, _, err := retry.Run(ctx, 5, 10, 480, func() (any, bool, error) {
-		groupJob, err := s.job.GetGroupJobState(groupID)
+		var err error
+		groupJob, err = s.job.GetGroupJobState(groupID)
 		if err != nil {
 			log.Errorf("polling group failed: %s", err.Error())
 			return nil, false, err
 		}
+		tracker.update(groupJob)
 
 		result, err := structure.StructToMap(groupJob)
 		if err != nil {
@@ -173,7 +356,8 @@ func (s *service) pollingJob(ctx context.Context, id uint, groupID string) {
 	}
 
 	// Polling timeout and failed.
-	if job.State != machineryv1tasks.StateSuccess && job.State != machineryv1tasks.StateFailure {
+	state := job.State
+	if state != machineryv1tasks.StateSuccess && state != machineryv1tasks.StateFailure {
 		job := models.Job{}
 		if err := s.db.WithContext(ctx).First(&job, id).Updates(models.Job{
 			State: machineryv1tasks.StateFailure,
@@ -181,7 +365,11 @@ func (s *service) pollingJob(ctx context.Context, id uint, groupID string) {
 			log.Errorf("polling group failed: %s", err.Error())
 		}
 		log.Error("polling group timeout")
+		state = machineryv1tasks.StateFailure
 	}
+	tracker.finish(state, groupJob)
+
+	s.notifyJobWebhooks(ctx, id, groupID)
 }
 
 func (s *service) DestroyJob(ctx context.Context, id uint) error {
@@ -222,9 +410,10 @@ func (s *service) GetJobs(ctx context.Context, q types.GetJobsQuery) ([]models.J
 	var count int64
 	var jobs []models.Job
 	if err := s.db.WithContext(ctx).Scopes(models.Paginate(q.Page, q.PerPage)).Where(&models.Job{
-		Type:   q.Type,
-		State:  q.State,
-		UserID: q.UserID,
+		Type:     q.Type,
+		State:    q.State,
+		UserID:   q.UserID,
+		TenantID: q.TenantID,
 	}).Find(&jobs).Limit(-1).Offset(-1).Count(&count).Error; err != nil {
 		return nil, 0, err
 	}
@@ -0,0 +1,107 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"time"
+
+	machineryv1tasks "github.com/RichardKnop/machinery/v1/tasks"
+
+	internaljob "d7y.io/dragonfly/v2/internal/job"
+	"d7y.io/dragonfly/v2/manager/metrics"
+)
+
+// jobMetricsTracker tracks the metrics of job during polling, the job is queued
+// until any task of group is received by schedulers, then it is running until finished.
+type jobMetricsTracker struct {
+	// jobType is the type of job.
+	jobType string
+
+	// createdAt is the time of creating job.
+	createdAt time.Time
+
+	// running is whether the job is running.
+	running bool
+
+	// released is whether the job is released from the gauges.
+	released bool
+}
+
+// newJobMetricsTracker returns a tracker of the job which is created and queued.
+func newJobMetricsTracker(jobType string) *jobMetricsTracker {
+	metrics.JobCount.WithLabelValues(jobType).Inc()
+	metrics.QueuedJobGauge.WithLabelValues(jobType).Inc()
+	return &jobMetricsTracker{
+		jobType:   jobType,
+		createdAt: time.Now(),
+	}
+}
+
+// update moves the job from queued to running once any task of group leaves the pending state.
+func (t *jobMetricsTracker) update(groupJob *internaljob.GroupJobState) {
+	if t.running || t.released {
+		return
+	}
+
+	for _, jobState := range groupJob.JobStates {
+		if jobState.State != machineryv1tasks.StatePending {
+			metrics.QueuedJobGauge.WithLabelValues(t.jobType).Dec()
+			metrics.RunningJobGauge.WithLabelValues(t.jobType).Inc()
+			t.running = true
+			return
+		}
+	}
+}
+
+// finish records the duration and the result of job, and the preheated layers of
+// preheat job, each task of preheat group preheats a layer in a scheduler cluster.
+func (t *jobMetricsTracker) finish(state string, groupJob *internaljob.GroupJobState) {
+	t.release()
+
+	metrics.JobDuration.WithLabelValues(t.jobType, state).Observe(float64(time.Since(t.createdAt).Milliseconds()))
+	if state != machineryv1tasks.StateSuccess {
+		metrics.JobFailureCount.WithLabelValues(t.jobType).Inc()
+	}
+
+	if t.jobType != internaljob.PreheatJob || groupJob == nil {
+		return
+	}
+
+	for _, jobState := range groupJob.JobStates {
+		switch {
+		case jobState.IsSuccess():
+			metrics.PreheatLayerCount.Inc()
+		case jobState.IsFailure():
+			metrics.PreheatLayerFailureCount.Inc()
+		}
+	}
+}
+
+// release removes the job from the gauges of queued and running jobs.
+func (t *jobMetricsTracker) release() {
+	if t.released {
+		return
+	}
+
+	if t.running {
+		metrics.RunningJobGauge.WithLabelValues(t.jobType).Dec()
+	} else {
+		metrics.QueuedJobGauge.WithLabelValues(t.jobType).Dec()
+	}
+
+	t.released = true
+}
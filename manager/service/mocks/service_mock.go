@@ -7,11 +7,13 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	models "d7y.io/dragonfly/v2/manager/models"
 	rbac "d7y.io/dragonfly/v2/manager/permission/rbac"
 	types "d7y.io/dragonfly/v2/manager/types"
 	objectstorage "d7y.io/dragonfly/v2/pkg/objectstorage"
+	structure "d7y.io/dragonfly/v2/pkg/structure"
 	gin "github.com/gin-gonic/gin"
 	gomock "github.com/golang/mock/gomock"
 )
@@ -39,6 +41,21 @@ func (m *MockService) EXPECT() *MockServiceMockRecorder {
 	return m.recorder
 }
 
+// AddOauthForUser mocks base method.
+func (m *MockService) AddOauthForUser(arg0 context.Context, arg1 types.AddOauthForUserParams, arg2 types.AddOauthForUserRequest) (*models.UserOauth, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddOauthForUser", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.UserOauth)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddOauthForUser indicates an expected call of AddOauthForUser.
+func (mr *MockServiceMockRecorder) AddOauthForUser(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOauthForUser", reflect.TypeOf((*MockService)(nil).AddOauthForUser), arg0, arg1, arg2)
+}
+
 // AddPermissionForRole mocks base method.
 func (m *MockService) AddPermissionForRole(arg0 context.Context, arg1 string, arg2 types.AddPermissionForRoleRequest) (bool, error) {
 	m.ctrl.T.Helper()
@@ -126,6 +143,20 @@ func (mr *MockServiceMockRecorder) CreateApplication(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateApplication", reflect.TypeOf((*MockService)(nil).CreateApplication), arg0, arg1)
 }
 
+// CreateAudit mocks base method.
+func (m *MockService) CreateAudit(arg0 context.Context, arg1 *models.Audit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAudit", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateAudit indicates an expected call of CreateAudit.
+func (mr *MockServiceMockRecorder) CreateAudit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAudit", reflect.TypeOf((*MockService)(nil).CreateAudit), arg0, arg1)
+}
+
 // CreateBucket mocks base method.
 func (m *MockService) CreateBucket(arg0 context.Context, arg1 types.CreateBucketRequest) error {
 	m.ctrl.T.Helper()
@@ -140,6 +171,21 @@ func (mr *MockServiceMockRecorder) CreateBucket(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBucket", reflect.TypeOf((*MockService)(nil).CreateBucket), arg0, arg1)
 }
 
+// CreateBulkPreheatJob mocks base method.
+func (m *MockService) CreateBulkPreheatJob(arg0 context.Context, arg1 types.CreateBulkPreheatJobRequest) (*models.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBulkPreheatJob", arg0, arg1)
+	ret0, _ := ret[0].(*models.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateBulkPreheatJob indicates an expected call of CreateBulkPreheatJob.
+func (mr *MockServiceMockRecorder) CreateBulkPreheatJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBulkPreheatJob", reflect.TypeOf((*MockService)(nil).CreateBulkPreheatJob), arg0, arg1)
+}
+
 // CreateCluster mocks base method.
 func (m *MockService) CreateCluster(arg0 context.Context, arg1 types.CreateClusterRequest) (*types.CreateClusterResponse, error) {
 	m.ctrl.T.Helper()
@@ -170,6 +216,21 @@ func (mr *MockServiceMockRecorder) CreateConfig(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateConfig", reflect.TypeOf((*MockService)(nil).CreateConfig), arg0, arg1)
 }
 
+// CreateGCDryRunJob mocks base method.
+func (m *MockService) CreateGCDryRunJob(arg0 context.Context, arg1 types.CreateGCDryRunJobRequest) (*models.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateGCDryRunJob", arg0, arg1)
+	ret0, _ := ret[0].(*models.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateGCDryRunJob indicates an expected call of CreateGCDryRunJob.
+func (mr *MockServiceMockRecorder) CreateGCDryRunJob(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGCDryRunJob", reflect.TypeOf((*MockService)(nil).CreateGCDryRunJob), arg0, arg1)
+}
+
 // CreateModel mocks base method.
 func (m *MockService) CreateModel(arg0 context.Context, arg1 types.CreateModelRequest) (*models.Model, error) {
 	m.ctrl.T.Helper()
@@ -200,6 +261,21 @@ func (mr *MockServiceMockRecorder) CreateOauth(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOauth", reflect.TypeOf((*MockService)(nil).CreateOauth), arg0, arg1)
 }
 
+// CreatePersonalAccessToken mocks base method.
+func (m *MockService) CreatePersonalAccessToken(arg0 context.Context, arg1 uint, arg2 types.CreatePersonalAccessTokenRequest) (*models.PersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePersonalAccessToken", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.PersonalAccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePersonalAccessToken indicates an expected call of CreatePersonalAccessToken.
+func (mr *MockServiceMockRecorder) CreatePersonalAccessToken(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePersonalAccessToken", reflect.TypeOf((*MockService)(nil).CreatePersonalAccessToken), arg0, arg1, arg2)
+}
+
 // CreatePreheatJob mocks base method.
 func (m *MockService) CreatePreheatJob(arg0 context.Context, arg1 types.CreatePreheatJobRequest) (*models.Job, error) {
 	m.ctrl.T.Helper()
@@ -259,6 +335,21 @@ func (mr *MockServiceMockRecorder) CreateSchedulerCluster(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSchedulerCluster", reflect.TypeOf((*MockService)(nil).CreateSchedulerCluster), arg0, arg1)
 }
 
+// CreateSearcherRule mocks base method.
+func (m *MockService) CreateSearcherRule(arg0 context.Context, arg1 types.CreateSearcherRuleRequest) (*models.SearcherRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSearcherRule", arg0, arg1)
+	ret0, _ := ret[0].(*models.SearcherRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateSearcherRule indicates an expected call of CreateSearcherRule.
+func (mr *MockServiceMockRecorder) CreateSearcherRule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSearcherRule", reflect.TypeOf((*MockService)(nil).CreateSearcherRule), arg0, arg1)
+}
+
 // CreateSeedPeer mocks base method.
 func (m *MockService) CreateSeedPeer(arg0 context.Context, arg1 types.CreateSeedPeerRequest) (*models.SeedPeer, error) {
 	m.ctrl.T.Helper()
@@ -289,6 +380,21 @@ func (mr *MockServiceMockRecorder) CreateSeedPeerCluster(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSeedPeerCluster", reflect.TypeOf((*MockService)(nil).CreateSeedPeerCluster), arg0, arg1)
 }
 
+// CreateTenant mocks base method.
+func (m *MockService) CreateTenant(arg0 context.Context, arg1 types.CreateTenantRequest) (*models.Tenant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTenant", arg0, arg1)
+	ret0, _ := ret[0].(*models.Tenant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTenant indicates an expected call of CreateTenant.
+func (mr *MockServiceMockRecorder) CreateTenant(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTenant", reflect.TypeOf((*MockService)(nil).CreateTenant), arg0, arg1)
+}
+
 // CreateV1Preheat mocks base method.
 func (m *MockService) CreateV1Preheat(arg0 context.Context, arg1 types.CreateV1PreheatRequest) (*types.CreateV1PreheatResponse, error) {
 	m.ctrl.T.Helper()
@@ -304,6 +410,21 @@ func (mr *MockServiceMockRecorder) CreateV1Preheat(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateV1Preheat", reflect.TypeOf((*MockService)(nil).CreateV1Preheat), arg0, arg1)
 }
 
+// CreateWebhook mocks base method.
+func (m *MockService) CreateWebhook(arg0 context.Context, arg1 types.CreateWebhookRequest) (*models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", arg0, arg1)
+	ret0, _ := ret[0].(*models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockServiceMockRecorder) CreateWebhook(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockService)(nil).CreateWebhook), arg0, arg1)
+}
+
 // DeletePermissionForRole mocks base method.
 func (m *MockService) DeletePermissionForRole(arg0 context.Context, arg1 string, arg2 types.DeletePermissionForRoleRequest) (bool, error) {
 	m.ctrl.T.Helper()
@@ -348,6 +469,21 @@ func (mr *MockServiceMockRecorder) DestroyApplication(arg0, arg1 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DestroyApplication", reflect.TypeOf((*MockService)(nil).DestroyApplication), arg0, arg1)
 }
 
+// DestroyAuditsBefore mocks base method.
+func (m *MockService) DestroyAuditsBefore(arg0 context.Context, arg1 time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DestroyAuditsBefore", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DestroyAuditsBefore indicates an expected call of DestroyAuditsBefore.
+func (mr *MockServiceMockRecorder) DestroyAuditsBefore(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DestroyAuditsBefore", reflect.TypeOf((*MockService)(nil).DestroyAuditsBefore), arg0, arg1)
+}
+
 // DestroyBucket mocks base method.
 func (m *MockService) DestroyBucket(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -432,6 +568,20 @@ func (mr *MockServiceMockRecorder) DestroyOauth(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DestroyOauth", reflect.TypeOf((*MockService)(nil).DestroyOauth), arg0, arg1)
 }
 
+// DestroyPersonalAccessToken mocks base method.
+func (m *MockService) DestroyPersonalAccessToken(arg0 context.Context, arg1, arg2 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DestroyPersonalAccessToken", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DestroyPersonalAccessToken indicates an expected call of DestroyPersonalAccessToken.
+func (mr *MockServiceMockRecorder) DestroyPersonalAccessToken(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DestroyPersonalAccessToken", reflect.TypeOf((*MockService)(nil).DestroyPersonalAccessToken), arg0, arg1, arg2)
+}
+
 // DestroyRole mocks base method.
 func (m *MockService) DestroyRole(arg0 context.Context, arg1 string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -475,6 +625,20 @@ func (mr *MockServiceMockRecorder) DestroySchedulerCluster(arg0, arg1 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DestroySchedulerCluster", reflect.TypeOf((*MockService)(nil).DestroySchedulerCluster), arg0, arg1)
 }
 
+// DestroySearcherRule mocks base method.
+func (m *MockService) DestroySearcherRule(arg0 context.Context, arg1 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DestroySearcherRule", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DestroySearcherRule indicates an expected call of DestroySearcherRule.
+func (mr *MockServiceMockRecorder) DestroySearcherRule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DestroySearcherRule", reflect.TypeOf((*MockService)(nil).DestroySearcherRule), arg0, arg1)
+}
+
 // DestroySeedPeer mocks base method.
 func (m *MockService) DestroySeedPeer(arg0 context.Context, arg1 uint) error {
 	m.ctrl.T.Helper()
@@ -503,6 +667,49 @@ func (mr *MockServiceMockRecorder) DestroySeedPeerCluster(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DestroySeedPeerCluster", reflect.TypeOf((*MockService)(nil).DestroySeedPeerCluster), arg0, arg1)
 }
 
+// DestroyTenant mocks base method.
+func (m *MockService) DestroyTenant(arg0 context.Context, arg1 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DestroyTenant", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DestroyTenant indicates an expected call of DestroyTenant.
+func (mr *MockServiceMockRecorder) DestroyTenant(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DestroyTenant", reflect.TypeOf((*MockService)(nil).DestroyTenant), arg0, arg1)
+}
+
+// DestroyWebhook mocks base method.
+func (m *MockService) DestroyWebhook(arg0 context.Context, arg1 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DestroyWebhook", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DestroyWebhook indicates an expected call of DestroyWebhook.
+func (mr *MockServiceMockRecorder) DestroyWebhook(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DestroyWebhook", reflect.TypeOf((*MockService)(nil).DestroyWebhook), arg0, arg1)
+}
+
+// DiffConfigVersions mocks base method.
+func (m *MockService) DiffConfigVersions(arg0 context.Context, arg1 string, arg2 uint, arg3 types.DiffConfigVersionsQuery) ([]structure.Diff, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DiffConfigVersions", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]structure.Diff)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DiffConfigVersions indicates an expected call of DiffConfigVersions.
+func (mr *MockServiceMockRecorder) DiffConfigVersions(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DiffConfigVersions", reflect.TypeOf((*MockService)(nil).DiffConfigVersions), arg0, arg1, arg2, arg3)
+}
+
 // GetApplication mocks base method.
 func (m *MockService) GetApplication(arg0 context.Context, arg1 uint) (*models.Application, error) {
 	m.ctrl.T.Helper()
@@ -534,6 +741,52 @@ func (mr *MockServiceMockRecorder) GetApplications(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetApplications", reflect.TypeOf((*MockService)(nil).GetApplications), arg0, arg1)
 }
 
+// GetAudit mocks base method.
+func (m *MockService) GetAudit(arg0 context.Context, arg1 uint) (*models.Audit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAudit", arg0, arg1)
+	ret0, _ := ret[0].(*models.Audit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAudit indicates an expected call of GetAudit.
+func (mr *MockServiceMockRecorder) GetAudit(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAudit", reflect.TypeOf((*MockService)(nil).GetAudit), arg0, arg1)
+}
+
+// GetAuditResourceState mocks base method.
+func (m *MockService) GetAuditResourceState(arg0 context.Context, arg1 string, arg2 uint) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAuditResourceState", arg0, arg1, arg2)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAuditResourceState indicates an expected call of GetAuditResourceState.
+func (mr *MockServiceMockRecorder) GetAuditResourceState(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAuditResourceState", reflect.TypeOf((*MockService)(nil).GetAuditResourceState), arg0, arg1, arg2)
+}
+
+// GetAudits mocks base method.
+func (m *MockService) GetAudits(arg0 context.Context, arg1 types.GetAuditsQuery) ([]models.Audit, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAudits", arg0, arg1)
+	ret0, _ := ret[0].([]models.Audit)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetAudits indicates an expected call of GetAudits.
+func (mr *MockServiceMockRecorder) GetAudits(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAudits", reflect.TypeOf((*MockService)(nil).GetAudits), arg0, arg1)
+}
+
 // GetBucket mocks base method.
 func (m *MockService) GetBucket(arg0 context.Context, arg1 string) (*objectstorage.BucketMetadata, error) {
 	m.ctrl.T.Helper()
@@ -610,6 +863,52 @@ func (mr *MockServiceMockRecorder) GetConfig(arg0, arg1 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfig", reflect.TypeOf((*MockService)(nil).GetConfig), arg0, arg1)
 }
 
+// GetConfigVersion mocks base method.
+func (m *MockService) GetConfigVersion(arg0 context.Context, arg1 string, arg2, arg3 uint) (*models.ConfigVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConfigVersion", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.ConfigVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConfigVersion indicates an expected call of GetConfigVersion.
+func (mr *MockServiceMockRecorder) GetConfigVersion(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfigVersion", reflect.TypeOf((*MockService)(nil).GetConfigVersion), arg0, arg1, arg2, arg3)
+}
+
+// GetConfigVersionReports mocks base method.
+func (m *MockService) GetConfigVersionReports(arg0 context.Context, arg1 string, arg2 uint) ([]types.ConfigVersionReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConfigVersionReports", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]types.ConfigVersionReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConfigVersionReports indicates an expected call of GetConfigVersionReports.
+func (mr *MockServiceMockRecorder) GetConfigVersionReports(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfigVersionReports", reflect.TypeOf((*MockService)(nil).GetConfigVersionReports), arg0, arg1, arg2)
+}
+
+// GetConfigVersions mocks base method.
+func (m *MockService) GetConfigVersions(arg0 context.Context, arg1 string, arg2 uint, arg3 types.GetConfigVersionsQuery) ([]models.ConfigVersion, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConfigVersions", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]models.ConfigVersion)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetConfigVersions indicates an expected call of GetConfigVersions.
+func (mr *MockServiceMockRecorder) GetConfigVersions(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfigVersions", reflect.TypeOf((*MockService)(nil).GetConfigVersions), arg0, arg1, arg2, arg3)
+}
+
 // GetConfigs mocks base method.
 func (m *MockService) GetConfigs(arg0 context.Context, arg1 types.GetConfigsQuery) ([]models.Config, int64, error) {
 	m.ctrl.T.Helper()
@@ -626,6 +925,38 @@ func (mr *MockServiceMockRecorder) GetConfigs(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfigs", reflect.TypeOf((*MockService)(nil).GetConfigs), arg0, arg1)
 }
 
+// GetInventoryHosts mocks base method.
+func (m *MockService) GetInventoryHosts(arg0 context.Context, arg1 types.GetInventoryHostsQuery) ([]types.InventoryHost, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInventoryHosts", arg0, arg1)
+	ret0, _ := ret[0].([]types.InventoryHost)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetInventoryHosts indicates an expected call of GetInventoryHosts.
+func (mr *MockServiceMockRecorder) GetInventoryHosts(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInventoryHosts", reflect.TypeOf((*MockService)(nil).GetInventoryHosts), arg0, arg1)
+}
+
+// GetInventoryPeers mocks base method.
+func (m *MockService) GetInventoryPeers(arg0 context.Context, arg1 types.GetInventoryPeersQuery) ([]types.InventoryPeer, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInventoryPeers", arg0, arg1)
+	ret0, _ := ret[0].([]types.InventoryPeer)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetInventoryPeers indicates an expected call of GetInventoryPeers.
+func (mr *MockServiceMockRecorder) GetInventoryPeers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInventoryPeers", reflect.TypeOf((*MockService)(nil).GetInventoryPeers), arg0, arg1)
+}
+
 // GetJob mocks base method.
 func (m *MockService) GetJob(arg0 context.Context, arg1 uint) (*models.Job, error) {
 	m.ctrl.T.Helper()
@@ -748,6 +1079,37 @@ func (mr *MockServiceMockRecorder) GetPermissions(arg0, arg1 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPermissions", reflect.TypeOf((*MockService)(nil).GetPermissions), arg0, arg1)
 }
 
+// GetPersonalAccessToken mocks base method.
+func (m *MockService) GetPersonalAccessToken(arg0 context.Context, arg1 uint) (*models.PersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPersonalAccessToken", arg0, arg1)
+	ret0, _ := ret[0].(*models.PersonalAccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPersonalAccessToken indicates an expected call of GetPersonalAccessToken.
+func (mr *MockServiceMockRecorder) GetPersonalAccessToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonalAccessToken", reflect.TypeOf((*MockService)(nil).GetPersonalAccessToken), arg0, arg1)
+}
+
+// GetPersonalAccessTokens mocks base method.
+func (m *MockService) GetPersonalAccessTokens(arg0 context.Context, arg1 types.GetPersonalAccessTokensQuery) ([]models.PersonalAccessToken, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPersonalAccessTokens", arg0, arg1)
+	ret0, _ := ret[0].([]models.PersonalAccessToken)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetPersonalAccessTokens indicates an expected call of GetPersonalAccessTokens.
+func (mr *MockServiceMockRecorder) GetPersonalAccessTokens(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPersonalAccessTokens", reflect.TypeOf((*MockService)(nil).GetPersonalAccessTokens), arg0, arg1)
+}
+
 // GetRole mocks base method.
 func (m *MockService) GetRole(arg0 context.Context, arg1 string) [][]string {
 	m.ctrl.T.Helper()
@@ -853,6 +1215,37 @@ func (mr *MockServiceMockRecorder) GetSchedulers(arg0, arg1 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSchedulers", reflect.TypeOf((*MockService)(nil).GetSchedulers), arg0, arg1)
 }
 
+// GetSearcherRule mocks base method.
+func (m *MockService) GetSearcherRule(arg0 context.Context, arg1 uint) (*models.SearcherRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSearcherRule", arg0, arg1)
+	ret0, _ := ret[0].(*models.SearcherRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSearcherRule indicates an expected call of GetSearcherRule.
+func (mr *MockServiceMockRecorder) GetSearcherRule(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSearcherRule", reflect.TypeOf((*MockService)(nil).GetSearcherRule), arg0, arg1)
+}
+
+// GetSearcherRules mocks base method.
+func (m *MockService) GetSearcherRules(arg0 context.Context, arg1 types.GetSearcherRulesQuery) ([]models.SearcherRule, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSearcherRules", arg0, arg1)
+	ret0, _ := ret[0].([]models.SearcherRule)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetSearcherRules indicates an expected call of GetSearcherRules.
+func (mr *MockServiceMockRecorder) GetSearcherRules(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSearcherRules", reflect.TypeOf((*MockService)(nil).GetSearcherRules), arg0, arg1)
+}
+
 // GetSeedPeer mocks base method.
 func (m *MockService) GetSeedPeer(arg0 context.Context, arg1 uint) (*models.SeedPeer, error) {
 	m.ctrl.T.Helper()
@@ -899,6 +1292,21 @@ func (mr *MockServiceMockRecorder) GetSeedPeerClusters(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSeedPeerClusters", reflect.TypeOf((*MockService)(nil).GetSeedPeerClusters), arg0, arg1)
 }
 
+// GetSeedPeerSaturation mocks base method.
+func (m *MockService) GetSeedPeerSaturation(arg0 context.Context, arg1 uint) (*types.SeedPeerSaturation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSeedPeerSaturation", arg0, arg1)
+	ret0, _ := ret[0].(*types.SeedPeerSaturation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSeedPeerSaturation indicates an expected call of GetSeedPeerSaturation.
+func (mr *MockServiceMockRecorder) GetSeedPeerSaturation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSeedPeerSaturation", reflect.TypeOf((*MockService)(nil).GetSeedPeerSaturation), arg0, arg1)
+}
+
 // GetSeedPeers mocks base method.
 func (m *MockService) GetSeedPeers(arg0 context.Context, arg1 types.GetSeedPeersQuery) ([]models.SeedPeer, int64, error) {
 	m.ctrl.T.Helper()
@@ -915,6 +1323,37 @@ func (mr *MockServiceMockRecorder) GetSeedPeers(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSeedPeers", reflect.TypeOf((*MockService)(nil).GetSeedPeers), arg0, arg1)
 }
 
+// GetTenant mocks base method.
+func (m *MockService) GetTenant(arg0 context.Context, arg1 uint) (*models.Tenant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTenant", arg0, arg1)
+	ret0, _ := ret[0].(*models.Tenant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTenant indicates an expected call of GetTenant.
+func (mr *MockServiceMockRecorder) GetTenant(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTenant", reflect.TypeOf((*MockService)(nil).GetTenant), arg0, arg1)
+}
+
+// GetTenants mocks base method.
+func (m *MockService) GetTenants(arg0 context.Context, arg1 types.GetTenantsQuery) ([]models.Tenant, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTenants", arg0, arg1)
+	ret0, _ := ret[0].([]models.Tenant)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetTenants indicates an expected call of GetTenants.
+func (mr *MockServiceMockRecorder) GetTenants(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTenants", reflect.TypeOf((*MockService)(nil).GetTenants), arg0, arg1)
+}
+
 // GetUser mocks base method.
 func (m *MockService) GetUser(arg0 context.Context, arg1 uint) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -961,6 +1400,37 @@ func (mr *MockServiceMockRecorder) GetV1Preheat(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetV1Preheat", reflect.TypeOf((*MockService)(nil).GetV1Preheat), arg0, arg1)
 }
 
+// GetWebhook mocks base method.
+func (m *MockService) GetWebhook(arg0 context.Context, arg1 uint) (*models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhook", arg0, arg1)
+	ret0, _ := ret[0].(*models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhook indicates an expected call of GetWebhook.
+func (mr *MockServiceMockRecorder) GetWebhook(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhook", reflect.TypeOf((*MockService)(nil).GetWebhook), arg0, arg1)
+}
+
+// GetWebhooks mocks base method.
+func (m *MockService) GetWebhooks(arg0 context.Context, arg1 types.GetWebhooksQuery) ([]models.Webhook, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhooks", arg0, arg1)
+	ret0, _ := ret[0].([]models.Webhook)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetWebhooks indicates an expected call of GetWebhooks.
+func (mr *MockServiceMockRecorder) GetWebhooks(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhooks", reflect.TypeOf((*MockService)(nil).GetWebhooks), arg0, arg1)
+}
+
 // OauthSignin mocks base method.
 func (m *MockService) OauthSignin(arg0 context.Context, arg1 string) (string, error) {
 	m.ctrl.T.Helper()
@@ -1005,6 +1475,21 @@ func (mr *MockServiceMockRecorder) ResetPassword(arg0, arg1, arg2 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockService)(nil).ResetPassword), arg0, arg1, arg2)
 }
 
+// RollbackConfigVersion mocks base method.
+func (m *MockService) RollbackConfigVersion(arg0 context.Context, arg1 string, arg2, arg3 uint) (*models.ConfigVersion, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RollbackConfigVersion", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.ConfigVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RollbackConfigVersion indicates an expected call of RollbackConfigVersion.
+func (mr *MockServiceMockRecorder) RollbackConfigVersion(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RollbackConfigVersion", reflect.TypeOf((*MockService)(nil).RollbackConfigVersion), arg0, arg1, arg2, arg3)
+}
+
 // SignIn mocks base method.
 func (m *MockService) SignIn(arg0 context.Context, arg1 types.SignInRequest) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -1125,6 +1610,21 @@ func (mr *MockServiceMockRecorder) UpdateOauth(arg0, arg1, arg2 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOauth", reflect.TypeOf((*MockService)(nil).UpdateOauth), arg0, arg1, arg2)
 }
 
+// UpdatePersonalAccessToken mocks base method.
+func (m *MockService) UpdatePersonalAccessToken(arg0 context.Context, arg1, arg2 uint, arg3 types.UpdatePersonalAccessTokenRequest) (*models.PersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePersonalAccessToken", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.PersonalAccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePersonalAccessToken indicates an expected call of UpdatePersonalAccessToken.
+func (mr *MockServiceMockRecorder) UpdatePersonalAccessToken(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePersonalAccessToken", reflect.TypeOf((*MockService)(nil).UpdatePersonalAccessToken), arg0, arg1, arg2, arg3)
+}
+
 // UpdateScheduler mocks base method.
 func (m *MockService) UpdateScheduler(arg0 context.Context, arg1 uint, arg2 types.UpdateSchedulerRequest) (*models.Scheduler, error) {
 	m.ctrl.T.Helper()
@@ -1155,6 +1655,21 @@ func (mr *MockServiceMockRecorder) UpdateSchedulerCluster(arg0, arg1, arg2 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSchedulerCluster", reflect.TypeOf((*MockService)(nil).UpdateSchedulerCluster), arg0, arg1, arg2)
 }
 
+// UpdateSearcherRule mocks base method.
+func (m *MockService) UpdateSearcherRule(arg0 context.Context, arg1 uint, arg2 types.UpdateSearcherRuleRequest) (*models.SearcherRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSearcherRule", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.SearcherRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateSearcherRule indicates an expected call of UpdateSearcherRule.
+func (mr *MockServiceMockRecorder) UpdateSearcherRule(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSearcherRule", reflect.TypeOf((*MockService)(nil).UpdateSearcherRule), arg0, arg1, arg2)
+}
+
 // UpdateSeedPeer mocks base method.
 func (m *MockService) UpdateSeedPeer(arg0 context.Context, arg1 uint, arg2 types.UpdateSeedPeerRequest) (*models.SeedPeer, error) {
 	m.ctrl.T.Helper()
@@ -1185,6 +1700,21 @@ func (mr *MockServiceMockRecorder) UpdateSeedPeerCluster(arg0, arg1, arg2 interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSeedPeerCluster", reflect.TypeOf((*MockService)(nil).UpdateSeedPeerCluster), arg0, arg1, arg2)
 }
 
+// UpdateTenant mocks base method.
+func (m *MockService) UpdateTenant(arg0 context.Context, arg1 uint, arg2 types.UpdateTenantRequest) (*models.Tenant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateTenant", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.Tenant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateTenant indicates an expected call of UpdateTenant.
+func (mr *MockServiceMockRecorder) UpdateTenant(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateTenant", reflect.TypeOf((*MockService)(nil).UpdateTenant), arg0, arg1, arg2)
+}
+
 // UpdateUser mocks base method.
 func (m *MockService) UpdateUser(arg0 context.Context, arg1 uint, arg2 types.UpdateUserRequest) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -1199,3 +1729,33 @@ func (mr *MockServiceMockRecorder) UpdateUser(arg0, arg1, arg2 interface{}) *gom
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockService)(nil).UpdateUser), arg0, arg1, arg2)
 }
+
+// UpdateWebhook mocks base method.
+func (m *MockService) UpdateWebhook(arg0 context.Context, arg1 uint, arg2 types.UpdateWebhookRequest) (*models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWebhook", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWebhook indicates an expected call of UpdateWebhook.
+func (mr *MockServiceMockRecorder) UpdateWebhook(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWebhook", reflect.TypeOf((*MockService)(nil).UpdateWebhook), arg0, arg1, arg2)
+}
+
+// ValidatePersonalAccessToken mocks base method.
+func (m *MockService) ValidatePersonalAccessToken(arg0 context.Context, arg1 string) (*models.PersonalAccessToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ValidatePersonalAccessToken", arg0, arg1)
+	ret0, _ := ret[0].(*models.PersonalAccessToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ValidatePersonalAccessToken indicates an expected call of ValidatePersonalAccessToken.
+func (mr *MockServiceMockRecorder) ValidatePersonalAccessToken(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidatePersonalAccessToken", reflect.TypeOf((*MockService)(nil).ValidatePersonalAccessToken), arg0, arg1)
+}
@@ -30,6 +30,9 @@ func (s *service) CreateOauth(ctx context.Context, json types.CreateOauthRequest
 		ClientID:     json.ClientID,
 		ClientSecret: json.ClientSecret,
 		RedirectURL:  json.RedirectURL,
+		IssuerURL:    json.IssuerURL,
+		GroupsClaim:  json.GroupsClaim,
+		GroupRoles:   stringMapToJSONMap(json.GroupRoles),
 	}
 
 	if err := s.db.WithContext(ctx).Create(&oauth).Error; err != nil {
@@ -60,6 +63,9 @@ func (s *service) UpdateOauth(ctx context.Context, id uint, json types.UpdateOau
 		ClientID:     json.ClientID,
 		ClientSecret: json.ClientSecret,
 		RedirectURL:  json.RedirectURL,
+		IssuerURL:    json.IssuerURL,
+		GroupsClaim:  json.GroupsClaim,
+		GroupRoles:   stringMapToJSONMap(json.GroupRoles),
 	}).Error; err != nil {
 		return nil, err
 	}
@@ -88,3 +94,17 @@ func (s *service) GetOauths(ctx context.Context, q types.GetOauthsQuery) ([]mode
 
 	return oauths, count, nil
 }
+
+// stringMapToJSONMap converts the string map to json map.
+func stringMapToJSONMap(m map[string]string) models.JSONMap {
+	if m == nil {
+		return nil
+	}
+
+	jsonMap := models.JSONMap{}
+	for k, v := range m {
+		jsonMap[k] = v
+	}
+
+	return jsonMap
+}
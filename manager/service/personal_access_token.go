@@ -0,0 +1,255 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"d7y.io/dragonfly/v2/manager/models"
+	"d7y.io/dragonfly/v2/manager/permission/rbac"
+	"d7y.io/dragonfly/v2/manager/types"
+	"d7y.io/dragonfly/v2/pkg/digest"
+)
+
+// personalAccessTokenLength is the length of random bytes in personal access token.
+const personalAccessTokenLength = 32
+
+var (
+	// ErrPermissionDenied is the error of the user having no permission.
+	ErrPermissionDenied = errors.New("permission denied")
+
+	// ErrInvalidPersonalAccessToken is the error of the personal access token
+	// being unknown, inactive or expired.
+	ErrInvalidPersonalAccessToken = errors.New("invalid personal access token")
+)
+
+func (s *service) CreatePersonalAccessToken(ctx context.Context, userID uint, json types.CreatePersonalAccessTokenRequest) (*models.PersonalAccessToken, error) {
+	tokenType := json.Type
+	if tokenType == "" {
+		tokenType = models.PersonalAccessTokenTypePersonal
+	}
+
+	if err := s.checkPersonalAccessTokenType(userID, tokenType); err != nil {
+		return nil, err
+	}
+
+	if !json.ExpiredAt.After(time.Now()) {
+		return nil, errors.New("expired_at must be in the future")
+	}
+
+	schedulerClusters, err := s.findSchedulerClusters(ctx, json.SchedulerClusterIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.findTenant(ctx, json.TenantID); err != nil {
+		return nil, err
+	}
+
+	if err := checkTenantSchedulerClusters(json.TenantID, schedulerClusters); err != nil {
+		return nil, err
+	}
+
+	token, err := generatePersonalAccessToken()
+	if err != nil {
+		return nil, err
+	}
+
+	personalAccessToken := models.PersonalAccessToken{
+		Name:              json.Name,
+		BIO:               json.BIO,
+		Type:              tokenType,
+		TokenHash:         digest.SHA256FromStrings(token),
+		Scopes:            json.Scopes,
+		State:             models.PersonalAccessTokenStateActive,
+		ExpiredAt:         json.ExpiredAt,
+		UserID:            userID,
+		TenantID:          json.TenantID,
+		SchedulerClusters: schedulerClusters,
+	}
+
+	if err := s.db.WithContext(ctx).Create(&personalAccessToken).Error; err != nil {
+		return nil, err
+	}
+
+	// The token is only returned once, only its hash is stored.
+	personalAccessToken.Token = token
+	return &personalAccessToken, nil
+}
+
+func (s *service) DestroyPersonalAccessToken(ctx context.Context, userID, id uint) error {
+	personalAccessToken := models.PersonalAccessToken{}
+	if err := s.db.WithContext(ctx).First(&personalAccessToken, id).Error; err != nil {
+		return err
+	}
+
+	if err := s.checkPersonalAccessTokenOwner(userID, personalAccessToken); err != nil {
+		return err
+	}
+
+	if err := s.db.WithContext(ctx).Model(&personalAccessToken).Association("SchedulerClusters").Clear(); err != nil {
+		return err
+	}
+
+	if err := s.db.WithContext(ctx).Unscoped().Delete(&models.PersonalAccessToken{}, id).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) UpdatePersonalAccessToken(ctx context.Context, userID, id uint, json types.UpdatePersonalAccessTokenRequest) (*models.PersonalAccessToken, error) {
+	personalAccessToken := models.PersonalAccessToken{}
+	if err := s.db.WithContext(ctx).First(&personalAccessToken, id).Error; err != nil {
+		return nil, err
+	}
+
+	if err := s.checkPersonalAccessTokenOwner(userID, personalAccessToken); err != nil {
+		return nil, err
+	}
+
+	if err := s.db.WithContext(ctx).Model(&personalAccessToken).Updates(models.PersonalAccessToken{
+		BIO:       json.BIO,
+		Scopes:    json.Scopes,
+		State:     json.State,
+		ExpiredAt: json.ExpiredAt,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	if json.SchedulerClusterIDs != nil {
+		schedulerClusters, err := s.findSchedulerClusters(ctx, json.SchedulerClusterIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := checkTenantSchedulerClusters(personalAccessToken.TenantID, schedulerClusters); err != nil {
+			return nil, err
+		}
+
+		if err := s.db.WithContext(ctx).Model(&personalAccessToken).Association("SchedulerClusters").Replace(schedulerClusters); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetPersonalAccessToken(ctx, id)
+}
+
+func (s *service) GetPersonalAccessToken(ctx context.Context, id uint) (*models.PersonalAccessToken, error) {
+	personalAccessToken := models.PersonalAccessToken{}
+	if err := s.db.WithContext(ctx).Preload("SchedulerClusters").First(&personalAccessToken, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &personalAccessToken, nil
+}
+
+func (s *service) GetPersonalAccessTokens(ctx context.Context, q types.GetPersonalAccessTokensQuery) ([]models.PersonalAccessToken, int64, error) {
+	var count int64
+	var personalAccessTokens []models.PersonalAccessToken
+	if err := s.db.WithContext(ctx).Scopes(models.Paginate(q.Page, q.PerPage)).Where(&models.PersonalAccessToken{
+		Type:   q.Type,
+		State:  q.State,
+		UserID: q.UserID,
+	}).Preload("SchedulerClusters").Find(&personalAccessTokens).Limit(-1).Offset(-1).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return personalAccessTokens, count, nil
+}
+
+// ValidatePersonalAccessToken returns the personal access token if it is active,
+// not expired and its user is enabled.
+func (s *service) ValidatePersonalAccessToken(ctx context.Context, token string) (*models.PersonalAccessToken, error) {
+	if !strings.HasPrefix(token, models.PersonalAccessTokenPrefix) {
+		return nil, ErrInvalidPersonalAccessToken
+	}
+
+	personalAccessToken := models.PersonalAccessToken{}
+	if err := s.db.WithContext(ctx).Preload("User").Preload("SchedulerClusters").First(&personalAccessToken, models.PersonalAccessToken{
+		TokenHash: digest.SHA256FromStrings(token),
+	}).Error; err != nil {
+		return nil, ErrInvalidPersonalAccessToken
+	}
+
+	if personalAccessToken.State != models.PersonalAccessTokenStateActive ||
+		!personalAccessToken.ExpiredAt.After(time.Now()) ||
+		personalAccessToken.User.State != models.UserStateEnabled {
+		return nil, ErrInvalidPersonalAccessToken
+	}
+
+	return &personalAccessToken, nil
+}
+
+// checkPersonalAccessTokenType checks whether the user is allowed to own the type of token.
+// Service token is not restricted by the roles of user, so only root owns it.
+func (s *service) checkPersonalAccessTokenType(userID uint, tokenType string) error {
+	if tokenType != models.PersonalAccessTokenTypeService {
+		return nil
+	}
+
+	ok, err := s.enforcer.HasRoleForUser(fmt.Sprint(userID), rbac.RootRole)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return ErrPermissionDenied
+	}
+
+	return nil
+}
+
+// checkPersonalAccessTokenOwner checks whether the user is allowed to modify the token,
+// only the owner modifies the token, and only root modifies the service token.
+func (s *service) checkPersonalAccessTokenOwner(userID uint, personalAccessToken models.PersonalAccessToken) error {
+	if personalAccessToken.UserID != userID {
+		return ErrPermissionDenied
+	}
+
+	return s.checkPersonalAccessTokenType(userID, personalAccessToken.Type)
+}
+
+// findSchedulerClusters finds the scheduler clusters by ids.
+func (s *service) findSchedulerClusters(ctx context.Context, ids []uint) ([]models.SchedulerCluster, error) {
+	var schedulerClusters []models.SchedulerCluster
+	for _, id := range ids {
+		schedulerCluster := models.SchedulerCluster{}
+		if err := s.db.WithContext(ctx).First(&schedulerCluster, id).Error; err != nil {
+			return nil, err
+		}
+
+		schedulerClusters = append(schedulerClusters, schedulerCluster)
+	}
+
+	return schedulerClusters, nil
+}
+
+// generatePersonalAccessToken generates the random personal access token.
+func generatePersonalAccessToken() (string, error) {
+	b := make([]byte, personalAccessTokenLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return models.PersonalAccessTokenPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -18,14 +18,21 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 
+	logger "d7y.io/dragonfly/v2/internal/dflog"
 	"d7y.io/dragonfly/v2/manager/models"
 	"d7y.io/dragonfly/v2/manager/types"
+	pkgredis "d7y.io/dragonfly/v2/pkg/redis"
 	"d7y.io/dragonfly/v2/pkg/structure"
 )
 
 func (s *service) CreateSchedulerCluster(ctx context.Context, json types.CreateSchedulerClusterRequest) (*models.SchedulerCluster, error) {
+	if _, err := s.findTenant(ctx, json.TenantID); err != nil {
+		return nil, err
+	}
+
 	config, err := structure.StructToMap(json.Config)
 	if err != nil {
 		return nil, err
@@ -48,12 +55,17 @@ func (s *service) CreateSchedulerCluster(ctx context.Context, json types.CreateS
 		ClientConfig: clientConfig,
 		Scopes:       scopes,
 		IsDefault:    json.IsDefault,
+		TenantID:     json.TenantID,
 	}
 
 	if err := s.db.WithContext(ctx).Create(&schedulerCluster).Error; err != nil {
 		return nil, err
 	}
 
+	if _, err := s.createConfigVersion(ctx, types.ConfigVersionClusterTypeScheduler, schedulerCluster.ID, 0); err != nil {
+		return nil, err
+	}
+
 	if json.SeedPeerClusterID > 0 {
 		if err := s.AddSchedulerClusterToSeedPeerCluster(ctx, json.SeedPeerClusterID, schedulerCluster.ID); err != nil {
 			return nil, err
@@ -81,6 +93,10 @@ func (s *service) DestroySchedulerCluster(ctx context.Context, id uint) error {
 }
 
 func (s *service) UpdateSchedulerCluster(ctx context.Context, id uint, json types.UpdateSchedulerClusterRequest) (*models.SchedulerCluster, error) {
+	if _, err := s.findTenant(ctx, json.TenantID); err != nil {
+		return nil, err
+	}
+
 	var (
 		config map[string]any
 		err    error
@@ -108,6 +124,12 @@ func (s *service) UpdateSchedulerCluster(ctx context.Context, id uint, json type
 		}
 	}
 
+	// The configuration before updating is recorded as the base version,
+	// if the scheduler cluster is created before versioning.
+	if _, err := s.createConfigVersion(ctx, types.ConfigVersionClusterTypeScheduler, id, 0); err != nil {
+		return nil, err
+	}
+
 	schedulerCluster := models.SchedulerCluster{}
 	if err := s.db.WithContext(ctx).First(&schedulerCluster, id).Updates(models.SchedulerCluster{
 		Name:         json.Name,
@@ -115,6 +137,7 @@ func (s *service) UpdateSchedulerCluster(ctx context.Context, id uint, json type
 		Config:       config,
 		ClientConfig: clientConfig,
 		Scopes:       scopes,
+		TenantID:     json.TenantID,
 	}).Error; err != nil {
 		return nil, err
 	}
@@ -127,6 +150,10 @@ func (s *service) UpdateSchedulerCluster(ctx context.Context, id uint, json type
 		}
 	}
 
+	if _, err := s.createConfigVersion(ctx, types.ConfigVersionClusterTypeScheduler, schedulerCluster.ID, 0); err != nil {
+		return nil, err
+	}
+
 	if json.SeedPeerClusterID > 0 {
 		if err := s.AddSchedulerClusterToSeedPeerCluster(ctx, json.SeedPeerClusterID, schedulerCluster.ID); err != nil {
 			return nil, err
@@ -149,7 +176,8 @@ func (s *service) GetSchedulerClusters(ctx context.Context, q types.GetScheduler
 	var count int64
 	var schedulerClusters []models.SchedulerCluster
 	if err := s.db.WithContext(ctx).Scopes(models.Paginate(q.Page, q.PerPage)).Where(&models.SchedulerCluster{
-		Name: q.Name,
+		Name:     q.Name,
+		TenantID: q.TenantID,
 	}).Preload("SeedPeerClusters").Find(&schedulerClusters).Limit(-1).Offset(-1).Count(&count).Error; err != nil {
 		return nil, 0, err
 	}
@@ -174,3 +202,59 @@ func (s *service) AddSchedulerToSchedulerCluster(ctx context.Context, id, schedu
 
 	return nil
 }
+
+// GetSeedPeerSaturation aggregates the seed peer saturation reported by the schedulers of scheduler cluster.
+// Seed peers are shared by the schedulers, so the number of seed peers and the upload limit are the maximum
+// of reports, and the uploads, tasks and peers scheduled by each scheduler are summed up.
+func (s *service) GetSeedPeerSaturation(ctx context.Context, id uint) (*types.SeedPeerSaturation, error) {
+	if err := s.db.WithContext(ctx).First(&models.SchedulerCluster{}, id).Error; err != nil {
+		return nil, err
+	}
+
+	keys, err := s.rdb.Keys(ctx, pkgredis.MakeSeedPeerSaturationsKeyInManager(id)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	saturation := types.SeedPeerSaturation{}
+	for _, key := range keys {
+		data, err := s.rdb.Get(ctx, key).Bytes()
+		if err != nil {
+			logger.Warnf("get seed peer saturation %s failed: %s", key, err.Error())
+			continue
+		}
+
+		var report types.SeedPeerSaturation
+		if err := json.Unmarshal(data, &report); err != nil {
+			logger.Warnf("unmarshal seed peer saturation %s failed: %s", key, err.Error())
+			continue
+		}
+
+		saturation.SchedulerCount++
+		saturation.ActiveTaskCount += report.ActiveTaskCount
+		saturation.ConcurrentUploadCount += report.ConcurrentUploadCount
+		saturation.PeerCount += report.PeerCount
+		saturation.BackToSourcePeerCount += report.BackToSourcePeerCount
+		if report.SeedPeerCount > saturation.SeedPeerCount {
+			saturation.SeedPeerCount = report.SeedPeerCount
+		}
+
+		if report.ConcurrentUploadLimit > saturation.ConcurrentUploadLimit {
+			saturation.ConcurrentUploadLimit = report.ConcurrentUploadLimit
+		}
+
+		if report.UpdatedAt.After(saturation.UpdatedAt) {
+			saturation.UpdatedAt = report.UpdatedAt
+		}
+	}
+
+	if saturation.ConcurrentUploadLimit > 0 {
+		saturation.Saturation = float64(saturation.ConcurrentUploadCount) / float64(saturation.ConcurrentUploadLimit)
+	}
+
+	if saturation.PeerCount > 0 {
+		saturation.BackToSourceRate = float64(saturation.BackToSourcePeerCount) / float64(saturation.PeerCount)
+	}
+
+	return &saturation, nil
+}
@@ -0,0 +1,102 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package service
+
+import (
+	"context"
+
+	"d7y.io/dragonfly/v2/manager/models"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+func (s *service) CreateSearcherRule(ctx context.Context, json types.CreateSearcherRuleRequest) (*models.SearcherRule, error) {
+	schedulerCluster := models.SchedulerCluster{}
+	if err := s.db.WithContext(ctx).First(&schedulerCluster, json.SchedulerClusterID).Error; err != nil {
+		return nil, err
+	}
+
+	searcherRule := models.SearcherRule{
+		Name:               json.Name,
+		BIO:                json.BIO,
+		CIDRs:              json.CIDRs,
+		Labels:             stringMapToJSONMap(json.Labels),
+		Weight:             json.Weight,
+		SchedulerClusterID: json.SchedulerClusterID,
+	}
+
+	if err := s.db.WithContext(ctx).Create(&searcherRule).Error; err != nil {
+		return nil, err
+	}
+
+	return &searcherRule, nil
+}
+
+func (s *service) DestroySearcherRule(ctx context.Context, id uint) error {
+	searcherRule := models.SearcherRule{}
+	if err := s.db.WithContext(ctx).First(&searcherRule, id).Error; err != nil {
+		return err
+	}
+
+	if err := s.db.WithContext(ctx).Unscoped().Delete(&models.SearcherRule{}, id).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) UpdateSearcherRule(ctx context.Context, id uint, json types.UpdateSearcherRuleRequest) (*models.SearcherRule, error) {
+	if json.SchedulerClusterID > 0 {
+		schedulerCluster := models.SchedulerCluster{}
+		if err := s.db.WithContext(ctx).First(&schedulerCluster, json.SchedulerClusterID).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	searcherRule := models.SearcherRule{}
+	if err := s.db.WithContext(ctx).First(&searcherRule, id).Updates(models.SearcherRule{
+		BIO:                json.BIO,
+		CIDRs:              json.CIDRs,
+		Labels:             stringMapToJSONMap(json.Labels),
+		Weight:             json.Weight,
+		SchedulerClusterID: json.SchedulerClusterID,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	return &searcherRule, nil
+}
+
+func (s *service) GetSearcherRule(ctx context.Context, id uint) (*models.SearcherRule, error) {
+	searcherRule := models.SearcherRule{}
+	if err := s.db.WithContext(ctx).First(&searcherRule, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &searcherRule, nil
+}
+
+func (s *service) GetSearcherRules(ctx context.Context, q types.GetSearcherRulesQuery) ([]models.SearcherRule, int64, error) {
+	var count int64
+	var searcherRules []models.SearcherRule
+	if err := s.db.WithContext(ctx).Scopes(models.Paginate(q.Page, q.PerPage)).Where(&models.SearcherRule{
+		Name:               q.Name,
+		SchedulerClusterID: q.SchedulerClusterID,
+	}).Find(&searcherRules).Limit(-1).Offset(-1).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return searcherRules, count, nil
+}
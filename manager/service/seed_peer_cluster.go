@@ -26,21 +26,30 @@ import (
 )
 
 func (s *service) CreateSeedPeerCluster(ctx context.Context, json types.CreateSeedPeerClusterRequest) (*models.SeedPeerCluster, error) {
+	if _, err := s.findTenant(ctx, json.TenantID); err != nil {
+		return nil, err
+	}
+
 	config, err := structure.StructToMap(json.Config)
 	if err != nil {
 		return nil, err
 	}
 
 	seedPeerCluster := models.SeedPeerCluster{
-		Name:   json.Name,
-		BIO:    json.BIO,
-		Config: config,
+		Name:     json.Name,
+		BIO:      json.BIO,
+		Config:   config,
+		TenantID: json.TenantID,
 	}
 
 	if err := s.db.WithContext(ctx).Create(&seedPeerCluster).Error; err != nil {
 		return nil, err
 	}
 
+	if _, err := s.createConfigVersion(ctx, types.ConfigVersionClusterTypeSeedPeer, seedPeerCluster.ID, 0); err != nil {
+		return nil, err
+	}
+
 	return &seedPeerCluster, nil
 }
 
@@ -62,6 +71,10 @@ func (s *service) DestroySeedPeerCluster(ctx context.Context, id uint) error {
 }
 
 func (s *service) UpdateSeedPeerCluster(ctx context.Context, id uint, json types.UpdateSeedPeerClusterRequest) (*models.SeedPeerCluster, error) {
+	if _, err := s.findTenant(ctx, json.TenantID); err != nil {
+		return nil, err
+	}
+
 	var (
 		config map[string]any
 		err    error
@@ -73,15 +86,26 @@ func (s *service) UpdateSeedPeerCluster(ctx context.Context, id uint, json types
 		}
 	}
 
+	// The configuration before updating is recorded as the base version,
+	// if the seed peer cluster is created before versioning.
+	if _, err := s.createConfigVersion(ctx, types.ConfigVersionClusterTypeSeedPeer, id, 0); err != nil {
+		return nil, err
+	}
+
 	seedPeerCluster := models.SeedPeerCluster{}
 	if err := s.db.WithContext(ctx).First(&seedPeerCluster, id).Updates(models.SeedPeerCluster{
-		Name:   json.Name,
-		BIO:    json.BIO,
-		Config: config,
+		Name:     json.Name,
+		BIO:      json.BIO,
+		Config:   config,
+		TenantID: json.TenantID,
 	}).Error; err != nil {
 		return nil, err
 	}
 
+	if _, err := s.createConfigVersion(ctx, types.ConfigVersionClusterTypeSeedPeer, seedPeerCluster.ID, 0); err != nil {
+		return nil, err
+	}
+
 	return &seedPeerCluster, nil
 }
 
@@ -98,7 +122,8 @@ func (s *service) GetSeedPeerClusters(ctx context.Context, q types.GetSeedPeerCl
 	var count int64
 	var seedPeerClusters []models.SeedPeerCluster
 	if err := s.db.WithContext(ctx).Scopes(models.Paginate(q.Page, q.PerPage)).Where(&models.SeedPeerCluster{
-		Name: q.Name,
+		Name:     q.Name,
+		TenantID: q.TenantID,
 	}).Find(&seedPeerClusters).Limit(-1).Offset(-1).Count(&count).Error; err != nil {
 		return nil, 0, err
 	}
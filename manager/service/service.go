@@ -20,6 +20,7 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/casbin/casbin/v2"
 	"github.com/gin-gonic/gin"
@@ -32,7 +33,9 @@ import (
 	"d7y.io/dragonfly/v2/manager/models"
 	"d7y.io/dragonfly/v2/manager/permission/rbac"
 	"d7y.io/dragonfly/v2/manager/types"
+	"d7y.io/dragonfly/v2/manager/webhook"
 	"d7y.io/dragonfly/v2/pkg/objectstorage"
+	"d7y.io/dragonfly/v2/pkg/structure"
 )
 
 type Service interface {
@@ -46,6 +49,7 @@ type Service interface {
 	ResetPassword(context.Context, uint, types.ResetPasswordRequest) error
 	GetRolesForUser(context.Context, uint) ([]string, error)
 	AddRoleForUser(context.Context, types.AddRoleForUserParams) (bool, error)
+	AddOauthForUser(context.Context, types.AddOauthForUserParams, types.AddOauthForUserRequest) (*models.UserOauth, error)
 	DeleteRoleForUser(context.Context, types.DeleteRoleForUserParams) (bool, error)
 
 	CreateRole(context.Context, types.CreateRoleRequest) error
@@ -85,12 +89,16 @@ type Service interface {
 
 	GetPeers(context.Context) ([]string, error)
 
+	GetInventoryHosts(context.Context, types.GetInventoryHostsQuery) ([]types.InventoryHost, int64, error)
+	GetInventoryPeers(context.Context, types.GetInventoryPeersQuery) ([]types.InventoryPeer, int64, error)
+
 	CreateSchedulerCluster(context.Context, types.CreateSchedulerClusterRequest) (*models.SchedulerCluster, error)
 	DestroySchedulerCluster(context.Context, uint) error
 	UpdateSchedulerCluster(context.Context, uint, types.UpdateSchedulerClusterRequest) (*models.SchedulerCluster, error)
 	GetSchedulerCluster(context.Context, uint) (*models.SchedulerCluster, error)
 	GetSchedulerClusters(context.Context, types.GetSchedulerClustersQuery) ([]models.SchedulerCluster, int64, error)
 	AddSchedulerToSchedulerCluster(context.Context, uint, uint) error
+	GetSeedPeerSaturation(context.Context, uint) (*types.SeedPeerSaturation, error)
 
 	CreateScheduler(context.Context, types.CreateSchedulerRequest) (*models.Scheduler, error)
 	DestroyScheduler(context.Context, uint) error
@@ -110,6 +118,8 @@ type Service interface {
 	GetConfigs(context.Context, types.GetConfigsQuery) ([]models.Config, int64, error)
 
 	CreatePreheatJob(context.Context, types.CreatePreheatJobRequest) (*models.Job, error)
+	CreateBulkPreheatJob(context.Context, types.CreateBulkPreheatJobRequest) (*models.Job, error)
+	CreateGCDryRunJob(context.Context, types.CreateGCDryRunJobRequest) (*models.Job, error)
 	DestroyJob(context.Context, uint) error
 	UpdateJob(context.Context, uint, types.UpdateJobRequest) (*models.Job, error)
 	GetJob(context.Context, uint) (*models.Job, error)
@@ -129,6 +139,43 @@ type Service interface {
 	UpdateModel(context.Context, uint, types.UpdateModelRequest) (*models.Model, error)
 	GetModel(context.Context, uint) (*models.Model, error)
 	GetModels(context.Context, types.GetModelsQuery) ([]models.Model, int64, error)
+
+	CreateAudit(context.Context, *models.Audit) error
+	DestroyAuditsBefore(context.Context, time.Time) (int64, error)
+	GetAudit(context.Context, uint) (*models.Audit, error)
+	GetAudits(context.Context, types.GetAuditsQuery) ([]models.Audit, int64, error)
+
+	CreatePersonalAccessToken(context.Context, uint, types.CreatePersonalAccessTokenRequest) (*models.PersonalAccessToken, error)
+	DestroyPersonalAccessToken(context.Context, uint, uint) error
+	UpdatePersonalAccessToken(context.Context, uint, uint, types.UpdatePersonalAccessTokenRequest) (*models.PersonalAccessToken, error)
+	GetPersonalAccessToken(context.Context, uint) (*models.PersonalAccessToken, error)
+	GetPersonalAccessTokens(context.Context, types.GetPersonalAccessTokensQuery) ([]models.PersonalAccessToken, int64, error)
+	ValidatePersonalAccessToken(context.Context, string) (*models.PersonalAccessToken, error)
+
+	CreateSearcherRule(context.Context, types.CreateSearcherRuleRequest) (*models.SearcherRule, error)
+	DestroySearcherRule(context.Context, uint) error
+	UpdateSearcherRule(context.Context, uint, types.UpdateSearcherRuleRequest) (*models.SearcherRule, error)
+	GetSearcherRule(context.Context, uint) (*models.SearcherRule, error)
+	GetSearcherRules(context.Context, types.GetSearcherRulesQuery) ([]models.SearcherRule, int64, error)
+	GetAuditResourceState(context.Context, string, uint) (map[string]any, error)
+
+	GetConfigVersion(context.Context, string, uint, uint) (*models.ConfigVersion, error)
+	GetConfigVersions(context.Context, string, uint, types.GetConfigVersionsQuery) ([]models.ConfigVersion, int64, error)
+	DiffConfigVersions(context.Context, string, uint, types.DiffConfigVersionsQuery) ([]structure.Diff, error)
+	RollbackConfigVersion(context.Context, string, uint, uint) (*models.ConfigVersion, error)
+	GetConfigVersionReports(context.Context, string, uint) ([]types.ConfigVersionReport, error)
+
+	CreateWebhook(context.Context, types.CreateWebhookRequest) (*models.Webhook, error)
+	DestroyWebhook(context.Context, uint) error
+	UpdateWebhook(context.Context, uint, types.UpdateWebhookRequest) (*models.Webhook, error)
+	GetWebhook(context.Context, uint) (*models.Webhook, error)
+	GetWebhooks(context.Context, types.GetWebhooksQuery) ([]models.Webhook, int64, error)
+
+	CreateTenant(context.Context, types.CreateTenantRequest) (*models.Tenant, error)
+	DestroyTenant(context.Context, uint) error
+	UpdateTenant(context.Context, uint, types.UpdateTenantRequest) (*models.Tenant, error)
+	GetTenant(context.Context, uint) (*models.Tenant, error)
+	GetTenants(context.Context, types.GetTenantsQuery) ([]models.Tenant, int64, error)
 }
 
 type service struct {
@@ -136,17 +183,19 @@ type service struct {
 	rdb           redis.UniversalClient
 	cache         *cache.Cache
 	job           *job.Job
+	webhook       webhook.Webhook
 	enforcer      *casbin.Enforcer
 	objectStorage objectstorage.ObjectStorage
 }
 
 // NewREST returns a new REST instence
-func New(database *database.Database, cache *cache.Cache, job *job.Job, enforcer *casbin.Enforcer, objectStorage objectstorage.ObjectStorage) Service {
+func New(database *database.Database, cache *cache.Cache, job *job.Job, webhook webhook.Webhook, enforcer *casbin.Enforcer, objectStorage objectstorage.ObjectStorage) Service {
 	return &service{
 		db:            database.DB,
 		rdb:           database.RDB,
 		cache:         cache,
 		job:           job,
+		webhook:       webhook,
 		enforcer:      enforcer,
 		objectStorage: objectStorage,
 	}
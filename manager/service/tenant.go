@@ -0,0 +1,247 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	machineryv1tasks "github.com/RichardKnop/machinery/v1/tasks"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	internaljob "d7y.io/dragonfly/v2/internal/job"
+	"d7y.io/dragonfly/v2/manager/models"
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+const (
+	// tenantQuotaWindow is the window of counting the running preheat jobs of tenant,
+	// the jobs whose polling ends before finishing are not counted after it.
+	tenantQuotaWindow = 24 * time.Hour
+)
+
+var (
+	// ErrTenantQuotaExceeded is the error of the tenant exceeding its quota.
+	ErrTenantQuotaExceeded = errors.New("tenant quota exceeded")
+)
+
+func (s *service) CreateTenant(ctx context.Context, json types.CreateTenantRequest) (*models.Tenant, error) {
+	tenant := models.Tenant{
+		Name:                  json.Name,
+		BIO:                   json.BIO,
+		State:                 models.TenantStateActive,
+		PreheatJobQuota:       json.PreheatJobQuota,
+		PreheatBandwidthQuota: json.PreheatBandwidthQuota,
+	}
+
+	if err := s.db.WithContext(ctx).Create(&tenant).Error; err != nil {
+		return nil, err
+	}
+
+	return &tenant, nil
+}
+
+func (s *service) DestroyTenant(ctx context.Context, id uint) error {
+	tenant := models.Tenant{}
+	if err := s.db.WithContext(ctx).Preload("SchedulerClusters").Preload("SeedPeerClusters").First(&tenant, id).Error; err != nil {
+		return err
+	}
+
+	if len(tenant.SchedulerClusters) != 0 || len(tenant.SeedPeerClusters) != 0 {
+		return errors.New("tenant exists clusters")
+	}
+
+	if err := s.db.WithContext(ctx).Unscoped().Delete(&models.Tenant{}, id).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) UpdateTenant(ctx context.Context, id uint, json types.UpdateTenantRequest) (*models.Tenant, error) {
+	tenant := models.Tenant{}
+	if err := s.db.WithContext(ctx).First(&tenant, id).Updates(models.Tenant{
+		BIO:                   json.BIO,
+		State:                 json.State,
+		PreheatJobQuota:       json.PreheatJobQuota,
+		PreheatBandwidthQuota: json.PreheatBandwidthQuota,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	return &tenant, nil
+}
+
+func (s *service) GetTenant(ctx context.Context, id uint) (*models.Tenant, error) {
+	tenant := models.Tenant{}
+	if err := s.db.WithContext(ctx).Preload("SchedulerClusters").Preload("SeedPeerClusters").First(&tenant, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &tenant, nil
+}
+
+func (s *service) GetTenants(ctx context.Context, q types.GetTenantsQuery) ([]models.Tenant, int64, error) {
+	var count int64
+	var tenants []models.Tenant
+	if err := s.db.WithContext(ctx).Scopes(models.Paginate(q.Page, q.PerPage)).Where(&models.Tenant{
+		Name:  q.Name,
+		State: q.State,
+	}).Find(&tenants).Limit(-1).Offset(-1).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return tenants, count, nil
+}
+
+// findTenant returns the tenant by id, zero id means no tenant.
+func (s *service) findTenant(ctx context.Context, id uint) (*models.Tenant, error) {
+	if id == 0 {
+		return nil, nil
+	}
+
+	tenant := models.Tenant{}
+	if err := s.db.WithContext(ctx).Preload("SchedulerClusters").First(&tenant, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &tenant, nil
+}
+
+// checkTenantSchedulerClusters checks the scheduler clusters belong to the tenant,
+// zero tenant id means no tenant and any scheduler clusters are allowed.
+func checkTenantSchedulerClusters(tenantID uint, schedulerClusters []models.SchedulerCluster) error {
+	if tenantID == 0 {
+		return nil
+	}
+
+	for _, schedulerCluster := range schedulerClusters {
+		if schedulerCluster.TenantID != tenantID {
+			return ErrPermissionDenied
+		}
+	}
+
+	return nil
+}
+
+// applyTenantPreheatQuota restricts the preheat job to the scheduler clusters of tenant and enforces the quotas of tenant,
+// it returns the scheduler cluster ids and the max bandwidth of the job. The max bandwidth defaults to the remaining
+// bandwidth quota of tenant. Zero tenant id means the job is not scoped to any tenant, it is restricted to the shared
+// scheduler clusters. The tenant is locked in tx, so the job must be created in tx to be counted by the next jobs.
+func (s *service) applyTenantPreheatQuota(ctx context.Context, tx *gorm.DB, tenantID uint, schedulerClusterIDs []uint, maxBandwidth int64) ([]uint, int64, error) {
+	if tenantID == 0 {
+		schedulerClusterIDs, err := restrictSharedSchedulerClusters(ctx, tx, schedulerClusterIDs)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		return schedulerClusterIDs, maxBandwidth, nil
+	}
+
+	tenant := models.Tenant{}
+	if err := tx.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Preload("SchedulerClusters").First(&tenant, tenantID).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if tenant.State != models.TenantStateActive {
+		return nil, 0, ErrPermissionDenied
+	}
+
+	if len(tenant.SchedulerClusters) == 0 {
+		return nil, 0, errors.New("tenant has no scheduler clusters")
+	}
+
+	allowed := make(map[uint]struct{}, len(tenant.SchedulerClusters))
+	for _, schedulerCluster := range tenant.SchedulerClusters {
+		allowed[schedulerCluster.ID] = struct{}{}
+	}
+
+	if len(schedulerClusterIDs) == 0 {
+		for id := range allowed {
+			schedulerClusterIDs = append(schedulerClusterIDs, id)
+		}
+	}
+
+	for _, id := range schedulerClusterIDs {
+		if _, ok := allowed[id]; !ok {
+			return nil, 0, ErrPermissionDenied
+		}
+	}
+
+	if tenant.PreheatJobQuota == 0 && tenant.PreheatBandwidthQuota == 0 {
+		return schedulerClusterIDs, maxBandwidth, nil
+	}
+
+	var jobs []models.Job
+	if err := tx.WithContext(ctx).Where("tenant_id = ? AND type = ? AND state NOT IN ? AND created_at > ?",
+		tenant.ID, internaljob.PreheatJob, []string{machineryv1tasks.StateSuccess, machineryv1tasks.StateFailure},
+		time.Now().Add(-tenantQuotaWindow)).Find(&jobs).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if tenant.PreheatJobQuota > 0 && len(jobs) >= tenant.PreheatJobQuota {
+		return nil, 0, ErrTenantQuotaExceeded
+	}
+
+	if tenant.PreheatBandwidthQuota > 0 {
+		remaining := tenant.PreheatBandwidthQuota
+		for _, job := range jobs {
+			if bandwidth, ok := job.Args["max_bandwidth"].(float64); ok {
+				remaining -= int64(bandwidth)
+			}
+		}
+
+		if remaining <= 0 || maxBandwidth > remaining {
+			return nil, 0, ErrTenantQuotaExceeded
+		}
+
+		if maxBandwidth == 0 {
+			maxBandwidth = remaining
+		}
+	}
+
+	return schedulerClusterIDs, maxBandwidth, nil
+}
+
+// restrictSharedSchedulerClusters restricts the job not scoped to any tenant to the shared scheduler clusters,
+// the job runs in all of the shared scheduler clusters if no scheduler clusters are given.
+func restrictSharedSchedulerClusters(ctx context.Context, tx *gorm.DB, schedulerClusterIDs []uint) ([]uint, error) {
+	if len(schedulerClusterIDs) != 0 {
+		var count int64
+		if err := tx.WithContext(ctx).Model(&models.SchedulerCluster{}).Where("id IN ? AND tenant_id != ?", schedulerClusterIDs, 0).Count(&count).Error; err != nil {
+			return nil, err
+		}
+
+		if count > 0 {
+			return nil, ErrPermissionDenied
+		}
+
+		return schedulerClusterIDs, nil
+	}
+
+	if err := tx.WithContext(ctx).Model(&models.SchedulerCluster{}).Where("tenant_id = ?", 0).Pluck("id", &schedulerClusterIDs).Error; err != nil {
+		return nil, err
+	}
+
+	if len(schedulerClusterIDs) == 0 {
+		return nil, errors.New("shared scheduler clusters not found")
+	}
+
+	return schedulerClusterIDs, nil
+}
@@ -21,9 +21,8 @@ import (
 	"errors"
 	"fmt"
 
-	"github.com/VividCortex/mysqlerr"
-	"github.com/go-sql-driver/mysql"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 
 	manageroauth "d7y.io/dragonfly/v2/manager/auth/oauth"
 	"d7y.io/dragonfly/v2/manager/models"
@@ -143,7 +142,7 @@ func (s *service) OauthSignin(ctx context.Context, name string) (string, error)
 		return "", err
 	}
 
-	o, err := manageroauth.New(oauth.Name, oauth.ClientID, oauth.ClientSecret, oauth.RedirectURL)
+	o, err := newOauth(oauth)
 	if err != nil {
 		return "", err
 	}
@@ -157,7 +156,7 @@ func (s *service) OauthSigninCallback(ctx context.Context, name, code string) (*
 		return nil, err
 	}
 
-	o, err := manageroauth.New(oauth.Name, oauth.ClientID, oauth.ClientSecret, oauth.RedirectURL)
+	o, err := newOauth(oauth)
 	if err != nil {
 		return nil, err
 	}
@@ -172,28 +171,125 @@ func (s *service) OauthSigninCallback(ctx context.Context, name, code string) (*
 		return nil, err
 	}
 
-	user := models.User{
-		Name:   oauthUser.Name,
-		Email:  oauthUser.Email,
-		Avatar: oauthUser.Avatar,
-		State:  models.UserStateEnabled,
+	if oauthUser.ID == "" {
+		return nil, errors.New("oauth user misses subject")
 	}
-	if err := s.db.WithContext(ctx).Create(&user).Error; err != nil {
-		var merr *mysql.MySQLError
-		if errors.As(err, &merr) && merr.Number == mysqlerr.ER_DUP_ENTRY {
-			return &user, nil
+
+	// The user is linked by the subject in the provider, never by name or email,
+	// otherwise the provider could sign in the local user with the same email.
+	var user models.User
+	userOauth := models.UserOauth{}
+	if err := s.db.WithContext(ctx).First(&userOauth, models.UserOauth{OauthID: oauth.ID, Subject: oauthUser.ID}).Error; err == nil {
+		// The user has signed in before.
+		if err := s.db.WithContext(ctx).First(&user, userOauth.UserID).Error; err != nil {
+			return nil, err
+		}
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	} else if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		user = models.User{
+			Name:   oauthUser.Name,
+			Email:  oauthUser.Email,
+			Avatar: oauthUser.Avatar,
+			State:  models.UserStateEnabled,
+		}
+		if err := tx.Create(&user).Error; err != nil {
+			// The user with the same name or email is not linked to the subject, e.g. the user
+			// signed in before the users are linked by subject, it is linked by the administrator.
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return fmt.Errorf("user with the same name or email already exists, it needs to be linked to subject %s of oauth %s by the administrator", oauthUser.ID, oauth.Name)
+			}
+
+			return err
 		}
 
+		if err := tx.Create(&models.UserOauth{UserID: user.ID, OauthID: oauth.ID, Subject: oauthUser.ID}).Error; err != nil {
+			return err
+		}
+
+		if _, err := s.enforcer.AddRoleForUser(fmt.Sprint(user.ID), rbac.GuestRole); err != nil {
+			return err
+		}
+
+		return nil
+	}); err != nil {
 		return nil, err
 	}
 
-	if _, err := s.enforcer.AddRoleForUser(fmt.Sprint(user.ID), rbac.GuestRole); err != nil {
+	if user.State != models.UserStateEnabled {
+		return nil, errors.New("user is disabled")
+	}
+
+	if err := s.syncRolesForUser(user.ID, oauthUser.Groups, oauth.GroupRoles); err != nil {
 		return nil, err
 	}
 
 	return &user, nil
 }
 
+// AddOauthForUser links the user to its subject in the oauth provider.
+func (s *service) AddOauthForUser(ctx context.Context, params types.AddOauthForUserParams, json types.AddOauthForUserRequest) (*models.UserOauth, error) {
+	user := models.User{}
+	if err := s.db.WithContext(ctx).First(&user, params.ID).Error; err != nil {
+		return nil, err
+	}
+
+	oauth := models.Oauth{}
+	if err := s.db.WithContext(ctx).First(&oauth, params.OauthID).Error; err != nil {
+		return nil, err
+	}
+
+	userOauth := models.UserOauth{
+		UserID:  user.ID,
+		OauthID: oauth.ID,
+		Subject: json.Subject,
+	}
+	if err := s.db.WithContext(ctx).Create(&userOauth).Error; err != nil {
+		return nil, err
+	}
+
+	return &userOauth, nil
+}
+
+// newOauth returns the oauth by the oauth config.
+func newOauth(oauth models.Oauth) (manageroauth.Oauth, error) {
+	return manageroauth.New(oauth.Name, oauth.ClientID, oauth.ClientSecret, oauth.RedirectURL,
+		manageroauth.WithIssuerURL(oauth.IssuerURL), manageroauth.WithGroupsClaim(oauth.GroupsClaim))
+}
+
+// syncRolesForUser syncs the roles mapped from the groups of user in the identity provider,
+// the user is granted the roles of its groups and revoked the mapped roles of other groups.
+// The roles not in mapping, e.g. granted by api, are kept.
+func (s *service) syncRolesForUser(id uint, groups []string, groupRoles models.JSONMap) error {
+	granted := map[string]struct{}{}
+	for _, group := range groups {
+		if role, ok := groupRoles[group].(string); ok && role != "" {
+			granted[role] = struct{}{}
+		}
+	}
+
+	for _, rawRole := range groupRoles {
+		role, ok := rawRole.(string)
+		if !ok || role == "" {
+			continue
+		}
+
+		if _, ok := granted[role]; ok {
+			if _, err := s.enforcer.AddRoleForUser(fmt.Sprint(id), role); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if _, err := s.enforcer.DeleteRoleForUser(fmt.Sprint(id), role); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (s *service) GetRolesForUser(ctx context.Context, id uint) ([]string, error) {
 	return s.enforcer.GetRolesForUser(fmt.Sprint(id))
 }
@@ -0,0 +1,170 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	machineryv1tasks "github.com/RichardKnop/machinery/v1/tasks"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/manager/models"
+	"d7y.io/dragonfly/v2/manager/types"
+	"d7y.io/dragonfly/v2/pkg/slices"
+)
+
+func (s *service) CreateWebhook(ctx context.Context, json types.CreateWebhookRequest) (*models.Webhook, error) {
+	webhook := models.Webhook{
+		Name:     json.Name,
+		BIO:      json.BIO,
+		URL:      json.URL,
+		Secret:   json.Secret,
+		Events:   json.Events,
+		JobTypes: json.JobTypes,
+		State:    models.WebhookStateActive,
+		UserID:   json.UserID,
+	}
+
+	if err := s.db.WithContext(ctx).Create(&webhook).Error; err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+func (s *service) DestroyWebhook(ctx context.Context, id uint) error {
+	webhook := models.Webhook{}
+	if err := s.db.WithContext(ctx).First(&webhook, id).Error; err != nil {
+		return err
+	}
+
+	if err := s.db.WithContext(ctx).Unscoped().Delete(&models.Webhook{}, id).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *service) UpdateWebhook(ctx context.Context, id uint, json types.UpdateWebhookRequest) (*models.Webhook, error) {
+	webhook := models.Webhook{}
+	if err := s.db.WithContext(ctx).First(&webhook, id).Updates(models.Webhook{
+		BIO:      json.BIO,
+		URL:      json.URL,
+		Secret:   json.Secret,
+		Events:   json.Events,
+		JobTypes: json.JobTypes,
+		State:    json.State,
+		UserID:   json.UserID,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+func (s *service) GetWebhook(ctx context.Context, id uint) (*models.Webhook, error) {
+	webhook := models.Webhook{}
+	if err := s.db.WithContext(ctx).First(&webhook, id).Error; err != nil {
+		return nil, err
+	}
+
+	return &webhook, nil
+}
+
+func (s *service) GetWebhooks(ctx context.Context, q types.GetWebhooksQuery) ([]models.Webhook, int64, error) {
+	var count int64
+	var webhooks []models.Webhook
+	if err := s.db.WithContext(ctx).Scopes(models.Paginate(q.Page, q.PerPage)).Where(&models.Webhook{
+		Name:  q.Name,
+		State: q.State,
+	}).Find(&webhooks).Limit(-1).Offset(-1).Count(&count).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return webhooks, count, nil
+}
+
+// notifyJobWebhooks sends the final state of job to the active webhooks subscribing the event and the job type,
+// the webhooks are sent concurrently, so a slow receiver does not delay the others.
+func (s *service) notifyJobWebhooks(ctx context.Context, id uint, groupID string) {
+	log := logger.WithGroupAndJobID(groupID, fmt.Sprint(id))
+	job := models.Job{}
+	if err := s.db.WithContext(ctx).Preload("SchedulerClusters").First(&job, id).Error; err != nil {
+		log.Errorf("notify webhooks failed: %s", err.Error())
+		return
+	}
+
+	var event string
+	switch job.State {
+	case machineryv1tasks.StateSuccess:
+		event = models.WebhookEventJobSucceeded
+	case machineryv1tasks.StateFailure:
+		event = models.WebhookEventJobFailed
+	default:
+		return
+	}
+
+	var webhooks []models.Webhook
+	if err := s.db.WithContext(ctx).Find(&webhooks, &models.Webhook{State: models.WebhookStateActive}).Error; err != nil {
+		log.Errorf("notify webhooks failed: %s", err.Error())
+		return
+	}
+
+	var schedulerClusterIDs []uint
+	for _, schedulerCluster := range job.SchedulerClusters {
+		schedulerClusterIDs = append(schedulerClusterIDs, schedulerCluster.ID)
+	}
+
+	payload := types.WebhookJobPayload{
+		Event: event,
+		Job: types.WebhookJob{
+			ID:                  job.ID,
+			TaskID:              job.TaskID,
+			BIO:                 job.BIO,
+			Type:                job.Type,
+			State:               job.State,
+			Args:                job.Args,
+			Result:              job.Result,
+			UserID:              job.UserID,
+			SchedulerClusterIDs: schedulerClusterIDs,
+			CreatedAt:           job.CreatedAt,
+			UpdatedAt:           job.UpdatedAt,
+		},
+		CreatedAt: time.Now(),
+	}
+
+	for _, webhook := range webhooks {
+		if len(webhook.Events) > 0 && !slices.Contains(webhook.Events, event) {
+			continue
+		}
+
+		if len(webhook.JobTypes) > 0 && !slices.Contains(webhook.JobTypes, job.Type) {
+			continue
+		}
+
+		go func(webhook models.Webhook) {
+			if err := s.webhook.Send(ctx, webhook.URL, webhook.Secret, event, payload); err != nil {
+				log.Errorf("send webhook %s failed: %s", webhook.Name, err.Error())
+				return
+			}
+
+			log.Infof("send webhook %s succeeded", webhook.Name)
+		}(webhook)
+	}
+}
@@ -0,0 +1,84 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"time"
+
+	"d7y.io/dragonfly/v2/pkg/digest"
+)
+
+const (
+	// ConfigVersionClusterTypeScheduler is the cluster type of scheduler cluster.
+	ConfigVersionClusterTypeScheduler = "scheduler"
+
+	// ConfigVersionClusterTypeSeedPeer is the cluster type of seed peer cluster.
+	ConfigVersionClusterTypeSeedPeer = "seed_peer"
+)
+
+type ConfigVersionsParams struct {
+	ID uint `uri:"id" binding:"required"`
+}
+
+type ConfigVersionParams struct {
+	ID      uint `uri:"id" binding:"required"`
+	Version uint `uri:"version" binding:"required"`
+}
+
+type GetConfigVersionsQuery struct {
+	Page    int `form:"page" binding:"omitempty,gte=1"`
+	PerPage int `form:"per_page" binding:"omitempty,gte=1,lte=50"`
+}
+
+type DiffConfigVersionsQuery struct {
+	From uint `form:"from" binding:"required"`
+	To   uint `form:"to" binding:"required"`
+}
+
+// ConfigVersionReport is the configuration reported by consumer of cluster,
+// the consumer reports the digest of configuration it is running.
+type ConfigVersionReport struct {
+	// Hostname is the hostname of consumer.
+	Hostname string `json:"hostname"`
+
+	// IP is the ip of consumer.
+	IP string `json:"ip"`
+
+	// Digest is the digest of configuration running in consumer.
+	Digest string `json:"digest"`
+
+	// Version is the version of configuration running in consumer, it is zero if the digest is unknown.
+	Version uint `json:"version"`
+
+	// Drifted is whether the configuration running in consumer differs from the current configuration of cluster.
+	Drifted bool `json:"drifted"`
+
+	// UpdatedAt is the time of reporting.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// MakeSchedulerClusterConfigDigest makes the digest of scheduler cluster configuration
+// by the config, client config and scopes delivered to schedulers.
+func MakeSchedulerClusterConfigDigest(config, clientConfig, scopes []byte) string {
+	return digest.SHA256FromStrings(string(config), string(clientConfig), string(scopes))
+}
+
+// MakeSeedPeerClusterConfigDigest makes the digest of seed peer cluster configuration
+// by the config delivered to consumers.
+func MakeSeedPeerClusterConfigDigest(config []byte) string {
+	return digest.SHA256FromStrings(string(config))
+}
@@ -0,0 +1,105 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import "time"
+
+const (
+	// InventoryHealthHealthy is the health of host or peer which works well.
+	InventoryHealthHealthy = "healthy"
+
+	// InventoryHealthUnhealthy is the health of host which stops announcing,
+	// or peer which fails or stalls in downloading.
+	InventoryHealthUnhealthy = "unhealthy"
+)
+
+// Inventory is the active hosts and peers reported by scheduler.
+type Inventory struct {
+	// SchedulerClusterID is the id of scheduler cluster.
+	SchedulerClusterID uint `json:"scheduler_cluster_id"`
+
+	// Hostname is the hostname of scheduler.
+	Hostname string `json:"hostname"`
+
+	// IP is the ip of scheduler.
+	IP string `json:"ip"`
+
+	// Hosts is the active hosts of scheduler.
+	Hosts []InventoryHost `json:"hosts"`
+
+	// Peers is the active peers of scheduler.
+	Peers []InventoryPeer `json:"peers"`
+
+	// UpdatedAt is the time of reporting.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type InventoryHost struct {
+	ID                    string    `json:"id"`
+	Type                  string    `json:"type"`
+	Hostname              string    `json:"hostname"`
+	IP                    string    `json:"ip"`
+	Port                  int32     `json:"port"`
+	DownloadPort          int32     `json:"download_port"`
+	IDC                   string    `json:"idc"`
+	Location              string    `json:"location"`
+	PeerCount             int32     `json:"peer_count"`
+	ConcurrentUploadCount int32     `json:"concurrent_upload_count"`
+	UploadCount           int64     `json:"upload_count"`
+	UploadFailedCount     int64     `json:"upload_failed_count"`
+	Health                string    `json:"health"`
+	SchedulerClusterID    uint      `json:"scheduler_cluster_id"`
+	CreatedAt             time.Time `json:"created_at"`
+	UpdatedAt             time.Time `json:"updated_at"`
+}
+
+type InventoryPeer struct {
+	ID                 string    `json:"id"`
+	TaskID             string    `json:"task_id"`
+	HostID             string    `json:"host_id"`
+	Hostname           string    `json:"hostname"`
+	IP                 string    `json:"ip"`
+	IDC                string    `json:"idc"`
+	State              string    `json:"state"`
+	FinishedPieceCount uint      `json:"finished_piece_count"`
+	Health             string    `json:"health"`
+	SchedulerClusterID uint      `json:"scheduler_cluster_id"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+type GetInventoryHostsQuery struct {
+	SchedulerClusterID uint   `form:"scheduler_cluster_id" binding:"omitempty"`
+	TenantID           uint   `form:"tenant_id" binding:"omitempty"`
+	IDC                string `form:"idc" binding:"omitempty"`
+	Type               string `form:"type" binding:"omitempty,oneof=normal super strong weak"`
+	Health             string `form:"health" binding:"omitempty,oneof=healthy unhealthy"`
+	Page               int    `form:"page" binding:"omitempty,gte=1"`
+	PerPage            int    `form:"per_page" binding:"omitempty,gte=1,lte=50"`
+}
+
+type GetInventoryPeersQuery struct {
+	SchedulerClusterID uint   `form:"scheduler_cluster_id" binding:"omitempty"`
+	TenantID           uint   `form:"tenant_id" binding:"omitempty"`
+	IDC                string `form:"idc" binding:"omitempty"`
+	TaskID             string `form:"task_id" binding:"omitempty"`
+	HostID             string `form:"host_id" binding:"omitempty"`
+	State              string `form:"state" binding:"omitempty,oneof=Pending ReceivedEmpty ReceivedTiny ReceivedSmall ReceivedNormal Running BackToSource Succeeded Failed Leave"`
+	Health             string `form:"health" binding:"omitempty,oneof=healthy unhealthy"`
+	Page               int    `form:"page" binding:"omitempty,gte=1"`
+	PerPage            int    `form:"per_page" binding:"omitempty,gte=1,lte=50"`
+}
@@ -36,11 +36,12 @@ type JobParams struct {
 }
 
 type GetJobsQuery struct {
-	Type    string `form:"type" binding:"omitempty"`
-	State   string `form:"state" binding:"omitempty,oneof=PENDING RECEIVED STARTED RETRY SUCCESS FAILURE"`
-	UserID  uint   `form:"user_id" binding:"omitempty"`
-	Page    int    `form:"page" binding:"omitempty,gte=1"`
-	PerPage int    `form:"per_page" binding:"omitempty,gte=1,lte=50"`
+	Type     string `form:"type" binding:"omitempty"`
+	State    string `form:"state" binding:"omitempty,oneof=PENDING RECEIVED STARTED RETRY SUCCESS FAILURE"`
+	UserID   uint   `form:"user_id" binding:"omitempty"`
+	TenantID uint   `form:"tenant_id" binding:"omitempty"`
+	Page     int    `form:"page" binding:"omitempty,gte=1"`
+	PerPage  int    `form:"per_page" binding:"omitempty,gte=1,lte=50"`
 }
 
 type CreatePreheatJobRequest struct {
@@ -49,13 +50,43 @@ type CreatePreheatJobRequest struct {
 	Args                PreheatArgs    `json:"args" binding:"omitempty"`
 	Result              map[string]any `json:"result" binding:"omitempty"`
 	UserID              uint           `json:"user_id" binding:"omitempty"`
+	TenantID            uint           `json:"tenant_id" binding:"omitempty"`
 	SchedulerClusterIDs []uint         `json:"scheduler_cluster_ids" binding:"omitempty"`
 }
 
 type PreheatArgs struct {
-	Type    string            `json:"type" binding:"required,oneof=image file"`
-	URL     string            `json:"url" binding:"required"`
-	Tag     string            `json:"tag" binding:"omitempty"`
-	Filter  string            `json:"filter" binding:"omitempty"`
-	Headers map[string]string `json:"headers" binding:"omitempty"`
+	Type         string            `json:"type" binding:"required,oneof=image file"`
+	URL          string            `json:"url" binding:"required"`
+	Tag          string            `json:"tag" binding:"omitempty"`
+	Filter       string            `json:"filter" binding:"omitempty"`
+	Headers      map[string]string `json:"headers" binding:"omitempty"`
+	TimeWindow   string            `json:"time_window" binding:"omitempty"`
+	MaxBandwidth int64             `json:"max_bandwidth" binding:"omitempty,gte=0"`
+}
+
+type CreateBulkPreheatJobRequest struct {
+	BIO                 string          `json:"bio" binding:"omitempty"`
+	Args                BulkPreheatArgs `json:"args" binding:"required"`
+	UserID              uint            `json:"user_id" binding:"omitempty"`
+	TenantID            uint            `json:"tenant_id" binding:"omitempty"`
+	SchedulerClusterIDs []uint          `json:"scheduler_cluster_ids" binding:"omitempty"`
+}
+
+type BulkPreheatArgs struct {
+	Repository     string            `json:"repository" binding:"required,url"`
+	TagPattern     string            `json:"tag_pattern" binding:"required"`
+	TagPatternType string            `json:"tag_pattern_type" binding:"omitempty,oneof=glob regex"`
+	Tag            string            `json:"tag" binding:"omitempty"`
+	Filter         string            `json:"filter" binding:"omitempty"`
+	Headers        map[string]string `json:"headers" binding:"omitempty"`
+	TimeWindow     string            `json:"time_window" binding:"omitempty"`
+	MaxBandwidth   int64             `json:"max_bandwidth" binding:"omitempty,gte=0"`
+}
+
+type CreateGCDryRunJobRequest struct {
+	BIO                 string                   `json:"bio" binding:"omitempty"`
+	Type                string                   `json:"type" binding:"required"`
+	Args                SchedulerClusterGCConfig `json:"args" binding:"omitempty"`
+	UserID              uint                     `json:"user_id" binding:"omitempty"`
+	SchedulerClusterIDs []uint                   `json:"scheduler_cluster_ids" binding:"omitempty"`
 }
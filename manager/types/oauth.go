@@ -21,24 +21,30 @@ type OauthParams struct {
 }
 
 type CreateOauthRequest struct {
-	Name         string `json:"name" binding:"required,oneof=github google"`
-	BIO          string `json:"bio" binding:"omitempty"`
-	ClientID     string `json:"client_id" binding:"required"`
-	ClientSecret string `json:"client_secret" binding:"required"`
-	RedirectURL  string `json:"redirect_url" binding:"omitempty,url"`
+	Name         string            `json:"name" binding:"required,oneof=github google oidc"`
+	BIO          string            `json:"bio" binding:"omitempty"`
+	ClientID     string            `json:"client_id" binding:"required"`
+	ClientSecret string            `json:"client_secret" binding:"required"`
+	RedirectURL  string            `json:"redirect_url" binding:"omitempty,url"`
+	IssuerURL    string            `json:"issuer_url" binding:"required_if=Name oidc,omitempty,url"`
+	GroupsClaim  string            `json:"groups_claim" binding:"omitempty"`
+	GroupRoles   map[string]string `json:"group_roles" binding:"omitempty"`
 }
 
 type UpdateOauthRequest struct {
-	Name         string `json:"name" binding:"omitempty,oneof=github google"`
-	BIO          string `json:"bio" binding:"omitempty"`
-	ClientID     string `json:"client_id" binding:"omitempty"`
-	ClientSecret string `json:"client_secret" binding:"omitempty"`
-	RedirectURL  string `json:"redirect_url" binding:"omitempty,url"`
+	Name         string            `json:"name" binding:"omitempty,oneof=github google oidc"`
+	BIO          string            `json:"bio" binding:"omitempty"`
+	ClientID     string            `json:"client_id" binding:"omitempty"`
+	ClientSecret string            `json:"client_secret" binding:"omitempty"`
+	RedirectURL  string            `json:"redirect_url" binding:"omitempty,url"`
+	IssuerURL    string            `json:"issuer_url" binding:"omitempty,url"`
+	GroupsClaim  string            `json:"groups_claim" binding:"omitempty"`
+	GroupRoles   map[string]string `json:"group_roles" binding:"omitempty"`
 }
 
 type GetOauthsQuery struct {
 	Page     int    `form:"page" binding:"omitempty,gte=1"`
 	PerPage  int    `form:"per_page" binding:"omitempty,gte=1,lte=50"`
-	Name     string `form:"name" binding:"omitempty,oneof=github google"`
+	Name     string `form:"name" binding:"omitempty,oneof=github google oidc"`
 	ClientID string `form:"client_id" binding:"omitempty"`
 }
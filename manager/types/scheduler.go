@@ -24,6 +24,40 @@ const (
 	SchedulerFeaturePreheat = "preheat"
 )
 
+const (
+	// SchedulerCPUCountMetadataKey is the grpc metadata key of the logical cpu count of scheduler host,
+	// the host stats are sent as grpc metadata of UpdateScheduler because the request has no fields of them.
+	SchedulerCPUCountMetadataKey = "d7y-scheduler-cpu-count"
+
+	// SchedulerMemoryTotalMetadataKey is the grpc metadata key of the total memory in bytes of scheduler host.
+	SchedulerMemoryTotalMetadataKey = "d7y-scheduler-memory-total"
+
+	// SchedulerDiskFreeMetadataKey is the grpc metadata key of the free disk in bytes of scheduler storage volume.
+	SchedulerDiskFreeMetadataKey = "d7y-scheduler-disk-free"
+
+	// SchedulerAdvertiseIPv4MetadataKey is the grpc metadata key of the ipv4 advertise ip of dual-stack scheduler.
+	SchedulerAdvertiseIPv4MetadataKey = "d7y-scheduler-advertise-ipv4"
+
+	// SchedulerAdvertiseIPv6MetadataKey is the grpc metadata key of the ipv6 advertise ip of dual-stack scheduler.
+	SchedulerAdvertiseIPv6MetadataKey = "d7y-scheduler-advertise-ipv6"
+
+	// SchedulerTrainDownloadBytesMetadataKey is the grpc metadata key of the download bytes sent in the last training cycle.
+	SchedulerTrainDownloadBytesMetadataKey = "d7y-scheduler-train-download-bytes"
+
+	// SchedulerTrainNetworkTopologyBytesMetadataKey is the grpc metadata key of the network topology bytes sent in the last training cycle.
+	SchedulerTrainNetworkTopologyBytesMetadataKey = "d7y-scheduler-train-network-topology-bytes"
+
+	// SchedulerTrainBudgetLimitedMetadataKey is the grpc metadata key of whether the last training cycle is limited by budget.
+	SchedulerTrainBudgetLimitedMetadataKey = "d7y-scheduler-train-budget-limited"
+
+	// SchedulerTrainDownloadSucceededMetadataKey is the grpc metadata key of whether download is uploaded in the last training cycle.
+	SchedulerTrainDownloadSucceededMetadataKey = "d7y-scheduler-train-download-succeeded"
+
+	// SchedulerTrainNetworkTopologySucceededMetadataKey is the grpc metadata key of whether network topology is uploaded
+	// in the last training cycle.
+	SchedulerTrainNetworkTopologySucceededMetadataKey = "d7y-scheduler-train-network-topology-succeeded"
+)
+
 var (
 	// DefaultSchedulerFeatures is the default features of scheduler.
 	DefaultSchedulerFeatures = []string{SchedulerFeatureSchedule, SchedulerFeaturePreheat}
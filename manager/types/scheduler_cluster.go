@@ -16,6 +16,8 @@
 
 package types
 
+import "time"
+
 type SchedulerClusterParams struct {
 	ID uint `uri:"id" binding:"required"`
 }
@@ -33,6 +35,7 @@ type CreateSchedulerClusterRequest struct {
 	Scopes            *SchedulerClusterScopes       `json:"scopes" binding:"omitempty"`
 	IsDefault         bool                          `json:"is_default" binding:"omitempty"`
 	SeedPeerClusterID uint                          `json:"seed_peer_cluster_id" binding:"omitempty"`
+	TenantID          uint                          `json:"tenant_id" binding:"omitempty"`
 }
 
 type UpdateSchedulerClusterRequest struct {
@@ -43,22 +46,40 @@ type UpdateSchedulerClusterRequest struct {
 	Scopes            *SchedulerClusterScopes       `json:"scopes" binding:"omitempty"`
 	IsDefault         bool                          `json:"is_default" binding:"omitempty"`
 	SeedPeerClusterID uint                          `json:"seed_peer_cluster_id" binding:"omitempty"`
+	TenantID          uint                          `json:"tenant_id" binding:"omitempty"`
 }
 
 type GetSchedulerClustersQuery struct {
-	Name    string `form:"name" binding:"omitempty"`
-	Page    int    `form:"page" binding:"omitempty,gte=1"`
-	PerPage int    `form:"per_page" binding:"omitempty,gte=1,lte=50"`
+	Name     string `form:"name" binding:"omitempty"`
+	TenantID uint   `form:"tenant_id" binding:"omitempty"`
+	Page     int    `form:"page" binding:"omitempty,gte=1"`
+	PerPage  int    `form:"per_page" binding:"omitempty,gte=1,lte=50"`
 }
 
 type SchedulerClusterConfig struct {
-	CandidateParentLimit uint32 `yaml:"candidateParentLimit" mapstructure:"candidateParentLimit" json:"candidate_parent_limit" binding:"omitempty,gte=1,lte=20"`
-	FilterParentLimit    uint32 `yaml:"filterParentLimit" mapstructure:"filterParentLimit" json:"filter_parent_limit" binding:"omitempty,gte=10,lte=1000"`
+	CandidateParentLimit   uint32                    `yaml:"candidateParentLimit" mapstructure:"candidateParentLimit" json:"candidate_parent_limit" binding:"omitempty,gte=1,lte=20"`
+	FilterParentLimit      uint32                    `yaml:"filterParentLimit" mapstructure:"filterParentLimit" json:"filter_parent_limit" binding:"omitempty,gte=10,lte=1000"`
+	TrainerAddrs           []string                  `yaml:"trainerAddrs" mapstructure:"trainerAddrs" json:"trainer_addrs" binding:"omitempty"`
+	RetryLimit             uint32                    `yaml:"retryLimit" mapstructure:"retryLimit" json:"retry_limit" binding:"omitempty,gte=1,lte=100"`
+	RetryBackToSourceLimit uint32                    `yaml:"retryBackToSourceLimit" mapstructure:"retryBackToSourceLimit" json:"retry_back_to_source_limit" binding:"omitempty,gte=1,lte=100"`
+	BackToSourceCount      uint32                    `yaml:"backToSourceCount" mapstructure:"backToSourceCount" json:"back_to_source_count" binding:"omitempty,gte=1,lte=1000"`
+	GC                     *SchedulerClusterGCConfig `yaml:"gc" mapstructure:"gc" json:"gc" binding:"omitempty"`
+}
+
+// SchedulerClusterGCConfig is the gc policy of scheduler cluster, the ttls are in seconds
+// and the zero values fall back to the gc config of scheduler.
+type SchedulerClusterGCConfig struct {
+	PeerTTL      uint32 `yaml:"peerTTL" mapstructure:"peerTTL" json:"peer_ttl" binding:"omitempty,gte=60"`
+	TaskTTL      uint32 `yaml:"taskTTL" mapstructure:"taskTTL" json:"task_ttl" binding:"omitempty,gte=60"`
+	HostTTL      uint32 `yaml:"hostTTL" mapstructure:"hostTTL" json:"host_ttl" binding:"omitempty,gte=60"`
+	MaxTaskCount uint32 `yaml:"maxTaskCount" mapstructure:"maxTaskCount" json:"max_task_count" binding:"omitempty,gte=1"`
 }
 
 type SchedulerClusterClientConfig struct {
 	LoadLimit            uint32 `yaml:"loadLimit" mapstructure:"loadLimit" json:"load_limit" binding:"omitempty,gte=1,lte=2000"`
 	ConcurrentPieceCount uint32 `yaml:"concurrentPieceCount" mapstructure:"concurrentPieceCount" json:"concurrent_piece_count" binding:"omitempty,gte=1,lte=50"`
+	QUIC                 bool   `yaml:"quic" mapstructure:"quic" json:"quic" binding:"omitempty"`
+	ProbeProtocol        string `yaml:"probeProtocol" mapstructure:"probeProtocol" json:"probe_protocol" binding:"omitempty,oneof=icmp tcp quic"`
 }
 
 type SchedulerClusterScopes struct {
@@ -66,3 +87,16 @@ type SchedulerClusterScopes struct {
 	Location string   `yaml:"location" mapstructure:"location" json:"location" binding:"omitempty"`
 	CIDRs    []string `yaml:"cidrs" mapstructure:"cidrs" json:"cidrs" binding:"omitempty"`
 }
+
+type SeedPeerSaturation struct {
+	SchedulerCount        int       `json:"scheduler_count"`
+	SeedPeerCount         int       `json:"seed_peer_count"`
+	ActiveTaskCount       int       `json:"active_task_count"`
+	ConcurrentUploadCount int64     `json:"concurrent_upload_count"`
+	ConcurrentUploadLimit int64     `json:"concurrent_upload_limit"`
+	Saturation            float64   `json:"saturation"`
+	PeerCount             int       `json:"peer_count"`
+	BackToSourcePeerCount int       `json:"back_to_source_peer_count"`
+	BackToSourceRate      float64   `json:"back_to_source_rate"`
+	UpdatedAt             time.Time `json:"updated_at"`
+}
@@ -0,0 +1,44 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+package types
+
+type SearcherRuleParams struct {
+	ID uint `uri:"id" binding:"required"`
+}
+
+type CreateSearcherRuleRequest struct {
+	Name               string            `json:"name" binding:"required"`
+	BIO                string            `json:"bio" binding:"omitempty"`
+	CIDRs              []string          `json:"cidrs" binding:"required_without=Labels,omitempty,dive,cidr"`
+	Labels             map[string]string `json:"labels" binding:"required_without=CIDRs,omitempty"`
+	Weight             int               `json:"weight" binding:"omitempty,gte=1,lte=100"`
+	SchedulerClusterID uint              `json:"scheduler_cluster_id" binding:"required"`
+}
+
+type UpdateSearcherRuleRequest struct {
+	BIO                string            `json:"bio" binding:"omitempty"`
+	CIDRs              []string          `json:"cidrs" binding:"omitempty,dive,cidr"`
+	Labels             map[string]string `json:"labels" binding:"omitempty"`
+	Weight             int               `json:"weight" binding:"omitempty,gte=1,lte=100"`
+	SchedulerClusterID uint              `json:"scheduler_cluster_id" binding:"omitempty"`
+}
+
+type GetSearcherRulesQuery struct {
+	Name               string `form:"name" binding:"omitempty"`
+	SchedulerClusterID uint   `form:"scheduler_cluster_id" binding:"omitempty"`
+	Page               int    `form:"page" binding:"omitempty,gte=1"`
+	PerPage            int    `form:"per_page" binding:"omitempty,gte=1,lte=50"`
+}
@@ -31,21 +31,24 @@ type AddSchedulerClusterToSeedPeerClusterParams struct {
 }
 
 type CreateSeedPeerClusterRequest struct {
-	Name   string                 `json:"name" binding:"required"`
-	BIO    string                 `json:"bio" binding:"omitempty"`
-	Config *SeedPeerClusterConfig `json:"config" binding:"required"`
+	Name     string                 `json:"name" binding:"required"`
+	BIO      string                 `json:"bio" binding:"omitempty"`
+	Config   *SeedPeerClusterConfig `json:"config" binding:"required"`
+	TenantID uint                   `json:"tenant_id" binding:"omitempty"`
 }
 
 type UpdateSeedPeerClusterRequest struct {
-	Name   string                 `json:"name" binding:"omitempty"`
-	BIO    string                 `json:"bio" binding:"omitempty"`
-	Config *SeedPeerClusterConfig `json:"config" binding:"omitempty"`
+	Name     string                 `json:"name" binding:"omitempty"`
+	BIO      string                 `json:"bio" binding:"omitempty"`
+	Config   *SeedPeerClusterConfig `json:"config" binding:"omitempty"`
+	TenantID uint                   `json:"tenant_id" binding:"omitempty"`
 }
 
 type GetSeedPeerClustersQuery struct {
-	Name    string `form:"name" binding:"omitempty"`
-	Page    int    `form:"page" binding:"omitempty,gte=1"`
-	PerPage int    `form:"per_page" binding:"omitempty,gte=1,lte=50"`
+	Name     string `form:"name" binding:"omitempty"`
+	TenantID uint   `form:"tenant_id" binding:"omitempty"`
+	Page     int    `form:"page" binding:"omitempty,gte=1"`
+	PerPage  int    `form:"per_page" binding:"omitempty,gte=1,lte=50"`
 }
 
 type SeedPeerClusterConfig struct {
@@ -0,0 +1,42 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+type TenantParams struct {
+	ID uint `uri:"id" binding:"required"`
+}
+
+type CreateTenantRequest struct {
+	Name                  string `json:"name" binding:"required"`
+	BIO                   string `json:"bio" binding:"omitempty"`
+	PreheatJobQuota       int    `json:"preheat_job_quota" binding:"omitempty,gte=0"`
+	PreheatBandwidthQuota int64  `json:"preheat_bandwidth_quota" binding:"omitempty,gte=0"`
+}
+
+type UpdateTenantRequest struct {
+	BIO                   string `json:"bio" binding:"omitempty"`
+	State                 string `json:"state" binding:"omitempty,oneof=active inactive"`
+	PreheatJobQuota       int    `json:"preheat_job_quota" binding:"omitempty,gte=0"`
+	PreheatBandwidthQuota int64  `json:"preheat_bandwidth_quota" binding:"omitempty,gte=0"`
+}
+
+type GetTenantsQuery struct {
+	Name    string `form:"name" binding:"omitempty"`
+	State   string `form:"state" binding:"omitempty,oneof=active inactive"`
+	Page    int    `form:"page" binding:"omitempty,gte=1"`
+	PerPage int    `form:"per_page" binding:"omitempty,gte=1,lte=50"`
+}
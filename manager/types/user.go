@@ -77,3 +77,12 @@ type AddRoleForUserParams struct {
 	ID   uint   `uri:"id" binding:"required"`
 	Role string `uri:"role" binding:"required"`
 }
+
+type AddOauthForUserParams struct {
+	ID      uint `uri:"id" binding:"required"`
+	OauthID uint `uri:"oauth_id" binding:"required"`
+}
+
+type AddOauthForUserRequest struct {
+	Subject string `json:"subject" binding:"required"`
+}
@@ -0,0 +1,77 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import "time"
+
+type WebhookParams struct {
+	ID uint `uri:"id" binding:"required"`
+}
+
+type CreateWebhookRequest struct {
+	Name     string   `json:"name" binding:"required"`
+	BIO      string   `json:"bio" binding:"omitempty"`
+	URL      string   `json:"url" binding:"required,url"`
+	Secret   string   `json:"secret" binding:"required,min=16"`
+	Events   []string `json:"events" binding:"omitempty,dive,oneof=job.succeeded job.failed"`
+	JobTypes []string `json:"job_types" binding:"omitempty"`
+	UserID   uint     `json:"user_id" binding:"required"`
+}
+
+type UpdateWebhookRequest struct {
+	BIO      string   `json:"bio" binding:"omitempty"`
+	URL      string   `json:"url" binding:"omitempty,url"`
+	Secret   string   `json:"secret" binding:"omitempty,min=16"`
+	Events   []string `json:"events" binding:"omitempty,dive,oneof=job.succeeded job.failed"`
+	JobTypes []string `json:"job_types" binding:"omitempty"`
+	State    string   `json:"state" binding:"omitempty,oneof=active inactive"`
+	UserID   uint     `json:"user_id" binding:"omitempty"`
+}
+
+type GetWebhooksQuery struct {
+	Name    string `form:"name" binding:"omitempty"`
+	State   string `form:"state" binding:"omitempty,oneof=active inactive"`
+	Page    int    `form:"page" binding:"omitempty,gte=1"`
+	PerPage int    `form:"per_page" binding:"omitempty,gte=1,lte=50"`
+}
+
+// WebhookJobPayload is the payload posted to webhook when the state of job is changed.
+type WebhookJobPayload struct {
+	// Event is the event of webhook.
+	Event string `json:"event"`
+
+	// Job is the job whose state is changed.
+	Job WebhookJob `json:"job"`
+
+	// CreatedAt is the time of event.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookJob is the job in webhook payload.
+type WebhookJob struct {
+	ID                  uint           `json:"id"`
+	TaskID              string         `json:"task_id"`
+	BIO                 string         `json:"bio"`
+	Type                string         `json:"type"`
+	State               string         `json:"state"`
+	Args                map[string]any `json:"args"`
+	Result              map[string]any `json:"result"`
+	UserID              uint           `json:"user_id"`
+	SchedulerClusterIDs []uint         `json:"scheduler_cluster_ids"`
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+}
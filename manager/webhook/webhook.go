@@ -0,0 +1,201 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+//go:generate mockgen -destination mocks/webhook_mock.go -source webhook.go -package mocks
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"d7y.io/dragonfly/v2/pkg/retry"
+)
+
+const (
+	// HeaderEvent is the header of webhook event.
+	HeaderEvent = "X-Dragonfly-Event"
+
+	// HeaderDelivery is the header of unique id of delivery, it is unchanged when retrying.
+	HeaderDelivery = "X-Dragonfly-Delivery"
+
+	// HeaderTimestamp is the header of unix timestamp when the payload is signed.
+	HeaderTimestamp = "X-Dragonfly-Timestamp"
+
+	// HeaderSignature is the header of payload signature.
+	HeaderSignature = "X-Dragonfly-Signature"
+
+	// SignaturePrefix is the prefix of signature, which is the algorithm of signature.
+	SignaturePrefix = "sha256="
+)
+
+const (
+	// defaultTimeout is the default timeout of sending webhook.
+	defaultTimeout = 10 * time.Second
+
+	// defaultMaxAttempts is the default max attempts of sending webhook.
+	defaultMaxAttempts = 3
+
+	// defaultInitBackoff is the default initial backoff in seconds of retrying.
+	defaultInitBackoff = 1
+
+	// defaultMaxBackoff is the default max backoff in seconds of retrying.
+	defaultMaxBackoff = 10
+)
+
+// Webhook sends the events to the receivers registered by users.
+type Webhook interface {
+	// Send posts the signed json payload of event to url, it retries when the receiver is unavailable.
+	Send(ctx context.Context, url, secret, event string, payload any) error
+}
+
+// webhook implements the Webhook interface.
+type webhook struct {
+	// client is the http client of sending webhook.
+	client *http.Client
+
+	// timeout is the timeout of sending webhook.
+	timeout time.Duration
+
+	// maxAttempts is the max attempts of sending webhook.
+	maxAttempts int
+
+	// initBackoff is the initial backoff in seconds of retrying.
+	initBackoff float64
+
+	// maxBackoff is the max backoff in seconds of retrying.
+	maxBackoff float64
+}
+
+// Option is a functional option for configuring the webhook.
+type Option func(w *webhook)
+
+// WithTimeout sets the timeout of sending webhook.
+func WithTimeout(timeout time.Duration) Option {
+	return func(w *webhook) {
+		w.timeout = timeout
+	}
+}
+
+// WithMaxAttempts sets the max attempts of sending webhook.
+func WithMaxAttempts(maxAttempts int) Option {
+	return func(w *webhook) {
+		w.maxAttempts = maxAttempts
+	}
+}
+
+// WithBackoff sets the initial and max backoff in seconds of retrying.
+func WithBackoff(initBackoff, maxBackoff float64) Option {
+	return func(w *webhook) {
+		w.initBackoff = initBackoff
+		w.maxBackoff = maxBackoff
+	}
+}
+
+// New returns a new Webhook interface.
+func New(options ...Option) Webhook {
+	w := &webhook{
+		timeout:     defaultTimeout,
+		maxAttempts: defaultMaxAttempts,
+		initBackoff: defaultInitBackoff,
+		maxBackoff:  defaultMaxBackoff,
+	}
+
+	for _, opt := range options {
+		opt(w)
+	}
+
+	w.client = &http.Client{Timeout: w.timeout}
+	return w
+}
+
+// Send posts the signed json payload of event to url, it retries when the receiver is unavailable.
+func (w *webhook) Send(ctx context.Context, url, secret, event string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	delivery := uuid.NewString()
+	_, _, err = retry.Run(ctx, w.initBackoff, w.maxBackoff, w.maxAttempts, func() (any, bool, error) {
+		statusCode, err := w.send(ctx, url, secret, event, delivery, body)
+		if err != nil {
+			return nil, false, err
+		}
+
+		// The receiver rejects the payload, retrying does not help.
+		if statusCode >= http.StatusBadRequest && statusCode < http.StatusInternalServerError &&
+			statusCode != http.StatusRequestTimeout && statusCode != http.StatusTooManyRequests {
+			return nil, true, fmt.Errorf("webhook %s responds %d", url, statusCode)
+		}
+
+		if statusCode >= http.StatusBadRequest {
+			return nil, false, fmt.Errorf("webhook %s responds %d", url, statusCode)
+		}
+
+		return nil, false, nil
+	})
+
+	return err
+}
+
+// send posts the signed payload once and returns the status code of response.
+func (w *webhook) send(ctx context.Context, url, secret, event, delivery string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, event)
+	req.Header.Set(HeaderDelivery, delivery)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(secret, timestamp, body))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode, nil
+}
+
+// Sign returns the signature of payload, which is the hmac sha256 of timestamp and payload
+// joined by dot, the timestamp is signed for receivers to reject the replayed payloads.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return SignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify returns whether the signature of payload is valid.
+func Verify(secret, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
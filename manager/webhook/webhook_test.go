@@ -0,0 +1,145 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/atomic"
+)
+
+func TestWebhook_Send(t *testing.T) {
+	tests := []struct {
+		name        string
+		statusCodes []int
+		expect      func(t *testing.T, attempts int, err error)
+	}{
+		{
+			name:        "send webhook",
+			statusCodes: []int{http.StatusOK},
+			expect: func(t *testing.T, attempts int, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(1, attempts)
+			},
+		},
+		{
+			name:        "retry when receiver is unavailable",
+			statusCodes: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusNoContent},
+			expect: func(t *testing.T, attempts int, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(3, attempts)
+			},
+		},
+		{
+			name:        "receiver rejects payload",
+			statusCodes: []int{http.StatusUnauthorized},
+			expect: func(t *testing.T, attempts int, err error) {
+				assert := assert.New(t)
+				assert.ErrorContains(err, "responds 401")
+				assert.Equal(1, attempts)
+			},
+		},
+		{
+			name:        "retry exceeds max attempts",
+			statusCodes: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusOK},
+			expect: func(t *testing.T, attempts int, err error) {
+				assert := assert.New(t)
+				assert.ErrorContains(err, "responds 502")
+				assert.Equal(3, attempts)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				attempts   atomic.Int32
+				deliveries = map[string]struct{}{}
+			)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(t, err)
+				assert.JSONEq(t, `{"foo":"bar"}`, string(body))
+				assert.Equal(t, "job.succeeded", r.Header.Get(HeaderEvent))
+				assert.True(t, Verify("secret", r.Header.Get(HeaderTimestamp), body, r.Header.Get(HeaderSignature)))
+				deliveries[r.Header.Get(HeaderDelivery)] = struct{}{}
+
+				w.WriteHeader(tc.statusCodes[attempts.Inc()-1])
+			}))
+			defer server.Close()
+
+			w := New(WithMaxAttempts(3), WithBackoff(0.001, 0.001))
+			err := w.Send(context.Background(), server.URL, "secret", "job.succeeded", map[string]string{"foo": "bar"})
+			tc.expect(t, int(attempts.Load()), err)
+			assert.Len(t, deliveries, 1)
+		})
+	}
+}
+
+func TestWebhook_Verify(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		body      []byte
+		expect    bool
+	}{
+		{
+			name:      "signature is valid",
+			secret:    "secret",
+			timestamp: "1700000000",
+			body:      []byte(`{"foo":"bar"}`),
+			expect:    true,
+		},
+		{
+			name:      "secret is invalid",
+			secret:    "foo",
+			timestamp: "1700000000",
+			body:      []byte(`{"foo":"bar"}`),
+			expect:    false,
+		},
+		{
+			name:      "timestamp is replaced",
+			secret:    "secret",
+			timestamp: "1700000001",
+			body:      []byte(`{"foo":"bar"}`),
+			expect:    false,
+		},
+		{
+			name:      "body is tampered",
+			secret:    "secret",
+			timestamp: "1700000000",
+			body:      []byte(`{"foo":"baz"}`),
+			expect:    false,
+		},
+	}
+
+	signature := Sign("secret", "1700000000", []byte(`{"foo":"bar"}`))
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expect, Verify(tc.secret, tc.timestamp, tc.body, signature))
+		})
+	}
+}
@@ -135,6 +135,25 @@ func Parse(digest string) (*Digest, error) {
 	}, nil
 }
 
+// ParsePiece parses the digest of piece, the md5 digest of piece is encoded without
+// algorithm for compatibility, the others are in the form of algorithm:encoded.
+func ParsePiece(digest string) *Digest {
+	if d, err := Parse(digest); err == nil {
+		return d
+	}
+
+	return New(AlgorithmMD5, digest)
+}
+
+// PieceString returns the digest string of piece which can be parsed by ParsePiece.
+func (d *Digest) PieceString() string {
+	if d.Algorithm == AlgorithmMD5 {
+		return d.Encoded
+	}
+
+	return d.String()
+}
+
 // MD5FromReader computes the MD5 checksum with io.Reader.
 func MD5FromReader(reader io.Reader) string {
 	h := md5.New()
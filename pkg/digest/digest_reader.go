@@ -32,6 +32,9 @@ import (
 	logger "d7y.io/dragonfly/v2/internal/dflog"
 )
 
+// ErrEncodedNotMatch represents the encoded of content is not match the desired one.
+var ErrEncodedNotMatch = errors.New("digest encoded not match")
+
 // Reader is the interface used for reading resource.
 type Reader interface {
 	io.Reader
@@ -107,7 +110,7 @@ func (r *reader) Read(p []byte) (int, error) {
 		encoded := r.Encoded()
 		if encoded != r.encoded {
 			r.logger.Warnf("digest encoded not match, desired: %s, actual: %s", r.encoded, encoded)
-			return n, errors.New("digest encoded not match")
+			return n, ErrEncodedNotMatch
 		}
 
 		r.logger.Debugf("digest encoded match: %s", encoded)
@@ -166,7 +166,7 @@ func TestDigest_Reader(t *testing.T) {
 				assert.NoError(err)
 				assert.Equal(reader.Encoded(), "da39a3ee5e6b4b0d3255bfef95601890afd80709")
 				_, err = io.ReadAll(reader)
-				assert.Error(err)
+				assert.ErrorIs(err, ErrEncodedNotMatch)
 			},
 		},
 		{
@@ -179,7 +179,7 @@ func TestDigest_Reader(t *testing.T) {
 				assert.NoError(err)
 				assert.Equal(reader.Encoded(), "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855")
 				_, err = io.ReadAll(reader)
-				assert.Error(err)
+				assert.ErrorIs(err, ErrEncodedNotMatch)
 			},
 		},
 		{
@@ -192,7 +192,7 @@ func TestDigest_Reader(t *testing.T) {
 				assert.NoError(err)
 				assert.Equal(reader.Encoded(), "cf83e1357eefb8bdf1542850d66d8007d620e4050b5715dc83f4a921d36ce9ce47d0d13c5d85f2b0ff8318d2877eec2f63b931bd47417a81a538327af927da3e")
 				_, err = io.ReadAll(reader)
-				assert.Error(err)
+				assert.ErrorIs(err, ErrEncodedNotMatch)
 			},
 		},
 		{
@@ -205,7 +205,7 @@ func TestDigest_Reader(t *testing.T) {
 				assert.NoError(err)
 				assert.Equal(reader.Encoded(), "d41d8cd98f00b204e9800998ecf8427e")
 				_, err = io.ReadAll(reader)
-				assert.Error(err)
+				assert.ErrorIs(err, ErrEncodedNotMatch)
 			},
 		},
 		{
@@ -148,6 +148,48 @@ func TestDigest_Parse(t *testing.T) {
 	}
 }
 
+func TestDigest_ParsePiece(t *testing.T) {
+	tests := []struct {
+		name   string
+		value  string
+		expect func(t *testing.T, d *Digest)
+	}{
+		{
+			name:  "md5 digest without algorithm",
+			value: "5d41402abc4b2a76b9719d911017c592",
+			expect: func(t *testing.T, d *Digest) {
+				assert := assert.New(t)
+				assert.EqualValues(d, New(AlgorithmMD5, "5d41402abc4b2a76b9719d911017c592"))
+				assert.Equal(d.PieceString(), "5d41402abc4b2a76b9719d911017c592")
+			},
+		},
+		{
+			name:  "md5 digest with algorithm",
+			value: "md5:5d41402abc4b2a76b9719d911017c592",
+			expect: func(t *testing.T, d *Digest) {
+				assert := assert.New(t)
+				assert.EqualValues(d, New(AlgorithmMD5, "5d41402abc4b2a76b9719d911017c592"))
+				assert.Equal(d.PieceString(), "5d41402abc4b2a76b9719d911017c592")
+			},
+		},
+		{
+			name:  "sha256 digest",
+			value: "sha256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824",
+			expect: func(t *testing.T, d *Digest) {
+				assert := assert.New(t)
+				assert.EqualValues(d, New(AlgorithmSHA256, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"))
+				assert.Equal(d.PieceString(), "sha256:2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expect(t, ParsePiece(tc.value))
+		})
+	}
+}
+
 func TestDigest_MD5FromReader(t *testing.T) {
 	assert.Equal(t, "5d41402abc4b2a76b9719d911017c592", MD5FromReader(strings.NewReader("hello")))
 }
@@ -18,6 +18,8 @@ package fqdn
 
 import (
 	"os"
+	"sync"
+	"time"
 
 	"github.com/Showmax/go-fqdn"
 
@@ -26,13 +28,65 @@ import (
 
 var FQDNHostname string
 
+// Hook is called after each resolution of fqdn hostname with the latency and error of resolution.
+type Hook func(elapsed time.Duration, err error)
+
+var (
+	// lookup resolves the fqdn hostname, it is replaced in tests.
+	lookup = fqdn.FqdnHostname
+
+	// hookMu protects hook and the resolutions not reported to hook.
+	hookMu  sync.Mutex
+	hook    Hook
+	pending []resolution
+)
+
+// resolution is the latency and error of a resolution.
+type resolution struct {
+	elapsed time.Duration
+	err     error
+}
+
 func init() {
 	FQDNHostname = fqdnHostname()
 }
 
+// SetHook sets the hook of resolutions, so that the metrics of resolutions can be collected
+// without depending on the metrics packages. The resolutions before the hook is set, e.g.
+// the resolution in init, are reported to the hook when it is set.
+func SetHook(h Hook) {
+	hookMu.Lock()
+	defer hookMu.Unlock()
+
+	hook = h
+	if hook == nil {
+		return
+	}
+
+	for _, r := range pending {
+		hook(r.elapsed, r.err)
+	}
+	pending = nil
+}
+
+// observe reports the resolution to hook, or keeps it until the hook is set.
+func observe(elapsed time.Duration, err error) {
+	hookMu.Lock()
+	defer hookMu.Unlock()
+
+	if hook == nil {
+		pending = append(pending, resolution{elapsed, err})
+		return
+	}
+
+	hook(elapsed, err)
+}
+
 // Get FQDN hostname
 func fqdnHostname() string {
-	fqdn, err := fqdn.FqdnHostname()
+	start := time.Now()
+	fqdn, err := lookup()
+	observe(time.Since(start), err)
 	if err != nil {
 		logger.Warnf("can not found fqdn: %s", err.Error())
 		hostname, err := os.Hostname()
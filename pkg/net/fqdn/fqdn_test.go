@@ -17,7 +17,9 @@
 package fqdn
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -26,3 +28,46 @@ func TestFQDNHostname(t *testing.T) {
 	fqdn := fqdnHostname()
 	assert.NotEmpty(t, fqdn)
 }
+
+func TestFQDNHostname_Hook(t *testing.T) {
+	tests := []struct {
+		name   string
+		lookup func() (string, error)
+		expect func(t *testing.T, hostname string, errs []error)
+	}{
+		{
+			name:   "resolution succeeds",
+			lookup: func() (string, error) { return "foo.example.com", nil },
+			expect: func(t *testing.T, hostname string, errs []error) {
+				assert := assert.New(t)
+				assert.Equal("foo.example.com", hostname)
+				assert.Equal([]error{nil}, errs)
+			},
+		},
+		{
+			name:   "resolution fails",
+			lookup: func() (string, error) { return "", errors.New("foo") },
+			expect: func(t *testing.T, hostname string, errs []error) {
+				assert := assert.New(t)
+				assert.NotEmpty(hostname)
+				assert.Len(errs, 1)
+				assert.EqualError(errs[0], "foo")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func(l func() (string, error)) { lookup = l }(lookup)
+			lookup = tc.lookup
+
+			var errs []error
+			SetHook(func(elapsed time.Duration, err error) { errs = append(errs, err) })
+			defer SetHook(nil)
+
+			// The resolution in init is reported when the hook is set.
+			errs = nil
+			tc.expect(t, fqdnHostname(), errs)
+		})
+	}
+}
@@ -17,10 +17,14 @@
 package ping
 
 import (
+	"context"
+	"crypto/tls"
 	"errors"
+	"net"
 	"time"
 
 	"github.com/gaius-qi/ping"
+	"github.com/quic-go/quic-go"
 )
 
 const (
@@ -33,6 +37,17 @@ const (
 	defaultPingTimeout = 1 * time.Second
 )
 
+const (
+	// ProtocolICMP probes the host with icmp echo.
+	ProtocolICMP = "icmp"
+
+	// ProtocolTCP probes the host with tcp handshake.
+	ProtocolTCP = "tcp"
+
+	// ProtocolQUIC probes the host with quic handshake over udp.
+	ProtocolQUIC = "quic"
+)
+
 // Ping returns the ping metrics with the icmp protocol.
 func Ping(addr string) (*ping.Statistics, error) {
 	pinger, err := ping.NewPinger(addr)
@@ -57,3 +72,42 @@ func Ping(addr string) (*ping.Statistics, error) {
 
 	return stats, nil
 }
+
+// PingTCP returns the round-trip time of the tcp handshake with addr.
+func PingTCP(ctx context.Context, addr string) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultPingTimeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return 0, err
+	}
+	rtt := time.Since(start)
+
+	conn.Close()
+	return rtt, nil
+}
+
+// PingQUIC returns the round-trip time of the quic handshake with addr. The handshake of quic
+// takes one round trip over udp, so it is not affected by the paths rate-limiting tcp syn.
+// The tlsConfig must contain the application protocols served by addr, and the certificates of
+// addr are verified by it, so the caller decides whether to verify them.
+func PingQUIC(ctx context.Context, addr string, tlsConfig *tls.Config) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultPingTimeout)
+	defer cancel()
+
+	start := time.Now()
+	conn, err := quic.DialAddr(ctx, addr, tlsConfig, &quic.Config{HandshakeIdleTimeout: defaultPingTimeout})
+	if err != nil {
+		return 0, err
+	}
+	rtt := time.Since(start)
+
+	if err := conn.CloseWithError(0, ""); err != nil {
+		return 0, err
+	}
+
+	return rtt, nil
+}
@@ -17,8 +17,19 @@
 package ping
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
 	"testing"
+	"time"
 
+	"github.com/quic-go/quic-go"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -36,3 +47,72 @@ func TestPing(t *testing.T) {
 	_, err = Ping("foo")
 	assert.Error(t, err)
 }
+
+func TestPingTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	rtt, err := PingTCP(context.Background(), listener.Addr().String())
+	assert.NoError(t, err)
+	assert.Greater(t, rtt, time.Duration(0))
+
+	addr := listener.Addr().String()
+	listener.Close()
+	_, err = PingTCP(context.Background(), addr)
+	assert.Error(t, err)
+}
+
+func TestPingQUIC(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "foo"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listener, err := quic.ListenAddr("127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+		NextProtos:   []string{"foo"},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept(context.Background())
+			if err != nil {
+				return
+			}
+
+			<-conn.Context().Done()
+		}
+	}()
+
+	rtt, err := PingQUIC(context.Background(), listener.Addr().String(), &tls.Config{InsecureSkipVerify: true, NextProtos: []string{"foo"}})
+	assert.NoError(t, err)
+	assert.Greater(t, rtt, time.Duration(0))
+
+	_, err = PingQUIC(context.Background(), listener.Addr().String(), &tls.Config{InsecureSkipVerify: true, NextProtos: []string{"bar"}})
+	assert.Error(t, err)
+
+	addr := listener.Addr().String()
+	listener.Close()
+	_, err = PingQUIC(context.Background(), addr, &tls.Config{InsecureSkipVerify: true, NextProtos: []string{"foo"}})
+	assert.Error(t, err)
+}
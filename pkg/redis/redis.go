@@ -49,6 +49,18 @@ const (
 
 	// ProbedCountNamespace prefix of probed count namespace cache key.
 	ProbedCountNamespace = "probed-count"
+
+	// PeerExchangeNamespace prefix of peer exchange namespace cache key.
+	PeerExchangeNamespace = "peer-exchange"
+
+	// SeedPeerSaturationNamespace prefix of seed peer saturation namespace cache key.
+	SeedPeerSaturationNamespace = "seed-peer-saturation"
+
+	// ConfigVersionReportNamespace prefix of config version report namespace cache key.
+	ConfigVersionReportNamespace = "config-version-reports"
+
+	// InventoryNamespace prefix of inventory namespace cache key.
+	InventoryNamespace = "inventories"
 )
 
 func NewRedis(cfg *redis.UniversalOptions) (redis.UniversalClient, error) {
@@ -98,6 +110,36 @@ func MakeSchedulersKeyForPeerInManager(hostname, ip string) string {
 	return MakeKeyInManager(PeersNamespace, fmt.Sprintf("%s-%s:schedulers", hostname, ip))
 }
 
+// MakeSeedPeerSaturationKeyInManager make seed peer saturation key of scheduler in manager.
+func MakeSeedPeerSaturationKeyInManager(clusterID uint, hostname, ip string) string {
+	return MakeKeyInManager(SeedPeerSaturationNamespace, fmt.Sprintf("%d-%s-%s", clusterID, hostname, ip))
+}
+
+// MakeSeedPeerSaturationsKeyInManager make the pattern of seed peer saturation keys of scheduler cluster in manager.
+func MakeSeedPeerSaturationsKeyInManager(clusterID uint) string {
+	return MakeKeyInManager(SeedPeerSaturationNamespace, fmt.Sprintf("%d-*", clusterID))
+}
+
+// MakeConfigVersionReportKeyInManager make config version report key of consumer in manager.
+func MakeConfigVersionReportKeyInManager(clusterType string, clusterID uint, hostname, ip string) string {
+	return MakeKeyInManager(ConfigVersionReportNamespace, fmt.Sprintf("%s:%d:%s-%s", clusterType, clusterID, hostname, ip))
+}
+
+// MakeConfigVersionReportsKeyInManager make the pattern of config version report keys of cluster in manager.
+func MakeConfigVersionReportsKeyInManager(clusterType string, clusterID uint) string {
+	return MakeKeyInManager(ConfigVersionReportNamespace, fmt.Sprintf("%s:%d:*", clusterType, clusterID))
+}
+
+// MakeInventoryKeyInManager make inventory key of scheduler in manager.
+func MakeInventoryKeyInManager(clusterID uint, hostname, ip string) string {
+	return MakeKeyInManager(InventoryNamespace, fmt.Sprintf("%d-%s-%s", clusterID, hostname, ip))
+}
+
+// MakeInventoriesKeyInManager make the pattern of inventory keys of schedulers in manager.
+func MakeInventoriesKeyInManager() string {
+	return MakeKeyInManager(InventoryNamespace, "*")
+}
+
 // MakeApplicationsKeyInManager make applications key in manager.
 func MakeApplicationsKeyInManager() string {
 	return MakeNamespaceKeyInManager(ApplicationsNamespace)
@@ -132,3 +174,8 @@ func MakeProbesKeyInScheduler(srcHostID, destHostID string) string {
 func MakeProbedCountKeyInScheduler(hostID string) string {
 	return MakeKeyInScheduler(ProbedCountNamespace, hostID)
 }
+
+// MakePeerExchangeKeyInScheduler make peer exchange key of task in scheduler cluster.
+func MakePeerExchangeKeyInScheduler(clusterID uint, taskID string) string {
+	return MakeKeyInScheduler(PeerExchangeNamespace, fmt.Sprintf("%d:%s", clusterID, taskID))
+}
@@ -18,6 +18,7 @@ package retry
 
 import (
 	"context"
+	"math/rand"
 	"time"
 
 	"d7y.io/dragonfly/v2/pkg/math"
@@ -51,3 +52,53 @@ func Run(ctx context.Context,
 
 	return res, cancel, cause
 }
+
+// RunWithBackoff runs f at most maxAttempts times until it succeeds or is canceled like Run,
+// the backoff between attempts is computed by Backoff and sleeping is interrupted when ctx is done.
+func RunWithBackoff(ctx context.Context,
+	initBackoff time.Duration,
+	maxBackoff time.Duration,
+	jitter float64,
+	maxAttempts int,
+	f func() (data any, cancel bool, err error)) (any, bool, error) {
+	var (
+		res    any
+		cancel bool
+		cause  error
+	)
+	for i := 1; i <= maxAttempts; i++ {
+		res, cancel, cause = f()
+		if cause == nil || cancel || i == maxAttempts {
+			break
+		}
+
+		timer := time.NewTimer(Backoff(initBackoff, maxBackoff, jitter, i))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, cancel, ctx.Err()
+		}
+	}
+
+	return res, cancel, cause
+}
+
+// Backoff returns the backoff after the attempt, it doubles from initBackoff up to maxBackoff
+// and is reduced randomly by at most the jitter ratio in [0, 1].
+func Backoff(initBackoff, maxBackoff time.Duration, jitter float64, attempt int) time.Duration {
+	backoff := initBackoff
+	for i := 1; i < attempt && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+
+	if jitter > 0 && backoff > 0 {
+		backoff -= time.Duration(jitter * rand.Float64() * float64(backoff))
+	}
+
+	return backoff
+}
@@ -0,0 +1,142 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		name        string
+		initBackoff time.Duration
+		maxBackoff  time.Duration
+		jitter      float64
+		expect      func(t *testing.T, backoffs []time.Duration)
+	}{
+		{
+			name:        "backoff doubles up to max backoff",
+			initBackoff: 100 * time.Millisecond,
+			maxBackoff:  time.Second,
+			expect: func(t *testing.T, backoffs []time.Duration) {
+				assert := assert.New(t)
+				assert.Equal([]time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond,
+					800 * time.Millisecond, time.Second, time.Second}, backoffs)
+			},
+		},
+		{
+			name:        "backoff is reduced by jitter",
+			initBackoff: 100 * time.Millisecond,
+			maxBackoff:  time.Second,
+			jitter:      0.5,
+			expect: func(t *testing.T, backoffs []time.Duration) {
+				assert := assert.New(t)
+				for i, max := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond,
+					800 * time.Millisecond, time.Second, time.Second} {
+					assert.LessOrEqual(backoffs[i], max)
+					assert.Greater(backoffs[i], max/2)
+				}
+			},
+		},
+		{
+			name: "no backoff",
+			expect: func(t *testing.T, backoffs []time.Duration) {
+				assert := assert.New(t)
+				assert.Equal(make([]time.Duration, 6), backoffs)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var backoffs []time.Duration
+			for attempt := 1; attempt <= 6; attempt++ {
+				backoffs = append(backoffs, Backoff(tc.initBackoff, tc.maxBackoff, tc.jitter, attempt))
+			}
+
+			tc.expect(t, backoffs)
+		})
+	}
+}
+
+func TestRunWithBackoff(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxAttempts int
+		failures    int
+		cancel      bool
+		expect      func(t *testing.T, attempts int, err error)
+	}{
+		{
+			name:        "succeed after retries",
+			maxAttempts: 3,
+			failures:    2,
+			expect: func(t *testing.T, attempts int, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(3, attempts)
+			},
+		},
+		{
+			name:        "fail after max attempts",
+			maxAttempts: 3,
+			failures:    5,
+			expect: func(t *testing.T, attempts int, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "foo")
+				assert.Equal(3, attempts)
+			},
+		},
+		{
+			name:        "context is canceled in backoff",
+			maxAttempts: 3,
+			failures:    5,
+			cancel:      true,
+			expect: func(t *testing.T, attempts int, err error) {
+				assert := assert.New(t)
+				assert.ErrorIs(err, context.Canceled)
+				assert.Equal(1, attempts)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			var attempts int
+			_, _, err := RunWithBackoff(ctx, time.Millisecond, 10*time.Millisecond, 0.5, tc.maxAttempts, func() (any, bool, error) {
+				attempts++
+				if tc.cancel {
+					cancel()
+				}
+
+				if attempts <= tc.failures {
+					return nil, false, errors.New("foo")
+				}
+				return nil, false, nil
+			})
+			tc.expect(t, attempts, err)
+		})
+	}
+}
@@ -16,6 +16,16 @@
 
 package common
 
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"google.golang.org/grpc/metadata"
+
+	"d7y.io/dragonfly/v2/pkg/types"
+)
+
 var (
 	// EndOfPiece is the number of end piece.
 	EndOfPiece = int32(1) << 30
@@ -23,3 +33,42 @@ var (
 	// BeginOfPiece is the number of begin piece.
 	BeginOfPiece = int32(-1)
 )
+
+// AppendPriorityClassToOutgoingContext returns a new context with the priority class of task
+// appended to the outgoing metadata, so that scheduler schedules the task by the priority class.
+func AppendPriorityClassToOutgoingContext(ctx context.Context, class types.PriorityClass) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, types.PriorityClassMetadataKey, class.Name())
+}
+
+// PriorityClassFromIncomingContext returns the priority class of task in the incoming metadata,
+// it returns the normal priority class if the metadata is not found.
+func PriorityClassFromIncomingContext(ctx context.Context) types.PriorityClass {
+	values := metadata.ValueFromIncomingContext(ctx, types.PriorityClassMetadataKey)
+	if len(values) == 0 {
+		return types.PriorityClassNormal
+	}
+
+	return types.ParsePriorityClass(values[0])
+}
+
+// AppendPieceLengthToOutgoingContext returns a new context with the preferred piece length
+// of task appended to the outgoing metadata.
+func AppendPieceLengthToOutgoingContext(ctx context.Context, pieceLength int32) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, types.PieceLengthMetadataKey, strconv.FormatInt(int64(pieceLength), 10))
+}
+
+// PieceLengthFromIncomingContext returns the preferred piece length of task in the incoming
+// metadata, it returns zero if the metadata is not found.
+func PieceLengthFromIncomingContext(ctx context.Context) (int32, error) {
+	values := metadata.ValueFromIncomingContext(ctx, types.PieceLengthMetadataKey)
+	if len(values) == 0 {
+		return 0, nil
+	}
+
+	pieceLength, err := strconv.ParseInt(values[0], 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid piece length %s: %w", values[0], err)
+	}
+
+	return int32(pieceLength), nil
+}
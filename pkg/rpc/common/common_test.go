@@ -0,0 +1,114 @@
+/*
+ *     Copyright 2022 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+
+	"d7y.io/dragonfly/v2/pkg/types"
+)
+
+func TestPriorityClassFromIncomingContext(t *testing.T) {
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		expect types.PriorityClass
+	}{
+		{
+			name:   "metadata is not found",
+			ctx:    context.Background(),
+			expect: types.PriorityClassNormal,
+		},
+		{
+			name:   "metadata is best-effort",
+			ctx:    metadata.NewIncomingContext(context.Background(), metadata.Pairs(types.PriorityClassMetadataKey, types.PriorityClassBestEffortName)),
+			expect: types.PriorityClassBestEffort,
+		},
+		{
+			name:   "metadata is critical",
+			ctx:    metadata.NewIncomingContext(context.Background(), metadata.Pairs(types.PriorityClassMetadataKey, types.PriorityClassCriticalName)),
+			expect: types.PriorityClassCritical,
+		},
+		{
+			name:   "metadata is unknown",
+			ctx:    metadata.NewIncomingContext(context.Background(), metadata.Pairs(types.PriorityClassMetadataKey, "foo")),
+			expect: types.PriorityClassNormal,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expect, PriorityClassFromIncomingContext(tc.ctx))
+		})
+	}
+}
+
+func TestAppendPriorityClassToOutgoingContext(t *testing.T) {
+	ctx := AppendPriorityClassToOutgoingContext(context.Background(), types.PriorityClassCritical)
+	md, ok := metadata.FromOutgoingContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, []string{types.PriorityClassCriticalName}, md.Get(types.PriorityClassMetadataKey))
+}
+
+func TestPieceLengthFromIncomingContext(t *testing.T) {
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		expect func(t *testing.T, pieceLength int32, err error)
+	}{
+		{
+			name: "metadata is not found",
+			ctx:  context.Background(),
+			expect: func(t *testing.T, pieceLength int32, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(int32(0), pieceLength)
+			},
+		},
+		{
+			name: "metadata is appended by outgoing context",
+			ctx: func() context.Context {
+				md, _ := metadata.FromOutgoingContext(AppendPieceLengthToOutgoingContext(context.Background(), 16*1024*1024))
+				return metadata.NewIncomingContext(context.Background(), md)
+			}(),
+			expect: func(t *testing.T, pieceLength int32, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(int32(16*1024*1024), pieceLength)
+			},
+		},
+		{
+			name: "metadata is invalid",
+			ctx:  metadata.NewIncomingContext(context.Background(), metadata.Pairs(types.PieceLengthMetadataKey, "foo")),
+			expect: func(t *testing.T, pieceLength int32, err error) {
+				assert := assert.New(t)
+				assert.Error(err)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pieceLength, err := PieceLengthFromIncomingContext(tc.ctx)
+			tc.expect(t, pieceLength, err)
+		})
+	}
+}
@@ -194,6 +194,7 @@ func (v *v2) CreateModel(ctx context.Context, req *managerv2.CreateModelRequest,
 // List acitve schedulers configuration.
 func (v *v2) KeepAlive(interval time.Duration, keepalive *managerv2.KeepAliveRequest, done <-chan struct{}, opts ...grpc.CallOption) {
 	log := logger.WithKeepAlive(keepalive.Hostname, keepalive.Ip, keepalive.SourceType.Enum().String(), keepalive.ClusterId)
+	observe := keepAliveObserver(opts)
 retry:
 	ctx, cancel := context.WithCancel(context.Background())
 	stream, err := v.ManagerClient.KeepAlive(ctx, opts...)
@@ -204,9 +205,15 @@ retry:
 			return
 		}
 
-		time.Sleep(interval)
+		observe(err)
 		cancel()
-		goto retry
+		select {
+		case <-time.After(interval):
+			goto retry
+		case <-done:
+			log.Info("keepalive done")
+			return
+		}
 	}
 
 	tick := time.NewTicker(interval)
@@ -219,17 +226,49 @@ retry:
 				Ip:         keepalive.Ip,
 				ClusterId:  keepalive.ClusterId,
 			}); err != nil {
+				observe(err)
 				if _, err := stream.CloseAndRecv(); err != nil {
 					log.Infof("recv stream failed: %s", err.Error())
 				}
 
+				tick.Stop()
 				cancel()
 				goto retry
 			}
+
+			observe(nil)
 		case <-done:
 			log.Info("keepalive done")
+			tick.Stop()
 			cancel()
 			return
 		}
 	}
 }
+
+// KeepAliveObserver observes the keepalive to manager, it is called with nil after each keepalive
+// request is sent, and with the error when the keepalive stream fails to open or send.
+type KeepAliveObserver func(err error)
+
+// KeepAliveObserverCallOption is the CallOption of KeepAlive setting the observer of keepalive,
+// it does not alter the grpc call.
+type KeepAliveObserverCallOption struct {
+	grpc.EmptyCallOption
+	Observer KeepAliveObserver
+}
+
+// WithKeepAliveObserver returns the CallOption of KeepAlive setting the observer of keepalive.
+func WithKeepAliveObserver(observer KeepAliveObserver) grpc.CallOption {
+	return KeepAliveObserverCallOption{Observer: observer}
+}
+
+// keepAliveObserver returns the observer of keepalive set by opts, the observer does nothing if it is not set.
+func keepAliveObserver(opts []grpc.CallOption) KeepAliveObserver {
+	for _, opt := range opts {
+		if o, ok := opt.(KeepAliveObserverCallOption); ok && o.Observer != nil {
+			return o.Observer
+		}
+	}
+
+	return func(error) {}
+}
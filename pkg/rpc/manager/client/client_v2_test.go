@@ -0,0 +1,168 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	managerv2 "d7y.io/api/pkg/apis/manager/v2"
+)
+
+// mockManagerServer is a manager server notifying the received keepalive.
+type mockManagerServer struct {
+	managerv2.UnimplementedManagerServer
+	keepalive chan struct{}
+}
+
+func (s *mockManagerServer) UpdateScheduler(ctx context.Context, req *managerv2.UpdateSchedulerRequest) (*managerv2.Scheduler, error) {
+	return &managerv2.Scheduler{Hostname: req.Hostname}, nil
+}
+
+func (s *mockManagerServer) KeepAlive(stream managerv2.Manager_KeepAliveServer) error {
+	if _, err := stream.Recv(); err != nil {
+		return err
+	}
+
+	close(s.keepalive)
+	<-stream.Context().Done()
+	return nil
+}
+
+func TestClientV2_Interceptors(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := grpc.NewServer()
+	managerServer := &mockManagerServer{keepalive: make(chan struct{})}
+	managerv2.RegisterManagerServer(server, managerServer)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	var (
+		mu      sync.Mutex
+		methods []string
+	)
+	record := func(method string) {
+		mu.Lock()
+		defer mu.Unlock()
+		methods = append(methods, method)
+	}
+
+	client, err := GetV2ByAddr(context.Background(), listener.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			record(method)
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}),
+		grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			record(method)
+			return streamer(ctx, desc, cc, method, opts...)
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	assert := assert.New(t)
+	scheduler, err := client.UpdateScheduler(context.Background(), &managerv2.UpdateSchedulerRequest{Hostname: "foo"})
+	assert.NoError(err)
+	assert.Equal("foo", scheduler.Hostname)
+
+	done := make(chan struct{})
+	go client.KeepAlive(10*time.Millisecond, &managerv2.KeepAliveRequest{Hostname: "foo"}, done)
+	select {
+	case <-managerServer.keepalive:
+	case <-time.After(10 * time.Second):
+		t.Fatal("keepalive is not received")
+	}
+	close(done)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal([]string{
+		"/manager.v2.Manager/UpdateScheduler",
+		"/manager.v2.Manager/KeepAlive",
+	}, methods)
+}
+
+func TestClientV2_KeepAliveObserver(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := grpc.NewServer()
+	managerv2.RegisterManagerServer(server, &mockManagerServer{keepalive: make(chan struct{})})
+	go server.Serve(listener)
+
+	client, err := GetV2ByAddr(context.Background(), listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	errs := make(chan error, 16)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		client.KeepAlive(10*time.Millisecond, &managerv2.KeepAliveRequest{Hostname: "foo"}, done, WithKeepAliveObserver(func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		}))
+	}()
+
+	// receive returns the next observed error which is nil or not.
+	receive := func(failed bool) error {
+		for {
+			select {
+			case err := <-errs:
+				if (err != nil) == failed {
+					return err
+				}
+			case <-time.After(10 * time.Second):
+				t.Fatal("keepalive is not observed")
+			}
+		}
+	}
+
+	assert := assert.New(t)
+	assert.NoError(receive(false))
+
+	// Keepalive fails after manager stops, and keeps retrying until done.
+	server.Stop()
+	assert.Error(receive(true))
+	close(done)
+	select {
+	case <-exited:
+	case <-time.After(10 * time.Second):
+		t.Fatal("keepalive does not exit")
+	}
+}
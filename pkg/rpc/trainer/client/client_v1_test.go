@@ -0,0 +1,87 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package client
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	trainerv1 "d7y.io/api/pkg/apis/trainer/v1"
+)
+
+// mockTrainerServer is a trainer server recording the metadata of train stream.
+type mockTrainerServer struct {
+	trainerv1.UnimplementedTrainerServer
+	md metadata.MD
+}
+
+func (s *mockTrainerServer) Train(stream trainerv1.Trainer_TrainServer) error {
+	s.md, _ = metadata.FromIncomingContext(stream.Context())
+	for {
+		if _, err := stream.Recv(); err != nil {
+			if err == io.EOF {
+				return stream.SendAndClose(&emptypb.Empty{})
+			}
+
+			return err
+		}
+	}
+}
+
+func TestClientV1_Interceptors(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := grpc.NewServer()
+	trainerServer := &mockTrainerServer{}
+	trainerv1.RegisterTrainerServer(server, trainerServer)
+	go server.Serve(listener)
+	defer server.Stop()
+
+	var methods []string
+	client, err := GetV1ByAddr(context.Background(), listener.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithChainStreamInterceptor(func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+			methods = append(methods, method)
+			return streamer(metadata.AppendToOutgoingContext(ctx, "authorization", "foo"), desc, cc, method, opts...)
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	assert := assert.New(t)
+	stream, err := client.Train(context.Background())
+	assert.NoError(err)
+	assert.NoError(stream.Send(&trainerv1.TrainRequest{Hostname: "foo"}))
+	_, err = stream.CloseAndRecv()
+	assert.NoError(err)
+
+	assert.Equal([]string{"/trainer.v1.Trainer/Train"}, methods)
+	assert.Equal([]string{"foo"}, trainerServer.md.Get("authorization"))
+}
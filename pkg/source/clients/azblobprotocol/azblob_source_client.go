@@ -0,0 +1,349 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package azblobprotocol
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+
+	nethttp "d7y.io/dragonfly/v2/pkg/net/http"
+	"d7y.io/dragonfly/v2/pkg/source"
+	pkgstrings "d7y.io/dragonfly/v2/pkg/strings"
+)
+
+// AzureBlobScheme is the scheme of azure blob url, the format is azblob://<container>/<blob>.
+const AzureBlobScheme = "azblob"
+
+const (
+	// Azure storage account name, the endpoint is https://<account>.blob.core.windows.net/
+	accountName = "azureAccountName"
+	// Azure blob service endpoint, it overrides the endpoint of account name, e.g. sovereign clouds and azurite
+	endpoint = "azureEndpoint"
+	// Azure shared access signature token, the blob is accessed with managed identity when it is empty
+	sasToken = "azureSASToken"
+	// Azure client ID of user-assigned managed identity, the system-assigned identity is used when it is empty
+	managedIdentityClientID = "azureManagedIdentityClientID"
+)
+
+var _ source.ResourceClient = (*azblobSourceClient)(nil)
+
+func init() {
+	source.RegisterBuilder(AzureBlobScheme, source.NewPlainResourceClientBuilder(Builder))
+}
+
+func Builder(optionYaml []byte) (source.ResourceClient, source.RequestAdapter, []source.Hook, error) {
+	return NewAzureBlobSourceClient(), adaptor, nil, nil
+}
+
+func adaptor(request *source.Request) *source.Request {
+	return request.Clone(request.Context())
+}
+
+func NewAzureBlobSourceClient(opts ...AzureBlobSourceClientOption) source.ResourceClient {
+	return newAzureBlobSourceClient(opts...)
+}
+
+func newAzureBlobSourceClient(opts ...AzureBlobSourceClientOption) *azblobSourceClient {
+	sourceClient := &azblobSourceClient{
+		clientMap: sync.Map{},
+	}
+	for i := range opts {
+		opts[i](sourceClient)
+	}
+	return sourceClient
+}
+
+type AzureBlobSourceClientOption func(p *azblobSourceClient)
+
+// azblobSourceClient is an implementation of the interface of source.ResourceClient.
+type azblobSourceClient struct {
+	// endpoint_sasToken_clientID -> azblob client,
+	// the client is cached to reuse the token of managed identity
+	clientMap sync.Map
+}
+
+func (c *azblobSourceClient) GetContentLength(request *source.Request) (int64, error) {
+	props, err := c.getProperties(request)
+	if err != nil {
+		return source.UnknownSourceFileLen, err
+	}
+
+	if props.ContentLength == nil {
+		return source.UnknownSourceFileLen, nil
+	}
+	return *props.ContentLength, nil
+}
+
+// IsSupportRange returns true when the blob exists, azure blob supports range for all blob types.
+func (c *azblobSourceClient) IsSupportRange(request *source.Request) (bool, error) {
+	if _, err := c.getProperties(request); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *azblobSourceClient) IsExpired(request *source.Request, info *source.ExpireInfo) (bool, error) {
+	props, err := c.getProperties(request)
+	if err != nil {
+		return false, err
+	}
+
+	var etag, lastModified string
+	if props.ETag != nil {
+		etag = string(*props.ETag)
+	}
+	if props.LastModified != nil {
+		lastModified = props.LastModified.UTC().Format(source.TimeFormat)
+	}
+	return !(etag == info.ETag || lastModified == info.LastModified), nil
+}
+
+func (c *azblobSourceClient) Download(request *source.Request) (*source.Response, error) {
+	blobClient, err := c.getBlobClient(request)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &blob.DownloadStreamOptions{}
+	if r := request.Header.Get(source.Range); r != "" {
+		rg, err := nethttp.ParseURLMetaRange(r, math.MaxInt64)
+		if err != nil {
+			return nil, fmt.Errorf("parse range %s: %w", r, err)
+		}
+		opts.Range = blob.HTTPRange{Offset: rg.Start, Count: rg.Length}
+	}
+
+	resp, err := blobClient.DownloadStream(request.Context(), opts)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+
+	var (
+		contentLength int64 = -1
+		expireInfo    source.ExpireInfo
+	)
+	if resp.ContentLength != nil {
+		contentLength = *resp.ContentLength
+	}
+	if resp.ETag != nil {
+		expireInfo.ETag = string(*resp.ETag)
+	}
+	if resp.LastModified != nil {
+		expireInfo.LastModified = resp.LastModified.UTC().Format(source.TimeFormat)
+	}
+
+	statusCode := http.StatusOK
+	if opts.Range.Count > 0 {
+		statusCode = http.StatusPartialContent
+	}
+
+	return source.NewResponse(
+		resp.Body,
+		source.WithStatus(statusCode, http.StatusText(statusCode)),
+		source.WithContentLength(contentLength),
+		source.WithExpireInfo(expireInfo),
+	), nil
+}
+
+func (c *azblobSourceClient) GetLastModified(request *source.Request) (int64, error) {
+	props, err := c.getProperties(request)
+	if err != nil {
+		return -1, err
+	}
+
+	if props.LastModified == nil {
+		return -1, nil
+	}
+	return props.LastModified.UnixMilli(), nil
+}
+
+func (c *azblobSourceClient) List(request *source.Request) (urls []source.URLEntry, err error) {
+	client, err := c.getClient(request.Header)
+	if err != nil {
+		return nil, fmt.Errorf("get azure blob client: %w", err)
+	}
+	containerClient := client.ServiceClient().NewContainerClient(request.URL.Host)
+
+	// if request is a single blob, just return
+	path := addTrailingSlash(strings.TrimPrefix(request.URL.Path, "/"))
+	isDir, err := isDirectory(request, containerClient, path)
+	if err != nil {
+		return nil, err
+	}
+	if !isDir {
+		return []source.URLEntry{buildURLEntry(false, request.URL)}, nil
+	}
+
+	// list all blobs and virtual directories
+	pager := containerClient.NewListBlobsHierarchyPager("/", &container.ListBlobsHierarchyOptions{Prefix: to.Ptr(path)})
+	for pager.More() {
+		page, err := pager.NextPage(request.Context())
+		if err != nil {
+			return urls, fmt.Errorf("list azure blob %s/%s: %w", request.URL.Host, path, err)
+		}
+		if page.Segment == nil {
+			continue
+		}
+
+		for _, item := range page.Segment.BlobItems {
+			if item.Name != nil && *item.Name != path {
+				url := *request.URL
+				url.Path = addLeadingSlash(*item.Name)
+				urls = append(urls, buildURLEntry(false, &url))
+			}
+		}
+
+		for _, prefix := range page.Segment.BlobPrefixes {
+			if prefix.Name != nil {
+				url := *request.URL
+				url.Path = addLeadingSlash(*prefix.Name)
+				urls = append(urls, buildURLEntry(true, &url))
+			}
+		}
+	}
+	return urls, nil
+}
+
+func isDirectory(request *source.Request, containerClient *container.Client, path string) (bool, error) {
+	pager := containerClient.NewListBlobsHierarchyPager("/", &container.ListBlobsHierarchyOptions{
+		Prefix:     to.Ptr(path),
+		MaxResults: to.Ptr(int32(1)),
+	})
+	page, err := pager.NextPage(request.Context())
+	if err != nil {
+		return false, fmt.Errorf("list azure blob %s/%s: %w", request.URL.Host, path, err)
+	}
+	if page.Segment == nil {
+		return false, nil
+	}
+	return len(page.Segment.BlobItems)+len(page.Segment.BlobPrefixes) > 0, nil
+}
+
+func (c *azblobSourceClient) getProperties(request *source.Request) (blob.GetPropertiesResponse, error) {
+	blobClient, err := c.getBlobClient(request)
+	if err != nil {
+		return blob.GetPropertiesResponse{}, err
+	}
+
+	props, err := blobClient.GetProperties(request.Context(), nil)
+	if err != nil {
+		return blob.GetPropertiesResponse{}, wrapError(err)
+	}
+	return props, nil
+}
+
+func (c *azblobSourceClient) getBlobClient(request *source.Request) (*blob.Client, error) {
+	client, err := c.getClient(request.Header)
+	if err != nil {
+		return nil, fmt.Errorf("get azure blob client: %w", err)
+	}
+
+	return client.ServiceClient().NewContainerClient(request.URL.Host).
+		NewBlobClient(strings.TrimPrefix(request.URL.Path, "/")), nil
+}
+
+func (c *azblobSourceClient) getClient(header source.Header) (*azblob.Client, error) {
+	serviceURL := header.Get(endpoint)
+	if pkgstrings.IsBlank(serviceURL) {
+		account := header.Get(accountName)
+		if pkgstrings.IsBlank(account) {
+			return nil, errors.New("azureAccountName and azureEndpoint are empty")
+		}
+		serviceURL = fmt.Sprintf("https://%s.blob.core.windows.net/", account)
+	}
+
+	sas := strings.TrimPrefix(header.Get(sasToken), "?")
+	clientID := header.Get(managedIdentityClientID)
+	clientKey := buildClientKey(serviceURL, sas, clientID)
+	if client, ok := c.clientMap.Load(clientKey); ok {
+		return client.(*azblob.Client), nil
+	}
+
+	var (
+		client *azblob.Client
+		err    error
+	)
+	if !pkgstrings.IsBlank(sas) {
+		client, err = azblob.NewClientWithNoCredential(fmt.Sprintf("%s?%s", serviceURL, sas), nil)
+	} else {
+		opts := &azidentity.ManagedIdentityCredentialOptions{}
+		if !pkgstrings.IsBlank(clientID) {
+			opts.ID = azidentity.ClientID(clientID)
+		}
+
+		var cred *azidentity.ManagedIdentityCredential
+		if cred, err = azidentity.NewManagedIdentityCredential(opts); err != nil {
+			return nil, fmt.Errorf("new managed identity credential: %w", err)
+		}
+		client, err = azblob.NewClient(serviceURL, cred, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	actual, _ := c.clientMap.LoadOrStore(clientKey, client)
+	return actual.(*azblob.Client), nil
+}
+
+func buildClientKey(serviceURL, sasToken, clientID string) string {
+	return fmt.Sprintf("%s_%s_%s", serviceURL, sasToken, clientID)
+}
+
+// wrapError converts the error of missing blob to source.ErrResourceNotReachable.
+func wrapError(err error) error {
+	if bloberror.HasCode(err, bloberror.BlobNotFound, bloberror.ContainerNotFound) {
+		return fmt.Errorf("%s: %w", err, source.ErrResourceNotReachable)
+	}
+	return err
+}
+
+func buildURLEntry(isDir bool, url *url.URL) source.URLEntry {
+	if isDir {
+		url.Path = addTrailingSlash(url.Path)
+		list := strings.Split(url.Path, "/")
+		return source.URLEntry{URL: url, Name: list[len(list)-2], IsDir: true}
+	}
+	_, name := filepath.Split(url.Path)
+	return source.URLEntry{URL: url, Name: name, IsDir: false}
+}
+
+func addLeadingSlash(s string) string {
+	if strings.HasPrefix(s, "/") {
+		return s
+	}
+	return "/" + s
+}
+
+func addTrailingSlash(s string) string {
+	if strings.HasSuffix(s, "/") {
+		return s
+	}
+	return s + "/"
+}
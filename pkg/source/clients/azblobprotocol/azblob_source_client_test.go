@@ -0,0 +1,171 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package azblobprotocol
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-http-utils/headers"
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/pkg/source"
+)
+
+const testContent = "hello azure blob"
+
+func newTestServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "sig", r.URL.Query().Get("sig"))
+		if r.URL.Path != "/container/dir/blob" {
+			w.Header().Set("x-ms-error-code", "BlobNotFound")
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set(headers.ETag, `"etag"`)
+		w.Header().Set(headers.LastModified, "Mon, 02 Jan 2006 15:04:05 GMT")
+		content := testContent
+		if rg := r.Header.Get("x-ms-range"); rg != "" {
+			var start, end int
+			_, err := fmt.Sscanf(rg, "bytes=%d-%d", &start, &end)
+			assert.NoError(t, err)
+			content = testContent[start : end+1]
+			w.Header().Set(headers.ContentLength, strconv.Itoa(len(content)))
+			w.WriteHeader(http.StatusPartialContent)
+		} else {
+			w.Header().Set(headers.ContentLength, strconv.Itoa(len(content)))
+		}
+
+		if r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(content))
+		}
+	}))
+}
+
+func TestAzureBlobSourceClient_Download(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    string
+		rg     string
+		expect func(t *testing.T, resp *source.Response, err error)
+	}{
+		{
+			name: "download blob",
+			url:  "azblob://container/dir/blob",
+			expect: func(t *testing.T, resp *source.Response, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				data, err := io.ReadAll(resp.Body)
+				assert.NoError(err)
+				assert.Equal(testContent, string(data))
+				assert.Equal(`"etag"`, resp.ExpireInfo().ETag)
+			},
+		},
+		{
+			name: "download blob with range",
+			url:  "azblob://container/dir/blob",
+			rg:   "6-10",
+			expect: func(t *testing.T, resp *source.Response, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(http.StatusPartialContent, resp.StatusCode)
+				data, err := io.ReadAll(resp.Body)
+				assert.NoError(err)
+				assert.Equal("azure", string(data))
+			},
+		},
+		{
+			name: "blob not found",
+			url:  "azblob://container/dir/foo",
+			expect: func(t *testing.T, resp *source.Response, err error) {
+				assert.ErrorIs(t, err, source.ErrResourceNotReachable)
+			},
+		},
+	}
+
+	server := newTestServer(t)
+	defer server.Close()
+	client := newAzureBlobSourceClient()
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			header := map[string]string{endpoint: server.URL, sasToken: "?sv=2021-08-06&sig=sig"}
+			if tc.rg != "" {
+				header[source.Range] = tc.rg
+			}
+			request, err := source.NewRequestWithHeader(tc.url, header)
+			assert.NoError(t, err)
+
+			resp, err := client.Download(adaptor(request))
+			if resp != nil {
+				defer resp.Body.Close()
+			}
+			tc.expect(t, resp, err)
+		})
+	}
+}
+
+func TestAzureBlobSourceClient_Metadata(t *testing.T) {
+	assert := assert.New(t)
+	server := newTestServer(t)
+	defer server.Close()
+
+	client := newAzureBlobSourceClient()
+	request, err := source.NewRequestWithHeader("azblob://container/dir/blob",
+		map[string]string{endpoint: server.URL, sasToken: "sv=2021-08-06&sig=sig"})
+	assert.NoError(err)
+
+	length, err := client.GetContentLength(request)
+	assert.NoError(err)
+	assert.EqualValues(len(testContent), length)
+
+	support, err := client.IsSupportRange(request)
+	assert.NoError(err)
+	assert.True(support)
+
+	expired, err := client.IsExpired(request, &source.ExpireInfo{ETag: `"etag"`})
+	assert.NoError(err)
+	assert.False(expired)
+
+	lastModified, err := client.GetLastModified(request)
+	assert.NoError(err)
+	assert.EqualValues(1136214245000, lastModified)
+}
+
+func TestAzureBlobSourceClient_GetClient(t *testing.T) {
+	assert := assert.New(t)
+	client := newAzureBlobSourceClient()
+
+	_, err := client.getClient(source.Header{})
+	assert.Error(err)
+
+	header := source.Header{}
+	header.Set(accountName, "account")
+	header.Set(sasToken, "sig=sig")
+	azClient, err := client.getClient(header)
+	assert.NoError(err)
+	assert.Equal("https://account.blob.core.windows.net/?sig=sig", azClient.URL())
+
+	cached, err := client.getClient(header)
+	assert.NoError(err)
+	assert.Same(azClient, cached)
+}
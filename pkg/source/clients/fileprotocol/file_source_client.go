@@ -0,0 +1,278 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fileprotocol
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	nethttp "d7y.io/dragonfly/v2/pkg/net/http"
+	"d7y.io/dragonfly/v2/pkg/source"
+)
+
+// FileScheme is the scheme of local file url, the format is file:///<path>,
+// the path is usually on a mounted shared filesystem, e.g. nfs export.
+const FileScheme = "file"
+
+// ErrPathNotAllowed is returned when the path is not under the allowed paths.
+var ErrPathNotAllowed = errors.New("path is not allowed")
+
+var _ source.ResourceClient = (*fileSourceClient)(nil)
+
+func init() {
+	source.RegisterBuilder(FileScheme, source.NewPlainResourceClientBuilder(Builder))
+}
+
+// fileOption is the option of file source client, it is configured in download.resourceClients.file.
+type fileOption struct {
+	// AllowedPaths are the absolute paths allowed to back-to-source, all paths are denied when it is empty,
+	// to prevent serving arbitrary files of the host.
+	AllowedPaths []string `yaml:"allowedPaths"`
+}
+
+// parseFileOption parses the option of file source client from yaml.
+func parseFileOption(optionYaml []byte) (*fileOption, error) {
+	opt := &fileOption{}
+	if err := yaml.Unmarshal(optionYaml, opt); err != nil {
+		return nil, err
+	}
+
+	for i, path := range opt.AllowedPaths {
+		if !filepath.IsAbs(path) {
+			return nil, fmt.Errorf("allowed path %s is not absolute", path)
+		}
+		opt.AllowedPaths[i] = filepath.Clean(path)
+	}
+
+	return opt, nil
+}
+
+func Builder(optionYaml []byte) (source.ResourceClient, source.RequestAdapter, []source.Hook, error) {
+	opt, err := parseFileOption(optionYaml)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return NewFileSourceClient(WithAllowedPaths(opt.AllowedPaths)), adaptor, nil, nil
+}
+
+func adaptor(request *source.Request) *source.Request {
+	return request.Clone(request.Context())
+}
+
+type FileSourceClientOption func(p *fileSourceClient)
+
+// WithAllowedPaths sets the paths allowed to back-to-source.
+func WithAllowedPaths(paths []string) FileSourceClientOption {
+	return func(p *fileSourceClient) {
+		p.allowedPaths = paths
+	}
+}
+
+func NewFileSourceClient(opts ...FileSourceClientOption) source.ResourceClient {
+	return newFileSourceClient(opts...)
+}
+
+func newFileSourceClient(opts ...FileSourceClientOption) *fileSourceClient {
+	client := &fileSourceClient{}
+	for _, opt := range opts {
+		opt(client)
+	}
+	return client
+}
+
+// fileSourceClient is an implementation of the interface of source.ResourceClient.
+type fileSourceClient struct {
+	allowedPaths []string
+}
+
+func (c *fileSourceClient) GetContentLength(request *source.Request) (int64, error) {
+	_, info, err := c.stat(request.URL)
+	if err != nil {
+		return source.UnknownSourceFileLen, err
+	}
+
+	if info.IsDir() {
+		return source.UnknownSourceFileLen, fmt.Errorf("%s is a directory", request.URL.Path)
+	}
+	return info.Size(), nil
+}
+
+func (c *fileSourceClient) IsSupportRange(request *source.Request) (bool, error) {
+	if _, _, err := c.stat(request.URL); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *fileSourceClient) IsExpired(request *source.Request, info *source.ExpireInfo) (bool, error) {
+	_, fileInfo, err := c.stat(request.URL)
+	if err != nil {
+		return false, err
+	}
+
+	return fileInfo.ModTime().UTC().Format(source.TimeFormat) != info.LastModified, nil
+}
+
+func (c *fileSourceClient) Download(request *source.Request) (*source.Response, error) {
+	path, info, err := c.stat(request.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", request.URL.Path)
+	}
+
+	var (
+		offset     int64
+		length     = info.Size()
+		statusCode = http.StatusOK
+	)
+	if r := request.Header.Get(source.Range); r != "" {
+		rg, err := nethttp.ParseURLMetaRange(r, info.Size())
+		if err != nil {
+			return nil, fmt.Errorf("parse range %s: %w", r, err)
+		}
+		offset, length, statusCode = rg.Start, rg.Length, http.StatusPartialContent
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return source.NewResponse(
+		&fileReader{Reader: io.LimitReader(f, length), Closer: f},
+		source.WithStatus(statusCode, http.StatusText(statusCode)),
+		source.WithContentLength(length),
+		source.WithExpireInfo(source.ExpireInfo{
+			LastModified: info.ModTime().UTC().Format(source.TimeFormat),
+		}),
+	), nil
+}
+
+func (c *fileSourceClient) GetLastModified(request *source.Request) (int64, error) {
+	_, info, err := c.stat(request.URL)
+	if err != nil {
+		return -1, err
+	}
+
+	return info.ModTime().UnixMilli(), nil
+}
+
+func (c *fileSourceClient) List(request *source.Request) (urls []source.URLEntry, err error) {
+	path, info, err := c.stat(request.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	// if request is a single file, just return
+	if !info.IsDir() {
+		_, name := filepath.Split(request.URL.Path)
+		return []source.URLEntry{{URL: request.URL, Name: name, IsDir: false}}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		url := *request.URL
+		url.Path = filepath.Join(request.URL.Path, entry.Name())
+		isDir := entry.IsDir()
+		if isDir {
+			url.Path += "/"
+		}
+		urls = append(urls, source.URLEntry{URL: &url, Name: entry.Name(), IsDir: isDir})
+	}
+	return urls, nil
+}
+
+// stat returns the real path and info of the file in url, the real path must be under the allowed paths.
+func (c *fileSourceClient) stat(u *url.URL) (string, fs.FileInfo, error) {
+	if u.Host != "" && u.Host != "localhost" {
+		return "", nil, fmt.Errorf("remote host %s is not supported", u.Host)
+	}
+
+	// check the path before touching the filesystem, otherwise the existence of
+	// files out of the allowed paths is exposed by the errors
+	path := filepath.Clean(u.Path)
+	if !c.isAllowed(path) {
+		return "", nil, fmt.Errorf("%s: %w", u.Path, ErrPathNotAllowed)
+	}
+
+	// resolve symbolic links and check again to prevent escaping from the allowed paths
+	path, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", nil, fmt.Errorf("%s: %w", u.Path, source.ErrResourceNotReachable)
+		}
+		return "", nil, err
+	}
+
+	if !c.isAllowed(path) {
+		return "", nil, fmt.Errorf("%s: %w", u.Path, ErrPathNotAllowed)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", nil, err
+	}
+	return path, info, nil
+}
+
+// isAllowed returns whether the path is under one of the allowed paths,
+// the allowed path matches both as configured and with symbolic links resolved.
+func (c *fileSourceClient) isAllowed(path string) bool {
+	for _, allowedPath := range c.allowedPaths {
+		if isUnder(path, allowedPath) {
+			return true
+		}
+
+		if realPath, err := filepath.EvalSymlinks(allowedPath); err == nil && isUnder(path, realPath) {
+			return true
+		}
+	}
+	return false
+}
+
+// isUnder returns whether the path is the dir or under the dir.
+func isUnder(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator))
+}
+
+// fileReader limits the reader of file to the range, and closes the file.
+type fileReader struct {
+	io.Reader
+	io.Closer
+}
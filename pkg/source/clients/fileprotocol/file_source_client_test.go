@@ -0,0 +1,217 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fileprotocol
+
+import (
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/pkg/source"
+)
+
+const testContent = "hello nfs"
+
+func TestParseFileOption(t *testing.T) {
+	tests := []struct {
+		name   string
+		yaml   string
+		expect func(t *testing.T, opt *fileOption, err error)
+	}{
+		{
+			name: "empty option",
+			yaml: "",
+			expect: func(t *testing.T, opt *fileOption, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Empty(opt.AllowedPaths)
+			},
+		},
+		{
+			name: "allowed paths",
+			yaml: "allowedPaths:\n- /mnt/nfs/\n- /data/../artifacts",
+			expect: func(t *testing.T, opt *fileOption, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal([]string{"/mnt/nfs", "/artifacts"}, opt.AllowedPaths)
+			},
+		},
+		{
+			name: "relative path",
+			yaml: "allowedPaths:\n- mnt/nfs",
+			expect: func(t *testing.T, opt *fileOption, err error) {
+				assert.ErrorContains(t, err, "not absolute")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			opt, err := parseFileOption([]byte(tc.yaml))
+			tc.expect(t, opt, err)
+		})
+	}
+}
+
+func TestFileSourceClient_Download(t *testing.T) {
+	dir := t.TempDir()
+	allowedDir := filepath.Join(dir, "allowed")
+	assert.NoError(t, os.MkdirAll(allowedDir, 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(allowedDir, "file"), []byte(testContent), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "secret"), []byte("secret"), 0644))
+	assert.NoError(t, os.Symlink(filepath.Join(dir, "secret"), filepath.Join(allowedDir, "link")))
+
+	tests := []struct {
+		name   string
+		path   string
+		rg     string
+		expect func(t *testing.T, resp *source.Response, err error)
+	}{
+		{
+			name: "download file",
+			path: filepath.Join(allowedDir, "file"),
+			expect: func(t *testing.T, resp *source.Response, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.EqualValues(len(testContent), resp.ContentLength)
+				data, err := io.ReadAll(resp.Body)
+				assert.NoError(err)
+				assert.Equal(testContent, string(data))
+			},
+		},
+		{
+			name: "download file with range",
+			path: filepath.Join(allowedDir, "file"),
+			rg:   "6-8",
+			expect: func(t *testing.T, resp *source.Response, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(http.StatusPartialContent, resp.StatusCode)
+				data, err := io.ReadAll(resp.Body)
+				assert.NoError(err)
+				assert.Equal("nfs", string(data))
+			},
+		},
+		{
+			name: "file not allowed",
+			path: filepath.Join(dir, "secret"),
+			expect: func(t *testing.T, resp *source.Response, err error) {
+				assert.ErrorIs(t, err, ErrPathNotAllowed)
+			},
+		},
+		{
+			name: "escape with parent path",
+			path: allowedDir + "/../secret",
+			expect: func(t *testing.T, resp *source.Response, err error) {
+				assert.ErrorIs(t, err, ErrPathNotAllowed)
+			},
+		},
+		{
+			name: "escape with symbolic link",
+			path: filepath.Join(allowedDir, "link"),
+			expect: func(t *testing.T, resp *source.Response, err error) {
+				assert.ErrorIs(t, err, ErrPathNotAllowed)
+			},
+		},
+		{
+			name: "file not found out of allowed paths",
+			path: filepath.Join(dir, "foo"),
+			expect: func(t *testing.T, resp *source.Response, err error) {
+				assert.ErrorIs(t, err, ErrPathNotAllowed)
+			},
+		},
+		{
+			name: "file not found",
+			path: filepath.Join(allowedDir, "foo"),
+			expect: func(t *testing.T, resp *source.Response, err error) {
+				assert.ErrorIs(t, err, source.ErrResourceNotReachable)
+			},
+		},
+	}
+
+	client := newFileSourceClient(WithAllowedPaths([]string{allowedDir}))
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			header := map[string]string{}
+			if tc.rg != "" {
+				header[source.Range] = tc.rg
+			}
+			request, err := source.NewRequestWithHeader("file://"+tc.path, header)
+			assert.NoError(t, err)
+
+			resp, err := client.Download(adaptor(request))
+			if resp != nil {
+				defer resp.Body.Close()
+			}
+			tc.expect(t, resp, err)
+		})
+	}
+}
+
+func TestFileSourceClient_Metadata(t *testing.T) {
+	assert := assert.New(t)
+	dir := t.TempDir()
+	assert.NoError(os.MkdirAll(filepath.Join(dir, "sub"), 0755))
+	assert.NoError(os.WriteFile(filepath.Join(dir, "file"), []byte(testContent), 0644))
+
+	client := newFileSourceClient(WithAllowedPaths([]string{dir}))
+	request, err := source.NewRequest("file://" + filepath.Join(dir, "file"))
+	assert.NoError(err)
+
+	length, err := client.GetContentLength(request)
+	assert.NoError(err)
+	assert.EqualValues(len(testContent), length)
+
+	support, err := client.IsSupportRange(request)
+	assert.NoError(err)
+	assert.True(support)
+
+	info, err := os.Stat(filepath.Join(dir, "file"))
+	assert.NoError(err)
+	lastModified, err := client.GetLastModified(request)
+	assert.NoError(err)
+	assert.Equal(info.ModTime().UnixMilli(), lastModified)
+
+	expired, err := client.IsExpired(request, &source.ExpireInfo{LastModified: info.ModTime().UTC().Format(source.TimeFormat)})
+	assert.NoError(err)
+	assert.False(expired)
+
+	request, err = source.NewRequest("file://" + dir)
+	assert.NoError(err)
+	entries, err := client.List(request)
+	assert.NoError(err)
+	assert.Len(entries, 2)
+	for _, entry := range entries {
+		switch entry.Name {
+		case "file":
+			assert.False(entry.IsDir)
+		case "sub":
+			assert.True(entry.IsDir)
+			assert.Equal(filepath.Join(dir, "sub")+"/", entry.URL.Path)
+		default:
+			t.Errorf("unexpected entry %s", entry.Name)
+		}
+	}
+
+	client = newFileSourceClient()
+	_, err = client.GetContentLength(request)
+	assert.ErrorIs(err, ErrPathNotAllowed)
+}
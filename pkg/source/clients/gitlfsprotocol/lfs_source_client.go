@@ -0,0 +1,342 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gitlfsprotocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-http-utils/headers"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/pkg/digest"
+	"d7y.io/dragonfly/v2/pkg/source"
+)
+
+const (
+	// LFSScheme is the scheme of git lfs object url, the format is
+	// lfs://<host>/<repository>/<oid>?size=<size>, e.g. lfs://github.com/org/repo/<sha256>?size=1024.
+	LFSScheme = "lfs"
+
+	// sizeQuery is the query of object size in url, it is required by batch api.
+	sizeQuery = "size"
+
+	// lfsMediaType is the media type of git lfs batch api.
+	lfsMediaType = "application/vnd.git-lfs+json"
+
+	// operationDownload is the download operation of batch api.
+	operationDownload = "download"
+
+	// transferBasic is the basic transfer adapter of batch api.
+	transferBasic = "basic"
+)
+
+var (
+	// oidPattern is the pattern of sha256 object id.
+	oidPattern = regexp.MustCompile("^[0-9a-f]{64}$")
+
+	// ErrInvalidURL is returned when the url is not a valid git lfs object url.
+	ErrInvalidURL = errors.New("invalid git lfs url, format is lfs://<host>/<repository>/<oid>?size=<size>")
+)
+
+func init() {
+	source.RegisterBuilder(LFSScheme,
+		source.NewPlainResourceClientBuilder(Builder),
+		source.WithDirector(source.NewPlainDirector(Director)))
+}
+
+func Builder(optionYaml []byte) (source.ResourceClient, source.RequestAdapter, []source.Hook, error) {
+	httpClient, err := source.ParseToHTTPClient(optionYaml)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	client := NewLFSSourceClient(WithHTTPClient(httpClient))
+	return client, adapter, nil, nil
+}
+
+// Director sets the oid of object as the digest of task, so the content is validated by peers.
+func Director(rawURL *url.URL, urlMeta *commonv1.UrlMeta) error {
+	object, err := parseURL(rawURL)
+	if err != nil {
+		return err
+	}
+
+	// the digest of ranged task is not the oid
+	if urlMeta.Digest == "" && urlMeta.Range == "" {
+		urlMeta.Digest = digest.New(digest.AlgorithmSHA256, object.oid).String()
+	}
+
+	return nil
+}
+
+func adapter(request *source.Request) *source.Request {
+	return request.Clone(request.Context())
+}
+
+// lfsSourceClient is an implementation of the interface of source.ResourceClient.
+type lfsSourceClient struct {
+	httpClient *http.Client
+}
+
+type LFSSourceClientOption func(p *lfsSourceClient)
+
+// WithHTTPClient sets the http client for batch api and object downloading.
+func WithHTTPClient(client *http.Client) LFSSourceClientOption {
+	return func(p *lfsSourceClient) {
+		p.httpClient = client
+	}
+}
+
+func NewLFSSourceClient(opts ...LFSSourceClientOption) source.ResourceClient {
+	return newLFSSourceClient(opts...)
+}
+
+func newLFSSourceClient(opts ...LFSSourceClientOption) *lfsSourceClient {
+	client := &lfsSourceClient{}
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	if client.httpClient == nil {
+		client.httpClient = http.DefaultClient
+	}
+	return client
+}
+
+var _ source.ResourceClient = (*lfsSourceClient)(nil)
+
+// lfsObject is the git lfs object parsed from url.
+type lfsObject struct {
+	// endpoint is the git lfs server url of repository
+	endpoint string
+	oid      string
+	size     int64
+}
+
+// batchRequest is the request of git lfs batch api.
+type batchRequest struct {
+	Operation string        `json:"operation"`
+	Transfers []string      `json:"transfers,omitempty"`
+	Objects   []batchObject `json:"objects"`
+	HashAlgo  string        `json:"hash_algo,omitempty"`
+}
+
+// batchResponse is the response of git lfs batch api.
+type batchResponse struct {
+	Transfer string        `json:"transfer,omitempty"`
+	Objects  []batchObject `json:"objects"`
+	Message  string        `json:"message,omitempty"`
+}
+
+type batchObject struct {
+	OID     string                  `json:"oid"`
+	Size    int64                   `json:"size"`
+	Actions map[string]*batchAction `json:"actions,omitempty"`
+	Error   *batchError             `json:"error,omitempty"`
+}
+
+type batchAction struct {
+	Href   string            `json:"href"`
+	Header map[string]string `json:"header,omitempty"`
+}
+
+type batchError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// parseURL parses the git lfs object from url, the endpoint of repository follows the
+// git lfs server discovery, e.g. https://<host>/<repository>.git/info/lfs.
+func parseURL(u *url.URL) (*lfsObject, error) {
+	path := strings.Trim(u.Path, "/")
+	i := strings.LastIndex(path, "/")
+	if u.Host == "" || i <= 0 {
+		return nil, ErrInvalidURL
+	}
+
+	repository, oid := path[:i], path[i+1:]
+	if !oidPattern.MatchString(oid) {
+		return nil, fmt.Errorf("invalid oid %s: %w", oid, ErrInvalidURL)
+	}
+
+	size, err := strconv.ParseInt(u.Query().Get(sizeQuery), 10, 64)
+	if err != nil || size < 0 {
+		return nil, fmt.Errorf("invalid size %q: %w", u.Query().Get(sizeQuery), ErrInvalidURL)
+	}
+
+	if !strings.HasSuffix(repository, ".git") {
+		repository += ".git"
+	}
+
+	return &lfsObject{
+		endpoint: fmt.Sprintf("https://%s/%s/info/lfs", u.Host, repository),
+		oid:      oid,
+		size:     size,
+	}, nil
+}
+
+func (client *lfsSourceClient) GetContentLength(request *source.Request) (int64, error) {
+	object, err := parseURL(request.URL)
+	if err != nil {
+		return source.UnknownSourceFileLen, err
+	}
+
+	return object.size, nil
+}
+
+func (client *lfsSourceClient) IsSupportRange(request *source.Request) (bool, error) {
+	if request.Header.Get(source.Range) != "" {
+		return true, nil
+	}
+
+	rangeRequest := request.Clone(request.Context())
+	rangeRequest.Header.Set(source.Range, "0-0")
+	resp, err := client.download(rangeRequest)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode == http.StatusPartialContent, nil
+}
+
+// IsExpired returns false, the git lfs object is addressed by the content.
+func (client *lfsSourceClient) IsExpired(request *source.Request, info *source.ExpireInfo) (bool, error) {
+	return false, nil
+}
+
+func (client *lfsSourceClient) Download(request *source.Request) (*source.Response, error) {
+	resp, err := client.download(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := source.CheckResponseCode(resp.StatusCode, []int{http.StatusOK, http.StatusPartialContent}); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+
+	return source.NewResponse(
+		resp.Body,
+		source.WithStatus(resp.StatusCode, resp.Status),
+		source.WithContentLength(resp.ContentLength),
+	), nil
+}
+
+// GetLastModified returns -1, the git lfs object is addressed by the content.
+func (client *lfsSourceClient) GetLastModified(request *source.Request) (int64, error) {
+	return -1, nil
+}
+
+// download requests the download action of object from batch api, then downloads the object with it.
+func (client *lfsSourceClient) download(request *source.Request) (*http.Response, error) {
+	object, err := parseURL(request.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	action, err := client.batch(request, object)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(request.Context(), http.MethodGet, action.Href, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range action.Header {
+		req.Header.Set(k, v)
+	}
+
+	if rg := request.Header.Get(source.Range); rg != "" {
+		req.Header.Set(headers.Range, fmt.Sprintf("bytes=%s", rg))
+	}
+
+	logger.Debugf("download git lfs object %s from %s", object.oid, action.Href)
+	return client.httpClient.Do(req)
+}
+
+// batch requests the download action of object, the authorization of request is used for batch api only,
+// the object is downloaded with the headers of action, because it is usually served by another storage.
+func (client *lfsSourceClient) batch(request *source.Request, object *lfsObject) (*batchAction, error) {
+	body, err := json.Marshal(&batchRequest{
+		Operation: operationDownload,
+		Transfers: []string{transferBasic},
+		Objects:   []batchObject{{OID: object.oid, Size: object.size}},
+		HashAlgo:  digest.AlgorithmSHA256,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(request.Context(), http.MethodPost, object.endpoint+"/objects/batch", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set(headers.Accept, lfsMediaType)
+	req.Header.Set(headers.ContentType, lfsMediaType)
+	if auth := request.Header.Get(headers.Authorization); auth != "" {
+		req.Header.Set(headers.Authorization, auth)
+	}
+
+	resp, err := client.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var batchResp batchResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&batchResp); err != nil && resp.StatusCode == http.StatusOK {
+		return nil, fmt.Errorf("decode git lfs batch response error: %w", err)
+	}
+
+	if err := source.CheckResponseCode(resp.StatusCode, []int{http.StatusOK}); err != nil {
+		if batchResp.Message != "" {
+			return nil, fmt.Errorf("git lfs batch error: %s: %w", batchResp.Message, err)
+		}
+		return nil, err
+	}
+
+	for _, o := range batchResp.Objects {
+		if o.OID != object.oid {
+			continue
+		}
+
+		if o.Error != nil {
+			return nil, fmt.Errorf("git lfs object %s error: %d %s", o.OID, o.Error.Code, o.Error.Message)
+		}
+
+		if action, ok := o.Actions[operationDownload]; ok && action.Href != "" {
+			return action, nil
+		}
+	}
+
+	return nil, fmt.Errorf("git lfs object %s download action not found", object.oid)
+}
@@ -0,0 +1,245 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package gitlfsprotocol
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-http-utils/headers"
+	"github.com/stretchr/testify/assert"
+
+	commonv1 "d7y.io/api/pkg/apis/common/v1"
+
+	"d7y.io/dragonfly/v2/pkg/digest"
+	"d7y.io/dragonfly/v2/pkg/source"
+)
+
+const testContent = "hello git lfs"
+
+var testOID = digest.SHA256FromStrings(testContent)
+
+func newTestServer(t *testing.T, objectError *batchError) *httptest.Server {
+	var server *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/org/repo.git/info/lfs/objects/batch", func(w http.ResponseWriter, r *http.Request) {
+		assert := assert.New(t)
+		assert.Equal(http.MethodPost, r.Method)
+		assert.Equal(lfsMediaType, r.Header.Get(headers.ContentType))
+		if r.Header.Get(headers.Authorization) != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_ = json.NewEncoder(w).Encode(&batchResponse{Message: "credentials needed"})
+			return
+		}
+
+		var req batchRequest
+		assert.NoError(json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(operationDownload, req.Operation)
+
+		object := batchObject{OID: req.Objects[0].OID, Size: req.Objects[0].Size, Error: objectError}
+		if objectError == nil {
+			object.Actions = map[string]*batchAction{
+				operationDownload: {
+					Href:   server.URL + "/objects/" + object.OID,
+					Header: map[string]string{"X-Object-Token": "object-token"},
+				},
+			}
+		}
+
+		w.Header().Set(headers.ContentType, lfsMediaType)
+		_ = json.NewEncoder(w).Encode(&batchResponse{Transfer: transferBasic, Objects: []batchObject{object}})
+	})
+	mux.HandleFunc("/objects/"+testOID, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "object-token", r.Header.Get("X-Object-Token"))
+		assert.Empty(t, r.Header.Get(headers.Authorization))
+		http.ServeContent(w, r, "", time.Time{}, strings.NewReader(testContent))
+	})
+
+	server = httptest.NewTLSServer(mux)
+	return server
+}
+
+func TestParseURL(t *testing.T) {
+	tests := []struct {
+		name   string
+		url    string
+		expect func(t *testing.T, object *lfsObject, err error)
+	}{
+		{
+			name: "repository without suffix",
+			url:  fmt.Sprintf("lfs://example.com/org/repo/%s?size=13", testOID),
+			expect: func(t *testing.T, object *lfsObject, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(&lfsObject{endpoint: "https://example.com/org/repo.git/info/lfs", oid: testOID, size: 13}, object)
+			},
+		},
+		{
+			name: "repository with suffix",
+			url:  fmt.Sprintf("lfs://example.com/org/repo.git/%s?size=0", testOID),
+			expect: func(t *testing.T, object *lfsObject, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("https://example.com/org/repo.git/info/lfs", object.endpoint)
+			},
+		},
+		{
+			name: "without repository",
+			url:  fmt.Sprintf("lfs://example.com/%s?size=13", testOID),
+			expect: func(t *testing.T, object *lfsObject, err error) {
+				assert.ErrorIs(t, err, ErrInvalidURL)
+			},
+		},
+		{
+			name: "invalid oid",
+			url:  "lfs://example.com/org/repo/foo?size=13",
+			expect: func(t *testing.T, object *lfsObject, err error) {
+				assert.ErrorIs(t, err, ErrInvalidURL)
+			},
+		},
+		{
+			name: "without size",
+			url:  fmt.Sprintf("lfs://example.com/org/repo/%s", testOID),
+			expect: func(t *testing.T, object *lfsObject, err error) {
+				assert.ErrorIs(t, err, ErrInvalidURL)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			u, err := url.Parse(tc.url)
+			assert.NoError(t, err)
+			object, err := parseURL(u)
+			tc.expect(t, object, err)
+		})
+	}
+}
+
+func TestLFSSourceClient_Download(t *testing.T) {
+	tests := []struct {
+		name        string
+		header      map[string]string
+		objectError *batchError
+		expect      func(t *testing.T, resp *source.Response, err error)
+	}{
+		{
+			name:   "download object",
+			header: map[string]string{headers.Authorization: "Bearer token"},
+			expect: func(t *testing.T, resp *source.Response, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				data, err := io.ReadAll(resp.Body)
+				assert.NoError(err)
+				assert.Equal(testContent, string(data))
+			},
+		},
+		{
+			name:   "download object with range",
+			header: map[string]string{headers.Authorization: "Bearer token", source.Range: "6-8"},
+			expect: func(t *testing.T, resp *source.Response, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(http.StatusPartialContent, resp.StatusCode)
+				data, err := io.ReadAll(resp.Body)
+				assert.NoError(err)
+				assert.Equal("git", string(data))
+			},
+		},
+		{
+			name: "unauthorized",
+			expect: func(t *testing.T, resp *source.Response, err error) {
+				assert := assert.New(t)
+				var statusErr source.UnexpectedStatusCodeError
+				assert.True(errors.As(err, &statusErr))
+				assert.Equal(http.StatusUnauthorized, statusErr.Got())
+				assert.Contains(err.Error(), "credentials needed")
+			},
+		},
+		{
+			name:        "object not found",
+			header:      map[string]string{headers.Authorization: "Bearer token"},
+			objectError: &batchError{Code: http.StatusNotFound, Message: "object does not exist"},
+			expect: func(t *testing.T, resp *source.Response, err error) {
+				assert.ErrorContains(t, err, "object does not exist")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newTestServer(t, tc.objectError)
+			defer server.Close()
+
+			client := newLFSSourceClient(WithHTTPClient(server.Client()))
+			request, err := source.NewRequestWithHeader(
+				fmt.Sprintf("lfs://%s/org/repo/%s?size=%d", server.Listener.Addr(), testOID, len(testContent)), tc.header)
+			assert.NoError(t, err)
+
+			resp, err := client.Download(request)
+			if resp != nil {
+				defer resp.Body.Close()
+			}
+			tc.expect(t, resp, err)
+		})
+	}
+}
+
+func TestLFSSourceClient_Metadata(t *testing.T) {
+	assert := assert.New(t)
+	server := newTestServer(t, nil)
+	defer server.Close()
+
+	client := newLFSSourceClient(WithHTTPClient(server.Client()))
+	request, err := source.NewRequestWithHeader(
+		fmt.Sprintf("lfs://%s/org/repo/%s?size=%d", server.Listener.Addr(), testOID, len(testContent)),
+		map[string]string{headers.Authorization: "Bearer token"})
+	assert.NoError(err)
+
+	length, err := client.GetContentLength(request)
+	assert.NoError(err)
+	assert.EqualValues(len(testContent), length)
+
+	support, err := client.IsSupportRange(request)
+	assert.NoError(err)
+	assert.True(support)
+
+	expired, err := client.IsExpired(request, nil)
+	assert.NoError(err)
+	assert.False(expired)
+}
+
+func TestDirector(t *testing.T) {
+	u, err := url.Parse(fmt.Sprintf("lfs://example.com/org/repo/%s?size=13", testOID))
+	assert.NoError(t, err)
+
+	urlMeta := &commonv1.UrlMeta{}
+	assert.NoError(t, Director(u, urlMeta))
+	assert.Equal(t, "sha256:"+testOID, urlMeta.Digest)
+
+	urlMeta = &commonv1.UrlMeta{Range: "0-9"}
+	assert.NoError(t, Director(u, urlMeta))
+	assert.Empty(t, urlMeta.Digest)
+}
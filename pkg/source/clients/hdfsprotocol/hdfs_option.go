@@ -0,0 +1,174 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hdfsprotocol
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/user"
+	"strings"
+
+	"github.com/colinmarc/hdfs/v2"
+	krb "github.com/jcmturner/gokrb5/v8/client"
+	krbconfig "github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/credentials"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// defaultKerberosConfigPath is the default path of krb5.conf.
+	defaultKerberosConfigPath = "/etc/krb5.conf"
+
+	// kerberosConfigEnv is the environment variable of krb5.conf path.
+	kerberosConfigEnv = "KRB5_CONFIG"
+
+	// kerberosCCacheEnv is the environment variable of credential cache path.
+	kerberosCCacheEnv = "KRB5CCNAME"
+)
+
+// hdfsOption is the option of hdfs source client, it is configured in download.resourceClients.hdfs.
+type hdfsOption struct {
+	// User is the hdfs user, default is the current user of system.
+	// When kerberos is enabled, default is the user of kerberos credentials.
+	User string `yaml:"user"`
+
+	// DataTransferProtection is the protection level of communicating with datanodes,
+	// the value is authentication, integrity or privacy as dfs.data.transfer.protection.
+	DataTransferProtection string `yaml:"dataTransferProtection"`
+
+	// Kerberos is the option of kerberos authentication.
+	Kerberos kerberosOption `yaml:"kerberos"`
+}
+
+// kerberosOption is the option of kerberos authentication for kerberized hdfs.
+type kerberosOption struct {
+	// Enable kerberos authentication.
+	Enable bool `yaml:"enable"`
+
+	// ConfigPath is the path of krb5.conf, default is $KRB5_CONFIG or /etc/krb5.conf.
+	ConfigPath string `yaml:"configPath"`
+
+	// ServicePrincipalName is the service principal name of namenodes as dfs.namenode.kerberos.principal,
+	// e.g. nn/_HOST, _HOST is replaced by the address of namenode.
+	ServicePrincipalName string `yaml:"servicePrincipalName"`
+
+	// Username is the principal name to login with keytab.
+	Username string `yaml:"username"`
+
+	// Realm is the realm of principal to login with keytab.
+	Realm string `yaml:"realm"`
+
+	// KeytabPath is the path of keytab, the credential cache is used when it is empty.
+	KeytabPath string `yaml:"keytabPath"`
+
+	// CCachePath is the path of credential cache, default is $KRB5CCNAME or /tmp/krb5cc_<uid>.
+	CCachePath string `yaml:"ccachePath"`
+}
+
+// parseHDFSOption parses the option of hdfs source client from yaml.
+func parseHDFSOption(optionYaml []byte) (*hdfsOption, error) {
+	opt := &hdfsOption{}
+	if err := yaml.Unmarshal(optionYaml, opt); err != nil {
+		return nil, err
+	}
+
+	switch opt.DataTransferProtection {
+	case "", hdfs.DataTransferProtectionAuthentication, hdfs.DataTransferProtectionIntegrity, hdfs.DataTransferProtectionPrivacy:
+	default:
+		return nil, fmt.Errorf("invalid data transfer protection %s", opt.DataTransferProtection)
+	}
+
+	if !opt.Kerberos.Enable {
+		return opt, nil
+	}
+
+	if opt.Kerberos.ServicePrincipalName == "" {
+		return nil, errors.New("kerberos requires parameter servicePrincipalName")
+	}
+
+	if opt.Kerberos.KeytabPath != "" && (opt.Kerberos.Username == "" || opt.Kerberos.Realm == "") {
+		return nil, errors.New("kerberos keytab requires parameter username and realm")
+	}
+
+	return opt, nil
+}
+
+// newKerberosClient creates kerberos client with keytab or credential cache,
+// it logins lazily when connecting to namenodes and renews the session automatically.
+func newKerberosClient(opt *kerberosOption) (*krb.Client, error) {
+	configPath := opt.ConfigPath
+	if configPath == "" {
+		configPath = os.Getenv(kerberosConfigEnv)
+	}
+	if configPath == "" {
+		configPath = defaultKerberosConfigPath
+	}
+
+	cfg, err := krbconfig.Load(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("load kerberos config %s error: %w", configPath, err)
+	}
+
+	if opt.KeytabPath != "" {
+		kt, err := keytab.Load(opt.KeytabPath)
+		if err != nil {
+			return nil, fmt.Errorf("load kerberos keytab %s error: %w", opt.KeytabPath, err)
+		}
+
+		return krb.NewWithKeytab(opt.Username, opt.Realm, kt, cfg, krb.DisablePAFXFAST(true)), nil
+	}
+
+	ccachePath, err := kerberosCCachePath(opt.CCachePath)
+	if err != nil {
+		return nil, err
+	}
+
+	ccache, err := credentials.LoadCCache(ccachePath)
+	if err != nil {
+		return nil, fmt.Errorf("load kerberos ccache %s error: %w", ccachePath, err)
+	}
+
+	return krb.NewFromCCache(ccache, cfg, krb.DisablePAFXFAST(true))
+}
+
+// kerberosCCachePath returns the path of credential cache, only the file credential cache is supported.
+func kerberosCCachePath(ccachePath string) (string, error) {
+	if ccachePath == "" {
+		ccachePath = os.Getenv(kerberosCCacheEnv)
+	}
+
+	if strings.Contains(ccachePath, ":") {
+		if !strings.HasPrefix(ccachePath, "FILE:") {
+			return "", fmt.Errorf("unusable kerberos ccache %s", ccachePath)
+		}
+
+		return strings.SplitN(ccachePath, ":", 2)[1], nil
+	}
+
+	if ccachePath == "" {
+		u, err := user.Current()
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("/tmp/krb5cc_%s", u.Uid), nil
+	}
+
+	return ccachePath, nil
+}
@@ -0,0 +1,193 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package hdfsprotocol
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jcmturner/gokrb5/v8/iana/etypeID"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/stretchr/testify/assert"
+)
+
+const testKerberosConfig = `[libdefaults]
+  default_realm = EXAMPLE.COM
+[realms]
+  EXAMPLE.COM = {
+    kdc = kdc.example.com:88
+  }
+`
+
+func TestParseHDFSOption(t *testing.T) {
+	tests := []struct {
+		name   string
+		yaml   string
+		expect func(t *testing.T, opt *hdfsOption, err error)
+	}{
+		{
+			name: "empty option",
+			yaml: "",
+			expect: func(t *testing.T, opt *hdfsOption, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.EqualValues(&hdfsOption{}, opt)
+			},
+		},
+		{
+			name: "kerberos with keytab",
+			yaml: `
+user: hadoop
+dataTransferProtection: privacy
+kerberos:
+  enable: true
+  servicePrincipalName: nn/_HOST
+  username: dragonfly
+  realm: EXAMPLE.COM
+  keytabPath: /etc/dragonfly.keytab
+`,
+			expect: func(t *testing.T, opt *hdfsOption, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.EqualValues(&hdfsOption{
+					User:                   "hadoop",
+					DataTransferProtection: "privacy",
+					Kerberos: kerberosOption{
+						Enable:               true,
+						ServicePrincipalName: "nn/_HOST",
+						Username:             "dragonfly",
+						Realm:                "EXAMPLE.COM",
+						KeytabPath:           "/etc/dragonfly.keytab",
+					},
+				}, opt)
+			},
+		},
+		{
+			name: "invalid data transfer protection",
+			yaml: "dataTransferProtection: foo",
+			expect: func(t *testing.T, opt *hdfsOption, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "invalid data transfer protection foo")
+			},
+		},
+		{
+			name: "kerberos without service principal name",
+			yaml: "kerberos: {enable: true}",
+			expect: func(t *testing.T, opt *hdfsOption, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "kerberos requires parameter servicePrincipalName")
+			},
+		},
+		{
+			name: "kerberos keytab without username",
+			yaml: "kerberos: {enable: true, servicePrincipalName: nn/_HOST, keytabPath: /etc/dragonfly.keytab}",
+			expect: func(t *testing.T, opt *hdfsOption, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "kerberos keytab requires parameter username and realm")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			opt, err := parseHDFSOption([]byte(tc.yaml))
+			tc.expect(t, opt, err)
+		})
+	}
+}
+
+func TestNewKerberosClient(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "krb5.conf")
+	if err := os.WriteFile(configPath, []byte(testKerberosConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	kt := keytab.New()
+	if err := kt.AddEntry("dragonfly", "EXAMPLE.COM", "password", time.Now(), 1, etypeID.AES256_CTS_HMAC_SHA1_96); err != nil {
+		t.Fatal(err)
+	}
+	data, err := kt.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keytabPath := filepath.Join(dir, "dragonfly.keytab")
+	if err := os.WriteFile(keytabPath, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		opt    *kerberosOption
+		expect func(t *testing.T, err error)
+	}{
+		{
+			name: "keytab",
+			opt: &kerberosOption{
+				ConfigPath: configPath,
+				Username:   "dragonfly",
+				Realm:      "EXAMPLE.COM",
+				KeytabPath: keytabPath,
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+			},
+		},
+		{
+			name: "config not found",
+			opt: &kerberosOption{
+				ConfigPath: filepath.Join(dir, "foo"),
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.ErrorContains(err, "load kerberos config")
+			},
+		},
+		{
+			name: "ccache not found",
+			opt: &kerberosOption{
+				ConfigPath: configPath,
+				CCachePath: "FILE:" + filepath.Join(dir, "foo"),
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.ErrorContains(err, "load kerberos ccache")
+			},
+		},
+		{
+			name: "unusable ccache",
+			opt: &kerberosOption{
+				ConfigPath: configPath,
+				CCachePath: "KEYRING:persistent:1000",
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "unusable kerberos ccache KEYRING:persistent:1000")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := newKerberosClient(tc.opt)
+			tc.expect(t, err)
+		})
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/colinmarc/hdfs/v2"
+	krb "github.com/jcmturner/gokrb5/v8/client"
 
 	"d7y.io/dragonfly/v2/pkg/net/http"
 	"d7y.io/dragonfly/v2/pkg/source"
@@ -48,7 +49,26 @@ func init() {
 }
 
 func Builder(optionYaml []byte) (source.ResourceClient, source.RequestAdapter, []source.Hook, error) {
-	return NewHDFSSourceClient(), adapter, nil, nil
+	opt, err := parseHDFSOption(optionYaml)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	opts := []HDFSSourceClientOption{
+		WithUser(opt.User),
+		WithDataTransferProtection(opt.DataTransferProtection),
+	}
+
+	if opt.Kerberos.Enable {
+		kerberosClient, err := newKerberosClient(&opt.Kerberos)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		opts = append(opts, WithKerberos(kerberosClient, opt.Kerberos.ServicePrincipalName))
+	}
+
+	return NewHDFSSourceClient(opts...), adapter, nil, nil
 }
 
 func adapter(request *source.Request) *source.Request {
@@ -60,6 +80,15 @@ func adapter(request *source.Request) *source.Request {
 type hdfsSourceClient struct {
 	sync.RWMutex
 	clientMap map[string]*hdfs.Client
+
+	// user is the hdfs user, default is the current user of system
+	user string
+	// dataTransferProtection is the protection level of communicating with datanodes
+	dataTransferProtection string
+	// kerberosClient is used to connect to kerberized hdfs clusters
+	kerberosClient *krb.Client
+	// kerberosServicePrincipalName is the service principal name of namenodes
+	kerberosServicePrincipalName string
 }
 
 // hdfsFileReaderClose is a combination object of the  io.LimitedReader and io.Closer
@@ -77,6 +106,28 @@ func newHdfsFileReaderClose(r io.ReadCloser, n int64) io.ReadCloser {
 
 type HDFSSourceClientOption func(p *hdfsSourceClient)
 
+// WithUser sets the hdfs user.
+func WithUser(user string) HDFSSourceClientOption {
+	return func(p *hdfsSourceClient) {
+		p.user = user
+	}
+}
+
+// WithDataTransferProtection sets the protection level of communicating with datanodes.
+func WithDataTransferProtection(protection string) HDFSSourceClientOption {
+	return func(p *hdfsSourceClient) {
+		p.dataTransferProtection = protection
+	}
+}
+
+// WithKerberos sets the kerberos client and the service principal name of namenodes.
+func WithKerberos(client *krb.Client, servicePrincipalName string) HDFSSourceClientOption {
+	return func(p *hdfsSourceClient) {
+		p.kerberosClient = client
+		p.kerberosServicePrincipalName = servicePrincipalName
+	}
+}
+
 func (h *hdfsSourceClient) GetContentLength(request *source.Request) (int64, error) {
 	hdfsClient, path, err := h.getHDFSClientAndPath(request.URL)
 	if err != nil {
@@ -191,14 +242,26 @@ func (h *hdfsSourceClient) getHDFSClient(url *url.URL) (*hdfs.Client, error) {
 		options.Addresses = []string{url.Host + nameNodeDefaultPort}
 	}
 
-	u, err := user.Current()
-	if err != nil {
-		return nil, err
+	options.DataTransferProtection = h.dataTransferProtection
+	options.User = h.user
+	if h.kerberosClient != nil {
+		// user is determined from the kerberos credentials if it is empty
+		options.KerberosClient = h.kerberosClient
+		options.KerberosServicePrincipleName = h.kerberosServicePrincipalName
+	} else if options.User == "" {
+		u, err := user.Current()
+		if err != nil {
+			return nil, err
+		}
+		options.User = u.Username
 	}
-	options.User = u.Username
 
 	// create hdfs client and put map
 	h.RWMutex.Lock()
+	if client, ok := h.clientMap[url.Host]; ok {
+		h.RWMutex.Unlock()
+		return client, nil
+	}
 	client, err := hdfs.NewClient(options)
 	if err != nil {
 		h.RWMutex.Unlock()
@@ -47,6 +47,10 @@ const (
 	sessionToken = "awsSessionToken"
 
 	forcePathStyle = "awsS3ForcePathStyle"
+	// AWS S3 Transfer Acceleration, the bucket must enable it and the endpoint must be empty
+	useAccelerate = "awsS3UseAccelerate"
+	// AWS S3 Requester Pays, the value is requester, it is required by the bucket enables requester pays
+	requestPayer = "awsRequestPayer"
 )
 
 var _ source.ResourceClient = (*s3SourceClient)(nil)
@@ -88,10 +92,21 @@ func (s *s3SourceClient) newAWSS3Client(request *source.Request) (*s3.S3, error)
 	if pathStyle := request.Header.Get(forcePathStyle); strings.ToLower(pathStyle) == "true" {
 		opts = append(opts, cfg.WithS3ForcePathStyle(true))
 	}
+	if accelerate := request.Header.Get(useAccelerate); strings.ToLower(accelerate) == "true" {
+		opts = append(opts, cfg.WithS3UseAccelerate(true))
+	}
 
 	return s3.New(session, opts...), nil
 }
 
+// requestPayer returns the requester pays option of request, it is nil when requester pays is not confirmed.
+func (s *s3SourceClient) requestPayer(request *source.Request) *string {
+	if payer := request.Header.Get(requestPayer); strings.ToLower(payer) == s3.RequestPayerRequester {
+		return aws.String(s3.RequestPayerRequester)
+	}
+	return nil
+}
+
 // GetContentLength get length of resource content
 // return source.UnknownSourceFileLen if response status is not StatusOK and StatusPartialContent
 func (s *s3SourceClient) GetContentLength(request *source.Request) (int64, error) {
@@ -101,9 +116,10 @@ func (s *s3SourceClient) GetContentLength(request *source.Request) (int64, error
 	}
 	resp, err := client.HeadObjectWithContext(request.Context(),
 		&s3.HeadObjectInput{
-			Bucket: aws.String(request.URL.Host),
-			Key:    aws.String(request.URL.Path),
-			Range:  aws.String(request.Header.Get(headers.Range)),
+			Bucket:       aws.String(request.URL.Host),
+			Key:          aws.String(request.URL.Path),
+			Range:        aws.String(request.Header.Get(headers.Range)),
+			RequestPayer: s.requestPayer(request),
 		})
 	if err != nil {
 		return -1, err
@@ -137,7 +153,8 @@ func (s *s3SourceClient) Download(request *source.Request) (*source.Response, er
 			Bucket: aws.String(request.URL.Host),
 			Key:    aws.String(request.URL.Path),
 			// TODO more header pass to GetObjectInput
-			Range: aws.String(request.Header.Get(headers.Range)),
+			Range:        aws.String(request.Header.Get(headers.Range)),
+			RequestPayer: s.requestPayer(request),
 		})
 
 	if err != nil {
@@ -178,8 +195,9 @@ func (s *s3SourceClient) GetLastModified(request *source.Request) (int64, error)
 		return -1, err
 	}
 	resp, err := client.HeadObjectWithContext(request.Context(), &s3.HeadObjectInput{
-		Bucket: aws.String(request.URL.Host),
-		Key:    aws.String(request.URL.Path),
+		Bucket:       aws.String(request.URL.Host),
+		Key:          aws.String(request.URL.Path),
+		RequestPayer: s.requestPayer(request),
 	})
 	if err != nil {
 		return -1, err
@@ -216,6 +234,7 @@ func (s *s3SourceClient) List(request *source.Request) (urls []source.URLEntry,
 				MaxKeys:           aws.Int64(1000),
 				ContinuationToken: continuationToken,
 				Delimiter:         &delimiter,
+				RequestPayer:      s.requestPayer(request),
 			})
 		if err != nil {
 			return urls, fmt.Errorf("list s3 object %s/%s: %w", request.URL.Host, path, err)
@@ -249,10 +268,11 @@ func (s *s3SourceClient) isDirectory(client *s3.S3, request *source.Request) (bo
 	output, err := client.ListObjectsV2WithContext(
 		request.Context(),
 		&s3.ListObjectsV2Input{
-			Bucket:    aws.String(request.URL.Host),
-			Prefix:    aws.String(uPath),
-			MaxKeys:   aws.Int64(1),
-			Delimiter: &delimiter,
+			Bucket:       aws.String(request.URL.Host),
+			Prefix:       aws.String(uPath),
+			MaxKeys:      aws.Int64(1),
+			Delimiter:    &delimiter,
+			RequestPayer: s.requestPayer(request),
 		})
 	if err != nil {
 		return false, fmt.Errorf("list oss object %s/%s: %w", request.URL.Host, uPath, err)
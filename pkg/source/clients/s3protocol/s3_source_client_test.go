@@ -0,0 +1,83 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package s3protocol
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/pkg/source"
+)
+
+func TestS3SourceClient_RequestPayer(t *testing.T) {
+	tests := []struct {
+		name   string
+		header map[string]string
+		expect string
+	}{
+		{
+			name:   "requester pays",
+			header: map[string]string{requestPayer: "requester"},
+			expect: "requester",
+		},
+		{
+			name:   "requester pays with uppercase",
+			header: map[string]string{requestPayer: "Requester"},
+			expect: "requester",
+		},
+		{
+			name:   "without requester pays",
+			header: map[string]string{},
+			expect: "",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal("/bucket/key", r.URL.Path)
+				assert.Equal(tc.expect, r.Header.Get("x-amz-request-payer"))
+				_, _ = w.Write([]byte("data"))
+			}))
+			defer server.Close()
+
+			tc.header[endpoint] = server.URL
+			tc.header[region] = "us-east-1"
+			tc.header[forcePathStyle] = "true"
+			tc.header[accessKeyID] = "ak"
+			tc.header[secretAccessKey] = "sk"
+			request, err := source.NewRequestWithHeader("s3://bucket/key", tc.header)
+			assert.NoError(err)
+
+			client := &s3SourceClient{}
+			resp, err := client.Download(client.adaptor(request))
+			if !assert.NoError(err) {
+				return
+			}
+			defer resp.Body.Close()
+
+			data, err := io.ReadAll(resp.Body)
+			assert.NoError(err)
+			assert.Equal("data", string(data))
+		})
+	}
+}
@@ -18,8 +18,37 @@ package structure
 
 import (
 	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
 )
 
+const (
+	// DiffTypeAdded is the type of diff that the path is added.
+	DiffTypeAdded = "added"
+
+	// DiffTypeRemoved is the type of diff that the path is removed.
+	DiffTypeRemoved = "removed"
+
+	// DiffTypeChanged is the type of diff that the value of path is changed.
+	DiffTypeChanged = "changed"
+)
+
+// Diff is the difference of a path between two maps.
+type Diff struct {
+	// Path is the dot separated keys of the value.
+	Path string `json:"path"`
+
+	// Type is the type of diff.
+	Type string `json:"type"`
+
+	// From is the value in the source map.
+	From any `json:"from"`
+
+	// To is the value in the target map.
+	To any `json:"to"`
+}
+
 // StructToMap coverts struct to map.
 func StructToMap(t any) (map[string]any, error) {
 	var m map[string]any
@@ -52,3 +81,49 @@ func MapToStruct(m map[string]any, t any) error {
 
 	return nil
 }
+
+// DiffMap compares the leaves of nested maps and returns the differences sorted by path,
+// slices are compared as a whole value.
+func DiffMap(from, to map[string]any) []Diff {
+	fromLeaves, toLeaves := map[string]any{}, map[string]any{}
+	flattenMap(nil, from, fromLeaves)
+	flattenMap(nil, to, toLeaves)
+
+	diffs := []Diff{}
+	for path, fromValue := range fromLeaves {
+		toValue, ok := toLeaves[path]
+		if !ok {
+			diffs = append(diffs, Diff{Path: path, Type: DiffTypeRemoved, From: fromValue})
+			continue
+		}
+
+		if !reflect.DeepEqual(fromValue, toValue) {
+			diffs = append(diffs, Diff{Path: path, Type: DiffTypeChanged, From: fromValue, To: toValue})
+		}
+	}
+
+	for path, toValue := range toLeaves {
+		if _, ok := fromLeaves[path]; !ok {
+			diffs = append(diffs, Diff{Path: path, Type: DiffTypeAdded, To: toValue})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Path < diffs[j].Path
+	})
+
+	return diffs
+}
+
+// flattenMap collects the leaves of nested map by the dot separated path.
+func flattenMap(prefix []string, m map[string]any, leaves map[string]any) {
+	for key, value := range m {
+		path := append(append([]string{}, prefix...), key)
+		if nested, ok := value.(map[string]any); ok && len(nested) > 0 {
+			flattenMap(path, nested, leaves)
+			continue
+		}
+
+		leaves[strings.Join(path, ".")] = value
+	}
+}
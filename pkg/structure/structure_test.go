@@ -124,3 +124,58 @@ func TestMapToStruct(t *testing.T) {
 		})
 	}
 }
+
+func TestDiffMap(t *testing.T) {
+	tests := []struct {
+		name   string
+		from   map[string]any
+		to     map[string]any
+		expect func(*testing.T, []Diff)
+	}{
+		{
+			name: "maps are equal",
+			from: map[string]any{"foo": "bar", "baz": map[string]any{"qux": float64(1)}},
+			to:   map[string]any{"foo": "bar", "baz": map[string]any{"qux": float64(1)}},
+			expect: func(t *testing.T, diffs []Diff) {
+				assert := assert.New(t)
+				assert.Empty(diffs)
+			},
+		},
+		{
+			name: "diff nested maps",
+			from: map[string]any{
+				"config": map[string]any{"retry_limit": float64(10), "trainer_addrs": []any{"a"}},
+				"scopes": map[string]any{"idc": "foo"},
+			},
+			to: map[string]any{
+				"config": map[string]any{"retry_limit": float64(20), "trainer_addrs": []any{"a", "b"}, "filter_parent_limit": float64(40)},
+				"scopes": map[string]any{},
+			},
+			expect: func(t *testing.T, diffs []Diff) {
+				assert := assert.New(t)
+				assert.Equal([]Diff{
+					{Path: "config.filter_parent_limit", Type: DiffTypeAdded, To: float64(40)},
+					{Path: "config.retry_limit", Type: DiffTypeChanged, From: float64(10), To: float64(20)},
+					{Path: "config.trainer_addrs", Type: DiffTypeChanged, From: []any{"a"}, To: []any{"a", "b"}},
+					{Path: "scopes", Type: DiffTypeAdded, To: map[string]any{}},
+					{Path: "scopes.idc", Type: DiffTypeRemoved, From: "foo"},
+				}, diffs)
+			},
+		},
+		{
+			name: "diff nil maps",
+			from: nil,
+			to:   map[string]any{"foo": "bar"},
+			expect: func(t *testing.T, diffs []Diff) {
+				assert := assert.New(t)
+				assert.Equal([]Diff{{Path: "foo", Type: DiffTypeAdded, To: "bar"}}, diffs)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expect(t, DiffMap(tc.from, tc.to))
+		})
+	}
+}
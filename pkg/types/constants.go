@@ -59,6 +59,16 @@ const (
 	TrainerMetricsName = "trainer"
 )
 
+const (
+	// PriorityClassMetadataKey is the grpc metadata key of the priority class of task,
+	// which is sent by dfdaemon when registering peer to scheduler.
+	PriorityClassMetadataKey = "d7y-priority-class"
+
+	// PieceLengthMetadataKey is the grpc metadata key of the preferred piece length of task,
+	// which is sent by dfdaemon when registering peer, and by scheduler when triggering seed peer.
+	PieceLengthMetadataKey = "d7y-piece-length"
+)
+
 const (
 	// AffinitySeparator is separator of affinity.
 	AffinitySeparator = "|"
@@ -136,6 +136,56 @@ func ParseHostType(name string) HostType {
 	return HostTypeNormal
 }
 
+// PriorityClass is the scheduling priority class of task, the higher class
+// can use the upload of parents reserved from the lower classes.
+type PriorityClass int
+
+const (
+	// PriorityClassBestEffort is the best-effort priority class of task, e.g. prefetch.
+	PriorityClassBestEffort PriorityClass = iota - 1
+
+	// PriorityClassNormal is the normal priority class of task.
+	PriorityClassNormal
+
+	// PriorityClassCritical is the critical priority class of task, e.g. image pulls.
+	PriorityClassCritical
+)
+
+const (
+	// PriorityClassBestEffortName is the name of best-effort priority class.
+	PriorityClassBestEffortName = "best-effort"
+
+	// PriorityClassNormalName is the name of normal priority class.
+	PriorityClassNormalName = "normal"
+
+	// PriorityClassCriticalName is the name of critical priority class.
+	PriorityClassCriticalName = "critical"
+)
+
+// Name returns the name of priority class.
+func (p PriorityClass) Name() string {
+	switch p {
+	case PriorityClassBestEffort:
+		return PriorityClassBestEffortName
+	case PriorityClassCritical:
+		return PriorityClassCriticalName
+	}
+
+	return PriorityClassNormalName
+}
+
+// ParsePriorityClass parses priority class by name.
+func ParsePriorityClass(name string) PriorityClass {
+	switch name {
+	case PriorityClassBestEffortName:
+		return PriorityClassBestEffort
+	case PriorityClassCriticalName:
+		return PriorityClassCritical
+	}
+
+	return PriorityClassNormal
+}
+
 // TaskTypeV1ToV2 converts task type from v1 to v2.
 func TaskTypeV1ToV2(typ commonv1.TaskType) commonv2.TaskType {
 	switch typ {
@@ -0,0 +1,58 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package announcer
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc/metadata"
+
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+const (
+	// AdvertiseIPv4MetadataKey is the grpc metadata key of the ipv4 advertise ip of scheduler.
+	AdvertiseIPv4MetadataKey = types.SchedulerAdvertiseIPv4MetadataKey
+
+	// AdvertiseIPv6MetadataKey is the grpc metadata key of the ipv6 advertise ip of scheduler.
+	AdvertiseIPv6MetadataKey = types.SchedulerAdvertiseIPv6MetadataKey
+)
+
+// advertiseIPsContext appends the advertise ips of each address family to the grpc metadata of ctx,
+// because the manager API carries only one advertise ip, which can not describe dual-stack hosts.
+// Manager persists them and returns the ip in the address family of peer when listing schedulers.
+func (a *announcer) advertiseIPsContext(ctx context.Context) context.Context {
+	var kv []string
+	for _, ip := range []net.IP{a.config.Server.AdvertiseIP, a.config.Server.SecondaryAdvertiseIP} {
+		if ip == nil {
+			continue
+		}
+
+		if ip.To4() != nil {
+			kv = append(kv, AdvertiseIPv4MetadataKey, ip.String())
+		} else {
+			kv = append(kv, AdvertiseIPv6MetadataKey, ip.String())
+		}
+	}
+
+	if len(kv) == 0 {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, kv...)
+}
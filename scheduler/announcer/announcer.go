@@ -20,27 +20,130 @@ package announcer
 
 import (
 	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand"
+	"net"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
-	"golang.org/x/sync/errgroup"
+	"github.com/hashicorp/go-multierror"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	_ "google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
 
 	managerv2 "d7y.io/api/pkg/apis/manager/v2"
 	trainerv1 "d7y.io/api/pkg/apis/trainer/v1"
 
 	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/manager/types"
+	"d7y.io/dragonfly/v2/pkg/net/fqdn"
+	"d7y.io/dragonfly/v2/pkg/retry"
 	managerclient "d7y.io/dragonfly/v2/pkg/rpc/manager/client"
 	trainerclient "d7y.io/dragonfly/v2/pkg/rpc/trainer/client"
 	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/metrics"
 	"d7y.io/dragonfly/v2/scheduler/storage"
 )
 
 const (
 	// UploadBufferSize is the buffer size for upload.
 	UploadBufferSize = 1024 * 1024
+
+	// messageHeadroom is the reserved size of message for the tags and length prefixes of dataset.
+	messageHeadroom = 16
+
+	// TrainDownloadBytesMetadataKey is the grpc metadata key of the download bytes sent in the last training cycle.
+	TrainDownloadBytesMetadataKey = types.SchedulerTrainDownloadBytesMetadataKey
+
+	// TrainNetworkTopologyBytesMetadataKey is the grpc metadata key of the network topology bytes sent in the last training cycle.
+	TrainNetworkTopologyBytesMetadataKey = types.SchedulerTrainNetworkTopologyBytesMetadataKey
+
+	// TrainBudgetLimitedMetadataKey is the grpc metadata key of whether the last training cycle is limited by budget.
+	TrainBudgetLimitedMetadataKey = types.SchedulerTrainBudgetLimitedMetadataKey
+
+	// TrainDownloadSucceededMetadataKey is the grpc metadata key of whether download is uploaded in the last training cycle.
+	TrainDownloadSucceededMetadataKey = types.SchedulerTrainDownloadSucceededMetadataKey
+
+	// TrainNetworkTopologySucceededMetadataKey is the grpc metadata key of whether network topology is uploaded in the last training cycle.
+	TrainNetworkTopologySucceededMetadataKey = types.SchedulerTrainNetworkTopologySucceededMetadataKey
+
+	// ReportTrainResultTimeout is the timeout of reporting the train result to manager.
+	ReportTrainResultTimeout = 10 * time.Second
+
+	// DefaultStopTimeout is the default timeout of waiting for announcer to exit.
+	DefaultStopTimeout = 30 * time.Second
+
+	// DefaultUpdateSchedulerMaxAttempts is the default max attempts of registering scheduler to manager.
+	DefaultUpdateSchedulerMaxAttempts = 3
+
+	// DefaultUpdateSchedulerInitBackoff is the default initial backoff of registering scheduler to manager.
+	DefaultUpdateSchedulerInitBackoff = 500 * time.Millisecond
+
+	// DefaultUpdateSchedulerMaxBackoff is the default max backoff of registering scheduler to manager.
+	DefaultUpdateSchedulerMaxBackoff = 5 * time.Second
+
+	// DefaultTrainerPreflightTimeout is the default timeout of checking trainer is reachable in New.
+	DefaultTrainerPreflightTimeout = 5 * time.Second
 )
 
+// ErrTrainerMisconfigured is returned when trainer is enabled but its configuration is invalid.
+var ErrTrainerMisconfigured = errors.New("trainer is misconfigured")
+
+// ErrStorageRequired is returned when trainer is configured without storage.
+var ErrStorageRequired = errors.New("storage is required by trainer")
+
+// ErrTrainStreamOpenTimeout is returned when the train stream is not opened within the stream open timeout.
+var ErrTrainStreamOpenTimeout = errors.New("open train stream timeout")
+
+// ErrTrainUnconfirmed is returned when all data is sent to trainer but the train stream is not confirmed.
+var ErrTrainUnconfirmed = errors.New("train stream is sent but unconfirmed")
+
+// ErrKeepAliveFailed is returned when keepalive to manager exceeds the max consecutive failures.
+var ErrKeepAliveFailed = errors.New("keepalive to manager failed")
+
+// ErrNotRegistered is returned when scheduler is not registered to manager.
+var ErrNotRegistered = errors.New("scheduler is not registered to manager")
+
+// ErrCycleBudgetExceeded is returned when the train stream is aborted because the budget of training cycle is exceeded.
+var ErrCycleBudgetExceeded = errors.New("budget of training cycle is exceeded")
+
+// TrainerConfigError describes the invalid trainer configuration field.
+type TrainerConfigError struct {
+	// Field is the name of the invalid field.
+	Field string
+
+	// Value is the invalid value of the field.
+	Value string
+
+	// Err is the underlying cause.
+	Err error
+}
+
+// Error returns the error message.
+func (e *TrainerConfigError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: invalid trainer.%s %q: %s", ErrTrainerMisconfigured, e.Field, e.Value, e.Err)
+	}
+
+	return fmt.Sprintf("%s: invalid trainer.%s %q", ErrTrainerMisconfigured, e.Field, e.Value)
+}
+
+// Unwrap returns ErrTrainerMisconfigured, so that errors.Is works with the sentinel error.
+func (e *TrainerConfigError) Unwrap() error {
+	return ErrTrainerMisconfigured
+}
+
 // Announcer is the interface used for announce service.
 type Announcer interface {
 	// Started announcer server.
@@ -48,15 +151,199 @@ type Announcer interface {
 
 	// Stop announcer server.
 	Stop() error
+
+	// StopContext stops announcer server and waits for the in-flight training cycle and
+	// keepalive to exit, it returns the error of ctx if ctx is done before they exit.
+	StopContext(ctx context.Context) error
+
+	// Subscribe returns a channel receiving the lifecycle events published after subscribing,
+	// events are dropped if the channel is full. The channel is closed when announcer stops.
+	Subscribe() <-chan Event
+
+	// ListTrainerEndpoints returns the trainer endpoints discovered from manager,
+	// it falls back to the configured trainer address if manager returns none.
+	ListTrainerEndpoints(context.Context) ([]string, error)
+
+	// EffectiveKeepAliveInterval returns the keepalive interval applied to manager,
+	// it returns zero if keepalive is not started.
+	EffectiveKeepAliveInterval() time.Duration
+
+	// Status returns the status of announcer.
+	Status() Status
+
+	// AdvertiseAddr returns the advertise ip sent to manager in the last successful registration,
+	// it returns ErrNotRegistered if scheduler is not registered.
+	AdvertiseAddr() (net.IP, error)
+
+	// Err returns a channel receiving the unrecoverable errors of announcer, e.g. keepalive to
+	// manager exceeds the max consecutive failures, so that the caller can decide to exit.
+	// The errors are dropped if the channel is full, and the channel is never closed.
+	Err() <-chan error
+}
+
+// TrainResult is the result of a training cycle, it is reported to manager as grpc
+// metadata of UpdateScheduler, because the manager API has no fields of it. The manager
+// persists it in the train columns of scheduler.
+type TrainResult struct {
+	// DownloadBytes is the bytes of download sent to trainer.
+	DownloadBytes int64
+
+	// NetworkTopologyBytes is the bytes of network topology sent to trainer.
+	NetworkTopologyBytes int64
+
+	// BudgetLimited is whether uploading is stopped by the duration or byte budget of training cycle.
+	BudgetLimited bool
+
+	// DownloadSucceeded is whether download is uploaded without failure, it is false if download is disabled.
+	DownloadSucceeded bool
+
+	// NetworkTopologySucceeded is whether network topology is uploaded without failure, it is false if
+	// network topology is disabled.
+	NetworkTopologySucceeded bool
+
+	// DownloadRecords is the record counts through the stages of uploading download.
+	DownloadRecords RecordCounts
+
+	// NetworkTopologyRecords is the record counts through the stages of uploading network topology.
+	NetworkTopologyRecords RecordCounts
 }
 
+// appendToOutgoingContext appends the train result to the grpc metadata of ctx.
+func (r TrainResult) appendToOutgoingContext(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx,
+		TrainDownloadBytesMetadataKey, strconv.FormatInt(r.DownloadBytes, 10),
+		TrainNetworkTopologyBytesMetadataKey, strconv.FormatInt(r.NetworkTopologyBytes, 10),
+		TrainBudgetLimitedMetadataKey, strconv.FormatBool(r.BudgetLimited),
+		TrainDownloadSucceededMetadataKey, strconv.FormatBool(r.DownloadSucceeded),
+		TrainNetworkTopologySucceededMetadataKey, strconv.FormatBool(r.NetworkTopologySucceeded),
+	)
+}
+
+// TrainerDialer dials the trainer by address.
+type TrainerDialer func(ctx context.Context, addr string) (trainerclient.V1, error)
+
 // announcer provides announce function.
 type announcer struct {
 	config        *config.Config
 	managerClient managerclient.V2
-	trainerClient trainerclient.V1
+	trainerClient UploadSink
 	storage       storage.Storage
 	done          chan struct{}
+
+	// hostname is the hostname of scheduler, it is resolved in New.
+	hostname string
+
+	// stopOnce closes done once, wg waits for the trainer loop and keepalive to exit.
+	// stopped is guarded by lifecycleMu, no goroutine is added to wg once it is set,
+	// so that Serve never starts a goroutine after StopContext is waiting for wg.
+	stopOnce    sync.Once
+	wg          sync.WaitGroup
+	lifecycleMu sync.Mutex
+	stopped     bool
+
+	// events publishes the lifecycle events to subscribers.
+	events eventBus
+
+	// errs receives the unrecoverable errors of announcer.
+	errs chan error
+
+	// log is the logger of announcer, logLevel overrides its level if it is not nil.
+	log      *zap.SugaredLogger
+	logLevel *zapcore.Level
+
+	// trainerDialer dials the trainer discovered from manager,
+	// trainer discovery is disabled if it is nil.
+	trainerDialer TrainerDialer
+
+	// discoveredTrainerClient is the client of the trainer discovered from manager,
+	// it takes precedence over trainerClient.
+	discoveredTrainerClient trainerclient.V1
+	discoveredTrainerAddr   string
+
+	// managerDialer dials the leader manager hinted by the manager which is not the leader,
+	// redirecting is disabled if it is nil.
+	managerDialer ManagerDialer
+
+	// redirectedManagerClient is the client of the leader manager redirected to, it takes
+	// precedence over managerClient. managerMu guards the redirected manager.
+	managerMu               sync.RWMutex
+	redirectedManagerClient managerclient.V2
+	redirectedManagerAddr   string
+
+	// managerEndpoints are the manager endpoints failed over in order when the active manager
+	// is unavailable, managerHealthChecker checks the endpoint before failing over to it.
+	managerEndpoints     []string
+	managerHealthChecker ManagerHealthChecker
+
+	// advertiseIP is the advertise ip sent to manager in the last successful registration.
+	advertiseIP atomic.Pointer[net.IP]
+
+	// standalone skips registering and keeping alive to manager.
+	standalone bool
+
+	// checkpointFilename is the file name of upload checkpoint,
+	// checkpoint is disabled if it is empty. checkpointMu guards checkpoint, which is
+	// updated concurrently by the train streams of datasets with best-effort policy.
+	checkpointFilename string
+	checkpoint         Checkpoint
+	checkpointMu       sync.Mutex
+
+	// hostStatsCollector collects the host stats sent to manager on registration,
+	// host stats are not sent if it is nil.
+	hostStatsCollector HostStatsCollector
+
+	// uploadTransforms are applied to the datasets in order before uploading to trainer.
+	uploadTransforms []UploadTransform
+
+	// updateSchedulerRetry is the retry policy of registering scheduler to manager.
+	updateSchedulerRetry retryPolicy
+
+	// degraded starts announcer without registration if registering to manager fails in New,
+	// then scheduler registers to manager lazily in Serve.
+	degraded bool
+
+	// uploadedSize is the dataset size when the last successful training started.
+	uploadedSize int64
+
+	// trainResult is the result of the current or last training cycle.
+	trainResult TrainResult
+
+	// trainResults queues the train result of the last cycle to report to manager, it holds
+	// only the latest result, so that a slow manager never blocks the trainer loop.
+	trainResults chan TrainResult
+
+	// budget is the budget of the current or last training cycle.
+	budget *cycleBudget
+
+	// verifyStorage verifies the checksums of storage before each training cycle.
+	verifyStorage bool
+
+	// signingKey signs the datasets sent to trainer, the datasets are not signed if it is nil.
+	signingKey ed25519.PrivateKey
+
+	// shadowTrainerClient is the client of shadow trainer, which receives a copy of the
+	// datasets uploaded to the primary trainer.
+	shadowTrainerClient UploadSink
+
+	// jitter returns a random duration in [0, max) as the delay before the first training
+	// cycle, it is replaced in tests. Nil jitter uses math/rand.
+	jitter func(max time.Duration) time.Duration
+
+	// breaker skips the training cycles after consecutive failures, nil breaker is always closed.
+	breaker *circuitBreaker
+
+	// intervalTuner adapts the interval of training cycles to the growth of records,
+	// the interval is fixed if it is nil.
+	intervalTuner *intervalTuner
+
+	// keepAliveInterval is the keepalive interval applied to manager.
+	keepAliveInterval atomic.Int64
+
+	// trainerPreflight checks trainer is reachable in New, New fails on
+	// unreachable trainer only if trainerPreflightStrict is true.
+	trainerPreflight        bool
+	trainerPreflightStrict  bool
+	trainerPreflightTimeout time.Duration
 }
 
 // WithTrainerClient sets the grpc client of trainer.
@@ -66,6 +353,181 @@ func WithTrainerClient(client trainerclient.V1) Option {
 	}
 }
 
+// WithUploadSink sets the sink of datasets uploaded to trainer, e.g. HTTPUploadSink.
+// It replaces the grpc client of trainer set by WithTrainerClient.
+func WithUploadSink(sink UploadSink) Option {
+	return func(a *announcer) {
+		a.trainerClient = sink
+	}
+}
+
+// WithManagerDialer enables redirecting to the leader manager hinted by the error
+// of manager, the dialer dials the leader manager by address.
+func WithManagerDialer(dialer ManagerDialer) Option {
+	return func(a *announcer) {
+		a.managerDialer = dialer
+	}
+}
+
+// WithTrainerDialer enables discovering trainer endpoints from manager,
+// the dialer is used to connect the discovered trainer.
+func WithTrainerDialer(dialer TrainerDialer) Option {
+	return func(a *announcer) {
+		a.trainerDialer = dialer
+	}
+}
+
+// WithLogger sets the logger of announcer.
+func WithLogger(log *zap.SugaredLogger) Option {
+	return func(a *announcer) {
+		a.log = log
+	}
+}
+
+// WithLogLevel overrides the log level of announcer, e.g. enables debug logs of
+// announcer without changing the log level of scheduler.
+func WithLogLevel(level zapcore.Level) Option {
+	return func(a *announcer) {
+		a.logLevel = &level
+	}
+}
+
+// WithStandalone runs the announcer without manager, registration and keepalive to manager
+// are skipped while the trainer loop still runs.
+func WithStandalone(standalone bool) Option {
+	return func(a *announcer) {
+		a.standalone = standalone
+	}
+}
+
+// WithCheckpointDir sets the directory of the upload checkpoint, the announcer
+// resumes uploading from the checkpoint after restarts.
+func WithCheckpointDir(dir string) Option {
+	return func(a *announcer) {
+		a.checkpointFilename = filepath.Join(dir, CheckpointFilename)
+	}
+}
+
+// WithHostStatsCollector sets the collector of host stats, the host stats are
+// sent to manager as grpc metadata of registration.
+func WithHostStatsCollector(collector HostStatsCollector) Option {
+	return func(a *announcer) {
+		a.hostStatsCollector = collector
+	}
+}
+
+// WithUploadTransforms sets the transforms applied in order to the datasets
+// between opening from storage and uploading to trainer.
+func WithUploadTransforms(transforms ...UploadTransform) Option {
+	return func(a *announcer) {
+		a.uploadTransforms = transforms
+	}
+}
+
+// retryPolicy is the bounded backoff retry policy.
+type retryPolicy struct {
+	maxAttempts int
+	initBackoff time.Duration
+	maxBackoff  time.Duration
+
+	// jitter is the ratio in [0, 1] by which each backoff is randomly reduced.
+	jitter float64
+}
+
+// backoff returns the backoff after the attempt.
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	return retry.Backoff(p.initBackoff, p.maxBackoff, p.jitter, attempt)
+}
+
+// WithUpdateSchedulerRetry retries registering scheduler to manager at most maxAttempts
+// times with backoff between initBackoff and maxBackoff.
+func WithUpdateSchedulerRetry(maxAttempts int, initBackoff, maxBackoff time.Duration) Option {
+	return func(a *announcer) {
+		if maxAttempts < 1 {
+			maxAttempts = 1
+		}
+
+		a.updateSchedulerRetry = retryPolicy{
+			maxAttempts: maxAttempts,
+			initBackoff: initBackoff,
+			maxBackoff:  maxBackoff,
+			jitter:      a.updateSchedulerRetry.jitter,
+		}
+	}
+}
+
+// WithUpdateSchedulerRetryJitter reduces each backoff of registering scheduler to manager
+// randomly by at most the jitter ratio in [0, 1].
+func WithUpdateSchedulerRetryJitter(jitter float64) Option {
+	return func(a *announcer) {
+		if jitter < 0 {
+			jitter = 0
+		}
+
+		if jitter > 1 {
+			jitter = 1
+		}
+
+		a.updateSchedulerRetry.jitter = jitter
+	}
+}
+
+// WithDegradedStart starts announcer without registration if registering scheduler to
+// manager fails in New, then scheduler registers to manager lazily in Serve with the
+// backoff of update scheduler retry until it succeeds or announcer is stopped.
+func WithDegradedStart(degraded bool) Option {
+	return func(a *announcer) {
+		a.degraded = degraded
+	}
+}
+
+// WithTrainerPreflight checks trainer is reachable in New by opening a train stream,
+// the stream is canceled without sending any data.
+func WithTrainerPreflight(preflight bool) Option {
+	return func(a *announcer) {
+		a.trainerPreflight = preflight
+	}
+}
+
+// WithTrainerPreflightStrict returns error from New if the trainer preflight fails,
+// otherwise the failure is only logged.
+func WithTrainerPreflightStrict(strict bool) Option {
+	return func(a *announcer) {
+		a.trainerPreflightStrict = strict
+	}
+}
+
+// WithTrainerPreflightTimeout sets the timeout of trainer preflight.
+func WithTrainerPreflightTimeout(timeout time.Duration) Option {
+	return func(a *announcer) {
+		a.trainerPreflightTimeout = timeout
+	}
+}
+
+// WithStorageVerification verifies the checksums of storage before each training cycle,
+// the training cycle is skipped if the storage is corrupt.
+func WithStorageVerification(verify bool) Option {
+	return func(a *announcer) {
+		a.verifyStorage = verify
+	}
+}
+
+// WithSigningKey signs the datasets sent to trainer with the Ed25519 private key,
+// it takes precedence over the signing key file of configuration.
+func WithSigningKey(key ed25519.PrivateKey) Option {
+	return func(a *announcer) {
+		a.signingKey = key
+	}
+}
+
+// WithShadowTrainer streams a copy of the datasets uploaded to the primary trainer to the
+// shadow trainer, the failures of shadow trainer never fail the training cycle.
+func WithShadowTrainer(client trainerclient.V1) Option {
+	return func(a *announcer) {
+		a.shadowTrainerClient = client
+	}
+}
+
 // Option is a functional option for configuring the announcer.
 type Option func(s *announcer)
 
@@ -76,187 +538,1499 @@ func New(cfg *config.Config, managerClient managerclient.V2, storage storage.Sto
 		managerClient: managerClient,
 		storage:       storage,
 		done:          make(chan struct{}),
+		trainResults:  make(chan TrainResult, 1),
+		errs:          make(chan error, 1),
+		log:           logger.CoreLogger.Desugar().WithOptions(zap.AddCallerSkip(-1)).Sugar(),
+		updateSchedulerRetry: retryPolicy{
+			maxAttempts: 1,
+		},
+		trainerPreflightTimeout: DefaultTrainerPreflightTimeout,
 	}
 
 	for _, opt := range options {
 		opt(a)
 	}
 
-	// Register to manager.
-	if _, err := a.managerClient.UpdateScheduler(context.Background(), &managerv2.UpdateSchedulerRequest{
-		SourceType:         managerv2.SourceType_SCHEDULER_SOURCE,
-		Hostname:           a.config.Server.Host,
-		Ip:                 a.config.Server.AdvertiseIP.String(),
-		Port:               int32(a.config.Server.AdvertisePort),
-		Idc:                a.config.Host.IDC,
-		Location:           a.config.Host.Location,
-		SchedulerClusterId: uint64(a.config.Manager.SchedulerClusterID),
-	}); err != nil {
+	if a.logLevel != nil {
+		a.log = newLevelLogger(a.log, *a.logLevel)
+	}
+
+	if err := validateTrainerConfig(&cfg.Trainer); err != nil {
 		return nil, err
 	}
 
-	return a, nil
-}
+	a.breaker = newCircuitBreaker(cfg.Trainer.BreakerFailureThreshold, cfg.Trainer.BreakerCooldown)
 
-// Started announcer server.
-func (a *announcer) Serve() error {
-	logger.Info("announce scheduler to manager")
-	if err := a.announceToManager(); err != nil {
-		return err
+	// Fall back to the fqdn hostname if the hostname of scheduler is not configured.
+	a.hostname = cfg.Server.Host
+	if a.hostname == "" {
+		a.hostname = fqdn.FQDNHostname
+		a.log.Infof("hostname is not configured, use fqdn hostname %s", a.hostname)
 	}
 
-	if a.trainerClient != nil {
-		logger.Info("announce scheduler to trainer")
-		if err := a.announceToTrainer(); err != nil {
-			return err
-		}
+	// Storage is only read by the trainer loop.
+	if (a.trainerClient != nil || cfg.Trainer.Enable) && storage == nil {
+		return nil, ErrStorageRequired
 	}
 
-	return nil
-}
-
-// Stop announcer server.
-func (a *announcer) Stop() error {
-	close(a.done)
-	return nil
-}
-
-// announceSeedPeer announces peer information to manager.
-func (a *announcer) announceToManager() error {
-	// Start keepalive to manager.
-	go func() {
-		a.managerClient.KeepAlive(a.config.Manager.KeepAlive.Interval, &managerv2.KeepAliveRequest{
-			SourceType: managerv2.SourceType_SCHEDULER_SOURCE,
-			Hostname:   a.config.Server.Host,
-			Ip:         a.config.Server.AdvertiseIP.String(),
-			ClusterId:  uint64(a.config.Manager.SchedulerClusterID),
-		}, a.done)
-	}()
+	if cfg.Trainer.AdaptiveInterval && storage != nil {
+		a.intervalTuner = newIntervalTuner(cfg.Trainer.Interval, cfg.Trainer.MinInterval, cfg.Trainer.MaxInterval,
+			cfg.Trainer.TargetRecords, cfg.Trainer.IntervalSensitivity, a.recordCount())
+	}
 
-	return nil
-}
+	if cfg.Trainer.SignDatasets && a.signingKey == nil {
+		if cfg.Trainer.SigningKeyFile == "" {
+			return nil, ErrSigningKeyRequired
+		}
 
-// announceSeedPeer announces dataset to trainer.
-func (a *announcer) announceToTrainer() error {
-	tick := time.NewTicker(a.config.Trainer.Interval)
-	for {
-		select {
-		case <-tick.C:
-			if err := a.train(); err != nil {
-				logger.Error(err)
-			}
-		case <-a.done:
-			return nil
+		signingKey, err := loadSigningKey(cfg.Trainer.SigningKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load signing key: %w", err)
 		}
+
+		a.signingKey = signingKey
 	}
-}
 
-// train uploads dataset to trainer and trigger training.
-func (a *announcer) train() error {
-	ctx, cancel := context.WithTimeout(context.Background(), a.config.Trainer.UploadTimeout)
-	defer cancel()
+	if a.checkpointFilename != "" {
+		checkpoint, err := loadCheckpoint(a.checkpointFilename)
+		if err != nil {
+			a.log.Warnf("load checkpoint %s failed, upload from the beginning: %s", a.checkpointFilename, err.Error())
+		}
 
-	stream, err := a.trainerClient.Train(ctx)
-	if err != nil {
-		return err
+		a.checkpoint = checkpoint
 	}
 
-	eg := errgroup.Group{}
-	eg.Go(func() error {
-		if err := a.uploadDownloadToTrainer(stream); err != nil {
-			return fmt.Errorf("upload download: %w", err)
+	if a.standalone {
+		a.log.Info("announcer runs in standalone mode, skip registering to manager")
+		if err := a.preflightTrainer(); err != nil {
+			return nil, err
 		}
 
-		return nil
-	})
+		return a, nil
+	}
 
-	eg.Go(func() error {
-		if err := a.uploadNetworkTopologyToTrainer(stream); err != nil {
-			return fmt.Errorf("upload network topology: %w", err)
+	// Register to manager.
+	if err := a.updateScheduler(a.registrationContext(context.Background())); err != nil {
+		if !a.degraded {
+			return nil, err
 		}
 
-		return nil
-	})
+		a.log.Warnf("register to manager failed, start in degraded mode: %s", err.Error())
+	}
 
-	if err := eg.Wait(); err != nil {
-		return err
+	// Discover trainer from manager.
+	if a.config.Trainer.Enable && a.trainerDialer != nil {
+		if err := a.refreshTrainerClient(context.Background()); err != nil {
+			a.log.Warnf("discover trainer failed: %s", err.Error())
+		}
 	}
 
-	if _, err := stream.CloseAndRecv(); err != nil {
-		return err
+	if err := a.preflightTrainer(); err != nil {
+		return nil, err
 	}
 
-	return nil
+	return a, nil
 }
 
-// uploadDownloadToTrainer uploads download information to trainer.
-func (a *announcer) uploadDownloadToTrainer(stream trainerv1.Trainer_TrainClient) error {
-	readCloser, err := a.storage.OpenDownload()
-	if err != nil {
-		return err
+// preflightTrainer checks the active trainer is reachable by opening a train stream
+// within the preflight timeout, the stream is canceled without sending any data.
+func (a *announcer) preflightTrainer() error {
+	if !a.trainerPreflight {
+		return nil
 	}
-	defer readCloser.Close()
 
-	buf := make([]byte, UploadBufferSize)
-	for {
-		n, err := readCloser.Read(buf)
-		if err != nil && err != io.EOF {
-			return err
-		}
+	client, addr := a.activeTrainer()
+	if client == nil {
+		return nil
+	}
 
-		if err := stream.Send(&trainerv1.TrainRequest{
-			Hostname:  a.config.Server.Host,
-			Ip:        a.config.Server.AdvertiseIP.String(),
-			ClusterId: uint64(a.config.Manager.SchedulerClusterID),
-			Request: &trainerv1.TrainRequest_TrainMlpRequest{
-				TrainMlpRequest: &trainerv1.TrainMLPRequest{
-					Dataset: buf[:n],
-				},
-			},
-		}); err != nil {
-			return err
-		}
+	ctx, cancel := context.WithTimeout(context.Background(), a.trainerPreflightTimeout)
+	defer cancel()
 
-		if err == io.EOF {
-			break
+	if _, err := client.Train(ctx, a.trainCallOptions()...); err != nil {
+		if a.trainerPreflightStrict {
+			return fmt.Errorf("preflight trainer %s: %w", addr, err)
 		}
+
+		a.log.Warnf("preflight trainer %s failed: %s", addr, err.Error())
+		return nil
 	}
 
+	a.log.Infof("preflight trainer %s succeeded", addr)
 	return nil
 }
 
-// uploadNetworkTopologyToTrainer uploads network topology to trainer.
-func (a *announcer) uploadNetworkTopologyToTrainer(stream trainerv1.Trainer_TrainClient) error {
-	readCloser, err := a.storage.OpenNetworkTopology()
-	if err != nil {
+// updateScheduler registers scheduler to manager, it retries with bounded and jittered
+// backoff if the update scheduler retry is enabled.
+func (a *announcer) updateScheduler(ctx context.Context) error {
+	req := a.updateSchedulerRequest()
+	var attempt int
+	if _, _, err := retry.RunWithBackoff(ctx, a.updateSchedulerRetry.initBackoff, a.updateSchedulerRetry.maxBackoff,
+		a.updateSchedulerRetry.jitter, a.updateSchedulerRetry.maxAttempts, func() (any, bool, error) {
+			attempt++
+			if err := a.updateSchedulerWithRedirect(ctx, req); err != nil {
+				a.log.Warnf("update scheduler to manager failed in attempt %d: %s", attempt, err.Error())
+				return nil, false, err
+			}
+
+			return nil, false, nil
+		}); err != nil {
 		return err
 	}
-	defer readCloser.Close()
 
-	buf := make([]byte, UploadBufferSize)
-	for {
-		n, err := readCloser.Read(buf)
-		if err != nil && err != io.EOF {
-			return err
+	ip := net.ParseIP(req.Ip)
+	if previous := a.advertiseIP.Swap(&ip); previous == nil || !previous.Equal(ip) {
+		a.log.Infof("registered to manager with %s advertise ip %s", ipFamily(ip), req.Ip)
+		if secondaryIP := a.config.Server.SecondaryAdvertiseIP; secondaryIP != nil {
+			a.log.Infof("registered to manager with %s secondary advertise ip %s", ipFamily(secondaryIP), secondaryIP.String())
 		}
+	}
 
-		if err := stream.Send(&trainerv1.TrainRequest{
-			Hostname:  a.config.Server.Host,
-			Ip:        a.config.Server.AdvertiseIP.String(),
-			ClusterId: uint64(a.config.Manager.SchedulerClusterID),
-			Request: &trainerv1.TrainRequest_TrainGnnRequest{
-				TrainGnnRequest: &trainerv1.TrainGNNRequest{
-					Dataset: buf[:n],
-				},
-			},
-		}); err != nil {
-			return err
+	a.events.publish(Event{Type: EventRegistered})
+	return nil
+}
+
+// updateSchedulerRequest returns the request registering scheduler to manager.
+func (a *announcer) updateSchedulerRequest() *managerv2.UpdateSchedulerRequest {
+	return &managerv2.UpdateSchedulerRequest{
+		SourceType:         managerv2.SourceType_SCHEDULER_SOURCE,
+		Hostname:           a.hostname,
+		Ip:                 a.config.Server.AdvertiseIP.String(),
+		Port:               int32(a.config.Server.AdvertisePort),
+		Idc:                a.config.Host.IDC,
+		Location:           a.config.Host.Location,
+		SchedulerClusterId: uint64(a.config.Manager.SchedulerClusterID),
+	}
+}
+
+// registrationContext returns ctx with the advertise ips and host stats sent to manager on registration.
+func (a *announcer) registrationContext(ctx context.Context) context.Context {
+	ctx = a.advertiseIPsContext(ctx)
+	if a.hostStatsCollector == nil {
+		return ctx
+	}
+
+	hostStats, err := a.hostStatsCollector()
+	if err != nil {
+		a.log.Warnf("collect host stats failed: %s", err.Error())
+		return ctx
+	}
+
+	return hostStats.appendToOutgoingContext(ctx)
+}
+
+// registerLazily registers scheduler to manager in rounds of update scheduler retry until
+// it succeeds or announcer is stopped, it returns false if announcer is stopped. The rounds
+// are separated by the backoff of update scheduler retry.
+func (a *announcer) registerLazily() bool {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-a.done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for round := 1; ; round++ {
+		err := a.updateScheduler(a.registrationContext(ctx))
+		if err == nil {
+			a.log.Infof("registered to manager lazily in round %d", round)
+			return true
+		}
+
+		if ctx.Err() != nil {
+			return false
+		}
+
+		backoff := a.updateSchedulerRetry.backoff(round)
+		if backoff <= 0 {
+			backoff = DefaultUpdateSchedulerMaxBackoff
+		}
+
+		a.log.Warnf("register to manager lazily failed in round %d, retry in %s: %s", round, backoff, err.Error())
+		select {
+		case <-time.After(backoff):
+		case <-a.done:
+			return false
+		}
+	}
+}
+
+// ipFamily returns the family name of ip.
+func ipFamily(ip net.IP) string {
+	if ip.To4() != nil {
+		return "ipv4"
+	}
+
+	return "ipv6"
+}
+
+// validateTrainerConfig validates the trainer configuration when trainer is enabled.
+func validateTrainerConfig(cfg *config.TrainerConfig) error {
+	if !cfg.Enable {
+		return nil
+	}
+
+	if cfg.Addr == "" {
+		return &TrainerConfigError{Field: "addr", Value: cfg.Addr, Err: errors.New("address is empty")}
+	}
+
+	if _, _, err := net.SplitHostPort(cfg.Addr); err != nil {
+		return &TrainerConfigError{Field: "addr", Value: cfg.Addr, Err: err}
+	}
+
+	if cfg.Compressor != "" && encoding.GetCompressor(cfg.Compressor) == nil {
+		return &TrainerConfigError{Field: "compressor", Value: cfg.Compressor, Err: errors.New("compressor is not registered")}
+	}
+
+	return nil
+}
+
+// Started announcer server.
+func (a *announcer) Serve() error {
+	if !a.standalone {
+		a.log.Info("announce scheduler to manager")
+		if err := a.announceToManager(); err != nil {
+			return err
+		}
+	}
+
+	if client, _ := a.activeTrainer(); client != nil && a.storage != nil {
+		a.log.Info("announce scheduler to trainer")
+		if err := a.announceToTrainer(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Stop announcer server.
+func (a *announcer) Stop() error {
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultStopTimeout)
+	defer cancel()
+
+	return a.StopContext(ctx)
+}
+
+// StopContext stops announcer server and waits for the in-flight training cycle and
+// keepalive to exit, it returns the error of ctx if ctx is done before they exit.
+func (a *announcer) StopContext(ctx context.Context) error {
+	a.lifecycleMu.Lock()
+	a.stopped = true
+	a.stopOnce.Do(func() {
+		close(a.done)
+	})
+	a.lifecycleMu.Unlock()
+
+	exited := make(chan struct{})
+	go func() {
+		a.wg.Wait()
+		close(exited)
+	}()
+
+	select {
+	case <-exited:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	// No event is published after the loops exit.
+	a.events.close()
+
+	var errs *multierror.Error
+	if a.discoveredTrainerClient != nil {
+		errs = multierror.Append(errs, a.discoveredTrainerClient.Close())
+	}
+
+	a.managerMu.Lock()
+	defer a.managerMu.Unlock()
+	if a.redirectedManagerClient != nil {
+		errs = multierror.Append(errs, a.redirectedManagerClient.Close())
+	}
+
+	return errs.ErrorOrNil()
+}
+
+// Subscribe returns a channel receiving the lifecycle events published after subscribing,
+// events are dropped if the channel is full. The channel is closed when announcer stops.
+func (a *announcer) Subscribe() <-chan Event {
+	return a.events.subscribe()
+}
+
+// track adds a goroutine to wg, it returns false if announcer is stopped.
+func (a *announcer) track() bool {
+	a.lifecycleMu.Lock()
+	defer a.lifecycleMu.Unlock()
+
+	if a.stopped {
+		return false
+	}
+
+	a.wg.Add(1)
+	return true
+}
+
+// ListTrainerEndpoints returns the trainer endpoints discovered from manager,
+// it falls back to the configured trainer address if manager returns none.
+func (a *announcer) ListTrainerEndpoints(ctx context.Context) ([]string, error) {
+	if a.standalone {
+		if a.config.Trainer.Addr == "" {
+			return nil, nil
+		}
+
+		return []string{a.config.Trainer.Addr}, nil
+	}
+
+	scheduler, err := a.activeManager().GetScheduler(ctx, &managerv2.GetSchedulerRequest{
+		SourceType:         managerv2.SourceType_SCHEDULER_SOURCE,
+		Hostname:           a.hostname,
+		Ip:                 a.config.Server.AdvertiseIP.String(),
+		SchedulerClusterId: uint64(a.config.Manager.SchedulerClusterID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoints []string
+	if scheduler.SchedulerCluster != nil && len(scheduler.SchedulerCluster.Config) > 0 {
+		var config types.SchedulerClusterConfig
+		if err := json.Unmarshal(scheduler.SchedulerCluster.Config, &config); err != nil {
+			return nil, err
+		}
+
+		for _, addr := range config.TrainerAddrs {
+			if _, _, err := net.SplitHostPort(addr); err != nil {
+				a.log.Warnf("invalid trainer addr %s from manager: %s", addr, err.Error())
+				continue
+			}
+
+			endpoints = append(endpoints, addr)
+		}
+	}
+
+	if len(endpoints) == 0 && a.config.Trainer.Addr != "" {
+		endpoints = append(endpoints, a.config.Trainer.Addr)
+	}
+
+	return endpoints, nil
+}
+
+// refreshTrainerClient discovers trainer endpoints from manager and switches the trainer client
+// if the current trainer is no longer available.
+func (a *announcer) refreshTrainerClient(ctx context.Context) error {
+	endpoints, err := a.ListTrainerEndpoints(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, endpoint := range endpoints {
+		if a.discoveredTrainerClient != nil && endpoint == a.discoveredTrainerAddr {
+			return nil
+		}
+	}
+
+	// Use the static trainer client if its address is still available.
+	for _, endpoint := range endpoints {
+		if a.trainerClient != nil && endpoint == a.config.Trainer.Addr {
+			a.closeDiscoveredTrainerClient()
+			return nil
+		}
+	}
+
+	if len(endpoints) == 0 {
+		return nil
+	}
+
+	client, err := a.trainerDialer(ctx, endpoints[0])
+	if err != nil {
+		return err
+	}
+
+	a.log.Infof("switch to trainer %s discovered from manager", endpoints[0])
+	a.closeDiscoveredTrainerClient()
+	a.discoveredTrainerClient = client
+	a.discoveredTrainerAddr = endpoints[0]
+	return nil
+}
+
+// closeDiscoveredTrainerClient closes the client of the trainer discovered from manager.
+func (a *announcer) closeDiscoveredTrainerClient() {
+	if a.discoveredTrainerClient == nil {
+		return
+	}
+
+	if err := a.discoveredTrainerClient.Close(); err != nil {
+		a.log.Errorf("close trainer client %s failed: %s", a.discoveredTrainerAddr, err.Error())
+	}
+
+	a.discoveredTrainerClient = nil
+	a.discoveredTrainerAddr = ""
+}
+
+// activeTrainer returns the client and address of the trainer used for training.
+func (a *announcer) activeTrainer() (UploadSink, string) {
+	if a.discoveredTrainerClient != nil {
+		return a.discoveredTrainerClient, a.discoveredTrainerAddr
+	}
+
+	return a.trainerClient, a.config.Trainer.Addr
+}
+
+// announceSeedPeer announces peer information to manager.
+func (a *announcer) announceToManager() error {
+	// Start keepalive to manager, the interval is not jittered.
+	interval := a.config.Manager.KeepAlive.Interval
+	a.keepAliveInterval.Store(int64(interval))
+	a.log.Debugf("keepalive to manager with interval %s", interval)
+	if !a.track() {
+		return nil
+	}
+
+	observer := a.keepAliveObserver()
+	go func() {
+		defer a.wg.Done()
+
+		// Scheduler started in degraded mode registers to manager before keepalive.
+		if a.degraded && a.advertiseIP.Load() == nil && !a.registerLazily() {
+			return
+		}
+
+		req := &managerv2.KeepAliveRequest{
+			SourceType: managerv2.SourceType_SCHEDULER_SOURCE,
+			Hostname:   a.hostname,
+			Ip:         a.config.Server.AdvertiseIP.String(),
+			ClusterId:  uint64(a.config.Manager.SchedulerClusterID),
+		}
+
+		if !a.managerFailoverEnabled() {
+			a.activeManager().KeepAlive(interval, req, a.done, managerclient.WithKeepAliveObserver(observer))
+			return
+		}
+
+		a.keepAliveWithFailover(interval, req, observer)
+	}()
+
+	return nil
+}
+
+// keepAliveWithFailover keeps alive to the active manager until announcer is stopped, the keepalive
+// fails over to the next healthy manager endpoint after consecutive failures of the active manager.
+func (a *announcer) keepAliveWithFailover(interval time.Duration, req *managerv2.KeepAliveRequest, observer managerclient.KeepAliveObserver) {
+	for {
+		var (
+			stop     = make(chan struct{})
+			stopOnce sync.Once
+			failures int
+		)
+		closeStop := func() {
+			stopOnce.Do(func() { close(stop) })
+		}
+
+		go func() {
+			select {
+			case <-a.done:
+				closeStop()
+			case <-stop:
+			}
+		}()
+
+		a.activeManager().KeepAlive(interval, req, stop, managerclient.WithKeepAliveObserver(func(err error) {
+			observer(err)
+			if err == nil {
+				failures = 0
+				return
+			}
+
+			failures++
+			if failures >= managerFailoverFailures {
+				closeStop()
+			}
+		}))
+		closeStop()
+
+		select {
+		case <-a.done:
+			return
+		default:
+		}
+
+		if err := a.failoverManager(context.Background()); err != nil {
+			a.log.Warnf("keepalive fails over to manager failed: %s", err.Error())
+			select {
+			case <-time.After(interval):
+			case <-a.done:
+				return
+			}
+		}
+	}
+}
+
+// keepAliveObserver returns the observer of keepalive counting the consecutive failures, an
+// unrecoverable error is reported once the failures exceed the max consecutive failures.
+// The observer is called by the keepalive goroutine only.
+func (a *announcer) keepAliveObserver() managerclient.KeepAliveObserver {
+	maxFailures := a.config.Manager.KeepAlive.MaxConsecutiveFailures
+	var failures int
+	return func(err error) {
+		if err == nil {
+			failures = 0
+			return
+		}
+
+		failures++
+		a.log.Warnf("keepalive to manager failed %d consecutive times: %s", failures, err.Error())
+		if maxFailures > 0 && failures == maxFailures+1 {
+			a.reportError(fmt.Errorf("%w: exceeded %d consecutive failures: %s", ErrKeepAliveFailed, maxFailures, err.Error()))
+		}
+	}
+}
+
+// reportError sends the unrecoverable error to errs, it is dropped if errs is full.
+func (a *announcer) reportError(err error) {
+	a.log.Error(err)
+	select {
+	case a.errs <- err:
+	default:
+	}
+}
+
+// Err returns a channel receiving the unrecoverable errors of announcer.
+func (a *announcer) Err() <-chan error {
+	return a.errs
+}
+
+// EffectiveKeepAliveInterval returns the keepalive interval applied to manager,
+// it returns zero if keepalive is not started.
+func (a *announcer) EffectiveKeepAliveInterval() time.Duration {
+	return time.Duration(a.keepAliveInterval.Load())
+}
+
+// AdvertiseAddr returns the advertise ip sent to manager in the last successful registration,
+// it returns ErrNotRegistered if scheduler is not registered.
+func (a *announcer) AdvertiseAddr() (net.IP, error) {
+	ip := a.advertiseIP.Load()
+	if ip == nil {
+		return nil, ErrNotRegistered
+	}
+
+	return *ip, nil
+}
+
+// Status returns the status of announcer.
+func (a *announcer) Status() Status {
+	if a.breaker == nil {
+		return Status{BreakerState: BreakerClosed}
+	}
+
+	state, failures := a.breaker.status()
+	return Status{
+		BreakerState:        state,
+		ConsecutiveFailures: failures,
+	}
+}
+
+// announceSeedPeer announces dataset to trainer.
+func (a *announcer) announceToTrainer() error {
+	if !a.track() {
+		return nil
+	}
+	defer a.wg.Done()
+
+	// Delay the first tick, so that the schedulers started together spread their training cycles.
+	if delay := a.startupDelay(); delay > 0 {
+		a.log.Infof("delay the first training cycle for %s", delay)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-a.done:
+			timer.Stop()
+			return nil
+		}
+	}
+
+	interval := a.config.Trainer.Interval
+	if a.intervalTuner != nil {
+		interval = a.intervalTuner.interval
+	}
+
+	if !a.standalone && a.track() {
+		go func() {
+			defer a.wg.Done()
+			a.reportTrainResults()
+		}()
+	}
+
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			if a.trainerDialer != nil && !a.standalone {
+				if err := a.refreshTrainerClient(context.Background()); err != nil {
+					a.log.Warnf("discover trainer failed: %s", err.Error())
+				}
+			}
+
+			if a.breaker != nil && !a.breaker.allow() {
+				a.log.Warn("circuit breaker is open, skip training")
+				continue
+			}
+
+			err := a.train()
+			if err != nil {
+				a.log.Error(err)
+			}
+
+			if a.breaker != nil {
+				a.breaker.record(err)
+			}
+
+			if !a.standalone {
+				a.queueTrainResult(a.trainResult)
+			}
+
+			if a.intervalTuner != nil {
+				interval = a.intervalTuner.next(a.recordCount())
+				a.log.Infof("next training cycle in %s", interval)
+				tick.Reset(interval)
+			}
+		case <-a.done:
+			return nil
+		}
+	}
+}
+
+// startupDelay returns the random delay before the first training cycle, it is
+// zero if the startup jitter is not configured.
+func (a *announcer) startupDelay() time.Duration {
+	if a.config.Trainer.StartupJitter <= 0 {
+		return 0
+	}
+
+	if a.jitter != nil {
+		return a.jitter(a.config.Trainer.StartupJitter)
+	}
+
+	return time.Duration(rand.Int63n(int64(a.config.Trainer.StartupJitter)))
+}
+
+// train uploads dataset to trainer and trigger training. The dataset is uploaded
+// in segments, each segment is uploaded in a separate stream and advances the
+// checkpoint after the trainer receives it, so that a failed cycle resumes
+// from the last completed segment.
+func (a *announcer) train() error {
+	if !a.config.Trainer.UploadDownload && !a.config.Trainer.UploadNetworkTopology {
+		a.log.Warn("uploading download and network topology are both disabled, skip training")
+		return nil
+	}
+
+	// Make sure the uploaded dataset is persisted, sync failure does not stop training.
+	if err := a.storage.Sync(); err != nil {
+		a.log.Errorf("sync storage failed: %s", err.Error())
+	}
+
+	// Corrupt dataset is never uploaded, the other verification failures do not stop training.
+	if a.verifyStorage {
+		if err := a.storage.Verify(); err != nil {
+			var corruptionErr *storage.CorruptionError
+			if errors.As(err, &corruptionErr) {
+				return fmt.Errorf("skip training: %w", err)
+			}
+
+			a.log.Errorf("verify storage failed: %s", err.Error())
+		}
+	}
+
+	// Defer training until enough dataset is written since the last successful training.
+	// The size is reset when storage is cleared, then the dataset written after clearing is pending.
+	var size int64
+	if a.config.Trainer.MinUploadBytes > 0 {
+		size = a.datasetSize()
+		if size < a.uploadedSize {
+			a.uploadedSize = 0
+		}
+
+		if pending := size - a.uploadedSize; pending < a.config.Trainer.MinUploadBytes {
+			a.log.Infof("pending dataset %d bytes is less than %d bytes, defer training", pending, a.config.Trainer.MinUploadBytes)
+			return nil
+		}
+	}
+
+	a.trainResult = TrainResult{}
+	a.budget = newCycleBudget(a.config.Trainer.CycleTimeout, a.config.Trainer.CycleMaxBytes)
+
+	// All segments of a cycle are uploaded to the same trainer.
+	client, addr := a.activeTrainer()
+	metrics.TrainCount.WithLabelValues(addr).Inc()
+	start := time.Now()
+	defer func() {
+		metrics.TrainDuration.WithLabelValues(addr).Observe(float64(time.Since(start).Milliseconds()))
+	}()
+	a.events.publish(Event{Type: EventCycleStarted, Trainer: addr})
+
+	// Only the dataset appended since the checkpoint is uploaded, unless full sync is due.
+	if err := a.startFullSync(addr); err != nil {
+		metrics.TrainFailureCount.WithLabelValues(addr).Inc()
+		a.events.publish(Event{Type: EventCycleFailed, Trainer: addr, Err: err})
+		return err
+	}
+
+	if a.config.Trainer.UploadPolicy == config.TrainerUploadPolicyBestEffort {
+		return a.trainBestEffort(client, addr, size)
+	}
+
+	// Disabled dataset is regarded as uploaded.
+	downloadDone, networkTopologyDone := !a.config.Trainer.UploadDownload, !a.config.Trainer.UploadNetworkTopology
+	for !downloadDone || !networkTopologyDone {
+		select {
+		case <-a.done:
+			a.log.Info("announcer is stopped, cancel training")
+			return nil
+		default:
+		}
+
+		var err error
+		if downloadDone, networkTopologyDone, err = a.trainSegment(client, addr, downloadDone, networkTopologyDone); err != nil {
+			a.trainResult.BudgetLimited = a.budget.isExceeded()
+			metrics.TrainFailureCount.WithLabelValues(addr).Inc()
+			a.events.publish(Event{Type: EventCycleFailed, Trainer: addr, Err: err})
+			return err
+		}
+
+		// The rest of dataset is uploaded in the next training, which is not
+		// deferred by MinUploadBytes.
+		if a.budget.isExceeded() {
+			a.trainResult.BudgetLimited = true
+			break
+		}
+	}
+
+	a.trainResult.DownloadSucceeded = a.config.Trainer.UploadDownload
+	a.trainResult.NetworkTopologySucceeded = a.config.Trainer.UploadNetworkTopology
+	if a.trainResult.BudgetLimited {
+		a.log.Infof("budget of training cycle is exceeded, upload the rest of dataset in the next training")
+	} else {
+		a.uploadedSize = size
+	}
+
+	metrics.TrainLastSuccessTimestampGauge.SetToCurrentTime()
+	a.events.publish(Event{Type: EventCycleSucceeded, Trainer: addr})
+	return nil
+}
+
+// trainBestEffort uploads each enabled dataset in its own train streams concurrently, failure
+// of a dataset does not stop uploading the other dataset. The cycle fails if any dataset fails,
+// and the train result reports which datasets are uploaded.
+func (a *announcer) trainBestEffort(client UploadSink, addr string, size int64) error {
+	var (
+		wg                              sync.WaitGroup
+		downloadErr, networkTopologyErr error
+	)
+	if a.config.Trainer.UploadDownload {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if downloadErr = a.trainDataset(client, addr, true); downloadErr != nil {
+				a.log.Errorf("upload download failed: %s", downloadErr.Error())
+			}
+		}()
+	}
+
+	if a.config.Trainer.UploadNetworkTopology {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if networkTopologyErr = a.trainDataset(client, addr, false); networkTopologyErr != nil {
+				a.log.Errorf("upload network topology failed: %s", networkTopologyErr.Error())
+			}
+		}()
+	}
+	wg.Wait()
+
+	select {
+	case <-a.done:
+		a.log.Info("announcer is stopped, cancel training")
+		return nil
+	default:
+	}
+
+	a.trainResult.DownloadSucceeded = a.config.Trainer.UploadDownload && downloadErr == nil
+	a.trainResult.NetworkTopologySucceeded = a.config.Trainer.UploadNetworkTopology && networkTopologyErr == nil
+	a.trainResult.BudgetLimited = a.budget.isExceeded()
+
+	var merr error
+	if downloadErr != nil {
+		merr = multierror.Append(merr, downloadErr)
+	}
+
+	if networkTopologyErr != nil {
+		merr = multierror.Append(merr, networkTopologyErr)
+	}
+
+	if merr != nil {
+		metrics.TrainFailureCount.WithLabelValues(addr).Inc()
+		a.events.publish(Event{Type: EventCycleFailed, Trainer: addr, Err: merr})
+		return merr
+	}
+
+	if a.trainResult.BudgetLimited {
+		a.log.Infof("budget of training cycle is exceeded, upload the rest of dataset in the next training")
+	} else {
+		a.uploadedSize = size
+	}
+
+	metrics.TrainLastSuccessTimestampGauge.SetToCurrentTime()
+	a.events.publish(Event{Type: EventCycleSucceeded, Trainer: addr})
+	return nil
+}
+
+// trainDataset uploads the download or the network topology in segments without the other dataset,
+// until it is uploaded completely, the budget is exceeded or announcer is stopped.
+func (a *announcer) trainDataset(client UploadSink, addr string, download bool) error {
+	downloadDone, networkTopologyDone := !download, download
+	for !downloadDone || !networkTopologyDone {
+		select {
+		case <-a.done:
+			return nil
+		default:
 		}
 
-		if err == io.EOF {
-			break
+		var err error
+		if downloadDone, networkTopologyDone, err = a.trainSegment(client, addr, downloadDone, networkTopologyDone); err != nil {
+			return err
+		}
+
+		if a.budget.isExceeded() {
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// datasetSize returns the size in bytes of the enabled datasets written into storage.
+func (a *announcer) datasetSize() int64 {
+	var size int64
+	if a.config.Trainer.UploadDownload {
+		size += a.storage.DownloadSize()
+	}
+
+	if a.config.Trainer.UploadNetworkTopology {
+		size += a.storage.NetworkTopologySize()
+	}
+
+	return size
+}
+
+// queueTrainResult queues the train result to report, it replaces the result not reported yet.
+func (a *announcer) queueTrainResult(result TrainResult) {
+	for {
+		select {
+		case a.trainResults <- result:
+			return
+		default:
+		}
+
+		select {
+		case <-a.trainResults:
+		default:
+		}
+	}
+}
+
+// reportTrainResults reports the queued train results to manager until announcer is stopped.
+func (a *announcer) reportTrainResults() {
+	for {
+		select {
+		case result := <-a.trainResults:
+			if err := a.reportTrainResult(context.Background(), result); err != nil {
+				a.log.Warnf("report train result to manager failed: %s", err.Error())
+			}
+		case <-a.done:
+			return
+		}
+	}
+}
+
+// reportTrainResult reports the train result to manager in a single UpdateScheduler, it is not
+// retried because the next cycle reports again, and it does not publish EventRegistered.
+func (a *announcer) reportTrainResult(ctx context.Context, result TrainResult) error {
+	ctx, cancel := context.WithTimeout(ctx, ReportTrainResultTimeout)
+	defer cancel()
+
+	return a.updateSchedulerWithRedirect(result.appendToOutgoingContext(a.registrationContext(ctx)), a.updateSchedulerRequest())
+}
+
+// trainSegment uploads a segment of dataset to the trainer of addr, it returns whether
+// the download and network topology have been uploaded completely.
+func (a *announcer) trainSegment(client UploadSink, addr string, downloadDone, networkTopologyDone bool) (bool, bool, error) {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), a.config.Trainer.UploadTimeout)
+	defer cancel()
+
+	a.checkpointMu.Lock()
+	if err := a.resetStaleCheckpoint(); err != nil {
+		a.checkpointMu.Unlock()
+		return false, false, err
+	}
+
+	seg := &segment{
+		uploadDownload:        !downloadDone,
+		uploadNetworkTopology: !networkTopologyDone,
+		downloadStart:         a.checkpoint.DownloadOffset,
+		networkTopologyStart:  a.checkpoint.NetworkTopologyOffset,
+		downloadDone:          downloadDone,
+		networkTopologyDone:   networkTopologyDone,
+	}
+	a.checkpointMu.Unlock()
+
+	// The datasets are signed before opening the train stream, because the signature
+	// is sent in the metadata of stream, which is sent before the datasets.
+	if a.signingKey != nil {
+		if err := a.signSegment(seg, a.config.Trainer.UploadSegmentSize); err != nil {
+			return false, false, fmt.Errorf("sign datasets of trainer %s: %w", addr, err)
+		}
+
+		ctx = appendSignatureToOutgoingContext(ctx, seg.signature)
+	}
+
+	stream, err := a.openTrainStream(ctx, cancel, client)
+	if err != nil {
+		if errors.Is(err, ErrTrainStreamOpenTimeout) {
+			a.log.Warnf("open train stream to trainer %s timeout after %s", addr, a.config.Trainer.StreamOpenTimeout)
+			metrics.TrainStreamOpenTimeoutCount.WithLabelValues(addr).Inc()
+		}
+
+		return false, false, fmt.Errorf("open train stream to trainer %s: %w", addr, err)
+	}
+
+	// The shadow stream is confirmed only if the primary stream is confirmed, otherwise
+	// the segment is sent again and the shadow trainer would receive it twice.
+	var confirmed bool
+	if shadow := a.openShadowStream(); shadow != nil {
+		stream = &teeStream{Trainer_TrainClient: stream, shadow: shadow}
+		defer func() { a.closeShadowStream(shadow, confirmed) }()
+	}
+
+	if seg.signature != nil {
+		err = a.sendRanges(stream, addr, seg)
+	} else {
+		err = a.sendSegment(stream, addr, seg, a.config.Trainer.UploadSegmentSize, a.config.Trainer.UploadSegmentSize)
+	}
+	if err != nil {
+		return false, false, err
+	}
+
+	// Canceling ctx aborts the train stream, so the data sent is discarded by trainer.
+	if a.budget.isExceeded() && a.config.Trainer.CycleBudgetPolicy == config.TrainerCycleBudgetPolicyAbort {
+		return false, false, fmt.Errorf("abort train stream of trainer %s: %w", addr, ErrCycleBudgetExceeded)
+	}
+
+	// All data is sent, but the trainer may not have received it if the stream is not
+	// confirmed. The segment is replayed on a fresh stream if the error is retryable,
+	// then the checkpoint advances only with optimistic policy.
+	if _, err := stream.CloseAndRecv(); err != nil {
+		if err = a.retryFinalize(client, addr, seg, err); err != nil {
+			err = fmt.Errorf("close train stream of trainer %s: %w: %s", addr, ErrTrainUnconfirmed, err.Error())
+			if a.config.Trainer.FinalizePolicy != config.TrainerFinalizePolicyOptimistic {
+				return false, false, err
+			}
+
+			a.log.Warnf("%s, advance checkpoint optimistically", err)
+			if err := a.advanceCheckpoint(seg.uploadDownload, seg.uploadNetworkTopology, seg.downloadEnd, seg.networkTopologyEnd); err != nil {
+				return false, false, err
+			}
+
+			return false, false, err
+		}
+	}
+	confirmed = true
+
+	elapsed := time.Since(start)
+	a.log.Debugf("upload segment to download offset %d and network topology offset %d in %s",
+		seg.downloadEnd, seg.networkTopologyEnd, elapsed)
+	metrics.TrainUploadDuration.WithLabelValues(addr).Observe(float64(elapsed.Milliseconds()))
+	a.warnUploadNearTimeout(addr, elapsed)
+
+	if err := a.advanceCheckpoint(seg.uploadDownload, seg.uploadNetworkTopology, seg.downloadEnd, seg.networkTopologyEnd); err != nil {
+		return false, false, err
+	}
+
+	return seg.downloadDone, seg.networkTopologyDone, nil
+}
+
+// openShadowStream opens the train stream to the shadow trainer, it returns nil if there is
+// no shadow trainer or the stream fails to open.
+func (a *announcer) openShadowStream() *shadowStream {
+	if a.shadowTrainerClient == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.config.Trainer.UploadTimeout)
+	stream, err := a.openTrainStream(ctx, cancel, a.shadowTrainerClient)
+	if err != nil {
+		cancel()
+		a.log.Warnf("open train stream to shadow trainer failed: %s", err)
+		metrics.TrainShadowFailureCount.Inc()
+		return nil
+	}
+
+	return newShadowStream(stream, cancel)
+}
+
+// closeShadowStream closes the shadow stream if the primary stream is confirmed, otherwise
+// it aborts the shadow stream. The failures are only logged.
+func (a *announcer) closeShadowStream(shadow *shadowStream, confirmed bool) {
+	if !confirmed {
+		shadow.abort()
+		return
+	}
+
+	if err := shadow.close(); err != nil {
+		a.log.Warnf("close train stream of shadow trainer failed: %s", err)
+		metrics.TrainShadowFailureCount.Inc()
+	}
+}
+
+// warnUploadNearTimeout logs a warning if the elapsed time of upload exceeds the warning ratio
+// of upload timeout, so that the interval can be tuned before uploads begin to time out.
+func (a *announcer) warnUploadNearTimeout(addr string, elapsed time.Duration) {
+	ratio := a.config.Trainer.UploadTimeoutWarningRatio
+	if ratio <= 0 {
+		return
+	}
+
+	if threshold := time.Duration(float64(a.config.Trainer.UploadTimeout) * ratio); elapsed >= threshold {
+		a.log.Warnf("upload to trainer %s took %s, exceeding %.0f%% of upload timeout %s",
+			addr, elapsed, ratio*100, a.config.Trainer.UploadTimeout)
+		metrics.TrainUploadNearTimeoutCount.WithLabelValues(addr).Inc()
+	}
+}
+
+// advanceCheckpoint updates the offsets of the uploaded datasets in checkpoint, the offsets of the
+// other datasets are kept, because they may be advanced concurrently by their own train streams.
+func (a *announcer) advanceCheckpoint(uploadDownload, uploadNetworkTopology bool, downloadOffset, networkTopologyOffset int64) error {
+	a.checkpointMu.Lock()
+	defer a.checkpointMu.Unlock()
+
+	if !uploadDownload {
+		downloadOffset = a.checkpoint.DownloadOffset
+	}
+
+	if !uploadNetworkTopology {
+		networkTopologyOffset = a.checkpoint.NetworkTopologyOffset
+	}
+
+	return a.updateCheckpoint(downloadOffset, networkTopologyOffset)
+}
+
+// startFullSync resets the offsets of checkpoint if the full sync interval has elapsed since
+// the last full sync, so that the whole dataset is uploaded from the beginning. A full sync
+// interrupted by failures or budget is resumed from the checkpoint like incremental uploading.
+func (a *announcer) startFullSync(addr string) error {
+	interval := a.config.Trainer.FullSyncInterval
+	if interval <= 0 {
+		return nil
+	}
+
+	a.checkpointMu.Lock()
+	defer a.checkpointMu.Unlock()
+
+	if time.Since(a.checkpoint.FullSyncAt) < interval {
+		return nil
+	}
+
+	a.log.Infof("start full sync to trainer %s, last full sync is at %s", addr, a.checkpoint.FullSyncAt)
+	metrics.TrainFullSyncCount.WithLabelValues(addr).Inc()
+	a.checkpoint.FullSyncAt = time.Now()
+	return a.updateCheckpoint(0, 0)
+}
+
+// resetStaleCheckpoint resets the offsets of checkpoint whose epochs differ from the epochs of
+// storage, because the histories of the offsets are lost, e.g. the storage is cleared.
+func (a *announcer) resetStaleCheckpoint() error {
+	originStorage, ok := a.storage.(storage.OriginStorage)
+	if !ok {
+		return nil
+	}
+
+	downloadOffset, networkTopologyOffset := a.checkpoint.DownloadOffset, a.checkpoint.NetworkTopologyOffset
+	downloadEpoch, networkTopologyEpoch := originStorage.DownloadEpoch(), originStorage.NetworkTopologyEpoch()
+	if downloadEpoch == a.checkpoint.DownloadEpoch && networkTopologyEpoch == a.checkpoint.NetworkTopologyEpoch {
+		return nil
+	}
+
+	if downloadEpoch != a.checkpoint.DownloadEpoch {
+		a.log.Infof("history of download changes from epoch %q to %q, upload from the beginning", a.checkpoint.DownloadEpoch, downloadEpoch)
+		downloadOffset = 0
+	}
+
+	if networkTopologyEpoch != a.checkpoint.NetworkTopologyEpoch {
+		a.log.Infof("history of network topology changes from epoch %q to %q, upload from the beginning", a.checkpoint.NetworkTopologyEpoch, networkTopologyEpoch)
+		networkTopologyOffset = 0
+	}
+
+	a.checkpoint.DownloadEpoch, a.checkpoint.NetworkTopologyEpoch = downloadEpoch, networkTopologyEpoch
+	return a.updateCheckpoint(downloadOffset, networkTopologyOffset)
+}
+
+// updateCheckpoint updates the checkpoint with offsets and saves it to file.
+func (a *announcer) updateCheckpoint(downloadOffset, networkTopologyOffset int64) error {
+	a.checkpoint = Checkpoint{
+		DownloadOffset:        downloadOffset,
+		NetworkTopologyOffset: networkTopologyOffset,
+		DownloadEpoch:         a.checkpoint.DownloadEpoch,
+		NetworkTopologyEpoch:  a.checkpoint.NetworkTopologyEpoch,
+		UpdatedAt:             time.Now(),
+		FullSyncAt:            a.checkpoint.FullSyncAt,
+	}
+
+	if a.checkpointFilename != "" {
+		if err := saveCheckpoint(a.checkpointFilename, a.checkpoint); err != nil {
+			return fmt.Errorf("save checkpoint: %w", err)
 		}
 	}
 
 	return nil
 }
+
+// openTrainStream opens the train stream with ctx, it cancels ctx and returns ErrTrainStreamOpenTimeout
+// if the stream is not opened within the stream open timeout. The stream can not be opened with a
+// sub-context of ctx, because canceling the sub-context closes the stream.
+func (a *announcer) openTrainStream(ctx context.Context, cancel context.CancelFunc, client UploadSink) (trainerv1.Trainer_TrainClient, error) {
+	if a.config.Trainer.StreamOpenTimeout <= 0 {
+		return client.Train(ctx, a.trainCallOptions()...)
+	}
+
+	type result struct {
+		stream trainerv1.Trainer_TrainClient
+		err    error
+	}
+
+	resultCh := make(chan result, 1)
+	go func() {
+		stream, err := client.Train(ctx, a.trainCallOptions()...)
+		resultCh <- result{stream, err}
+	}()
+
+	timer := time.NewTimer(a.config.Trainer.StreamOpenTimeout)
+	defer timer.Stop()
+
+	select {
+	case r := <-resultCh:
+		return r.stream, r.err
+	case <-timer.C:
+		cancel()
+		return nil, ErrTrainStreamOpenTimeout
+	}
+}
+
+// trainCallOptions returns the call options of train stream.
+func (a *announcer) trainCallOptions() []grpc.CallOption {
+	var opts []grpc.CallOption
+	if a.config.Trainer.Compressor != "" {
+		opts = append(opts, grpc.UseCompressor(a.config.Trainer.Compressor))
+	}
+
+	return opts
+}
+
+// uploadDownloadToTrainer uploads at most limit bytes of download information to trainer from offset,
+// limit less than or equal to zero means no limit. It returns the offset of uploaded download information
+// and whether the download information has been uploaded completely.
+func (a *announcer) uploadDownloadToTrainer(stream trainerv1.Trainer_TrainClient, offset, limit int64) (int64, bool, error) {
+	_, end, done, err := a.uploadDownloadRange(stream, offset, limit, false)
+	return end, done, err
+}
+
+// uploadDownloadRange uploads at most limit bytes of download information to trainer from offset like
+// uploadDownloadToTrainer, it also returns the start offset of uploaded range, which is zero if the
+// download information is shorter than offset and uploaded from the beginning. If replay is true, the range
+// has been uploaded before, so it is neither counted in train result nor charged to the cycle budget again.
+func (a *announcer) uploadDownloadRange(stream trainerv1.Trainer_TrainClient, offset, limit int64, replay bool) (int64, int64, bool, error) {
+	const dataset = DownloadDataset
+	readCloser, offset, err := a.openWithOffset(a.openDownload, offset)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	defer readCloser.Close()
+
+	source := &sourceReader{reader: readCloser}
+	if limit > 0 {
+		source.reader = io.LimitReader(readCloser, limit)
+	}
+
+	budget := a.budget
+	if replay {
+		budget = nil
+	}
+
+	if budget != nil {
+		source.reader = &budgetReader{reader: source.reader, budget: budget}
+	}
+	reader, pipeline := applyCountedUploadTransforms(source, a.uploadTransforms, dataset)
+
+	chunkSize, err := a.uploadChunkSize()
+	if err != nil {
+		return 0, 0, false, err
+	}
+
+	buf := make([]byte, chunkSize)
+	for {
+		// Read may return data together with an error, send the data before handling the error.
+		n, err := a.readChunk(reader, buf)
+		if n > 0 {
+			start := time.Now()
+			if err := stream.Send(&trainerv1.TrainRequest{
+				Hostname:  a.hostname,
+				Ip:        a.config.Server.AdvertiseIP.String(),
+				ClusterId: a.trainerClusterID(),
+				Request: &trainerv1.TrainRequest_TrainMlpRequest{
+					TrainMlpRequest: &trainerv1.TrainMLPRequest{
+						Dataset: buf[:n],
+					},
+				},
+			}); err != nil {
+				return 0, 0, false, err
+			}
+			if !replay {
+				a.trainResult.DownloadBytes += int64(n)
+				metrics.TrainUploadBytesCount.WithLabelValues(metrics.TrainDatasetDownload).Add(float64(n))
+			}
+			a.log.Debugf("send %d bytes of %s in %s", n, dataset, time.Since(start))
+		}
+
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+
+			return 0, 0, false, unwrapSourceError(err)
+		}
+	}
+
+	done := (limit <= 0 || source.n < limit) && !budget.isExceeded()
+	counts := pipeline.counts(done)
+	if !replay {
+		a.trainResult.DownloadRecords.add(counts)
+	}
+	a.log.Debugf("%s records read %d, dropped %v, sent %d", dataset, counts.Read, counts.Dropped, counts.Sent)
+	return offset, offset + source.n, done, nil
+}
+
+// uploadNetworkTopologyToTrainer uploads at most limit bytes of network topology to trainer from offset,
+// limit less than or equal to zero means no limit. It returns the offset of uploaded network topology
+// and whether the network topology has been uploaded completely.
+func (a *announcer) uploadNetworkTopologyToTrainer(stream trainerv1.Trainer_TrainClient, offset, limit int64) (int64, bool, error) {
+	_, end, done, err := a.uploadNetworkTopologyRange(stream, offset, limit, false)
+	return end, done, err
+}
+
+// uploadNetworkTopologyRange uploads at most limit bytes of network topology to trainer from offset like
+// uploadNetworkTopologyToTrainer, it also returns the start offset of uploaded range, which is zero if
+// the network topology is shorter than offset and uploaded from the beginning. If replay is true, the range
+// has been uploaded before, so it is neither counted in train result nor charged to the cycle budget again.
+func (a *announcer) uploadNetworkTopologyRange(stream trainerv1.Trainer_TrainClient, offset, limit int64, replay bool) (int64, int64, bool, error) {
+	const dataset = NetworkTopologyDataset
+	readCloser, offset, err := a.openWithOffset(a.openNetworkTopology, offset)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	defer readCloser.Close()
+
+	source := &sourceReader{reader: readCloser}
+	if limit > 0 {
+		source.reader = io.LimitReader(readCloser, limit)
+	}
+
+	budget := a.budget
+	if replay {
+		budget = nil
+	}
+
+	if budget != nil {
+		source.reader = &budgetReader{reader: source.reader, budget: budget}
+	}
+	reader, pipeline := applyCountedUploadTransforms(source, a.uploadTransforms, dataset)
+
+	chunkSize, err := a.uploadChunkSize()
+	if err != nil {
+		return 0, 0, false, err
+	}
+
+	buf := make([]byte, chunkSize)
+	for {
+		// Read may return data together with an error, send the data before handling the error.
+		n, err := a.readChunk(reader, buf)
+		if n > 0 {
+			start := time.Now()
+			if err := stream.Send(&trainerv1.TrainRequest{
+				Hostname:  a.hostname,
+				Ip:        a.config.Server.AdvertiseIP.String(),
+				ClusterId: a.trainerClusterID(),
+				Request: &trainerv1.TrainRequest_TrainGnnRequest{
+					TrainGnnRequest: &trainerv1.TrainGNNRequest{
+						Dataset: buf[:n],
+					},
+				},
+			}); err != nil {
+				return 0, 0, false, err
+			}
+			if !replay {
+				a.trainResult.NetworkTopologyBytes += int64(n)
+				metrics.TrainUploadBytesCount.WithLabelValues(metrics.TrainDatasetNetworkTopology).Add(float64(n))
+			}
+			a.log.Debugf("send %d bytes of %s in %s", n, dataset, time.Since(start))
+		}
+
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+
+			return 0, 0, false, unwrapSourceError(err)
+		}
+	}
+
+	done := (limit <= 0 || source.n < limit) && !budget.isExceeded()
+	counts := pipeline.counts(done)
+	if !replay {
+		a.trainResult.NetworkTopologyRecords.add(counts)
+	}
+	a.log.Debugf("%s records read %d, dropped %v, sent %d", dataset, counts.Read, counts.Dropped, counts.Sent)
+	return offset, offset + source.n, done, nil
+}
+
+// trainerClusterID returns the cluster id sent to trainer, it falls back to the
+// scheduler cluster id if the trainer cluster id is not configured.
+func (a *announcer) trainerClusterID() uint64 {
+	if a.config.Trainer.ClusterID != 0 {
+		return uint64(a.config.Trainer.ClusterID)
+	}
+
+	return uint64(a.config.Manager.SchedulerClusterID)
+}
+
+// readChunk reads a chunk of dataset into buf. If upload batching is enabled, the reads are
+// coalesced until buf is full, so that the small reads are sent in fewer messages.
+func (a *announcer) readChunk(reader io.Reader, buf []byte) (int, error) {
+	if a.config.Trainer.UploadBatchSize <= 0 {
+		return reader.Read(buf)
+	}
+
+	var n int
+	for n < len(buf) {
+		m, err := reader.Read(buf[n:])
+		n += m
+		if err != nil {
+			return n, err
+		}
+	}
+
+	return n, nil
+}
+
+// uploadChunkSize returns the max size of dataset in each message, so that the message
+// including its metadata does not exceed the max message size and the upload batch size.
+// The dataset is split at byte boundary, so an oversized record is sent in multiple messages.
+func (a *announcer) uploadChunkSize() (int, error) {
+	size, err := a.maxChunkSize()
+	if err != nil {
+		return 0, err
+	}
+
+	if batchSize := a.config.Trainer.UploadBatchSize; batchSize > 0 && batchSize < size {
+		return batchSize, nil
+	}
+
+	return size, nil
+}
+
+// maxChunkSize returns the max size of dataset in each message limited by the max message size.
+func (a *announcer) maxChunkSize() (int, error) {
+	if a.config.Trainer.MaxMessageSize <= 0 {
+		return UploadBufferSize, nil
+	}
+
+	// Both datasets have the same metadata size, the headroom covers the tags and
+	// the length prefixes of dataset.
+	size := a.config.Trainer.MaxMessageSize - messageHeadroom - proto.Size(&trainerv1.TrainRequest{
+		Hostname:  a.hostname,
+		Ip:        a.config.Server.AdvertiseIP.String(),
+		ClusterId: a.trainerClusterID(),
+		Request: &trainerv1.TrainRequest_TrainMlpRequest{
+			TrainMlpRequest: &trainerv1.TrainMLPRequest{},
+		},
+	})
+	if size <= 0 {
+		return 0, fmt.Errorf("max message size %d is too small for message metadata", a.config.Trainer.MaxMessageSize)
+	}
+
+	if size > UploadBufferSize {
+		return UploadBufferSize, nil
+	}
+
+	return size, nil
+}
+
+// openDownload opens the download of storage, it also returns the origin of download if the
+// storage locates the records in the history, otherwise the origin is zero.
+func (a *announcer) openDownload() (io.ReadCloser, storage.Origin, error) {
+	if originStorage, ok := a.storage.(storage.OriginStorage); ok {
+		return originStorage.OpenDownloadWithOrigin()
+	}
+
+	readCloser, err := a.storage.OpenDownload()
+	return readCloser, storage.Origin{}, err
+}
+
+// openNetworkTopology opens the network topology of storage, it also returns the origin of network
+// topology if the storage locates the records in the history, otherwise the origin is zero.
+func (a *announcer) openNetworkTopology() (io.ReadCloser, storage.Origin, error) {
+	if originStorage, ok := a.storage.(storage.OriginStorage); ok {
+		return originStorage.OpenNetworkTopologyWithOrigin()
+	}
+
+	readCloser, err := a.storage.OpenNetworkTopology()
+	return readCloser, storage.Origin{}, err
+}
+
+// openWithOffset opens the dataset and discards the bytes before offset, the offsets are in the
+// history of dataset located by the origin. If the records before offset have been evicted, the
+// dataset is uploaded from the origin. If the dataset is shorter than offset, e.g. the storage
+// does not locate the records and has been rotated or cleared, the dataset is reopened and
+// uploaded from the beginning. It returns the offset of the first byte read.
+func (a *announcer) openWithOffset(open func() (io.ReadCloser, storage.Origin, error), offset int64) (io.ReadCloser, int64, error) {
+	readCloser, origin, err := open()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if offset <= origin.Offset {
+		if offset > 0 && offset < origin.Offset {
+			a.log.Warnf("dataset before offset %d is evicted before uploading, upload from offset %d", offset, origin.Offset)
+		}
+
+		return readCloser, origin.Offset, nil
+	}
+
+	if _, err := io.CopyN(io.Discard, readCloser, offset-origin.Offset); err != nil {
+		readCloser.Close()
+		if err != io.EOF {
+			return nil, 0, err
+		}
+
+		a.log.Warnf("dataset is shorter than checkpoint offset %d, upload from the beginning", offset)
+		readCloser, origin, err := open()
+		if err != nil {
+			return nil, 0, err
+		}
+
+		return readCloser, origin.Offset, nil
+	}
+
+	return readCloser, offset, nil
+}
@@ -17,15 +17,46 @@
 package announcer
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"io"
 	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"testing/iotest"
+	"time"
 
+	"github.com/gocarina/gocsv"
 	"github.com/golang/mock/gomock"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
 
+	managerv2 "d7y.io/api/pkg/apis/manager/v2"
+	trainerv1 "d7y.io/api/pkg/apis/trainer/v1"
+	trainerv1mocks "d7y.io/api/pkg/apis/trainer/v1/mocks"
+
+	"d7y.io/dragonfly/v2/pkg/net/fqdn"
+	managerclient "d7y.io/dragonfly/v2/pkg/rpc/manager/client"
 	clientmocks "d7y.io/dragonfly/v2/pkg/rpc/manager/client/mocks"
+	trainerclient "d7y.io/dragonfly/v2/pkg/rpc/trainer/client"
+	trainerclientmocks "d7y.io/dragonfly/v2/pkg/rpc/trainer/client/mocks"
 	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/metrics"
+	"d7y.io/dragonfly/v2/scheduler/storage"
 	storagemocks "d7y.io/dragonfly/v2/scheduler/storage/mocks"
 )
 
@@ -89,6 +120,113 @@ func TestAnnouncer_New(t *testing.T) {
 				assert.Error(err)
 			},
 		},
+		{
+			name: "trainer is enabled without addr",
+			config: &config.Config{
+				Server: config.ServerConfig{
+					Host:          "localhost",
+					AdvertiseIP:   net.ParseIP("127.0.0.1"),
+					AdvertisePort: 8004,
+					Port:          8080,
+				},
+				Manager: config.ManagerConfig{
+					SchedulerClusterID: 1,
+				},
+				Trainer: config.TrainerConfig{
+					Enable: true,
+				},
+			},
+			mock: func(m *clientmocks.MockV2MockRecorder) {},
+			expect: func(t *testing.T, a Announcer, err error) {
+				assert := assert.New(t)
+				assert.ErrorIs(err, ErrTrainerMisconfigured)
+
+				var configErr *TrainerConfigError
+				assert.True(errors.As(err, &configErr))
+				assert.Equal("addr", configErr.Field)
+			},
+		},
+		{
+			name: "trainer is enabled with invalid addr",
+			config: &config.Config{
+				Server: config.ServerConfig{
+					Host:          "localhost",
+					AdvertiseIP:   net.ParseIP("127.0.0.1"),
+					AdvertisePort: 8004,
+					Port:          8080,
+				},
+				Manager: config.ManagerConfig{
+					SchedulerClusterID: 1,
+				},
+				Trainer: config.TrainerConfig{
+					Enable: true,
+					Addr:   "127.0.0.1",
+				},
+			},
+			mock: func(m *clientmocks.MockV2MockRecorder) {},
+			expect: func(t *testing.T, a Announcer, err error) {
+				assert := assert.New(t)
+				assert.ErrorIs(err, ErrTrainerMisconfigured)
+
+				var configErr *TrainerConfigError
+				assert.True(errors.As(err, &configErr))
+				assert.Equal("addr", configErr.Field)
+				assert.Equal("127.0.0.1", configErr.Value)
+			},
+		},
+		{
+			name: "trainer compressor is not registered",
+			config: &config.Config{
+				Server: config.ServerConfig{
+					Host:          "localhost",
+					AdvertiseIP:   net.ParseIP("127.0.0.1"),
+					AdvertisePort: 8004,
+					Port:          8080,
+				},
+				Manager: config.ManagerConfig{
+					SchedulerClusterID: 1,
+				},
+				Trainer: config.TrainerConfig{
+					Enable:     true,
+					Addr:       "127.0.0.1:9090",
+					Compressor: "foo",
+				},
+			},
+			mock: func(m *clientmocks.MockV2MockRecorder) {},
+			expect: func(t *testing.T, a Announcer, err error) {
+				assert := assert.New(t)
+				assert.ErrorIs(err, ErrTrainerMisconfigured)
+
+				var configErr *TrainerConfigError
+				assert.True(errors.As(err, &configErr))
+				assert.Equal("compressor", configErr.Field)
+			},
+		},
+		{
+			name: "trainer is enabled with valid addr",
+			config: &config.Config{
+				Server: config.ServerConfig{
+					Host:          "localhost",
+					AdvertiseIP:   net.ParseIP("127.0.0.1"),
+					AdvertisePort: 8004,
+					Port:          8080,
+				},
+				Manager: config.ManagerConfig{
+					SchedulerClusterID: 1,
+				},
+				Trainer: config.TrainerConfig{
+					Enable: true,
+					Addr:   "127.0.0.1:9090",
+				},
+			},
+			mock: func(m *clientmocks.MockV2MockRecorder) {
+				m.UpdateScheduler(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+			},
+			expect: func(t *testing.T, a Announcer, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -104,3 +242,3177 @@ func TestAnnouncer_New(t *testing.T) {
 		})
 	}
 }
+
+func TestAnnouncer_Checkpoint(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:          "localhost",
+			AdvertiseIP:   net.ParseIP("127.0.0.1"),
+			AdvertisePort: 8004,
+			Port:          8080,
+		},
+		Manager: config.ManagerConfig{
+			SchedulerClusterID: 1,
+		},
+		Trainer: config.TrainerConfig{
+			Enable:                true,
+			Addr:                  "127.0.0.1:9090",
+			Interval:              time.Minute,
+			UploadTimeout:         time.Minute,
+			UploadDownload:        true,
+			UploadNetworkTopology: true,
+		},
+	}
+
+	// train runs a training cycle with the given datasets and returns the uploaded datasets.
+	train := func(t *testing.T, dir, download, networkTopology string) (string, string) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+		mockManagerClient := clientmocks.NewMockV2(ctl)
+		mockTrainerClient := trainerclientmocks.NewMockV1(ctl)
+		mockStream := trainerv1mocks.NewMockTrainer_TrainClient(ctl)
+		mockStorage := storagemocks.NewMockStorage(ctl)
+
+		var (
+			mu                      sync.Mutex
+			uploadedDownload        strings.Builder
+			uploadedNetworkTopology strings.Builder
+		)
+		mockManagerClient.EXPECT().UpdateScheduler(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+		mockTrainerClient.EXPECT().Train(gomock.Any()).Return(mockStream, nil).Times(1)
+		mockStorage.EXPECT().Sync().Return(nil).Times(1)
+		mockStorage.EXPECT().OpenDownload().DoAndReturn(func() (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(download)), nil
+		}).AnyTimes()
+		mockStorage.EXPECT().OpenNetworkTopology().DoAndReturn(func() (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(networkTopology)), nil
+		}).AnyTimes()
+		mockStream.EXPECT().Send(gomock.Any()).DoAndReturn(func(req *trainerv1.TrainRequest) error {
+			mu.Lock()
+			defer mu.Unlock()
+			switch r := req.Request.(type) {
+			case *trainerv1.TrainRequest_TrainMlpRequest:
+				uploadedDownload.Write(r.TrainMlpRequest.Dataset)
+			case *trainerv1.TrainRequest_TrainGnnRequest:
+				uploadedNetworkTopology.Write(r.TrainGnnRequest.Dataset)
+			}
+
+			return nil
+		}).AnyTimes()
+		mockStream.EXPECT().CloseAndRecv().Return(nil, nil).Times(1)
+
+		a, err := New(cfg, mockManagerClient, mockStorage, WithTrainerClient(mockTrainerClient), WithCheckpointDir(dir))
+		assert.NoError(t, err)
+		assert.NoError(t, a.(*announcer).train())
+		return uploadedDownload.String(), uploadedNetworkTopology.String()
+	}
+
+	t.Run("resume from checkpoint after restart", func(t *testing.T) {
+		dir := t.TempDir()
+		download, networkTopology := train(t, dir, "foo", "bar")
+		assert.Equal(t, "foo", download)
+		assert.Equal(t, "bar", networkTopology)
+
+		checkpoint, err := loadCheckpoint(filepath.Join(dir, CheckpointFilename))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), checkpoint.DownloadOffset)
+		assert.Equal(t, int64(3), checkpoint.NetworkTopologyOffset)
+
+		download, networkTopology = train(t, dir, "foobaz", "barqux")
+		assert.Equal(t, "baz", download)
+		assert.Equal(t, "qux", networkTopology)
+	})
+
+	t.Run("dataset is shorter than checkpoint", func(t *testing.T) {
+		dir := t.TempDir()
+		assert.NoError(t, saveCheckpoint(filepath.Join(dir, CheckpointFilename), Checkpoint{DownloadOffset: 100, NetworkTopologyOffset: 100}))
+
+		download, networkTopology := train(t, dir, "foo", "bar")
+		assert.Equal(t, "foo", download)
+		assert.Equal(t, "bar", networkTopology)
+	})
+
+	t.Run("checkpoint is corrupt", func(t *testing.T) {
+		dir := t.TempDir()
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, CheckpointFilename), []byte("{foo"), 0600))
+
+		download, networkTopology := train(t, dir, "foo", "bar")
+		assert.Equal(t, "foo", download)
+		assert.Equal(t, "bar", networkTopology)
+	})
+
+	t.Run("upload incrementally before full sync is due", func(t *testing.T) {
+		cfg.Trainer.FullSyncInterval = time.Hour
+		defer func() { cfg.Trainer.FullSyncInterval = 0 }()
+
+		dir := t.TempDir()
+		fullSyncAt := time.Now().Add(-time.Minute)
+		assert.NoError(t, saveCheckpoint(filepath.Join(dir, CheckpointFilename), Checkpoint{DownloadOffset: 3, NetworkTopologyOffset: 3, FullSyncAt: fullSyncAt}))
+
+		download, networkTopology := train(t, dir, "foobaz", "barqux")
+		assert.Equal(t, "baz", download)
+		assert.Equal(t, "qux", networkTopology)
+
+		checkpoint, err := loadCheckpoint(filepath.Join(dir, CheckpointFilename))
+		assert.NoError(t, err)
+		assert.True(t, fullSyncAt.Equal(checkpoint.FullSyncAt))
+	})
+
+	t.Run("upload from the beginning when full sync is due", func(t *testing.T) {
+		cfg.Trainer.FullSyncInterval = time.Hour
+		defer func() { cfg.Trainer.FullSyncInterval = 0 }()
+
+		dir := t.TempDir()
+		assert.NoError(t, saveCheckpoint(filepath.Join(dir, CheckpointFilename), Checkpoint{DownloadOffset: 3, NetworkTopologyOffset: 3, FullSyncAt: time.Now().Add(-2 * time.Hour)}))
+
+		start := time.Now()
+		download, networkTopology := train(t, dir, "foobaz", "barqux")
+		assert.Equal(t, "foobaz", download)
+		assert.Equal(t, "barqux", networkTopology)
+
+		checkpoint, err := loadCheckpoint(filepath.Join(dir, CheckpointFilename))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(6), checkpoint.DownloadOffset)
+		assert.Equal(t, int64(6), checkpoint.NetworkTopologyOffset)
+		assert.False(t, checkpoint.FullSyncAt.Before(start.Truncate(time.Second)))
+	})
+}
+
+func TestAnnouncer_CheckpointWithStorage(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:          "localhost",
+			AdvertiseIP:   net.ParseIP("127.0.0.1"),
+			AdvertisePort: 8004,
+			Port:          8080,
+		},
+		Manager: config.ManagerConfig{
+			SchedulerClusterID: 1,
+		},
+		Trainer: config.TrainerConfig{
+			Enable:         true,
+			Addr:           "127.0.0.1:9090",
+			Interval:       time.Minute,
+			UploadTimeout:  time.Minute,
+			UploadDownload: true,
+		},
+	}
+
+	// train runs a training cycle with the storage and returns the ids of uploaded downloads.
+	train := func(t *testing.T, dir string, s storage.Storage) []string {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+		mockManagerClient := clientmocks.NewMockV2(ctl)
+		mockTrainerClient := trainerclientmocks.NewMockV1(ctl)
+		mockStream := trainerv1mocks.NewMockTrainer_TrainClient(ctl)
+
+		var (
+			mu       sync.Mutex
+			uploaded bytes.Buffer
+		)
+		mockManagerClient.EXPECT().UpdateScheduler(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+		mockTrainerClient.EXPECT().Train(gomock.Any()).Return(mockStream, nil).Times(1)
+		mockStream.EXPECT().Send(gomock.Any()).DoAndReturn(func(req *trainerv1.TrainRequest) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if r, ok := req.Request.(*trainerv1.TrainRequest_TrainMlpRequest); ok {
+				uploaded.Write(r.TrainMlpRequest.Dataset)
+			}
+
+			return nil
+		}).AnyTimes()
+		mockStream.EXPECT().CloseAndRecv().Return(nil, nil).Times(1)
+
+		a, err := New(cfg, mockManagerClient, s, WithTrainerClient(mockTrainerClient), WithCheckpointDir(dir))
+		assert.NoError(t, err)
+		assert.NoError(t, a.(*announcer).train())
+
+		var downloads []storage.Download
+		if uploaded.Len() > 0 {
+			assert.NoError(t, gocsv.UnmarshalWithoutHeaders(&uploaded, &downloads))
+		}
+
+		ids := []string{}
+		for _, download := range downloads {
+			ids = append(ids, download.ID)
+		}
+		return ids
+	}
+
+	create := func(t *testing.T, s storage.Storage, ids ...string) {
+		for _, id := range ids {
+			assert.NoError(t, s.CreateDownload(storage.Download{ID: id}))
+		}
+	}
+
+	open := func(t *testing.T, baseDir string, options ...storage.Option) storage.Storage {
+		s, err := storage.New(baseDir, config.DefaultStorageMaxSize, config.DefaultStorageMaxBackups, 0, options...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return s
+	}
+
+	t.Run("resume from checkpoint after restart and rotation", func(t *testing.T) {
+		dir, baseDir := t.TempDir(), t.TempDir()
+		s := open(t, baseDir)
+		create(t, s, "0", "1")
+		assert.Equal(t, []string{"0", "1"}, train(t, dir, s))
+
+		// The current file is rotated into a backup after restarting.
+		s = open(t, baseDir)
+		create(t, s, "2")
+		assert.Equal(t, []string{"2"}, train(t, dir, s))
+
+		create(t, s, "3")
+		assert.Equal(t, []string{"3"}, train(t, dir, s))
+	})
+
+	t.Run("resume from checkpoint after eviction", func(t *testing.T) {
+		dir, baseDir := t.TempDir(), t.TempDir()
+		s := open(t, baseDir, storage.WithMaxRecords(3))
+		create(t, s, "0", "1", "2")
+		assert.Equal(t, []string{"0", "1", "2"}, train(t, dir, s))
+
+		s = open(t, baseDir, storage.WithMaxRecords(3))
+		create(t, s, "3", "4")
+		assert.Equal(t, []string{"3", "4"}, train(t, dir, s))
+	})
+
+	t.Run("upload records not evicted if checkpoint is behind eviction", func(t *testing.T) {
+		dir, baseDir := t.TempDir(), t.TempDir()
+		s := open(t, baseDir, storage.WithMaxRecords(2))
+		create(t, s, "0")
+		assert.Equal(t, []string{"0"}, train(t, dir, s))
+
+		create(t, s, "1", "2", "3")
+		assert.Equal(t, []string{"2", "3"}, train(t, dir, s))
+	})
+
+	t.Run("upload from the beginning after clearing storage", func(t *testing.T) {
+		dir, baseDir := t.TempDir(), t.TempDir()
+		s := open(t, baseDir)
+		create(t, s, "0", "1")
+		assert.Equal(t, []string{"0", "1"}, train(t, dir, s))
+
+		assert.NoError(t, s.ClearDownload())
+		s = open(t, baseDir)
+		create(t, s, "2")
+		assert.Equal(t, []string{"2"}, train(t, dir, s))
+
+		checkpoint, err := loadCheckpoint(filepath.Join(dir, CheckpointFilename))
+		assert.NoError(t, err)
+		assert.Equal(t, s.(storage.OriginStorage).DownloadEpoch(), checkpoint.DownloadEpoch)
+	})
+}
+
+func TestAnnouncer_TrainSegment(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:          "localhost",
+			AdvertiseIP:   net.ParseIP("127.0.0.1"),
+			AdvertisePort: 8004,
+			Port:          8080,
+		},
+		Manager: config.ManagerConfig{
+			SchedulerClusterID: 1,
+		},
+		Trainer: config.TrainerConfig{
+			Enable:                true,
+			Addr:                  "127.0.0.1:9090",
+			Interval:              time.Minute,
+			UploadTimeout:         time.Minute,
+			UploadSegmentSize:     3,
+			UploadDownload:        true,
+			UploadNetworkTopology: true,
+		},
+	}
+
+	// train runs a training cycle, the stream of segment failedSegment fails to close,
+	// it returns the uploaded datasets of each segment.
+	train := func(t *testing.T, dir string, failedSegment int) ([]string, []string, error) {
+		ctl := gomock.NewController(t)
+		defer ctl.Finish()
+		mockManagerClient := clientmocks.NewMockV2(ctl)
+		mockTrainerClient := trainerclientmocks.NewMockV1(ctl)
+		mockStorage := storagemocks.NewMockStorage(ctl)
+
+		var (
+			mu                sync.Mutex
+			downloads         []string
+			networkTopologies []string
+		)
+		mockManagerClient.EXPECT().UpdateScheduler(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+		mockStorage.EXPECT().Sync().Return(nil).Times(1)
+		mockStorage.EXPECT().OpenDownload().DoAndReturn(func() (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader("foobar")), nil
+		}).AnyTimes()
+		mockStorage.EXPECT().OpenNetworkTopology().DoAndReturn(func() (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader("bazqux")), nil
+		}).AnyTimes()
+		mockTrainerClient.EXPECT().Train(gomock.Any()).DoAndReturn(func(_ any, _ ...any) (trainerv1.Trainer_TrainClient, error) {
+			segment := len(downloads)
+			downloads = append(downloads, "")
+			networkTopologies = append(networkTopologies, "")
+
+			mockStream := trainerv1mocks.NewMockTrainer_TrainClient(ctl)
+			mockStream.EXPECT().Send(gomock.Any()).DoAndReturn(func(req *trainerv1.TrainRequest) error {
+				mu.Lock()
+				defer mu.Unlock()
+				switch r := req.Request.(type) {
+				case *trainerv1.TrainRequest_TrainMlpRequest:
+					downloads[segment] += string(r.TrainMlpRequest.Dataset)
+				case *trainerv1.TrainRequest_TrainGnnRequest:
+					networkTopologies[segment] += string(r.TrainGnnRequest.Dataset)
+				}
+
+				return nil
+			}).AnyTimes()
+
+			if segment == failedSegment {
+				mockStream.EXPECT().CloseAndRecv().Return(nil, errors.New("foo")).Times(1)
+			} else {
+				mockStream.EXPECT().CloseAndRecv().Return(nil, nil).Times(1)
+			}
+
+			return mockStream, nil
+		}).AnyTimes()
+
+		a, err := New(cfg, mockManagerClient, mockStorage, WithTrainerClient(mockTrainerClient), WithCheckpointDir(dir))
+		assert.NoError(t, err)
+		err = a.(*announcer).train()
+		return downloads, networkTopologies, err
+	}
+
+	t.Run("upload in segments", func(t *testing.T) {
+		downloads, networkTopologies, err := train(t, t.TempDir(), -1)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"foo", "bar", ""}, downloads)
+		assert.Equal(t, []string{"baz", "qux", ""}, networkTopologies)
+	})
+
+	t.Run("resume from the last completed segment", func(t *testing.T) {
+		dir := t.TempDir()
+		downloads, networkTopologies, err := train(t, dir, 1)
+		assert.Error(t, err)
+		assert.Equal(t, []string{"foo", "bar"}, downloads)
+		assert.Equal(t, []string{"baz", "qux"}, networkTopologies)
+
+		checkpoint, err := loadCheckpoint(filepath.Join(dir, CheckpointFilename))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), checkpoint.DownloadOffset)
+		assert.Equal(t, int64(3), checkpoint.NetworkTopologyOffset)
+
+		downloads, networkTopologies, err = train(t, dir, -1)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"bar", ""}, downloads)
+		assert.Equal(t, []string{"qux", ""}, networkTopologies)
+	})
+}
+
+func TestAnnouncer_ListTrainerEndpoints(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:          "localhost",
+			AdvertiseIP:   net.ParseIP("127.0.0.1"),
+			AdvertisePort: 8004,
+			Port:          8080,
+		},
+		Manager: config.ManagerConfig{
+			SchedulerClusterID: 1,
+		},
+		Trainer: config.TrainerConfig{
+			Enable: true,
+			Addr:   "127.0.0.1:9090",
+		},
+	}
+
+	tests := []struct {
+		name   string
+		mock   func(m *clientmocks.MockV2MockRecorder)
+		expect func(t *testing.T, a *announcer, dialed []string)
+	}{
+		{
+			name: "discover trainers from manager",
+			mock: func(m *clientmocks.MockV2MockRecorder) {
+				m.GetScheduler(gomock.Any(), gomock.Any()).Return(&managerv2.Scheduler{
+					SchedulerCluster: &managerv2.SchedulerCluster{
+						Config: []byte(`{"trainer_addrs":["10.0.0.1:9090","10.0.0.2:9090","foo"]}`),
+					},
+				}, nil).Times(2)
+			},
+			expect: func(t *testing.T, a *announcer, dialed []string) {
+				assert := assert.New(t)
+				assert.Equal([]string{"10.0.0.1:9090"}, dialed)
+				assert.Equal("10.0.0.1:9090", a.discoveredTrainerAddr)
+				client, addr := a.activeTrainer()
+				assert.Equal(a.discoveredTrainerClient, client)
+				assert.Equal("10.0.0.1:9090", addr)
+
+				endpoints, err := a.ListTrainerEndpoints(context.Background())
+				assert.NoError(err)
+				assert.Equal([]string{"10.0.0.1:9090", "10.0.0.2:9090"}, endpoints)
+			},
+		},
+		{
+			name: "fall back to the configured trainer",
+			mock: func(m *clientmocks.MockV2MockRecorder) {
+				m.GetScheduler(gomock.Any(), gomock.Any()).Return(&managerv2.Scheduler{
+					SchedulerCluster: &managerv2.SchedulerCluster{
+						Config: []byte(`{"candidate_parent_limit":4}`),
+					},
+				}, nil).Times(2)
+			},
+			expect: func(t *testing.T, a *announcer, dialed []string) {
+				assert := assert.New(t)
+				assert.Empty(dialed)
+				client, addr := a.activeTrainer()
+				assert.Equal(a.trainerClient, client)
+				assert.Equal("127.0.0.1:9090", addr)
+
+				endpoints, err := a.ListTrainerEndpoints(context.Background())
+				assert.NoError(err)
+				assert.Equal([]string{"127.0.0.1:9090"}, endpoints)
+			},
+		},
+		{
+			name: "get scheduler failed",
+			mock: func(m *clientmocks.MockV2MockRecorder) {
+				m.GetScheduler(gomock.Any(), gomock.Any()).Return(nil, errors.New("foo")).Times(2)
+			},
+			expect: func(t *testing.T, a *announcer, dialed []string) {
+				assert := assert.New(t)
+				assert.Empty(dialed)
+				client, addr := a.activeTrainer()
+				assert.Equal(a.trainerClient, client)
+				assert.Equal("127.0.0.1:9090", addr)
+
+				_, err := a.ListTrainerEndpoints(context.Background())
+				assert.Error(err)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockManagerClient := clientmocks.NewMockV2(ctl)
+			mockTrainerClient := trainerclientmocks.NewMockV1(ctl)
+			mockStorage := storagemocks.NewMockStorage(ctl)
+			mockManagerClient.EXPECT().UpdateScheduler(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+			tc.mock(mockManagerClient.EXPECT())
+
+			var dialed []string
+			a, err := New(cfg, mockManagerClient, mockStorage, WithTrainerClient(mockTrainerClient),
+				WithTrainerDialer(func(ctx context.Context, addr string) (trainerclient.V1, error) {
+					dialed = append(dialed, addr)
+					return trainerclientmocks.NewMockV1(ctl), nil
+				}))
+			assert.NoError(t, err)
+			tc.expect(t, a.(*announcer), dialed)
+		})
+	}
+}
+
+// mockChunkReader returns a chunk on each read, the last chunk is returned together with io.EOF.
+type mockChunkReader struct {
+	chunks []string
+}
+
+func (r *mockChunkReader) Read(p []byte) (int, error) {
+	if len(r.chunks) == 0 {
+		return 0, io.EOF
+	}
+
+	// The rest of chunk is read by the next read if p is shorter than chunk.
+	n := copy(p, r.chunks[0])
+	if n < len(r.chunks[0]) {
+		r.chunks[0] = r.chunks[0][n:]
+		return n, nil
+	}
+
+	r.chunks = r.chunks[1:]
+	if len(r.chunks) == 0 {
+		return n, io.EOF
+	}
+
+	return n, nil
+}
+
+func (r *mockChunkReader) Close() error {
+	return nil
+}
+
+func TestAnnouncer_UploadPartialRead(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:        "localhost",
+			AdvertiseIP: net.ParseIP("127.0.0.1"),
+		},
+		Manager: config.ManagerConfig{
+			SchedulerClusterID: 1,
+		},
+	}
+
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	mockStorage := storagemocks.NewMockStorage(ctl)
+	mockStream := trainerv1mocks.NewMockTrainer_TrainClient(ctl)
+
+	var downloads, networkTopologies []string
+	mockStorage.EXPECT().OpenDownload().Return(&mockChunkReader{chunks: []string{"fo", "o", "bar"}}, nil).Times(1)
+	mockStorage.EXPECT().OpenNetworkTopology().Return(&mockChunkReader{chunks: []string{"baz", "qux"}}, nil).Times(1)
+	mockStream.EXPECT().Send(gomock.Any()).DoAndReturn(func(req *trainerv1.TrainRequest) error {
+		switch r := req.Request.(type) {
+		case *trainerv1.TrainRequest_TrainMlpRequest:
+			downloads = append(downloads, string(r.TrainMlpRequest.Dataset))
+		case *trainerv1.TrainRequest_TrainGnnRequest:
+			networkTopologies = append(networkTopologies, string(r.TrainGnnRequest.Dataset))
+		}
+
+		return nil
+	}).Times(5)
+
+	a := &announcer{config: cfg, storage: mockStorage, log: zap.NewNop().Sugar()}
+	offset, done, err := a.uploadDownloadToTrainer(mockStream, 0, 0)
+	assert.NoError(t, err)
+	assert.True(t, done)
+	assert.Equal(t, int64(6), offset)
+	assert.Equal(t, []string{"fo", "o", "bar"}, downloads)
+
+	offset, done, err = a.uploadNetworkTopologyToTrainer(mockStream, 0, 0)
+	assert.NoError(t, err)
+	assert.True(t, done)
+	assert.Equal(t, int64(6), offset)
+	assert.Equal(t, []string{"baz", "qux"}, networkTopologies)
+}
+
+func TestAnnouncer_WithLogLevel(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:        "localhost",
+			AdvertiseIP: net.ParseIP("127.0.0.1"),
+		},
+		Manager: config.ManagerConfig{
+			SchedulerClusterID: 1,
+		},
+	}
+
+	tests := []struct {
+		name    string
+		options []Option
+		expect  func(t *testing.T, logs *observer.ObservedLogs)
+	}{
+		{
+			name: "debug logs are suppressed by default",
+			expect: func(t *testing.T, logs *observer.ObservedLogs) {
+				assert := assert.New(t)
+				assert.Equal(0, logs.FilterLevelExact(zapcore.DebugLevel).Len())
+			},
+		},
+		{
+			name:    "debug logs are emitted with debug level",
+			options: []Option{WithLogLevel(zapcore.DebugLevel)},
+			expect: func(t *testing.T, logs *observer.ObservedLogs) {
+				assert := assert.New(t)
+				assert.Equal(1, logs.FilterLevelExact(zapcore.DebugLevel).FilterMessageSnippet("bytes of download").Len())
+			},
+		},
+		{
+			name:    "info logs are suppressed with error level",
+			options: []Option{WithLogLevel(zapcore.ErrorLevel)},
+			expect: func(t *testing.T, logs *observer.ObservedLogs) {
+				assert := assert.New(t)
+				assert.Equal(0, logs.Len())
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockManagerClient := clientmocks.NewMockV2(ctl)
+			mockStorage := storagemocks.NewMockStorage(ctl)
+			mockStream := trainerv1mocks.NewMockTrainer_TrainClient(ctl)
+			mockManagerClient.EXPECT().UpdateScheduler(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+			mockStorage.EXPECT().OpenDownload().Return(io.NopCloser(strings.NewReader("foo")), nil).Times(1)
+			mockStream.EXPECT().Send(gomock.Any()).Return(nil).Times(1)
+
+			core, logs := observer.New(zapcore.InfoLevel)
+			options := append([]Option{WithLogger(zap.New(core).Sugar())}, tc.options...)
+			a, err := New(cfg, mockManagerClient, mockStorage, options...)
+			assert.NoError(t, err)
+
+			_, _, err = a.(*announcer).uploadDownloadToTrainer(mockStream, 0, 0)
+			assert.NoError(t, err)
+			tc.expect(t, logs)
+		})
+	}
+}
+
+func TestAnnouncer_Standalone(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:        "localhost",
+			AdvertiseIP: net.ParseIP("127.0.0.1"),
+		},
+		Trainer: config.TrainerConfig{
+			Enable:                true,
+			Addr:                  "127.0.0.1:9090",
+			Interval:              time.Minute,
+			UploadTimeout:         time.Minute,
+			UploadDownload:        true,
+			UploadNetworkTopology: true,
+		},
+	}
+
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	mockTrainerClient := trainerclientmocks.NewMockV1(ctl)
+	mockStream := trainerv1mocks.NewMockTrainer_TrainClient(ctl)
+	mockStorage := storagemocks.NewMockStorage(ctl)
+	mockTrainerClient.EXPECT().Train(gomock.Any()).Return(mockStream, nil).Times(1)
+	mockStorage.EXPECT().Sync().Return(nil).Times(1)
+	mockStorage.EXPECT().OpenDownload().Return(io.NopCloser(strings.NewReader("foo")), nil).Times(1)
+	mockStorage.EXPECT().OpenNetworkTopology().Return(io.NopCloser(strings.NewReader("bar")), nil).Times(1)
+	mockStream.EXPECT().Send(gomock.Any()).Return(nil).Times(2)
+	mockStream.EXPECT().CloseAndRecv().Return(nil, nil).Times(1)
+
+	a, err := New(cfg, nil, mockStorage, WithStandalone(true), WithTrainerClient(mockTrainerClient),
+		WithTrainerDialer(func(ctx context.Context, addr string) (trainerclient.V1, error) {
+			t.Fatal("trainer should not be discovered in standalone mode")
+			return nil, nil
+		}))
+	assert.NoError(t, err)
+
+	endpoints, err := a.ListTrainerEndpoints(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"127.0.0.1:9090"}, endpoints)
+	assert.NoError(t, a.(*announcer).train())
+
+	assert.NoError(t, a.Stop())
+	assert.NoError(t, a.Serve())
+}
+
+func TestAnnouncer_trainCallOptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		compressor string
+		expect     func(t *testing.T, opts []grpc.CallOption)
+	}{
+		{
+			name: "compressor is empty",
+			expect: func(t *testing.T, opts []grpc.CallOption) {
+				assert := assert.New(t)
+				assert.Empty(opts)
+			},
+		},
+		{
+			name:       "compressor is gzip",
+			compressor: "gzip",
+			expect: func(t *testing.T, opts []grpc.CallOption) {
+				assert := assert.New(t)
+				assert.Equal(1, len(opts))
+				assert.Equal("gzip", opts[0].(grpc.CompressorCallOption).CompressorType)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			a := &announcer{config: &config.Config{Trainer: config.TrainerConfig{Compressor: tc.compressor}}}
+			tc.expect(t, a.trainCallOptions())
+		})
+	}
+}
+
+func TestAnnouncer_TrainDatasetToggles(t *testing.T) {
+	tests := []struct {
+		name                  string
+		uploadDownload        bool
+		uploadNetworkTopology bool
+		mock                  func(ms *storagemocks.MockStorageMockRecorder, mt *trainerclientmocks.MockV1MockRecorder, stream *trainerv1mocks.MockTrainer_TrainClient)
+	}{
+		{
+			name:                  "upload download and network topology",
+			uploadDownload:        true,
+			uploadNetworkTopology: true,
+			mock: func(ms *storagemocks.MockStorageMockRecorder, mt *trainerclientmocks.MockV1MockRecorder, stream *trainerv1mocks.MockTrainer_TrainClient) {
+				mt.Train(gomock.Any()).Return(stream, nil).Times(1)
+				ms.Sync().Return(nil).Times(1)
+				ms.OpenDownload().Return(io.NopCloser(strings.NewReader("foo")), nil).Times(1)
+				ms.OpenNetworkTopology().Return(io.NopCloser(strings.NewReader("bar")), nil).Times(1)
+				stream.EXPECT().Send(gomock.Any()).Return(nil).Times(2)
+				stream.EXPECT().CloseAndRecv().Return(nil, nil).Times(1)
+			},
+		},
+		{
+			name:           "upload download only",
+			uploadDownload: true,
+			mock: func(ms *storagemocks.MockStorageMockRecorder, mt *trainerclientmocks.MockV1MockRecorder, stream *trainerv1mocks.MockTrainer_TrainClient) {
+				mt.Train(gomock.Any()).Return(stream, nil).Times(1)
+				ms.Sync().Return(nil).Times(1)
+				ms.OpenDownload().Return(io.NopCloser(strings.NewReader("foo")), nil).Times(1)
+				stream.EXPECT().Send(gomock.Any()).Return(nil).Times(1)
+				stream.EXPECT().CloseAndRecv().Return(nil, nil).Times(1)
+			},
+		},
+		{
+			name:                  "upload network topology only",
+			uploadNetworkTopology: true,
+			mock: func(ms *storagemocks.MockStorageMockRecorder, mt *trainerclientmocks.MockV1MockRecorder, stream *trainerv1mocks.MockTrainer_TrainClient) {
+				mt.Train(gomock.Any()).Return(stream, nil).Times(1)
+				ms.Sync().Return(nil).Times(1)
+				ms.OpenNetworkTopology().Return(io.NopCloser(strings.NewReader("bar")), nil).Times(1)
+				stream.EXPECT().Send(gomock.Any()).Return(nil).Times(1)
+				stream.EXPECT().CloseAndRecv().Return(nil, nil).Times(1)
+			},
+		},
+		{
+			name: "skip training if both are disabled",
+			mock: func(ms *storagemocks.MockStorageMockRecorder, mt *trainerclientmocks.MockV1MockRecorder, stream *trainerv1mocks.MockTrainer_TrainClient) {
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockTrainerClient := trainerclientmocks.NewMockV1(ctl)
+			mockStream := trainerv1mocks.NewMockTrainer_TrainClient(ctl)
+			mockStorage := storagemocks.NewMockStorage(ctl)
+			tc.mock(mockStorage.EXPECT(), mockTrainerClient.EXPECT(), mockStream)
+
+			a := &announcer{
+				config: &config.Config{
+					Trainer: config.TrainerConfig{
+						UploadTimeout:         time.Minute,
+						UploadDownload:        tc.uploadDownload,
+						UploadNetworkTopology: tc.uploadNetworkTopology,
+					},
+				},
+				trainerClient: mockTrainerClient,
+				storage:       mockStorage,
+				done:          make(chan struct{}),
+				log:           zap.NewNop().Sugar(),
+			}
+			assert.NoError(t, a.train())
+		})
+	}
+}
+
+func TestAnnouncer_TrainSync(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{
+			name: "sync storage before uploading",
+		},
+		{
+			name: "sync storage failed",
+			err:  errors.New("foo"),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockTrainerClient := trainerclientmocks.NewMockV1(ctl)
+			mockStream := trainerv1mocks.NewMockTrainer_TrainClient(ctl)
+			mockStorage := storagemocks.NewMockStorage(ctl)
+
+			gomock.InOrder(
+				mockStorage.EXPECT().Sync().Return(tc.err).Times(1),
+				mockTrainerClient.EXPECT().Train(gomock.Any()).Return(mockStream, nil).Times(1),
+				mockStorage.EXPECT().OpenDownload().Return(io.NopCloser(strings.NewReader("foo")), nil).Times(1),
+			)
+			mockStream.EXPECT().Send(gomock.Any()).Return(nil).Times(1)
+			mockStream.EXPECT().CloseAndRecv().Return(nil, nil).Times(1)
+
+			a := &announcer{
+				config: &config.Config{
+					Trainer: config.TrainerConfig{
+						UploadTimeout:  time.Minute,
+						UploadDownload: true,
+					},
+				},
+				trainerClient: mockTrainerClient,
+				storage:       mockStorage,
+				done:          make(chan struct{}),
+				log:           zap.NewNop().Sugar(),
+			}
+			assert.NoError(t, a.train())
+		})
+	}
+}
+
+func TestAnnouncer_TrainFailedTrainer(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	mockTrainerClient := trainerclientmocks.NewMockV1(ctl)
+	mockDiscoveredTrainerClient := trainerclientmocks.NewMockV1(ctl)
+	mockStream := trainerv1mocks.NewMockTrainer_TrainClient(ctl)
+	mockStorage := storagemocks.NewMockStorage(ctl)
+
+	mockStorage.EXPECT().Sync().Return(nil).Times(2)
+	mockStorage.EXPECT().OpenDownload().DoAndReturn(func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader("foo")), nil
+	}).Times(2)
+	mockDiscoveredTrainerClient.EXPECT().Train(gomock.Any()).Return(mockStream, nil).Times(1)
+	mockStream.EXPECT().Send(gomock.Any()).Return(errors.New("foo")).Times(1)
+	mockTrainerClient.EXPECT().Train(gomock.Any()).Return(mockStream, nil).Times(1)
+	mockStream.EXPECT().Send(gomock.Any()).Return(nil).Times(1)
+	mockStream.EXPECT().CloseAndRecv().Return(nil, nil).Times(1)
+
+	a := &announcer{
+		config: &config.Config{
+			Trainer: config.TrainerConfig{
+				Addr:           "127.0.0.1:9091",
+				UploadTimeout:  time.Minute,
+				UploadDownload: true,
+			},
+		},
+		trainerClient:           mockTrainerClient,
+		discoveredTrainerClient: mockDiscoveredTrainerClient,
+		discoveredTrainerAddr:   "10.0.0.1:9090",
+		storage:                 mockStorage,
+		done:                    make(chan struct{}),
+		log:                     zap.NewNop().Sugar(),
+	}
+
+	assert := assert.New(t)
+	err := a.train()
+	assert.ErrorContains(err, "upload download to trainer 10.0.0.1:9090")
+	assert.NotContains(err.Error(), "127.0.0.1:9091")
+	assert.Equal(float64(1), testutil.ToFloat64(metrics.TrainFailureCount.WithLabelValues("10.0.0.1:9090")))
+
+	// Fall back to the configured trainer.
+	a.discoveredTrainerClient, a.discoveredTrainerAddr = nil, ""
+	assert.NoError(a.train())
+	assert.Equal(float64(0), testutil.ToFloat64(metrics.TrainFailureCount.WithLabelValues("127.0.0.1:9091")))
+}
+
+func TestAnnouncer_TrainMetrics(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	mockTrainerClient := trainerclientmocks.NewMockV1(ctl)
+	mockStream := trainerv1mocks.NewMockTrainer_TrainClient(ctl)
+	mockStorage := storagemocks.NewMockStorage(ctl)
+
+	gomock.InOrder(
+		mockStorage.EXPECT().Sync().Return(nil).Times(1),
+		mockTrainerClient.EXPECT().Train(gomock.Any()).Return(mockStream, nil).Times(1),
+		mockStorage.EXPECT().OpenDownload().Return(io.NopCloser(strings.NewReader("foo")), nil).Times(1),
+	)
+	mockStream.EXPECT().Send(gomock.Any()).Return(nil).Times(1)
+	mockStream.EXPECT().CloseAndRecv().Return(nil, nil).Times(1)
+
+	a := &announcer{
+		config: &config.Config{
+			Trainer: config.TrainerConfig{
+				Addr:           "127.0.0.1:9093",
+				UploadTimeout:  time.Minute,
+				UploadDownload: true,
+			},
+		},
+		trainerClient: mockTrainerClient,
+		storage:       mockStorage,
+		done:          make(chan struct{}),
+		log:           zap.NewNop().Sugar(),
+	}
+
+	assert := assert.New(t)
+	uploadBytes := testutil.ToFloat64(metrics.TrainUploadBytesCount.WithLabelValues(metrics.TrainDatasetDownload))
+	durations := testutil.CollectAndCount(metrics.TrainDuration)
+	uploadDurations := testutil.CollectAndCount(metrics.TrainUploadDuration)
+	metrics.TrainLastSuccessTimestampGauge.Set(0)
+	assert.NoError(a.train())
+
+	// The durations of the trainer are observed in new series.
+	assert.Equal(float64(3), testutil.ToFloat64(metrics.TrainUploadBytesCount.WithLabelValues(metrics.TrainDatasetDownload))-uploadBytes)
+	assert.Equal(durations+1, testutil.CollectAndCount(metrics.TrainDuration))
+	assert.Equal(uploadDurations+1, testutil.CollectAndCount(metrics.TrainUploadDuration))
+	assert.Greater(testutil.ToFloat64(metrics.TrainLastSuccessTimestampGauge), float64(0))
+}
+
+func TestAnnouncer_TrainStreamOpenTimeout(t *testing.T) {
+	tests := []struct {
+		name   string
+		delay  time.Duration
+		expect func(t *testing.T, err error)
+	}{
+		{
+			name:  "stream is opened slowly",
+			delay: 5 * time.Second,
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.ErrorIs(err, ErrTrainStreamOpenTimeout)
+				assert.ErrorContains(err, "open train stream to trainer 127.0.0.1:9092")
+				assert.Equal(float64(1), testutil.ToFloat64(metrics.TrainStreamOpenTimeoutCount.WithLabelValues("127.0.0.1:9092")))
+			},
+		},
+		{
+			name:  "stream is opened in time",
+			delay: 0,
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockTrainerClient := trainerclientmocks.NewMockV1(ctl)
+			mockStream := trainerv1mocks.NewMockTrainer_TrainClient(ctl)
+			mockStorage := storagemocks.NewMockStorage(ctl)
+
+			mockStorage.EXPECT().Sync().Return(nil).Times(1)
+			mockTrainerClient.EXPECT().Train(gomock.Any()).DoAndReturn(func(ctx context.Context, opts ...grpc.CallOption) (trainerv1.Trainer_TrainClient, error) {
+				select {
+				case <-time.After(tc.delay):
+					return mockStream, nil
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+			}).Times(1)
+			if tc.delay == 0 {
+				mockStorage.EXPECT().OpenDownload().Return(io.NopCloser(strings.NewReader("foo")), nil).Times(1)
+				mockStream.EXPECT().Send(gomock.Any()).Return(nil).Times(1)
+				mockStream.EXPECT().CloseAndRecv().Return(nil, nil).Times(1)
+			}
+
+			a := &announcer{
+				config: &config.Config{
+					Trainer: config.TrainerConfig{
+						Addr:              "127.0.0.1:9092",
+						UploadTimeout:     time.Minute,
+						StreamOpenTimeout: 100 * time.Millisecond,
+						UploadDownload:    true,
+					},
+				},
+				trainerClient: mockTrainerClient,
+				storage:       mockStorage,
+				done:          make(chan struct{}),
+				log:           zap.NewNop().Sugar(),
+			}
+
+			tc.expect(t, a.train())
+		})
+	}
+}
+
+func TestAnnouncer_TrainFinalizePolicy(t *testing.T) {
+	tests := []struct {
+		name           string
+		finalizePolicy string
+		expect         func(t *testing.T, a *announcer, err error)
+	}{
+		{
+			name:           "pessimistic policy keeps checkpoint",
+			finalizePolicy: config.TrainerFinalizePolicyPessimistic,
+			expect: func(t *testing.T, a *announcer, err error) {
+				assert := assert.New(t)
+				assert.ErrorIs(err, ErrTrainUnconfirmed)
+				assert.EqualValues(0, a.checkpoint.DownloadOffset)
+
+				checkpoint, err := loadCheckpoint(a.checkpointFilename)
+				assert.NoError(err)
+				assert.EqualValues(0, checkpoint.DownloadOffset)
+			},
+		},
+		{
+			name:           "optimistic policy advances checkpoint",
+			finalizePolicy: config.TrainerFinalizePolicyOptimistic,
+			expect: func(t *testing.T, a *announcer, err error) {
+				assert := assert.New(t)
+				assert.ErrorIs(err, ErrTrainUnconfirmed)
+				assert.EqualValues(3, a.checkpoint.DownloadOffset)
+
+				checkpoint, err := loadCheckpoint(a.checkpointFilename)
+				assert.NoError(err)
+				assert.EqualValues(3, checkpoint.DownloadOffset)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockTrainerClient := trainerclientmocks.NewMockV1(ctl)
+			mockStream := trainerv1mocks.NewMockTrainer_TrainClient(ctl)
+			mockStorage := storagemocks.NewMockStorage(ctl)
+
+			mockStorage.EXPECT().Sync().Return(nil).Times(1)
+			mockStorage.EXPECT().OpenDownload().Return(io.NopCloser(strings.NewReader("foo")), nil).Times(1)
+			mockTrainerClient.EXPECT().Train(gomock.Any()).Return(mockStream, nil).Times(1)
+			mockStream.EXPECT().Send(gomock.Any()).Return(nil).Times(1)
+			mockStream.EXPECT().CloseAndRecv().Return(nil, errors.New("foo")).Times(1)
+
+			a := &announcer{
+				config: &config.Config{
+					Trainer: config.TrainerConfig{
+						Addr:           "127.0.0.1:9093",
+						UploadTimeout:  time.Minute,
+						UploadDownload: true,
+						FinalizePolicy: tc.finalizePolicy,
+					},
+				},
+				trainerClient:      mockTrainerClient,
+				storage:            mockStorage,
+				done:               make(chan struct{}),
+				log:                zap.NewNop().Sugar(),
+				checkpointFilename: filepath.Join(t.TempDir(), CheckpointFilename),
+			}
+
+			tc.expect(t, a, a.train())
+		})
+	}
+}
+
+func TestAnnouncer_HostStats(t *testing.T) {
+	tests := []struct {
+		name      string
+		collector HostStatsCollector
+		expect    func(t *testing.T, md metadata.MD)
+	}{
+		{
+			name: "send host stats",
+			collector: func() (HostStats, error) {
+				return HostStats{CPUCount: 8, MemoryTotal: 1024, DiskFree: 512}, nil
+			},
+			expect: func(t *testing.T, md metadata.MD) {
+				assert := assert.New(t)
+				assert.Equal([]string{"8"}, md.Get(HostCPUCountMetadataKey))
+				assert.Equal([]string{"1024"}, md.Get(HostMemoryTotalMetadataKey))
+				assert.Equal([]string{"512"}, md.Get(HostDiskFreeMetadataKey))
+			},
+		},
+		{
+			name: "collect host stats failed",
+			collector: func() (HostStats, error) {
+				return HostStats{}, errors.New("foo")
+			},
+			expect: func(t *testing.T, md metadata.MD) {
+				assert := assert.New(t)
+				assert.Empty(md.Get(HostCPUCountMetadataKey))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockManagerClient := clientmocks.NewMockV2(ctl)
+			mockStorage := storagemocks.NewMockStorage(ctl)
+
+			var md metadata.MD
+			mockManagerClient.EXPECT().UpdateScheduler(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(ctx context.Context, req *managerv2.UpdateSchedulerRequest, opts ...grpc.CallOption) (*managerv2.Scheduler, error) {
+					md, _ = metadata.FromOutgoingContext(ctx)
+					return nil, nil
+				}).Times(1)
+
+			_, err := New(&config.Config{
+				Server: config.ServerConfig{
+					Host:          "localhost",
+					AdvertiseIP:   net.ParseIP("127.0.0.1"),
+					AdvertisePort: 8004,
+					Port:          8080,
+				},
+			}, mockManagerClient, mockStorage, WithHostStatsCollector(tc.collector), WithLogger(zap.NewNop().Sugar()))
+			assert.NoError(t, err)
+			tc.expect(t, md)
+		})
+	}
+}
+
+func TestAnnouncer_NewHostStatsCollector(t *testing.T) {
+	assert := assert.New(t)
+	stats, err := NewHostStatsCollector(t.TempDir())()
+	assert.NoError(err)
+	assert.Greater(stats.CPUCount, 0)
+	assert.Greater(stats.MemoryTotal, uint64(0))
+
+	_, err = NewHostStatsCollector(filepath.Join(t.TempDir(), "foo"))()
+	assert.Error(err)
+}
+
+func TestAnnouncer_AdvertiseIPs(t *testing.T) {
+	tests := []struct {
+		name                 string
+		advertiseIP          net.IP
+		secondaryAdvertiseIP net.IP
+		expect               func(t *testing.T, md metadata.MD)
+	}{
+		{
+			name:        "send advertise ip",
+			advertiseIP: net.ParseIP("127.0.0.1"),
+			expect: func(t *testing.T, md metadata.MD) {
+				assert := assert.New(t)
+				assert.Equal([]string{"127.0.0.1"}, md.Get(AdvertiseIPv4MetadataKey))
+				assert.Empty(md.Get(AdvertiseIPv6MetadataKey))
+			},
+		},
+		{
+			name:                 "send dual-stack advertise ips",
+			advertiseIP:          net.ParseIP("::1"),
+			secondaryAdvertiseIP: net.ParseIP("127.0.0.1"),
+			expect: func(t *testing.T, md metadata.MD) {
+				assert := assert.New(t)
+				assert.Equal([]string{"127.0.0.1"}, md.Get(AdvertiseIPv4MetadataKey))
+				assert.Equal([]string{"::1"}, md.Get(AdvertiseIPv6MetadataKey))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockManagerClient := clientmocks.NewMockV2(ctl)
+			mockStorage := storagemocks.NewMockStorage(ctl)
+
+			var md metadata.MD
+			mockManagerClient.EXPECT().UpdateScheduler(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(ctx context.Context, req *managerv2.UpdateSchedulerRequest, opts ...grpc.CallOption) (*managerv2.Scheduler, error) {
+					md, _ = metadata.FromOutgoingContext(ctx)
+					return nil, nil
+				}).Times(1)
+
+			_, err := New(&config.Config{
+				Server: config.ServerConfig{
+					Host:                 "localhost",
+					AdvertiseIP:          tc.advertiseIP,
+					SecondaryAdvertiseIP: tc.secondaryAdvertiseIP,
+					AdvertisePort:        8004,
+					Port:                 8080,
+				},
+			}, mockManagerClient, mockStorage, WithLogger(zap.NewNop().Sugar()))
+			assert.NoError(t, err)
+			tc.expect(t, md)
+		})
+	}
+}
+
+func TestAnnouncer_UploadTransforms(t *testing.T) {
+	upper := UploadTransform{
+		Name: "upper",
+		Transform: func(r io.Reader) io.Reader {
+			data, err := io.ReadAll(r)
+			if err != nil {
+				return iotest.ErrReader(err)
+			}
+
+			return bytes.NewReader(bytes.ToUpper(data))
+		},
+	}
+
+	prefix := UploadTransform{
+		Name: "prefix",
+		Transform: func(r io.Reader) io.Reader {
+			return io.MultiReader(strings.NewReader("x"), r)
+		},
+	}
+
+	failed := UploadTransform{
+		Name: "failed",
+		Transform: func(r io.Reader) io.Reader {
+			return iotest.ErrReader(errors.New("foo"))
+		},
+	}
+
+	tests := []struct {
+		name       string
+		transforms []UploadTransform
+		dataset    io.Reader
+		expect     func(t *testing.T, sent string, offset int64, err error)
+	}{
+		{
+			name:       "apply transforms in order",
+			transforms: []UploadTransform{upper, prefix},
+			dataset:    strings.NewReader("foobar"),
+			expect: func(t *testing.T, sent string, offset int64, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("xFOOBAR", sent)
+				assert.EqualValues(6, offset)
+			},
+		},
+		{
+			name:       "apply transforms in reverse order",
+			transforms: []UploadTransform{prefix, upper},
+			dataset:    strings.NewReader("foobar"),
+			expect: func(t *testing.T, sent string, offset int64, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("XFOOBAR", sent)
+				assert.EqualValues(6, offset)
+			},
+		},
+		{
+			name:       "transform failed",
+			transforms: []UploadTransform{failed, prefix},
+			dataset:    strings.NewReader("foobar"),
+			expect: func(t *testing.T, sent string, offset int64, err error) {
+				assert := assert.New(t)
+				var transformErr *UploadTransformError
+				assert.True(errors.As(err, &transformErr))
+				assert.Equal("failed", transformErr.Name)
+				assert.EqualError(err, "upload transform failed failed: foo")
+			},
+		},
+		{
+			name:       "read dataset failed",
+			transforms: []UploadTransform{upper, prefix},
+			dataset:    iotest.ErrReader(errors.New("bar")),
+			expect: func(t *testing.T, sent string, offset int64, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "bar")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockStream := trainerv1mocks.NewMockTrainer_TrainClient(ctl)
+			mockStorage := storagemocks.NewMockStorage(ctl)
+
+			var sent string
+			mockStorage.EXPECT().OpenDownload().Return(io.NopCloser(tc.dataset), nil).Times(1)
+			mockStream.EXPECT().Send(gomock.Any()).DoAndReturn(func(req *trainerv1.TrainRequest) error {
+				sent += string(req.GetTrainMlpRequest().Dataset)
+				return nil
+			}).AnyTimes()
+
+			a := &announcer{
+				config:           &config.Config{},
+				storage:          mockStorage,
+				log:              zap.NewNop().Sugar(),
+				uploadTransforms: tc.transforms,
+			}
+
+			offset, _, err := a.uploadDownloadToTrainer(mockStream, 0, 0)
+			tc.expect(t, sent, offset, err)
+		})
+	}
+}
+
+func TestAnnouncer_EffectiveKeepAliveInterval(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	mockManagerClient := clientmocks.NewMockV2(ctl)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	mockManagerClient.EXPECT().KeepAlive(gomock.Eq(5*time.Second), gomock.Any(), gomock.Any(), gomock.Any()).Do(
+		func(interval time.Duration, req *managerv2.KeepAliveRequest, done <-chan struct{}, opts ...grpc.CallOption) {
+			wg.Done()
+		}).Times(1)
+
+	a := &announcer{
+		config: &config.Config{
+			Server: config.ServerConfig{
+				AdvertiseIP: net.ParseIP("127.0.0.1"),
+			},
+			Manager: config.ManagerConfig{
+				KeepAlive: config.KeepAliveConfig{
+					Interval: 5 * time.Second,
+				},
+			},
+		},
+		managerClient: mockManagerClient,
+		done:          make(chan struct{}),
+		log:           zap.NewNop().Sugar(),
+	}
+
+	assert := assert.New(t)
+	assert.Equal(time.Duration(0), a.EffectiveKeepAliveInterval())
+	assert.NoError(a.announceToManager())
+	wg.Wait()
+	assert.Equal(5*time.Second, a.EffectiveKeepAliveInterval())
+}
+
+func TestAnnouncer_NilStorage(t *testing.T) {
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			Host:          "localhost",
+			AdvertiseIP:   net.ParseIP("127.0.0.1"),
+			AdvertisePort: 8004,
+			Port:          8080,
+		},
+	}
+
+	tests := []struct {
+		name    string
+		config  func() *config.Config
+		options func(ctl *gomock.Controller) []Option
+		mock    func(m *clientmocks.MockV2MockRecorder)
+		expect  func(t *testing.T, a Announcer, err error)
+	}{
+		{
+			name:    "trainer is disabled",
+			config:  func() *config.Config { return cfg },
+			options: func(ctl *gomock.Controller) []Option { return nil },
+			mock: func(m *clientmocks.MockV2MockRecorder) {
+				m.UpdateScheduler(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+				m.KeepAlive(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+			},
+			expect: func(t *testing.T, a Announcer, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.NoError(a.Serve())
+				assert.NoError(a.Stop())
+			},
+		},
+		{
+			name:   "trainer client is configured",
+			config: func() *config.Config { return cfg },
+			options: func(ctl *gomock.Controller) []Option {
+				return []Option{WithTrainerClient(trainerclientmocks.NewMockV1(ctl))}
+			},
+			mock: func(m *clientmocks.MockV2MockRecorder) {},
+			expect: func(t *testing.T, a Announcer, err error) {
+				assert := assert.New(t)
+				assert.ErrorIs(err, ErrStorageRequired)
+			},
+		},
+		{
+			name: "trainer is enabled",
+			config: func() *config.Config {
+				cfg := *cfg
+				cfg.Trainer = config.TrainerConfig{
+					Enable: true,
+					Addr:   "127.0.0.1:9090",
+				}
+				return &cfg
+			},
+			options: func(ctl *gomock.Controller) []Option { return nil },
+			mock:    func(m *clientmocks.MockV2MockRecorder) {},
+			expect: func(t *testing.T, a Announcer, err error) {
+				assert := assert.New(t)
+				assert.ErrorIs(err, ErrStorageRequired)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockManagerClient := clientmocks.NewMockV2(ctl)
+			tc.mock(mockManagerClient.EXPECT())
+
+			a, err := New(tc.config(), mockManagerClient, nil, tc.options(ctl)...)
+			tc.expect(t, a, err)
+		})
+	}
+}
+
+func TestAnnouncer_UpdateSchedulerRetry(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxAttempts int
+		mock        func(m *clientmocks.MockV2MockRecorder)
+		expect      func(t *testing.T, err error)
+	}{
+		{
+			name:        "first attempt failed then succeeded",
+			maxAttempts: 3,
+			mock: func(m *clientmocks.MockV2MockRecorder) {
+				gomock.InOrder(
+					m.UpdateScheduler(gomock.Any(), gomock.Any()).Return(nil, errors.New("foo")).Times(1),
+					m.UpdateScheduler(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1),
+				)
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+			},
+		},
+		{
+			name:        "attempts exhausted",
+			maxAttempts: 2,
+			mock: func(m *clientmocks.MockV2MockRecorder) {
+				m.UpdateScheduler(gomock.Any(), gomock.Any()).Return(nil, errors.New("foo")).Times(2)
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "foo")
+			},
+		},
+		{
+			name:        "invalid max attempts",
+			maxAttempts: 0,
+			mock: func(m *clientmocks.MockV2MockRecorder) {
+				m.UpdateScheduler(gomock.Any(), gomock.Any()).Return(nil, errors.New("foo")).Times(1)
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "foo")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockManagerClient := clientmocks.NewMockV2(ctl)
+			mockStorage := storagemocks.NewMockStorage(ctl)
+			tc.mock(mockManagerClient.EXPECT())
+
+			_, err := New(&config.Config{
+				Server: config.ServerConfig{
+					Host:          "localhost",
+					AdvertiseIP:   net.ParseIP("127.0.0.1"),
+					AdvertisePort: 8004,
+					Port:          8080,
+				},
+			}, mockManagerClient, mockStorage, WithUpdateSchedulerRetry(tc.maxAttempts, time.Millisecond, 10*time.Millisecond), WithLogger(zap.NewNop().Sugar()))
+			tc.expect(t, err)
+		})
+	}
+}
+
+func TestAnnouncer_DegradedStart(t *testing.T) {
+	tests := []struct {
+		name     string
+		degraded bool
+		mock     func(m *clientmocks.MockV2MockRecorder, keepAlive chan struct{})
+		expect   func(t *testing.T, a Announcer, err error, keepAlive chan struct{})
+	}{
+		{
+			name:     "register lazily after degraded start",
+			degraded: true,
+			mock: func(m *clientmocks.MockV2MockRecorder, keepAlive chan struct{}) {
+				gomock.InOrder(
+					m.UpdateScheduler(gomock.Any(), gomock.Any()).Return(nil, errors.New("foo")).Times(3),
+					m.UpdateScheduler(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1),
+				)
+				m.KeepAlive(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
+					func(interval time.Duration, req *managerv2.KeepAliveRequest, done <-chan struct{}, opts ...grpc.CallOption) {
+						close(keepAlive)
+						<-done
+					}).Times(1)
+			},
+			expect: func(t *testing.T, a Announcer, err error, keepAlive chan struct{}) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				_, err = a.AdvertiseAddr()
+				assert.ErrorIs(err, ErrNotRegistered)
+
+				assert.NoError(a.Serve())
+				select {
+				case <-keepAlive:
+				case <-time.After(5 * time.Second):
+					t.Fatal("keepalive is not started after registration")
+				}
+
+				ip, err := a.AdvertiseAddr()
+				assert.NoError(err)
+				assert.Equal("127.0.0.1", ip.String())
+				assert.NoError(a.Stop())
+			},
+		},
+		{
+			name:     "stop before lazy registration succeeds",
+			degraded: true,
+			mock: func(m *clientmocks.MockV2MockRecorder, keepAlive chan struct{}) {
+				m.UpdateScheduler(gomock.Any(), gomock.Any()).Return(nil, errors.New("foo")).MinTimes(2)
+				m.KeepAlive(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			expect: func(t *testing.T, a Announcer, err error, keepAlive chan struct{}) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.NoError(a.Serve())
+
+				// Wait for a failed round of lazy registration.
+				time.Sleep(20 * time.Millisecond)
+				assert.NoError(a.Stop())
+				_, err = a.AdvertiseAddr()
+				assert.ErrorIs(err, ErrNotRegistered)
+			},
+		},
+		{
+			name:     "registration failure fails startup without degraded start",
+			degraded: false,
+			mock: func(m *clientmocks.MockV2MockRecorder, keepAlive chan struct{}) {
+				m.UpdateScheduler(gomock.Any(), gomock.Any()).Return(nil, errors.New("foo")).Times(2)
+			},
+			expect: func(t *testing.T, a Announcer, err error, keepAlive chan struct{}) {
+				assert := assert.New(t)
+				assert.EqualError(err, "foo")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockManagerClient := clientmocks.NewMockV2(ctl)
+			mockStorage := storagemocks.NewMockStorage(ctl)
+			keepAlive := make(chan struct{})
+			tc.mock(mockManagerClient.EXPECT(), keepAlive)
+
+			a, err := New(&config.Config{
+				Server: config.ServerConfig{
+					Host:          "localhost",
+					AdvertiseIP:   net.ParseIP("127.0.0.1"),
+					AdvertisePort: 8004,
+					Port:          8080,
+				},
+				Manager: config.ManagerConfig{
+					KeepAlive: config.KeepAliveConfig{
+						Interval: time.Second,
+					},
+				},
+			}, mockManagerClient, mockStorage,
+				WithUpdateSchedulerRetry(2, time.Millisecond, 10*time.Millisecond),
+				WithUpdateSchedulerRetryJitter(0.5),
+				WithDegradedStart(tc.degraded),
+				WithLogger(zap.NewNop().Sugar()))
+			tc.expect(t, a, err, keepAlive)
+		})
+	}
+}
+
+func TestAnnouncer_ReportTrainResult(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	mockManagerClient := clientmocks.NewMockV2(ctl)
+	mockTrainerClient := trainerclientmocks.NewMockV1(ctl)
+	mockStream := trainerv1mocks.NewMockTrainer_TrainClient(ctl)
+	mockStorage := storagemocks.NewMockStorage(ctl)
+
+	mockStorage.EXPECT().Sync().Return(nil).Times(1)
+	mockStorage.EXPECT().OpenDownload().Return(io.NopCloser(strings.NewReader("foo")), nil).Times(1)
+	mockStorage.EXPECT().OpenNetworkTopology().Return(io.NopCloser(strings.NewReader("barbaz")), nil).Times(1)
+	mockTrainerClient.EXPECT().Train(gomock.Any()).Return(mockStream, nil).Times(1)
+	mockStream.EXPECT().Send(gomock.Any()).Return(nil).Times(2)
+	mockStream.EXPECT().CloseAndRecv().Return(nil, nil).Times(1)
+
+	var md metadata.MD
+	mockManagerClient.EXPECT().UpdateScheduler(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(ctx context.Context, req *managerv2.UpdateSchedulerRequest, opts ...grpc.CallOption) (*managerv2.Scheduler, error) {
+			md, _ = metadata.FromOutgoingContext(ctx)
+			return nil, nil
+		}).Times(1)
+
+	a := &announcer{
+		config: &config.Config{
+			Server: config.ServerConfig{
+				AdvertiseIP: net.ParseIP("127.0.0.1"),
+			},
+			Trainer: config.TrainerConfig{
+				Addr:                  "127.0.0.1:9094",
+				UploadTimeout:         time.Minute,
+				UploadDownload:        true,
+				UploadNetworkTopology: true,
+			},
+		},
+		managerClient:        mockManagerClient,
+		trainerClient:        mockTrainerClient,
+		storage:              mockStorage,
+		done:                 make(chan struct{}),
+		log:                  zap.NewNop().Sugar(),
+		updateSchedulerRetry: retryPolicy{maxAttempts: 1},
+	}
+
+	assert := assert.New(t)
+	assert.NoError(a.train())
+	assert.Equal(TrainResult{
+		DownloadBytes:            3,
+		NetworkTopologyBytes:     6,
+		DownloadSucceeded:        true,
+		NetworkTopologySucceeded: true,
+		DownloadRecords:          RecordCounts{Read: 1, Sent: 1},
+		NetworkTopologyRecords:   RecordCounts{Read: 1, Sent: 1},
+	}, a.trainResult)
+	assert.NoError(a.reportTrainResult(context.Background(), a.trainResult))
+	assert.Equal([]string{"3"}, md.Get(TrainDownloadBytesMetadataKey))
+	assert.Equal([]string{"6"}, md.Get(TrainNetworkTopologyBytesMetadataKey))
+	assert.Equal([]string{"true"}, md.Get(TrainDownloadSucceededMetadataKey))
+	assert.Equal([]string{"true"}, md.Get(TrainNetworkTopologySucceededMetadataKey))
+}
+
+func TestAnnouncer_QueueTrainResult(t *testing.T) {
+	a := &announcer{trainResults: make(chan TrainResult, 1)}
+	a.queueTrainResult(TrainResult{DownloadBytes: 1})
+	a.queueTrainResult(TrainResult{DownloadBytes: 2})
+
+	assert := assert.New(t)
+	assert.Equal(TrainResult{DownloadBytes: 2}, <-a.trainResults)
+	assert.Len(a.trainResults, 0)
+}
+
+func TestAnnouncer_UploadOversizedRecord(t *testing.T) {
+	tests := []struct {
+		name           string
+		maxMessageSize int
+		expect         func(t *testing.T, sent []*trainerv1.TrainRequest, err error)
+	}{
+		{
+			name:           "split oversized record",
+			maxMessageSize: 128,
+			expect: func(t *testing.T, sent []*trainerv1.TrainRequest, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Greater(len(sent), 1)
+
+				var dataset string
+				for _, req := range sent {
+					assert.LessOrEqual(proto.Size(req), 128)
+					dataset += string(req.GetTrainMlpRequest().Dataset)
+				}
+				assert.Equal("foo\n"+strings.Repeat("x", 4096)+"\nbar\n", dataset)
+			},
+		},
+		{
+			name:           "max message size is too small",
+			maxMessageSize: 16,
+			expect: func(t *testing.T, sent []*trainerv1.TrainRequest, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "max message size 16 is too small for message metadata")
+				assert.Empty(sent)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockStream := trainerv1mocks.NewMockTrainer_TrainClient(ctl)
+			mockStorage := storagemocks.NewMockStorage(ctl)
+
+			var sent []*trainerv1.TrainRequest
+			mockStorage.EXPECT().OpenDownload().Return(io.NopCloser(strings.NewReader("foo\n"+strings.Repeat("x", 4096)+"\nbar\n")), nil).Times(1)
+			mockStream.EXPECT().Send(gomock.Any()).DoAndReturn(func(req *trainerv1.TrainRequest) error {
+				// The dataset buffer is reused by the next message.
+				sent = append(sent, proto.Clone(req).(*trainerv1.TrainRequest))
+				return nil
+			}).AnyTimes()
+
+			a := &announcer{
+				config: &config.Config{
+					Server: config.ServerConfig{
+						Host:        "localhost",
+						AdvertiseIP: net.ParseIP("127.0.0.1"),
+					},
+					Trainer: config.TrainerConfig{
+						MaxMessageSize: tc.maxMessageSize,
+					},
+				},
+				storage: mockStorage,
+				log:     zap.NewNop().Sugar(),
+			}
+
+			_, _, err := a.uploadDownloadToTrainer(mockStream, 0, 0)
+			tc.expect(t, sent, err)
+		})
+	}
+}
+
+func TestAnnouncer_StopContext(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   func(m *clientmocks.MockV2MockRecorder, release chan struct{})
+		expect func(t *testing.T, a *announcer)
+	}{
+		{
+			name: "keepalive exits",
+			mock: func(m *clientmocks.MockV2MockRecorder, release chan struct{}) {
+				m.KeepAlive(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
+					func(interval time.Duration, req *managerv2.KeepAliveRequest, done <-chan struct{}, opts ...grpc.CallOption) {
+						<-done
+					}).Times(1)
+			},
+			expect: func(t *testing.T, a *announcer) {
+				assert := assert.New(t)
+				ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				assert.NoError(a.StopContext(ctx))
+
+				// Stop is idempotent.
+				assert.NoError(a.Stop())
+			},
+		},
+		{
+			name: "keepalive does not exit in time",
+			mock: func(m *clientmocks.MockV2MockRecorder, release chan struct{}) {
+				m.KeepAlive(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
+					func(interval time.Duration, req *managerv2.KeepAliveRequest, done <-chan struct{}, opts ...grpc.CallOption) {
+						<-release
+					}).Times(1)
+			},
+			expect: func(t *testing.T, a *announcer) {
+				assert := assert.New(t)
+				ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+				defer cancel()
+				assert.ErrorIs(a.StopContext(ctx), context.DeadlineExceeded)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockManagerClient := clientmocks.NewMockV2(ctl)
+
+			release := make(chan struct{})
+			defer close(release)
+			tc.mock(mockManagerClient.EXPECT(), release)
+
+			a := &announcer{
+				config: &config.Config{
+					Server: config.ServerConfig{
+						AdvertiseIP: net.ParseIP("127.0.0.1"),
+					},
+				},
+				managerClient: mockManagerClient,
+				done:          make(chan struct{}),
+				log:           zap.NewNop().Sugar(),
+			}
+
+			assert.NoError(t, a.Serve())
+			tc.expect(t, a)
+		})
+	}
+}
+
+func TestAnnouncer_Subscribe(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	mockManagerClient := clientmocks.NewMockV2(ctl)
+	mockTrainerClient := trainerclientmocks.NewMockV1(ctl)
+	mockStream := trainerv1mocks.NewMockTrainer_TrainClient(ctl)
+	mockStorage := storagemocks.NewMockStorage(ctl)
+
+	mockStorage.EXPECT().Sync().Return(nil).Times(2)
+	mockStorage.EXPECT().OpenDownload().DoAndReturn(func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader("foo")), nil
+	}).Times(2)
+	mockTrainerClient.EXPECT().Train(gomock.Any()).Return(mockStream, nil).Times(2)
+	// The second cycle resumes from the checkpoint and sends nothing.
+	mockStream.EXPECT().Send(gomock.Any()).Return(nil).Times(1)
+	gomock.InOrder(
+		mockStream.EXPECT().CloseAndRecv().Return(nil, nil).Times(1),
+		mockStream.EXPECT().CloseAndRecv().Return(nil, errors.New("foo")).Times(1),
+	)
+	mockManagerClient.EXPECT().UpdateScheduler(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+
+	a := &announcer{
+		config: &config.Config{
+			Server: config.ServerConfig{
+				AdvertiseIP: net.ParseIP("127.0.0.1"),
+			},
+			Trainer: config.TrainerConfig{
+				Addr:           "127.0.0.1:9095",
+				UploadTimeout:  time.Minute,
+				UploadDownload: true,
+			},
+		},
+		managerClient:        mockManagerClient,
+		trainerClient:        mockTrainerClient,
+		storage:              mockStorage,
+		done:                 make(chan struct{}),
+		log:                  zap.NewNop().Sugar(),
+		updateSchedulerRetry: retryPolicy{maxAttempts: 1},
+	}
+
+	assert := assert.New(t)
+	subscribers := []<-chan Event{a.Subscribe(), a.Subscribe()}
+	assert.NoError(a.train())
+	assert.NoError(a.reportTrainResult(context.Background(), a.trainResult))
+	assert.Error(a.train())
+	assert.NoError(a.Stop())
+
+	for _, events := range subscribers {
+		var types []EventType
+		for event := range events {
+			types = append(types, event.Type)
+			if event.Type == EventCycleFailed {
+				assert.ErrorContains(event.Err, "close train stream of trainer 127.0.0.1:9095")
+			}
+		}
+
+		assert.Equal([]EventType{EventCycleStarted, EventCycleSucceeded, EventCycleStarted, EventCycleFailed}, types)
+	}
+
+	// Subscribe after stopping returns a closed channel.
+	_, ok := <-a.Subscribe()
+	assert.False(ok)
+}
+
+func TestAnnouncer_SubscribeSlowConsumer(t *testing.T) {
+	a := &announcer{done: make(chan struct{})}
+	events := a.Subscribe()
+	for i := 0; i < EventBufferSize+10; i++ {
+		a.events.publish(Event{Type: EventCycleStarted})
+	}
+	assert.NoError(t, a.Stop())
+
+	count := 0
+	for range events {
+		count++
+	}
+	assert.Equal(t, EventBufferSize, count)
+}
+
+func TestAnnouncer_MinUploadBytes(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	mockTrainerClient := trainerclientmocks.NewMockV1(ctl)
+	mockStream := trainerv1mocks.NewMockTrainer_TrainClient(ctl)
+	s := storage.NewMemory()
+
+	var sent string
+	mockTrainerClient.EXPECT().Train(gomock.Any()).Return(mockStream, nil).Times(1)
+	mockStream.EXPECT().Send(gomock.Any()).DoAndReturn(func(req *trainerv1.TrainRequest) error {
+		sent += string(req.GetTrainMlpRequest().Dataset)
+		return nil
+	}).AnyTimes()
+	mockStream.EXPECT().CloseAndRecv().Return(nil, nil).Times(1)
+
+	a := &announcer{
+		config: &config.Config{
+			Server: config.ServerConfig{
+				AdvertiseIP: net.ParseIP("127.0.0.1"),
+			},
+			Trainer: config.TrainerConfig{
+				Addr:           "127.0.0.1:9096",
+				UploadTimeout:  time.Minute,
+				UploadDownload: true,
+				MinUploadBytes: 8,
+			},
+		},
+		trainerClient: mockTrainerClient,
+		storage:       s,
+		done:          make(chan struct{}),
+		log:           zap.NewNop().Sugar(),
+	}
+
+	assert := assert.New(t)
+
+	// Small dataset defers training.
+	s.SetDownload([]byte("foo\n"))
+	assert.NoError(a.train())
+	assert.Empty(sent)
+
+	// Large dataset triggers training.
+	s.SetDownload([]byte("foo\nbar\nbaz\n"))
+	assert.NoError(a.train())
+	assert.Equal("foo\nbar\nbaz\n", sent)
+
+	// Training is deferred again until enough dataset is written.
+	assert.NoError(a.train())
+
+	// Small dataset written after storage is cleared defers training.
+	s.SetDownload([]byte("foo\n"))
+	assert.NoError(a.train())
+	assert.Equal(int64(0), a.uploadedSize)
+}
+
+func TestAnnouncer_TrainerPreflight(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []Option
+		mock    func(m *trainerclientmocks.MockV1MockRecorder, stream trainerv1.Trainer_TrainClient)
+		expect  func(t *testing.T, a Announcer, err error)
+	}{
+		{
+			name:    "trainer is reachable",
+			options: []Option{WithTrainerPreflight(true), WithTrainerPreflightStrict(true)},
+			mock: func(m *trainerclientmocks.MockV1MockRecorder, stream trainerv1.Trainer_TrainClient) {
+				m.Train(gomock.Any()).Return(stream, nil).Times(1)
+			},
+			expect: func(t *testing.T, a Announcer, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.NotNil(a)
+			},
+		},
+		{
+			name:    "trainer is unreachable in strict mode",
+			options: []Option{WithTrainerPreflight(true), WithTrainerPreflightStrict(true)},
+			mock: func(m *trainerclientmocks.MockV1MockRecorder, stream trainerv1.Trainer_TrainClient) {
+				m.Train(gomock.Any()).Return(nil, errors.New("foo")).Times(1)
+			},
+			expect: func(t *testing.T, a Announcer, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "preflight trainer 127.0.0.1:9090: foo")
+				assert.Nil(a)
+			},
+		},
+		{
+			name:    "trainer is unreachable in non-strict mode",
+			options: []Option{WithTrainerPreflight(true)},
+			mock: func(m *trainerclientmocks.MockV1MockRecorder, stream trainerv1.Trainer_TrainClient) {
+				m.Train(gomock.Any()).Return(nil, errors.New("foo")).Times(1)
+			},
+			expect: func(t *testing.T, a Announcer, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.NotNil(a)
+			},
+		},
+		{
+			name: "trainer preflight timeout",
+			options: []Option{WithTrainerPreflight(true), WithTrainerPreflightStrict(true),
+				WithTrainerPreflightTimeout(10 * time.Millisecond)},
+			mock: func(m *trainerclientmocks.MockV1MockRecorder, stream trainerv1.Trainer_TrainClient) {
+				m.Train(gomock.Any()).DoAndReturn(func(ctx context.Context, opts ...grpc.CallOption) (trainerv1.Trainer_TrainClient, error) {
+					<-ctx.Done()
+					return nil, ctx.Err()
+				}).Times(1)
+			},
+			expect: func(t *testing.T, a Announcer, err error) {
+				assert := assert.New(t)
+				assert.ErrorIs(err, context.DeadlineExceeded)
+			},
+		},
+		{
+			name:    "trainer preflight is disabled",
+			options: []Option{WithTrainerPreflightStrict(true)},
+			mock:    func(m *trainerclientmocks.MockV1MockRecorder, stream trainerv1.Trainer_TrainClient) {},
+			expect: func(t *testing.T, a Announcer, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockTrainerClient := trainerclientmocks.NewMockV1(ctl)
+			mockStream := trainerv1mocks.NewMockTrainer_TrainClient(ctl)
+			mockStorage := storagemocks.NewMockStorage(ctl)
+			tc.mock(mockTrainerClient.EXPECT(), mockStream)
+
+			cfg := &config.Config{
+				Trainer: config.TrainerConfig{
+					Enable:        true,
+					Addr:          "127.0.0.1:9090",
+					Interval:      time.Minute,
+					UploadTimeout: time.Minute,
+				},
+			}
+
+			options := append([]Option{WithStandalone(true), WithTrainerClient(mockTrainerClient),
+				WithLogger(zap.NewNop().Sugar())}, tc.options...)
+			a, err := New(cfg, nil, mockStorage, options...)
+			tc.expect(t, a, err)
+		})
+	}
+}
+
+func TestAnnouncer_ServeStopInterleaved(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	mockManagerClient := clientmocks.NewMockV2(ctl)
+	mockTrainerClient := trainerclientmocks.NewMockV1(ctl)
+	mockStorage := storagemocks.NewMockStorage(ctl)
+	mockManagerClient.EXPECT().KeepAlive(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
+		func(interval time.Duration, req *managerv2.KeepAliveRequest, done <-chan struct{}, opts ...grpc.CallOption) {
+			<-done
+		}).AnyTimes()
+
+	for i := 0; i < 100; i++ {
+		a := &announcer{
+			config: &config.Config{
+				Server: config.ServerConfig{
+					AdvertiseIP: net.ParseIP("127.0.0.1"),
+				},
+				Trainer: config.TrainerConfig{
+					Interval: time.Hour,
+				},
+			},
+			managerClient: mockManagerClient,
+			trainerClient: mockTrainerClient,
+			storage:       mockStorage,
+			done:          make(chan struct{}),
+			log:           zap.NewNop().Sugar(),
+		}
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, a.Serve())
+		}()
+
+		for j := 0; j < 3; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, a.Stop())
+			}()
+		}
+
+		exited := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(exited)
+		}()
+
+		select {
+		case <-exited:
+		case <-time.After(5 * time.Second):
+			t.Fatal("serve or stop does not exit")
+		}
+
+		// Serve after Stop starts no goroutine.
+		assert.NoError(t, a.Serve())
+		assert.NoError(t, a.Stop())
+	}
+}
+
+func TestAnnouncer_FQDNHostname(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	mockTrainerClient := trainerclientmocks.NewMockV1(ctl)
+	mockStream := trainerv1mocks.NewMockTrainer_TrainClient(ctl)
+	s := storage.NewMemory()
+	s.SetDownload([]byte("foo\n"))
+
+	var hostnames []string
+	mockTrainerClient.EXPECT().Train(gomock.Any()).Return(mockStream, nil).Times(1)
+	mockStream.EXPECT().Send(gomock.Any()).DoAndReturn(func(req *trainerv1.TrainRequest) error {
+		hostnames = append(hostnames, req.Hostname)
+		return nil
+	}).Times(1)
+	mockStream.EXPECT().CloseAndRecv().Return(nil, nil).Times(1)
+
+	cfg := &config.Config{
+		Server: config.ServerConfig{
+			AdvertiseIP: net.ParseIP("127.0.0.1"),
+		},
+		Trainer: config.TrainerConfig{
+			Enable:         true,
+			Addr:           "127.0.0.1:9097",
+			Interval:       time.Minute,
+			UploadTimeout:  time.Minute,
+			UploadDownload: true,
+		},
+	}
+
+	a, err := New(cfg, nil, s, WithStandalone(true), WithTrainerClient(mockTrainerClient), WithLogger(zap.NewNop().Sugar()))
+	assert := assert.New(t)
+	assert.NoError(err)
+	assert.NoError(a.(*announcer).train())
+	assert.Equal([]string{fqdn.FQDNHostname}, hostnames)
+}
+
+func TestAnnouncer_CycleBudget(t *testing.T) {
+	tests := []struct {
+		name    string
+		trainer config.TrainerConfig
+		data    []byte
+		mock    func(m *trainerv1mocks.MockTrainer_TrainClientMockRecorder, sent *bytes.Buffer)
+		expect  func(t *testing.T, a *announcer, err error, sent *bytes.Buffer)
+	}{
+		{
+			name: "byte budget is exceeded",
+			trainer: config.TrainerConfig{
+				Addr:          "127.0.0.1:9098",
+				CycleMaxBytes: 8,
+			},
+			data: []byte("foo\nbar\nbaz\n"),
+			mock: func(m *trainerv1mocks.MockTrainer_TrainClientMockRecorder, sent *bytes.Buffer) {
+				m.Send(gomock.Any()).DoAndReturn(func(req *trainerv1.TrainRequest) error {
+					sent.Write(req.GetTrainMlpRequest().Dataset)
+					return nil
+				}).AnyTimes()
+				m.CloseAndRecv().Return(nil, nil).Times(2)
+			},
+			expect: func(t *testing.T, a *announcer, err error, sent *bytes.Buffer) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("foo\nbar\n", sent.String())
+				assert.True(a.trainResult.BudgetLimited)
+				assert.Equal(int64(8), a.checkpoint.DownloadOffset)
+
+				// The rest of dataset is uploaded in the next training.
+				sent.Reset()
+				assert.NoError(a.train())
+				assert.Equal("baz\n", sent.String())
+				assert.False(a.trainResult.BudgetLimited)
+				assert.Equal(int64(12), a.checkpoint.DownloadOffset)
+			},
+		},
+		{
+			name: "byte budget is exceeded with abort policy",
+			trainer: config.TrainerConfig{
+				Addr:              "127.0.0.1:9099",
+				CycleMaxBytes:     8,
+				CycleBudgetPolicy: config.TrainerCycleBudgetPolicyAbort,
+			},
+			data: []byte("foo\nbar\nbaz\n"),
+			mock: func(m *trainerv1mocks.MockTrainer_TrainClientMockRecorder, sent *bytes.Buffer) {
+				m.Send(gomock.Any()).DoAndReturn(func(req *trainerv1.TrainRequest) error {
+					sent.Write(req.GetTrainMlpRequest().Dataset)
+					return nil
+				}).AnyTimes()
+			},
+			expect: func(t *testing.T, a *announcer, err error, sent *bytes.Buffer) {
+				assert := assert.New(t)
+				assert.ErrorIs(err, ErrCycleBudgetExceeded)
+				assert.Equal("foo\nbar\n", sent.String())
+				assert.True(a.trainResult.BudgetLimited)
+				assert.Equal(int64(0), a.checkpoint.DownloadOffset)
+			},
+		},
+		{
+			name: "duration budget is exceeded",
+			trainer: config.TrainerConfig{
+				Addr:         "127.0.0.1:9100",
+				CycleTimeout: 50 * time.Millisecond,
+			},
+			data: bytes.Repeat([]byte("foo\n"), UploadBufferSize/2),
+			mock: func(m *trainerv1mocks.MockTrainer_TrainClientMockRecorder, sent *bytes.Buffer) {
+				m.Send(gomock.Any()).DoAndReturn(func(req *trainerv1.TrainRequest) error {
+					sent.Write(req.GetTrainMlpRequest().Dataset)
+					time.Sleep(100 * time.Millisecond)
+					return nil
+				}).Times(1)
+				m.CloseAndRecv().Return(nil, nil).Times(1)
+			},
+			expect: func(t *testing.T, a *announcer, err error, sent *bytes.Buffer) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(UploadBufferSize, sent.Len())
+				assert.True(a.trainResult.BudgetLimited)
+				assert.Equal(int64(UploadBufferSize), a.checkpoint.DownloadOffset)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockTrainerClient := trainerclientmocks.NewMockV1(ctl)
+			mockStream := trainerv1mocks.NewMockTrainer_TrainClient(ctl)
+			mockTrainerClient.EXPECT().Train(gomock.Any()).Return(mockStream, nil).AnyTimes()
+
+			var sent bytes.Buffer
+			tc.mock(mockStream.EXPECT(), &sent)
+
+			s := storage.NewMemory()
+			s.SetDownload(tc.data)
+
+			tc.trainer.UploadTimeout = time.Minute
+			tc.trainer.UploadDownload = true
+			a := &announcer{
+				config: &config.Config{
+					Server: config.ServerConfig{
+						AdvertiseIP: net.ParseIP("127.0.0.1"),
+					},
+					Trainer: tc.trainer,
+				},
+				trainerClient: mockTrainerClient,
+				storage:       s,
+				done:          make(chan struct{}),
+				log:           zap.NewNop().Sugar(),
+			}
+
+			err := a.train()
+			tc.expect(t, a, err, &sent)
+		})
+	}
+}
+
+func TestAnnouncer_StorageVerification(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   func(m *storagemocks.MockStorageMockRecorder, tm *trainerclientmocks.MockV1MockRecorder)
+		expect func(t *testing.T, err error)
+	}{
+		{
+			name: "storage is corrupt",
+			mock: func(m *storagemocks.MockStorageMockRecorder, tm *trainerclientmocks.MockV1MockRecorder) {
+				m.Sync().Return(nil).Times(1)
+				m.Verify().Return(&storage.CorruptionError{Segments: []storage.CorruptSegment{{Filename: "download.csv", Length: 1}}}).Times(1)
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				var corruptionErr *storage.CorruptionError
+				assert.ErrorAs(err, &corruptionErr)
+			},
+		},
+		{
+			name: "verify storage failed",
+			mock: func(m *storagemocks.MockStorageMockRecorder, tm *trainerclientmocks.MockV1MockRecorder) {
+				m.Sync().Return(nil).Times(1)
+				m.Verify().Return(errors.New("foo")).Times(1)
+				tm.Train(gomock.Any()).Return(nil, errors.New("bar")).Times(1)
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.ErrorContains(err, "bar")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockTrainerClient := trainerclientmocks.NewMockV1(ctl)
+			mockStorage := storagemocks.NewMockStorage(ctl)
+			tc.mock(mockStorage.EXPECT(), mockTrainerClient.EXPECT())
+
+			a := &announcer{
+				config: &config.Config{
+					Trainer: config.TrainerConfig{
+						Addr:           "127.0.0.1:9101",
+						UploadTimeout:  time.Minute,
+						UploadDownload: true,
+					},
+				},
+				trainerClient: mockTrainerClient,
+				storage:       mockStorage,
+				done:          make(chan struct{}),
+				log:           zap.NewNop().Sugar(),
+				verifyStorage: true,
+			}
+
+			tc.expect(t, a.train())
+		})
+	}
+}
+
+func TestAnnouncer_TrainerClusterID(t *testing.T) {
+	tests := []struct {
+		name             string
+		trainerClusterID uint
+		expect           uint64
+	}{
+		{
+			name:             "send trainer cluster id",
+			trainerClusterID: 2,
+			expect:           2,
+		},
+		{
+			name:   "fall back to scheduler cluster id",
+			expect: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockTrainerClient := trainerclientmocks.NewMockV1(ctl)
+			mockStream := trainerv1mocks.NewMockTrainer_TrainClient(ctl)
+			s := storage.NewMemory()
+			s.SetDownload([]byte("foo\n"))
+			s.SetNetworkTopology([]byte("bar\n"))
+
+			var (
+				mu         sync.Mutex
+				clusterIDs []uint64
+			)
+			mockTrainerClient.EXPECT().Train(gomock.Any()).Return(mockStream, nil).Times(1)
+			mockStream.EXPECT().Send(gomock.Any()).DoAndReturn(func(req *trainerv1.TrainRequest) error {
+				mu.Lock()
+				defer mu.Unlock()
+				clusterIDs = append(clusterIDs, req.ClusterId)
+				return nil
+			}).Times(2)
+			mockStream.EXPECT().CloseAndRecv().Return(nil, nil).Times(1)
+
+			a := &announcer{
+				config: &config.Config{
+					Server: config.ServerConfig{
+						AdvertiseIP: net.ParseIP("127.0.0.1"),
+					},
+					Manager: config.ManagerConfig{
+						SchedulerClusterID: 1,
+					},
+					Trainer: config.TrainerConfig{
+						Addr:                  "127.0.0.1:9102",
+						ClusterID:             tc.trainerClusterID,
+						UploadTimeout:         time.Minute,
+						UploadDownload:        true,
+						UploadNetworkTopology: true,
+					},
+				},
+				trainerClient: mockTrainerClient,
+				storage:       s,
+				done:          make(chan struct{}),
+				log:           zap.NewNop().Sugar(),
+			}
+
+			assert := assert.New(t)
+			assert.NoError(a.train())
+			assert.Equal([]uint64{tc.expect, tc.expect}, clusterIDs)
+		})
+	}
+}
+
+func TestAnnouncer_SQLStorage(t *testing.T) {
+	assert := assert.New(t)
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	mockTrainerClient := trainerclientmocks.NewMockV1(ctl)
+	mockStream := trainerv1mocks.NewMockTrainer_TrainClient(ctl)
+
+	db, err := sql.Open("sqlite3", "file:announcer?mode=memory&cache=shared")
+	assert.NoError(err)
+	db.SetMaxIdleConns(1)
+	defer db.Close()
+
+	s, err := storage.NewSQL(db)
+	assert.NoError(err)
+	for i := 0; i < 3; i++ {
+		assert.NoError(s.CreateDownload(storage.Download{ID: fmt.Sprint(i)}))
+	}
+	assert.NoError(s.CreateNetworkTopology(storage.NetworkTopology{ID: "foo"}))
+
+	var (
+		mu                        sync.Mutex
+		download, networkTopology string
+	)
+	mockTrainerClient.EXPECT().Train(gomock.Any()).Return(mockStream, nil).Times(1)
+	mockStream.EXPECT().Send(gomock.Any()).DoAndReturn(func(req *trainerv1.TrainRequest) error {
+		mu.Lock()
+		defer mu.Unlock()
+		download += string(req.GetTrainMlpRequest().GetDataset())
+		networkTopology += string(req.GetTrainGnnRequest().GetDataset())
+		return nil
+	}).AnyTimes()
+	mockStream.EXPECT().CloseAndRecv().Return(nil, nil).Times(1)
+
+	a := &announcer{
+		config: &config.Config{
+			Server: config.ServerConfig{
+				AdvertiseIP: net.ParseIP("127.0.0.1"),
+			},
+			Trainer: config.TrainerConfig{
+				Addr:                  "127.0.0.1:9103",
+				UploadTimeout:         time.Minute,
+				UploadDownload:        true,
+				UploadNetworkTopology: true,
+			},
+		},
+		trainerClient: mockTrainerClient,
+		storage:       s,
+		done:          make(chan struct{}),
+		log:           zap.NewNop().Sugar(),
+	}
+
+	assert.NoError(a.train())
+
+	var downloads []storage.Download
+	assert.NoError(gocsv.UnmarshalWithoutHeaders(strings.NewReader(download), &downloads))
+	assert.Len(downloads, 3)
+	assert.Equal("2", downloads[2].ID)
+
+	var networkTopologies []storage.NetworkTopology
+	assert.NoError(gocsv.UnmarshalWithoutHeaders(strings.NewReader(networkTopology), &networkTopologies))
+	assert.Len(networkTopologies, 1)
+	assert.Equal("foo", networkTopologies[0].ID)
+}
+
+func TestAnnouncer_UploadNearTimeout(t *testing.T) {
+	tests := []struct {
+		name   string
+		addr   string
+		ratio  float64
+		delay  time.Duration
+		expect func(t *testing.T, logs *observer.ObservedLogs, addr string)
+	}{
+		{
+			name:  "slow upload crosses warning ratio",
+			addr:  "127.0.0.1:9104",
+			ratio: 0.5,
+			delay: 300 * time.Millisecond,
+			expect: func(t *testing.T, logs *observer.ObservedLogs, addr string) {
+				assert := assert.New(t)
+				assert.Equal(1, logs.FilterMessageSnippet("exceeding 50% of upload timeout").Len())
+				assert.Equal(float64(1), testutil.ToFloat64(metrics.TrainUploadNearTimeoutCount.WithLabelValues(addr)))
+			},
+		},
+		{
+			name:  "fast upload does not cross warning ratio",
+			addr:  "127.0.0.1:9105",
+			ratio: 0.5,
+			expect: func(t *testing.T, logs *observer.ObservedLogs, addr string) {
+				assert := assert.New(t)
+				assert.Equal(0, logs.FilterMessageSnippet("of upload timeout").Len())
+				assert.Equal(float64(0), testutil.ToFloat64(metrics.TrainUploadNearTimeoutCount.WithLabelValues(addr)))
+			},
+		},
+		{
+			name:  "warning is disabled",
+			addr:  "127.0.0.1:9106",
+			delay: 300 * time.Millisecond,
+			expect: func(t *testing.T, logs *observer.ObservedLogs, addr string) {
+				assert := assert.New(t)
+				assert.Equal(0, logs.FilterMessageSnippet("of upload timeout").Len())
+				assert.Equal(float64(0), testutil.ToFloat64(metrics.TrainUploadNearTimeoutCount.WithLabelValues(addr)))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockTrainerClient := trainerclientmocks.NewMockV1(ctl)
+			mockStream := trainerv1mocks.NewMockTrainer_TrainClient(ctl)
+			s := storage.NewMemory()
+			s.SetDownload([]byte("foo\n"))
+
+			mockTrainerClient.EXPECT().Train(gomock.Any()).Return(mockStream, nil).Times(1)
+			mockStream.EXPECT().Send(gomock.Any()).DoAndReturn(func(*trainerv1.TrainRequest) error {
+				time.Sleep(tc.delay)
+				return nil
+			}).Times(1)
+			mockStream.EXPECT().CloseAndRecv().Return(nil, nil).Times(1)
+
+			core, logs := observer.New(zapcore.WarnLevel)
+			a := &announcer{
+				config: &config.Config{
+					Server: config.ServerConfig{
+						AdvertiseIP: net.ParseIP("127.0.0.1"),
+					},
+					Trainer: config.TrainerConfig{
+						Addr:                      tc.addr,
+						UploadTimeout:             500 * time.Millisecond,
+						UploadTimeoutWarningRatio: tc.ratio,
+						UploadDownload:            true,
+					},
+				},
+				trainerClient: mockTrainerClient,
+				storage:       s,
+				done:          make(chan struct{}),
+				log:           zap.New(core).Sugar(),
+			}
+
+			assert.NoError(t, a.train())
+			tc.expect(t, logs, tc.addr)
+		})
+	}
+}
+
+func TestAnnouncer_UploadPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		// streams is the number of train streams, closed is the number of confirmed train streams.
+		streams int
+		closed  int
+		expect  func(t *testing.T, a *announcer, err error)
+	}{
+		{
+			name:    "network topology failure fails download with all-or-nothing policy",
+			policy:  config.TrainerUploadPolicyAllOrNothing,
+			streams: 1,
+			expect: func(t *testing.T, a *announcer, err error) {
+				assert := assert.New(t)
+				assert.Error(err)
+				assert.False(a.trainResult.DownloadSucceeded)
+				assert.False(a.trainResult.NetworkTopologySucceeded)
+				assert.Equal(int64(0), a.checkpoint.DownloadOffset)
+				assert.Equal(int64(0), a.checkpoint.NetworkTopologyOffset)
+			},
+		},
+		{
+			name:    "network topology failure keeps download with best-effort policy",
+			policy:  config.TrainerUploadPolicyBestEffort,
+			streams: 2,
+			closed:  1,
+			expect: func(t *testing.T, a *announcer, err error) {
+				assert := assert.New(t)
+				assert.ErrorContains(err, "upload network topology to trainer 127.0.0.1:9107")
+				assert.True(a.trainResult.DownloadSucceeded)
+				assert.False(a.trainResult.NetworkTopologySucceeded)
+				assert.Equal(int64(4), a.trainResult.DownloadBytes)
+				assert.Equal(int64(4), a.checkpoint.DownloadOffset)
+				assert.Equal(int64(0), a.checkpoint.NetworkTopologyOffset)
+				assert.Equal(int64(0), a.uploadedSize)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockTrainerClient := trainerclientmocks.NewMockV1(ctl)
+			mockStream := trainerv1mocks.NewMockTrainer_TrainClient(ctl)
+			s := storage.NewMemory()
+			s.SetDownload([]byte("foo\n"))
+			s.SetNetworkTopology([]byte("bar\n"))
+
+			mockTrainerClient.EXPECT().Train(gomock.Any()).Return(mockStream, nil).Times(tc.streams)
+			mockStream.EXPECT().CloseAndRecv().Return(nil, nil).Times(tc.closed)
+			mockStream.EXPECT().Send(gomock.Any()).DoAndReturn(func(req *trainerv1.TrainRequest) error {
+				if req.GetTrainGnnRequest() != nil {
+					return errors.New("foo")
+				}
+
+				return nil
+			}).AnyTimes()
+
+			a := &announcer{
+				config: &config.Config{
+					Server: config.ServerConfig{
+						AdvertiseIP: net.ParseIP("127.0.0.1"),
+					},
+					Trainer: config.TrainerConfig{
+						Addr:                  "127.0.0.1:9107",
+						UploadTimeout:         time.Minute,
+						UploadDownload:        true,
+						UploadNetworkTopology: true,
+						UploadPolicy:          tc.policy,
+					},
+				},
+				trainerClient: mockTrainerClient,
+				storage:       s,
+				done:          make(chan struct{}),
+				log:           zap.NewNop().Sugar(),
+			}
+
+			tc.expect(t, a, a.train())
+		})
+	}
+}
+
+func TestAnnouncer_RecordCounts(t *testing.T) {
+	// lineTransform keeps the lines of csv matched by the matcher returned by newMatcher.
+	lineTransform := func(newMatcher func() func(string) bool) func(io.Reader) io.Reader {
+		return func(r io.Reader) io.Reader {
+			pr, pw := io.Pipe()
+			go func() {
+				match := newMatcher()
+				scanner := bufio.NewScanner(r)
+				for scanner.Scan() {
+					if match(scanner.Text()) {
+						fmt.Fprintln(pw, scanner.Text())
+					}
+				}
+				pw.CloseWithError(scanner.Err())
+			}()
+
+			return pr
+		}
+	}
+
+	filter := UploadTransform{
+		Name: "filter",
+		Transform: lineTransform(func() func(string) bool {
+			return func(line string) bool { return !strings.HasPrefix(line, "skip") }
+		}),
+	}
+
+	dedup := UploadTransform{
+		Name: "dedup",
+		Transform: lineTransform(func() func(string) bool {
+			seen := make(map[string]bool)
+			return func(line string) bool {
+				if seen[line] {
+					return false
+				}
+
+				seen[line] = true
+				return true
+			}
+		}),
+	}
+
+	tests := []struct {
+		name              string
+		download          string
+		uploadSegmentSize int64
+		transforms        []UploadTransform
+		expect            func(t *testing.T, counts RecordCounts, sent string)
+	}{
+		{
+			name:       "count records filtered out and deduplicated",
+			download:   "a\nskip1\nb\na\nskip2\nc",
+			transforms: []UploadTransform{filter, dedup},
+			expect: func(t *testing.T, counts RecordCounts, sent string) {
+				assert := assert.New(t)
+				assert.Equal("a\nb\nc\n", sent)
+				assert.Equal(RecordCounts{Read: 6, Dropped: map[string]int64{"filter": 2, "dedup": 1}, Sent: 3}, counts)
+				assert.Equal(counts.Read, counts.Dropped["filter"]+counts.Dropped["dedup"]+counts.Sent)
+			},
+		},
+		{
+			name:       "count records without dropping",
+			download:   "a\nb\n",
+			transforms: []UploadTransform{filter},
+			expect: func(t *testing.T, counts RecordCounts, sent string) {
+				assert := assert.New(t)
+				assert.Equal(RecordCounts{Read: 2, Dropped: map[string]int64{"filter": 0}, Sent: 2}, counts)
+			},
+		},
+		{
+			name:              "count records split across segments once",
+			download:          "a\nbb\nccc\nd",
+			uploadSegmentSize: 4,
+			expect: func(t *testing.T, counts RecordCounts, sent string) {
+				assert := assert.New(t)
+				assert.Equal("a\nbb\nccc\nd", sent)
+				assert.Equal(RecordCounts{Read: 4, Sent: 4}, counts)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockTrainerClient := trainerclientmocks.NewMockV1(ctl)
+			mockStream := trainerv1mocks.NewMockTrainer_TrainClient(ctl)
+			s := storage.NewMemory()
+			s.SetDownload([]byte(tc.download))
+
+			var sent string
+			mockTrainerClient.EXPECT().Train(gomock.Any()).Return(mockStream, nil).AnyTimes()
+			mockStream.EXPECT().Send(gomock.Any()).DoAndReturn(func(req *trainerv1.TrainRequest) error {
+				sent += string(req.GetTrainMlpRequest().GetDataset())
+				return nil
+			}).AnyTimes()
+			mockStream.EXPECT().CloseAndRecv().Return(nil, nil).AnyTimes()
+
+			a := &announcer{
+				config: &config.Config{
+					Server: config.ServerConfig{
+						AdvertiseIP: net.ParseIP("127.0.0.1"),
+					},
+					Trainer: config.TrainerConfig{
+						Addr:              "127.0.0.1:9108",
+						UploadTimeout:     time.Minute,
+						UploadSegmentSize: tc.uploadSegmentSize,
+						UploadDownload:    true,
+					},
+				},
+				trainerClient:    mockTrainerClient,
+				storage:          s,
+				uploadTransforms: tc.transforms,
+				done:             make(chan struct{}),
+				log:              zap.NewNop().Sugar(),
+			}
+
+			assert.NoError(t, a.train())
+			tc.expect(t, a.trainResult.DownloadRecords, sent)
+		})
+	}
+}
+
+func TestAnnouncer_ShadowTrainer(t *testing.T) {
+	tests := []struct {
+		name       string
+		shadowSend func(*trainerv1.TrainRequest) error
+		closed     int
+		expect     func(t *testing.T, primary, shadow string, failures float64)
+	}{
+		{
+			name:       "shadow trainer failure does not fail primary trainer",
+			shadowSend: func(*trainerv1.TrainRequest) error { return errors.New("foo") },
+			expect: func(t *testing.T, primary, shadow string, failures float64) {
+				assert := assert.New(t)
+				assert.Equal("foo\n", primary)
+				assert.Equal(float64(1), failures)
+			},
+		},
+		{
+			name:       "shadow trainer receives the datasets of primary trainer",
+			shadowSend: func(*trainerv1.TrainRequest) error { return nil },
+			closed:     1,
+			expect: func(t *testing.T, primary, shadow string, failures float64) {
+				assert := assert.New(t)
+				assert.Equal("foo\n", primary)
+				assert.Equal(primary, shadow)
+				assert.Equal(float64(0), failures)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockTrainerClient := trainerclientmocks.NewMockV1(ctl)
+			mockStream := trainerv1mocks.NewMockTrainer_TrainClient(ctl)
+			mockShadowTrainerClient := trainerclientmocks.NewMockV1(ctl)
+			mockShadowStream := trainerv1mocks.NewMockTrainer_TrainClient(ctl)
+			s := storage.NewMemory()
+			s.SetDownload([]byte("foo\n"))
+
+			var (
+				mu      sync.Mutex
+				primary bytes.Buffer
+				shadow  bytes.Buffer
+			)
+			write := func(buf *bytes.Buffer, req *trainerv1.TrainRequest) {
+				mu.Lock()
+				defer mu.Unlock()
+				switch r := req.Request.(type) {
+				case *trainerv1.TrainRequest_TrainMlpRequest:
+					buf.Write(r.TrainMlpRequest.Dataset)
+				case *trainerv1.TrainRequest_TrainGnnRequest:
+					buf.Write(r.TrainGnnRequest.Dataset)
+				}
+			}
+
+			mockTrainerClient.EXPECT().Train(gomock.Any()).Return(mockStream, nil).Times(1)
+			mockStream.EXPECT().Send(gomock.Any()).DoAndReturn(func(req *trainerv1.TrainRequest) error {
+				write(&primary, req)
+				return nil
+			}).AnyTimes()
+			mockStream.EXPECT().CloseAndRecv().Return(nil, nil).Times(1)
+			mockShadowTrainerClient.EXPECT().Train(gomock.Any()).Return(mockShadowStream, nil).Times(1)
+			mockShadowStream.EXPECT().Send(gomock.Any()).DoAndReturn(func(req *trainerv1.TrainRequest) error {
+				if err := tc.shadowSend(req); err != nil {
+					return err
+				}
+
+				write(&shadow, req)
+				return nil
+			}).AnyTimes()
+			mockShadowStream.EXPECT().CloseAndRecv().Return(nil, nil).Times(tc.closed)
+
+			a := &announcer{
+				config: &config.Config{
+					Server: config.ServerConfig{
+						AdvertiseIP: net.ParseIP("127.0.0.1"),
+					},
+					Trainer: config.TrainerConfig{
+						Addr:           "127.0.0.1:9109",
+						UploadTimeout:  time.Minute,
+						UploadDownload: true,
+					},
+				},
+				trainerClient:       mockTrainerClient,
+				shadowTrainerClient: mockShadowTrainerClient,
+				storage:             s,
+				done:                make(chan struct{}),
+				log:                 zap.NewNop().Sugar(),
+			}
+
+			failures := testutil.ToFloat64(metrics.TrainShadowFailureCount)
+			assert.NoError(t, a.train())
+			assert.True(t, a.trainResult.DownloadSucceeded)
+			tc.expect(t, primary.String(), shadow.String(), testutil.ToFloat64(metrics.TrainShadowFailureCount)-failures)
+		})
+	}
+}
+
+func TestAnnouncer_StartupJitter(t *testing.T) {
+	const (
+		interval = 100 * time.Millisecond
+		delay    = 300 * time.Millisecond
+	)
+
+	tests := []struct {
+		name          string
+		startupJitter time.Duration
+		expect        func(t *testing.T, elapsed time.Duration, jitters []time.Duration)
+	}{
+		{
+			name: "first cycle fires at interval without startup jitter",
+			expect: func(t *testing.T, elapsed time.Duration, jitters []time.Duration) {
+				assert := assert.New(t)
+				assert.Empty(jitters)
+				assert.GreaterOrEqual(elapsed, interval)
+				assert.Less(elapsed, interval+delay)
+			},
+		},
+		{
+			name:          "first cycle is delayed by startup jitter",
+			startupJitter: 2 * delay,
+			expect: func(t *testing.T, elapsed time.Duration, jitters []time.Duration) {
+				assert := assert.New(t)
+				assert.Equal([]time.Duration{2 * delay}, jitters)
+				assert.GreaterOrEqual(elapsed, interval+delay)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockTrainerClient := trainerclientmocks.NewMockV1(ctl)
+			s := storage.NewMemory()
+			s.SetDownload([]byte("foo\n"))
+
+			trained := make(chan time.Time, 1)
+			mockTrainerClient.EXPECT().Train(gomock.Any()).DoAndReturn(func(ctx context.Context, opts ...grpc.CallOption) (trainerv1.Trainer_TrainClient, error) {
+				select {
+				case trained <- time.Now():
+				default:
+				}
+
+				return nil, errors.New("foo")
+			}).MinTimes(1)
+
+			var jitters []time.Duration
+			a := &announcer{
+				config: &config.Config{
+					Server: config.ServerConfig{
+						AdvertiseIP: net.ParseIP("127.0.0.1"),
+					},
+					Trainer: config.TrainerConfig{
+						Addr:           "127.0.0.1:9110",
+						Interval:       interval,
+						StartupJitter:  tc.startupJitter,
+						UploadTimeout:  time.Minute,
+						UploadDownload: true,
+					},
+				},
+				jitter: func(max time.Duration) time.Duration {
+					jitters = append(jitters, max)
+					return delay
+				},
+				standalone:    true,
+				trainerClient: mockTrainerClient,
+				storage:       s,
+				done:          make(chan struct{}),
+				log:           zap.NewNop().Sugar(),
+			}
+
+			start := time.Now()
+			go a.announceToTrainer()
+			elapsed := (<-trained).Sub(start)
+			close(a.done)
+			a.wg.Wait()
+
+			tc.expect(t, elapsed, jitters)
+		})
+	}
+}
+
+func TestAnnouncer_UploadBatching(t *testing.T) {
+	tests := []struct {
+		name            string
+		uploadBatchSize int
+		// chunkSize is the max size of dataset in each message limited by the max message size,
+		// zero means the max message size is not limited.
+		chunkSize int
+		expect    func(t *testing.T, downloads []string)
+	}{
+		{
+			name: "each read is sent without batching",
+			expect: func(t *testing.T, downloads []string) {
+				assert := assert.New(t)
+				assert.Len(downloads, 12)
+				assert.Equal("foo\nbar\nbaz\n", strings.Join(downloads, ""))
+			},
+		},
+		{
+			name:            "reads are coalesced up to upload batch size",
+			uploadBatchSize: 8,
+			expect: func(t *testing.T, downloads []string) {
+				assert := assert.New(t)
+				assert.Equal([]string{"foo\nbar\n", "baz\n"}, downloads)
+			},
+		},
+		{
+			name:            "upload batch size is capped by max message size",
+			uploadBatchSize: 64,
+			chunkSize:       5,
+			expect: func(t *testing.T, downloads []string) {
+				assert := assert.New(t)
+				assert.Equal([]string{"foo\nb", "ar\nba", "z\n"}, downloads)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockStorage := storagemocks.NewMockStorage(ctl)
+			mockStream := trainerv1mocks.NewMockTrainer_TrainClient(ctl)
+
+			a := &announcer{
+				config: &config.Config{
+					Server: config.ServerConfig{
+						AdvertiseIP: net.ParseIP("127.0.0.1"),
+					},
+					Trainer: config.TrainerConfig{
+						UploadBatchSize: tc.uploadBatchSize,
+					},
+				},
+				storage: mockStorage,
+				log:     zap.NewNop().Sugar(),
+			}
+
+			if tc.chunkSize > 0 {
+				a.config.Trainer.MaxMessageSize = UploadBufferSize
+				size, err := a.maxChunkSize()
+				assert.NoError(t, err)
+				a.config.Trainer.MaxMessageSize = UploadBufferSize - size + tc.chunkSize
+			}
+
+			var downloads []string
+			mockStorage.EXPECT().OpenDownload().Return(io.NopCloser(iotest.OneByteReader(strings.NewReader("foo\nbar\nbaz\n"))), nil).Times(1)
+			mockStream.EXPECT().Send(gomock.Any()).DoAndReturn(func(req *trainerv1.TrainRequest) error {
+				if a.config.Trainer.MaxMessageSize > 0 {
+					assert.LessOrEqual(t, proto.Size(req), a.config.Trainer.MaxMessageSize)
+				}
+
+				downloads = append(downloads, string(req.GetTrainMlpRequest().Dataset))
+				return nil
+			}).AnyTimes()
+
+			offset, done, err := a.uploadDownloadToTrainer(mockStream, 0, 0)
+			assert.NoError(t, err)
+			assert.True(t, done)
+			assert.Equal(t, int64(12), offset)
+			tc.expect(t, downloads)
+		})
+	}
+}
+
+// countingStream counts the messages sent to trainer.
+type countingStream struct {
+	trainerv1.Trainer_TrainClient
+	messages int
+}
+
+func (s *countingStream) Send(*trainerv1.TrainRequest) error {
+	s.messages++
+	return nil
+}
+
+func BenchmarkAnnouncer_UploadBatching(b *testing.B) {
+	records := make([]string, 1000)
+	for i := range records {
+		records[i] = fmt.Sprintf("record-%d\n", i)
+	}
+
+	benchmarks := []struct {
+		name            string
+		uploadBatchSize int
+	}{
+		{
+			name: "without batching",
+		},
+		{
+			name:            "with batching",
+			uploadBatchSize: 4096,
+		},
+	}
+
+	for _, bm := range benchmarks {
+		b.Run(bm.name, func(b *testing.B) {
+			stream := &countingStream{}
+			a := &announcer{
+				config: &config.Config{
+					Server: config.ServerConfig{
+						AdvertiseIP: net.ParseIP("127.0.0.1"),
+					},
+					Trainer: config.TrainerConfig{
+						UploadBatchSize: bm.uploadBatchSize,
+					},
+				},
+				log: zap.NewNop().Sugar(),
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				// Each read of storage returns a record.
+				a.storage = &chunkStorage{Storage: storage.NewMemory(), chunks: records}
+				if _, _, err := a.uploadDownloadToTrainer(stream, 0, 0); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			b.ReportMetric(float64(stream.messages)/float64(b.N), "messages/op")
+		})
+	}
+}
+
+// chunkStorage is the storage whose download is read in chunks.
+type chunkStorage struct {
+	storage.Storage
+	chunks []string
+}
+
+func (s *chunkStorage) OpenDownload() (io.ReadCloser, error) {
+	return &mockChunkReader{chunks: append([]string(nil), s.chunks...)}, nil
+}
+
+func TestAnnouncer_AdvertiseAddr(t *testing.T) {
+	tests := []struct {
+		name        string
+		advertiseIP net.IP
+		standalone  bool
+		expect      func(t *testing.T, ip net.IP, err error)
+	}{
+		{
+			name:        "registered with ipv4 advertise ip",
+			advertiseIP: net.ParseIP("127.0.0.1"),
+			expect: func(t *testing.T, ip net.IP, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("127.0.0.1", ip.String())
+				assert.NotNil(ip.To4())
+			},
+		},
+		{
+			name:        "registered with ipv6 advertise ip",
+			advertiseIP: net.ParseIP("::1"),
+			expect: func(t *testing.T, ip net.IP, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("::1", ip.String())
+				assert.Nil(ip.To4())
+			},
+		},
+		{
+			name:        "not registered in standalone mode",
+			advertiseIP: net.ParseIP("127.0.0.1"),
+			standalone:  true,
+			expect: func(t *testing.T, ip net.IP, err error) {
+				assert := assert.New(t)
+				assert.ErrorIs(err, ErrNotRegistered)
+				assert.Nil(ip)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockManagerClient := clientmocks.NewMockV2(ctl)
+			mockStorage := storagemocks.NewMockStorage(ctl)
+
+			var sent string
+			mockManagerClient.EXPECT().UpdateScheduler(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(ctx context.Context, req *managerv2.UpdateSchedulerRequest, opts ...grpc.CallOption) (*managerv2.Scheduler, error) {
+					sent = req.Ip
+					return nil, nil
+				}).MaxTimes(1)
+
+			a, err := New(&config.Config{
+				Server: config.ServerConfig{
+					Host:          "localhost",
+					AdvertiseIP:   tc.advertiseIP,
+					AdvertisePort: 8004,
+					Port:          8080,
+				},
+			}, mockManagerClient, mockStorage, WithStandalone(tc.standalone), WithLogger(zap.NewNop().Sugar()))
+			assert.NoError(t, err)
+
+			ip, err := a.AdvertiseAddr()
+			if !tc.standalone {
+				assert.Equal(t, sent, ip.String())
+			}
+
+			tc.expect(t, ip, err)
+		})
+	}
+}
+
+func TestAnnouncer_KeepAliveMaxConsecutiveFailures(t *testing.T) {
+	tests := []struct {
+		name                   string
+		maxConsecutiveFailures int
+		// results are the results of keepalive observed in order, nil means succeeded.
+		results []error
+		expect  func(t *testing.T, errs <-chan error)
+	}{
+		{
+			name:                   "failures exceed max consecutive failures",
+			maxConsecutiveFailures: 3,
+			results:                []error{nil, errors.New("foo"), errors.New("foo"), errors.New("foo"), errors.New("bar"), errors.New("baz")},
+			expect: func(t *testing.T, errs <-chan error) {
+				assert := assert.New(t)
+				select {
+				case err := <-errs:
+					assert.ErrorIs(err, ErrKeepAliveFailed)
+					assert.ErrorContains(err, "bar")
+				default:
+					t.Fatal("unrecoverable error is not reported")
+				}
+
+				assert.Empty(errs)
+			},
+		},
+		{
+			name:                   "success resets consecutive failures",
+			maxConsecutiveFailures: 3,
+			results:                []error{errors.New("foo"), errors.New("foo"), errors.New("foo"), nil, errors.New("foo"), errors.New("foo"), errors.New("foo")},
+			expect: func(t *testing.T, errs <-chan error) {
+				assert.Empty(t, errs)
+			},
+		},
+		{
+			name:    "keepalive retries forever without max consecutive failures",
+			results: []error{errors.New("foo"), errors.New("foo"), errors.New("foo"), errors.New("foo"), errors.New("foo")},
+			expect: func(t *testing.T, errs <-chan error) {
+				assert.Empty(t, errs)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockManagerClient := clientmocks.NewMockV2(ctl)
+
+			var wg sync.WaitGroup
+			wg.Add(1)
+			mockManagerClient.EXPECT().KeepAlive(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
+				func(interval time.Duration, req *managerv2.KeepAliveRequest, done <-chan struct{}, opts ...grpc.CallOption) {
+					defer wg.Done()
+					for _, opt := range opts {
+						if o, ok := opt.(managerclient.KeepAliveObserverCallOption); ok {
+							for _, result := range tc.results {
+								o.Observer(result)
+							}
+						}
+					}
+				}).Times(1)
+
+			a := &announcer{
+				config: &config.Config{
+					Server: config.ServerConfig{
+						AdvertiseIP: net.ParseIP("127.0.0.1"),
+					},
+					Manager: config.ManagerConfig{
+						KeepAlive: config.KeepAliveConfig{
+							Interval:               time.Second,
+							MaxConsecutiveFailures: tc.maxConsecutiveFailures,
+						},
+					},
+				},
+				managerClient: mockManagerClient,
+				done:          make(chan struct{}),
+				errs:          make(chan error, 1),
+				log:           zap.NewNop().Sugar(),
+			}
+
+			assert.NoError(t, a.announceToManager())
+			wg.Wait()
+			tc.expect(t, a.Err())
+		})
+	}
+}
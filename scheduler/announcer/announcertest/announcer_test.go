@@ -0,0 +1,130 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package announcertest
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"d7y.io/dragonfly/v2/scheduler/announcer"
+	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/storage"
+)
+
+// The tests below show the usage of fakes for testing announcer.
+
+func TestAnnouncer_RegisterWithRetry(t *testing.T) {
+	manager := NewManager()
+	manager.FailUpdateScheduler(2, status.Error(codes.Unavailable, "foo"))
+
+	a, err := announcer.New(newConfig(), manager, storage.NewMemory(),
+		announcer.WithUpdateSchedulerRetry(3, time.Millisecond, time.Millisecond))
+
+	assert := assert.New(t)
+	assert.NoError(err)
+	assert.Len(manager.UpdateSchedulerRequests(), 3)
+	assert.Equal("foo", manager.UpdateSchedulerRequests()[0].Hostname)
+	assert.NoError(a.Stop())
+}
+
+func TestAnnouncer_TrainCycle(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   func(trainer *Trainer)
+		expect func(t *testing.T, trainer *Trainer, event announcer.Event)
+	}{
+		{
+			name: "train stream records all sends",
+			mock: func(trainer *Trainer) {},
+			expect: func(t *testing.T, trainer *Trainer, event announcer.Event) {
+				assert := assert.New(t)
+				assert.Equal(announcer.EventCycleSucceeded, event.Type)
+				assert.Equal("foo\nbar\n", string(trainer.Streams()[0].Download()))
+				assert.True(trainer.Streams()[0].Closed())
+			},
+		},
+		{
+			name: "train fails",
+			mock: func(trainer *Trainer) {
+				trainer.FailTrain(1, errors.New("foo"))
+			},
+			expect: func(t *testing.T, trainer *Trainer, event announcer.Event) {
+				assert := assert.New(t)
+				assert.Equal(announcer.EventCycleFailed, event.Type)
+				assert.ErrorContains(event.Err, "foo")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			manager := NewManager()
+			trainer := NewTrainer()
+			tc.mock(trainer)
+
+			s := storage.NewMemory()
+			s.SetDownload([]byte("foo\nbar\n"))
+
+			a, err := announcer.New(newConfig(), manager, s, announcer.WithTrainerClient(trainer))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			events := a.Subscribe()
+			go a.Serve()
+			defer a.Stop()
+
+			for event := range events {
+				if event.Type == announcer.EventCycleSucceeded || event.Type == announcer.EventCycleFailed {
+					tc.expect(t, trainer, event)
+					return
+				}
+			}
+		})
+	}
+}
+
+// newConfig returns the configuration of announcer training every 10 milliseconds.
+func newConfig() *config.Config {
+	return &config.Config{
+		Server: config.ServerConfig{
+			Host:          "foo",
+			AdvertiseIP:   net.ParseIP("127.0.0.1"),
+			AdvertisePort: 8004,
+			Port:          8080,
+		},
+		Manager: config.ManagerConfig{
+			SchedulerClusterID: 1,
+			KeepAlive: config.KeepAliveConfig{
+				Interval: time.Second,
+			},
+		},
+		Trainer: config.TrainerConfig{
+			Enable:         true,
+			Addr:           "127.0.0.1:9090",
+			Interval:       10 * time.Millisecond,
+			UploadTimeout:  time.Minute,
+			UploadDownload: true,
+		},
+	}
+}
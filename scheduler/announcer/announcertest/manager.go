@@ -0,0 +1,265 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package announcertest provides the fakes of manager and trainer clients for
+// testing the announcer of scheduler outside its package.
+package announcertest
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	managerv2 "d7y.io/api/pkg/apis/manager/v2"
+
+	managerclient "d7y.io/dragonfly/v2/pkg/rpc/manager/client"
+)
+
+// Manager is the fake of manager client, the responses of UpdateScheduler, GetScheduler
+// and KeepAlive are scripted and the requests are recorded. The other calls return
+// the Unimplemented error. It is safe for concurrent use.
+type Manager struct {
+	mu sync.Mutex
+
+	// scheduler is returned by UpdateScheduler and GetScheduler after the scripted errors.
+	scheduler *managerv2.Scheduler
+
+	// updateSchedulerErrs, getSchedulerErrs and keepAliveErrs are the scripted errors
+	// returned by the next calls in order.
+	updateSchedulerErrs []error
+	getSchedulerErrs    []error
+	keepAliveErrs       []error
+
+	updateSchedulerRequests []*managerv2.UpdateSchedulerRequest
+	getSchedulerRequests    []*managerv2.GetSchedulerRequest
+	keepAliveRequests       []*managerv2.KeepAliveRequest
+	keepAlives              int
+	closed                  bool
+}
+
+// Manager implements the manager client.
+var _ managerclient.V2 = (*Manager)(nil)
+
+// NewManager returns the fake of manager client succeeding all scripted calls.
+func NewManager() *Manager {
+	return &Manager{
+		scheduler: &managerv2.Scheduler{},
+	}
+}
+
+// SetScheduler sets the scheduler returned by UpdateScheduler and GetScheduler.
+func (m *Manager) SetScheduler(scheduler *managerv2.Scheduler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.scheduler = scheduler
+}
+
+// FailUpdateScheduler makes the next times calls of UpdateScheduler return err,
+// e.g. FailUpdateScheduler(2, err) fails UpdateScheduler twice then succeeds.
+func (m *Manager) FailUpdateScheduler(times int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.updateSchedulerErrs = appendErrors(m.updateSchedulerErrs, times, err)
+}
+
+// FailGetScheduler makes the next times calls of GetScheduler return err.
+func (m *Manager) FailGetScheduler(times int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.getSchedulerErrs = appendErrors(m.getSchedulerErrs, times, err)
+}
+
+// FailKeepAlive makes the next times keepalives report err to the keepalive observer,
+// the keepalives after them report success.
+func (m *Manager) FailKeepAlive(times int, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.keepAliveErrs = appendErrors(m.keepAliveErrs, times, err)
+}
+
+// UpdateSchedulerRequests returns the requests of UpdateScheduler in order.
+func (m *Manager) UpdateSchedulerRequests() []*managerv2.UpdateSchedulerRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]*managerv2.UpdateSchedulerRequest(nil), m.updateSchedulerRequests...)
+}
+
+// GetSchedulerRequests returns the requests of GetScheduler in order.
+func (m *Manager) GetSchedulerRequests() []*managerv2.GetSchedulerRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]*managerv2.GetSchedulerRequest(nil), m.getSchedulerRequests...)
+}
+
+// KeepAliveRequests returns the requests of KeepAlive in order.
+func (m *Manager) KeepAliveRequests() []*managerv2.KeepAliveRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]*managerv2.KeepAliveRequest(nil), m.keepAliveRequests...)
+}
+
+// KeepAlives returns the number of keepalives sent, including the failed ones.
+func (m *Manager) KeepAlives() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.keepAlives
+}
+
+// Closed returns whether the client is closed.
+func (m *Manager) Closed() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.closed
+}
+
+// UpdateSeedPeer returns the Unimplemented error.
+func (m *Manager) UpdateSeedPeer(context.Context, *managerv2.UpdateSeedPeerRequest, ...grpc.CallOption) (*managerv2.SeedPeer, error) {
+	return nil, unimplemented("UpdateSeedPeer")
+}
+
+// GetScheduler records req and returns the scheduler or the scripted error.
+func (m *Manager) GetScheduler(ctx context.Context, req *managerv2.GetSchedulerRequest, opts ...grpc.CallOption) (*managerv2.Scheduler, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.getSchedulerRequests = append(m.getSchedulerRequests, req)
+	if err := popError(&m.getSchedulerErrs); err != nil {
+		return nil, err
+	}
+
+	return m.scheduler, nil
+}
+
+// UpdateScheduler records req and returns the scheduler or the scripted error.
+func (m *Manager) UpdateScheduler(ctx context.Context, req *managerv2.UpdateSchedulerRequest, opts ...grpc.CallOption) (*managerv2.Scheduler, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.updateSchedulerRequests = append(m.updateSchedulerRequests, req)
+	if err := popError(&m.updateSchedulerErrs); err != nil {
+		return nil, err
+	}
+
+	return m.scheduler, nil
+}
+
+// ListSchedulers returns the Unimplemented error.
+func (m *Manager) ListSchedulers(context.Context, *managerv2.ListSchedulersRequest, ...grpc.CallOption) (*managerv2.ListSchedulersResponse, error) {
+	return nil, unimplemented("ListSchedulers")
+}
+
+// GetObjectStorage returns the Unimplemented error.
+func (m *Manager) GetObjectStorage(context.Context, *managerv2.GetObjectStorageRequest, ...grpc.CallOption) (*managerv2.ObjectStorage, error) {
+	return nil, unimplemented("GetObjectStorage")
+}
+
+// ListBuckets returns the Unimplemented error.
+func (m *Manager) ListBuckets(context.Context, *managerv2.ListBucketsRequest, ...grpc.CallOption) (*managerv2.ListBucketsResponse, error) {
+	return nil, unimplemented("ListBuckets")
+}
+
+// ListApplications returns the Unimplemented error.
+func (m *Manager) ListApplications(context.Context, *managerv2.ListApplicationsRequest, ...grpc.CallOption) (*managerv2.ListApplicationsResponse, error) {
+	return nil, unimplemented("ListApplications")
+}
+
+// CreateModel returns the Unimplemented error.
+func (m *Manager) CreateModel(context.Context, *managerv2.CreateModelRequest, ...grpc.CallOption) error {
+	return unimplemented("CreateModel")
+}
+
+// KeepAlive records req and sends a keepalive every interval until done is closed, the result
+// of each keepalive is reported to the observer set by managerclient.WithKeepAliveObserver.
+func (m *Manager) KeepAlive(interval time.Duration, req *managerv2.KeepAliveRequest, done <-chan struct{}, opts ...grpc.CallOption) {
+	m.mu.Lock()
+	m.keepAliveRequests = append(m.keepAliveRequests, req)
+	m.mu.Unlock()
+
+	observer := keepAliveObserver(opts)
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			m.mu.Lock()
+			m.keepAlives++
+			err := popError(&m.keepAliveErrs)
+			m.mu.Unlock()
+
+			observer(err)
+		case <-done:
+			return
+		}
+	}
+}
+
+// Close marks the client closed.
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.closed = true
+	return nil
+}
+
+// keepAliveObserver returns the observer of keepalive set by opts, the observer does nothing if it is not set.
+func keepAliveObserver(opts []grpc.CallOption) managerclient.KeepAliveObserver {
+	for _, opt := range opts {
+		if o, ok := opt.(managerclient.KeepAliveObserverCallOption); ok && o.Observer != nil {
+			return o.Observer
+		}
+	}
+
+	return func(error) {}
+}
+
+// unimplemented returns the Unimplemented error of method.
+func unimplemented(method string) error {
+	return status.Errorf(codes.Unimplemented, "method %s is not implemented by fake", method)
+}
+
+// appendErrors appends times copies of err to errs.
+func appendErrors(errs []error, times int, err error) []error {
+	for i := 0; i < times; i++ {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// popError removes and returns the first error of errs, it returns nil if errs is empty.
+func popError(errs *[]error) error {
+	if len(*errs) == 0 {
+		return nil
+	}
+
+	err := (*errs)[0]
+	*errs = (*errs)[1:]
+	return err
+}
@@ -0,0 +1,137 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package announcertest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	managerv2 "d7y.io/api/pkg/apis/manager/v2"
+
+	managerclient "d7y.io/dragonfly/v2/pkg/rpc/manager/client"
+)
+
+func TestManager_UpdateScheduler(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   func(m *Manager)
+		expect func(t *testing.T, errs []error)
+	}{
+		{
+			name: "update scheduler succeeds",
+			mock: func(m *Manager) {},
+			expect: func(t *testing.T, errs []error) {
+				assert := assert.New(t)
+				assert.Equal([]error{nil, nil, nil}, errs)
+			},
+		},
+		{
+			name: "update scheduler fails twice then succeeds",
+			mock: func(m *Manager) {
+				m.FailUpdateScheduler(2, errors.New("foo"))
+			},
+			expect: func(t *testing.T, errs []error) {
+				assert := assert.New(t)
+				assert.EqualError(errs[0], "foo")
+				assert.EqualError(errs[1], "foo")
+				assert.NoError(errs[2])
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			m := NewManager()
+			m.SetScheduler(&managerv2.Scheduler{Id: 1})
+			tc.mock(m)
+
+			var errs []error
+			for i := 0; i < 3; i++ {
+				scheduler, err := m.UpdateScheduler(context.Background(), &managerv2.UpdateSchedulerRequest{Hostname: "foo"})
+				if err == nil {
+					assert.Equal(t, uint64(1), scheduler.Id)
+				}
+
+				errs = append(errs, err)
+			}
+
+			assert.Len(t, m.UpdateSchedulerRequests(), 3)
+			tc.expect(t, errs)
+		})
+	}
+}
+
+func TestManager_GetScheduler(t *testing.T) {
+	m := NewManager()
+	m.FailGetScheduler(1, errors.New("foo"))
+
+	assert := assert.New(t)
+	_, err := m.GetScheduler(context.Background(), &managerv2.GetSchedulerRequest{})
+	assert.EqualError(err, "foo")
+
+	scheduler, err := m.GetScheduler(context.Background(), &managerv2.GetSchedulerRequest{})
+	assert.NoError(err)
+	assert.NotNil(scheduler)
+	assert.Len(m.GetSchedulerRequests(), 2)
+}
+
+func TestManager_KeepAlive(t *testing.T) {
+	m := NewManager()
+	m.FailKeepAlive(2, errors.New("foo"))
+
+	results := make(chan error, 3)
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		m.KeepAlive(time.Millisecond, &managerv2.KeepAliveRequest{Hostname: "foo"}, done, managerclient.WithKeepAliveObserver(func(err error) {
+			select {
+			case results <- err:
+			default:
+			}
+		}))
+	}()
+
+	assert := assert.New(t)
+	assert.EqualError(<-results, "foo")
+	assert.EqualError(<-results, "foo")
+	assert.NoError(<-results)
+
+	close(done)
+	<-exited
+	assert.GreaterOrEqual(m.KeepAlives(), 3)
+	assert.Len(m.KeepAliveRequests(), 1)
+	assert.Equal("foo", m.KeepAliveRequests()[0].Hostname)
+}
+
+func TestManager_Unimplemented(t *testing.T) {
+	m := NewManager()
+
+	assert := assert.New(t)
+	_, err := m.ListSchedulers(context.Background(), &managerv2.ListSchedulersRequest{})
+	assert.Equal(codes.Unimplemented, status.Code(err))
+	assert.Equal(codes.Unimplemented, status.Code(m.CreateModel(context.Background(), &managerv2.CreateModelRequest{})))
+
+	assert.NoError(m.Close())
+	assert.True(m.Closed())
+}
@@ -0,0 +1,247 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package announcertest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	trainerv1 "d7y.io/api/pkg/apis/trainer/v1"
+
+	trainerclient "d7y.io/dragonfly/v2/pkg/rpc/trainer/client"
+)
+
+// ErrStreamClosed is returned by sending to the closed train stream.
+var ErrStreamClosed = errors.New("train stream is closed")
+
+// Trainer is the fake of trainer client, the results of opening and closing train streams
+// are scripted and each stream records all sends. It is safe for concurrent use.
+type Trainer struct {
+	mu sync.Mutex
+
+	// trainErrs and closeErrs are the scripted errors returned by the next
+	// calls of Train and CloseAndRecv of the next streams in order.
+	trainErrs []error
+	closeErrs []error
+
+	streams []*TrainStream
+	closed  bool
+}
+
+// Trainer implements the trainer client.
+var _ trainerclient.V1 = (*Trainer)(nil)
+
+// NewTrainer returns the fake of trainer client succeeding all scripted calls.
+func NewTrainer() *Trainer {
+	return &Trainer{}
+}
+
+// FailTrain makes the next times calls of Train return err.
+func (t *Trainer) FailTrain(times int, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.trainErrs = appendErrors(t.trainErrs, times, err)
+}
+
+// FailClose makes CloseAndRecv of the next times streams opened return err,
+// the datasets sent are still recorded.
+func (t *Trainer) FailClose(times int, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.closeErrs = appendErrors(t.closeErrs, times, err)
+}
+
+// Streams returns the train streams opened in order.
+func (t *Trainer) Streams() []*TrainStream {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]*TrainStream(nil), t.streams...)
+}
+
+// Closed returns whether the client is closed.
+func (t *Trainer) Closed() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.closed
+}
+
+// Train opens the train stream recording all sends, or returns the scripted error.
+func (t *Trainer) Train(ctx context.Context, opts ...grpc.CallOption) (trainerv1.Trainer_TrainClient, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := popError(&t.trainErrs); err != nil {
+		return nil, err
+	}
+
+	stream := &TrainStream{
+		ctx:      ctx,
+		closeErr: popError(&t.closeErrs),
+	}
+	t.streams = append(t.streams, stream)
+	return stream, nil
+}
+
+// Close marks the client closed.
+func (t *Trainer) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.closed = true
+	return nil
+}
+
+// TrainStream is the fake of train stream recording all sends.
+type TrainStream struct {
+	ctx      context.Context
+	closeErr error
+
+	mu       sync.Mutex
+	requests []*trainerv1.TrainRequest
+	closed   bool
+}
+
+// TrainStream implements the train stream.
+var _ trainerv1.Trainer_TrainClient = (*TrainStream)(nil)
+
+// Requests returns the requests sent in order.
+func (s *TrainStream) Requests() []*trainerv1.TrainRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]*trainerv1.TrainRequest(nil), s.requests...)
+}
+
+// Download returns the download dataset sent.
+func (s *TrainStream) Download() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var dataset []byte
+	for _, req := range s.requests {
+		dataset = append(dataset, req.GetTrainMlpRequest().GetDataset()...)
+	}
+
+	return dataset
+}
+
+// NetworkTopology returns the network topology dataset sent.
+func (s *TrainStream) NetworkTopology() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var dataset []byte
+	for _, req := range s.requests {
+		dataset = append(dataset, req.GetTrainGnnRequest().GetDataset()...)
+	}
+
+	return dataset
+}
+
+// Metadata returns the outgoing grpc metadata of stream.
+func (s *TrainStream) Metadata() metadata.MD {
+	md, _ := metadata.FromOutgoingContext(s.ctx)
+	return md
+}
+
+// Closed returns whether the stream is closed by CloseAndRecv or CloseSend.
+func (s *TrainStream) Closed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.closed
+}
+
+// Send records req, it returns ErrStreamClosed if the stream is closed
+// and the error of context if the context of stream is done.
+func (s *TrainStream) Send(req *trainerv1.TrainRequest) error {
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ErrStreamClosed
+	}
+
+	s.requests = append(s.requests, req)
+	return nil
+}
+
+// CloseAndRecv closes the stream and returns the scripted error.
+func (s *TrainStream) CloseAndRecv() (*emptypb.Empty, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	if s.closeErr != nil {
+		return nil, s.closeErr
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// Header returns the empty header metadata.
+func (s *TrainStream) Header() (metadata.MD, error) {
+	return metadata.MD{}, nil
+}
+
+// Trailer returns the empty trailer metadata.
+func (s *TrainStream) Trailer() metadata.MD {
+	return metadata.MD{}
+}
+
+// CloseSend closes the stream.
+func (s *TrainStream) CloseSend() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	return nil
+}
+
+// Context returns the context of stream.
+func (s *TrainStream) Context() context.Context {
+	return s.ctx
+}
+
+// SendMsg records m if it is the train request.
+func (s *TrainStream) SendMsg(m any) error {
+	req, ok := m.(*trainerv1.TrainRequest)
+	if !ok {
+		return errors.New("message is not train request")
+	}
+
+	return s.Send(req)
+}
+
+// RecvMsg returns io.EOF, the train stream receives no message before closing.
+func (s *TrainStream) RecvMsg(m any) error {
+	return io.EOF
+}
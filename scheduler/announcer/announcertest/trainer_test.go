@@ -0,0 +1,116 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package announcertest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+
+	trainerv1 "d7y.io/api/pkg/apis/trainer/v1"
+)
+
+func TestTrainer_Train(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   func(tr *Trainer)
+		expect func(t *testing.T, tr *Trainer, trainErr, closeErr error)
+	}{
+		{
+			name: "train stream records all sends",
+			mock: func(tr *Trainer) {},
+			expect: func(t *testing.T, tr *Trainer, trainErr, closeErr error) {
+				assert := assert.New(t)
+				assert.NoError(trainErr)
+				assert.NoError(closeErr)
+
+				streams := tr.Streams()
+				assert.Len(streams, 1)
+				assert.Len(streams[0].Requests(), 3)
+				assert.Equal("foo\nbar\n", string(streams[0].Download()))
+				assert.Equal("baz\n", string(streams[0].NetworkTopology()))
+				assert.Equal([]string{"bar"}, streams[0].Metadata().Get("foo"))
+				assert.True(streams[0].Closed())
+			},
+		},
+		{
+			name: "train fails",
+			mock: func(tr *Trainer) {
+				tr.FailTrain(1, errors.New("foo"))
+			},
+			expect: func(t *testing.T, tr *Trainer, trainErr, closeErr error) {
+				assert := assert.New(t)
+				assert.EqualError(trainErr, "foo")
+				assert.Empty(tr.Streams())
+			},
+		},
+		{
+			name: "close fails",
+			mock: func(tr *Trainer) {
+				tr.FailClose(1, errors.New("foo"))
+			},
+			expect: func(t *testing.T, tr *Trainer, trainErr, closeErr error) {
+				assert := assert.New(t)
+				assert.NoError(trainErr)
+				assert.EqualError(closeErr, "foo")
+				assert.Equal("foo\nbar\n", string(tr.Streams()[0].Download()))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tr := NewTrainer()
+			tc.mock(tr)
+
+			ctx := metadata.AppendToOutgoingContext(context.Background(), "foo", "bar")
+			stream, err := tr.Train(ctx)
+			if err != nil {
+				tc.expect(t, tr, err, nil)
+				return
+			}
+
+			for _, req := range []*trainerv1.TrainRequest{
+				{Request: &trainerv1.TrainRequest_TrainMlpRequest{TrainMlpRequest: &trainerv1.TrainMLPRequest{Dataset: []byte("foo\n")}}},
+				{Request: &trainerv1.TrainRequest_TrainGnnRequest{TrainGnnRequest: &trainerv1.TrainGNNRequest{Dataset: []byte("baz\n")}}},
+				{Request: &trainerv1.TrainRequest_TrainMlpRequest{TrainMlpRequest: &trainerv1.TrainMLPRequest{Dataset: []byte("bar\n")}}},
+			} {
+				assert.NoError(t, stream.Send(req))
+			}
+
+			_, err = stream.CloseAndRecv()
+			tc.expect(t, tr, nil, err)
+		})
+	}
+}
+
+func TestTrainStream_Send(t *testing.T) {
+	tr := NewTrainer()
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := tr.Train(ctx)
+
+	assert := assert.New(t)
+	assert.NoError(err)
+	assert.NoError(stream.CloseSend())
+	assert.ErrorIs(stream.Send(&trainerv1.TrainRequest{}), ErrStreamClosed)
+
+	cancel()
+	assert.ErrorIs(stream.Send(&trainerv1.TrainRequest{}), context.Canceled)
+}
@@ -0,0 +1,138 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package announcer
+
+import (
+	"sync"
+	"time"
+
+	"d7y.io/dragonfly/v2/scheduler/metrics"
+)
+
+// BreakerState is the state of the training circuit breaker.
+type BreakerState string
+
+const (
+	// BreakerClosed runs every training cycle.
+	BreakerClosed BreakerState = "closed"
+
+	// BreakerOpen skips the training cycles until the cooldown elapses.
+	BreakerOpen BreakerState = "open"
+
+	// BreakerHalfOpen runs a single training cycle to probe the trainer.
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+// metricValue returns the value of state reported by metrics.TrainBreakerStateGauge.
+func (s BreakerState) metricValue() float64 {
+	switch s {
+	case BreakerOpen:
+		return 1
+	case BreakerHalfOpen:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// Status is the status of announcer.
+type Status struct {
+	// BreakerState is the state of the training circuit breaker.
+	BreakerState BreakerState
+
+	// ConsecutiveFailures is the number of consecutive failed training cycles.
+	ConsecutiveFailures int
+}
+
+// circuitBreaker opens after threshold consecutive failures and skips the training cycles
+// for cooldown, then half-opens to probe the trainer with a single training cycle. The
+// circuit breaker with non-positive threshold is always closed.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     BreakerState
+	failures  int
+	openedAt  time.Time
+
+	// now returns the current time, it is replaced in tests.
+	now func() time.Time
+}
+
+// newCircuitBreaker returns a new closed circuitBreaker.
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     BreakerClosed,
+		now:       time.Now,
+	}
+}
+
+// allow returns whether the training cycle runs, the opened circuit breaker half-opens
+// after cooldown.
+func (b *circuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != BreakerOpen {
+		return true
+	}
+
+	if b.now().Sub(b.openedAt) < b.cooldown {
+		return false
+	}
+
+	b.setState(BreakerHalfOpen)
+	return true
+}
+
+// record records the result of training cycle.
+func (b *circuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil {
+		b.failures = 0
+		b.setState(BreakerClosed)
+		return
+	}
+
+	b.failures++
+	if b.threshold <= 0 {
+		return
+	}
+
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.openedAt = b.now()
+		b.setState(BreakerOpen)
+	}
+}
+
+// status returns the state and the number of consecutive failures.
+func (b *circuitBreaker) status() (BreakerState, int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state, b.failures
+}
+
+// setState sets the state and updates the metrics.
+func (b *circuitBreaker) setState(state BreakerState) {
+	b.state = state
+	metrics.TrainBreakerStateGauge.Set(state.metricValue())
+}
@@ -0,0 +1,153 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package announcer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	errTrain := errors.New("foo")
+
+	tests := []struct {
+		name      string
+		threshold int
+		expect    func(t *testing.T, b *circuitBreaker, advance func(time.Duration))
+	}{
+		{
+			name:      "open after consecutive failures",
+			threshold: 3,
+			expect: func(t *testing.T, b *circuitBreaker, advance func(time.Duration)) {
+				assert := assert.New(t)
+				for i := 0; i < 2; i++ {
+					assert.True(b.allow())
+					b.record(errTrain)
+				}
+
+				state, failures := b.status()
+				assert.Equal(BreakerClosed, state)
+				assert.Equal(2, failures)
+
+				assert.True(b.allow())
+				b.record(errTrain)
+				state, failures = b.status()
+				assert.Equal(BreakerOpen, state)
+				assert.Equal(3, failures)
+				assert.False(b.allow())
+
+				advance(time.Minute - time.Second)
+				assert.False(b.allow())
+			},
+		},
+		{
+			name:      "success resets consecutive failures",
+			threshold: 2,
+			expect: func(t *testing.T, b *circuitBreaker, advance func(time.Duration)) {
+				assert := assert.New(t)
+				b.record(errTrain)
+				b.record(nil)
+				b.record(errTrain)
+
+				state, failures := b.status()
+				assert.Equal(BreakerClosed, state)
+				assert.Equal(1, failures)
+				assert.True(b.allow())
+			},
+		},
+		{
+			name:      "half-open after cooldown and close on success",
+			threshold: 1,
+			expect: func(t *testing.T, b *circuitBreaker, advance func(time.Duration)) {
+				assert := assert.New(t)
+				b.record(errTrain)
+				assert.False(b.allow())
+
+				advance(time.Minute)
+				assert.True(b.allow())
+				state, _ := b.status()
+				assert.Equal(BreakerHalfOpen, state)
+
+				b.record(nil)
+				state, failures := b.status()
+				assert.Equal(BreakerClosed, state)
+				assert.Equal(0, failures)
+				assert.True(b.allow())
+			},
+		},
+		{
+			name:      "half-open reopens on failure",
+			threshold: 3,
+			expect: func(t *testing.T, b *circuitBreaker, advance func(time.Duration)) {
+				assert := assert.New(t)
+				for i := 0; i < 3; i++ {
+					b.record(errTrain)
+				}
+
+				advance(time.Minute)
+				assert.True(b.allow())
+				b.record(errTrain)
+				state, failures := b.status()
+				assert.Equal(BreakerOpen, state)
+				assert.Equal(4, failures)
+				assert.False(b.allow())
+
+				advance(time.Minute)
+				assert.True(b.allow())
+				state, _ = b.status()
+				assert.Equal(BreakerHalfOpen, state)
+			},
+		},
+		{
+			name:      "disabled breaker is always closed",
+			threshold: 0,
+			expect: func(t *testing.T, b *circuitBreaker, advance func(time.Duration)) {
+				assert := assert.New(t)
+				for i := 0; i < 10; i++ {
+					assert.True(b.allow())
+					b.record(errTrain)
+				}
+
+				state, failures := b.status()
+				assert.Equal(BreakerClosed, state)
+				assert.Equal(10, failures)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			now := time.Now()
+			b := newCircuitBreaker(tc.threshold, time.Minute)
+			b.now = func() time.Time { return now }
+			tc.expect(t, b, func(d time.Duration) { now = now.Add(d) })
+		})
+	}
+}
+
+func TestAnnouncer_Status(t *testing.T) {
+	assert := assert.New(t)
+	a := &announcer{}
+	assert.Equal(Status{BreakerState: BreakerClosed}, a.Status())
+
+	a.breaker = newCircuitBreaker(1, time.Minute)
+	a.breaker.record(errors.New("foo"))
+	assert.Equal(Status{BreakerState: BreakerOpen, ConsecutiveFailures: 1}, a.Status())
+}
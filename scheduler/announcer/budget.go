@@ -0,0 +1,125 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package announcer
+
+import (
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// cycleBudget is the duration and byte budget of a training cycle shared by the
+// uploads of datasets. The nil cycleBudget is unlimited.
+type cycleBudget struct {
+	// deadline is the deadline of uploading, zero means no deadline.
+	deadline time.Time
+
+	// remaining is the remaining bytes of dataset, it is not limited if limitBytes is false.
+	limitBytes bool
+	remaining  atomic.Int64
+
+	// exceeded is set when the budget is exhausted.
+	exceeded atomic.Bool
+}
+
+// newCycleBudget returns a new cycleBudget, it returns nil if neither timeout nor maxBytes is limited.
+func newCycleBudget(timeout time.Duration, maxBytes int64) *cycleBudget {
+	if timeout <= 0 && maxBytes <= 0 {
+		return nil
+	}
+
+	b := &cycleBudget{}
+	if timeout > 0 {
+		b.deadline = time.Now().Add(timeout)
+	}
+
+	if maxBytes > 0 {
+		b.limitBytes = true
+		b.remaining.Store(maxBytes)
+	}
+
+	return b
+}
+
+// reserve reserves at most n bytes of budget, it returns zero if the budget is exhausted.
+func (b *cycleBudget) reserve(n int) int {
+	if b == nil {
+		return n
+	}
+
+	if !b.deadline.IsZero() && !time.Now().Before(b.deadline) {
+		b.exceeded.Store(true)
+		return 0
+	}
+
+	if !b.limitBytes {
+		return n
+	}
+
+	for {
+		remaining := b.remaining.Load()
+		if remaining <= 0 {
+			b.exceeded.Store(true)
+			return 0
+		}
+
+		reserved := int64(n)
+		if reserved > remaining {
+			reserved = remaining
+		}
+
+		if b.remaining.CompareAndSwap(remaining, remaining-reserved) {
+			return int(reserved)
+		}
+	}
+}
+
+// refund returns the unused reserved bytes to budget.
+func (b *cycleBudget) refund(n int) {
+	if b == nil || !b.limitBytes || n <= 0 {
+		return
+	}
+
+	b.remaining.Add(int64(n))
+}
+
+// isExceeded returns whether the budget is exhausted.
+func (b *cycleBudget) isExceeded() bool {
+	return b != nil && b.exceeded.Load()
+}
+
+// budgetReader reads the dataset within budget, it returns io.EOF when the budget is exhausted.
+type budgetReader struct {
+	reader io.Reader
+	budget *cycleBudget
+}
+
+// Read reads the dataset within budget.
+func (r *budgetReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return r.reader.Read(p)
+	}
+
+	reserved := r.budget.reserve(len(p))
+	if reserved == 0 {
+		return 0, io.EOF
+	}
+
+	n, err := r.reader.Read(p[:reserved])
+	r.budget.refund(reserved - n)
+	return n, err
+}
@@ -0,0 +1,110 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package announcer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// CheckpointFilename is the file name of the upload checkpoint in the storage directory.
+	CheckpointFilename = "announcer-checkpoint.json"
+)
+
+// Checkpoint is the position of the last successful upload to trainer. If the storage locates
+// the records in the history of dataset, the offsets are in the history of the epochs, so that
+// they are not shifted by eviction, rotation and clearing of storage. Otherwise the offsets are
+// in the datasets opened from storage.
+type Checkpoint struct {
+	// DownloadOffset is the number of download bytes uploaded.
+	DownloadOffset int64 `json:"downloadOffset"`
+
+	// NetworkTopologyOffset is the number of network topology bytes uploaded.
+	NetworkTopologyOffset int64 `json:"networkTopologyOffset"`
+
+	// DownloadEpoch is the epoch of the history of downloads of DownloadOffset.
+	DownloadEpoch string `json:"downloadEpoch,omitempty"`
+
+	// NetworkTopologyEpoch is the epoch of the history of network topologies of NetworkTopologyOffset.
+	NetworkTopologyEpoch string `json:"networkTopologyEpoch,omitempty"`
+
+	// UpdatedAt is the time of the last successful upload.
+	UpdatedAt time.Time `json:"updatedAt"`
+
+	// FullSyncAt is the time when the last full sync started uploading from the beginning.
+	FullSyncAt time.Time `json:"fullSyncAt,omitempty"`
+}
+
+// loadCheckpoint reads the checkpoint from file, it returns an empty checkpoint
+// if the file does not exist.
+func loadCheckpoint(filename string) (Checkpoint, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Checkpoint{}, nil
+		}
+
+		return Checkpoint{}, err
+	}
+
+	var checkpoint Checkpoint
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return Checkpoint{}, fmt.Errorf("checkpoint is corrupt: %w", err)
+	}
+
+	if checkpoint.DownloadOffset < 0 || checkpoint.NetworkTopologyOffset < 0 {
+		return Checkpoint{}, errors.New("checkpoint has invalid offset")
+	}
+
+	return checkpoint, nil
+}
+
+// saveCheckpoint writes the checkpoint to file atomically.
+func saveCheckpoint(filename string, checkpoint Checkpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filename)
+}
@@ -0,0 +1,127 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package announcer
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/test/bufconn"
+
+	trainerv1 "d7y.io/api/pkg/apis/trainer/v1"
+
+	trainerclient "d7y.io/dragonfly/v2/pkg/rpc/trainer/client"
+	"d7y.io/dragonfly/v2/scheduler/storage"
+)
+
+func TestZstdCompressor(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "compress dataset",
+			data: []byte(strings.Repeat("foo,bar,baz\n", 1024)),
+		},
+		{
+			name: "compress empty data",
+			data: []byte{},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+			compressor := encoding.GetCompressor(ZstdCompressorName)
+			assert.NotNil(compressor)
+
+			// The encoders and decoders are reused from pool in the second round.
+			for i := 0; i < 2; i++ {
+				compressed, err := compressChunk(compressor, tc.data)
+				assert.NoError(err)
+				if len(tc.data) > 0 {
+					assert.Less(len(compressed), len(tc.data))
+				}
+
+				r, err := compressor.Decompress(bytes.NewReader(compressed))
+				assert.NoError(err)
+				data, err := io.ReadAll(r)
+				assert.NoError(err)
+				assert.Equal(tc.data, data)
+			}
+		})
+	}
+}
+
+func TestGRPCUploadSink_Compressor(t *testing.T) {
+	tests := []struct {
+		name       string
+		compressor string
+	}{
+		{
+			name:       "compress train stream by gzip",
+			compressor: "gzip",
+		},
+		{
+			name:       "compress train stream by zstd",
+			compressor: ZstdCompressorName,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			lis := bufconn.Listen(1024 * 1024)
+			server := grpc.NewServer()
+			fakeServer := &fakeTrainerServer{}
+			trainerv1.RegisterTrainerServer(server, fakeServer)
+			go server.Serve(lis)
+			defer server.Stop()
+
+			client, err := trainerclient.GetV1ByAddr(context.Background(), "bufnet",
+				grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+					return lis.DialContext(ctx)
+				}),
+				grpc.WithTransportCredentials(insecure.NewCredentials()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+
+			s := storage.NewMemory()
+			s.SetDownload([]byte("foo\nbar\n"))
+			s.SetNetworkTopology([]byte("baz\n"))
+			a := newSinkAnnouncer(client, "127.0.0.1:9115", s)
+			a.config.Trainer.Compressor = tc.compressor
+
+			assert := assert.New(t)
+			assert.NoError(a.train())
+
+			fakeServer.mu.Lock()
+			defer fakeServer.mu.Unlock()
+			assert.Equal("foo\nbar\n", fakeServer.download)
+			assert.Equal("baz\n", fakeServer.networkTopology)
+		})
+	}
+}
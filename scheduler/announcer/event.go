@@ -0,0 +1,115 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package announcer
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// EventBufferSize is the buffer size of the channel returned by Subscribe.
+	EventBufferSize = 64
+)
+
+// EventType is the type of announcer lifecycle event.
+type EventType string
+
+const (
+	// EventRegistered is published when scheduler is registered to manager.
+	EventRegistered EventType = "registered"
+
+	// EventCycleStarted is published when a training cycle starts.
+	EventCycleStarted EventType = "cycle-started"
+
+	// EventCycleSucceeded is published when a training cycle succeeds.
+	EventCycleSucceeded EventType = "cycle-succeeded"
+
+	// EventCycleFailed is published when a training cycle fails.
+	EventCycleFailed EventType = "cycle-failed"
+)
+
+// Event is the announcer lifecycle event.
+type Event struct {
+	// Type is the type of event.
+	Type EventType
+
+	// Trainer is the address of trainer used by the training cycle.
+	Trainer string
+
+	// Err is the error of the failed training cycle.
+	Err error
+
+	// CreatedAt is the time of event.
+	CreatedAt time.Time
+}
+
+// eventBus publishes the events to subscribers, the events are dropped
+// for the subscribers whose channel is full.
+type eventBus struct {
+	mu          sync.Mutex
+	subscribers []chan Event
+	closed      bool
+}
+
+// subscribe returns a new channel receiving the events published after subscribing.
+func (b *eventBus) subscribe() <-chan Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan Event, EventBufferSize)
+	if b.closed {
+		close(ch)
+		return ch
+	}
+
+	b.subscribers = append(b.subscribers, ch)
+	return ch
+}
+
+// publish sends the event to subscribers without blocking.
+func (b *eventBus) publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	event.CreatedAt = time.Now()
+	for _, ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// close closes the channels of subscribers.
+func (b *eventBus) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+
+	b.closed = true
+	for _, ch := range b.subscribers {
+		close(ch)
+	}
+}
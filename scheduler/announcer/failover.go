@@ -0,0 +1,113 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package announcer
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// managerFailoverFailures is the number of consecutive keepalive failures
+	// after which keepalive fails over to the next healthy manager endpoint.
+	managerFailoverFailures = 3
+)
+
+// ErrNoHealthyManager is returned when no other manager endpoint is healthy to fail over to.
+var ErrNoHealthyManager = errors.New("no healthy manager endpoint to fail over to")
+
+// ManagerHealthChecker checks the health of manager by address.
+type ManagerHealthChecker func(ctx context.Context, addr string) error
+
+// WithManagerEndpoints enables failing over among the manager endpoints when the active
+// manager is unavailable, e.g. the configured manager address followed by the standby
+// manager addresses. The endpoint is checked by checker and dialed by the manager dialer.
+func WithManagerEndpoints(addrs []string, checker ManagerHealthChecker) Option {
+	return func(a *announcer) {
+		a.managerEndpoints = addrs
+		a.managerHealthChecker = checker
+	}
+}
+
+// managerFailoverEnabled returns whether failing over among manager endpoints is enabled.
+func (a *announcer) managerFailoverEnabled() bool {
+	return len(a.managerEndpoints) > 1 && a.managerHealthChecker != nil && a.managerDialer != nil
+}
+
+// isManagerUnavailable returns whether err indicates the manager is unavailable.
+func isManagerUnavailable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+
+	return false
+}
+
+// activeManagerAddr returns the address of the active manager.
+func (a *announcer) activeManagerAddr() string {
+	a.managerMu.RLock()
+	defer a.managerMu.RUnlock()
+
+	if a.redirectedManagerClient != nil {
+		return a.redirectedManagerAddr
+	}
+
+	return a.config.Manager.Addr
+}
+
+// failoverManager switches the active manager to the next healthy manager endpoint
+// after the active one in order, it returns ErrNoHealthyManager if none is healthy.
+func (a *announcer) failoverManager(ctx context.Context) error {
+	if !a.managerFailoverEnabled() {
+		return ErrNoHealthyManager
+	}
+
+	current := a.activeManagerAddr()
+	start := -1
+	for i, addr := range a.managerEndpoints {
+		if addr == current {
+			start = i
+			break
+		}
+	}
+
+	for i := 1; i <= len(a.managerEndpoints); i++ {
+		addr := a.managerEndpoints[(start+i)%len(a.managerEndpoints)]
+		if addr == current {
+			continue
+		}
+
+		if err := a.managerHealthChecker(ctx, addr); err != nil {
+			a.log.Warnf("manager %s is unhealthy: %s", addr, err.Error())
+			continue
+		}
+
+		if err := a.redirectManager(ctx, addr); err != nil {
+			a.log.Warnf("dial manager %s failed: %s", addr, err.Error())
+			continue
+		}
+
+		a.log.Infof("manager %s is unavailable, fail over to manager %s", current, addr)
+		return nil
+	}
+
+	return ErrNoHealthyManager
+}
@@ -0,0 +1,216 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package announcer
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	managerv2 "d7y.io/api/pkg/apis/manager/v2"
+
+	managerclient "d7y.io/dragonfly/v2/pkg/rpc/manager/client"
+	clientmocks "d7y.io/dragonfly/v2/pkg/rpc/manager/client/mocks"
+	"d7y.io/dragonfly/v2/scheduler/config"
+	storagemocks "d7y.io/dragonfly/v2/scheduler/storage/mocks"
+)
+
+var mockManagerEndpoints = []string{"127.0.0.1:65003", "127.0.0.1:65013", "127.0.0.1:65023"}
+
+func newFailoverTestConfig() *config.Config {
+	return &config.Config{
+		Server: config.ServerConfig{
+			Host:          "localhost",
+			AdvertiseIP:   net.ParseIP("127.0.0.1"),
+			AdvertisePort: 8004,
+			Port:          8080,
+		},
+		Manager: config.ManagerConfig{
+			Addr: mockManagerEndpoints[0],
+			KeepAlive: config.KeepAliveConfig{
+				Interval: 10 * time.Millisecond,
+			},
+		},
+	}
+}
+
+func TestAnnouncer_ManagerFailover(t *testing.T) {
+	tests := []struct {
+		name      string
+		endpoints []string
+		unhealthy map[string]bool
+		mock      func(m, standby *clientmocks.MockV2MockRecorder)
+		expect    func(t *testing.T, a Announcer, dials []string, err error)
+	}{
+		{
+			name:      "fail over to the next healthy manager",
+			endpoints: mockManagerEndpoints,
+			unhealthy: map[string]bool{mockManagerEndpoints[1]: true},
+			mock: func(m, standby *clientmocks.MockV2MockRecorder) {
+				m.UpdateScheduler(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.Unavailable, "foo")).Times(1)
+				standby.UpdateScheduler(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+			},
+			expect: func(t *testing.T, a Announcer, dials []string, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal([]string{mockManagerEndpoints[2]}, dials)
+				assert.Equal(mockManagerEndpoints[2], a.(*announcer).activeManagerAddr())
+			},
+		},
+		{
+			name:      "no healthy manager to fail over to",
+			endpoints: mockManagerEndpoints,
+			unhealthy: map[string]bool{mockManagerEndpoints[1]: true, mockManagerEndpoints[2]: true},
+			mock: func(m, standby *clientmocks.MockV2MockRecorder) {
+				m.UpdateScheduler(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.Unavailable, "foo")).Times(1)
+			},
+			expect: func(t *testing.T, a Announcer, dials []string, err error) {
+				assert := assert.New(t)
+				assert.Equal(codes.Unavailable, status.Code(err))
+				assert.Empty(dials)
+			},
+		},
+		{
+			name:      "each manager is failed over to once",
+			endpoints: mockManagerEndpoints[:2],
+			mock: func(m, standby *clientmocks.MockV2MockRecorder) {
+				m.UpdateScheduler(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.Unavailable, "foo")).Times(1)
+				standby.UpdateScheduler(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.Unavailable, "foo")).Times(1)
+			},
+			expect: func(t *testing.T, a Announcer, dials []string, err error) {
+				assert := assert.New(t)
+				assert.Equal(codes.Unavailable, status.Code(err))
+				assert.Equal([]string{mockManagerEndpoints[1]}, dials)
+			},
+		},
+		{
+			name:      "manager is not failed over when it rejects the request",
+			endpoints: mockManagerEndpoints,
+			mock: func(m, standby *clientmocks.MockV2MockRecorder) {
+				m.UpdateScheduler(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.InvalidArgument, "foo")).Times(1)
+			},
+			expect: func(t *testing.T, a Announcer, dials []string, err error) {
+				assert := assert.New(t)
+				assert.Equal(codes.InvalidArgument, status.Code(err))
+				assert.Empty(dials)
+			},
+		},
+		{
+			name: "failover is disabled without endpoints",
+			mock: func(m, standby *clientmocks.MockV2MockRecorder) {
+				m.UpdateScheduler(gomock.Any(), gomock.Any()).Return(nil, status.Error(codes.Unavailable, "foo")).Times(1)
+			},
+			expect: func(t *testing.T, a Announcer, dials []string, err error) {
+				assert := assert.New(t)
+				assert.Equal(codes.Unavailable, status.Code(err))
+				assert.Empty(dials)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockManagerClient := clientmocks.NewMockV2(ctl)
+			mockStandbyManagerClient := clientmocks.NewMockV2(ctl)
+			mockStorage := storagemocks.NewMockStorage(ctl)
+			tc.mock(mockManagerClient.EXPECT(), mockStandbyManagerClient.EXPECT())
+
+			var dials []string
+			a, err := New(newFailoverTestConfig(), mockManagerClient, mockStorage,
+				WithLogger(zap.NewNop().Sugar()),
+				WithManagerDialer(func(ctx context.Context, addr string) (managerclient.V2, error) {
+					dials = append(dials, addr)
+					return mockStandbyManagerClient, nil
+				}),
+				WithManagerEndpoints(tc.endpoints, func(ctx context.Context, addr string) error {
+					if tc.unhealthy[addr] {
+						return errors.New("foo")
+					}
+
+					return nil
+				}))
+			tc.expect(t, a, dials, err)
+		})
+	}
+}
+
+func TestAnnouncer_KeepAliveWithFailover(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	mockManagerClient := clientmocks.NewMockV2(ctl)
+	mockStandbyManagerClient := clientmocks.NewMockV2(ctl)
+	mockStorage := storagemocks.NewMockStorage(ctl)
+
+	keepAlive := func(results ...error) func(time.Duration, *managerv2.KeepAliveRequest, <-chan struct{}, ...grpc.CallOption) {
+		return func(interval time.Duration, req *managerv2.KeepAliveRequest, done <-chan struct{}, opts ...grpc.CallOption) {
+			for _, opt := range opts {
+				if o, ok := opt.(managerclient.KeepAliveObserverCallOption); ok {
+					for _, result := range results {
+						o.Observer(result)
+					}
+				}
+			}
+
+			<-done
+		}
+	}
+
+	kept := make(chan struct{})
+	gomock.InOrder(
+		mockManagerClient.EXPECT().UpdateScheduler(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1),
+		mockManagerClient.EXPECT().KeepAlive(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
+			keepAlive(nil, errors.New("foo"), errors.New("foo"), errors.New("foo"))).Times(1),
+		mockStandbyManagerClient.EXPECT().KeepAlive(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
+			func(interval time.Duration, req *managerv2.KeepAliveRequest, done <-chan struct{}, opts ...grpc.CallOption) {
+				close(kept)
+				keepAlive(nil)(interval, req, done, opts...)
+			}).Times(1),
+		mockStandbyManagerClient.EXPECT().Close().Return(nil).Times(1),
+	)
+
+	a, err := New(newFailoverTestConfig(), mockManagerClient, mockStorage,
+		WithLogger(zap.NewNop().Sugar()),
+		WithManagerDialer(func(ctx context.Context, addr string) (managerclient.V2, error) {
+			assert.Equal(t, mockManagerEndpoints[1], addr)
+			return mockStandbyManagerClient, nil
+		}),
+		WithManagerEndpoints(mockManagerEndpoints, func(ctx context.Context, addr string) error {
+			return nil
+		}))
+	assert.NoError(t, err)
+	assert.NoError(t, a.(*announcer).announceToManager())
+
+	select {
+	case <-kept:
+	case <-time.After(5 * time.Second):
+		t.Fatal("keepalive is not failed over")
+	}
+
+	assert.Equal(t, mockManagerEndpoints[1], a.(*announcer).activeManagerAddr())
+	assert.NoError(t, a.Stop())
+}
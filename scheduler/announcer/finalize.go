@@ -0,0 +1,172 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package announcer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	trainerv1 "d7y.io/api/pkg/apis/trainer/v1"
+
+	"d7y.io/dragonfly/v2/scheduler/metrics"
+)
+
+// segment is the ranges of datasets uploaded in a train stream, the ranges are read from
+// storage by offsets, so that the segment can be replayed with the same data.
+type segment struct {
+	// uploadDownload and uploadNetworkTopology are whether the datasets are uploaded.
+	uploadDownload        bool
+	uploadNetworkTopology bool
+
+	// The ranges of datasets are [start, end).
+	downloadStart        int64
+	downloadEnd          int64
+	networkTopologyStart int64
+	networkTopologyEnd   int64
+
+	// downloadDone and networkTopologyDone are whether the datasets are uploaded completely.
+	downloadDone        bool
+	networkTopologyDone bool
+
+	// signature is the signature of the datasets in the ranges, nil means the datasets are not signed.
+	signature []byte
+
+	// replay is whether the ranges have been sent before, the replayed bytes are
+	// neither counted in train result nor charged to the cycle budget again.
+	replay bool
+}
+
+// sendSegment sends at most the limit bytes of each dataset of seg from its start offset,
+// the start offsets are reset if the datasets are shorter, and the end offsets are updated.
+func (a *announcer) sendSegment(stream trainerv1.Trainer_TrainClient, addr string, seg *segment, downloadLimit, networkTopologyLimit int64) error {
+	// The datasets are sent concurrently, but the grpc stream does not support concurrent sends.
+	stream = &syncStream{Trainer_TrainClient: stream}
+	eg := errgroup.Group{}
+	if seg.uploadDownload {
+		eg.Go(func() error {
+			start, end, done, err := a.uploadDownloadRange(stream, seg.downloadStart, downloadLimit, seg.replay)
+			if err != nil {
+				return fmt.Errorf("upload download to trainer %s: %w", addr, err)
+			}
+
+			seg.downloadStart, seg.downloadEnd, seg.downloadDone = start, end, done
+			return nil
+		})
+	}
+
+	if seg.uploadNetworkTopology {
+		eg.Go(func() error {
+			start, end, done, err := a.uploadNetworkTopologyRange(stream, seg.networkTopologyStart, networkTopologyLimit, seg.replay)
+			if err != nil {
+				return fmt.Errorf("upload network topology to trainer %s: %w", addr, err)
+			}
+
+			seg.networkTopologyStart, seg.networkTopologyEnd, seg.networkTopologyDone = start, end, done
+			return nil
+		})
+	}
+
+	return eg.Wait()
+}
+
+// syncStream serializes the sends of stream.
+type syncStream struct {
+	trainerv1.Trainer_TrainClient
+	mu sync.Mutex
+}
+
+// Send sends req after the in-flight send returns.
+func (s *syncStream) Send(req *trainerv1.TrainRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.Trainer_TrainClient.Send(req)
+}
+
+// isRetryableFinalizeError returns whether the error of closing train stream is transient,
+// so that the segment can be replayed on a fresh stream.
+func isRetryableFinalizeError(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.Aborted, codes.ResourceExhausted:
+		return true
+	default:
+		return false
+	}
+}
+
+// retryFinalize replays the unconfirmed segment on fresh streams at most the finalize retries
+// times if err is retryable, it returns nil if a replayed stream is confirmed, otherwise it
+// returns the last error. Only the ranges of seg are re-sent, instead of the whole datasets.
+func (a *announcer) retryFinalize(client UploadSink, addr string, seg *segment, err error) error {
+	for retry := 1; retry <= a.config.Trainer.FinalizeRetries && isRetryableFinalizeError(err); retry++ {
+		a.log.Warnf("close train stream of trainer %s failed, replay segment in retry %d: %s", addr, retry, err.Error())
+		metrics.TrainFinalizeRetryCount.WithLabelValues(addr).Inc()
+		if err = a.replaySegment(client, addr, seg); err == nil {
+			return nil
+		}
+	}
+
+	return err
+}
+
+// replaySegment sends the ranges of seg on a fresh stream and closes it.
+func (a *announcer) replaySegment(client UploadSink, addr string, seg *segment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), a.config.Trainer.UploadTimeout)
+	defer cancel()
+
+	if seg.signature != nil {
+		ctx = appendSignatureToOutgoingContext(ctx, seg.signature)
+	}
+
+	stream, err := a.openTrainStream(ctx, cancel, client)
+	if err != nil {
+		return fmt.Errorf("open train stream to trainer %s: %w", addr, err)
+	}
+
+	if err := a.sendRanges(stream, addr, seg); err != nil {
+		return err
+	}
+
+	_, err = stream.CloseAndRecv()
+	return err
+}
+
+// sendRanges sends exactly the ranges of seg. It fails if the datasets are changed so that the
+// same ranges can not be read, e.g. they are rotated. The ranges have been sent before, so they
+// are sent as replay.
+func (a *announcer) sendRanges(stream trainerv1.Trainer_TrainClient, addr string, seg *segment) error {
+	// The empty ranges are skipped, because zero limit means no limit.
+	send := *seg
+	send.replay = true
+	send.uploadDownload = seg.uploadDownload && seg.downloadEnd > seg.downloadStart
+	send.uploadNetworkTopology = seg.uploadNetworkTopology && seg.networkTopologyEnd > seg.networkTopologyStart
+	if err := a.sendSegment(stream, addr, &send, seg.downloadEnd-seg.downloadStart, seg.networkTopologyEnd-seg.networkTopologyStart); err != nil {
+		return err
+	}
+
+	if send.downloadStart != seg.downloadStart || send.downloadEnd != seg.downloadEnd ||
+		send.networkTopologyStart != seg.networkTopologyStart || send.networkTopologyEnd != seg.networkTopologyEnd {
+		return fmt.Errorf("send segment to trainer %s: datasets are changed", addr)
+	}
+
+	return nil
+}
@@ -0,0 +1,146 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package announcer
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	trainerv1 "d7y.io/api/pkg/apis/trainer/v1"
+	trainerv1mocks "d7y.io/api/pkg/apis/trainer/v1/mocks"
+
+	trainerclientmocks "d7y.io/dragonfly/v2/pkg/rpc/trainer/client/mocks"
+	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/metrics"
+	"d7y.io/dragonfly/v2/scheduler/storage"
+)
+
+func TestAnnouncer_FinalizeRetry(t *testing.T) {
+	tests := []struct {
+		name            string
+		finalizeRetries int
+		// closeErrs are the errors of closing the train streams in order.
+		closeErrs []error
+		expect    func(t *testing.T, a *announcer, err error, sent []string, retries float64)
+	}{
+		{
+			name:            "retryable failure is retried with only the tail re-sent",
+			finalizeRetries: 1,
+			closeErrs:       []error{status.Error(codes.Unavailable, "foo"), nil},
+			expect: func(t *testing.T, a *announcer, err error, sent []string, retries float64) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal([]string{"bar\nbaz\n", "bar\nbaz\n"}, sent)
+				assert.Equal(int64(12), a.checkpoint.DownloadOffset)
+				assert.Equal(int64(8), a.trainResult.DownloadBytes)
+				assert.Equal(float64(1), retries)
+			},
+		},
+		{
+			name:            "retries are bounded",
+			finalizeRetries: 2,
+			closeErrs:       []error{status.Error(codes.Unavailable, "foo"), status.Error(codes.Unavailable, "foo"), status.Error(codes.Unavailable, "foo")},
+			expect: func(t *testing.T, a *announcer, err error, sent []string, retries float64) {
+				assert := assert.New(t)
+				assert.ErrorIs(err, ErrTrainUnconfirmed)
+				assert.Equal([]string{"bar\nbaz\n", "bar\nbaz\n", "bar\nbaz\n"}, sent)
+				assert.Equal(int64(4), a.checkpoint.DownloadOffset)
+				assert.Equal(int64(8), a.trainResult.DownloadBytes)
+				assert.Equal(float64(2), retries)
+			},
+		},
+		{
+			name:            "non-retryable failure is not retried",
+			finalizeRetries: 1,
+			closeErrs:       []error{status.Error(codes.InvalidArgument, "foo")},
+			expect: func(t *testing.T, a *announcer, err error, sent []string, retries float64) {
+				assert := assert.New(t)
+				assert.ErrorIs(err, ErrTrainUnconfirmed)
+				assert.Equal([]string{"bar\nbaz\n"}, sent)
+				assert.Equal(int64(4), a.checkpoint.DownloadOffset)
+				assert.Equal(float64(0), retries)
+			},
+		},
+		{
+			name:      "finalize retry is disabled",
+			closeErrs: []error{status.Error(codes.Unavailable, "foo")},
+			expect: func(t *testing.T, a *announcer, err error, sent []string, retries float64) {
+				assert := assert.New(t)
+				assert.ErrorIs(err, ErrTrainUnconfirmed)
+				assert.Equal([]string{"bar\nbaz\n"}, sent)
+				assert.Equal(float64(0), retries)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockTrainerClient := trainerclientmocks.NewMockV1(ctl)
+			s := storage.NewMemory()
+			s.SetDownload([]byte("foo\nbar\nbaz\n"))
+
+			// Each stream sends the dataset in a message.
+			sent := make([]string, len(tc.closeErrs))
+			var calls []*gomock.Call
+			for i, closeErr := range tc.closeErrs {
+				i := i
+				mockStream := trainerv1mocks.NewMockTrainer_TrainClient(ctl)
+				mockStream.EXPECT().Send(gomock.Any()).DoAndReturn(func(req *trainerv1.TrainRequest) error {
+					sent[i] += string(req.GetTrainMlpRequest().Dataset)
+					return nil
+				}).AnyTimes()
+				mockStream.EXPECT().CloseAndRecv().Return(nil, closeErr).Times(1)
+				calls = append(calls, mockTrainerClient.EXPECT().Train(gomock.Any()).Return(mockStream, nil).Times(1))
+			}
+			gomock.InOrder(calls...)
+
+			a := &announcer{
+				config: &config.Config{
+					Server: config.ServerConfig{
+						AdvertiseIP: net.ParseIP("127.0.0.1"),
+					},
+					Trainer: config.TrainerConfig{
+						Addr:            "127.0.0.1:9111",
+						UploadTimeout:   time.Minute,
+						UploadDownload:  true,
+						FinalizePolicy:  config.TrainerFinalizePolicyPessimistic,
+						FinalizeRetries: tc.finalizeRetries,
+					},
+				},
+				checkpoint:    Checkpoint{DownloadOffset: 4},
+				trainerClient: mockTrainerClient,
+				storage:       s,
+				done:          make(chan struct{}),
+				log:           zap.NewNop().Sugar(),
+			}
+
+			retries := testutil.ToFloat64(metrics.TrainFinalizeRetryCount.WithLabelValues("127.0.0.1:9111"))
+			err := a.train()
+			tc.expect(t, a, err, sent, testutil.ToFloat64(metrics.TrainFinalizeRetryCount.WithLabelValues("127.0.0.1:9111"))-retries)
+		})
+	}
+}
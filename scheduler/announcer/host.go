@@ -0,0 +1,91 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package announcer
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/shirou/gopsutil/v3/cpu"
+	"github.com/shirou/gopsutil/v3/disk"
+	"github.com/shirou/gopsutil/v3/mem"
+	"google.golang.org/grpc/metadata"
+
+	"d7y.io/dragonfly/v2/manager/types"
+)
+
+const (
+	// HostCPUCountMetadataKey is the grpc metadata key of the logical cpu count of scheduler host.
+	HostCPUCountMetadataKey = types.SchedulerCPUCountMetadataKey
+
+	// HostMemoryTotalMetadataKey is the grpc metadata key of the total memory in bytes of scheduler host.
+	HostMemoryTotalMetadataKey = types.SchedulerMemoryTotalMetadataKey
+
+	// HostDiskFreeMetadataKey is the grpc metadata key of the free disk in bytes of the storage volume.
+	HostDiskFreeMetadataKey = types.SchedulerDiskFreeMetadataKey
+)
+
+// HostStats is the resource stats of scheduler host.
+type HostStats struct {
+	// CPUCount is the logical cpu count.
+	CPUCount int
+
+	// MemoryTotal is the total memory in bytes.
+	MemoryTotal uint64
+
+	// DiskFree is the free disk in bytes of the storage volume.
+	DiskFree uint64
+}
+
+// HostStatsCollector collects the resource stats of scheduler host.
+type HostStatsCollector func() (HostStats, error)
+
+// NewHostStatsCollector returns a HostStatsCollector, the free disk is collected from the volume of dir.
+func NewHostStatsCollector(dir string) HostStatsCollector {
+	return func() (HostStats, error) {
+		cpuCount, err := cpu.Counts(true)
+		if err != nil {
+			return HostStats{}, err
+		}
+
+		virtualMemory, err := mem.VirtualMemory()
+		if err != nil {
+			return HostStats{}, err
+		}
+
+		usage, err := disk.Usage(dir)
+		if err != nil {
+			return HostStats{}, err
+		}
+
+		return HostStats{
+			CPUCount:    cpuCount,
+			MemoryTotal: virtualMemory.Total,
+			DiskFree:    usage.Free,
+		}, nil
+	}
+}
+
+// appendToOutgoingContext appends the host stats to the grpc metadata of ctx, the manager
+// API has no fields of host resources, so manager persists them from the metadata.
+func (s HostStats) appendToOutgoingContext(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx,
+		HostCPUCountMetadataKey, strconv.Itoa(s.CPUCount),
+		HostMemoryTotalMetadataKey, strconv.FormatUint(s.MemoryTotal, 10),
+		HostDiskFreeMetadataKey, strconv.FormatUint(s.DiskFree, 10),
+	)
+}
@@ -0,0 +1,108 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package announcer
+
+import (
+	"time"
+
+	"d7y.io/dragonfly/v2/scheduler/metrics"
+)
+
+// intervalTuner adapts the interval of training cycles to the growth rate of records. The ideal
+// interval is the time to accumulate targetRecords at the growth rate since the last cycle, and
+// the interval moves towards it by sensitivity in (0, 1] each cycle within [min, max]. The interval
+// contracts when records accumulate quickly and expands to max when no record is written.
+type intervalTuner struct {
+	min           time.Duration
+	max           time.Duration
+	targetRecords int64
+	sensitivity   float64
+	interval      time.Duration
+
+	// records is the number of records observed at observedAt.
+	records    int64
+	observedAt time.Time
+
+	// now returns the current time, it is replaced in tests.
+	now func() time.Time
+}
+
+// newIntervalTuner returns a new intervalTuner starting from interval, the records
+// are counted from records.
+func newIntervalTuner(interval, min, max time.Duration, targetRecords int64, sensitivity float64, records int64) *intervalTuner {
+	t := &intervalTuner{
+		min:           min,
+		max:           max,
+		targetRecords: targetRecords,
+		sensitivity:   sensitivity,
+		records:       records,
+		now:           time.Now,
+	}
+	t.interval = t.clamp(interval)
+	t.observedAt = t.now()
+	return t
+}
+
+// next observes the number of records and returns the interval before the next training cycle.
+// The records decrease only if storage is cleared, then all records are regarded as grown.
+func (t *intervalTuner) next(records int64) time.Duration {
+	now := t.now()
+	growth, elapsed := records-t.records, now.Sub(t.observedAt)
+	if growth < 0 {
+		growth = records
+	}
+	t.records, t.observedAt = records, now
+
+	ideal := t.max
+	if growth > 0 && elapsed > 0 {
+		if d := float64(elapsed) * float64(t.targetRecords) / float64(growth); d < float64(t.max) {
+			ideal = time.Duration(d)
+		}
+	}
+
+	ideal = t.clamp(ideal)
+	t.interval = t.clamp(t.interval + time.Duration(t.sensitivity*float64(ideal-t.interval)))
+	metrics.TrainIntervalGauge.Set(t.interval.Seconds())
+	return t.interval
+}
+
+// clamp returns interval within [min, max].
+func (t *intervalTuner) clamp(interval time.Duration) time.Duration {
+	if interval < t.min {
+		return t.min
+	}
+
+	if interval > t.max {
+		return t.max
+	}
+
+	return interval
+}
+
+// recordCount returns the number of records of the uploaded datasets.
+func (a *announcer) recordCount() int64 {
+	var records int64
+	if a.config.Trainer.UploadDownload {
+		records += a.storage.DownloadCount()
+	}
+
+	if a.config.Trainer.UploadNetworkTopology {
+		records += a.storage.NetworkTopologyCount()
+	}
+
+	return records
+}
@@ -0,0 +1,113 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package announcer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/metrics"
+	"d7y.io/dragonfly/v2/scheduler/storage"
+)
+
+func TestAnnouncer_AdaptiveInterval(t *testing.T) {
+	// step is a training cycle after elapsed, records are the number of download records.
+	type step struct {
+		elapsed  time.Duration
+		records  int
+		interval time.Duration
+	}
+
+	tests := []struct {
+		name        string
+		sensitivity float64
+		steps       []step
+	}{
+		{
+			name:        "interval contracts on bursty growth then expands when idle",
+			sensitivity: 0.5,
+			steps: []step{
+				// 1000 records in 10m targets 1m for 100 records.
+				{elapsed: 10 * time.Minute, records: 1000, interval: 15*time.Minute + 30*time.Second},
+				{elapsed: 10 * time.Minute, records: 2000, interval: 8*time.Minute + 15*time.Second},
+				{elapsed: 10 * time.Minute, records: 3000, interval: 4*time.Minute + 37*time.Second + 500*time.Millisecond},
+				// No record targets the max interval.
+				{elapsed: 10 * time.Minute, records: 3000, interval: 32*time.Minute + 18*time.Second + 750*time.Millisecond},
+				{elapsed: 10 * time.Minute, records: 3000, interval: 46*time.Minute + 9*time.Second + 375*time.Millisecond},
+			},
+		},
+		{
+			name:        "interval is bounded",
+			sensitivity: 1,
+			steps: []step{
+				{elapsed: time.Minute, records: 100000, interval: time.Minute},
+				{elapsed: time.Minute, records: 100000, interval: time.Hour},
+				// 200 records in 1h targets 30m for 100 records.
+				{elapsed: time.Hour, records: 100200, interval: 30 * time.Minute},
+			},
+		},
+		{
+			name:        "cleared records are regarded as grown",
+			sensitivity: 1,
+			steps: []step{
+				{elapsed: 10 * time.Minute, records: 1000, interval: time.Minute},
+				{elapsed: 10 * time.Minute, records: 200, interval: 5 * time.Minute},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := storage.NewMemory()
+			a := &announcer{
+				config: &config.Config{
+					Trainer: config.TrainerConfig{
+						UploadDownload: true,
+					},
+				},
+				storage: s,
+			}
+
+			now := time.Now()
+			tuner := newIntervalTuner(30*time.Minute, time.Minute, time.Hour, 100, tc.sensitivity, a.recordCount())
+			tuner.now = func() time.Time { return now }
+			tuner.observedAt = now
+
+			assert := assert.New(t)
+			for i, step := range tc.steps {
+				now = now.Add(step.elapsed)
+				s.SetDownload([]byte(strings.Repeat("foo\n", step.records)))
+
+				interval := tuner.next(a.recordCount())
+				assert.Equal(step.interval, interval, "step %d", i)
+				assert.Equal(step.interval.Seconds(), testutil.ToFloat64(metrics.TrainIntervalGauge))
+			}
+		})
+	}
+}
+
+func TestNewIntervalTuner(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(time.Hour, newIntervalTuner(7*24*time.Hour, time.Minute, time.Hour, 100, 0.5, 0).interval)
+	assert.Equal(time.Minute, newIntervalTuner(time.Second, time.Minute, time.Hour, 100, 0.5, 0).interval)
+	assert.Equal(10*time.Minute, newIntervalTuner(10*time.Minute, time.Minute, time.Hour, 100, 0.5, 0).interval)
+}
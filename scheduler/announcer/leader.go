@@ -0,0 +1,135 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package announcer
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+
+	managerv2 "d7y.io/api/pkg/apis/manager/v2"
+
+	managerclient "d7y.io/dragonfly/v2/pkg/rpc/manager/client"
+)
+
+const (
+	// ManagerNotLeaderReason is the reason of errdetails.ErrorInfo in the error returned
+	// by the manager which is not the leader.
+	ManagerNotLeaderReason = "NOT_LEADER"
+
+	// ManagerLeaderMetadataKey is the metadata key of errdetails.ErrorInfo holding
+	// the address of the leader manager.
+	ManagerLeaderMetadataKey = "leader"
+
+	// maxManagerRedirects is the max number of redirects to leader manager in an attempt
+	// of updating scheduler, so that the managers hinting each other are not looped forever.
+	maxManagerRedirects = 3
+)
+
+// ManagerDialer dials the manager by address.
+type ManagerDialer func(ctx context.Context, addr string) (managerclient.V2, error)
+
+// managerLeaderHint returns the address of leader manager hinted by the error details of err.
+func managerLeaderHint(err error) (string, bool) {
+	st, ok := status.FromError(err)
+	if !ok {
+		return "", false
+	}
+
+	for _, detail := range st.Details() {
+		info, ok := detail.(*errdetails.ErrorInfo)
+		if !ok || info.GetReason() != ManagerNotLeaderReason {
+			continue
+		}
+
+		if addr := info.GetMetadata()[ManagerLeaderMetadataKey]; addr != "" {
+			return addr, true
+		}
+	}
+
+	return "", false
+}
+
+// updateSchedulerWithRedirect updates scheduler to the active manager, it re-dials the
+// leader manager hinted by the error and updates again if the manager is not the leader.
+// If the manager is unavailable, it fails over to the next healthy manager endpoint and
+// updates again, each endpoint is failed over to at most once.
+func (a *announcer) updateSchedulerWithRedirect(ctx context.Context, req *managerv2.UpdateSchedulerRequest) error {
+	var redirects, failovers int
+	for {
+		_, err := a.activeManager().UpdateScheduler(ctx, req)
+		if err == nil {
+			return nil
+		}
+
+		if isManagerUnavailable(err) && failovers < len(a.managerEndpoints)-1 {
+			if err := a.failoverManager(ctx); err == nil {
+				failovers++
+				continue
+			}
+		}
+
+		addr, ok := managerLeaderHint(err)
+		if !ok || a.managerDialer == nil || redirects >= maxManagerRedirects {
+			return err
+		}
+
+		redirects++
+		a.log.Infof("manager is not the leader, redirect to leader manager %s", addr)
+		if err := a.redirectManager(ctx, addr); err != nil {
+			return fmt.Errorf("redirect to leader manager %s: %w", addr, err)
+		}
+	}
+}
+
+// redirectManager dials the manager of addr and uses it as the active manager,
+// the client of the previous redirected manager is closed.
+func (a *announcer) redirectManager(ctx context.Context, addr string) error {
+	client, err := a.managerDialer(ctx, addr)
+	if err != nil {
+		return err
+	}
+
+	a.managerMu.Lock()
+	previous := a.redirectedManagerClient
+	a.redirectedManagerClient = client
+	a.redirectedManagerAddr = addr
+	a.managerMu.Unlock()
+
+	if previous != nil {
+		if err := previous.Close(); err != nil {
+			a.log.Warnf("close manager client failed: %s", err.Error())
+		}
+	}
+
+	return nil
+}
+
+// activeManager returns the client of the manager used for announcing, the redirected
+// manager takes precedence over the configured manager.
+func (a *announcer) activeManager() managerclient.V2 {
+	a.managerMu.RLock()
+	defer a.managerMu.RUnlock()
+
+	if a.redirectedManagerClient != nil {
+		return a.redirectedManagerClient
+	}
+
+	return a.managerClient
+}
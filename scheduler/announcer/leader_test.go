@@ -0,0 +1,180 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package announcer
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	managerclient "d7y.io/dragonfly/v2/pkg/rpc/manager/client"
+	clientmocks "d7y.io/dragonfly/v2/pkg/rpc/manager/client/mocks"
+	"d7y.io/dragonfly/v2/scheduler/config"
+	storagemocks "d7y.io/dragonfly/v2/scheduler/storage/mocks"
+)
+
+// notLeaderError returns the error of manager which is not the leader, hinting the leader of addr.
+func notLeaderError(t *testing.T, addr string) error {
+	st, err := status.New(codes.FailedPrecondition, "manager is not the leader").WithDetails(&errdetails.ErrorInfo{
+		Reason:   ManagerNotLeaderReason,
+		Metadata: map[string]string{ManagerLeaderMetadataKey: addr},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return st.Err()
+}
+
+func TestManagerLeaderHint(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		expect func(t *testing.T, addr string, ok bool)
+	}{
+		{
+			name: "error hints leader",
+			err:  notLeaderError(t, "127.0.0.1:65004"),
+			expect: func(t *testing.T, addr string, ok bool) {
+				assert := assert.New(t)
+				assert.True(ok)
+				assert.Equal("127.0.0.1:65004", addr)
+			},
+		},
+		{
+			name: "error hints empty leader",
+			err:  notLeaderError(t, ""),
+			expect: func(t *testing.T, addr string, ok bool) {
+				assert.False(t, ok)
+			},
+		},
+		{
+			name: "status error without details",
+			err:  status.Error(codes.Unavailable, "foo"),
+			expect: func(t *testing.T, addr string, ok bool) {
+				assert.False(t, ok)
+			},
+		},
+		{
+			name: "non-status error",
+			err:  errors.New("foo"),
+			expect: func(t *testing.T, addr string, ok bool) {
+				assert.False(t, ok)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			addr, ok := managerLeaderHint(tc.err)
+			tc.expect(t, addr, ok)
+		})
+	}
+}
+
+func TestAnnouncer_ManagerRedirect(t *testing.T) {
+	tests := []struct {
+		name   string
+		dialer bool
+		mock   func(m, leader *clientmocks.MockV2MockRecorder, dials *int)
+		expect func(t *testing.T, a Announcer, leader managerclient.V2, dials int, err error)
+	}{
+		{
+			name:   "redirect to leader once then accepted",
+			dialer: true,
+			mock: func(m, leader *clientmocks.MockV2MockRecorder, dials *int) {
+				m.UpdateScheduler(gomock.Any(), gomock.Any()).Return(nil, notLeaderError(t, "127.0.0.1:65004")).Times(1)
+				leader.UpdateScheduler(gomock.Any(), gomock.Any()).Return(nil, nil).Times(1)
+				leader.Close().Return(nil).Times(1)
+			},
+			expect: func(t *testing.T, a Announcer, leader managerclient.V2, dials int, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(1, dials)
+				assert.Equal(leader, a.(*announcer).activeManager())
+				assert.Equal("127.0.0.1:65004", a.(*announcer).redirectedManagerAddr)
+				assert.NoError(a.Stop())
+			},
+		},
+		{
+			name: "redirect is disabled without dialer",
+			mock: func(m, leader *clientmocks.MockV2MockRecorder, dials *int) {
+				m.UpdateScheduler(gomock.Any(), gomock.Any()).Return(nil, notLeaderError(t, "127.0.0.1:65004")).Times(1)
+			},
+			expect: func(t *testing.T, a Announcer, leader managerclient.V2, dials int, err error) {
+				assert := assert.New(t)
+				assert.Error(err)
+				assert.Equal(codes.FailedPrecondition, status.Code(err))
+				assert.Equal(0, dials)
+			},
+		},
+		{
+			name:   "redirects are bounded",
+			dialer: true,
+			mock: func(m, leader *clientmocks.MockV2MockRecorder, dials *int) {
+				m.UpdateScheduler(gomock.Any(), gomock.Any()).Return(nil, notLeaderError(t, "127.0.0.1:65004")).Times(1)
+				leader.UpdateScheduler(gomock.Any(), gomock.Any()).Return(nil, notLeaderError(t, "127.0.0.1:65004")).Times(maxManagerRedirects)
+				leader.Close().Return(nil).Times(maxManagerRedirects - 1)
+			},
+			expect: func(t *testing.T, a Announcer, leader managerclient.V2, dials int, err error) {
+				assert := assert.New(t)
+				assert.Error(err)
+				assert.Equal(maxManagerRedirects, dials)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockManagerClient := clientmocks.NewMockV2(ctl)
+			mockLeaderManagerClient := clientmocks.NewMockV2(ctl)
+			mockStorage := storagemocks.NewMockStorage(ctl)
+
+			var dials int
+			tc.mock(mockManagerClient.EXPECT(), mockLeaderManagerClient.EXPECT(), &dials)
+
+			options := []Option{WithLogger(zap.NewNop().Sugar())}
+			if tc.dialer {
+				options = append(options, WithManagerDialer(func(ctx context.Context, addr string) (managerclient.V2, error) {
+					assert.Equal(t, "127.0.0.1:65004", addr)
+					dials++
+					return mockLeaderManagerClient, nil
+				}))
+			}
+
+			a, err := New(&config.Config{
+				Server: config.ServerConfig{
+					Host:          "localhost",
+					AdvertiseIP:   net.ParseIP("127.0.0.1"),
+					AdvertisePort: 8004,
+					Port:          8080,
+				},
+			}, mockManagerClient, mockStorage, options...)
+			tc.expect(t, a, mockLeaderManagerClient, dials, err)
+		})
+	}
+}
@@ -5,8 +5,12 @@
 package mocks
 
 import (
+	context "context"
+	net "net"
 	reflect "reflect"
+	time "time"
 
+	announcer "d7y.io/dragonfly/v2/scheduler/announcer"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -33,6 +37,64 @@ func (m *MockAnnouncer) EXPECT() *MockAnnouncerMockRecorder {
 	return m.recorder
 }
 
+// AdvertiseAddr mocks base method.
+func (m *MockAnnouncer) AdvertiseAddr() (net.IP, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdvertiseAddr")
+	ret0, _ := ret[0].(net.IP)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdvertiseAddr indicates an expected call of AdvertiseAddr.
+func (mr *MockAnnouncerMockRecorder) AdvertiseAddr() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdvertiseAddr", reflect.TypeOf((*MockAnnouncer)(nil).AdvertiseAddr))
+}
+
+// EffectiveKeepAliveInterval mocks base method.
+func (m *MockAnnouncer) EffectiveKeepAliveInterval() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EffectiveKeepAliveInterval")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// EffectiveKeepAliveInterval indicates an expected call of EffectiveKeepAliveInterval.
+func (mr *MockAnnouncerMockRecorder) EffectiveKeepAliveInterval() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EffectiveKeepAliveInterval", reflect.TypeOf((*MockAnnouncer)(nil).EffectiveKeepAliveInterval))
+}
+
+// Err mocks base method.
+func (m *MockAnnouncer) Err() <-chan error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Err")
+	ret0, _ := ret[0].(<-chan error)
+	return ret0
+}
+
+// Err indicates an expected call of Err.
+func (mr *MockAnnouncerMockRecorder) Err() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Err", reflect.TypeOf((*MockAnnouncer)(nil).Err))
+}
+
+// ListTrainerEndpoints mocks base method.
+func (m *MockAnnouncer) ListTrainerEndpoints(arg0 context.Context) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTrainerEndpoints", arg0)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTrainerEndpoints indicates an expected call of ListTrainerEndpoints.
+func (mr *MockAnnouncerMockRecorder) ListTrainerEndpoints(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTrainerEndpoints", reflect.TypeOf((*MockAnnouncer)(nil).ListTrainerEndpoints), arg0)
+}
+
 // Serve mocks base method.
 func (m *MockAnnouncer) Serve() error {
 	m.ctrl.T.Helper()
@@ -47,6 +109,20 @@ func (mr *MockAnnouncerMockRecorder) Serve() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Serve", reflect.TypeOf((*MockAnnouncer)(nil).Serve))
 }
 
+// Status mocks base method.
+func (m *MockAnnouncer) Status() announcer.Status {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Status")
+	ret0, _ := ret[0].(announcer.Status)
+	return ret0
+}
+
+// Status indicates an expected call of Status.
+func (mr *MockAnnouncerMockRecorder) Status() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Status", reflect.TypeOf((*MockAnnouncer)(nil).Status))
+}
+
 // Stop mocks base method.
 func (m *MockAnnouncer) Stop() error {
 	m.ctrl.T.Helper()
@@ -60,3 +136,31 @@ func (mr *MockAnnouncerMockRecorder) Stop() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockAnnouncer)(nil).Stop))
 }
+
+// StopContext mocks base method.
+func (m *MockAnnouncer) StopContext(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StopContext", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StopContext indicates an expected call of StopContext.
+func (mr *MockAnnouncerMockRecorder) StopContext(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopContext", reflect.TypeOf((*MockAnnouncer)(nil).StopContext), ctx)
+}
+
+// Subscribe mocks base method.
+func (m *MockAnnouncer) Subscribe() <-chan announcer.Event {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe")
+	ret0, _ := ret[0].(<-chan announcer.Event)
+	return ret0
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockAnnouncerMockRecorder) Subscribe() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockAnnouncer)(nil).Subscribe))
+}
@@ -0,0 +1,158 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package announcer
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+
+	"github.com/gocarina/gocsv"
+
+	"d7y.io/dragonfly/v2/scheduler/storage"
+)
+
+const (
+	// IPRedactionTransformName is the name of ip redaction transform.
+	IPRedactionTransformName = "redact-ips"
+
+	// redactedIPSize is the size in bytes of HMAC kept in the redacted ip.
+	redactedIPSize = 16
+)
+
+// NewIPRedactionTransforms returns the transforms replacing the host ips of download
+// and network topology records with the hex encoded HMAC-SHA256 of ips keyed by salt.
+// The same ip is always replaced with the same value, so records can still be joined
+// by ip, and the other fields of records are untouched.
+func NewIPRedactionTransforms(salt []byte) []UploadTransform {
+	redact := func(ip string) string {
+		if ip == "" {
+			return ip
+		}
+
+		mac := hmac.New(sha256.New, salt)
+		mac.Write([]byte(ip))
+		return hex.EncodeToString(mac.Sum(nil)[:redactedIPSize])
+	}
+
+	return []UploadTransform{
+		{
+			Name:    IPRedactionTransformName,
+			Dataset: DownloadDataset,
+			Transform: func(r io.Reader) io.Reader {
+				return newRecordReader(r, func(line []byte) ([]byte, error) {
+					var downloads []storage.Download
+					if err := gocsv.UnmarshalWithoutHeaders(bytes.NewReader(line), &downloads); err != nil {
+						return nil, err
+					}
+
+					for i := range downloads {
+						downloads[i].Host.IP = redact(downloads[i].Host.IP)
+						for j := range downloads[i].Parents {
+							downloads[i].Parents[j].Host.IP = redact(downloads[i].Parents[j].Host.IP)
+						}
+					}
+
+					var buf bytes.Buffer
+					if err := gocsv.MarshalWithoutHeaders(downloads, &buf); err != nil {
+						return nil, err
+					}
+
+					return buf.Bytes(), nil
+				})
+			},
+		},
+		{
+			Name:    IPRedactionTransformName,
+			Dataset: NetworkTopologyDataset,
+			Transform: func(r io.Reader) io.Reader {
+				return newRecordReader(r, func(line []byte) ([]byte, error) {
+					var networkTopologies []storage.NetworkTopology
+					if err := gocsv.UnmarshalWithoutHeaders(bytes.NewReader(line), &networkTopologies); err != nil {
+						return nil, err
+					}
+
+					for i := range networkTopologies {
+						networkTopologies[i].Host.IP = redact(networkTopologies[i].Host.IP)
+						for j := range networkTopologies[i].DestHosts {
+							networkTopologies[i].DestHosts[j].Host.IP = redact(networkTopologies[i].DestHosts[j].Host.IP)
+						}
+					}
+
+					var buf bytes.Buffer
+					if err := gocsv.MarshalWithoutHeaders(networkTopologies, &buf); err != nil {
+						return nil, err
+					}
+
+					return buf.Bytes(), nil
+				})
+			},
+		},
+	}
+}
+
+// recordReader decodes the records of csv format line by line and reads the
+// records rewritten by the rewrite function.
+type recordReader struct {
+	reader  *bufio.Reader
+	rewrite func([]byte) ([]byte, error)
+	buf     []byte
+	err     error
+}
+
+// newRecordReader returns a new recordReader instance.
+func newRecordReader(r io.Reader, rewrite func([]byte) ([]byte, error)) *recordReader {
+	return &recordReader{reader: bufio.NewReader(r), rewrite: rewrite}
+}
+
+// Read reads the rewritten records.
+func (r *recordReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		line, err := r.reader.ReadBytes('\n')
+		if err != nil {
+			if err == io.EOF && len(line) > 0 {
+				// The record is not terminated, it may be truncated by the segment
+				// and can not be rewritten.
+				err = errors.New("incomplete record")
+			}
+
+			r.err = err
+			continue
+		}
+
+		if len(bytes.TrimSpace(line)) == 0 {
+			r.buf = line
+			continue
+		}
+
+		if r.buf, err = r.rewrite(line); err != nil {
+			r.err = err
+		}
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
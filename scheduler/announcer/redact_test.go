@@ -0,0 +1,164 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package announcer
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/gocarina/gocsv"
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/scheduler/storage"
+)
+
+func TestNewIPRedactionTransforms(t *testing.T) {
+	downloads := []storage.Download{
+		{
+			ID:   "1",
+			Host: storage.Host{ID: "foo", Hostname: "foo", IP: "127.0.0.1", Port: 8002},
+			Parents: []storage.Parent{
+				{ID: "2", Host: storage.Host{ID: "bar", Hostname: "bar", IP: "127.0.0.2", Port: 8002}},
+				{},
+			},
+		},
+		{
+			ID:   "3",
+			Host: storage.Host{ID: "bar", Hostname: "bar", IP: "127.0.0.2", Port: 8002},
+		},
+	}
+
+	networkTopologies := []storage.NetworkTopology{
+		{
+			ID:   "4",
+			Host: storage.Host{ID: "foo", Hostname: "foo", IP: "127.0.0.1", Port: 8002},
+			DestHosts: []storage.DestHost{
+				{Host: storage.Host{ID: "bar", Hostname: "bar", IP: "127.0.0.2", Port: 8002}, Probes: storage.Probes{AverageRTT: 10}},
+				{},
+			},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		salt   []byte
+		expect func(t *testing.T, transforms []UploadTransform)
+	}{
+		{
+			name: "redact ips of download",
+			salt: []byte("foo"),
+			expect: func(t *testing.T, transforms []UploadTransform) {
+				assert := assert.New(t)
+				var buf bytes.Buffer
+				assert.NoError(gocsv.MarshalWithoutHeaders(downloads, &buf))
+				original := append([]byte(nil), buf.Bytes()...)
+
+				data, err := io.ReadAll(applyUploadTransforms(&buf, transforms, DownloadDataset))
+				assert.NoError(err)
+
+				var redacted []storage.Download
+				assert.NoError(gocsv.UnmarshalWithoutHeaders(bytes.NewReader(data), &redacted))
+				assert.Len(redacted, 2)
+
+				ip := redacted[0].Host.IP
+				parentIP := redacted[0].Parents[0].Host.IP
+				assert.NotEqual("127.0.0.1", ip)
+				assert.NotEqual("127.0.0.2", parentIP)
+				assert.NotEqual(ip, parentIP)
+				assert.Equal(parentIP, redacted[1].Host.IP)
+				assert.Equal("", redacted[0].Parents[1].Host.IP)
+
+				// Non-ip fields are untouched.
+				redacted[0].Host.IP = downloads[0].Host.IP
+				redacted[0].Parents[0].Host.IP = downloads[0].Parents[0].Host.IP
+				redacted[1].Host.IP = downloads[1].Host.IP
+				var expected []storage.Download
+				assert.NoError(gocsv.UnmarshalWithoutHeaders(bytes.NewReader(original), &expected))
+				assert.Equal(expected, redacted)
+			},
+		},
+		{
+			name: "redact ips of network topology consistently with download",
+			salt: []byte("foo"),
+			expect: func(t *testing.T, transforms []UploadTransform) {
+				assert := assert.New(t)
+				var downloadBuf, networkTopologyBuf bytes.Buffer
+				assert.NoError(gocsv.MarshalWithoutHeaders(downloads, &downloadBuf))
+				assert.NoError(gocsv.MarshalWithoutHeaders(networkTopologies, &networkTopologyBuf))
+				original := append([]byte(nil), networkTopologyBuf.Bytes()...)
+
+				downloadData, err := io.ReadAll(applyUploadTransforms(&downloadBuf, transforms, DownloadDataset))
+				assert.NoError(err)
+				networkTopologyData, err := io.ReadAll(applyUploadTransforms(&networkTopologyBuf, transforms, NetworkTopologyDataset))
+				assert.NoError(err)
+
+				var redactedDownloads []storage.Download
+				assert.NoError(gocsv.UnmarshalWithoutHeaders(bytes.NewReader(downloadData), &redactedDownloads))
+				var redacted []storage.NetworkTopology
+				assert.NoError(gocsv.UnmarshalWithoutHeaders(bytes.NewReader(networkTopologyData), &redacted))
+				assert.Len(redacted, 1)
+				assert.Equal(redactedDownloads[0].Host.IP, redacted[0].Host.IP)
+				assert.Equal(redactedDownloads[1].Host.IP, redacted[0].DestHosts[0].Host.IP)
+				assert.Equal("", redacted[0].DestHosts[1].Host.IP)
+
+				// Non-ip fields are untouched.
+				redacted[0].Host.IP = networkTopologies[0].Host.IP
+				redacted[0].DestHosts[0].Host.IP = networkTopologies[0].DestHosts[0].Host.IP
+				var expected []storage.NetworkTopology
+				assert.NoError(gocsv.UnmarshalWithoutHeaders(bytes.NewReader(original), &expected))
+				assert.Equal(expected, redacted)
+			},
+		},
+		{
+			name: "redact ips with different salts",
+			salt: []byte("foo"),
+			expect: func(t *testing.T, transforms []UploadTransform) {
+				assert := assert.New(t)
+				var buf bytes.Buffer
+				assert.NoError(gocsv.MarshalWithoutHeaders(downloads, &buf))
+				data := buf.Bytes()
+
+				foo, err := io.ReadAll(applyUploadTransforms(bytes.NewReader(data), transforms, DownloadDataset))
+				assert.NoError(err)
+				bar, err := io.ReadAll(applyUploadTransforms(bytes.NewReader(data), NewIPRedactionTransforms([]byte("bar")), DownloadDataset))
+				assert.NoError(err)
+				assert.NotEqual(foo, bar)
+			},
+		},
+		{
+			name: "redact incomplete record",
+			salt: []byte("foo"),
+			expect: func(t *testing.T, transforms []UploadTransform) {
+				assert := assert.New(t)
+				var buf bytes.Buffer
+				assert.NoError(gocsv.MarshalWithoutHeaders(downloads, &buf))
+
+				_, err := io.ReadAll(applyUploadTransforms(io.LimitReader(&buf, int64(buf.Len()-1)), transforms, DownloadDataset))
+				var transformErr *UploadTransformError
+				assert.ErrorAs(err, &transformErr)
+				assert.Equal(IPRedactionTransformName, transformErr.Name)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expect(t, NewIPRedactionTransforms(tc.salt))
+		})
+	}
+}
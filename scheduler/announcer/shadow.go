@@ -0,0 +1,132 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package announcer
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	"google.golang.org/protobuf/proto"
+
+	trainerv1 "d7y.io/api/pkg/apis/trainer/v1"
+)
+
+const (
+	// shadowQueueSize is the number of requests queued for the shadow trainer,
+	// the shadow stream is dropped if the queue is full.
+	shadowQueueSize = 8
+)
+
+// ErrShadowTrainerBehind is returned when the shadow trainer does not keep up with
+// the primary trainer, and the shadow stream is dropped.
+var ErrShadowTrainerBehind = errors.New("shadow trainer is behind primary trainer")
+
+// shadowStream sends the requests of primary train stream to the shadow trainer in
+// background, so that the shadow trainer never blocks or fails the primary stream.
+type shadowStream struct {
+	stream trainerv1.Trainer_TrainClient
+	cancel context.CancelFunc
+
+	// requests are sent by the sender goroutine, which closes done after requests is closed.
+	requests chan *trainerv1.TrainRequest
+	done     chan struct{}
+
+	// err is the error of sending, it is read after done is closed. dropped is set if
+	// the queue is full, the requests are no longer sent after it is set.
+	err     error
+	dropped atomic.Bool
+}
+
+// newShadowStream returns a new shadowStream of stream, cancel cancels stream.
+func newShadowStream(stream trainerv1.Trainer_TrainClient, cancel context.CancelFunc) *shadowStream {
+	s := &shadowStream{
+		stream:   stream,
+		cancel:   cancel,
+		requests: make(chan *trainerv1.TrainRequest, shadowQueueSize),
+		done:     make(chan struct{}),
+	}
+
+	go func() {
+		defer close(s.done)
+		for req := range s.requests {
+			if s.err != nil || s.dropped.Load() {
+				continue
+			}
+
+			s.err = s.stream.Send(req)
+		}
+	}()
+
+	return s
+}
+
+// send queues the copy of req, the data of req may be reused after the primary stream sends it.
+func (s *shadowStream) send(req *trainerv1.TrainRequest) {
+	if s.dropped.Load() {
+		return
+	}
+
+	select {
+	case s.requests <- proto.Clone(req).(*trainerv1.TrainRequest):
+	default:
+		s.dropped.Store(true)
+	}
+}
+
+// close waits for the queued requests to be sent, and closes the shadow stream
+// to confirm the data sent, it must not be called with abort.
+func (s *shadowStream) close() error {
+	close(s.requests)
+	<-s.done
+	defer s.cancel()
+
+	if s.dropped.Load() {
+		return ErrShadowTrainerBehind
+	}
+
+	if s.err != nil {
+		return s.err
+	}
+
+	_, err := s.stream.CloseAndRecv()
+	return err
+}
+
+// abort cancels the shadow stream without confirming the data sent, it is called
+// when the primary stream fails, so that the data is sent again with the primary.
+func (s *shadowStream) abort() {
+	s.cancel()
+	close(s.requests)
+	<-s.done
+}
+
+// teeStream is the primary train stream which also sends the requests to the shadow trainer.
+type teeStream struct {
+	trainerv1.Trainer_TrainClient
+	shadow *shadowStream
+}
+
+// Send sends req to the primary trainer, then queues it for the shadow trainer.
+func (s *teeStream) Send(req *trainerv1.TrainRequest) error {
+	if err := s.Trainer_TrainClient.Send(req); err != nil {
+		return err
+	}
+
+	s.shadow.send(req)
+	return nil
+}
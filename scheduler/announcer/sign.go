@@ -0,0 +1,155 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package announcer
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+
+	"google.golang.org/grpc/metadata"
+
+	"d7y.io/dragonfly/v2/scheduler/storage"
+)
+
+const (
+	// TrainSignatureMetadataKey is the grpc metadata key of the base64 encoded Ed25519 signature
+	// of the datasets sent in the train stream.
+	TrainSignatureMetadataKey = "d7y-scheduler-train-signature"
+)
+
+// ErrSigningKeyRequired is returned when dataset signing is enabled without signing key.
+var ErrSigningKeyRequired = errors.New("signing key is required by dataset signing")
+
+// DatasetDigest returns the message signed for the datasets sent in a train stream, it is the
+// sha256 of download followed by the sha256 of network topology. The datasets are hashed
+// separately, because their messages are interleaved in the stream.
+func DatasetDigest(download, networkTopology io.Reader) ([]byte, error) {
+	downloadHash, networkTopologyHash := sha256.New(), sha256.New()
+	if _, err := io.Copy(downloadHash, download); err != nil {
+		return nil, err
+	}
+
+	if _, err := io.Copy(networkTopologyHash, networkTopology); err != nil {
+		return nil, err
+	}
+
+	return datasetDigest(downloadHash, networkTopologyHash), nil
+}
+
+// VerifyDatasetSignature verifies the signature of the datasets received in a train stream
+// with the public key of scheduler.
+func VerifyDatasetSignature(publicKey ed25519.PublicKey, download, networkTopology io.Reader, signature []byte) (bool, error) {
+	digest, err := DatasetDigest(download, networkTopology)
+	if err != nil {
+		return false, err
+	}
+
+	return ed25519.Verify(publicKey, digest, signature), nil
+}
+
+// datasetDigest returns the message signed for the hashes of datasets.
+func datasetDigest(download, networkTopology hash.Hash) []byte {
+	return networkTopology.Sum(download.Sum(nil))
+}
+
+// loadSigningKey loads the Ed25519 private key in PKCS #8 PEM format from filename.
+func loadSigningKey(filename string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no pem block in signing key %s", filename)
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("signing key %s is %T, not ed25519 private key", filename, key)
+	}
+
+	return privateKey, nil
+}
+
+// signSegment reads the ranges of seg as they are sent, and signs the datasets read with
+// the signing key. The ranges are fixed by signing, so that the datasets sent are the
+// datasets signed even if they are appended in the meantime.
+func (a *announcer) signSegment(seg *segment, limit int64) error {
+	downloadHash, networkTopologyHash := sha256.New(), sha256.New()
+	if seg.uploadDownload {
+		start, end, done, err := a.hashRange(downloadHash, a.openDownload, DownloadDataset, seg.downloadStart, limit)
+		if err != nil {
+			return fmt.Errorf("hash download: %w", err)
+		}
+
+		seg.downloadStart, seg.downloadEnd, seg.downloadDone = start, end, done
+	}
+
+	if seg.uploadNetworkTopology {
+		start, end, done, err := a.hashRange(networkTopologyHash, a.openNetworkTopology, NetworkTopologyDataset, seg.networkTopologyStart, limit)
+		if err != nil {
+			return fmt.Errorf("hash network topology: %w", err)
+		}
+
+		seg.networkTopologyStart, seg.networkTopologyEnd, seg.networkTopologyDone = start, end, done
+	}
+
+	seg.signature = ed25519.Sign(a.signingKey, datasetDigest(downloadHash, networkTopologyHash))
+	return nil
+}
+
+// hashRange writes at most limit bytes of dataset from offset into h after the upload transforms,
+// limit less than or equal to zero means no limit. It returns the range read and whether the
+// dataset has been read completely.
+func (a *announcer) hashRange(h hash.Hash, open func() (io.ReadCloser, storage.Origin, error), dataset string, offset, limit int64) (int64, int64, bool, error) {
+	readCloser, offset, err := a.openWithOffset(open, offset)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	defer readCloser.Close()
+
+	source := &sourceReader{reader: readCloser}
+	if limit > 0 {
+		source.reader = io.LimitReader(readCloser, limit)
+	}
+
+	if _, err := io.Copy(h, applyUploadTransforms(source, a.uploadTransforms, dataset)); err != nil {
+		return 0, 0, false, unwrapSourceError(err)
+	}
+
+	return offset, offset + source.n, limit <= 0 || source.n < limit, nil
+}
+
+// appendSignatureToOutgoingContext appends the signature of datasets to the grpc metadata of ctx.
+func appendSignatureToOutgoingContext(ctx context.Context, signature []byte) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, TrainSignatureMetadataKey, base64.StdEncoding.EncodeToString(signature))
+}
@@ -0,0 +1,244 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package announcer
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	trainerv1 "d7y.io/api/pkg/apis/trainer/v1"
+	trainerv1mocks "d7y.io/api/pkg/apis/trainer/v1/mocks"
+
+	clientmocks "d7y.io/dragonfly/v2/pkg/rpc/manager/client/mocks"
+	trainerclientmocks "d7y.io/dragonfly/v2/pkg/rpc/trainer/client/mocks"
+	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/storage"
+	storagemocks "d7y.io/dragonfly/v2/scheduler/storage/mocks"
+)
+
+func TestAnnouncer_SignDatasets(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name              string
+		uploadSegmentSize int64
+		// expect are the datasets sent in the train streams in order.
+		expect []signedStream
+	}{
+		{
+			name: "datasets are signed",
+			expect: []signedStream{
+				{download: "foo\nbar\n", networkTopology: "baz\n"},
+			},
+		},
+		{
+			name:              "each segment of datasets is signed",
+			uploadSegmentSize: 4,
+			expect: []signedStream{
+				{download: "foo\n", networkTopology: "baz\n"},
+				{download: "bar\n"},
+				{},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockTrainerClient := trainerclientmocks.NewMockV1(ctl)
+			s := storage.NewMemory()
+			s.SetDownload([]byte("foo\nbar\n"))
+			s.SetNetworkTopology([]byte("baz\n"))
+
+			var (
+				mu      sync.Mutex
+				streams []*signedStream
+			)
+			mockTrainerClient.EXPECT().Train(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(ctx context.Context, opts ...grpc.CallOption) (trainerv1.Trainer_TrainClient, error) {
+					stream := &signedStream{}
+					md, _ := metadata.FromOutgoingContext(ctx)
+					if values := md.Get(TrainSignatureMetadataKey); len(values) == 1 {
+						stream.signature, _ = base64.StdEncoding.DecodeString(values[0])
+					}
+					streams = append(streams, stream)
+
+					mockStream := trainerv1mocks.NewMockTrainer_TrainClient(ctl)
+					mockStream.EXPECT().Send(gomock.Any()).DoAndReturn(func(req *trainerv1.TrainRequest) error {
+						mu.Lock()
+						defer mu.Unlock()
+						switch r := req.Request.(type) {
+						case *trainerv1.TrainRequest_TrainMlpRequest:
+							stream.download += string(r.TrainMlpRequest.Dataset)
+						case *trainerv1.TrainRequest_TrainGnnRequest:
+							stream.networkTopology += string(r.TrainGnnRequest.Dataset)
+						}
+
+						return nil
+					}).AnyTimes()
+					mockStream.EXPECT().CloseAndRecv().Return(nil, nil).Times(1)
+					return mockStream, nil
+				}).Times(len(tc.expect))
+
+			a := &announcer{
+				config: &config.Config{
+					Server: config.ServerConfig{
+						AdvertiseIP: net.ParseIP("127.0.0.1"),
+					},
+					Trainer: config.TrainerConfig{
+						Addr:                  "127.0.0.1:9112",
+						UploadTimeout:         time.Minute,
+						UploadDownload:        true,
+						UploadNetworkTopology: true,
+						UploadSegmentSize:     tc.uploadSegmentSize,
+					},
+				},
+				trainerClient: mockTrainerClient,
+				storage:       s,
+				signingKey:    privateKey,
+				done:          make(chan struct{}),
+				log:           zap.NewNop().Sugar(),
+			}
+
+			assert := assert.New(t)
+			assert.NoError(a.train())
+			assert.Len(streams, len(tc.expect))
+			for i, stream := range streams {
+				assert.Equal(tc.expect[i].download, stream.download)
+				assert.Equal(tc.expect[i].networkTopology, stream.networkTopology)
+
+				ok, err := VerifyDatasetSignature(publicKey, strings.NewReader(stream.download), strings.NewReader(stream.networkTopology), stream.signature)
+				assert.NoError(err)
+				assert.True(ok)
+
+				ok, err = VerifyDatasetSignature(publicKey, strings.NewReader(stream.download+"foo\n"), strings.NewReader(stream.networkTopology), stream.signature)
+				assert.NoError(err)
+				assert.False(ok)
+			}
+		})
+	}
+}
+
+// signedStream is the datasets and signature received in a train stream.
+type signedStream struct {
+	download        string
+	networkTopology string
+	signature       []byte
+}
+
+func TestAnnouncer_SigningKey(t *testing.T) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signingKeyFile := filepath.Join(t.TempDir(), "signing.key")
+	if err := os.WriteFile(signingKeyFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	invalidKeyFile := filepath.Join(t.TempDir(), "invalid.key")
+	if err := os.WriteFile(invalidKeyFile, []byte("foo"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		signingKeyFile string
+		expect         func(t *testing.T, a Announcer, err error)
+	}{
+		{
+			name:           "signing key is loaded",
+			signingKeyFile: signingKeyFile,
+			expect: func(t *testing.T, a Announcer, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.True(privateKey.Equal(a.(*announcer).signingKey))
+			},
+		},
+		{
+			name: "signing key is missing",
+			expect: func(t *testing.T, a Announcer, err error) {
+				assert := assert.New(t)
+				assert.ErrorIs(err, ErrSigningKeyRequired)
+			},
+		},
+		{
+			name:           "signing key is invalid",
+			signingKeyFile: invalidKeyFile,
+			expect: func(t *testing.T, a Announcer, err error) {
+				assert := assert.New(t)
+				assert.ErrorContains(err, "load signing key")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			mockManagerClient := clientmocks.NewMockV2(ctl)
+			mockStorage := storagemocks.NewMockStorage(ctl)
+			mockManagerClient.EXPECT().UpdateScheduler(gomock.Any(), gomock.Any()).Return(nil, nil).AnyTimes()
+
+			a, err := New(&config.Config{
+				Server: config.ServerConfig{
+					Host:          "localhost",
+					AdvertiseIP:   net.ParseIP("127.0.0.1"),
+					AdvertisePort: 8004,
+					Port:          8080,
+				},
+				Manager: config.ManagerConfig{
+					SchedulerClusterID: 1,
+				},
+				Trainer: config.TrainerConfig{
+					Enable:         true,
+					Addr:           "127.0.0.1:9090",
+					SignDatasets:   true,
+					SigningKeyFile: tc.signingKeyFile,
+				},
+			}, mockManagerClient, mockStorage)
+			tc.expect(t, a, err)
+		})
+	}
+}
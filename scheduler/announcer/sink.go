@@ -0,0 +1,464 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package announcer
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	trainerv1 "d7y.io/api/pkg/apis/trainer/v1"
+
+	trainerclient "d7y.io/dragonfly/v2/pkg/rpc/trainer/client"
+)
+
+// UploadSink is the sink of datasets uploaded to trainer. Each train stream opened by Train uploads
+// a segment of datasets, the segment is confirmed by trainer only if CloseAndRecv of the stream
+// succeeds. The errors are grpc status errors, so that the announcer handles them regardless of sink.
+type UploadSink interface {
+	// Train opens the stream uploading a segment of datasets.
+	Train(context.Context, ...grpc.CallOption) (trainerv1.Trainer_TrainClient, error)
+
+	// Close closes the sink.
+	Close() error
+}
+
+// The grpc client of trainer uploads datasets by the Train stream.
+var _ UploadSink = trainerclient.V1(nil)
+
+const (
+	// DefaultHTTPUploadChunkRetries is the default max number of retries of uploading a chunk to http upload sink.
+	DefaultHTTPUploadChunkRetries = 3
+
+	// DefaultHTTPUploadChunkRetryBackoff is the default backoff between the retries of uploading a chunk.
+	DefaultHTTPUploadChunkRetryBackoff = 500 * time.Millisecond
+)
+
+const (
+	// HTTPUploadHostnameHeader is the http header of the hostname of scheduler.
+	HTTPUploadHostnameHeader = "X-Dragonfly-Hostname"
+
+	// HTTPUploadIPHeader is the http header of the ip of scheduler.
+	HTTPUploadIPHeader = "X-Dragonfly-IP"
+
+	// HTTPUploadClusterIDHeader is the http header of the cluster id of datasets.
+	HTTPUploadClusterIDHeader = "X-Dragonfly-Cluster-ID"
+)
+
+const (
+	// HTTPUploadDownloadDataset is the path of download dataset in the urls of http upload sink.
+	HTTPUploadDownloadDataset = "download"
+
+	// HTTPUploadNetworkTopologyDataset is the path of network topology dataset in the urls of http upload sink.
+	HTTPUploadNetworkTopologyDataset = "network-topology"
+)
+
+// ErrUploadStreamClosed is returned by sending to the closed upload stream.
+var ErrUploadStreamClosed = errors.New("upload stream is closed")
+
+// HTTPUploadStatus is the response of querying the upload of a dataset, it is used to resume
+// the upload after a chunk is not acknowledged.
+type HTTPUploadStatus struct {
+	// Acked is the number of consecutive chunks received from the first chunk.
+	Acked uint64 `json:"acked"`
+}
+
+// HTTPUploadCompletion is the request of completing the upload, the trainer confirms
+// the upload only if it has received all chunks.
+type HTTPUploadCompletion struct {
+	// Download is the number of chunks of download dataset.
+	Download uint64 `json:"download"`
+
+	// NetworkTopology is the number of chunks of network topology dataset.
+	NetworkTopology uint64 `json:"networkTopology"`
+}
+
+// HTTPUploadSink uploads datasets to the http upload endpoint of trainer. HTTP/2 is negotiated
+// by the default transport for the https endpoint. Each train stream is an upload identified
+// by a random id, and its datasets are uploaded in chunks:
+//
+//   - PUT {endpoint}/uploads/{id}/{dataset}/{seq} uploads the chunk seq of dataset starting from 0,
+//     the 2xx response acknowledges the chunk.
+//   - GET {endpoint}/uploads/{id}/{dataset} returns HTTPUploadStatus, it is queried to resume the
+//     upload if a chunk is not acknowledged, the chunk is re-sent only if it is not received.
+//   - POST {endpoint}/uploads/{id}/complete with HTTPUploadCompletion confirms the upload.
+//
+// The outgoing grpc metadata of stream is sent as http headers of each request. If the stream is
+// opened with the grpc compressor call option, the chunks are compressed by the compressor and
+// sent with the Content-Encoding header of the compressor name, e.g. gzip or zstd.
+type HTTPUploadSink struct {
+	endpoint          *url.URL
+	client            *http.Client
+	chunkRetries      int
+	chunkRetryBackoff time.Duration
+}
+
+// HTTPUploadSinkOption is a functional option for configuring the HTTPUploadSink.
+type HTTPUploadSinkOption func(s *HTTPUploadSink)
+
+// WithHTTPUploadClient sets the http client of http upload sink.
+func WithHTTPUploadClient(client *http.Client) HTTPUploadSinkOption {
+	return func(s *HTTPUploadSink) {
+		s.client = client
+	}
+}
+
+// WithHTTPUploadChunkRetries sets the max number of retries of uploading a chunk,
+// zero disables retrying.
+func WithHTTPUploadChunkRetries(retries int) HTTPUploadSinkOption {
+	return func(s *HTTPUploadSink) {
+		s.chunkRetries = retries
+	}
+}
+
+// WithHTTPUploadChunkRetryBackoff sets the backoff between the retries of uploading a chunk.
+func WithHTTPUploadChunkRetryBackoff(backoff time.Duration) HTTPUploadSinkOption {
+	return func(s *HTTPUploadSink) {
+		s.chunkRetryBackoff = backoff
+	}
+}
+
+// NewHTTPUploadSink returns the http upload sink of endpoint, e.g. https://trainer:9443/api/v1.
+func NewHTTPUploadSink(endpoint string, options ...HTTPUploadSinkOption) (*HTTPUploadSink, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid scheme of http upload endpoint %s", endpoint)
+	}
+
+	s := &HTTPUploadSink{
+		endpoint:          u,
+		client:            &http.Client{Transport: http.DefaultTransport.(*http.Transport).Clone()},
+		chunkRetries:      DefaultHTTPUploadChunkRetries,
+		chunkRetryBackoff: DefaultHTTPUploadChunkRetryBackoff,
+	}
+
+	for _, opt := range options {
+		opt(s)
+	}
+
+	return s, nil
+}
+
+// Train opens the upload of a segment of datasets, no request is sent until the first chunk.
+// The grpc call options other than the compressor are ignored.
+func (s *HTTPUploadSink) Train(ctx context.Context, opts ...grpc.CallOption) (trainerv1.Trainer_TrainClient, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, status.FromContextError(err).Err()
+	}
+
+	var compressor encoding.Compressor
+	for _, opt := range opts {
+		if o, ok := opt.(grpc.CompressorCallOption); ok {
+			if compressor = encoding.GetCompressor(o.CompressorType); compressor == nil {
+				return nil, status.Errorf(codes.Internal, "compressor %s is not registered", o.CompressorType)
+			}
+		}
+	}
+
+	header := http.Header{}
+	md, _ := metadata.FromOutgoingContext(ctx)
+	for key, values := range md {
+		for _, value := range values {
+			// The binary values are base64 encoded like grpc does on the wire.
+			if strings.HasSuffix(key, "-bin") {
+				value = base64.StdEncoding.EncodeToString([]byte(value))
+			}
+
+			header.Add(key, value)
+		}
+	}
+
+	return &httpUploadStream{
+		sink:       s,
+		ctx:        ctx,
+		id:         uuid.NewString(),
+		header:     header,
+		compressor: compressor,
+	}, nil
+}
+
+// Close closes the idle connections of http upload sink.
+func (s *HTTPUploadSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// httpUploadStream is the train stream uploading a segment of datasets to http upload sink.
+// The datasets are sent concurrently, but the chunks of each dataset are sent in order.
+type httpUploadStream struct {
+	sink   *HTTPUploadSink
+	ctx    context.Context
+	id     string
+	header http.Header
+
+	// compressor compresses the chunks, nil means the chunks are not compressed.
+	compressor encoding.Compressor
+
+	mu                 sync.Mutex
+	downloadSeq        uint64
+	networkTopologySeq uint64
+	closed             bool
+}
+
+// Send uploads the dataset of req as the next chunk of its dataset, it returns after
+// the chunk is acknowledged.
+func (s *httpUploadStream) Send(req *trainerv1.TrainRequest) error {
+	var (
+		dataset string
+		chunk   []byte
+		seq     *uint64
+	)
+	switch r := req.Request.(type) {
+	case *trainerv1.TrainRequest_TrainMlpRequest:
+		dataset, chunk, seq = HTTPUploadDownloadDataset, r.TrainMlpRequest.GetDataset(), &s.downloadSeq
+	case *trainerv1.TrainRequest_TrainGnnRequest:
+		dataset, chunk, seq = HTTPUploadNetworkTopologyDataset, r.TrainGnnRequest.GetDataset(), &s.networkTopologySeq
+	default:
+		return status.Errorf(codes.InvalidArgument, "unknown train request %T", req.Request)
+	}
+
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrUploadStreamClosed
+	}
+	n := *seq
+	s.mu.Unlock()
+
+	header := s.header.Clone()
+	header.Set(HTTPUploadHostnameHeader, req.Hostname)
+	header.Set(HTTPUploadIPHeader, req.Ip)
+	header.Set(HTTPUploadClusterIDHeader, strconv.FormatUint(req.ClusterId, 10))
+	if s.compressor != nil {
+		compressed, err := compressChunk(s.compressor, chunk)
+		if err != nil {
+			return status.Errorf(codes.Internal, "compress chunk: %s", err.Error())
+		}
+
+		chunk = compressed
+		header.Set("Content-Encoding", s.compressor.Name())
+	}
+
+	if err := s.putChunk(dataset, n, chunk, header); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	*seq = n + 1
+	s.mu.Unlock()
+	return nil
+}
+
+// putChunk uploads the chunk seq of dataset, it resumes the upload at most the chunk retries
+// times if the chunk is not acknowledged for transient errors.
+func (s *httpUploadStream) putChunk(dataset string, seq uint64, chunk []byte, header http.Header) error {
+	u := s.sink.endpoint.JoinPath("uploads", s.id, dataset, strconv.FormatUint(seq, 10))
+	for retry := 0; ; retry++ {
+		err := s.do(http.MethodPut, u, bytes.NewReader(chunk), header, nil)
+		if err == nil || retry >= s.sink.chunkRetries || !isRetryableUploadError(err) {
+			return err
+		}
+
+		timer := time.NewTimer(s.sink.chunkRetryBackoff)
+		select {
+		case <-timer.C:
+		case <-s.ctx.Done():
+			timer.Stop()
+			return status.FromContextError(s.ctx.Err()).Err()
+		}
+
+		// The chunk may be received but the acknowledgment is lost.
+		var uploadStatus HTTPUploadStatus
+		if err := s.do(http.MethodGet, s.sink.endpoint.JoinPath("uploads", s.id, dataset), nil, s.header, &uploadStatus); err == nil && uploadStatus.Acked > seq {
+			return nil
+		}
+	}
+}
+
+// compressChunk returns the chunk compressed by compressor.
+func compressChunk(compressor encoding.Compressor, chunk []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := compressor.Compress(&buf)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(chunk); err != nil {
+		w.Close()
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// CloseAndRecv completes the upload, the segment is confirmed if it succeeds.
+func (s *httpUploadStream) CloseAndRecv() (*emptypb.Empty, error) {
+	s.mu.Lock()
+	s.closed = true
+	completion := HTTPUploadCompletion{
+		Download:        s.downloadSeq,
+		NetworkTopology: s.networkTopologySeq,
+	}
+	s.mu.Unlock()
+
+	body, err := json.Marshal(completion)
+	if err != nil {
+		return nil, err
+	}
+
+	header := s.header.Clone()
+	header.Set("Content-Type", "application/json")
+	if err := s.do(http.MethodPost, s.sink.endpoint.JoinPath("uploads", s.id, "complete"), bytes.NewReader(body), header, nil); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// do sends the http request and decodes the json response into v if v is not nil,
+// the errors are converted to grpc status errors.
+func (s *httpUploadStream) do(method string, u *url.URL, body io.Reader, header http.Header, v any) error {
+	req, err := http.NewRequestWithContext(s.ctx, method, u.String(), body)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	req.Header = header.Clone()
+	if method == http.MethodPut {
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+
+	resp, err := s.sink.client.Do(req)
+	if err != nil {
+		if ctxErr := s.ctx.Err(); ctxErr != nil {
+			return status.FromContextError(ctxErr).Err()
+		}
+
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return status.Errorf(httpStatusCode(resp.StatusCode), "%s %s: %s: %s", method, u.Path, resp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// Header returns the empty header metadata, the http upload has no grpc header.
+func (s *httpUploadStream) Header() (metadata.MD, error) {
+	return metadata.MD{}, nil
+}
+
+// Trailer returns the empty trailer metadata, the http upload has no grpc trailer.
+func (s *httpUploadStream) Trailer() metadata.MD {
+	return metadata.MD{}
+}
+
+// CloseSend closes the stream without completing the upload.
+func (s *httpUploadStream) CloseSend() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.closed = true
+	return nil
+}
+
+// Context returns the context of stream.
+func (s *httpUploadStream) Context() context.Context {
+	return s.ctx
+}
+
+// SendMsg uploads m if it is the train request.
+func (s *httpUploadStream) SendMsg(m any) error {
+	req, ok := m.(*trainerv1.TrainRequest)
+	if !ok {
+		return status.Errorf(codes.InvalidArgument, "unknown message %T", m)
+	}
+
+	return s.Send(req)
+}
+
+// RecvMsg returns io.EOF, the http upload receives no message before completing.
+func (s *httpUploadStream) RecvMsg(any) error {
+	return io.EOF
+}
+
+// isRetryableUploadError returns whether the chunk can be uploaded again after err.
+func isRetryableUploadError(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted:
+		return true
+	default:
+		return false
+	}
+}
+
+// httpStatusCode converts the http status code to the grpc status code, so that the transient
+// failures of completing the upload are retried like the grpc sink.
+func httpStatusCode(code int) codes.Code {
+	switch {
+	case code == http.StatusBadRequest:
+		return codes.InvalidArgument
+	case code == http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case code == http.StatusForbidden:
+		return codes.PermissionDenied
+	case code == http.StatusNotFound:
+		return codes.NotFound
+	case code == http.StatusConflict:
+		return codes.Aborted
+	case code == http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case code == http.StatusRequestTimeout || code >= 500:
+		return codes.Unavailable
+	default:
+		return codes.Unknown
+	}
+}
@@ -0,0 +1,400 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package announcer
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	trainerv1 "d7y.io/api/pkg/apis/trainer/v1"
+
+	trainerclient "d7y.io/dragonfly/v2/pkg/rpc/trainer/client"
+	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/storage"
+)
+
+// newSinkAnnouncer returns the announcer uploading datasets to sink.
+func newSinkAnnouncer(sink UploadSink, addr string, s storage.Storage) *announcer {
+	return &announcer{
+		config: &config.Config{
+			Server: config.ServerConfig{
+				AdvertiseIP: net.ParseIP("127.0.0.1"),
+			},
+			Trainer: config.TrainerConfig{
+				Addr:                  addr,
+				UploadTimeout:         time.Minute,
+				UploadDownload:        true,
+				UploadNetworkTopology: true,
+				UploadBatchSize:       4,
+				FinalizeRetries:       1,
+			},
+		},
+		hostname:      "foo",
+		trainerClient: sink,
+		storage:       s,
+		done:          make(chan struct{}),
+		log:           zap.NewNop().Sugar(),
+	}
+}
+
+// fakeTrainerServer is the fake of trainer grpc service recording the datasets received.
+type fakeTrainerServer struct {
+	trainerv1.UnimplementedTrainerServer
+
+	mu              sync.Mutex
+	hostnames       []string
+	download        string
+	networkTopology string
+}
+
+// Train receives the datasets until the stream is closed.
+func (s *fakeTrainerServer) Train(stream trainerv1.Trainer_TrainServer) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(&emptypb.Empty{})
+		}
+
+		if err != nil {
+			return err
+		}
+
+		s.mu.Lock()
+		s.hostnames = append(s.hostnames, req.Hostname)
+		s.download += string(req.GetTrainMlpRequest().GetDataset())
+		s.networkTopology += string(req.GetTrainGnnRequest().GetDataset())
+		s.mu.Unlock()
+	}
+}
+
+func TestGRPCUploadSink(t *testing.T) {
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	fakeServer := &fakeTrainerServer{}
+	trainerv1.RegisterTrainerServer(server, fakeServer)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	client, err := trainerclient.GetV1ByAddr(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	s := storage.NewMemory()
+	s.SetDownload([]byte("foo\nbar\n"))
+	s.SetNetworkTopology([]byte("baz\n"))
+	a := newSinkAnnouncer(client, "127.0.0.1:9113", s)
+
+	assert := assert.New(t)
+	assert.NoError(a.train())
+
+	fakeServer.mu.Lock()
+	defer fakeServer.mu.Unlock()
+	assert.Equal("foo\nbar\n", fakeServer.download)
+	assert.Equal("baz\n", fakeServer.networkTopology)
+	assert.Equal([]string{"foo", "foo", "foo"}, fakeServer.hostnames)
+	assert.Equal(int64(8), a.checkpoint.DownloadOffset)
+	assert.Equal(int64(4), a.checkpoint.NetworkTopologyOffset)
+}
+
+// fakeUploadServer is the fake of trainer http upload endpoint, the chunks of each upload
+// are kept by dataset and sequence, and the completed uploads are the confirmed datasets.
+type fakeUploadServer struct {
+	mu sync.Mutex
+	// chunks is the chunks of uploads keyed by upload id, dataset and sequence.
+	chunks map[string]map[string]map[uint64]string
+	// puts is the number of chunk requests received.
+	puts int
+	// hostname is the hostname header of the last chunk.
+	hostname string
+	// contentEncoding is the content encoding of the last chunk.
+	contentEncoding string
+
+	// dropAck fails the chunk requests of these numbers after keeping the chunk,
+	// dropChunk fails them without keeping the chunk, and failComplete fails
+	// the complete requests of these numbers.
+	dropAck      map[int]int
+	dropChunk    map[int]int
+	failComplete map[int]int
+	completes    int
+
+	download        string
+	networkTopology string
+}
+
+// ServeHTTP serves the requests of http upload sink.
+func (s *fakeUploadServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/v1/uploads/"), "/")
+	switch {
+	case r.Method == http.MethodPut && len(parts) == 3:
+		s.puts++
+		if code, ok := s.dropChunk[s.puts]; ok {
+			w.WriteHeader(code)
+			return
+		}
+
+		seq, err := strconv.ParseUint(parts[2], 10, 64)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		var body []byte
+		if s.contentEncoding = r.Header.Get("Content-Encoding"); s.contentEncoding != "" {
+			compressor := encoding.GetCompressor(s.contentEncoding)
+			if compressor == nil {
+				w.WriteHeader(http.StatusUnsupportedMediaType)
+				return
+			}
+
+			decompressed, err := compressor.Decompress(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			if body, err = io.ReadAll(decompressed); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		} else {
+			body, _ = io.ReadAll(r.Body)
+		}
+
+		if s.chunks[parts[0]] == nil {
+			s.chunks[parts[0]] = map[string]map[uint64]string{}
+		}
+		if s.chunks[parts[0]][parts[1]] == nil {
+			s.chunks[parts[0]][parts[1]] = map[uint64]string{}
+		}
+		s.chunks[parts[0]][parts[1]][seq] = string(body)
+		s.hostname = r.Header.Get(HTTPUploadHostnameHeader)
+
+		if code, ok := s.dropAck[s.puts]; ok {
+			w.WriteHeader(code)
+			return
+		}
+	case r.Method == http.MethodGet && len(parts) == 2:
+		var acked uint64
+		for {
+			if _, ok := s.chunks[parts[0]][parts[1]][acked]; !ok {
+				break
+			}
+			acked++
+		}
+
+		json.NewEncoder(w).Encode(HTTPUploadStatus{Acked: acked})
+	case r.Method == http.MethodPost && len(parts) == 2 && parts[1] == "complete":
+		s.completes++
+		if code, ok := s.failComplete[s.completes]; ok {
+			w.WriteHeader(code)
+			return
+		}
+
+		var completion HTTPUploadCompletion
+		if err := json.NewDecoder(r.Body).Decode(&completion); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		chunks := s.chunks[parts[0]]
+		for seq := uint64(0); seq < completion.Download; seq++ {
+			s.download += chunks[HTTPUploadDownloadDataset][seq]
+		}
+
+		for seq := uint64(0); seq < completion.NetworkTopology; seq++ {
+			s.networkTopology += chunks[HTTPUploadNetworkTopologyDataset][seq]
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestHTTPUploadSink(t *testing.T) {
+	tests := []struct {
+		name       string
+		server     *fakeUploadServer
+		compressor string
+		expect     func(t *testing.T, server *fakeUploadServer, a *announcer, err error)
+	}{
+		{
+			name:   "upload datasets in chunks",
+			server: &fakeUploadServer{},
+			expect: func(t *testing.T, server *fakeUploadServer, a *announcer, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("foo\nbar\n", server.download)
+				assert.Equal("baz\n", server.networkTopology)
+				assert.Equal("foo", server.hostname)
+				assert.Empty(server.contentEncoding)
+				assert.Equal(3, server.puts)
+				assert.Equal(int64(8), a.checkpoint.DownloadOffset)
+				assert.Equal(int64(4), a.checkpoint.NetworkTopologyOffset)
+			},
+		},
+		{
+			name:       "upload chunks compressed by zstd",
+			server:     &fakeUploadServer{},
+			compressor: ZstdCompressorName,
+			expect: func(t *testing.T, server *fakeUploadServer, a *announcer, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("foo\nbar\n", server.download)
+				assert.Equal("baz\n", server.networkTopology)
+				assert.Equal(ZstdCompressorName, server.contentEncoding)
+			},
+		},
+		{
+			name:       "upload chunks compressed by gzip",
+			server:     &fakeUploadServer{},
+			compressor: "gzip",
+			expect: func(t *testing.T, server *fakeUploadServer, a *announcer, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("foo\nbar\n", server.download)
+				assert.Equal("baz\n", server.networkTopology)
+				assert.Equal("gzip", server.contentEncoding)
+			},
+		},
+		{
+			name:   "resume upload without re-sending chunk whose ack is lost",
+			server: &fakeUploadServer{dropAck: map[int]int{1: http.StatusServiceUnavailable}},
+			expect: func(t *testing.T, server *fakeUploadServer, a *announcer, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("foo\nbar\n", server.download)
+				assert.Equal("baz\n", server.networkTopology)
+				assert.Equal(3, server.puts)
+			},
+		},
+		{
+			name:   "resume upload by re-sending chunk not received",
+			server: &fakeUploadServer{dropChunk: map[int]int{1: http.StatusBadGateway}},
+			expect: func(t *testing.T, server *fakeUploadServer, a *announcer, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("foo\nbar\n", server.download)
+				assert.Equal("baz\n", server.networkTopology)
+				assert.Equal(4, server.puts)
+			},
+		},
+		{
+			name:   "chunk is not retried for permanent error",
+			server: &fakeUploadServer{dropChunk: map[int]int{1: http.StatusForbidden}},
+			expect: func(t *testing.T, server *fakeUploadServer, a *announcer, err error) {
+				assert := assert.New(t)
+				assert.ErrorContains(err, "403 Forbidden")
+				assert.Empty(server.download)
+				assert.Equal(int64(0), a.checkpoint.DownloadOffset)
+			},
+		},
+		{
+			name:   "segment is replayed if completing upload fails",
+			server: &fakeUploadServer{failComplete: map[int]int{1: http.StatusServiceUnavailable}},
+			expect: func(t *testing.T, server *fakeUploadServer, a *announcer, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal("foo\nbar\n", server.download)
+				assert.Equal("baz\n", server.networkTopology)
+				assert.Equal(6, server.puts)
+				assert.Equal(2, server.completes)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.server.chunks = map[string]map[string]map[uint64]string{}
+			server := httptest.NewServer(tc.server)
+			defer server.Close()
+
+			sink, err := NewHTTPUploadSink(server.URL+"/api/v1", WithHTTPUploadChunkRetryBackoff(time.Millisecond))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer sink.Close()
+
+			s := storage.NewMemory()
+			s.SetDownload([]byte("foo\nbar\n"))
+			s.SetNetworkTopology([]byte("baz\n"))
+			a := newSinkAnnouncer(sink, "127.0.0.1:9114", s)
+			a.config.Trainer.Compressor = tc.compressor
+
+			err = a.train()
+			tc.server.mu.Lock()
+			defer tc.server.mu.Unlock()
+			tc.expect(t, tc.server, a, err)
+		})
+	}
+}
+
+func TestNewHTTPUploadSink(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		expect   func(t *testing.T, err error)
+	}{
+		{
+			name:     "https endpoint",
+			endpoint: "https://127.0.0.1:9443/api/v1",
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+			},
+		},
+		{
+			name:     "endpoint without scheme",
+			endpoint: "127.0.0.1:9443",
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.Error(err)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewHTTPUploadSink(tc.endpoint)
+			tc.expect(t, err)
+		})
+	}
+}
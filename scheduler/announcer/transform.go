@@ -0,0 +1,234 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package announcer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// DownloadDataset is the name of download dataset.
+	DownloadDataset = "download"
+
+	// NetworkTopologyDataset is the name of network topology dataset.
+	NetworkTopologyDataset = "network topology"
+)
+
+// UploadTransform transforms the dataset before uploading to trainer,
+// e.g. filters PII or normalizes fields.
+type UploadTransform struct {
+	// Name is the name of transform, it is used to describe the failed transform.
+	Name string
+
+	// Dataset is the dataset transformed, e.g. DownloadDataset. Empty means all datasets.
+	Dataset string
+
+	// Transform wraps the dataset reader and returns the transformed reader.
+	Transform func(io.Reader) io.Reader
+}
+
+// UploadTransformError describes the failed transform.
+type UploadTransformError struct {
+	// Name is the name of the failed transform.
+	Name string
+
+	// Err is the error returned by the failed transform.
+	Err error
+}
+
+// Error returns the description of the failed transform.
+func (e *UploadTransformError) Error() string {
+	return fmt.Sprintf("upload transform %s failed: %s", e.Name, e.Err.Error())
+}
+
+// Unwrap returns the error returned by the failed transform.
+func (e *UploadTransformError) Unwrap() error {
+	return e.Err
+}
+
+// transformReader tags the errors of transform with its name.
+type transformReader struct {
+	name   string
+	reader io.Reader
+}
+
+// Read reads the transformed dataset, the errors passed through from
+// the upstream readers are returned as is.
+func (r *transformReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if err == nil || err == io.EOF {
+		return n, err
+	}
+
+	var transformErr *UploadTransformError
+	var sourceErr *sourceError
+	if errors.As(err, &transformErr) || errors.As(err, &sourceErr) {
+		return n, err
+	}
+
+	return n, &UploadTransformError{Name: r.name, Err: err}
+}
+
+// sourceError is the error of reading dataset, it is not tagged by transforms.
+type sourceError struct {
+	err error
+}
+
+// Error returns the error of reading dataset.
+func (e *sourceError) Error() string {
+	return e.err.Error()
+}
+
+// sourceReader reads the dataset and counts the bytes read, the transforms
+// may change the size of dataset, so the offset is counted before transforms.
+type sourceReader struct {
+	reader io.Reader
+	n      int64
+}
+
+// Read reads the dataset.
+func (r *sourceReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.n += int64(n)
+	if err != nil && err != io.EOF {
+		return n, &sourceError{err}
+	}
+
+	return n, err
+}
+
+// applyUploadTransforms applies the transforms of dataset to reader in order.
+func applyUploadTransforms(reader io.Reader, transforms []UploadTransform, dataset string) io.Reader {
+	reader, _ = applyCountedUploadTransforms(reader, transforms, dataset)
+	return reader
+}
+
+// applyCountedUploadTransforms applies the transforms of dataset to reader in order, and counts
+// the records read from reader and the records output by each transform.
+func applyCountedUploadTransforms(reader io.Reader, transforms []UploadTransform, dataset string) (io.Reader, *recordPipeline) {
+	counter := &recordCounter{reader: reader}
+	pipeline := &recordPipeline{counters: []*recordCounter{counter}}
+	reader = counter
+	for _, transform := range transforms {
+		if transform.Dataset != "" && transform.Dataset != dataset {
+			continue
+		}
+
+		counter = &recordCounter{reader: &transformReader{name: transform.Name, reader: transform.Transform(reader)}}
+		pipeline.names = append(pipeline.names, transform.Name)
+		pipeline.counters = append(pipeline.counters, counter)
+		reader = counter
+	}
+
+	return reader, pipeline
+}
+
+// RecordCounts is the number of records through each stage of uploading a dataset. Each record
+// read from storage is either dropped by a transform or sent to trainer, so Read equals the sum of
+// Dropped and Sent if the dataset is uploaded completely.
+type RecordCounts struct {
+	// Read is the number of records read from storage.
+	Read int64
+
+	// Dropped is the number of records dropped by each transform, keyed by the name of transform,
+	// e.g. the records filtered out or deduplicated.
+	Dropped map[string]int64
+
+	// Sent is the number of records sent to trainer.
+	Sent int64
+}
+
+// add adds the counts of other to c.
+func (c *RecordCounts) add(other RecordCounts) {
+	c.Read += other.Read
+	c.Sent += other.Sent
+	for name, n := range other.Dropped {
+		if c.Dropped == nil {
+			c.Dropped = make(map[string]int64)
+		}
+
+		c.Dropped[name] += n
+	}
+}
+
+// recordPipeline is the record counters of the stages of uploading a dataset, the first
+// counter counts the records read from storage and the others count the records output
+// by the transforms of names.
+type recordPipeline struct {
+	names    []string
+	counters []*recordCounter
+}
+
+// counts returns the record counts of pipeline, the records output by the last stage are sent.
+// The unterminated last line is counted only if the dataset is read completely, otherwise
+// it is the part of a record continued in the next segment.
+func (p *recordPipeline) counts(complete bool) RecordCounts {
+	n := make([]int64, len(p.counters))
+	for i, counter := range p.counters {
+		n[i] = counter.n
+		if complete && counter.unfinished {
+			n[i]++
+		}
+	}
+
+	counts := RecordCounts{
+		Read: n[0],
+		Sent: n[len(n)-1],
+	}
+
+	for i, name := range p.names {
+		if counts.Dropped == nil {
+			counts.Dropped = make(map[string]int64)
+		}
+
+		counts.Dropped[name] += n[i] - n[i+1]
+	}
+
+	return counts
+}
+
+// recordCounter counts the records read through it, each record is a line of csv.
+// unfinished is whether the last line read is not terminated.
+type recordCounter struct {
+	reader     io.Reader
+	n          int64
+	unfinished bool
+}
+
+// Read reads the records and counts the terminated lines.
+func (r *recordCounter) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.n += int64(bytes.Count(p[:n], []byte{'\n'}))
+		r.unfinished = p[n-1] != '\n'
+	}
+
+	return n, err
+}
+
+// unwrapSourceError returns the error of reading dataset if err is caused by it.
+func unwrapSourceError(err error) error {
+	var sourceErr *sourceError
+	if errors.As(err, &sourceErr) {
+		return sourceErr.err
+	}
+
+	return err
+}
@@ -28,6 +28,7 @@ import (
 	"d7y.io/dragonfly/v2/pkg/rpc"
 	"d7y.io/dragonfly/v2/pkg/slices"
 	"d7y.io/dragonfly/v2/pkg/types"
+	"d7y.io/dragonfly/v2/pkg/unit"
 )
 
 type Config struct {
@@ -75,12 +76,22 @@ type Config struct {
 
 	// Trainer configuration.
 	Trainer TrainerConfig `yaml:"trainer" mapstructure:"trainer"`
+
+	// PeerExchange configuration.
+	PeerExchange PeerExchangeConfig `yaml:"peerExchange" mapstructure:"peerExchange"`
+
+	// Inventory configuration.
+	Inventory InventoryConfig `yaml:"inventory" mapstructure:"inventory"`
 }
 
 type ServerConfig struct {
 	// AdvertiseIP is advertise ip.
 	AdvertiseIP net.IP `yaml:"advertiseIP" mapstructure:"advertiseIP"`
 
+	// SecondaryAdvertiseIP is advertise ip of the other address family than advertiseIP,
+	// it is advertised together with advertiseIP on dual-stack hosts.
+	SecondaryAdvertiseIP net.IP `yaml:"secondaryAdvertiseIP" mapstructure:"secondaryAdvertiseIP"`
+
 	// AdvertisePort is advertise port.
 	AdvertisePort int `yaml:"advertisePort" mapstructure:"advertisePort"`
 
@@ -107,6 +118,10 @@ type ServerConfig struct {
 
 	// Server storage data directory.
 	DataDir string `yaml:"dataDir" mapstructure:"dataDir"`
+
+	// DrainTimeout is the timeout of waiting for the in-flight peers to finish
+	// when the scheduler is draining.
+	DrainTimeout time.Duration `yaml:"drainTimeout" mapstructure:"drainTimeout"`
 }
 
 type DatabaseConfig struct {
@@ -115,7 +130,8 @@ type DatabaseConfig struct {
 }
 
 type SchedulerConfig struct {
-	// Algorithm is scheduling algorithm used by the scheduler.
+	// Algorithm is scheduling algorithm used by the scheduler, it is default, ml, plugin
+	// or the algorithm registered by evaluator.Register.
 	Algorithm string `yaml:"algorithm" mapstructure:"algorithm"`
 
 	// BackToSourceCount is single task allows the peer to back-to-source count.
@@ -132,6 +148,59 @@ type SchedulerConfig struct {
 
 	// GC configuration.
 	GC GCConfig `yaml:"gc" mapstructure:"gc"`
+
+	// PriorityClass configuration.
+	PriorityClass PriorityClassConfig `yaml:"priorityClass" mapstructure:"priorityClass"`
+
+	// UploadBandwidth configuration.
+	UploadBandwidth UploadBandwidthConfig `yaml:"uploadBandwidth" mapstructure:"uploadBandwidth"`
+
+	// Snapshot configuration.
+	Snapshot SnapshotConfig `yaml:"snapshot" mapstructure:"snapshot"`
+
+	// RateLimit configuration.
+	RateLimit RateLimitConfig `yaml:"rateLimit" mapstructure:"rateLimit"`
+}
+
+type RateLimitConfig struct {
+	// Enable limits the rate of registering peers per client by token bucket, the client
+	// is identified by host id, or by ip if the request has no host id.
+	Enable bool `yaml:"enable" mapstructure:"enable"`
+
+	// Limit is the number of tokens refilled per second for each client.
+	Limit float64 `yaml:"limit" mapstructure:"limit"`
+
+	// Burst is the size of token bucket for each client.
+	Burst int `yaml:"burst" mapstructure:"burst"`
+}
+
+type SnapshotConfig struct {
+	// Enable snapshots the hosts, tasks and peers of resource into the data directory,
+	// and reloads them when the scheduler restarts, so that the running peers are re-adopted.
+	Enable bool `yaml:"enable" mapstructure:"enable"`
+
+	// Interval is the interval of snapshotting resource.
+	Interval time.Duration `yaml:"interval" mapstructure:"interval"`
+}
+
+type UploadBandwidthConfig struct {
+	// PeerWatermark is the upload bandwidth watermark per second of the normal host, the parents
+	// on the host whose upload bandwidth exceeds the watermark are not scheduled, zero means no watermark.
+	PeerWatermark unit.Bytes `yaml:"peerWatermark" mapstructure:"peerWatermark"`
+
+	// SeedPeerWatermark is the upload bandwidth watermark per second of the seed peer host, the parents
+	// on the host whose upload bandwidth exceeds the watermark are not scheduled, zero means no watermark.
+	SeedPeerWatermark unit.Bytes `yaml:"seedPeerWatermark" mapstructure:"seedPeerWatermark"`
+}
+
+type PriorityClassConfig struct {
+	// NormalReservedUploadRatio is the ratio of the parent's upload limit reserved for
+	// critical peers, normal peers are not scheduled to the parent in the reserved upload.
+	NormalReservedUploadRatio float64 `yaml:"normalReservedUploadRatio" mapstructure:"normalReservedUploadRatio"`
+
+	// BestEffortReservedUploadRatio is the ratio of the parent's upload limit reserved for normal
+	// and critical peers, best-effort peers are not scheduled to the parent in the reserved upload.
+	BestEffortReservedUploadRatio float64 `yaml:"bestEffortReservedUploadRatio" mapstructure:"bestEffortReservedUploadRatio"`
 }
 
 type GCConfig struct {
@@ -155,6 +224,15 @@ type GCConfig struct {
 	// HostTTL is time to live of host. If host announces message to scheduler,
 	// then HostTTl will be reset.
 	HostTTL time.Duration `yaml:"hostTTL" mapstructure:"hostTTL"`
+
+	// TaskTTL is time to live of idle task. If the task has no running peers and
+	// is not updated within TaskTTL, then the task will be reclaimed, zero means
+	// the task is reclaimed only when all the peers have been reclaimed.
+	TaskTTL time.Duration `yaml:"taskTTL" mapstructure:"taskTTL"`
+
+	// MaxTaskCount is the max number of tasks. If the number of tasks exceeds MaxTaskCount,
+	// the least recently updated idle tasks will be reclaimed, zero means unlimited.
+	MaxTaskCount int `yaml:"maxTaskCount" mapstructure:"maxTaskCount"`
 }
 
 type DynConfig struct {
@@ -174,11 +252,18 @@ type ManagerConfig struct {
 	// Addr is manager address.
 	Addr string `yaml:"addr" mapstructure:"addr"`
 
+	// Addrs are the addresses of standby manager instances, the announcer fails over to
+	// them in order for registration and keepalive when the active manager is unavailable.
+	Addrs []string `yaml:"addrs" mapstructure:"addrs"`
+
 	// SchedulerClusterID is scheduler cluster id.
 	SchedulerClusterID uint `yaml:"schedulerClusterID" mapstructure:"schedulerClusterID"`
 
 	// KeepAlive configuration.
 	KeepAlive KeepAliveConfig `yaml:"keepAlive" mapstructure:"keepAlive"`
+
+	// Registration configuration.
+	Registration RegistrationConfig `yaml:"registration" mapstructure:"registration"`
 }
 
 type SeedPeerConfig struct {
@@ -189,6 +274,31 @@ type SeedPeerConfig struct {
 type KeepAliveConfig struct {
 	// Keep alive interval.
 	Interval time.Duration `yaml:"interval" mapstructure:"interval"`
+
+	// MaxConsecutiveFailures is the max number of consecutive failed keepalive streams, the
+	// announcer reports an unrecoverable error when it is exceeded. Zero means keepalive
+	// retries forever.
+	MaxConsecutiveFailures int `yaml:"maxConsecutiveFailures" mapstructure:"maxConsecutiveFailures"`
+}
+
+type RegistrationConfig struct {
+	// MaxAttempts is the max attempts of registering scheduler to manager on startup.
+	MaxAttempts int `yaml:"maxAttempts" mapstructure:"maxAttempts"`
+
+	// InitBackoff is the backoff before the second attempt, the backoff doubles
+	// in each attempt up to MaxBackoff.
+	InitBackoff time.Duration `yaml:"initBackoff" mapstructure:"initBackoff"`
+
+	// MaxBackoff is the max backoff between attempts.
+	MaxBackoff time.Duration `yaml:"maxBackoff" mapstructure:"maxBackoff"`
+
+	// Jitter is the ratio in [0, 1] by which each backoff is randomly reduced,
+	// so that schedulers do not retry in lockstep after a manager outage.
+	Jitter float64 `yaml:"jitter" mapstructure:"jitter"`
+
+	// Degraded starts scheduler without registration if all attempts fail,
+	// then scheduler registers to manager lazily in the background.
+	Degraded bool `yaml:"degraded" mapstructure:"degraded"`
 }
 
 type JobConfig struct {
@@ -209,6 +319,11 @@ type JobConfig struct {
 }
 
 type StorageConfig struct {
+	// Driver is the driver of storage, it can be file or sqlite. The file driver writes the
+	// records into rotated csv files and clears them when scheduler stops. The sqlite driver
+	// keeps the records in the sqlite database of data directory, so that they survive restarts.
+	Driver string `yaml:"driver" mapstructure:"driver"`
+
 	// MaxSize sets the maximum size in megabytes of storage file.
 	MaxSize int `yaml:"maxSize" mapstructure:"maxSize"`
 
@@ -218,6 +333,36 @@ type StorageConfig struct {
 	// BufferSize sets the size of buffer container,
 	// if the buffer is full, write all the records in the buffer to the file.
 	BufferSize int `yaml:"bufferSize" mapstructure:"bufferSize"`
+
+	// MaxRecords sets the maximum number of records to retain for each of downloads and
+	// network topologies, the oldest records are dropped when appending. Zero means unlimited.
+	MaxRecords int `yaml:"maxRecords" mapstructure:"maxRecords"`
+
+	// Encryption is the configuration of encryption at rest.
+	Encryption StorageEncryptionConfig `yaml:"encryption" mapstructure:"encryption"`
+}
+
+type StorageEncryptionConfig struct {
+	// Enable encrypts storage files with AES-GCM.
+	Enable bool `yaml:"enable" mapstructure:"enable"`
+
+	// KeyID is the id of the key used to encrypt new records.
+	KeyID string `yaml:"keyID" mapstructure:"keyID"`
+
+	// Keys are the keys used to decrypt records, including the rotated keys.
+	Keys []StorageEncryptionKeyConfig `yaml:"keys" mapstructure:"keys"`
+}
+
+type StorageEncryptionKeyConfig struct {
+	// ID is the key id tagged on the encrypted records.
+	ID string `yaml:"id" mapstructure:"id"`
+
+	// File is the path of file containing the base64 encoded 32 bytes key.
+	File string `yaml:"file" mapstructure:"file"`
+
+	// Env is the environment variable containing the base64 encoded 32 bytes key,
+	// it is used if file is empty.
+	Env string `yaml:"env" mapstructure:"env"`
 }
 
 type RedisConfig struct {
@@ -258,6 +403,18 @@ type MetricsConfig struct {
 
 	// Enable host metrics.
 	EnableHost bool `yaml:"enableHost" mapstructure:"enableHost"`
+
+	// SeedPeerSaturation configuration.
+	SeedPeerSaturation SeedPeerSaturationConfig `yaml:"seedPeerSaturation" mapstructure:"seedPeerSaturation"`
+}
+
+type SeedPeerSaturationConfig struct {
+	// Enable computes the saturation of seed peers and the back-to-source rate periodically,
+	// and publishes them to manager for the autoscaler of seed peers.
+	Enable bool `yaml:"enable" mapstructure:"enable"`
+
+	// Interval is the interval of computing the saturation of seed peers.
+	Interval time.Duration `yaml:"interval" mapstructure:"interval"`
 }
 
 type SecurityConfig struct {
@@ -308,6 +465,36 @@ type NetworkTopologyConfig struct {
 	Probe ProbeConfig `yaml:"probe" mapstructure:"probe"`
 }
 
+type PeerExchangeConfig struct {
+	// Enable exchanges the peers of active tasks between the schedulers of the same cluster by redis,
+	// so that the peers registered on other schedulers can be scheduled as parents.
+	Enable bool `yaml:"enable" mapstructure:"enable"`
+
+	// Interval is the interval of exchanging peers, the peers published by a scheduler
+	// expire if they are not published again in three intervals.
+	Interval time.Duration `yaml:"interval" mapstructure:"interval"`
+
+	// MaxPeers is the max number of peers of each task published by the scheduler.
+	MaxPeers int `yaml:"maxPeers" mapstructure:"maxPeers"`
+}
+
+type InventoryConfig struct {
+	// Enable reports the active hosts and peers of scheduler to manager periodically,
+	// so that they can be listed by the inventory api of manager.
+	Enable bool `yaml:"enable" mapstructure:"enable"`
+
+	// Interval is the interval of reporting inventory, the inventory reported by a scheduler
+	// expires if it is not reported again in three intervals.
+	Interval time.Duration `yaml:"interval" mapstructure:"interval"`
+
+	// UnhealthyTimeout is the timeout after which the host without announcing
+	// or the downloading peer without finishing pieces is unhealthy.
+	UnhealthyTimeout time.Duration `yaml:"unhealthyTimeout" mapstructure:"unhealthyTimeout"`
+
+	// MaxPeers is the max number of peers reported by the scheduler.
+	MaxPeers int `yaml:"maxPeers" mapstructure:"maxPeers"`
+}
+
 type ProbeConfig struct {
 	// QueueLength is the length of probe queue in directed graph.
 	QueueLength int `mapstructure:"queueLength" yaml:"queueLength"`
@@ -326,11 +513,150 @@ type TrainerConfig struct {
 	// Addr is trainer service address.
 	Addr string `yaml:"addr" mapstructure:"addr"`
 
+	// ClusterID is the cluster id of training dataset sent to trainer.
+	// Zero means the scheduler cluster id is used.
+	ClusterID uint `yaml:"clusterID" mapstructure:"clusterID"`
+
 	// Interval is the interval of training.
 	Interval time.Duration `yaml:"interval" mapstructure:"interval"`
 
+	// AdaptiveInterval adapts the interval of training to the growth rate of records, the interval
+	// contracts when records accumulate quickly and expands when no record is written. Interval is
+	// the initial interval.
+	AdaptiveInterval bool `yaml:"adaptiveInterval" mapstructure:"adaptiveInterval"`
+
+	// MinInterval is the min interval of training adapted by AdaptiveInterval.
+	MinInterval time.Duration `yaml:"minInterval" mapstructure:"minInterval"`
+
+	// MaxInterval is the max interval of training adapted by AdaptiveInterval.
+	MaxInterval time.Duration `yaml:"maxInterval" mapstructure:"maxInterval"`
+
+	// TargetRecords is the number of records uploaded in each training targeted by AdaptiveInterval.
+	TargetRecords int64 `yaml:"targetRecords" mapstructure:"targetRecords"`
+
+	// IntervalSensitivity is the ratio in (0, 1] that the interval moves towards the interval of
+	// TargetRecords in each training, one moves to it immediately.
+	IntervalSensitivity float64 `yaml:"intervalSensitivity" mapstructure:"intervalSensitivity"`
+
+	// StartupJitter is the max random delay before the first training cycle, so that the
+	// schedulers started together do not train at the same time. It must not exceed Interval,
+	// zero means the first training cycle starts after Interval.
+	StartupJitter time.Duration `yaml:"startupJitter" mapstructure:"startupJitter"`
+
 	// UploadTimeout is the timeout of uploading dataset to trainer.
 	UploadTimeout time.Duration `yaml:"uploadTimeout" mapstructure:"uploadTimeout"`
+
+	// UploadTimeoutWarningRatio is the ratio of elapsed time of upload to UploadTimeout, beyond
+	// which a warning is logged even if the upload succeeds. Zero disables the warning.
+	UploadTimeoutWarningRatio float64 `yaml:"uploadTimeoutWarningRatio" mapstructure:"uploadTimeoutWarningRatio"`
+
+	// StreamOpenTimeout is the timeout of opening train stream to trainer, it is
+	// a part of UploadTimeout. Zero means no separate timeout.
+	StreamOpenTimeout time.Duration `yaml:"streamOpenTimeout" mapstructure:"streamOpenTimeout"`
+
+	// UploadSegmentSize is the size in bytes of each dataset segment uploaded to trainer,
+	// the checkpoint advances after each segment is uploaded. Zero means the dataset
+	// is uploaded in a single segment.
+	UploadSegmentSize int64 `yaml:"uploadSegmentSize" mapstructure:"uploadSegmentSize"`
+
+	// MaxMessageSize is the max size in bytes of each message sent to trainer, including
+	// the metadata of message. Zero means the size of message is not limited.
+	MaxMessageSize int `yaml:"maxMessageSize" mapstructure:"maxMessageSize"`
+
+	// UploadBatchSize is the size in bytes of dataset coalesced from the reads of dataset into
+	// each message, it is capped by MaxMessageSize. Zero means each read is sent in a message.
+	UploadBatchSize int `yaml:"uploadBatchSize" mapstructure:"uploadBatchSize"`
+
+	// MinUploadBytes is the min size in bytes of dataset written since the last successful
+	// training, the training is deferred to the next tick if the dataset is smaller.
+	// Zero means the training is never deferred.
+	MinUploadBytes int64 `yaml:"minUploadBytes" mapstructure:"minUploadBytes"`
+
+	// Compressor is the name of grpc compressor used by the train stream, e.g. gzip or zstd,
+	// the compressor must be registered. The http upload sink compresses the chunks by it.
+	// Empty means no compression.
+	Compressor string `yaml:"compressor" mapstructure:"compressor"`
+
+	// UploadDownload uploads download dataset to trainer.
+	UploadDownload bool `yaml:"uploadDownload" mapstructure:"uploadDownload"`
+
+	// UploadNetworkTopology uploads network topology dataset to trainer.
+	UploadNetworkTopology bool `yaml:"uploadNetworkTopology" mapstructure:"uploadNetworkTopology"`
+
+	// SampleRate is the rate in (0, 1] of download records written into storage for training,
+	// the records are sampled before they are written. One means all records are written.
+	SampleRate float64 `yaml:"sampleRate" mapstructure:"sampleRate"`
+
+	// SamplePolicy is the policy of sampling download records, it can be rate or stratified.
+	SamplePolicy string `yaml:"samplePolicy" mapstructure:"samplePolicy"`
+
+	// FinalizePolicy is the policy of checkpoint when all data is sent but the train stream
+	// is not confirmed by trainer, it can be pessimistic or optimistic.
+	FinalizePolicy string `yaml:"finalizePolicy" mapstructure:"finalizePolicy"`
+
+	// FinalizeRetries is the max number of retries when the train stream is not confirmed with a
+	// retryable error, each retry re-sends only the unconfirmed segment on a fresh stream before
+	// FinalizePolicy applies. Zero means no retry.
+	FinalizeRetries int `yaml:"finalizeRetries" mapstructure:"finalizeRetries"`
+
+	// FullSyncInterval is the interval of uploading the whole dataset from the beginning, the
+	// dataset appended since the checkpoint is uploaded between full syncs. It is the fallback
+	// if trainer loses the dataset uploaded incrementally. Zero disables full sync.
+	FullSyncInterval time.Duration `yaml:"fullSyncInterval" mapstructure:"fullSyncInterval"`
+
+	// CycleTimeout is the max duration of uploading dataset in a training cycle, uploading
+	// stops when it is exceeded. Zero means the duration is not limited.
+	CycleTimeout time.Duration `yaml:"cycleTimeout" mapstructure:"cycleTimeout"`
+
+	// CycleMaxBytes is the max size in bytes of dataset uploaded in a training cycle, the size is
+	// counted before upload transforms, uploading stops when it is exceeded. Zero means the size
+	// is not limited.
+	CycleMaxBytes int64 `yaml:"cycleMaxBytes" mapstructure:"cycleMaxBytes"`
+
+	// CycleBudgetPolicy is the policy of train stream when CycleTimeout or CycleMaxBytes
+	// is exceeded, it can be finalize or abort.
+	CycleBudgetPolicy string `yaml:"cycleBudgetPolicy" mapstructure:"cycleBudgetPolicy"`
+
+	// UploadPolicy is the policy of uploading datasets, it can be all-or-nothing or best-effort.
+	UploadPolicy string `yaml:"uploadPolicy" mapstructure:"uploadPolicy"`
+
+	// BreakerFailureThreshold is the number of consecutive failed training cycles that opens
+	// the circuit breaker, the training cycles are skipped until BreakerCooldown elapses.
+	// Zero means the circuit breaker is disabled.
+	BreakerFailureThreshold int `yaml:"breakerFailureThreshold" mapstructure:"breakerFailureThreshold"`
+
+	// BreakerCooldown is the duration of the opened circuit breaker, a single training
+	// cycle is tried after it elapses.
+	BreakerCooldown time.Duration `yaml:"breakerCooldown" mapstructure:"breakerCooldown"`
+
+	// RedactIPs replaces the host ips of dataset with the salted HMAC of ips before uploading
+	// to trainer, the same ip is always replaced with the same value, so records can be joined.
+	RedactIPs bool `yaml:"redactIPs" mapstructure:"redactIPs"`
+
+	// RedactIPsSalt is the salt of HMAC used by RedactIPs.
+	RedactIPsSalt string `yaml:"redactIPsSalt" mapstructure:"redactIPsSalt"`
+
+	// SignDatasets signs the datasets sent in each train stream with the Ed25519 private key of
+	// SigningKeyFile, so that trainer can verify the datasets with the public key of scheduler.
+	SignDatasets bool `yaml:"signDatasets" mapstructure:"signDatasets"`
+
+	// SigningKeyFile is the file of Ed25519 private key in PKCS #8 PEM format used by SignDatasets.
+	SigningKeyFile string `yaml:"signingKeyFile" mapstructure:"signingKeyFile"`
+
+	// UploadSink is the sink of datasets uploaded to trainer, it can be grpc or http.
+	// The grpc sink uploads by the Train stream of Addr, the http sink uploads by HTTPUpload.
+	UploadSink string `yaml:"uploadSink" mapstructure:"uploadSink"`
+
+	// HTTPUpload is the http upload sink configuration.
+	HTTPUpload TrainerHTTPUploadConfig `yaml:"httpUpload" mapstructure:"httpUpload"`
+}
+
+type TrainerHTTPUploadConfig struct {
+	// Endpoint is the base url of http upload endpoint of trainer, e.g. https://trainer:9443/api/v1.
+	Endpoint string `yaml:"endpoint" mapstructure:"endpoint"`
+
+	// ChunkRetries is the max number of retries of uploading a chunk, zero disables retrying.
+	ChunkRetries int `yaml:"chunkRetries" mapstructure:"chunkRetries"`
 }
 
 // New default configuration.
@@ -340,6 +666,7 @@ func New() *Config {
 			Port:          DefaultServerPort,
 			AdvertisePort: DefaultServerAdvertisePort,
 			Host:          fqdn.FQDNHostname,
+			DrainTimeout:  DefaultServerDrainTimeout,
 		},
 		Database: DatabaseConfig{
 			Redis: RedisConfig{
@@ -362,6 +689,19 @@ func New() *Config {
 				HostGCInterval:       DefaultSchedulerHostGCInterval,
 				HostTTL:              DefaultSchedulerHostTTL,
 			},
+			PriorityClass: PriorityClassConfig{
+				NormalReservedUploadRatio:     DefaultSchedulerNormalReservedUploadRatio,
+				BestEffortReservedUploadRatio: DefaultSchedulerBestEffortReservedUploadRatio,
+			},
+			Snapshot: SnapshotConfig{
+				Enable:   false,
+				Interval: DefaultSchedulerSnapshotInterval,
+			},
+			RateLimit: RateLimitConfig{
+				Enable: false,
+				Limit:  DefaultSchedulerRateLimit,
+				Burst:  DefaultSchedulerRateLimitBurst,
+			},
 		},
 		DynConfig: DynConfig{
 			RefreshInterval: DefaultDynConfigRefreshInterval,
@@ -372,6 +712,12 @@ func New() *Config {
 			KeepAlive: KeepAliveConfig{
 				Interval: DefaultManagerKeepAliveInterval,
 			},
+			Registration: RegistrationConfig{
+				MaxAttempts: DefaultManagerRegistrationMaxAttempts,
+				InitBackoff: DefaultManagerRegistrationInitBackoff,
+				MaxBackoff:  DefaultManagerRegistrationMaxBackoff,
+				Jitter:      DefaultManagerRegistrationJitter,
+			},
 		},
 		SeedPeer: SeedPeerConfig{
 			Enable: true,
@@ -383,6 +729,7 @@ func New() *Config {
 			LocalWorkerNum:     DefaultJobLocalWorkerNum,
 		},
 		Storage: StorageConfig{
+			Driver:     DefaultStorageDriver,
 			MaxSize:    DefaultStorageMaxSize,
 			MaxBackups: DefaultStorageMaxBackups,
 			BufferSize: DefaultStorageBufferSize,
@@ -391,6 +738,10 @@ func New() *Config {
 			Enable:     false,
 			Addr:       DefaultMetricsAddr,
 			EnableHost: false,
+			SeedPeerSaturation: SeedPeerSaturationConfig{
+				Enable:   false,
+				Interval: DefaultMetricsSeedPeerSaturationInterval,
+			},
 		},
 		Security: SecurityConfig{
 			AutoIssueCert: false,
@@ -414,11 +765,39 @@ func New() *Config {
 				SyncCount:    DefaultProbeSyncCount,
 			},
 		},
+		PeerExchange: PeerExchangeConfig{
+			Enable:   false,
+			Interval: DefaultPeerExchangeInterval,
+			MaxPeers: DefaultPeerExchangeMaxPeers,
+		},
+		Inventory: InventoryConfig{
+			Enable:           false,
+			Interval:         DefaultInventoryInterval,
+			UnhealthyTimeout: DefaultInventoryUnhealthyTimeout,
+			MaxPeers:         DefaultInventoryMaxPeers,
+		},
 		Trainer: TrainerConfig{
-			Enable:        false,
-			Addr:          DefaultTrainerAddr,
-			Interval:      DefaultTrainerInterval,
-			UploadTimeout: DefaultTrainerUploadTimeout,
+			Enable:                    false,
+			Addr:                      DefaultTrainerAddr,
+			Interval:                  DefaultTrainerInterval,
+			IntervalSensitivity:       DefaultTrainerIntervalSensitivity,
+			UploadTimeout:             DefaultTrainerUploadTimeout,
+			UploadTimeoutWarningRatio: DefaultTrainerUploadTimeoutWarningRatio,
+			StreamOpenTimeout:         DefaultTrainerStreamOpenTimeout,
+			MaxMessageSize:            DefaultTrainerMaxMessageSize,
+			UploadDownload:            true,
+			UploadNetworkTopology:     true,
+			SampleRate:                DefaultTrainerSampleRate,
+			SamplePolicy:              DefaultTrainerSamplePolicy,
+			FinalizePolicy:            DefaultTrainerFinalizePolicy,
+			FinalizeRetries:           DefaultTrainerFinalizeRetries,
+			FullSyncInterval:          DefaultTrainerFullSyncInterval,
+			CycleBudgetPolicy:         DefaultTrainerCycleBudgetPolicy,
+			UploadPolicy:              DefaultTrainerUploadPolicy,
+			UploadSink:                DefaultTrainerUploadSink,
+			HTTPUpload: TrainerHTTPUploadConfig{
+				ChunkRetries: DefaultTrainerHTTPUploadChunkRetries,
+			},
 		},
 	}
 }
@@ -429,6 +808,11 @@ func (cfg *Config) Validate() error {
 		return errors.New("server requires parameter advertiseIP")
 	}
 
+	if cfg.Server.SecondaryAdvertiseIP != nil &&
+		(cfg.Server.SecondaryAdvertiseIP.To4() == nil) == (cfg.Server.AdvertiseIP.To4() == nil) {
+		return errors.New("server requires parameter secondaryAdvertiseIP in the other address family than advertiseIP")
+	}
+
 	if cfg.Server.AdvertisePort <= 0 {
 		return errors.New("server requires parameter advertisePort")
 	}
@@ -445,6 +829,10 @@ func (cfg *Config) Validate() error {
 		return errors.New("server requires parameter host")
 	}
 
+	if cfg.Server.DrainTimeout <= 0 {
+		return errors.New("server requires parameter drainTimeout")
+	}
+
 	if len(cfg.Database.Redis.Addrs) == 0 {
 		return errors.New("redis requires parameter addrs")
 	}
@@ -505,6 +893,45 @@ func (cfg *Config) Validate() error {
 		return errors.New("scheduler requires parameter hostTTL")
 	}
 
+	if cfg.Scheduler.GC.TaskTTL < 0 {
+		return errors.New("scheduler requires parameter taskTTL")
+	}
+
+	if cfg.Scheduler.GC.MaxTaskCount < 0 {
+		return errors.New("scheduler requires parameter maxTaskCount")
+	}
+
+	if cfg.Scheduler.PriorityClass.NormalReservedUploadRatio < 0 || cfg.Scheduler.PriorityClass.NormalReservedUploadRatio >= 1 {
+		return errors.New("scheduler requires parameter normalReservedUploadRatio")
+	}
+
+	if cfg.Scheduler.PriorityClass.BestEffortReservedUploadRatio < cfg.Scheduler.PriorityClass.NormalReservedUploadRatio ||
+		cfg.Scheduler.PriorityClass.BestEffortReservedUploadRatio >= 1 {
+		return errors.New("scheduler requires parameter bestEffortReservedUploadRatio")
+	}
+
+	if cfg.Scheduler.UploadBandwidth.PeerWatermark < 0 {
+		return errors.New("scheduler requires parameter peerWatermark")
+	}
+
+	if cfg.Scheduler.UploadBandwidth.SeedPeerWatermark < 0 {
+		return errors.New("scheduler requires parameter seedPeerWatermark")
+	}
+
+	if cfg.Scheduler.Snapshot.Enable && cfg.Scheduler.Snapshot.Interval <= 0 {
+		return errors.New("scheduler requires parameter snapshot interval")
+	}
+
+	if cfg.Scheduler.RateLimit.Enable {
+		if cfg.Scheduler.RateLimit.Limit <= 0 {
+			return errors.New("scheduler requires parameter rateLimit limit")
+		}
+
+		if cfg.Scheduler.RateLimit.Burst <= 0 {
+			return errors.New("scheduler requires parameter rateLimit burst")
+		}
+	}
+
 	if cfg.DynConfig.RefreshInterval <= 0 {
 		return errors.New("dynconfig requires parameter refreshInterval")
 	}
@@ -521,6 +948,26 @@ func (cfg *Config) Validate() error {
 		return errors.New("manager requires parameter keepAlive interval")
 	}
 
+	if cfg.Manager.KeepAlive.MaxConsecutiveFailures < 0 {
+		return errors.New("manager requires parameter keepAlive maxConsecutiveFailures")
+	}
+
+	if cfg.Manager.Registration.MaxAttempts <= 0 {
+		return errors.New("manager requires parameter registration maxAttempts")
+	}
+
+	if cfg.Manager.Registration.InitBackoff <= 0 {
+		return errors.New("manager requires parameter registration initBackoff")
+	}
+
+	if cfg.Manager.Registration.MaxBackoff < cfg.Manager.Registration.InitBackoff {
+		return errors.New("manager requires parameter registration maxBackoff")
+	}
+
+	if cfg.Manager.Registration.Jitter < 0 || cfg.Manager.Registration.Jitter > 1 {
+		return errors.New("manager requires parameter registration jitter")
+	}
+
 	if cfg.Job.Enable {
 		if cfg.Job.GlobalWorkerNum == 0 {
 			return errors.New("job requires parameter globalWorkerNum")
@@ -535,6 +982,10 @@ func (cfg *Config) Validate() error {
 		}
 	}
 
+	if cfg.Storage.Driver != StorageDriverFile && cfg.Storage.Driver != StorageDriverSQLite {
+		return errors.New("storage requires parameter driver")
+	}
+
 	if cfg.Storage.MaxSize <= 0 {
 		return errors.New("storage requires parameter maxSize")
 	}
@@ -547,12 +998,44 @@ func (cfg *Config) Validate() error {
 		return errors.New("storage requires parameter bufferSize")
 	}
 
+	if cfg.Storage.MaxRecords < 0 {
+		return errors.New("storage requires parameter maxRecords")
+	}
+
+	if cfg.Storage.Encryption.Enable {
+		if cfg.Storage.Driver != StorageDriverFile {
+			return errors.New("storage encryption requires file driver")
+		}
+
+		if cfg.Storage.Encryption.KeyID == "" {
+			return errors.New("storage encryption requires parameter keyID")
+		}
+
+		if len(cfg.Storage.Encryption.Keys) == 0 {
+			return errors.New("storage encryption requires parameter keys")
+		}
+
+		for _, key := range cfg.Storage.Encryption.Keys {
+			if key.ID == "" {
+				return errors.New("storage encryption key requires parameter id")
+			}
+
+			if key.File == "" && key.Env == "" {
+				return errors.New("storage encryption key requires parameter file or env")
+			}
+		}
+	}
+
 	if cfg.Metrics.Enable {
 		if cfg.Metrics.Addr == "" {
 			return errors.New("metrics requires parameter addr")
 		}
 	}
 
+	if cfg.Metrics.SeedPeerSaturation.Enable && cfg.Metrics.SeedPeerSaturation.Interval <= 0 {
+		return errors.New("metrics requires parameter seedPeerSaturation interval")
+	}
+
 	if cfg.Security.AutoIssueCert {
 		if cfg.Security.CACert == "" {
 			return errors.New("security requires parameter caCert")
@@ -591,6 +1074,30 @@ func (cfg *Config) Validate() error {
 		return errors.New("probe requires parameter syncCount")
 	}
 
+	if cfg.PeerExchange.Enable {
+		if cfg.PeerExchange.Interval <= 0 {
+			return errors.New("peerExchange requires parameter interval")
+		}
+
+		if cfg.PeerExchange.MaxPeers <= 0 {
+			return errors.New("peerExchange requires parameter maxPeers")
+		}
+	}
+
+	if cfg.Inventory.Enable {
+		if cfg.Inventory.Interval <= 0 {
+			return errors.New("inventory requires parameter interval")
+		}
+
+		if cfg.Inventory.UnhealthyTimeout <= 0 {
+			return errors.New("inventory requires parameter unhealthyTimeout")
+		}
+
+		if cfg.Inventory.MaxPeers <= 0 {
+			return errors.New("inventory requires parameter maxPeers")
+		}
+	}
+
 	if cfg.Trainer.Enable {
 		if cfg.Trainer.Addr == "" {
 			return errors.New("trainer requires parameter addr")
@@ -600,9 +1107,133 @@ func (cfg *Config) Validate() error {
 			return errors.New("trainer requires parameter interval")
 		}
 
+		if cfg.Trainer.StartupJitter < 0 || cfg.Trainer.StartupJitter > cfg.Trainer.Interval {
+			return errors.New("trainer requires parameter startupJitter")
+		}
+
 		if cfg.Trainer.UploadTimeout <= 0 {
 			return errors.New("trainer requires parameter uploadTimeout")
 		}
+
+		if cfg.Trainer.UploadTimeoutWarningRatio < 0 || cfg.Trainer.UploadTimeoutWarningRatio >= 1 {
+			return errors.New("trainer requires parameter uploadTimeoutWarningRatio")
+		}
+
+		if cfg.Trainer.StreamOpenTimeout < 0 {
+			return errors.New("trainer requires parameter streamOpenTimeout")
+		}
+
+		if cfg.Trainer.UploadSegmentSize < 0 {
+			return errors.New("trainer requires parameter uploadSegmentSize")
+		}
+
+		if cfg.Trainer.MinUploadBytes < 0 {
+			return errors.New("trainer requires parameter minUploadBytes")
+		}
+
+		if cfg.Trainer.MaxMessageSize < 0 {
+			return errors.New("trainer requires parameter maxMessageSize")
+		}
+
+		if cfg.Trainer.UploadBatchSize < 0 {
+			return errors.New("trainer requires parameter uploadBatchSize")
+		}
+
+		if cfg.Trainer.FinalizePolicy != TrainerFinalizePolicyPessimistic &&
+			cfg.Trainer.FinalizePolicy != TrainerFinalizePolicyOptimistic {
+			return errors.New("trainer requires parameter finalizePolicy")
+		}
+
+		if cfg.Trainer.FinalizeRetries < 0 {
+			return errors.New("trainer requires parameter finalizeRetries")
+		}
+
+		if cfg.Trainer.FullSyncInterval < 0 {
+			return errors.New("trainer requires parameter fullSyncInterval")
+		}
+
+		if cfg.Trainer.CycleTimeout < 0 {
+			return errors.New("trainer requires parameter cycleTimeout")
+		}
+
+		if cfg.Trainer.CycleMaxBytes < 0 {
+			return errors.New("trainer requires parameter cycleMaxBytes")
+		}
+
+		if cfg.Trainer.CycleBudgetPolicy != TrainerCycleBudgetPolicyFinalize &&
+			cfg.Trainer.CycleBudgetPolicy != TrainerCycleBudgetPolicyAbort {
+			return errors.New("trainer requires parameter cycleBudgetPolicy")
+		}
+
+		if cfg.Trainer.SampleRate <= 0 || cfg.Trainer.SampleRate > 1 {
+			return errors.New("trainer requires parameter sampleRate")
+		}
+
+		if cfg.Trainer.SamplePolicy != TrainerSamplePolicyRate &&
+			cfg.Trainer.SamplePolicy != TrainerSamplePolicyStratified {
+			return errors.New("trainer requires parameter samplePolicy")
+		}
+
+		if cfg.Trainer.UploadPolicy != TrainerUploadPolicyAllOrNothing &&
+			cfg.Trainer.UploadPolicy != TrainerUploadPolicyBestEffort {
+			return errors.New("trainer requires parameter uploadPolicy")
+		}
+
+		if cfg.Trainer.AdaptiveInterval {
+			if cfg.Trainer.MinInterval <= 0 {
+				return errors.New("trainer requires parameter minInterval")
+			}
+
+			if cfg.Trainer.MaxInterval < cfg.Trainer.MinInterval {
+				return errors.New("trainer requires parameter maxInterval")
+			}
+
+			if cfg.Trainer.TargetRecords <= 0 {
+				return errors.New("trainer requires parameter targetRecords")
+			}
+
+			if cfg.Trainer.IntervalSensitivity <= 0 || cfg.Trainer.IntervalSensitivity > 1 {
+				return errors.New("trainer requires parameter intervalSensitivity")
+			}
+		}
+
+		if cfg.Trainer.BreakerFailureThreshold < 0 {
+			return errors.New("trainer requires parameter breakerFailureThreshold")
+		}
+
+		if cfg.Trainer.BreakerFailureThreshold > 0 && cfg.Trainer.BreakerCooldown <= 0 {
+			return errors.New("trainer requires parameter breakerCooldown")
+		}
+
+		if cfg.Trainer.RedactIPs && cfg.Trainer.RedactIPsSalt == "" {
+			return errors.New("trainer requires parameter redactIPsSalt")
+		}
+
+		if cfg.Trainer.SignDatasets && cfg.Trainer.SigningKeyFile == "" {
+			return errors.New("trainer requires parameter signingKeyFile")
+		}
+
+		if cfg.Trainer.UploadSink != TrainerUploadSinkGRPC &&
+			cfg.Trainer.UploadSink != TrainerUploadSinkHTTP {
+			return errors.New("trainer requires parameter uploadSink")
+		}
+
+		if cfg.Trainer.UploadSink == TrainerUploadSinkHTTP && cfg.Trainer.HTTPUpload.Endpoint == "" {
+			return errors.New("trainer requires parameter httpUpload endpoint")
+		}
+
+		if cfg.Trainer.HTTPUpload.ChunkRetries < 0 {
+			return errors.New("trainer requires parameter httpUpload chunkRetries")
+		}
+
+		// The records may be truncated by segments and cycle budgets, which can not be redacted.
+		if cfg.Trainer.RedactIPs && cfg.Trainer.UploadSegmentSize > 0 {
+			return errors.New("trainer redactIPs requires parameter uploadSegmentSize to be zero")
+		}
+
+		if cfg.Trainer.RedactIPs && (cfg.Trainer.CycleTimeout > 0 || cfg.Trainer.CycleMaxBytes > 0) {
+			return errors.New("trainer redactIPs requires parameter cycleTimeout and cycleMaxBytes to be zero")
+		}
 	}
 
 	return nil
@@ -27,6 +27,7 @@ import (
 
 	"d7y.io/dragonfly/v2/pkg/rpc"
 	"d7y.io/dragonfly/v2/pkg/types"
+	"d7y.io/dragonfly/v2/pkg/unit"
 )
 
 var (
@@ -36,6 +37,12 @@ var (
 		KeepAlive: KeepAliveConfig{
 			Interval: DefaultManagerKeepAliveInterval,
 		},
+		Registration: RegistrationConfig{
+			MaxAttempts: DefaultManagerRegistrationMaxAttempts,
+			InitBackoff: DefaultManagerRegistrationInitBackoff,
+			MaxBackoff:  DefaultManagerRegistrationMaxBackoff,
+			Jitter:      DefaultManagerRegistrationJitter,
+		},
 	}
 
 	mockJobConfig = JobConfig{
@@ -87,19 +94,40 @@ func TestConfig_Load(t *testing.T) {
 				TaskGCInterval:       30 * time.Second,
 				HostGCInterval:       1 * time.Minute,
 				HostTTL:              1 * time.Minute,
+				TaskTTL:              1 * time.Hour,
+				MaxTaskCount:         10000,
+			},
+			PriorityClass: PriorityClassConfig{
+				NormalReservedUploadRatio:     0.1,
+				BestEffortReservedUploadRatio: 0.3,
+			},
+			UploadBandwidth: UploadBandwidthConfig{
+				PeerWatermark:     100 * unit.MB,
+				SeedPeerWatermark: unit.GB,
+			},
+			Snapshot: SnapshotConfig{
+				Enable:   true,
+				Interval: 10 * time.Second,
+			},
+			RateLimit: RateLimitConfig{
+				Enable: true,
+				Limit:  20,
+				Burst:  40,
 			},
 		},
 		Server: ServerConfig{
-			AdvertiseIP:   net.ParseIP("127.0.0.1"),
-			AdvertisePort: 8004,
-			ListenIP:      net.ParseIP("0.0.0.0"),
-			Port:          8002,
-			Host:          "foo",
-			WorkHome:      "foo",
-			CacheDir:      "foo",
-			LogDir:        "foo",
-			PluginDir:     "foo",
-			DataDir:       "foo",
+			AdvertiseIP:          net.ParseIP("127.0.0.1"),
+			SecondaryAdvertiseIP: net.ParseIP("::1"),
+			AdvertisePort:        8004,
+			ListenIP:             net.ParseIP("0.0.0.0"),
+			Port:                 8002,
+			Host:                 "foo",
+			WorkHome:             "foo",
+			CacheDir:             "foo",
+			LogDir:               "foo",
+			PluginDir:            "foo",
+			DataDir:              "foo",
+			DrainTimeout:         1 * time.Minute,
 		},
 		Database: DatabaseConfig{
 			Redis: RedisConfig{
@@ -118,9 +146,18 @@ func TestConfig_Load(t *testing.T) {
 		},
 		Manager: ManagerConfig{
 			Addr:               "127.0.0.1:65003",
+			Addrs:              []string{"127.0.0.1:65013", "127.0.0.1:65023"},
 			SchedulerClusterID: 1,
 			KeepAlive: KeepAliveConfig{
-				Interval: 5 * time.Second,
+				Interval:               5 * time.Second,
+				MaxConsecutiveFailures: 3,
+			},
+			Registration: RegistrationConfig{
+				MaxAttempts: 5,
+				InitBackoff: time.Second,
+				MaxBackoff:  10 * time.Second,
+				Jitter:      0.2,
+				Degraded:    true,
 			},
 		},
 		SeedPeer: SeedPeerConfig{
@@ -137,14 +174,20 @@ func TestConfig_Load(t *testing.T) {
 			LocalWorkerNum:     5,
 		},
 		Storage: StorageConfig{
+			Driver:     "sqlite",
 			MaxSize:    1,
 			MaxBackups: 1,
 			BufferSize: 1,
+			MaxRecords: 1000,
 		},
 		Metrics: MetricsConfig{
 			Enable:     false,
 			Addr:       ":8000",
 			EnableHost: true,
+			SeedPeerSaturation: SeedPeerSaturationConfig{
+				Enable:   true,
+				Interval: time.Minute,
+			},
 		},
 		Security: SecurityConfig{
 			AutoIssueCert: true,
@@ -169,11 +212,57 @@ func TestConfig_Load(t *testing.T) {
 				SyncCount:    10,
 			},
 		},
+		PeerExchange: PeerExchangeConfig{
+			Enable:   true,
+			Interval: 30 * time.Second,
+			MaxPeers: 10,
+		},
+		Inventory: InventoryConfig{
+			Enable:           true,
+			Interval:         time.Minute,
+			UnhealthyTimeout: 10 * time.Minute,
+			MaxPeers:         1000,
+		},
 		Trainer: TrainerConfig{
-			Enable:        false,
-			Addr:          "127.0.0.1:9000",
-			Interval:      10 * time.Minute,
-			UploadTimeout: 2 * time.Hour,
+			Enable:                    false,
+			Addr:                      "127.0.0.1:9000",
+			ClusterID:                 2,
+			Interval:                  10 * time.Minute,
+			AdaptiveInterval:          true,
+			MinInterval:               1 * time.Minute,
+			MaxInterval:               1 * time.Hour,
+			TargetRecords:             10000,
+			IntervalSensitivity:       0.8,
+			StartupJitter:             5 * time.Minute,
+			UploadTimeout:             2 * time.Hour,
+			UploadTimeoutWarningRatio: 0.9,
+			StreamOpenTimeout:         30 * time.Second,
+			UploadSegmentSize:         1048576,
+			MaxMessageSize:            1048576,
+			UploadBatchSize:           65536,
+			MinUploadBytes:            1024,
+			UploadDownload:            true,
+			UploadNetworkTopology:     false,
+			SampleRate:                0.5,
+			SamplePolicy:              "stratified",
+			FinalizePolicy:            "optimistic",
+			FinalizeRetries:           2,
+			FullSyncInterval:          12 * time.Hour,
+			CycleTimeout:              2 * time.Minute,
+			CycleMaxBytes:             524288000,
+			CycleBudgetPolicy:         "abort",
+			UploadPolicy:              "best-effort",
+			BreakerFailureThreshold:   3,
+			BreakerCooldown:           time.Hour,
+			RedactIPs:                 true,
+			RedactIPsSalt:             "foo",
+			SignDatasets:              true,
+			SigningKeyFile:            "/etc/dragonfly/scheduler/signing.key",
+			UploadSink:                "http",
+			HTTPUpload: TrainerHTTPUploadConfig{
+				Endpoint:     "https://127.0.0.1:9443/api/v1",
+				ChunkRetries: 5,
+			},
 		},
 	}
 
@@ -220,6 +309,20 @@ func TestConfig_Validate(t *testing.T) {
 				assert.EqualError(err, "server requires parameter advertiseIP")
 			},
 		},
+		{
+			name:   "server requires parameter secondaryAdvertiseIP in the other address family",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Job = mockJobConfig
+				cfg.Server.AdvertiseIP = net.ParseIP("127.0.0.1")
+				cfg.Server.SecondaryAdvertiseIP = net.ParseIP("127.0.0.2")
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "server requires parameter secondaryAdvertiseIP in the other address family than advertiseIP")
+			},
+		},
 		{
 			name:   "server requires parameter listenIP",
 			config: New(),
@@ -259,6 +362,19 @@ func TestConfig_Validate(t *testing.T) {
 				assert.EqualError(err, "server requires parameter host")
 			},
 		},
+		{
+			name:   "server requires parameter drainTimeout",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Job = mockJobConfig
+				cfg.Server.DrainTimeout = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "server requires parameter drainTimeout")
+			},
+		},
 		{
 			name:   "redis requires parameter addrs",
 			config: New(),
@@ -465,6 +581,136 @@ func TestConfig_Validate(t *testing.T) {
 				assert.EqualError(err, "scheduler requires parameter hostTTL")
 			},
 		},
+		{
+			name:   "scheduler requires parameter taskTTL",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Scheduler.GC.TaskTTL = -1
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "scheduler requires parameter taskTTL")
+			},
+		},
+		{
+			name:   "scheduler requires parameter maxTaskCount",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Scheduler.GC.MaxTaskCount = -1
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "scheduler requires parameter maxTaskCount")
+			},
+		},
+		{
+			name:   "scheduler requires parameter normalReservedUploadRatio",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Scheduler.PriorityClass.NormalReservedUploadRatio = 1
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "scheduler requires parameter normalReservedUploadRatio")
+			},
+		},
+		{
+			name:   "scheduler requires parameter bestEffortReservedUploadRatio",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Scheduler.PriorityClass.NormalReservedUploadRatio = 0.5
+				cfg.Scheduler.PriorityClass.BestEffortReservedUploadRatio = 0.2
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "scheduler requires parameter bestEffortReservedUploadRatio")
+			},
+		},
+		{
+			name:   "scheduler requires parameter peerWatermark",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Scheduler.UploadBandwidth.PeerWatermark = -1
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "scheduler requires parameter peerWatermark")
+			},
+		},
+		{
+			name:   "scheduler requires parameter seedPeerWatermark",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Scheduler.UploadBandwidth.SeedPeerWatermark = -1
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "scheduler requires parameter seedPeerWatermark")
+			},
+		},
+		{
+			name:   "scheduler requires parameter snapshot interval",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Scheduler.Snapshot.Enable = true
+				cfg.Scheduler.Snapshot.Interval = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "scheduler requires parameter snapshot interval")
+			},
+		},
+		{
+			name:   "scheduler requires parameter rateLimit limit",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Scheduler.RateLimit.Enable = true
+				cfg.Scheduler.RateLimit.Limit = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "scheduler requires parameter rateLimit limit")
+			},
+		},
+		{
+			name:   "scheduler requires parameter rateLimit burst",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Scheduler.RateLimit.Enable = true
+				cfg.Scheduler.RateLimit.Burst = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "scheduler requires parameter rateLimit burst")
+			},
+		},
 		{
 			name:   "dynconfig requires parameter refreshInterval",
 			config: New(),
@@ -521,6 +767,76 @@ func TestConfig_Validate(t *testing.T) {
 				assert.EqualError(err, "manager requires parameter keepAlive interval")
 			},
 		},
+		{
+			name:   "manager requires parameter keepAlive maxConsecutiveFailures",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Manager.KeepAlive.MaxConsecutiveFailures = -1
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "manager requires parameter keepAlive maxConsecutiveFailures")
+			},
+		},
+		{
+			name:   "manager requires parameter registration maxAttempts",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Manager.Registration.MaxAttempts = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "manager requires parameter registration maxAttempts")
+			},
+		},
+		{
+			name:   "manager requires parameter registration initBackoff",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Manager.Registration.InitBackoff = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "manager requires parameter registration initBackoff")
+			},
+		},
+		{
+			name:   "manager requires parameter registration maxBackoff",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Manager.Registration.MaxBackoff = time.Millisecond
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "manager requires parameter registration maxBackoff")
+			},
+		},
+		{
+			name:   "manager requires parameter registration jitter",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Manager.Registration.Jitter = 1.5
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "manager requires parameter registration jitter")
+			},
+		},
 		{
 			name:   "job requires parameter globalWorkerNum",
 			config: New(),
@@ -606,194 +922,816 @@ func TestConfig_Validate(t *testing.T) {
 			},
 		},
 		{
-			name:   "metrics requires parameter addr",
+			name:   "storage requires parameter maxRecords",
 			config: New(),
 			mock: func(cfg *Config) {
 				cfg.Manager = mockManagerConfig
 				cfg.Database.Redis = mockRedisConfig
 				cfg.Job = mockJobConfig
-				cfg.Metrics = mockMetricsConfig
-				cfg.Metrics.Addr = ""
+				cfg.Storage.MaxRecords = -1
 			},
 			expect: func(t *testing.T, err error) {
 				assert := assert.New(t)
-				assert.EqualError(err, "metrics requires parameter addr")
+				assert.EqualError(err, "storage requires parameter maxRecords")
 			},
 		},
 		{
-			name:   "security requires parameter caCert",
+			name:   "storage requires parameter driver",
 			config: New(),
 			mock: func(cfg *Config) {
 				cfg.Manager = mockManagerConfig
 				cfg.Database.Redis = mockRedisConfig
 				cfg.Job = mockJobConfig
-				cfg.Security = mockSecurityConfig
-				cfg.Security.CACert = ""
+				cfg.Storage.Driver = "foo"
 			},
 			expect: func(t *testing.T, err error) {
 				assert := assert.New(t)
-				assert.EqualError(err, "security requires parameter caCert")
+				assert.EqualError(err, "storage requires parameter driver")
 			},
 		},
 		{
-			name:   "security requires parameter tlsPolicy",
+			name:   "storage encryption requires file driver",
 			config: New(),
 			mock: func(cfg *Config) {
 				cfg.Manager = mockManagerConfig
 				cfg.Database.Redis = mockRedisConfig
 				cfg.Job = mockJobConfig
-				cfg.Security = mockSecurityConfig
-				cfg.Security.TLSPolicy = ""
+				cfg.Storage.Driver = StorageDriverSQLite
+				cfg.Storage.Encryption.Enable = true
 			},
 			expect: func(t *testing.T, err error) {
 				assert := assert.New(t)
-				assert.EqualError(err, "security requires parameter tlsPolicy")
+				assert.EqualError(err, "storage encryption requires file driver")
 			},
 		},
 		{
-			name:   "certSpec requires parameter ipAddresses",
+			name:   "storage encryption requires parameter keyID",
 			config: New(),
 			mock: func(cfg *Config) {
 				cfg.Manager = mockManagerConfig
 				cfg.Database.Redis = mockRedisConfig
 				cfg.Job = mockJobConfig
-				cfg.Security = mockSecurityConfig
-				cfg.Security.CertSpec.IPAddresses = []net.IP{}
+				cfg.Storage.Encryption = StorageEncryptionConfig{Enable: true}
 			},
 			expect: func(t *testing.T, err error) {
 				assert := assert.New(t)
-				assert.EqualError(err, "certSpec requires parameter ipAddresses")
+				assert.EqualError(err, "storage encryption requires parameter keyID")
 			},
 		},
 		{
-			name:   "certSpec requires parameter dnsNames",
+			name:   "storage encryption requires parameter keys",
 			config: New(),
 			mock: func(cfg *Config) {
 				cfg.Manager = mockManagerConfig
 				cfg.Database.Redis = mockRedisConfig
 				cfg.Job = mockJobConfig
-				cfg.Security = mockSecurityConfig
-				cfg.Security.CertSpec.DNSNames = []string{}
+				cfg.Storage.Encryption = StorageEncryptionConfig{Enable: true, KeyID: "foo"}
 			},
 			expect: func(t *testing.T, err error) {
 				assert := assert.New(t)
-				assert.EqualError(err, "certSpec requires parameter dnsNames")
+				assert.EqualError(err, "storage encryption requires parameter keys")
 			},
 		},
 		{
-			name:   "certSpec requires parameter validityPeriod",
+			name:   "storage encryption key requires parameter file or env",
 			config: New(),
 			mock: func(cfg *Config) {
 				cfg.Manager = mockManagerConfig
 				cfg.Database.Redis = mockRedisConfig
 				cfg.Job = mockJobConfig
-				cfg.Security = mockSecurityConfig
-				cfg.Security.CertSpec.ValidityPeriod = 0
+				cfg.Storage.Encryption = StorageEncryptionConfig{Enable: true, KeyID: "foo", Keys: []StorageEncryptionKeyConfig{{ID: "foo"}}}
 			},
 			expect: func(t *testing.T, err error) {
 				assert := assert.New(t)
-				assert.EqualError(err, "certSpec requires parameter validityPeriod")
+				assert.EqualError(err, "storage encryption key requires parameter file or env")
 			},
 		},
 		{
-			name:   "networkTopology requires parameter collectInterval",
+			name:   "metrics requires parameter addr",
 			config: New(),
 			mock: func(cfg *Config) {
 				cfg.Manager = mockManagerConfig
 				cfg.Database.Redis = mockRedisConfig
 				cfg.Job = mockJobConfig
-				cfg.NetworkTopology.CollectInterval = 0
+				cfg.Metrics = mockMetricsConfig
+				cfg.Metrics.Addr = ""
 			},
 			expect: func(t *testing.T, err error) {
 				assert := assert.New(t)
-				assert.EqualError(err, "networkTopology requires parameter collectInterval")
+				assert.EqualError(err, "metrics requires parameter addr")
 			},
 		},
 		{
-			name:   "probe requires parameter queueLength",
+			name:   "metrics requires parameter seedPeerSaturation interval",
 			config: New(),
 			mock: func(cfg *Config) {
 				cfg.Manager = mockManagerConfig
 				cfg.Database.Redis = mockRedisConfig
 				cfg.Job = mockJobConfig
-				cfg.NetworkTopology.Probe.QueueLength = 0
+				cfg.Metrics.SeedPeerSaturation.Enable = true
+				cfg.Metrics.SeedPeerSaturation.Interval = 0
 			},
 			expect: func(t *testing.T, err error) {
 				assert := assert.New(t)
-				assert.EqualError(err, "probe requires parameter queueLength")
+				assert.EqualError(err, "metrics requires parameter seedPeerSaturation interval")
 			},
 		},
 		{
-			name:   "probe requires parameter SyncInterval",
+			name:   "security requires parameter caCert",
 			config: New(),
 			mock: func(cfg *Config) {
 				cfg.Manager = mockManagerConfig
 				cfg.Database.Redis = mockRedisConfig
 				cfg.Job = mockJobConfig
-				cfg.NetworkTopology.Probe.SyncInterval = 0
+				cfg.Security = mockSecurityConfig
+				cfg.Security.CACert = ""
 			},
 			expect: func(t *testing.T, err error) {
 				assert := assert.New(t)
-				assert.EqualError(err, "probe requires parameter syncInterval")
+				assert.EqualError(err, "security requires parameter caCert")
 			},
 		},
 		{
-			name:   "probe requires parameter SyncCount",
+			name:   "security requires parameter tlsPolicy",
 			config: New(),
 			mock: func(cfg *Config) {
 				cfg.Manager = mockManagerConfig
 				cfg.Database.Redis = mockRedisConfig
 				cfg.Job = mockJobConfig
-				cfg.NetworkTopology.Probe.SyncCount = 0
+				cfg.Security = mockSecurityConfig
+				cfg.Security.TLSPolicy = ""
 			},
 			expect: func(t *testing.T, err error) {
 				assert := assert.New(t)
-				assert.EqualError(err, "probe requires parameter syncCount")
+				assert.EqualError(err, "security requires parameter tlsPolicy")
 			},
 		},
 		{
-			name:   "trainer requires parameter addr",
+			name:   "certSpec requires parameter ipAddresses",
 			config: New(),
 			mock: func(cfg *Config) {
 				cfg.Manager = mockManagerConfig
 				cfg.Database.Redis = mockRedisConfig
 				cfg.Job = mockJobConfig
-				cfg.Trainer.Enable = true
-				cfg.Trainer.Addr = ""
+				cfg.Security = mockSecurityConfig
+				cfg.Security.CertSpec.IPAddresses = []net.IP{}
 			},
 			expect: func(t *testing.T, err error) {
 				assert := assert.New(t)
-				assert.EqualError(err, "trainer requires parameter addr")
+				assert.EqualError(err, "certSpec requires parameter ipAddresses")
 			},
 		},
 		{
-			name:   "trainer requires parameter interval",
+			name:   "certSpec requires parameter dnsNames",
 			config: New(),
 			mock: func(cfg *Config) {
 				cfg.Manager = mockManagerConfig
 				cfg.Database.Redis = mockRedisConfig
 				cfg.Job = mockJobConfig
-				cfg.Trainer.Enable = true
-				cfg.Trainer.Interval = 0
+				cfg.Security = mockSecurityConfig
+				cfg.Security.CertSpec.DNSNames = []string{}
 			},
 			expect: func(t *testing.T, err error) {
 				assert := assert.New(t)
-				assert.EqualError(err, "trainer requires parameter interval")
+				assert.EqualError(err, "certSpec requires parameter dnsNames")
 			},
 		},
 		{
-			name:   "trainer requires parameter interval",
+			name:   "certSpec requires parameter validityPeriod",
 			config: New(),
 			mock: func(cfg *Config) {
 				cfg.Manager = mockManagerConfig
 				cfg.Database.Redis = mockRedisConfig
 				cfg.Job = mockJobConfig
-				cfg.Trainer.Enable = true
-				cfg.Trainer.UploadTimeout = 0
+				cfg.Security = mockSecurityConfig
+				cfg.Security.CertSpec.ValidityPeriod = 0
 			},
 			expect: func(t *testing.T, err error) {
 				assert := assert.New(t)
-				assert.EqualError(err, "trainer requires parameter uploadTimeout")
+				assert.EqualError(err, "certSpec requires parameter validityPeriod")
+			},
+		},
+		{
+			name:   "networkTopology requires parameter collectInterval",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.NetworkTopology.CollectInterval = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "networkTopology requires parameter collectInterval")
+			},
+		},
+		{
+			name:   "probe requires parameter queueLength",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.NetworkTopology.Probe.QueueLength = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "probe requires parameter queueLength")
+			},
+		},
+		{
+			name:   "probe requires parameter SyncInterval",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.NetworkTopology.Probe.SyncInterval = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "probe requires parameter syncInterval")
+			},
+		},
+		{
+			name:   "probe requires parameter SyncCount",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.NetworkTopology.Probe.SyncCount = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "probe requires parameter syncCount")
+			},
+		},
+		{
+			name:   "trainer requires parameter addr",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.Addr = ""
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer requires parameter addr")
+			},
+		},
+		{
+			name:   "trainer requires parameter interval",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.Interval = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer requires parameter interval")
+			},
+		},
+		{
+			name:   "trainer requires parameter interval",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.UploadTimeout = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer requires parameter uploadTimeout")
+			},
+		},
+		{
+			name:   "trainer requires parameter uploadTimeoutWarningRatio",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.UploadTimeoutWarningRatio = 1
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer requires parameter uploadTimeoutWarningRatio")
+			},
+		},
+		{
+			name:   "trainer requires parameter streamOpenTimeout",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.StreamOpenTimeout = -1
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer requires parameter streamOpenTimeout")
+			},
+		},
+		{
+			name:   "trainer requires parameter uploadSegmentSize",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.UploadSegmentSize = -1
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer requires parameter uploadSegmentSize")
+			},
+		},
+		{
+			name:   "trainer requires parameter minUploadBytes",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.MinUploadBytes = -1
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer requires parameter minUploadBytes")
+			},
+		},
+		{
+			name:   "trainer requires parameter maxMessageSize",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.MaxMessageSize = -1
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer requires parameter maxMessageSize")
+			},
+		},
+		{
+			name:   "trainer requires parameter uploadBatchSize",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.UploadBatchSize = -1
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer requires parameter uploadBatchSize")
+			},
+		},
+		{
+			name:   "trainer requires parameter finalizePolicy",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.FinalizePolicy = "foo"
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer requires parameter finalizePolicy")
+			},
+		},
+		{
+			name:   "trainer requires parameter finalizeRetries",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.FinalizeRetries = -1
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer requires parameter finalizeRetries")
+			},
+		},
+		{
+			name:   "trainer requires parameter fullSyncInterval",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.FullSyncInterval = -1
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer requires parameter fullSyncInterval")
+			},
+		},
+		{
+			name:   "trainer requires parameter cycleTimeout",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.CycleTimeout = -1
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer requires parameter cycleTimeout")
+			},
+		},
+		{
+			name:   "trainer requires parameter cycleMaxBytes",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.CycleMaxBytes = -1
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer requires parameter cycleMaxBytes")
+			},
+		},
+		{
+			name:   "trainer requires parameter cycleBudgetPolicy",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.CycleBudgetPolicy = "foo"
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer requires parameter cycleBudgetPolicy")
+			},
+		},
+		{
+			name:   "peerExchange requires parameter interval",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.PeerExchange.Enable = true
+				cfg.PeerExchange.Interval = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "peerExchange requires parameter interval")
+			},
+		},
+		{
+			name:   "peerExchange requires parameter maxPeers",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.PeerExchange.Enable = true
+				cfg.PeerExchange.MaxPeers = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "peerExchange requires parameter maxPeers")
+			},
+		},
+		{
+			name:   "inventory requires parameter interval",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Inventory.Enable = true
+				cfg.Inventory.Interval = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "inventory requires parameter interval")
+			},
+		},
+		{
+			name:   "inventory requires parameter unhealthyTimeout",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Inventory.Enable = true
+				cfg.Inventory.UnhealthyTimeout = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "inventory requires parameter unhealthyTimeout")
+			},
+		},
+		{
+			name:   "inventory requires parameter maxPeers",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Inventory.Enable = true
+				cfg.Inventory.MaxPeers = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "inventory requires parameter maxPeers")
+			},
+		},
+		{
+			name:   "trainer requires parameter sampleRate",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.SampleRate = 0
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer requires parameter sampleRate")
+			},
+		},
+		{
+			name:   "trainer requires parameter samplePolicy",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.SamplePolicy = "foo"
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer requires parameter samplePolicy")
+			},
+		},
+		{
+			name:   "trainer requires parameter uploadPolicy",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.UploadPolicy = "foo"
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer requires parameter uploadPolicy")
+			},
+		},
+		{
+			name:   "trainer requires parameter startupJitter",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.StartupJitter = cfg.Trainer.Interval + time.Second
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer requires parameter startupJitter")
+			},
+		},
+		{
+			name:   "trainer requires parameter breakerFailureThreshold",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.BreakerFailureThreshold = -1
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer requires parameter breakerFailureThreshold")
+			},
+		},
+		{
+			name:   "trainer requires parameter breakerCooldown",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.BreakerFailureThreshold = 1
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer requires parameter breakerCooldown")
+			},
+		},
+		{
+			name:   "trainer requires parameter redactIPsSalt",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.RedactIPs = true
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer requires parameter redactIPsSalt")
+			},
+		},
+		{
+			name:   "trainer requires parameter signingKeyFile",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.SignDatasets = true
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer requires parameter signingKeyFile")
+			},
+		},
+		{
+			name:   "trainer requires parameter uploadSink",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.UploadSink = "foo"
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer requires parameter uploadSink")
+			},
+		},
+		{
+			name:   "trainer requires parameter httpUpload endpoint",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.UploadSink = TrainerUploadSinkHTTP
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer requires parameter httpUpload endpoint")
+			},
+		},
+		{
+			name:   "trainer requires parameter httpUpload chunkRetries",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.HTTPUpload.ChunkRetries = -1
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer requires parameter httpUpload chunkRetries")
+			},
+		},
+		{
+			name:   "trainer requires parameter minInterval",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.AdaptiveInterval = true
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer requires parameter minInterval")
+			},
+		},
+		{
+			name:   "trainer requires parameter maxInterval",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.AdaptiveInterval = true
+				cfg.Trainer.MinInterval = time.Hour
+				cfg.Trainer.MaxInterval = time.Minute
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer requires parameter maxInterval")
+			},
+		},
+		{
+			name:   "trainer requires parameter targetRecords",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.AdaptiveInterval = true
+				cfg.Trainer.MinInterval = time.Minute
+				cfg.Trainer.MaxInterval = time.Hour
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer requires parameter targetRecords")
+			},
+		},
+		{
+			name:   "trainer requires parameter intervalSensitivity",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.AdaptiveInterval = true
+				cfg.Trainer.MinInterval = time.Minute
+				cfg.Trainer.MaxInterval = time.Hour
+				cfg.Trainer.TargetRecords = 1
+				cfg.Trainer.IntervalSensitivity = 2
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer requires parameter intervalSensitivity")
+			},
+		},
+		{
+			name:   "trainer redactIPs requires parameter uploadSegmentSize to be zero",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.RedactIPs = true
+				cfg.Trainer.RedactIPsSalt = "foo"
+				cfg.Trainer.UploadSegmentSize = 1024
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer redactIPs requires parameter uploadSegmentSize to be zero")
+			},
+		},
+		{
+			name:   "trainer redactIPs requires parameter cycleTimeout and cycleMaxBytes to be zero",
+			config: New(),
+			mock: func(cfg *Config) {
+				cfg.Manager = mockManagerConfig
+				cfg.Database.Redis = mockRedisConfig
+				cfg.Job = mockJobConfig
+				cfg.Trainer.Enable = true
+				cfg.Trainer.RedactIPs = true
+				cfg.Trainer.RedactIPsSalt = "foo"
+				cfg.Trainer.CycleMaxBytes = 1024
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "trainer redactIPs requires parameter cycleTimeout and cycleMaxBytes to be zero")
 			},
 		},
 	}
@@ -0,0 +1,110 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/manager/types"
+	pkgredis "d7y.io/dragonfly/v2/pkg/redis"
+)
+
+const (
+	// configVersionReportExpireIntervals is the number of notify intervals after which
+	// the config version report published to manager is expired.
+	configVersionReportExpireIntervals = 3
+)
+
+// ConfigVersionReporter reports the digests of cluster configurations running in scheduler to manager,
+// then manager resolves the versions of configurations and finds the drifted schedulers.
+type ConfigVersionReporter struct {
+	// hostname is the hostname of scheduler.
+	hostname string
+
+	// ip is the advertise ip of scheduler.
+	ip string
+
+	// rdb is Redis universal client interface.
+	rdb redis.UniversalClient
+}
+
+// NewConfigVersionReporter returns a new config version reporter.
+func NewConfigVersionReporter(hostname, ip string, rdb redis.UniversalClient) *ConfigVersionReporter {
+	return &ConfigVersionReporter{
+		hostname: hostname,
+		ip:       ip,
+		rdb:      rdb,
+	}
+}
+
+// OnNotify publishes the config version reports when dynconfig is notified.
+func (r *ConfigVersionReporter) OnNotify(data *DynconfigData) {
+	ctx, cancel := context.WithTimeout(context.Background(), watchInterval)
+	defer cancel()
+
+	for key, report := range makeConfigVersionReports(data, r.hostname, r.ip) {
+		b, err := json.Marshal(report)
+		if err != nil {
+			logger.Errorf("marshal config version report %s failed: %s", key, err.Error())
+			continue
+		}
+
+		if err := r.rdb.Set(ctx, key, b, configVersionReportExpireIntervals*watchInterval).Err(); err != nil {
+			logger.Errorf("publish config version report %s failed: %s", key, err.Error())
+		}
+	}
+}
+
+// makeConfigVersionReports makes the config version reports of scheduler cluster and seed peer clusters
+// used by scheduler, the key of map is the cache key of report in manager.
+func makeConfigVersionReports(data *DynconfigData, hostname, ip string) map[string]types.ConfigVersionReport {
+	reports := map[string]types.ConfigVersionReport{}
+	if data == nil || data.Scheduler == nil {
+		return reports
+	}
+
+	now := time.Now()
+	if schedulerCluster := data.Scheduler.SchedulerCluster; schedulerCluster != nil {
+		reports[pkgredis.MakeConfigVersionReportKeyInManager(types.ConfigVersionClusterTypeScheduler, uint(schedulerCluster.Id), hostname, ip)] = types.ConfigVersionReport{
+			Hostname:  hostname,
+			IP:        ip,
+			Digest:    types.MakeSchedulerClusterConfigDigest(schedulerCluster.Config, schedulerCluster.ClientConfig, schedulerCluster.Scopes),
+			UpdatedAt: now,
+		}
+	}
+
+	for _, seedPeer := range data.Scheduler.SeedPeers {
+		seedPeerCluster := seedPeer.SeedPeerCluster
+		if seedPeerCluster == nil {
+			continue
+		}
+
+		reports[pkgredis.MakeConfigVersionReportKeyInManager(types.ConfigVersionClusterTypeSeedPeer, uint(seedPeerCluster.Id), hostname, ip)] = types.ConfigVersionReport{
+			Hostname:  hostname,
+			IP:        ip,
+			Digest:    types.MakeSeedPeerClusterConfigDigest(seedPeerCluster.Config),
+			UpdatedAt: now,
+		}
+	}
+
+	return reports
+}
@@ -0,0 +1,100 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	managerv2 "d7y.io/api/pkg/apis/manager/v2"
+
+	managertypes "d7y.io/dragonfly/v2/manager/types"
+	pkgredis "d7y.io/dragonfly/v2/pkg/redis"
+)
+
+func TestConfigVersionReporter_makeConfigVersionReports(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   *DynconfigData
+		expect func(t *testing.T, reports map[string]managertypes.ConfigVersionReport)
+	}{
+		{
+			name: "make reports of scheduler cluster and seed peer clusters",
+			data: &DynconfigData{
+				Scheduler: &managerv2.Scheduler{
+					SchedulerCluster: &managerv2.SchedulerCluster{
+						Id:           1,
+						Config:       []byte(`{"filter_parent_limit":10}`),
+						ClientConfig: []byte(`{"load_limit":10}`),
+						Scopes:       []byte(`{}`),
+					},
+					SeedPeers: []*managerv2.SeedPeer{
+						{Hostname: "foo", SeedPeerCluster: &managerv2.SeedPeerCluster{Id: 2, Config: []byte(`{"load_limit":300}`)}},
+						{Hostname: "bar", SeedPeerCluster: &managerv2.SeedPeerCluster{Id: 2, Config: []byte(`{"load_limit":300}`)}},
+						{Hostname: "baz"},
+					},
+				},
+			},
+			expect: func(t *testing.T, reports map[string]managertypes.ConfigVersionReport) {
+				assert := assert.New(t)
+				assert.Len(reports, 2)
+
+				report, ok := reports[pkgredis.MakeConfigVersionReportKeyInManager(managertypes.ConfigVersionClusterTypeScheduler, 1, "localhost", "127.0.0.1")]
+				assert.True(ok)
+				assert.Equal("localhost", report.Hostname)
+				assert.Equal("127.0.0.1", report.IP)
+				assert.Equal(managertypes.MakeSchedulerClusterConfigDigest([]byte(`{"filter_parent_limit":10}`), []byte(`{"load_limit":10}`), []byte(`{}`)), report.Digest)
+				assert.False(report.UpdatedAt.IsZero())
+
+				report, ok = reports[pkgredis.MakeConfigVersionReportKeyInManager(managertypes.ConfigVersionClusterTypeSeedPeer, 2, "localhost", "127.0.0.1")]
+				assert.True(ok)
+				assert.Equal(managertypes.MakeSeedPeerClusterConfigDigest([]byte(`{"load_limit":300}`)), report.Digest)
+			},
+		},
+		{
+			name: "scheduler cluster changes digest",
+			data: &DynconfigData{
+				Scheduler: &managerv2.Scheduler{
+					SchedulerCluster: &managerv2.SchedulerCluster{
+						Id:     1,
+						Config: []byte(`{"filter_parent_limit":20}`),
+					},
+				},
+			},
+			expect: func(t *testing.T, reports map[string]managertypes.ConfigVersionReport) {
+				assert := assert.New(t)
+				report := reports[pkgredis.MakeConfigVersionReportKeyInManager(managertypes.ConfigVersionClusterTypeScheduler, 1, "localhost", "127.0.0.1")]
+				assert.NotEqual(managertypes.MakeSchedulerClusterConfigDigest([]byte(`{"filter_parent_limit":10}`), nil, nil), report.Digest)
+			},
+		},
+		{
+			name: "scheduler is empty",
+			data: &DynconfigData{},
+			expect: func(t *testing.T, reports map[string]managertypes.ConfigVersionReport) {
+				assert := assert.New(t)
+				assert.Empty(reports)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expect(t, makeConfigVersionReports(tc.data, "localhost", "127.0.0.1"))
+		})
+	}
+}
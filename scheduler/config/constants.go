@@ -46,6 +46,9 @@ const (
 
 	// DefaultServerAdvertisePort is default advertise port for server.
 	DefaultServerAdvertisePort = 8002
+
+	// DefaultServerDrainTimeout is default timeout of waiting for the in-flight peers when server is draining.
+	DefaultServerDrainTimeout = 5 * time.Minute
 )
 
 const (
@@ -93,6 +96,21 @@ const (
 	// DefaultSchedulerHostTTL is default ttl for host.
 	DefaultSchedulerHostTTL = 1 * time.Hour
 
+	// DefaultSchedulerNormalReservedUploadRatio is default ratio of parent's upload reserved from normal peers.
+	DefaultSchedulerNormalReservedUploadRatio = 0
+
+	// DefaultSchedulerBestEffortReservedUploadRatio is default ratio of parent's upload reserved from best-effort peers.
+	DefaultSchedulerBestEffortReservedUploadRatio = 0.2
+
+	// DefaultSchedulerSnapshotInterval is default interval of snapshotting resource.
+	DefaultSchedulerSnapshotInterval = 30 * time.Second
+
+	// DefaultSchedulerRateLimit is default number of tokens refilled per second for each client.
+	DefaultSchedulerRateLimit = 50
+
+	// DefaultSchedulerRateLimitBurst is default size of token bucket for each client.
+	DefaultSchedulerRateLimitBurst = 100
+
 	// DefaultRefreshModelInterval is model refresh interval.
 	DefaultRefreshModelInterval = 168 * time.Hour
 
@@ -111,6 +129,18 @@ const (
 
 	// DefaultManagerKeepAliveInterval is default interval for keepalive.
 	DefaultManagerKeepAliveInterval = 5 * time.Second
+
+	// DefaultManagerRegistrationMaxAttempts is default max attempts for registration.
+	DefaultManagerRegistrationMaxAttempts = 3
+
+	// DefaultManagerRegistrationInitBackoff is default initial backoff for registration.
+	DefaultManagerRegistrationInitBackoff = 500 * time.Millisecond
+
+	// DefaultManagerRegistrationMaxBackoff is default max backoff for registration.
+	DefaultManagerRegistrationMaxBackoff = 5 * time.Second
+
+	// DefaultManagerRegistrationJitter is default jitter ratio of backoff for registration.
+	DefaultManagerRegistrationJitter = 0.5
 )
 
 const (
@@ -133,6 +163,9 @@ const (
 const (
 	// DefaultMetricsAddr is default address for metrics server.
 	DefaultMetricsAddr = ":8000"
+
+	// DefaultMetricsSeedPeerSaturationInterval is default interval of computing the saturation of seed peers.
+	DefaultMetricsSeedPeerSaturationInterval = 30 * time.Second
 )
 
 var (
@@ -152,6 +185,15 @@ var (
 )
 
 const (
+	// StorageDriverFile writes the records of storage into rotated csv files.
+	StorageDriverFile = "file"
+
+	// StorageDriverSQLite keeps the records of storage in sqlite database.
+	StorageDriverSQLite = "sqlite"
+
+	// DefaultStorageDriver is the default driver of storage.
+	DefaultStorageDriver = StorageDriverFile
+
 	// DefaultStorageMaxSize is the default maximum size of record file.
 	DefaultStorageMaxSize = 100
 
@@ -179,6 +221,25 @@ const (
 	DefaultProbeSyncCount = 10
 )
 
+const (
+	// DefaultPeerExchangeInterval is the default interval of exchanging peers between schedulers.
+	DefaultPeerExchangeInterval = 10 * time.Second
+
+	// DefaultPeerExchangeMaxPeers is the default max number of peers of each task published by the scheduler.
+	DefaultPeerExchangeMaxPeers = 20
+)
+
+const (
+	// DefaultInventoryInterval is the default interval of reporting inventory to manager.
+	DefaultInventoryInterval = 30 * time.Second
+
+	// DefaultInventoryUnhealthyTimeout is the default timeout after which the host or peer is unhealthy.
+	DefaultInventoryUnhealthyTimeout = 5 * time.Minute
+
+	// DefaultInventoryMaxPeers is the default max number of peers reported by the scheduler.
+	DefaultInventoryMaxPeers = 10000
+)
+
 const (
 	// DefaultTrainerAddr is the default address of trainer.
 	DefaultTrainerAddr = "127.0.0.1:9000"
@@ -186,6 +247,95 @@ const (
 	// DefaultTrainerInterval is the default interval of training.
 	DefaultTrainerInterval = 7 * 24 * time.Hour
 
+	// DefaultTrainerIntervalSensitivity is the default ratio that the adaptive interval of training
+	// moves towards the interval of target records in each training.
+	DefaultTrainerIntervalSensitivity = 0.5
+
 	// DefaultTrainerUploadTimeout is the default timeout of uploading dataset to trainer.
 	DefaultTrainerUploadTimeout = 1 * time.Hour
+
+	// DefaultTrainerUploadTimeoutWarningRatio is the default ratio of elapsed time of upload to
+	// UploadTimeout, beyond which a warning is logged.
+	DefaultTrainerUploadTimeoutWarningRatio = 0.8
+
+	// DefaultTrainerStreamOpenTimeout is the default timeout of opening train stream to trainer.
+	DefaultTrainerStreamOpenTimeout = 1 * time.Minute
+
+	// DefaultTrainerMaxMessageSize is the default max size of message sent to trainer,
+	// it is the default max receive message size of grpc server.
+	DefaultTrainerMaxMessageSize = 4 * 1024 * 1024
+)
+
+const (
+	// TrainerFinalizePolicyPessimistic keeps the checkpoint if the train stream is not confirmed by trainer,
+	// the unconfirmed segment is uploaded again in the next training.
+	TrainerFinalizePolicyPessimistic = "pessimistic"
+
+	// TrainerFinalizePolicyOptimistic advances the checkpoint if all data is sent to trainer,
+	// even if the train stream is not confirmed by trainer.
+	TrainerFinalizePolicyOptimistic = "optimistic"
+
+	// DefaultTrainerFinalizePolicy is the default finalize policy of train stream.
+	DefaultTrainerFinalizePolicy = TrainerFinalizePolicyPessimistic
+
+	// DefaultTrainerFinalizeRetries is the default max number of retries of unconfirmed train stream.
+	DefaultTrainerFinalizeRetries = 1
+
+	// DefaultTrainerFullSyncInterval is the default interval of uploading the whole dataset to trainer.
+	DefaultTrainerFullSyncInterval = 24 * time.Hour
+)
+
+const (
+	// TrainerCycleBudgetPolicyFinalize stops uploading when the budget of training cycle is exhausted,
+	// and finalizes the train stream with the data sent.
+	TrainerCycleBudgetPolicyFinalize = "finalize"
+
+	// TrainerCycleBudgetPolicyAbort aborts the train stream when the budget of training cycle is exhausted,
+	// the data sent in the stream is uploaded again in the next training.
+	TrainerCycleBudgetPolicyAbort = "abort"
+
+	// DefaultTrainerCycleBudgetPolicy is the default policy of exhausted training cycle budget.
+	DefaultTrainerCycleBudgetPolicy = TrainerCycleBudgetPolicyFinalize
+)
+
+const (
+	// TrainerUploadPolicyAllOrNothing uploads the datasets in the same train stream,
+	// failure of any dataset fails the stream of both datasets.
+	TrainerUploadPolicyAllOrNothing = "all-or-nothing"
+
+	// TrainerUploadPolicyBestEffort uploads each dataset in its own train stream,
+	// failure of a dataset does not discard the upload of the other dataset.
+	TrainerUploadPolicyBestEffort = "best-effort"
+
+	// DefaultTrainerUploadPolicy is the default policy of uploading datasets.
+	DefaultTrainerUploadPolicy = TrainerUploadPolicyAllOrNothing
+)
+
+const (
+	// TrainerSamplePolicyRate keeps each download record with the probability of sample rate.
+	TrainerSamplePolicyRate = "rate"
+
+	// TrainerSamplePolicyStratified keeps the sample rate of download records in each stratum
+	// of task size and download state, so that the rare strata are kept.
+	TrainerSamplePolicyStratified = "stratified"
+
+	// DefaultTrainerSamplePolicy is the default policy of sampling download records.
+	DefaultTrainerSamplePolicy = TrainerSamplePolicyRate
+
+	// DefaultTrainerSampleRate is the default rate of sampling download records, all records are kept.
+	DefaultTrainerSampleRate = 1.0
+)
+
+const (
+	// TrainerUploadSinkGRPC uploads datasets by the Train stream of trainer grpc service.
+	TrainerUploadSinkGRPC = "grpc"
+
+	// TrainerUploadSinkHTTP uploads datasets in chunks to the http upload endpoint of trainer.
+	TrainerUploadSinkHTTP = "http"
+
+	// DefaultTrainerUploadSink is the default sink of datasets uploaded to trainer.
+	DefaultTrainerUploadSink = TrainerUploadSinkGRPC
+
+	// DefaultTrainerHTTPUploadChunkRetries is the default max number of retries of uploading a chunk.
+	DefaultTrainerHTTPUploadChunkRetries = 3
 )
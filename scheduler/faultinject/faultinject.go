@@ -0,0 +1,145 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package faultinject
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Faults is the faults injected into scheduling for resilience tests.
+type Faults struct {
+	// ScheduleDelay delays each scheduling of peer.
+	ScheduleDelay time.Duration `json:"scheduleDelay"`
+
+	// DropAnnouncementRate is the probability of dropping the announcements of peers.
+	DropAnnouncementRate float64 `json:"dropAnnouncementRate"`
+
+	// PieceFailureRate is the probability of handling the finished pieces as failed.
+	PieceFailureRate float64 `json:"pieceFailureRate"`
+}
+
+// Validate returns error if the faults are invalid.
+func (f Faults) Validate() error {
+	if f.ScheduleDelay < 0 {
+		return fmt.Errorf("invalid schedule delay %s", f.ScheduleDelay)
+	}
+
+	if f.DropAnnouncementRate < 0 || f.DropAnnouncementRate > 1 {
+		return fmt.Errorf("invalid drop announcement rate %f", f.DropAnnouncementRate)
+	}
+
+	if f.PieceFailureRate < 0 || f.PieceFailureRate > 1 {
+		return fmt.Errorf("invalid piece failure rate %f", f.PieceFailureRate)
+	}
+
+	return nil
+}
+
+// injector injects the faults, which are updated by the admin endpoint.
+type injector struct {
+	mu     sync.RWMutex
+	faults Faults
+
+	randMu sync.Mutex
+	rand   *rand.Rand
+}
+
+// newInjector returns an injector without faults.
+func newInjector() *injector {
+	return &injector{rand: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// load returns the faults.
+func (i *injector) load() Faults {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	return i.faults
+}
+
+// store replaces the faults.
+func (i *injector) store(faults Faults) error {
+	if err := faults.Validate(); err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.faults = faults
+	return nil
+}
+
+// delay sleeps for the schedule delay.
+func (i *injector) delay() {
+	if d := i.load().ScheduleDelay; d > 0 {
+		time.Sleep(d)
+	}
+}
+
+// dropAnnouncement returns whether the announcement is dropped.
+func (i *injector) dropAnnouncement() bool {
+	return i.hit(i.load().DropAnnouncementRate)
+}
+
+// failPiece returns whether the finished piece is handled as failed.
+func (i *injector) failPiece() bool {
+	return i.hit(i.load().PieceFailureRate)
+}
+
+// hit returns true with the probability of rate.
+func (i *injector) hit(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+
+	i.randMu.Lock()
+	defer i.randMu.Unlock()
+	return i.rand.Float64() < rate
+}
+
+// ServeHTTP serves the admin endpoint of faults, GET returns the faults,
+// PUT replaces the faults and DELETE clears the faults.
+func (i *injector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var faults Faults
+		if err := json.NewDecoder(r.Body).Decode(&faults); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if err := i.store(faults); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	case http.MethodDelete:
+		// nolint
+		i.store(Faults{})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	// nolint
+	json.NewEncoder(w).Encode(i.load())
+}
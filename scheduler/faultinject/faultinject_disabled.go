@@ -0,0 +1,43 @@
+//go:build !faultinject
+
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package faultinject
+
+import "net/http"
+
+// Enabled indicates whether the faults are injected, it is false unless the binary
+// is built with the faultinject build tag.
+const Enabled = false
+
+// Delay does nothing unless the binary is built with the faultinject build tag.
+func Delay() {}
+
+// DropAnnouncement returns false unless the binary is built with the faultinject build tag.
+func DropAnnouncement() bool {
+	return false
+}
+
+// FailPiece returns false unless the binary is built with the faultinject build tag.
+func FailPiece() bool {
+	return false
+}
+
+// Handler returns nil unless the binary is built with the faultinject build tag.
+func Handler() http.Handler {
+	return nil
+}
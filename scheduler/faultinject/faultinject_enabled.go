@@ -0,0 +1,48 @@
+//go:build faultinject
+
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package faultinject
+
+import "net/http"
+
+// Enabled indicates whether the faults are injected, it is true in the binaries
+// built with the faultinject build tag.
+const Enabled = true
+
+// defaultInjector is the injector of scheduler.
+var defaultInjector = newInjector()
+
+// Delay sleeps for the schedule delay before scheduling peer.
+func Delay() {
+	defaultInjector.delay()
+}
+
+// DropAnnouncement returns whether the announcement of peer is dropped.
+func DropAnnouncement() bool {
+	return defaultInjector.dropAnnouncement()
+}
+
+// FailPiece returns whether the finished piece is handled as failed.
+func FailPiece() bool {
+	return defaultInjector.failPiece()
+}
+
+// Handler returns the handler of admin endpoint updating the faults.
+func Handler() http.Handler {
+	return defaultInjector
+}
@@ -0,0 +1,185 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package faultinject
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFaults_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		faults Faults
+		expect func(t *testing.T, err error)
+	}{
+		{
+			name:   "valid faults",
+			faults: Faults{ScheduleDelay: time.Second, DropAnnouncementRate: 0.5, PieceFailureRate: 1},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+			},
+		},
+		{
+			name:   "invalid schedule delay",
+			faults: Faults{ScheduleDelay: -time.Second},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "invalid schedule delay -1s")
+			},
+		},
+		{
+			name:   "invalid drop announcement rate",
+			faults: Faults{DropAnnouncementRate: 1.5},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "invalid drop announcement rate 1.500000")
+			},
+		},
+		{
+			name:   "invalid piece failure rate",
+			faults: Faults{PieceFailureRate: -1},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "invalid piece failure rate -1.000000")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expect(t, tc.faults.Validate())
+		})
+	}
+}
+
+func TestInjector(t *testing.T) {
+	assert := assert.New(t)
+	i := newInjector()
+	assert.False(i.dropAnnouncement())
+	assert.False(i.failPiece())
+
+	assert.NoError(i.store(Faults{ScheduleDelay: 10 * time.Millisecond, DropAnnouncementRate: 1, PieceFailureRate: 1}))
+	assert.True(i.dropAnnouncement())
+	assert.True(i.failPiece())
+
+	start := time.Now()
+	i.delay()
+	assert.GreaterOrEqual(time.Since(start), 10*time.Millisecond)
+
+	assert.Error(i.store(Faults{PieceFailureRate: 2}))
+	assert.Equal(float64(1), i.load().PieceFailureRate)
+}
+
+func TestInjector_ServeHTTP(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		body   string
+		expect func(t *testing.T, i *injector, resp *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "get faults",
+			method: http.MethodGet,
+			expect: func(t *testing.T, i *injector, resp *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, resp.Code)
+
+				var faults Faults
+				assert.NoError(json.NewDecoder(resp.Body).Decode(&faults))
+				assert.Equal(Faults{}, faults)
+			},
+		},
+		{
+			name:   "put faults",
+			method: http.MethodPut,
+			body:   `{"scheduleDelay":1000000,"dropAnnouncementRate":0.1,"pieceFailureRate":0.2}`,
+			expect: func(t *testing.T, i *injector, resp *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, resp.Code)
+				assert.Equal(Faults{ScheduleDelay: time.Millisecond, DropAnnouncementRate: 0.1, PieceFailureRate: 0.2}, i.load())
+			},
+		},
+		{
+			name:   "put invalid faults",
+			method: http.MethodPut,
+			body:   `{"pieceFailureRate":2}`,
+			expect: func(t *testing.T, i *injector, resp *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusBadRequest, resp.Code)
+				assert.Equal(Faults{}, i.load())
+			},
+		},
+		{
+			name:   "put malformed faults",
+			method: http.MethodPut,
+			body:   `foo`,
+			expect: func(t *testing.T, i *injector, resp *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusBadRequest, resp.Code)
+			},
+		},
+		{
+			name:   "delete faults",
+			method: http.MethodDelete,
+			expect: func(t *testing.T, i *injector, resp *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusOK, resp.Code)
+				assert.Equal(Faults{}, i.load())
+			},
+		},
+		{
+			name:   "method not allowed",
+			method: http.MethodPost,
+			expect: func(t *testing.T, i *injector, resp *httptest.ResponseRecorder) {
+				assert := assert.New(t)
+				assert.Equal(http.StatusMethodNotAllowed, resp.Code)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			i := newInjector()
+			if tc.method == http.MethodDelete {
+				assert.NoError(t, i.store(Faults{PieceFailureRate: 1}))
+			}
+
+			resp := httptest.NewRecorder()
+			i.ServeHTTP(resp, httptest.NewRequest(tc.method, "/faults", strings.NewReader(tc.body)))
+			tc.expect(t, i, resp)
+		})
+	}
+}
+
+func TestDisabled(t *testing.T) {
+	if Enabled {
+		t.Skip("faults are injected with faultinject build tag")
+	}
+
+	assert := assert.New(t)
+	assert.False(DropAnnouncement())
+	assert.False(FailPiece())
+	assert.Nil(Handler())
+}
@@ -0,0 +1,238 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+//go:generate mockgen -destination mocks/inventory_mock.go -source inventory.go -package mocks
+
+package inventory
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	managertypes "d7y.io/dragonfly/v2/manager/types"
+	pkgredis "d7y.io/dragonfly/v2/pkg/redis"
+	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/resource"
+)
+
+const (
+	// contextTimeout is the timeout of redis invoke.
+	contextTimeout = 30 * time.Second
+
+	// expireIntervals is the number of report intervals after which
+	// the inventory reported by a scheduler is expired.
+	expireIntervals = 3
+)
+
+// Inventory is an interface for reporting the active hosts and peers of scheduler to manager.
+type Inventory interface {
+	// Serve starts reporting inventory.
+	Serve()
+
+	// Stop stops reporting inventory and withdraws the inventory reported by the scheduler.
+	Stop()
+}
+
+// inventory is an implementation of inventory.
+type inventory struct {
+	// config is the inventory config.
+	config config.InventoryConfig
+
+	// clusterID is the id of scheduler cluster.
+	clusterID uint
+
+	// hostname is the hostname of scheduler.
+	hostname string
+
+	// ip is the advertise ip of scheduler.
+	ip string
+
+	// key is the cache key of the inventory in manager.
+	key string
+
+	// rdb is Redis universal client interface.
+	rdb redis.UniversalClient
+
+	// resource is resource interface.
+	resource resource.Resource
+
+	// done is the channel for stopping report.
+	done chan struct{}
+}
+
+// New inventory interface.
+func New(cfg *config.Config, hostname string, rdb redis.UniversalClient, resource resource.Resource) (Inventory, error) {
+	ip := cfg.Server.AdvertiseIP.String()
+	return &inventory{
+		config:    cfg.Inventory,
+		clusterID: cfg.Manager.SchedulerClusterID,
+		hostname:  hostname,
+		ip:        ip,
+		key:       pkgredis.MakeInventoryKeyInManager(cfg.Manager.SchedulerClusterID, hostname, ip),
+		rdb:       rdb,
+		resource:  resource,
+		done:      make(chan struct{}),
+	}, nil
+}
+
+// Serve starts reporting inventory.
+func (i *inventory) Serve() {
+	logger.Info("inventory start to serve")
+
+	tick := time.NewTicker(i.config.Interval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-tick.C:
+			if err := i.report(i.collect(time.Now())); err != nil {
+				logger.Errorf("report inventory failed: %s", err.Error())
+			}
+		case <-i.done:
+			return
+		}
+	}
+}
+
+// Stop stops reporting inventory and withdraws the inventory reported by the scheduler.
+func (i *inventory) Stop() {
+	close(i.done)
+
+	ctx, cancel := context.WithTimeout(context.Background(), contextTimeout)
+	defer cancel()
+
+	if err := i.rdb.Del(ctx, i.key).Err(); err != nil {
+		logger.Errorf("withdraw inventory failed: %s", err.Error())
+	}
+}
+
+// collect returns the inventory of the hosts and peers in scheduler. Peers which have left
+// are not reported, and the most recently updated peers are reported if there are too many peers.
+func (i *inventory) collect(now time.Time) managertypes.Inventory {
+	inv := managertypes.Inventory{
+		SchedulerClusterID: i.clusterID,
+		Hostname:           i.hostname,
+		IP:                 i.ip,
+		Hosts:              []managertypes.InventoryHost{},
+		Peers:              []managertypes.InventoryPeer{},
+		UpdatedAt:          now,
+	}
+
+	i.resource.HostManager().Range(func(_, value any) bool {
+		host, ok := value.(*resource.Host)
+		if !ok {
+			return true
+		}
+
+		inv.Hosts = append(inv.Hosts, managertypes.InventoryHost{
+			ID:                    host.ID,
+			Type:                  host.Type.Name(),
+			Hostname:              host.Hostname,
+			IP:                    host.IP,
+			Port:                  host.Port,
+			DownloadPort:          host.DownloadPort,
+			IDC:                   host.Network.IDC,
+			Location:              host.Network.Location,
+			PeerCount:             host.PeerCount.Load(),
+			ConcurrentUploadCount: host.ConcurrentUploadCount.Load(),
+			UploadCount:           host.UploadCount.Load(),
+			UploadFailedCount:     host.UploadFailedCount.Load(),
+			Health:                i.hostHealth(host, now),
+			SchedulerClusterID:    i.clusterID,
+			CreatedAt:             host.CreatedAt.Load(),
+			UpdatedAt:             host.UpdatedAt.Load(),
+		})
+
+		return true
+	})
+
+	i.resource.PeerManager().Range(func(_, value any) bool {
+		peer, ok := value.(*resource.Peer)
+		if !ok || peer.FSM.Is(resource.PeerStateLeave) {
+			return true
+		}
+
+		inv.Peers = append(inv.Peers, managertypes.InventoryPeer{
+			ID:                 peer.ID,
+			TaskID:             peer.Task.ID,
+			HostID:             peer.Host.ID,
+			Hostname:           peer.Host.Hostname,
+			IP:                 peer.Host.IP,
+			IDC:                peer.Host.Network.IDC,
+			State:              peer.FSM.Current(),
+			FinishedPieceCount: peer.FinishedPieces.Count(),
+			Health:             i.peerHealth(peer, now),
+			SchedulerClusterID: i.clusterID,
+			CreatedAt:          peer.CreatedAt.Load(),
+			UpdatedAt:          peer.UpdatedAt.Load(),
+		})
+
+		return true
+	})
+
+	if len(inv.Peers) > i.config.MaxPeers {
+		sort.SliceStable(inv.Peers, func(m, n int) bool {
+			return inv.Peers[m].UpdatedAt.After(inv.Peers[n].UpdatedAt)
+		})
+
+		inv.Peers = inv.Peers[:i.config.MaxPeers]
+	}
+
+	return inv
+}
+
+// report reports the inventory to manager.
+func (i *inventory) report(inv managertypes.Inventory) error {
+	data, err := json.Marshal(inv)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), contextTimeout)
+	defer cancel()
+
+	return i.rdb.Set(ctx, i.key, data, expireIntervals*i.config.Interval).Err()
+}
+
+// hostHealth returns the health of host, the host is unhealthy if it
+// has not announced in the unhealthy timeout.
+func (i *inventory) hostHealth(host *resource.Host, now time.Time) string {
+	if now.Sub(host.UpdatedAt.Load()) > i.config.UnhealthyTimeout {
+		return managertypes.InventoryHealthUnhealthy
+	}
+
+	return managertypes.InventoryHealthHealthy
+}
+
+// peerHealth returns the health of peer, the peer is unhealthy if it has failed,
+// or it is downloading but has not finished pieces in the unhealthy timeout.
+func (i *inventory) peerHealth(peer *resource.Peer, now time.Time) string {
+	if peer.FSM.Is(resource.PeerStateFailed) {
+		return managertypes.InventoryHealthUnhealthy
+	}
+
+	if (peer.FSM.Is(resource.PeerStateRunning) || peer.FSM.Is(resource.PeerStateBackToSource)) &&
+		now.Sub(peer.PieceUpdatedAt.Load()) > i.config.UnhealthyTimeout {
+		return managertypes.InventoryHealthUnhealthy
+	}
+
+	return managertypes.InventoryHealthHealthy
+}
@@ -0,0 +1,204 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package inventory
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	commonv2 "d7y.io/api/pkg/apis/common/v2"
+
+	managertypes "d7y.io/dragonfly/v2/manager/types"
+	"d7y.io/dragonfly/v2/pkg/types"
+	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/resource"
+)
+
+var (
+	mockInventoryConfig = config.InventoryConfig{
+		Enable:           true,
+		Interval:         config.DefaultInventoryInterval,
+		UnhealthyTimeout: config.DefaultInventoryUnhealthyTimeout,
+		MaxPeers:         2,
+	}
+
+	mockTaskID = "4d4ed4a2ba4ed2ff39e0f0a2a63ab1d0c1a9d6d2c9f0ee0b6c8d9a2e8a3a2b7d"
+)
+
+func newMockInventory() *inventory {
+	return &inventory{
+		config:    mockInventoryConfig,
+		clusterID: 1,
+		hostname:  "scheduler",
+		ip:        "127.0.0.1",
+		done:      make(chan struct{}),
+	}
+}
+
+func newMockResource(ctl *gomock.Controller, peers, hosts *sync.Map) resource.Resource {
+	res := resource.NewMockResource(ctl)
+	peerManager := resource.NewMockPeerManager(ctl)
+	hostManager := resource.NewMockHostManager(ctl)
+	res.EXPECT().PeerManager().Return(peerManager).AnyTimes()
+	res.EXPECT().HostManager().Return(hostManager).AnyTimes()
+	peerManager.EXPECT().Range(gomock.Any()).Do(peers.Range).AnyTimes()
+	hostManager.EXPECT().Range(gomock.Any()).Do(hosts.Range).AnyTimes()
+	return res
+}
+
+func newMockPeer(id string, task *resource.Task, host *resource.Host, state string, updatedAt time.Time) *resource.Peer {
+	peer := resource.NewPeer(id, task, host)
+	peer.FSM.SetState(state)
+	peer.UpdatedAt.Store(updatedAt)
+	return peer
+}
+
+func TestInventory_collect(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	assert := assert.New(t)
+
+	now := time.Now()
+	task := resource.NewTask(mockTaskID, "https://example.com", "", "", commonv2.TaskType_DFDAEMON, nil, nil, 3)
+	host := resource.NewHost("host", "127.0.0.2", "hostname", 8003, 8001, types.HostTypeNormal,
+		resource.WithNetwork(resource.Network{IDC: "idc", Location: "location"}))
+	seedHost := resource.NewHost("seed-host", "127.0.0.3", "seed", 8003, 8001, types.HostTypeSuperSeed)
+	hosts := &sync.Map{}
+	hosts.Store(host.ID, host)
+	hosts.Store(seedHost.ID, seedHost)
+
+	peers := &sync.Map{}
+	for _, peer := range []*resource.Peer{
+		newMockPeer("oldest", task, host, resource.PeerStateSucceeded, now.Add(-3*time.Minute)),
+		newMockPeer("older", task, host, resource.PeerStateRunning, now.Add(-2*time.Minute)),
+		newMockPeer("newest", task, seedHost, resource.PeerStateFailed, now.Add(-time.Minute)),
+		newMockPeer("leave", task, host, resource.PeerStateLeave, now),
+	} {
+		peers.Store(peer.ID, peer)
+	}
+
+	in := newMockInventory()
+	in.resource = newMockResource(ctl, peers, hosts)
+	inv := in.collect(now)
+	assert.Equal(uint(1), inv.SchedulerClusterID)
+	assert.Equal("scheduler", inv.Hostname)
+	assert.Equal("127.0.0.1", inv.IP)
+	assert.Equal(now, inv.UpdatedAt)
+
+	assert.Len(inv.Hosts, 2)
+	for _, h := range inv.Hosts {
+		if h.ID == host.ID {
+			assert.Equal("normal", h.Type)
+			assert.Equal("idc", h.IDC)
+			assert.Equal("location", h.Location)
+			assert.Equal(managertypes.InventoryHealthHealthy, h.Health)
+		}
+	}
+
+	assert.Len(inv.Peers, 2)
+	assert.Equal("newest", inv.Peers[0].ID)
+	assert.Equal(resource.PeerStateFailed, inv.Peers[0].State)
+	assert.Equal(managertypes.InventoryHealthUnhealthy, inv.Peers[0].Health)
+	assert.Equal(seedHost.ID, inv.Peers[0].HostID)
+	assert.Equal("older", inv.Peers[1].ID)
+	assert.Equal(mockTaskID, inv.Peers[1].TaskID)
+	assert.Equal("idc", inv.Peers[1].IDC)
+	assert.Equal(managertypes.InventoryHealthHealthy, inv.Peers[1].Health)
+}
+
+func TestInventory_hostHealth(t *testing.T) {
+	tests := []struct {
+		name      string
+		updatedAt time.Duration
+		expect    string
+	}{
+		{
+			name:      "host announces recently",
+			updatedAt: -time.Minute,
+			expect:    managertypes.InventoryHealthHealthy,
+		},
+		{
+			name:      "host stops announcing",
+			updatedAt: -2 * config.DefaultInventoryUnhealthyTimeout,
+			expect:    managertypes.InventoryHealthUnhealthy,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			now := time.Now()
+			host := resource.NewHost("host", "127.0.0.2", "hostname", 8003, 8001, types.HostTypeNormal)
+			host.UpdatedAt.Store(now.Add(tc.updatedAt))
+			assert.Equal(t, tc.expect, newMockInventory().hostHealth(host, now))
+		})
+	}
+}
+
+func TestInventory_peerHealth(t *testing.T) {
+	tests := []struct {
+		name           string
+		state          string
+		pieceUpdatedAt time.Duration
+		expect         string
+	}{
+		{
+			name:           "peer succeeds",
+			state:          resource.PeerStateSucceeded,
+			pieceUpdatedAt: -2 * config.DefaultInventoryUnhealthyTimeout,
+			expect:         managertypes.InventoryHealthHealthy,
+		},
+		{
+			name:           "peer fails",
+			state:          resource.PeerStateFailed,
+			pieceUpdatedAt: -time.Minute,
+			expect:         managertypes.InventoryHealthUnhealthy,
+		},
+		{
+			name:           "peer finishes pieces recently",
+			state:          resource.PeerStateRunning,
+			pieceUpdatedAt: -time.Minute,
+			expect:         managertypes.InventoryHealthHealthy,
+		},
+		{
+			name:           "peer stalls in downloading",
+			state:          resource.PeerStateRunning,
+			pieceUpdatedAt: -2 * config.DefaultInventoryUnhealthyTimeout,
+			expect:         managertypes.InventoryHealthUnhealthy,
+		},
+		{
+			name:           "peer stalls in downloading back-to-source",
+			state:          resource.PeerStateBackToSource,
+			pieceUpdatedAt: -2 * config.DefaultInventoryUnhealthyTimeout,
+			expect:         managertypes.InventoryHealthUnhealthy,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			now := time.Now()
+			task := resource.NewTask(mockTaskID, "https://example.com", "", "", commonv2.TaskType_DFDAEMON, nil, nil, 3)
+			host := resource.NewHost("host", "127.0.0.2", "hostname", 8003, 8001, types.HostTypeNormal)
+			peer := newMockPeer("peer", task, host, tc.state, now)
+			peer.PieceUpdatedAt.Store(now.Add(tc.pieceUpdatedAt))
+			assert.Equal(t, tc.expect, newMockInventory().peerHealth(peer, now))
+		})
+	}
+}
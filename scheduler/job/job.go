@@ -20,6 +20,7 @@ package job
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"strings"
 	"time"
@@ -33,6 +34,7 @@ import (
 
 	logger "d7y.io/dragonfly/v2/internal/dflog"
 	internaljob "d7y.io/dragonfly/v2/internal/job"
+	"d7y.io/dragonfly/v2/manager/types"
 	"d7y.io/dragonfly/v2/pkg/idgen"
 	"d7y.io/dragonfly/v2/pkg/net/http"
 	"d7y.io/dragonfly/v2/scheduler/config"
@@ -42,6 +44,9 @@ import (
 const (
 	// preheatTimeout is timeout of preheating.
 	preheatTimeout = 20 * time.Minute
+
+	// gcDryRunMaxIDs is the max number of ids reported by each kind of resource in gc dry run.
+	gcDryRunMaxIDs = 100
 )
 
 type Job interface {
@@ -102,7 +107,8 @@ func New(cfg *config.Config, resource resource.Resource) (Job, error) {
 	}
 
 	namedJobFuncs := map[string]any{
-		internaljob.PreheatJob: t.preheat,
+		internaljob.PreheatJob:  t.preheat,
+		internaljob.GCDryRunJob: t.gcDryRun,
 	}
 
 	if err := localJob.RegisterJob(namedJobFuncs); err != nil {
@@ -204,3 +210,55 @@ func (j *job) preheat(ctx context.Context, req string) error {
 		}
 	}
 }
+
+// gcDryRun reports the peers, tasks and hosts reclaimed by the gc policy in use,
+// overridden by the request, without reclaiming them.
+func (j *job) gcDryRun(ctx context.Context, req string) (string, error) {
+	gcDryRun := &internaljob.GCDryRunRequest{}
+	if err := internaljob.UnmarshalRequest(req, gcDryRun); err != nil {
+		logger.Errorf("unmarshal request err: %s, request body: %s", err.Error(), req)
+		return "", err
+	}
+
+	policy := j.resource.GCPolicy().Override(&types.SchedulerClusterGCConfig{
+		PeerTTL:      gcDryRun.PeerTTL,
+		TaskTTL:      gcDryRun.TaskTTL,
+		HostTTL:      gcDryRun.HostTTL,
+		MaxTaskCount: gcDryRun.MaxTaskCount,
+	})
+
+	peerIDs := j.resource.PeerManager().DryRunGC(policy)
+	taskIDs := j.resource.TaskManager().DryRunGC(policy)
+	hostIDs := j.resource.HostManager().DryRunGC()
+	logger.Infof("gc dry run with policy %#v reclaims %d peers, %d tasks and %d hosts",
+		policy, len(peerIDs), len(taskIDs), len(hostIDs))
+
+	b, err := json.Marshal(&internaljob.GCDryRunResponse{
+		Hostname:     j.config.Server.Host,
+		IP:           j.config.Server.AdvertiseIP.String(),
+		PeerTTL:      policy.PeerTTL,
+		TaskTTL:      policy.TaskTTL,
+		HostTTL:      policy.HostTTL,
+		MaxTaskCount: policy.MaxTaskCount,
+		PeerCount:    len(peerIDs),
+		TaskCount:    len(taskIDs),
+		HostCount:    len(hostIDs),
+		PeerIDs:      truncateIDs(peerIDs),
+		TaskIDs:      truncateIDs(taskIDs),
+		HostIDs:      truncateIDs(hostIDs),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
+// truncateIDs returns the first gcDryRunMaxIDs ids.
+func truncateIDs(ids []string) []string {
+	if len(ids) > gcDryRunMaxIDs {
+		return ids[:gcDryRunMaxIDs]
+	}
+
+	return ids
+}
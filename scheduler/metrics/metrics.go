@@ -18,6 +18,7 @@ package metrics
 
 import (
 	"net/http"
+	"time"
 
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"github.com/prometheus/client_golang/prometheus"
@@ -25,6 +26,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
 
+	"d7y.io/dragonfly/v2/pkg/net/fqdn"
 	"d7y.io/dragonfly/v2/pkg/types"
 	"d7y.io/dragonfly/v2/scheduler/config"
 	"d7y.io/dragonfly/v2/version"
@@ -36,6 +38,12 @@ var (
 
 	// HostTrafficDownloadType is download traffic type for host traffic metrics.
 	HostTrafficDownloadType = "download"
+
+	// TrainDatasetDownload is download dataset type for train metrics.
+	TrainDatasetDownload = "download"
+
+	// TrainDatasetNetworkTopology is network topology dataset type for train metrics.
+	TrainDatasetNetworkTopology = "network_topology"
 )
 
 // Variables declared for metrics.
@@ -253,6 +261,149 @@ var (
 		Help:      "Gauge of the number of concurrent of the scheduling.",
 	})
 
+	TrainCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.SchedulerMetricsName,
+		Name:      "train_total",
+		Help:      "Counter of the number of the training.",
+	}, []string{"trainer"})
+
+	TrainFailureCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.SchedulerMetricsName,
+		Name:      "train_failure_total",
+		Help:      "Counter of the number of failed of the training.",
+	}, []string{"trainer"})
+
+	TrainDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.SchedulerMetricsName,
+		Name:      "train_duration_milliseconds",
+		Help:      "Histogram of the time each training cycle uploading dataset to trainer.",
+		Buckets:   []float64{100, 500, 1000, 5 * 1000, 10 * 1000, 30 * 1000, 60 * 1000, 300 * 1000, 600 * 1000, 1800 * 1000, 3600 * 1000},
+	}, []string{"trainer"})
+
+	TrainUploadDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.SchedulerMetricsName,
+		Name:      "train_upload_duration_milliseconds",
+		Help:      "Histogram of the time each segment of dataset uploaded to trainer.",
+		Buckets:   []float64{10, 50, 100, 500, 1000, 5 * 1000, 10 * 1000, 30 * 1000, 60 * 1000, 300 * 1000, 600 * 1000},
+	}, []string{"trainer"})
+
+	TrainUploadBytesCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.SchedulerMetricsName,
+		Name:      "train_upload_bytes_total",
+		Help:      "Counter of the bytes of dataset uploaded to trainer.",
+	}, []string{"type"})
+
+	TrainLastSuccessTimestampGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.SchedulerMetricsName,
+		Name:      "train_last_success_timestamp_seconds",
+		Help:      "Gauge of the unix timestamp in seconds of the last successful training cycle.",
+	})
+
+	TrainStreamOpenTimeoutCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.SchedulerMetricsName,
+		Name:      "train_stream_open_timeout_total",
+		Help:      "Counter of the number of timeout of opening the train stream.",
+	}, []string{"trainer"})
+
+	TrainUploadNearTimeoutCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.SchedulerMetricsName,
+		Name:      "train_upload_near_timeout_total",
+		Help:      "Counter of the number of uploads to trainer whose elapsed time exceeds the warning ratio of the upload timeout.",
+	}, []string{"trainer"})
+
+	TrainFinalizeRetryCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.SchedulerMetricsName,
+		Name:      "train_finalize_retry_total",
+		Help:      "Counter of the number of retries replaying the unconfirmed segment to trainer.",
+	}, []string{"trainer"})
+
+	TrainFullSyncCount = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.SchedulerMetricsName,
+		Name:      "train_full_sync_total",
+		Help:      "Counter of the number of full syncs uploading the whole dataset to trainer.",
+	}, []string{"trainer"})
+
+	TrainShadowFailureCount = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.SchedulerMetricsName,
+		Name:      "train_shadow_failure_total",
+		Help:      "Counter of the number of failed train streams to shadow trainer.",
+	})
+
+	TrainBreakerStateGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.SchedulerMetricsName,
+		Name:      "train_breaker_state",
+		Help:      "Gauge of the state of the training circuit breaker, 0 is closed, 1 is open and 2 is half-open.",
+	})
+
+	TrainIntervalGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.SchedulerMetricsName,
+		Name:      "train_interval_seconds",
+		Help:      "Gauge of the interval in seconds before the next training cycle adapted to the growth of records.",
+	})
+
+	StorageFileSizeGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.SchedulerMetricsName,
+		Name:      "storage_file_size_bytes",
+		Help:      "Gauge of the size of the storage file being written.",
+	}, []string{"type"})
+
+	StorageTotalSizeGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.SchedulerMetricsName,
+		Name:      "storage_total_size_bytes",
+		Help:      "Gauge of the total size of the storage files, including backup files.",
+	}, []string{"type"})
+
+	FQDNResolveFailureCount = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.SchedulerMetricsName,
+		Name:      "fqdn_resolve_failure_total",
+		Help:      "Counter of the number of failed resolutions of fqdn hostname.",
+	})
+
+	FQDNResolveDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.SchedulerMetricsName,
+		Name:      "fqdn_resolve_duration_milliseconds",
+		Help:      "Histogram of the time each resolution of fqdn hostname.",
+		Buckets:   []float64{1, 5, 10, 50, 100, 500, 1000, 5 * 1000, 10 * 1000, 30 * 1000},
+	})
+
+	SeedPeerSaturationGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.SchedulerMetricsName,
+		Name:      "seed_peer_saturation",
+		Help:      "Gauge of the ratio of concurrent uploads to the upload limit of seed peers.",
+	})
+
+	SeedPeerActiveTaskGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.SchedulerMetricsName,
+		Name:      "seed_peer_active_tasks",
+		Help:      "Gauge of the number of tasks being downloaded by seed peers.",
+	})
+
+	BackToSourceRateGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: types.MetricsNamespace,
+		Subsystem: types.SchedulerMetricsName,
+		Name:      "back_to_source_rate",
+		Help:      "Gauge of the ratio of normal peers downloading back-to-source.",
+	})
+
 	VersionGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: types.MetricsNamespace,
 		Subsystem: types.SchedulerMetricsName,
@@ -261,15 +412,33 @@ var (
 	}, []string{"major", "minor", "git_version", "git_commit", "platform", "build_time", "go_version", "go_tags", "go_gcflags"})
 )
 
-func New(cfg *config.MetricsConfig, svr *grpc.Server) *http.Server {
+// New returns the metrics server, the drain handler and the fault handler are served
+// as the admin endpoints of draining scheduler and injecting faults if they are not nil.
+func New(cfg *config.MetricsConfig, svr *grpc.Server, drainHandler, faultHandler http.Handler) *http.Server {
 	grpc_prometheus.Register(svr)
 
 	mux := http.NewServeMux()
 	mux.Handle("/metrics", promhttp.Handler())
+	if drainHandler != nil {
+		mux.Handle("/drain", drainHandler)
+	}
 
+	if faultHandler != nil {
+		mux.Handle("/faults", faultHandler)
+	}
+
+	fqdn.SetHook(observeFQDNResolution)
 	VersionGauge.WithLabelValues(version.Major, version.Minor, version.GitVersion, version.GitCommit, version.Platform, version.BuildTime, version.GoVersion, version.Gotags, version.Gogcflags).Set(1)
 	return &http.Server{
 		Addr:    cfg.Addr,
 		Handler: mux,
 	}
 }
+
+// observeFQDNResolution collects the metrics of resolution of fqdn hostname.
+func observeFQDNResolution(elapsed time.Duration, err error) {
+	FQDNResolveDuration.Observe(float64(elapsed.Milliseconds()))
+	if err != nil {
+		FQDNResolveFailureCount.Inc()
+	}
+}
@@ -17,9 +17,13 @@
 package metrics
 
 import (
+	"errors"
 	"net/http"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
 
 	"d7y.io/dragonfly/v2/scheduler/config"
@@ -30,7 +34,7 @@ func TestNew(t *testing.T) {
 		Addr: "localhost:8080",
 	}
 	svr := grpc.NewServer()
-	server := New(cfg, svr)
+	server := New(cfg, svr, nil, nil)
 
 	if server.Addr != cfg.Addr {
 		t.Errorf("expected server.Addr to be %s, but got %s", cfg.Addr, server.Addr)
@@ -40,3 +44,28 @@ func TestNew(t *testing.T) {
 		t.Errorf("expected server.Handler to be a *http.ServeMux, but got %T", server.Handler)
 	}
 }
+
+func TestObserveFQDNResolution(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		failures float64
+	}{
+		{
+			name: "resolution succeeds",
+		},
+		{
+			name:     "resolution fails",
+			err:      errors.New("foo"),
+			failures: 1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			failures := testutil.ToFloat64(FQDNResolveFailureCount)
+			observeFQDNResolution(time.Millisecond, tc.err)
+			assert.Equal(t, tc.failures, testutil.ToFloat64(FQDNResolveFailureCount)-failures)
+		})
+	}
+}
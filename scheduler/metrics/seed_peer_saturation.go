@@ -0,0 +1,159 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	managertypes "d7y.io/dragonfly/v2/manager/types"
+	pkgredis "d7y.io/dragonfly/v2/pkg/redis"
+	"d7y.io/dragonfly/v2/scheduler/config"
+)
+
+const (
+	// seedPeerSaturationExpireIntervals is the number of intervals after which
+	// the seed peer saturation published to manager is expired.
+	seedPeerSaturationExpireIntervals = 3
+)
+
+// SeedPeerHostSample is the sample of seed peer host for computing the saturation.
+type SeedPeerHostSample struct {
+	// ActiveTaskCount is the number of tasks being downloaded by the seed peers on host.
+	ActiveTaskCount int
+
+	// ConcurrentUploadCount is the number of concurrent uploads of host.
+	ConcurrentUploadCount int32
+
+	// ConcurrentUploadLimit is the limit of concurrent uploads of host.
+	ConcurrentUploadLimit int32
+}
+
+// PeerSample is the sample of normal peer for computing the back-to-source rate.
+type PeerSample struct {
+	// BackToSource is whether the peer downloads back-to-source.
+	BackToSource bool
+}
+
+// SeedPeerSampler returns the samples of seed peer hosts and normal peers.
+type SeedPeerSampler func() ([]SeedPeerHostSample, []PeerSample)
+
+// ComputeSeedPeerSaturation computes the saturation of seed peers by the concurrent uploads
+// against the upload limit, and the back-to-source rate of normal peers.
+func ComputeSeedPeerSaturation(hosts []SeedPeerHostSample, peers []PeerSample) managertypes.SeedPeerSaturation {
+	saturation := managertypes.SeedPeerSaturation{
+		SchedulerCount: 1,
+		SeedPeerCount:  len(hosts),
+		PeerCount:      len(peers),
+		UpdatedAt:      time.Now(),
+	}
+
+	for _, host := range hosts {
+		saturation.ActiveTaskCount += host.ActiveTaskCount
+		saturation.ConcurrentUploadCount += int64(host.ConcurrentUploadCount)
+		saturation.ConcurrentUploadLimit += int64(host.ConcurrentUploadLimit)
+	}
+
+	if saturation.ConcurrentUploadLimit > 0 {
+		saturation.Saturation = float64(saturation.ConcurrentUploadCount) / float64(saturation.ConcurrentUploadLimit)
+	}
+
+	for _, peer := range peers {
+		if peer.BackToSource {
+			saturation.BackToSourcePeerCount++
+		}
+	}
+
+	if saturation.PeerCount > 0 {
+		saturation.BackToSourceRate = float64(saturation.BackToSourcePeerCount) / float64(saturation.PeerCount)
+	}
+
+	return saturation
+}
+
+// SeedPeerSaturationService computes the saturation of seed peers periodically, and
+// publishes it as metrics and to manager for the autoscaler of seed peers.
+type SeedPeerSaturationService struct {
+	// config is the seed peer saturation config.
+	config config.SeedPeerSaturationConfig
+
+	// key is the cache key of the saturation in manager.
+	key string
+
+	// rdb is Redis universal client interface.
+	rdb redis.UniversalClient
+
+	// sampler returns the samples of seed peer hosts and normal peers.
+	sampler SeedPeerSampler
+
+	// done is the channel for stopping service.
+	done chan struct{}
+}
+
+// NewSeedPeerSaturationService returns a new seed peer saturation service.
+func NewSeedPeerSaturationService(cfg *config.Config, hostname string, rdb redis.UniversalClient, sampler SeedPeerSampler) *SeedPeerSaturationService {
+	return &SeedPeerSaturationService{
+		config:  cfg.Metrics.SeedPeerSaturation,
+		key:     pkgredis.MakeSeedPeerSaturationKeyInManager(cfg.Manager.SchedulerClusterID, hostname, cfg.Server.AdvertiseIP.String()),
+		rdb:     rdb,
+		sampler: sampler,
+		done:    make(chan struct{}),
+	}
+}
+
+// Serve starts computing the saturation of seed peers.
+func (s *SeedPeerSaturationService) Serve() {
+	tick := time.NewTicker(s.config.Interval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-tick.C:
+			if err := s.publish(ComputeSeedPeerSaturation(s.sampler())); err != nil {
+				logger.Errorf("publish seed peer saturation failed: %s", err.Error())
+			}
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Stop stops computing the saturation of seed peers.
+func (s *SeedPeerSaturationService) Stop() {
+	close(s.done)
+}
+
+// publish sets the metrics of saturation and publishes it to manager.
+func (s *SeedPeerSaturationService) publish(saturation managertypes.SeedPeerSaturation) error {
+	SeedPeerSaturationGauge.Set(saturation.Saturation)
+	SeedPeerActiveTaskGauge.Set(float64(saturation.ActiveTaskCount))
+	BackToSourceRateGauge.Set(saturation.BackToSourceRate)
+
+	data, err := json.Marshal(saturation)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Interval)
+	defer cancel()
+
+	return s.rdb.Set(ctx, s.key, data, seedPeerSaturationExpireIntervals*s.config.Interval).Err()
+}
@@ -0,0 +1,86 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	managertypes "d7y.io/dragonfly/v2/manager/types"
+)
+
+func TestComputeSeedPeerSaturation(t *testing.T) {
+	tests := []struct {
+		name   string
+		hosts  []SeedPeerHostSample
+		peers  []PeerSample
+		expect func(t *testing.T, saturation managertypes.SeedPeerSaturation)
+	}{
+		{
+			name: "compute saturation and back-to-source rate",
+			hosts: []SeedPeerHostSample{
+				{ActiveTaskCount: 2, ConcurrentUploadCount: 30, ConcurrentUploadLimit: 100},
+				{ActiveTaskCount: 1, ConcurrentUploadCount: 10, ConcurrentUploadLimit: 100},
+			},
+			peers: []PeerSample{{BackToSource: true}, {}, {}, {}},
+			expect: func(t *testing.T, saturation managertypes.SeedPeerSaturation) {
+				assert := assert.New(t)
+				assert.Equal(1, saturation.SchedulerCount)
+				assert.Equal(2, saturation.SeedPeerCount)
+				assert.Equal(3, saturation.ActiveTaskCount)
+				assert.Equal(int64(40), saturation.ConcurrentUploadCount)
+				assert.Equal(int64(200), saturation.ConcurrentUploadLimit)
+				assert.Equal(0.2, saturation.Saturation)
+				assert.Equal(4, saturation.PeerCount)
+				assert.Equal(1, saturation.BackToSourcePeerCount)
+				assert.Equal(0.25, saturation.BackToSourceRate)
+				assert.False(saturation.UpdatedAt.IsZero())
+			},
+		},
+		{
+			name:  "compute without seed peers and peers",
+			hosts: []SeedPeerHostSample{},
+			peers: []PeerSample{},
+			expect: func(t *testing.T, saturation managertypes.SeedPeerSaturation) {
+				assert := assert.New(t)
+				assert.Equal(0, saturation.SeedPeerCount)
+				assert.Equal(float64(0), saturation.Saturation)
+				assert.Equal(float64(0), saturation.BackToSourceRate)
+			},
+		},
+		{
+			name: "compute saturation of seed peers without upload limit",
+			hosts: []SeedPeerHostSample{
+				{ActiveTaskCount: 1, ConcurrentUploadCount: 10},
+			},
+			peers: []PeerSample{{BackToSource: true}},
+			expect: func(t *testing.T, saturation managertypes.SeedPeerSaturation) {
+				assert := assert.New(t)
+				assert.Equal(1, saturation.SeedPeerCount)
+				assert.Equal(float64(0), saturation.Saturation)
+				assert.Equal(float64(1), saturation.BackToSourceRate)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expect(t, ComputeSeedPeerSaturation(tc.hosts, tc.peers))
+		})
+	}
+}
@@ -0,0 +1,58 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: peer_exchange.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPeerExchange is a mock of PeerExchange interface.
+type MockPeerExchange struct {
+	ctrl     *gomock.Controller
+	recorder *MockPeerExchangeMockRecorder
+}
+
+// MockPeerExchangeMockRecorder is the mock recorder for MockPeerExchange.
+type MockPeerExchangeMockRecorder struct {
+	mock *MockPeerExchange
+}
+
+// NewMockPeerExchange creates a new mock instance.
+func NewMockPeerExchange(ctrl *gomock.Controller) *MockPeerExchange {
+	mock := &MockPeerExchange{ctrl: ctrl}
+	mock.recorder = &MockPeerExchangeMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPeerExchange) EXPECT() *MockPeerExchangeMockRecorder {
+	return m.recorder
+}
+
+// Serve mocks base method.
+func (m *MockPeerExchange) Serve() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Serve")
+}
+
+// Serve indicates an expected call of Serve.
+func (mr *MockPeerExchangeMockRecorder) Serve() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Serve", reflect.TypeOf((*MockPeerExchange)(nil).Serve))
+}
+
+// Stop mocks base method.
+func (m *MockPeerExchange) Stop() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Stop")
+}
+
+// Stop indicates an expected call of Stop.
+func (mr *MockPeerExchangeMockRecorder) Stop() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockPeerExchange)(nil).Stop))
+}
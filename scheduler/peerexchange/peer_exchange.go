@@ -0,0 +1,414 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+//go:generate mockgen -destination mocks/peer_exchange_mock.go -source peer_exchange.go -package mocks
+
+package peerexchange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/bits-and-blooms/bitset"
+	"github.com/go-redis/redis/v8"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/pkg/container/set"
+	pkgredis "d7y.io/dragonfly/v2/pkg/redis"
+	"d7y.io/dragonfly/v2/pkg/types"
+	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/resource"
+)
+
+const (
+	// contextTimeout is the timeout of redis invoke.
+	contextTimeout = 30 * time.Second
+
+	// expireIntervals is the number of exchange intervals after which
+	// the peers published by a scheduler are expired.
+	expireIntervals = 3
+)
+
+// PeerExchange is an interface for exchanging peers between the schedulers of the same cluster.
+type PeerExchange interface {
+	// Serve starts exchanging peers.
+	Serve()
+
+	// Stop stops exchanging peers and withdraws the peers published by the scheduler.
+	Stop()
+}
+
+// Host is the host of peer exchanged between schedulers.
+type Host struct {
+	ID           string         `json:"id"`
+	Type         types.HostType `json:"type"`
+	Hostname     string         `json:"hostname"`
+	IP           string         `json:"ip"`
+	Port         int32          `json:"port"`
+	DownloadPort int32          `json:"downloadPort"`
+	Location     string         `json:"location"`
+	IDC          string         `json:"idc"`
+}
+
+// Peer is the peer exchanged between schedulers.
+type Peer struct {
+	ID             string         `json:"id"`
+	State          string         `json:"state"`
+	FinishedPieces *bitset.BitSet `json:"finishedPieces"`
+	Host           Host           `json:"host"`
+}
+
+// membership is the peers of a task published by a scheduler.
+type membership struct {
+	// Peers is the peers of task which can be parents.
+	Peers []Peer `json:"peers"`
+
+	// UpdatedAt is the time of publishing.
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// peerExchange is an implementation of peer exchange.
+type peerExchange struct {
+	// config is the peer exchange config.
+	config config.PeerExchangeConfig
+
+	// clusterID is the id of scheduler cluster.
+	clusterID uint
+
+	// schedulerID is the id of scheduler in the cluster.
+	schedulerID string
+
+	// rdb is Redis universal client interface.
+	rdb redis.UniversalClient
+
+	// resource is resource interface.
+	resource resource.Resource
+
+	// adoptedPeers is the ids of peers adopted from other schedulers,
+	// which are never published again.
+	adoptedPeers set.SafeSet[string]
+
+	// publishedTaskIDs is the ids of tasks published in the last exchange.
+	publishedTaskIDs set.Set[string]
+
+	// done is the channel for stopping exchange.
+	done chan struct{}
+}
+
+// New peer exchange interface.
+func New(cfg *config.Config, rdb redis.UniversalClient, resource resource.Resource) (PeerExchange, error) {
+	return &peerExchange{
+		config:           cfg.PeerExchange,
+		clusterID:        cfg.Manager.SchedulerClusterID,
+		schedulerID:      fmt.Sprintf("%s-%s", cfg.Server.Host, cfg.Server.AdvertiseIP.String()),
+		rdb:              rdb,
+		resource:         resource,
+		adoptedPeers:     set.NewSafeSet[string](),
+		publishedTaskIDs: set.New[string](),
+		done:             make(chan struct{}),
+	}, nil
+}
+
+// Serve starts exchanging peers.
+func (pe *peerExchange) Serve() {
+	logger.Info("peer exchange start to serve")
+
+	tick := time.NewTicker(pe.config.Interval)
+	defer tick.Stop()
+
+	for {
+		select {
+		case <-tick.C:
+			pe.exchange()
+		case <-pe.done:
+			return
+		}
+	}
+}
+
+// Stop stops exchanging peers and withdraws the peers published by the scheduler.
+func (pe *peerExchange) Stop() {
+	close(pe.done)
+
+	ctx, cancel := context.WithTimeout(context.Background(), contextTimeout)
+	defer cancel()
+
+	pipe := pe.rdb.Pipeline()
+	for _, taskID := range pe.publishedTaskIDs.Values() {
+		pipe.HDel(ctx, pkgredis.MakePeerExchangeKeyInScheduler(pe.clusterID, taskID), pe.schedulerID)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Errorf("withdraw peers failed: %s", err.Error())
+	}
+}
+
+// exchange publishes the peers of the scheduler and adopts the peers published by other schedulers.
+func (pe *peerExchange) exchange() {
+	ctx, cancel := context.WithTimeout(context.Background(), contextTimeout)
+	defer cancel()
+
+	published, demanded := pe.collect()
+	if err := pe.publish(ctx, published); err != nil {
+		logger.Errorf("publish peers failed: %s", err.Error())
+	}
+
+	for _, taskID := range demanded.Values() {
+		task, loaded := pe.resource.TaskManager().Load(taskID)
+		if !loaded {
+			continue
+		}
+
+		peers, err := pe.load(ctx, taskID)
+		if err != nil {
+			logger.Errorf("load peers of task %s failed: %s", taskID, err.Error())
+			continue
+		}
+
+		pe.adopt(task, peers)
+	}
+
+	// Forget the adopted peers which have been reclaimed.
+	for _, id := range pe.adoptedPeers.Values() {
+		if _, loaded := pe.resource.PeerManager().Load(id); !loaded {
+			pe.adoptedPeers.Delete(id)
+		}
+	}
+}
+
+// collect returns the peers which can be parents grouped by task id and the ids of tasks
+// which have downloading peers. Peers of seed peer are not published, because seed peers
+// are already known by all schedulers of the cluster.
+func (pe *peerExchange) collect() (map[string][]Peer, set.Set[string]) {
+	var (
+		published = make(map[string][]*resource.Peer)
+		demanded  = set.New[string]()
+	)
+
+	pe.resource.PeerManager().Range(func(_, value any) bool {
+		peer, ok := value.(*resource.Peer)
+		if !ok {
+			return true
+		}
+
+		if pe.adoptedPeers.Contains(peer.ID) {
+			return true
+		}
+
+		switch {
+		case peer.FSM.Is(resource.PeerStateSucceeded):
+		case peer.FSM.Is(resource.PeerStateRunning) || peer.FSM.Is(resource.PeerStateBackToSource):
+			demanded.Add(peer.Task.ID)
+			if peer.FinishedPieces.Count() == 0 {
+				return true
+			}
+		case peer.FSM.Is(resource.PeerStateReceivedTiny) || peer.FSM.Is(resource.PeerStateReceivedSmall) ||
+			peer.FSM.Is(resource.PeerStateReceivedNormal):
+			demanded.Add(peer.Task.ID)
+			return true
+		default:
+			return true
+		}
+
+		if peer.Host.Type != types.HostTypeNormal {
+			return true
+		}
+
+		published[peer.Task.ID] = append(published[peer.Task.ID], peer)
+		return true
+	})
+
+	peers := make(map[string][]Peer, len(published))
+	for taskID, candidates := range published {
+		// Prefer the peers which have finished more pieces.
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].FinishedPieces.Count() > candidates[j].FinishedPieces.Count()
+		})
+
+		if len(candidates) > pe.config.MaxPeers {
+			candidates = candidates[:pe.config.MaxPeers]
+		}
+
+		for _, candidate := range candidates {
+			peers[taskID] = append(peers[taskID], newPeer(candidate))
+		}
+	}
+
+	return peers, demanded
+}
+
+// publish publishes the peers of tasks and withdraws the tasks which have no peers to publish.
+func (pe *peerExchange) publish(ctx context.Context, peers map[string][]Peer) error {
+	pipe := pe.rdb.Pipeline()
+	now := time.Now()
+	for taskID, taskPeers := range peers {
+		data, err := json.Marshal(membership{Peers: taskPeers, UpdatedAt: now})
+		if err != nil {
+			return err
+		}
+
+		key := pkgredis.MakePeerExchangeKeyInScheduler(pe.clusterID, taskID)
+		pipe.HSet(ctx, key, pe.schedulerID, data)
+		pipe.Expire(ctx, key, expireIntervals*pe.config.Interval)
+	}
+
+	publishedTaskIDs := set.New[string]()
+	for taskID := range peers {
+		publishedTaskIDs.Add(taskID)
+	}
+
+	for _, taskID := range pe.publishedTaskIDs.Values() {
+		if !publishedTaskIDs.Contains(taskID) {
+			pipe.HDel(ctx, pkgredis.MakePeerExchangeKeyInScheduler(pe.clusterID, taskID), pe.schedulerID)
+		}
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	pe.publishedTaskIDs = publishedTaskIDs
+	return nil
+}
+
+// load loads the peers of task published by other schedulers, expired memberships are skipped.
+func (pe *peerExchange) load(ctx context.Context, taskID string) ([]Peer, error) {
+	rawMemberships, err := pe.rdb.HGetAll(ctx, pkgredis.MakePeerExchangeKeyInScheduler(pe.clusterID, taskID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	return pe.parse(rawMemberships, time.Now()), nil
+}
+
+// parse returns the peers of memberships which are published by other schedulers and not expired.
+func (pe *peerExchange) parse(rawMemberships map[string]string, now time.Time) []Peer {
+	var (
+		peers   []Peer
+		peerIDs = set.New[string]()
+	)
+
+	for schedulerID, rawMembership := range rawMemberships {
+		if schedulerID == pe.schedulerID {
+			continue
+		}
+
+		var m membership
+		if err := json.Unmarshal([]byte(rawMembership), &m); err != nil {
+			logger.Errorf("invalid membership of scheduler %s: %s", schedulerID, err.Error())
+			continue
+		}
+
+		if now.Sub(m.UpdatedAt) > expireIntervals*pe.config.Interval {
+			continue
+		}
+
+		for _, peer := range m.Peers {
+			if peerIDs.Add(peer.ID) {
+				peers = append(peers, peer)
+			}
+		}
+	}
+
+	return peers
+}
+
+// adopt stores the peers published by other schedulers into the task, so they can be scheduled
+// as parents, and deletes the adopted peers of task which are no longer published.
+func (pe *peerExchange) adopt(task *resource.Task, peers []Peer) {
+	peerIDs := set.New[string]()
+	for _, p := range peers {
+		peerIDs.Add(p.ID)
+
+		if peer, loaded := pe.resource.PeerManager().Load(p.ID); loaded {
+			// Peers registered on the scheduler are always up to date.
+			if !pe.adoptedPeers.Contains(p.ID) {
+				continue
+			}
+
+			refresh(peer, p)
+			continue
+		}
+
+		host, loaded := pe.resource.HostManager().Load(p.Host.ID)
+		if !loaded {
+			host = resource.NewHost(
+				p.Host.ID, p.Host.IP, p.Host.Hostname,
+				p.Host.Port, p.Host.DownloadPort, p.Host.Type,
+				resource.WithNetwork(resource.Network{
+					Location: p.Host.Location,
+					IDC:      p.Host.IDC,
+				}),
+			)
+			host, _ = pe.resource.HostManager().LoadOrStore(host)
+		}
+
+		peer, loaded := pe.resource.PeerManager().LoadOrStore(resource.NewPeer(p.ID, task, host))
+		if loaded {
+			continue
+		}
+
+		pe.adoptedPeers.Add(peer.ID)
+		refresh(peer, p)
+		peer.Log.Infof("peer is adopted from other scheduler")
+	}
+
+	for _, id := range pe.adoptedPeers.Values() {
+		peer, loaded := pe.resource.PeerManager().Load(id)
+		if !loaded || peer.Task.ID != task.ID || peerIDs.Contains(id) {
+			continue
+		}
+
+		pe.resource.PeerManager().Delete(id)
+		pe.adoptedPeers.Delete(id)
+		peer.Log.Info("adopted peer is withdrawn by other scheduler")
+	}
+}
+
+// refresh updates the state and finished pieces of adopted peer.
+func refresh(peer *resource.Peer, p Peer) {
+	peer.FSM.SetState(p.State)
+	if p.FinishedPieces != nil {
+		peer.FinishedPieces = p.FinishedPieces
+		for i, ok := peer.FinishedPieces.NextSet(0); ok; i, ok = peer.FinishedPieces.NextSet(i + 1) {
+			peer.Task.StorePiecePeer(int32(i), peer.ID)
+		}
+	}
+
+	peer.UpdatedAt.Store(time.Now())
+}
+
+// newPeer returns the exchanged peer of peer.
+func newPeer(peer *resource.Peer) Peer {
+	return Peer{
+		ID:             peer.ID,
+		State:          peer.FSM.Current(),
+		FinishedPieces: peer.FinishedPieces.Clone(),
+		Host: Host{
+			ID:           peer.Host.ID,
+			Type:         peer.Host.Type,
+			Hostname:     peer.Host.Hostname,
+			IP:           peer.Host.IP,
+			Port:         peer.Host.Port,
+			DownloadPort: peer.Host.DownloadPort,
+			Location:     peer.Host.Network.Location,
+			IDC:          peer.Host.Network.IDC,
+		},
+	}
+}
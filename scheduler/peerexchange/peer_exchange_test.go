@@ -0,0 +1,279 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package peerexchange
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bits-and-blooms/bitset"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	commonv2 "d7y.io/api/pkg/apis/common/v2"
+
+	"d7y.io/dragonfly/v2/pkg/container/set"
+	"d7y.io/dragonfly/v2/pkg/types"
+	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/resource"
+)
+
+var (
+	mockPeerExchangeConfig = config.PeerExchangeConfig{
+		Enable:   true,
+		Interval: config.DefaultPeerExchangeInterval,
+		MaxPeers: 2,
+	}
+
+	mockTaskID = "4d4ed4a2ba4ed2ff39e0f0a2a63ab1d0c1a9d6d2c9f0ee0b6c8d9a2e8a3a2b7d"
+
+	mockRemoteHost = Host{
+		ID:           "remote-host",
+		Type:         types.HostTypeNormal,
+		Hostname:     "remote",
+		IP:           "127.0.0.2",
+		Port:         8003,
+		DownloadPort: 8001,
+		Location:     "location",
+		IDC:          "idc",
+	}
+)
+
+func newMockPeerExchange() *peerExchange {
+	return &peerExchange{
+		config:           mockPeerExchangeConfig,
+		schedulerID:      "local",
+		adoptedPeers:     set.NewSafeSet[string](),
+		publishedTaskIDs: set.New[string](),
+		done:             make(chan struct{}),
+	}
+}
+
+func newMockResource(ctl *gomock.Controller, peers, hosts *sync.Map) resource.Resource {
+	res := resource.NewMockResource(ctl)
+	peerManager := resource.NewMockPeerManager(ctl)
+	hostManager := resource.NewMockHostManager(ctl)
+	res.EXPECT().PeerManager().Return(peerManager).AnyTimes()
+	res.EXPECT().HostManager().Return(hostManager).AnyTimes()
+
+	peerManager.EXPECT().Range(gomock.Any()).Do(peers.Range).AnyTimes()
+	peerManager.EXPECT().Load(gomock.Any()).DoAndReturn(func(id string) (*resource.Peer, bool) {
+		peer, loaded := peers.Load(id)
+		if !loaded {
+			return nil, false
+		}
+
+		return peer.(*resource.Peer), true
+	}).AnyTimes()
+	peerManager.EXPECT().LoadOrStore(gomock.Any()).DoAndReturn(func(peer *resource.Peer) (*resource.Peer, bool) {
+		rawPeer, loaded := peers.LoadOrStore(peer.ID, peer)
+		if !loaded {
+			peer.Task.StorePeer(peer)
+			peer.Host.StorePeer(peer)
+		}
+
+		return rawPeer.(*resource.Peer), loaded
+	}).AnyTimes()
+	peerManager.EXPECT().Delete(gomock.Any()).Do(func(id string) {
+		if peer, loaded := peers.LoadAndDelete(id); loaded {
+			peer.(*resource.Peer).Task.DeletePeer(id)
+		}
+	}).AnyTimes()
+
+	hostManager.EXPECT().Load(gomock.Any()).DoAndReturn(func(id string) (*resource.Host, bool) {
+		host, loaded := hosts.Load(id)
+		if !loaded {
+			return nil, false
+		}
+
+		return host.(*resource.Host), true
+	}).AnyTimes()
+	hostManager.EXPECT().LoadOrStore(gomock.Any()).DoAndReturn(func(host *resource.Host) (*resource.Host, bool) {
+		rawHost, loaded := hosts.LoadOrStore(host.ID, host)
+		return rawHost.(*resource.Host), loaded
+	}).AnyTimes()
+
+	return res
+}
+
+func newMockTask() *resource.Task {
+	return resource.NewTask(mockTaskID, "https://example.com", "", "", commonv2.TaskType_DFDAEMON, nil, nil, 3)
+}
+
+func newMockPeer(id string, task *resource.Task, host *resource.Host, state string, pieces ...uint) *resource.Peer {
+	peer := resource.NewPeer(id, task, host)
+	peer.FSM.SetState(state)
+	for _, piece := range pieces {
+		peer.FinishedPieces.Set(piece)
+	}
+
+	return peer
+}
+
+func TestPeerExchange_collect(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	assert := assert.New(t)
+
+	task := newMockTask()
+	host := resource.NewHost("host", "127.0.0.1", "hostname", 8003, 8001, types.HostTypeNormal)
+	seedHost := resource.NewHost("seed-host", "127.0.0.3", "seed", 8003, 8001, types.HostTypeSuperSeed)
+	peers := &sync.Map{}
+	for _, peer := range []*resource.Peer{
+		newMockPeer("succeeded", task, host, resource.PeerStateSucceeded, 0, 1, 2),
+		newMockPeer("running", task, host, resource.PeerStateRunning, 0, 1),
+		newMockPeer("running-without-pieces", task, host, resource.PeerStateRunning),
+		newMockPeer("back-to-source", task, host, resource.PeerStateBackToSource, 0),
+		newMockPeer("failed", task, host, resource.PeerStateFailed, 0),
+		newMockPeer("seed", task, seedHost, resource.PeerStateSucceeded, 0, 1, 2),
+		newMockPeer("adopted", task, host, resource.PeerStateSucceeded, 0, 1, 2),
+	} {
+		peers.Store(peer.ID, peer)
+	}
+
+	pe := newMockPeerExchange()
+	pe.resource = newMockResource(ctl, peers, &sync.Map{})
+	pe.adoptedPeers.Add("adopted")
+
+	published, demanded := pe.collect()
+	assert.Len(published[mockTaskID], 2)
+	assert.Equal("succeeded", published[mockTaskID][0].ID)
+	assert.Equal(resource.PeerStateSucceeded, published[mockTaskID][0].State)
+	assert.Equal(uint(3), published[mockTaskID][0].FinishedPieces.Count())
+	assert.Equal(host.ID, published[mockTaskID][0].Host.ID)
+	assert.Equal("running", published[mockTaskID][1].ID)
+	assert.Equal([]string{mockTaskID}, demanded.Values())
+}
+
+func TestPeerExchange_parse(t *testing.T) {
+	now := time.Now()
+	newRawMembership := func(updatedAt time.Time, ids ...string) string {
+		m := membership{UpdatedAt: updatedAt}
+		for _, id := range ids {
+			m.Peers = append(m.Peers, Peer{ID: id, State: resource.PeerStateSucceeded, Host: mockRemoteHost})
+		}
+
+		data, _ := json.Marshal(m)
+		return string(data)
+	}
+
+	tests := []struct {
+		name           string
+		rawMemberships map[string]string
+		expect         func(t *testing.T, peers []Peer)
+	}{
+		{
+			name: "parse peers of other schedulers",
+			rawMemberships: map[string]string{
+				"foo": newRawMembership(now, "a", "b"),
+				"bar": newRawMembership(now, "b"),
+			},
+			expect: func(t *testing.T, peers []Peer) {
+				assert := assert.New(t)
+				assert.Len(peers, 2)
+			},
+		},
+		{
+			name: "skip peers of itself",
+			rawMemberships: map[string]string{
+				"local": newRawMembership(now, "a"),
+			},
+			expect: func(t *testing.T, peers []Peer) {
+				assert := assert.New(t)
+				assert.Empty(peers)
+			},
+		},
+		{
+			name: "skip expired memberships",
+			rawMemberships: map[string]string{
+				"foo": newRawMembership(now.Add(-expireIntervals*mockPeerExchangeConfig.Interval-time.Second), "a"),
+				"bar": newRawMembership(now, "b"),
+			},
+			expect: func(t *testing.T, peers []Peer) {
+				assert := assert.New(t)
+				assert.Len(peers, 1)
+				assert.Equal("b", peers[0].ID)
+			},
+		},
+		{
+			name: "skip invalid memberships",
+			rawMemberships: map[string]string{
+				"foo": "foo",
+			},
+			expect: func(t *testing.T, peers []Peer) {
+				assert := assert.New(t)
+				assert.Empty(peers)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pe := newMockPeerExchange()
+			tc.expect(t, pe.parse(tc.rawMemberships, now))
+		})
+	}
+}
+
+func TestPeerExchange_adopt(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	assert := assert.New(t)
+
+	task := newMockTask()
+	host := resource.NewHost("host", "127.0.0.1", "hostname", 8003, 8001, types.HostTypeNormal)
+	local := newMockPeer("local", task, host, resource.PeerStateRunning)
+	peers, hosts := &sync.Map{}, &sync.Map{}
+	peers.Store(local.ID, local)
+	task.StorePeer(local)
+	hosts.Store(host.ID, host)
+
+	pe := newMockPeerExchange()
+	pe.resource = newMockResource(ctl, peers, hosts)
+
+	finishedPieces := bitset.New(0).Set(0).Set(1)
+	pe.adopt(task, []Peer{
+		{ID: "remote", State: resource.PeerStateRunning, FinishedPieces: finishedPieces, Host: mockRemoteHost},
+		{ID: local.ID, State: resource.PeerStateSucceeded, Host: Host{ID: host.ID}},
+	})
+
+	remote, loaded := task.LoadPeer("remote")
+	assert.True(loaded)
+	assert.True(pe.adoptedPeers.Contains("remote"))
+	assert.Equal(resource.PeerStateRunning, remote.FSM.Current())
+	assert.Equal(mockRemoteHost.IP, remote.Host.IP)
+	assert.Equal(mockRemoteHost.IDC, remote.Host.Network.IDC)
+	assert.Equal([]string{"remote"}, task.LoadPiecePeers(1))
+	assert.Equal(resource.PeerStateRunning, local.FSM.Current())
+
+	// Refresh the adopted peer.
+	pe.adopt(task, []Peer{
+		{ID: "remote", State: resource.PeerStateSucceeded, FinishedPieces: bitset.New(0).Set(0).Set(1).Set(2), Host: mockRemoteHost},
+	})
+	assert.Equal(resource.PeerStateSucceeded, remote.FSM.Current())
+	assert.Equal([]string{"remote"}, task.LoadPiecePeers(2))
+
+	// Delete the adopted peer which is withdrawn.
+	pe.adopt(task, nil)
+	_, loaded = task.LoadPeer("remote")
+	assert.False(loaded)
+	assert.False(pe.adoptedPeers.Contains("remote"))
+	_, loaded = task.LoadPeer(local.ID)
+	assert.True(loaded)
+}
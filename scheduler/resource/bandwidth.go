@@ -0,0 +1,90 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"sync"
+	"time"
+)
+
+// Bandwidth measures the bandwidth of traffic in a sliding window. The traffic
+// of the previous window is weighted by its overlap with the sliding window.
+type Bandwidth struct {
+	// window is the length of sliding window.
+	window time.Duration
+
+	// current and previous are the traffic bytes of the current and previous window.
+	current  int64
+	previous int64
+
+	// start is the start time of the current window.
+	start time.Time
+
+	// now returns the current time, it is replaced in tests.
+	now func() time.Time
+
+	mu sync.Mutex
+}
+
+// NewBandwidth returns a new Bandwidth measured in window.
+func NewBandwidth(window time.Duration) *Bandwidth {
+	return &Bandwidth{
+		window: window,
+		start:  time.Now(),
+		now:    time.Now,
+	}
+}
+
+// Add adds the traffic bytes at the current time.
+func (b *Bandwidth) Add(n int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance()
+	b.current += n
+}
+
+// Rate returns the bandwidth in bytes per second.
+func (b *Bandwidth) Rate() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	elapsed := b.advance()
+	weight := float64(b.window-elapsed) / float64(b.window)
+	return (float64(b.previous)*weight + float64(b.current)) / b.window.Seconds()
+}
+
+// advance moves the current window to the current time, and returns the elapsed
+// time of the current window.
+func (b *Bandwidth) advance() time.Duration {
+	elapsed := b.now().Sub(b.start)
+	if elapsed < b.window {
+		return elapsed
+	}
+
+	// The traffic of the current window becomes the previous window only
+	// if they are adjacent, otherwise no traffic is in the previous window.
+	if elapsed < 2*b.window {
+		b.previous = b.current
+	} else {
+		b.previous = 0
+	}
+
+	b.current = 0
+	b.start = b.start.Add(elapsed / b.window * b.window)
+	return elapsed % b.window
+}
@@ -0,0 +1,86 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBandwidth_Rate(t *testing.T) {
+	// step adds bytes after elapsed, then expects the rate.
+	type step struct {
+		elapsed time.Duration
+		bytes   int64
+		rate    float64
+	}
+
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "bandwidth is zero without traffic",
+			steps: []step{
+				{elapsed: 0, rate: 0},
+				{elapsed: time.Hour, rate: 0},
+			},
+		},
+		{
+			name: "bandwidth in current window",
+			steps: []step{
+				{elapsed: time.Second, bytes: 100, rate: 10},
+				{elapsed: time.Second, bytes: 100, rate: 20},
+			},
+		},
+		{
+			name: "bandwidth of previous window is weighted",
+			steps: []step{
+				{elapsed: 0, bytes: 1000, rate: 100},
+				// Half of the previous window overlaps with the sliding window.
+				{elapsed: 15 * time.Second, bytes: 100, rate: 60},
+				// The first window does not overlap with the sliding window.
+				{elapsed: 5 * time.Second, bytes: 0, rate: 10},
+			},
+		},
+		{
+			name: "bandwidth expires after idle windows",
+			steps: []step{
+				{elapsed: 0, bytes: 1000, rate: 100},
+				{elapsed: 25 * time.Second, bytes: 0, rate: 0},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			now := time.Now()
+			b := NewBandwidth(10 * time.Second)
+			b.now = func() time.Time { return now }
+			b.start = now
+
+			assert := assert.New(t)
+			for i, step := range tc.steps {
+				now = now.Add(step.elapsed)
+				b.Add(step.bytes)
+				assert.InDelta(step.rate, b.Rate(), 0.001, "step %d", i)
+			}
+		})
+	}
+}
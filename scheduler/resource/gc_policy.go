@@ -0,0 +1,129 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/manager/types"
+	"d7y.io/dragonfly/v2/scheduler/config"
+)
+
+// GCPolicy is the policy of reclaiming peers, tasks and hosts.
+type GCPolicy struct {
+	// PeerTTL is time to live of peer.
+	PeerTTL time.Duration
+
+	// TaskTTL is time to live of idle task, zero means the task
+	// is reclaimed only when all the peers have been reclaimed.
+	TaskTTL time.Duration
+
+	// HostTTL is time to live of host.
+	HostTTL time.Duration
+
+	// MaxTaskCount is the max number of tasks, zero means unlimited.
+	MaxTaskCount int
+}
+
+// Override returns the policy overridden by the non-zero fields of
+// the gc config of scheduler cluster.
+func (p GCPolicy) Override(cfg *types.SchedulerClusterGCConfig) GCPolicy {
+	if cfg == nil {
+		return p
+	}
+
+	if cfg.PeerTTL > 0 {
+		p.PeerTTL = time.Duration(cfg.PeerTTL) * time.Second
+	}
+
+	if cfg.TaskTTL > 0 {
+		p.TaskTTL = time.Duration(cfg.TaskTTL) * time.Second
+	}
+
+	if cfg.HostTTL > 0 {
+		p.HostTTL = time.Duration(cfg.HostTTL) * time.Second
+	}
+
+	if cfg.MaxTaskCount > 0 {
+		p.MaxTaskCount = int(cfg.MaxTaskCount)
+	}
+
+	return p
+}
+
+// gcPolicy holds the policy used by gc, the gc config of scheduler cluster
+// in manager overrides the gc config of scheduler.
+type gcPolicy struct {
+	// base is the policy of the gc config of scheduler.
+	base GCPolicy
+
+	// current is the policy in use.
+	current GCPolicy
+
+	// mu is policy mutex.
+	mu *sync.RWMutex
+}
+
+// newGCPolicy returns a new gc policy initialized by the gc config of scheduler.
+func newGCPolicy(cfg *config.GCConfig) *gcPolicy {
+	base := GCPolicy{
+		PeerTTL:      cfg.PeerTTL,
+		TaskTTL:      cfg.TaskTTL,
+		HostTTL:      cfg.HostTTL,
+		MaxTaskCount: cfg.MaxTaskCount,
+	}
+
+	return &gcPolicy{
+		base:    base,
+		current: base,
+		mu:      &sync.RWMutex{},
+	}
+}
+
+// Load returns the policy in use.
+func (g *gcPolicy) Load() GCPolicy {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	return g.current
+}
+
+// OnNotify overrides the policy by the gc config of scheduler cluster when dynconfig is notified.
+func (g *gcPolicy) OnNotify(data *config.DynconfigData) {
+	if data == nil || data.Scheduler == nil || data.Scheduler.SchedulerCluster == nil {
+		return
+	}
+
+	var cfg types.SchedulerClusterConfig
+	if err := json.Unmarshal(data.Scheduler.SchedulerCluster.Config, &cfg); err != nil {
+		logger.Errorf("unmarshal scheduler cluster config failed: %s", err.Error())
+		return
+	}
+
+	policy := g.base.Override(cfg.GC)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.current != policy {
+		logger.Infof("gc policy is changed from %#v to %#v", g.current, policy)
+		g.current = policy
+	}
+}
@@ -0,0 +1,131 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	managerv2 "d7y.io/api/pkg/apis/manager/v2"
+
+	"d7y.io/dragonfly/v2/manager/types"
+	"d7y.io/dragonfly/v2/scheduler/config"
+)
+
+var (
+	mockGCPolicyConfig = &config.GCConfig{
+		PeerTTL: 24 * time.Hour,
+		HostTTL: 1 * time.Hour,
+	}
+)
+
+func TestGCPolicy_Override(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    *types.SchedulerClusterGCConfig
+		expect GCPolicy
+	}{
+		{
+			name: "gc config of scheduler cluster is empty",
+			cfg:  nil,
+			expect: GCPolicy{
+				PeerTTL: 24 * time.Hour,
+				HostTTL: 1 * time.Hour,
+			},
+		},
+		{
+			name: "gc config of scheduler cluster overrides the non-zero fields",
+			cfg: &types.SchedulerClusterGCConfig{
+				PeerTTL:      3600,
+				TaskTTL:      600,
+				MaxTaskCount: 100,
+			},
+			expect: GCPolicy{
+				PeerTTL:      1 * time.Hour,
+				TaskTTL:      10 * time.Minute,
+				HostTTL:      1 * time.Hour,
+				MaxTaskCount: 100,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+			assert.Equal(newGCPolicy(mockGCPolicyConfig).Load().Override(tc.cfg), tc.expect)
+		})
+	}
+}
+
+func TestGCPolicy_OnNotify(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   *config.DynconfigData
+		expect GCPolicy
+	}{
+		{
+			name: "scheduler cluster is empty",
+			data: &config.DynconfigData{
+				Scheduler: &managerv2.Scheduler{},
+			},
+			expect: GCPolicy{
+				PeerTTL: 24 * time.Hour,
+				HostTTL: 1 * time.Hour,
+			},
+		},
+		{
+			name: "invalid scheduler cluster config",
+			data: &config.DynconfigData{
+				Scheduler: &managerv2.Scheduler{
+					SchedulerCluster: &managerv2.SchedulerCluster{
+						Config: []byte("foo"),
+					},
+				},
+			},
+			expect: GCPolicy{
+				PeerTTL: 24 * time.Hour,
+				HostTTL: 1 * time.Hour,
+			},
+		},
+		{
+			name: "scheduler cluster config overrides gc policy",
+			data: &config.DynconfigData{
+				Scheduler: &managerv2.Scheduler{
+					SchedulerCluster: &managerv2.SchedulerCluster{
+						Config: []byte(`{"gc":{"host_ttl":600,"max_task_count":10}}`),
+					},
+				},
+			},
+			expect: GCPolicy{
+				PeerTTL:      24 * time.Hour,
+				HostTTL:      10 * time.Minute,
+				MaxTaskCount: 10,
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+			policy := newGCPolicy(mockGCPolicyConfig)
+			policy.OnNotify(tc.data)
+			assert.Equal(policy.Load(), tc.expect)
+		})
+	}
+}
@@ -28,6 +28,11 @@ import (
 	"d7y.io/dragonfly/v2/scheduler/config"
 )
 
+const (
+	// UploadBandwidthWindow is the sliding window of measuring host's upload bandwidth.
+	UploadBandwidthWindow = 10 * time.Second
+)
+
 // HostOption is a functional option for configuring the host.
 type HostOption func(h *Host)
 
@@ -170,6 +175,10 @@ type Host struct {
 	// UploadFailedCount is upload failed count.
 	UploadFailedCount *atomic.Int64
 
+	// UploadBandwidth is upload bandwidth of host, which is accounted
+	// by the pieces reported to be downloaded from the host.
+	UploadBandwidth *Bandwidth
+
 	// Peer sync map.
 	Peers *sync.Map
 
@@ -338,6 +347,7 @@ func NewHost(
 		ConcurrentUploadCount: atomic.NewInt32(0),
 		UploadCount:           atomic.NewInt64(0),
 		UploadFailedCount:     atomic.NewInt64(0),
+		UploadBandwidth:       NewBandwidth(UploadBandwidthWindow),
 		Peers:                 &sync.Map{},
 		PeerCount:             atomic.NewInt32(0),
 		CreatedAt:             atomic.NewTime(time.Now()),
@@ -47,8 +47,15 @@ type HostManager interface {
 	// Delete deletes host for a key.
 	Delete(string)
 
+	// Range calls f sequentially for each key and host present in the host manager,
+	// if f returns false, range stops the iteration.
+	Range(f func(any, any) bool)
+
 	// Try to reclaim host.
 	RunGC() error
+
+	// DryRunGC returns the ids of hosts reclaimed by gc without reclaiming them.
+	DryRunGC() []string
 }
 
 // hostManager contains content for host manager.
@@ -112,9 +119,7 @@ func (h *hostManager) RunGC() error {
 			return true
 		}
 
-		if host.PeerCount.Load() == 0 &&
-			host.ConcurrentUploadCount.Load() == 0 &&
-			host.Type == types.HostTypeNormal {
+		if isHostReclaimable(host) {
 			host.Log.Info("host has been reclaimed")
 			h.Delete(host.ID)
 		}
@@ -124,3 +129,29 @@ func (h *hostManager) RunGC() error {
 
 	return nil
 }
+
+// DryRunGC returns the ids of hosts reclaimed by gc without reclaiming them.
+func (h *hostManager) DryRunGC() []string {
+	var ids []string
+	h.Map.Range(func(_, value any) bool {
+		host, ok := value.(*Host)
+		if !ok {
+			return true
+		}
+
+		if isHostReclaimable(host) {
+			ids = append(ids, host.ID)
+		}
+
+		return true
+	})
+
+	return ids
+}
+
+// isHostReclaimable returns whether the normal host has no peers and no uploads.
+func isHostReclaimable(host *Host) bool {
+	return host.PeerCount.Load() == 0 &&
+		host.ConcurrentUploadCount.Load() == 0 &&
+		host.Type == types.HostTypeNormal
+}
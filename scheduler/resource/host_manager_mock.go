@@ -45,6 +45,20 @@ func (mr *MockHostManagerMockRecorder) Delete(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockHostManager)(nil).Delete), arg0)
 }
 
+// DryRunGC mocks base method.
+func (m *MockHostManager) DryRunGC() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DryRunGC")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// DryRunGC indicates an expected call of DryRunGC.
+func (mr *MockHostManagerMockRecorder) DryRunGC() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DryRunGC", reflect.TypeOf((*MockHostManager)(nil).DryRunGC))
+}
+
 // Load mocks base method.
 func (m *MockHostManager) Load(arg0 string) (*Host, bool) {
 	m.ctrl.T.Helper()
@@ -75,6 +89,18 @@ func (mr *MockHostManagerMockRecorder) LoadOrStore(arg0 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadOrStore", reflect.TypeOf((*MockHostManager)(nil).LoadOrStore), arg0)
 }
 
+// Range mocks base method.
+func (m *MockHostManager) Range(f func(any, any) bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Range", f)
+}
+
+// Range indicates an expected call of Range.
+func (mr *MockHostManagerMockRecorder) Range(f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Range", reflect.TypeOf((*MockHostManager)(nil).Range), f)
+}
+
 // RunGC mocks base method.
 func (m *MockHostManager) RunGC() error {
 	m.ctrl.T.Helper()
@@ -406,3 +406,29 @@ func TestHostManager_RunGC(t *testing.T) {
 		})
 	}
 }
+
+func TestHostManager_DryRunGC(t *testing.T) {
+	ctl := gomock.NewController(t)
+	defer ctl.Finish()
+	gc := gc.NewMockGC(ctl)
+	gc.EXPECT().Add(gomock.Any()).Return(nil).Times(1)
+
+	mockHost := NewHost(
+		mockRawHost.ID, mockRawHost.IP, mockRawHost.Hostname,
+		mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
+	mockSeedHost := NewHost(
+		mockRawSeedHost.ID, mockRawSeedHost.IP, mockRawSeedHost.Hostname,
+		mockRawSeedHost.Port, mockRawSeedHost.DownloadPort, mockRawSeedHost.Type)
+	hostManager, err := newHostManager(mockHostGCConfig, gc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert := assert.New(t)
+	hostManager.Store(mockHost)
+	hostManager.Store(mockSeedHost)
+	assert.Equal(hostManager.DryRunGC(), []string{mockHost.ID})
+
+	_, loaded := hostManager.Load(mockHost.ID)
+	assert.Equal(loaded, true)
+}
@@ -154,6 +154,7 @@ func TestHost_NewHost(t *testing.T) {
 				assert.Equal(host.ConcurrentUploadCount.Load(), int32(0))
 				assert.Equal(host.UploadCount.Load(), int64(0))
 				assert.Equal(host.UploadFailedCount.Load(), int64(0))
+				assert.Equal(host.UploadBandwidth.Rate(), float64(0))
 				assert.NotNil(host.Peers)
 				assert.Equal(host.PeerCount.Load(), int32(0))
 				assert.NotEmpty(host.CreatedAt.Load())
@@ -176,6 +177,7 @@ func TestHost_NewHost(t *testing.T) {
 				assert.Equal(host.ConcurrentUploadCount.Load(), int32(0))
 				assert.Equal(host.UploadCount.Load(), int64(0))
 				assert.Equal(host.UploadFailedCount.Load(), int64(0))
+				assert.Equal(host.UploadBandwidth.Rate(), float64(0))
 				assert.NotNil(host.Peers)
 				assert.Equal(host.PeerCount.Load(), int32(0))
 				assert.NotEmpty(host.CreatedAt.Load())
@@ -199,6 +201,7 @@ func TestHost_NewHost(t *testing.T) {
 				assert.Equal(host.ConcurrentUploadCount.Load(), int32(0))
 				assert.Equal(host.UploadCount.Load(), int64(0))
 				assert.Equal(host.UploadFailedCount.Load(), int64(0))
+				assert.Equal(host.UploadBandwidth.Rate(), float64(0))
 				assert.NotNil(host.Peers)
 				assert.Equal(host.PeerCount.Load(), int32(0))
 				assert.NotEmpty(host.CreatedAt.Load())
@@ -223,6 +226,7 @@ func TestHost_NewHost(t *testing.T) {
 				assert.Equal(host.ConcurrentUploadCount.Load(), int32(0))
 				assert.Equal(host.UploadCount.Load(), int64(0))
 				assert.Equal(host.UploadFailedCount.Load(), int64(0))
+				assert.Equal(host.UploadBandwidth.Rate(), float64(0))
 				assert.NotNil(host.Peers)
 				assert.Equal(host.PeerCount.Load(), int32(0))
 				assert.NotEmpty(host.CreatedAt.Load())
@@ -247,6 +251,7 @@ func TestHost_NewHost(t *testing.T) {
 				assert.Equal(host.ConcurrentUploadCount.Load(), int32(0))
 				assert.Equal(host.UploadCount.Load(), int64(0))
 				assert.Equal(host.UploadFailedCount.Load(), int64(0))
+				assert.Equal(host.UploadBandwidth.Rate(), float64(0))
 				assert.NotNil(host.Peers)
 				assert.Equal(host.PeerCount.Load(), int32(0))
 				assert.NotEmpty(host.CreatedAt.Load())
@@ -271,6 +276,7 @@ func TestHost_NewHost(t *testing.T) {
 				assert.Equal(host.ConcurrentUploadCount.Load(), int32(0))
 				assert.Equal(host.UploadCount.Load(), int64(0))
 				assert.Equal(host.UploadFailedCount.Load(), int64(0))
+				assert.Equal(host.UploadBandwidth.Rate(), float64(0))
 				assert.NotNil(host.Peers)
 				assert.Equal(host.PeerCount.Load(), int32(0))
 				assert.NotEmpty(host.CreatedAt.Load())
@@ -295,6 +301,7 @@ func TestHost_NewHost(t *testing.T) {
 				assert.Equal(host.ConcurrentUploadCount.Load(), int32(0))
 				assert.Equal(host.UploadCount.Load(), int64(0))
 				assert.Equal(host.UploadFailedCount.Load(), int64(0))
+				assert.Equal(host.UploadBandwidth.Rate(), float64(0))
 				assert.NotNil(host.Peers)
 				assert.Equal(host.PeerCount.Load(), int32(0))
 				assert.NotEmpty(host.CreatedAt.Load())
@@ -319,6 +326,7 @@ func TestHost_NewHost(t *testing.T) {
 				assert.Equal(host.ConcurrentUploadCount.Load(), int32(0))
 				assert.Equal(host.UploadCount.Load(), int64(0))
 				assert.Equal(host.UploadFailedCount.Load(), int64(0))
+				assert.Equal(host.UploadBandwidth.Rate(), float64(0))
 				assert.NotNil(host.Peers)
 				assert.Equal(host.PeerCount.Load(), int32(0))
 				assert.NotEmpty(host.CreatedAt.Load())
@@ -343,6 +351,7 @@ func TestHost_NewHost(t *testing.T) {
 				assert.Equal(host.ConcurrentUploadCount.Load(), int32(0))
 				assert.Equal(host.UploadCount.Load(), int64(0))
 				assert.Equal(host.UploadFailedCount.Load(), int64(0))
+				assert.Equal(host.UploadBandwidth.Rate(), float64(0))
 				assert.NotNil(host.Peers)
 				assert.Equal(host.PeerCount.Load(), int32(0))
 				assert.NotEmpty(host.CreatedAt.Load())
@@ -367,6 +376,7 @@ func TestHost_NewHost(t *testing.T) {
 				assert.Equal(host.ConcurrentUploadCount.Load(), int32(0))
 				assert.Equal(host.UploadCount.Load(), int64(0))
 				assert.Equal(host.UploadFailedCount.Load(), int64(0))
+				assert.Equal(host.UploadBandwidth.Rate(), float64(0))
 				assert.NotNil(host.Peers)
 				assert.Equal(host.PeerCount.Load(), int32(0))
 				assert.NotEmpty(host.CreatedAt.Load())
@@ -391,6 +401,7 @@ func TestHost_NewHost(t *testing.T) {
 				assert.Equal(host.ConcurrentUploadCount.Load(), int32(0))
 				assert.Equal(host.UploadCount.Load(), int64(0))
 				assert.Equal(host.UploadFailedCount.Load(), int64(0))
+				assert.Equal(host.UploadBandwidth.Rate(), float64(0))
 				assert.NotNil(host.Peers)
 				assert.Equal(host.PeerCount.Load(), int32(0))
 				assert.NotEmpty(host.CreatedAt.Load())
@@ -415,6 +426,7 @@ func TestHost_NewHost(t *testing.T) {
 				assert.Equal(host.ConcurrentUploadCount.Load(), int32(0))
 				assert.Equal(host.UploadCount.Load(), int64(0))
 				assert.Equal(host.UploadFailedCount.Load(), int64(0))
+				assert.Equal(host.UploadBandwidth.Rate(), float64(0))
 				assert.NotNil(host.Peers)
 				assert.Equal(host.PeerCount.Load(), int32(0))
 				assert.NotEmpty(host.CreatedAt.Load())
@@ -439,6 +451,7 @@ func TestHost_NewHost(t *testing.T) {
 				assert.Equal(host.ConcurrentUploadCount.Load(), int32(0))
 				assert.Equal(host.UploadCount.Load(), int64(0))
 				assert.Equal(host.UploadFailedCount.Load(), int64(0))
+				assert.Equal(host.UploadBandwidth.Rate(), float64(0))
 				assert.NotNil(host.Peers)
 				assert.Equal(host.PeerCount.Load(), int32(0))
 				assert.NotEmpty(host.CreatedAt.Load())
@@ -48,8 +48,15 @@ type PeerManager interface {
 	// Delete deletes peer for a key.
 	Delete(string)
 
+	// Range calls f sequentially for each key and peer present in the peer manager,
+	// if f returns false, range stops the iteration.
+	Range(f func(any, any) bool)
+
 	// Try to reclaim peer.
 	RunGC() error
+
+	// DryRunGC returns the ids of peers reclaimed by the policy without reclaiming them.
+	DryRunGC(GCPolicy) []string
 }
 
 // peerManager contains content for peer manager.
@@ -57,11 +64,8 @@ type peerManager struct {
 	// Peer sync map.
 	*sync.Map
 
-	// peerTTL is time to live of peer.
-	peerTTL time.Duration
-
-	// hostTTL is time to live of host.
-	hostTTL time.Duration
+	// gcPolicy is the policy of reclaiming peer.
+	gcPolicy *gcPolicy
 
 	// pieceDownloadTimeout is timeout of downloading piece.
 	pieceDownloadTimeout time.Duration
@@ -71,11 +75,10 @@ type peerManager struct {
 }
 
 // New peer manager interface.
-func newPeerManager(cfg *config.GCConfig, gc pkggc.GC) (PeerManager, error) {
+func newPeerManager(cfg *config.GCConfig, gc pkggc.GC, gcPolicy *gcPolicy) (PeerManager, error) {
 	p := &peerManager{
 		Map:                  &sync.Map{},
-		peerTTL:              cfg.PeerTTL,
-		hostTTL:              cfg.HostTTL,
+		gcPolicy:             gcPolicy,
 		pieceDownloadTimeout: cfg.PieceDownloadTimeout,
 		mu:                   &sync.Mutex{},
 	}
@@ -142,6 +145,7 @@ func (p *peerManager) Delete(key string) {
 
 // Try to reclaim peer.
 func (p *peerManager) RunGC() error {
+	policy := p.gcPolicy.Load()
 	p.Map.Range(func(_, value any) bool {
 		peer, ok := value.(*Peer)
 		if !ok {
@@ -175,7 +179,7 @@ func (p *peerManager) RunGC() error {
 		// If the peer's elapsed exceeds the peer ttl,
 		// then set the peer state to PeerStateLeave and then delete peer.
 		elapsed := time.Since(peer.UpdatedAt.Load())
-		if elapsed > p.peerTTL {
+		if elapsed > policy.PeerTTL {
 			peer.Log.Info("peer elapsed exceeds the peer ttl, causing the peer to leave")
 			if err := peer.FSM.Event(context.Background(), PeerEventLeave); err != nil {
 				peer.Log.Errorf("peer fsm event failed: %s", err.Error())
@@ -188,7 +192,7 @@ func (p *peerManager) RunGC() error {
 		// If the host's elapsed exceeds the host ttl,
 		// then set the peer state to PeerStateLeave and then delete peer.
 		elapsed = time.Since(peer.Host.UpdatedAt.Load())
-		if elapsed > p.hostTTL {
+		if elapsed > policy.HostTTL {
 			peer.Log.Info("peer elapsed exceeds the host ttl, causing the peer to leave")
 			if err := peer.FSM.Event(context.Background(), PeerEventLeave); err != nil {
 				peer.Log.Errorf("peer fsm event failed: %s", err.Error())
@@ -238,3 +242,48 @@ func (p *peerManager) RunGC() error {
 
 	return nil
 }
+
+// DryRunGC returns the ids of peers reclaimed by the policy without reclaiming them.
+func (p *peerManager) DryRunGC(policy GCPolicy) []string {
+	var ids []string
+	p.Map.Range(func(_, value any) bool {
+		peer, ok := value.(*Peer)
+		if !ok {
+			return true
+		}
+
+		if p.isReclaimable(peer, policy) {
+			ids = append(ids, peer.ID)
+		}
+
+		return true
+	})
+
+	return ids
+}
+
+// isReclaimable returns whether the peer is reclaimed by the policy, it matches
+// the conditions of reclaiming peer in RunGC.
+func (p *peerManager) isReclaimable(peer *Peer, policy GCPolicy) bool {
+	if peer.FSM.Is(PeerStateLeave) || peer.FSM.Is(PeerStateFailed) {
+		return true
+	}
+
+	if (peer.FSM.Is(PeerStateRunning) || peer.FSM.Is(PeerStateBackToSource)) &&
+		time.Since(peer.PieceUpdatedAt.Load()) > p.pieceDownloadTimeout {
+		return true
+	}
+
+	if time.Since(peer.UpdatedAt.Load()) > policy.PeerTTL ||
+		time.Since(peer.Host.UpdatedAt.Load()) > policy.HostTTL {
+		return true
+	}
+
+	degree, err := peer.Task.PeerDegree(peer.ID)
+	if err != nil {
+		return true
+	}
+
+	return peer.Task.PeerCount() > PeerCountLimitForTask &&
+		peer.FSM.Is(PeerStateSucceeded) && degree == 0
+}
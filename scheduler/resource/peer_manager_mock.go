@@ -45,6 +45,20 @@ func (mr *MockPeerManagerMockRecorder) Delete(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockPeerManager)(nil).Delete), arg0)
 }
 
+// DryRunGC mocks base method.
+func (m *MockPeerManager) DryRunGC(arg0 GCPolicy) []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DryRunGC", arg0)
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// DryRunGC indicates an expected call of DryRunGC.
+func (mr *MockPeerManagerMockRecorder) DryRunGC(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DryRunGC", reflect.TypeOf((*MockPeerManager)(nil).DryRunGC), arg0)
+}
+
 // Load mocks base method.
 func (m *MockPeerManager) Load(arg0 string) (*Peer, bool) {
 	m.ctrl.T.Helper()
@@ -75,6 +89,18 @@ func (mr *MockPeerManagerMockRecorder) LoadOrStore(arg0 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadOrStore", reflect.TypeOf((*MockPeerManager)(nil).LoadOrStore), arg0)
 }
 
+// Range mocks base method.
+func (m *MockPeerManager) Range(f func(any, any) bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Range", f)
+}
+
+// Range indicates an expected call of Range.
+func (mr *MockPeerManagerMockRecorder) Range(f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Range", reflect.TypeOf((*MockPeerManager)(nil).Range), f)
+}
+
 // RunGC mocks base method.
 func (m *MockPeerManager) RunGC() error {
 	m.ctrl.T.Helper()
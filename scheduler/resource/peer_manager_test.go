@@ -74,7 +74,7 @@ func TestPeerManager_newPeerManager(t *testing.T) {
 			gc := gc.NewMockGC(ctl)
 			tc.mock(gc.EXPECT())
 
-			peerManager, err := newPeerManager(mockPeerGCConfig, gc)
+			peerManager, err := newPeerManager(mockPeerGCConfig, gc, newGCPolicy(mockPeerGCConfig))
 			tc.expect(t, peerManager, err)
 		})
 	}
@@ -138,7 +138,7 @@ func TestPeerManager_Load(t *testing.T) {
 				mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
 			mockTask := NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_DFDAEMON, mockTaskFilters, mockTaskHeader, mockTaskBackToSourceLimit, WithDigest(mockTaskDigest))
 			mockPeer := NewPeer(mockPeerID, mockTask, mockHost)
-			peerManager, err := newPeerManager(mockPeerGCConfig, gc)
+			peerManager, err := newPeerManager(mockPeerGCConfig, gc, newGCPolicy(mockPeerGCConfig))
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -195,7 +195,7 @@ func TestPeerManager_Store(t *testing.T) {
 				mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
 			mockTask := NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_DFDAEMON, mockTaskFilters, mockTaskHeader, mockTaskBackToSourceLimit, WithDigest(mockTaskDigest))
 			mockPeer := NewPeer(mockPeerID, mockTask, mockHost)
-			peerManager, err := newPeerManager(mockPeerGCConfig, gc)
+			peerManager, err := newPeerManager(mockPeerGCConfig, gc, newGCPolicy(mockPeerGCConfig))
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -250,7 +250,7 @@ func TestPeerManager_LoadOrStore(t *testing.T) {
 				mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
 			mockTask := NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_DFDAEMON, mockTaskFilters, mockTaskHeader, mockTaskBackToSourceLimit, WithDigest(mockTaskDigest))
 			mockPeer := NewPeer(mockPeerID, mockTask, mockHost)
-			peerManager, err := newPeerManager(mockPeerGCConfig, gc)
+			peerManager, err := newPeerManager(mockPeerGCConfig, gc, newGCPolicy(mockPeerGCConfig))
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -307,7 +307,7 @@ func TestPeerManager_Delete(t *testing.T) {
 				mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
 			mockTask := NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_DFDAEMON, mockTaskFilters, mockTaskHeader, mockTaskBackToSourceLimit, WithDigest(mockTaskDigest))
 			mockPeer := NewPeer(mockPeerID, mockTask, mockHost)
-			peerManager, err := newPeerManager(mockPeerGCConfig, gc)
+			peerManager, err := newPeerManager(mockPeerGCConfig, gc, newGCPolicy(mockPeerGCConfig))
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -551,7 +551,7 @@ func TestPeerManager_RunGC(t *testing.T) {
 				mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
 			mockTask := NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_DFDAEMON, mockTaskFilters, mockTaskHeader, mockTaskBackToSourceLimit, WithDigest(mockTaskDigest))
 			mockPeer := NewPeer(mockPeerID, mockTask, mockHost)
-			peerManager, err := newPeerManager(tc.gcConfig, gc)
+			peerManager, err := newPeerManager(tc.gcConfig, gc, newGCPolicy(tc.gcConfig))
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -560,3 +560,87 @@ func TestPeerManager_RunGC(t *testing.T) {
 		})
 	}
 }
+
+func TestPeerManager_DryRunGC(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy GCPolicy
+		mock   func(mockPeer *Peer)
+		expect func(t *testing.T, ids []string)
+	}{
+		{
+			name:   "peer exceeds the peer ttl",
+			policy: GCPolicy{PeerTTL: 1 * time.Microsecond, HostTTL: 1 * time.Hour},
+			mock: func(mockPeer *Peer) {
+				mockPeer.FSM.SetState(PeerStateSucceeded)
+			},
+			expect: func(t *testing.T, ids []string) {
+				assert := assert.New(t)
+				assert.Equal(ids, []string{mockPeerID})
+			},
+		},
+		{
+			name:   "peer exceeds the host ttl",
+			policy: GCPolicy{PeerTTL: 1 * time.Hour, HostTTL: 1 * time.Microsecond},
+			mock: func(mockPeer *Peer) {
+				mockPeer.FSM.SetState(PeerStateSucceeded)
+			},
+			expect: func(t *testing.T, ids []string) {
+				assert := assert.New(t)
+				assert.Equal(ids, []string{mockPeerID})
+			},
+		},
+		{
+			name:   "peer state is PeerStateLeave",
+			policy: GCPolicy{PeerTTL: 1 * time.Hour, HostTTL: 1 * time.Hour},
+			mock: func(mockPeer *Peer) {
+				mockPeer.FSM.SetState(PeerStateLeave)
+			},
+			expect: func(t *testing.T, ids []string) {
+				assert := assert.New(t)
+				assert.Equal(ids, []string{mockPeerID})
+			},
+		},
+		{
+			name:   "peer is not reclaimed",
+			policy: GCPolicy{PeerTTL: 1 * time.Hour, HostTTL: 1 * time.Hour},
+			mock: func(mockPeer *Peer) {
+				mockPeer.FSM.SetState(PeerStateSucceeded)
+			},
+			expect: func(t *testing.T, ids []string) {
+				assert := assert.New(t)
+				assert.Empty(ids)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			gc := gc.NewMockGC(ctl)
+			gc.EXPECT().Add(gomock.Any()).Return(nil).Times(1)
+
+			mockHost := NewHost(
+				mockRawHost.ID, mockRawHost.IP, mockRawHost.Hostname,
+				mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
+			mockTask := NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_DFDAEMON, mockTaskFilters, mockTaskHeader, mockTaskBackToSourceLimit, WithDigest(mockTaskDigest))
+			mockPeer := NewPeer(mockPeerID, mockTask, mockHost)
+			gcConfig := &config.GCConfig{
+				PieceDownloadTimeout: 1 * time.Hour,
+				PeerGCInterval:       1 * time.Second,
+			}
+			peerManager, err := newPeerManager(gcConfig, gc, newGCPolicy(gcConfig))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			peerManager.Store(mockPeer)
+			tc.mock(mockPeer)
+			ids := peerManager.DryRunGC(tc.policy)
+			_, loaded := peerManager.Load(mockPeer.ID)
+			assert.True(t, loaded)
+			tc.expect(t, ids)
+		})
+	}
+}
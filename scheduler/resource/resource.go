@@ -19,10 +19,13 @@
 package resource
 
 import (
+	"sync"
+
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 
+	logger "d7y.io/dragonfly/v2/internal/dflog"
 	"d7y.io/dragonfly/v2/pkg/gc"
 	"d7y.io/dragonfly/v2/scheduler/config"
 )
@@ -41,6 +44,9 @@ type Resource interface {
 	// Task manager interface.
 	TaskManager() TaskManager
 
+	// GCPolicy returns the policy of reclaiming peers, tasks and hosts in use.
+	GCPolicy() GCPolicy
+
 	// Stop resource serivce.
 	Stop() error
 }
@@ -59,11 +65,23 @@ type resource struct {
 	// Task manager interface.
 	taskManager TaskManager
 
+	// gcPolicy is the policy of reclaiming peers, tasks and hosts.
+	gcPolicy *gcPolicy
+
 	// Scheduler config.
 	config *config.Config
 
 	// TransportCredentials stores the Authenticator required to setup a client connection.
 	transportCredentials credentials.TransportCredentials
+
+	// snapshotDir is the directory of snapshot file.
+	snapshotDir string
+
+	// snapshotDone is closed when resource is stopped.
+	snapshotDone chan struct{}
+
+	// snapshotWG waits for the snapshot goroutine to exit.
+	snapshotWG sync.WaitGroup
 }
 
 // Option is a functional option for configuring the resource.
@@ -77,9 +95,17 @@ func WithTransportCredentials(creds credentials.TransportCredentials) Option {
 	}
 }
 
+// WithSnapshotDir sets the directory of snapshot file, the resource is snapshotted
+// into the directory if the snapshot of scheduler is enabled.
+func WithSnapshotDir(dir string) Option {
+	return func(r *resource) {
+		r.snapshotDir = dir
+	}
+}
+
 // New returns Resource interface.
 func New(cfg *config.Config, gc gc.GC, dynconfig config.DynconfigInterface, options ...Option) (Resource, error) {
-	resource := &resource{config: cfg}
+	resource := &resource{config: cfg, gcPolicy: newGCPolicy(&cfg.Scheduler.GC)}
 
 	for _, opt := range options {
 		opt(resource)
@@ -93,19 +119,22 @@ func New(cfg *config.Config, gc gc.GC, dynconfig config.DynconfigInterface, opti
 	resource.hostManager = hostManager
 
 	// Initialize task manager interface.
-	taskManager, err := newTaskManager(&cfg.Scheduler.GC, gc)
+	taskManager, err := newTaskManager(&cfg.Scheduler.GC, gc, resource.gcPolicy)
 	if err != nil {
 		return nil, err
 	}
 	resource.taskManager = taskManager
 
 	// Initialize peer manager interface.
-	peerManager, err := newPeerManager(&cfg.Scheduler.GC, gc)
+	peerManager, err := newPeerManager(&cfg.Scheduler.GC, gc, resource.gcPolicy)
 	if err != nil {
 		return nil, err
 	}
 	resource.peerManager = peerManager
 
+	// Override the gc policy by the gc config of scheduler cluster.
+	dynconfig.Register(resource.gcPolicy)
+
 	// Initialize seed peer interface.
 	if cfg.SeedPeer.Enable {
 		dialOptions := []grpc.DialOption{}
@@ -123,6 +152,18 @@ func New(cfg *config.Config, gc gc.GC, dynconfig config.DynconfigInterface, opti
 		resource.seedPeer = newSeedPeer(client, peerManager, hostManager)
 	}
 
+	// Reload the snapshot and serve snapshotting, the scheduler starts with
+	// empty resource if the snapshot is corrupted.
+	if cfg.Scheduler.Snapshot.Enable && resource.snapshotDir != "" {
+		if err := resource.loadSnapshot(); err != nil {
+			logger.Errorf("load resource snapshot failed: %s", err.Error())
+		}
+
+		resource.snapshotDone = make(chan struct{})
+		resource.snapshotWG.Add(1)
+		go resource.serveSnapshot()
+	}
+
 	return resource, nil
 }
 
@@ -146,8 +187,23 @@ func (r *resource) TaskManager() TaskManager {
 	return r.taskManager
 }
 
+// GCPolicy returns the policy of reclaiming peers, tasks and hosts in use.
+func (r *resource) GCPolicy() GCPolicy {
+	return r.gcPolicy.Load()
+}
+
 // Stop resource serivce.
 func (r *resource) Stop() error {
+	// Snapshot resource for the last time before stopping.
+	if r.snapshotDone != nil {
+		close(r.snapshotDone)
+		r.snapshotWG.Wait()
+
+		if err := r.saveSnapshot(); err != nil {
+			logger.Errorf("snapshot resource failed: %s", err.Error())
+		}
+	}
+
 	if r.config.SeedPeer.Enable {
 		return r.seedPeer.Stop()
 	}
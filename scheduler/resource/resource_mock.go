@@ -33,6 +33,20 @@ func (m *MockResource) EXPECT() *MockResourceMockRecorder {
 	return m.recorder
 }
 
+// GCPolicy mocks base method.
+func (m *MockResource) GCPolicy() GCPolicy {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GCPolicy")
+	ret0, _ := ret[0].(GCPolicy)
+	return ret0
+}
+
+// GCPolicy indicates an expected call of GCPolicy.
+func (mr *MockResourceMockRecorder) GCPolicy() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GCPolicy", reflect.TypeOf((*MockResource)(nil).GCPolicy))
+}
+
 // HostManager mocks base method.
 func (m *MockResource) HostManager() HostManager {
 	m.ctrl.T.Helper()
@@ -45,6 +45,7 @@ func TestResource_New(t *testing.T) {
 			mock: func(mg *gc.MockGCMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder) {
 				gomock.InOrder(
 					mg.Add(gomock.Any()).Return(nil).Times(3),
+					md.Register(gomock.Any()).Return().Times(1),
 					md.Get().Return(&config.DynconfigData{
 						Scheduler: &managerv2.Scheduler{
 							SeedPeers: []*managerv2.SeedPeer{
@@ -110,6 +111,7 @@ func TestResource_New(t *testing.T) {
 			mock: func(mg *gc.MockGCMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder) {
 				gomock.InOrder(
 					mg.Add(gomock.Any()).Return(nil).Times(3),
+					md.Register(gomock.Any()).Return().Times(1),
 					md.Get().Return(&config.DynconfigData{}, errors.New("foo")).Times(1),
 				)
 			},
@@ -124,6 +126,7 @@ func TestResource_New(t *testing.T) {
 			mock: func(mg *gc.MockGCMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder) {
 				gomock.InOrder(
 					mg.Add(gomock.Any()).Return(nil).Times(3),
+					md.Register(gomock.Any()).Return().Times(1),
 					md.Get().Return(&config.DynconfigData{
 						Scheduler: &managerv2.Scheduler{
 							SeedPeers: []*managerv2.SeedPeer{},
@@ -155,7 +158,10 @@ func TestResource_New(t *testing.T) {
 				},
 			},
 			mock: func(mg *gc.MockGCMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder) {
-				mg.Add(gomock.Any()).Return(nil).Times(3)
+				gomock.InOrder(
+					mg.Add(gomock.Any()).Return(nil).Times(3),
+					md.Register(gomock.Any()).Return().Times(1),
+				)
 			},
 			expect: func(t *testing.T, resource Resource, err error) {
 				assert := assert.New(t)
@@ -112,6 +112,11 @@ func (s *seedPeer) TriggerTask(ctx context.Context, rg *http.Range, task *Task)
 		urlMeta.Range = rg.URLMetaString()
 	}
 
+	// Seed peer downloads back-to-source with the preferred piece length of task.
+	if task.PieceLength > 0 {
+		ctx = common.AppendPieceLengthToOutgoingContext(ctx, task.PieceLength)
+	}
+
 	stream, err := s.client.ObtainSeeds(ctx, &cdnsystemv1.SeedRequest{
 		TaskId:  task.ID,
 		Url:     task.URL,
@@ -174,11 +179,12 @@ func (s *seedPeer) TriggerTask(ctx context.Context, rg *http.Range, task *Task)
 			}
 
 			if len(pieceSeed.PieceInfo.PieceMd5) > 0 {
-				piece.Digest = digest.New(digest.AlgorithmMD5, pieceSeed.PieceInfo.PieceMd5)
+				piece.Digest = digest.ParsePiece(pieceSeed.PieceInfo.PieceMd5)
 			}
 
 			peer.StorePiece(piece)
 			peer.FinishedPieces.Set(uint(pieceSeed.PieceInfo.PieceNum))
+			peer.Task.StorePiecePeer(pieceSeed.PieceInfo.PieceNum, peer.ID)
 			peer.AppendPieceCost(piece.Cost)
 
 			// When the piece is downloaded successfully,
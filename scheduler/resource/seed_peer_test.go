@@ -20,13 +20,19 @@ import (
 	"context"
 	"errors"
 	"reflect"
+	"strings"
 	"testing"
 
 	gomock "github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 
+	cdnsystemv1 "d7y.io/api/pkg/apis/cdnsystem/v1"
 	commonv2 "d7y.io/api/pkg/apis/common/v2"
 	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
+
+	"d7y.io/dragonfly/v2/pkg/types"
 )
 
 func TestSeedPeer_newSeedPeer(t *testing.T) {
@@ -58,9 +64,10 @@ func TestSeedPeer_newSeedPeer(t *testing.T) {
 
 func TestSeedPeer_TriggerTask(t *testing.T) {
 	tests := []struct {
-		name   string
-		mock   func(mc *MockSeedPeerClientMockRecorder)
-		expect func(t *testing.T, peer *Peer, result *schedulerv1.PeerResult, err error)
+		name        string
+		pieceLength int32
+		mock        func(mc *MockSeedPeerClientMockRecorder)
+		expect      func(t *testing.T, peer *Peer, result *schedulerv1.PeerResult, err error)
 	}{
 		{
 			name: "start obtain seed stream failed",
@@ -72,6 +79,21 @@ func TestSeedPeer_TriggerTask(t *testing.T) {
 				assert.EqualError(err, "foo")
 			},
 		},
+		{
+			name:        "start obtain seed stream with piece length",
+			pieceLength: 16 * 1024 * 1024,
+			mock: func(mc *MockSeedPeerClientMockRecorder) {
+				mc.ObtainSeeds(gomock.Any(), gomock.Any()).DoAndReturn(
+					func(ctx context.Context, req *cdnsystemv1.SeedRequest, opts ...grpc.CallOption) (cdnsystemv1.Seeder_ObtainSeedsClient, error) {
+						md, _ := metadata.FromOutgoingContext(ctx)
+						return nil, errors.New(strings.Join(md.Get(types.PieceLengthMetadataKey), ","))
+					}).Times(1)
+			},
+			expect: func(t *testing.T, peer *Peer, result *schedulerv1.PeerResult, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "16777216")
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -84,7 +106,7 @@ func TestSeedPeer_TriggerTask(t *testing.T) {
 			tc.mock(client.EXPECT())
 
 			seedPeer := newSeedPeer(client, peerManager, hostManager)
-			mockTask := NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_DFDAEMON, mockTaskFilters, mockTaskHeader, mockTaskBackToSourceLimit, WithDigest(mockTaskDigest))
+			mockTask := NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_DFDAEMON, mockTaskFilters, mockTaskHeader, mockTaskBackToSourceLimit, WithDigest(mockTaskDigest), WithPieceLength(tc.pieceLength))
 			peer, result, err := seedPeer.TriggerTask(context.Background(), nil, mockTask)
 			tc.expect(t, peer, result, err)
 		})
@@ -0,0 +1,381 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bits-and-blooms/bitset"
+
+	commonv2 "d7y.io/api/pkg/apis/common/v2"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+	"d7y.io/dragonfly/v2/pkg/digest"
+	nethttp "d7y.io/dragonfly/v2/pkg/net/http"
+	"d7y.io/dragonfly/v2/pkg/types"
+)
+
+const (
+	// SnapshotFileName is the file name of resource snapshot in the data directory.
+	SnapshotFileName = "resource-snapshot.json"
+)
+
+// snapshot is the persistent state of resource.
+type snapshot struct {
+	// Hosts of resource.
+	Hosts []hostSnapshot `json:"hosts"`
+
+	// Tasks of resource.
+	Tasks []taskSnapshot `json:"tasks"`
+
+	// Peers of resource.
+	Peers []peerSnapshot `json:"peers"`
+
+	// CreatedAt is the time of snapshotting.
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// hostSnapshot is the persistent state of host.
+type hostSnapshot struct {
+	ID                    string         `json:"id"`
+	Type                  types.HostType `json:"type"`
+	Hostname              string         `json:"hostname"`
+	IP                    string         `json:"ip"`
+	Port                  int32          `json:"port"`
+	DownloadPort          int32          `json:"downloadPort"`
+	OS                    string         `json:"os"`
+	Platform              string         `json:"platform"`
+	PlatformFamily        string         `json:"platformFamily"`
+	PlatformVersion       string         `json:"platformVersion"`
+	KernelVersion         string         `json:"kernelVersion"`
+	CPU                   CPU            `json:"cpu"`
+	Memory                Memory         `json:"memory"`
+	Network               Network        `json:"network"`
+	Disk                  Disk           `json:"disk"`
+	Build                 Build          `json:"build"`
+	ConcurrentUploadLimit int32          `json:"concurrentUploadLimit"`
+	CreatedAt             time.Time      `json:"createdAt"`
+	UpdatedAt             time.Time      `json:"updatedAt"`
+}
+
+// taskSnapshot is the persistent state of task.
+type taskSnapshot struct {
+	ID                string            `json:"id"`
+	Type              commonv2.TaskType `json:"type"`
+	URL               string            `json:"url"`
+	Digest            *digest.Digest    `json:"digest,omitempty"`
+	Tag               string            `json:"tag"`
+	Application       string            `json:"application"`
+	Filters           []string          `json:"filters"`
+	Header            map[string]string `json:"header"`
+	PieceLength       int32             `json:"pieceLength"`
+	DirectPiece       []byte            `json:"directPiece"`
+	ContentLength     int64             `json:"contentLength"`
+	TotalPieceCount   int32             `json:"totalPieceCount"`
+	BackToSourceLimit int32             `json:"backToSourceLimit"`
+	PriorityClass     int32             `json:"priorityClass"`
+	State             string            `json:"state"`
+	Pieces            []*Piece          `json:"pieces"`
+	CreatedAt         time.Time         `json:"createdAt"`
+	UpdatedAt         time.Time         `json:"updatedAt"`
+}
+
+// peerSnapshot is the persistent state of peer.
+type peerSnapshot struct {
+	ID             string            `json:"id"`
+	TaskID         string            `json:"taskID"`
+	HostID         string            `json:"hostID"`
+	Range          *nethttp.Range    `json:"range,omitempty"`
+	Priority       commonv2.Priority `json:"priority"`
+	State          string            `json:"state"`
+	FinishedPieces *bitset.BitSet    `json:"finishedPieces"`
+	Pieces         []*Piece          `json:"pieces"`
+	PieceUpdatedAt time.Time         `json:"pieceUpdatedAt"`
+	CreatedAt      time.Time         `json:"createdAt"`
+	UpdatedAt      time.Time         `json:"updatedAt"`
+}
+
+// snapshotPath returns the path of snapshot file.
+func (r *resource) snapshotPath() string {
+	return filepath.Join(r.snapshotDir, SnapshotFileName)
+}
+
+// serveSnapshot snapshots resource by interval until resource is stopped.
+func (r *resource) serveSnapshot() {
+	defer r.snapshotWG.Done()
+
+	ticker := time.NewTicker(r.config.Scheduler.Snapshot.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := r.saveSnapshot(); err != nil {
+				logger.Errorf("snapshot resource failed: %s", err.Error())
+			}
+		case <-r.snapshotDone:
+			return
+		}
+	}
+}
+
+// saveSnapshot writes the snapshot of resource into the snapshot file, the file is
+// replaced by renaming so that the snapshot is not corrupted by crash.
+func (r *resource) saveSnapshot() error {
+	s := snapshot{CreatedAt: time.Now()}
+	r.hostManager.Range(func(_, value any) bool {
+		if host, ok := value.(*Host); ok {
+			s.Hosts = append(s.Hosts, newHostSnapshot(host))
+		}
+
+		return true
+	})
+
+	r.taskManager.Range(func(_, value any) bool {
+		if task, ok := value.(*Task); ok {
+			s.Tasks = append(s.Tasks, newTaskSnapshot(task))
+		}
+
+		return true
+	})
+
+	r.peerManager.Range(func(_, value any) bool {
+		if peer, ok := value.(*Peer); ok && !peer.FSM.Is(PeerStateLeave) {
+			s.Peers = append(s.Peers, newPeerSnapshot(peer))
+		}
+
+		return true
+	})
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	tmp := r.snapshotPath() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, r.snapshotPath())
+}
+
+// loadSnapshot reloads the hosts, tasks and peers from the snapshot file, the hosts loaded
+// from dynconfig are kept. The parents of the peers are not reloaded, the running peers
+// are rescheduled when they report pieces again.
+func (r *resource) loadSnapshot() error {
+	data, err := os.ReadFile(r.snapshotPath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+
+		return err
+	}
+
+	var s snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	for _, h := range s.Hosts {
+		r.hostManager.LoadOrStore(h.restore())
+	}
+
+	for _, t := range s.Tasks {
+		r.taskManager.LoadOrStore(t.restore())
+	}
+
+	var count int
+	for _, p := range s.Peers {
+		host, loaded := r.hostManager.Load(p.HostID)
+		if !loaded {
+			continue
+		}
+
+		task, loaded := r.taskManager.Load(p.TaskID)
+		if !loaded {
+			continue
+		}
+
+		if _, loaded := r.peerManager.LoadOrStore(p.restore(task, host)); !loaded {
+			count++
+		}
+	}
+
+	logger.Infof("load resource snapshot created at %s, %d hosts, %d tasks and %d peers",
+		s.CreatedAt, len(s.Hosts), len(s.Tasks), count)
+	return nil
+}
+
+// newHostSnapshot returns the snapshot of host.
+func newHostSnapshot(h *Host) hostSnapshot {
+	return hostSnapshot{
+		ID:                    h.ID,
+		Type:                  h.Type,
+		Hostname:              h.Hostname,
+		IP:                    h.IP,
+		Port:                  h.Port,
+		DownloadPort:          h.DownloadPort,
+		OS:                    h.OS,
+		Platform:              h.Platform,
+		PlatformFamily:        h.PlatformFamily,
+		PlatformVersion:       h.PlatformVersion,
+		KernelVersion:         h.KernelVersion,
+		CPU:                   h.CPU,
+		Memory:                h.Memory,
+		Network:               h.Network,
+		Disk:                  h.Disk,
+		Build:                 h.Build,
+		ConcurrentUploadLimit: h.ConcurrentUploadLimit.Load(),
+		CreatedAt:             h.CreatedAt.Load(),
+		UpdatedAt:             h.UpdatedAt.Load(),
+	}
+}
+
+// restore returns the host of snapshot.
+func (h hostSnapshot) restore() *Host {
+	host := NewHost(h.ID, h.IP, h.Hostname, h.Port, h.DownloadPort, h.Type,
+		WithConcurrentUploadLimit(h.ConcurrentUploadLimit),
+		WithOS(h.OS),
+		WithPlatform(h.Platform),
+		WithPlatformFamily(h.PlatformFamily),
+		WithPlatformVersion(h.PlatformVersion),
+		WithKernelVersion(h.KernelVersion),
+		WithCPU(h.CPU),
+		WithMemory(h.Memory),
+		WithNetwork(h.Network),
+		WithDisk(h.Disk),
+		WithBuild(h.Build),
+	)
+	host.CreatedAt.Store(h.CreatedAt)
+	host.UpdatedAt.Store(h.UpdatedAt)
+	return host
+}
+
+// newTaskSnapshot returns the snapshot of task.
+func newTaskSnapshot(t *Task) taskSnapshot {
+	s := taskSnapshot{
+		ID:                t.ID,
+		Type:              t.Type,
+		URL:               t.URL,
+		Digest:            t.Digest,
+		Tag:               t.Tag,
+		Application:       t.Application,
+		Filters:           t.Filters,
+		Header:            t.Header,
+		PieceLength:       t.PieceLength,
+		DirectPiece:       t.DirectPiece,
+		ContentLength:     t.ContentLength.Load(),
+		TotalPieceCount:   t.TotalPieceCount.Load(),
+		BackToSourceLimit: t.BackToSourceLimit.Load(),
+		PriorityClass:     t.priorityClass.Load(),
+		State:             t.FSM.Current(),
+		CreatedAt:         t.CreatedAt.Load(),
+		UpdatedAt:         t.UpdatedAt.Load(),
+	}
+
+	t.Pieces.Range(func(_, value any) bool {
+		if piece, ok := value.(*Piece); ok {
+			s.Pieces = append(s.Pieces, piece)
+		}
+
+		return true
+	})
+
+	return s
+}
+
+// restore returns the task of snapshot.
+func (t taskSnapshot) restore() *Task {
+	options := []TaskOption{
+		WithPieceLength(t.PieceLength),
+		WithPriorityClass(types.PriorityClass(t.PriorityClass)),
+	}
+	if t.Digest != nil {
+		options = append(options, WithDigest(t.Digest))
+	}
+
+	task := NewTask(t.ID, t.URL, t.Tag, t.Application, t.Type, t.Filters, t.Header, t.BackToSourceLimit, options...)
+	task.DirectPiece = t.DirectPiece
+	task.ContentLength.Store(t.ContentLength)
+	task.TotalPieceCount.Store(t.TotalPieceCount)
+	task.FSM.SetState(t.State)
+	for _, piece := range t.Pieces {
+		task.StorePiece(piece)
+	}
+
+	task.CreatedAt.Store(t.CreatedAt)
+	task.UpdatedAt.Store(t.UpdatedAt)
+	return task
+}
+
+// newPeerSnapshot returns the snapshot of peer.
+func newPeerSnapshot(p *Peer) peerSnapshot {
+	s := peerSnapshot{
+		ID:             p.ID,
+		TaskID:         p.Task.ID,
+		HostID:         p.Host.ID,
+		Range:          p.Range,
+		Priority:       p.Priority,
+		State:          p.FSM.Current(),
+		FinishedPieces: p.FinishedPieces.Clone(),
+		PieceUpdatedAt: p.PieceUpdatedAt.Load(),
+		CreatedAt:      p.CreatedAt.Load(),
+		UpdatedAt:      p.UpdatedAt.Load(),
+	}
+
+	p.Pieces.Range(func(_, value any) bool {
+		if piece, ok := value.(*Piece); ok {
+			s.Pieces = append(s.Pieces, piece)
+		}
+
+		return true
+	})
+
+	return s
+}
+
+// restore returns the peer of snapshot, which belongs to the task and host.
+func (p peerSnapshot) restore(task *Task, host *Host) *Peer {
+	options := []PeerOption{WithPriority(p.Priority)}
+	if p.Range != nil {
+		options = append(options, WithRange(*p.Range))
+	}
+
+	peer := NewPeer(p.ID, task, host, options...)
+	peer.FSM.SetState(p.State)
+	if p.FinishedPieces != nil {
+		peer.FinishedPieces = p.FinishedPieces
+		for i, ok := peer.FinishedPieces.NextSet(0); ok; i, ok = peer.FinishedPieces.NextSet(i + 1) {
+			task.StorePiecePeer(int32(i), peer.ID)
+		}
+	}
+
+	for _, piece := range p.Pieces {
+		peer.StorePiece(piece)
+	}
+
+	peer.PieceUpdatedAt.Store(p.PieceUpdatedAt)
+	peer.CreatedAt.Store(p.CreatedAt)
+	peer.UpdatedAt.Store(p.UpdatedAt)
+	return peer
+}
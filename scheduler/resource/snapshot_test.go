@@ -0,0 +1,178 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package resource
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	commonv2 "d7y.io/api/pkg/apis/common/v2"
+
+	"d7y.io/dragonfly/v2/pkg/gc"
+	"d7y.io/dragonfly/v2/pkg/types"
+	"d7y.io/dragonfly/v2/scheduler/config"
+)
+
+func newSnapshotTestResource(t *testing.T, ctl *gomock.Controller, dir string) *resource {
+	mockGC := gc.NewMockGC(ctl)
+	mockGC.EXPECT().Add(gomock.Any()).Return(nil).AnyTimes()
+
+	hostManager, err := newHostManager(mockHostGCConfig, mockGC)
+	assert.NoError(t, err)
+	taskManager, err := newTaskManager(mockTaskGCConfig, mockGC, newGCPolicy(mockTaskGCConfig))
+	assert.NoError(t, err)
+	peerManager, err := newPeerManager(mockPeerGCConfig, mockGC, newGCPolicy(mockPeerGCConfig))
+	assert.NoError(t, err)
+
+	return &resource{
+		config:      config.New(),
+		hostManager: hostManager,
+		taskManager: taskManager,
+		peerManager: peerManager,
+		snapshotDir: dir,
+	}
+}
+
+func TestResource_Snapshot(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   func(t *testing.T, r *resource)
+		expect func(t *testing.T, r *resource, err error)
+	}{
+		{
+			name: "reload running peer",
+			mock: func(t *testing.T, r *resource) {
+				host := NewHost(
+					mockRawHost.ID, mockRawHost.IP, mockRawHost.Hostname,
+					mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type, WithCPU(mockCPU))
+				task := NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_DFDAEMON, mockTaskFilters, mockTaskHeader, mockTaskBackToSourceLimit,
+					WithDigest(mockTaskDigest), WithPriorityClass(types.PriorityClassCritical))
+				task.ContentLength.Store(1024)
+				task.TotalPieceCount.Store(2)
+				task.FSM.SetState(TaskStateRunning)
+				task.StorePiece(mockPiece)
+
+				peer := NewPeer(mockPeerID, task, host, WithPriority(commonv2.Priority_LEVEL4))
+				peer.FSM.SetState(PeerStateRunning)
+				peer.FinishedPieces.Set(0)
+				peer.StorePiece(mockPiece)
+
+				r.hostManager.Store(host)
+				r.taskManager.Store(task)
+				r.peerManager.Store(peer)
+			},
+			expect: func(t *testing.T, r *resource, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+
+				host, loaded := r.hostManager.Load(mockRawHost.ID)
+				assert.True(loaded)
+				assert.Equal(mockRawHost.Type, host.Type)
+				assert.Equal(mockCPU, host.CPU)
+
+				task, loaded := r.taskManager.Load(mockTaskID)
+				assert.True(loaded)
+				assert.True(task.FSM.Is(TaskStateRunning))
+				assert.Equal(mockTaskDigest, task.Digest)
+				assert.Equal(int64(1024), task.ContentLength.Load())
+				assert.Equal(int32(2), task.TotalPieceCount.Load())
+				assert.Equal(types.PriorityClassCritical, task.PriorityClass())
+				piece, loaded := task.LoadPiece(mockPiece.Number)
+				assert.True(loaded)
+				assert.Equal(mockPiece.Length, piece.Length)
+
+				peer, loaded := r.peerManager.Load(mockPeerID)
+				assert.True(loaded)
+				assert.True(peer.FSM.Is(PeerStateRunning))
+				assert.Equal(commonv2.Priority_LEVEL4, peer.Priority)
+				assert.True(peer.FinishedPieces.Test(0))
+				assert.Same(host, peer.Host)
+				assert.Same(task, peer.Task)
+				assert.Equal(1, task.PeerCount())
+				assert.Equal(int32(1), host.PeerCount.Load())
+			},
+		},
+		{
+			name: "peer which has left is not reloaded",
+			mock: func(t *testing.T, r *resource) {
+				host := NewHost(
+					mockRawHost.ID, mockRawHost.IP, mockRawHost.Hostname,
+					mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
+				task := NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_DFDAEMON, mockTaskFilters, mockTaskHeader, mockTaskBackToSourceLimit)
+				peer := NewPeer(mockPeerID, task, host)
+				peer.FSM.SetState(PeerStateLeave)
+
+				r.hostManager.Store(host)
+				r.taskManager.Store(task)
+				r.peerManager.Store(peer)
+			},
+			expect: func(t *testing.T, r *resource, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+
+				_, loaded := r.taskManager.Load(mockTaskID)
+				assert.True(loaded)
+				_, loaded = r.peerManager.Load(mockPeerID)
+				assert.False(loaded)
+			},
+		},
+		{
+			name: "snapshot does not exist",
+			mock: func(t *testing.T, r *resource) {},
+			expect: func(t *testing.T, r *resource, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.NoError(os.Remove(r.snapshotPath()))
+				assert.NoError(r.loadSnapshot())
+
+				_, loaded := r.peerManager.Load(mockPeerID)
+				assert.False(loaded)
+			},
+		},
+		{
+			name: "snapshot is corrupted",
+			mock: func(t *testing.T, r *resource) {},
+			expect: func(t *testing.T, r *resource, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.NoError(os.WriteFile(r.snapshotPath(), []byte("foo"), 0600))
+				assert.Error(r.loadSnapshot())
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			dir := t.TempDir()
+			r := newSnapshotTestResource(t, ctl, dir)
+			tc.mock(t, r)
+			assert.NoError(t, r.saveSnapshot())
+			_, err := os.Stat(filepath.Join(dir, SnapshotFileName))
+			assert.NoError(t, err)
+
+			reloaded := newSnapshotTestResource(t, ctl, dir)
+			tc.expect(t, reloaded, reloaded.loadSnapshot())
+		})
+	}
+}
@@ -19,6 +19,7 @@ package resource
 import (
 	"context"
 	"errors"
+	"fmt"
 	"sort"
 	"sync"
 	"time"
@@ -45,6 +46,14 @@ const (
 	EmptyFileSize = 0
 )
 
+const (
+	// MinPieceLength is the minimum preferred piece length of task, which is 1MiB.
+	MinPieceLength = 1024 * 1024
+
+	// MaxPieceLength is the maximum preferred piece length of task, which is 64MiB.
+	MaxPieceLength = 64 * 1024 * 1024
+)
+
 const (
 	// Peer failure limit in task.
 	FailedPeerCountLimit = 200
@@ -84,6 +93,15 @@ const (
 	TaskEventLeave = "Leave"
 )
 
+// ValidatePieceLength validates the preferred piece length of task, zero means no preference.
+func ValidatePieceLength(pieceLength int32) error {
+	if pieceLength != 0 && (pieceLength < MinPieceLength || pieceLength > MaxPieceLength) {
+		return fmt.Errorf("piece length %d is out of range [%d, %d]", pieceLength, MinPieceLength, MaxPieceLength)
+	}
+
+	return nil
+}
+
 // TaskOption is a functional option for task.
 type TaskOption func(task *Task)
 
@@ -101,6 +119,13 @@ func WithDigest(d *digest.Digest) TaskOption {
 	}
 }
 
+// WithPriorityClass set PriorityClass for task.
+func WithPriorityClass(class types.PriorityClass) TaskOption {
+	return func(t *Task) {
+		t.priorityClass.Store(int32(class))
+	}
+}
+
 // Task contains content for task.
 type Task struct {
 	// ID is task id.
@@ -145,12 +170,19 @@ type Task struct {
 	// BackToSourcePeers is back-to-source sync map.
 	BackToSourcePeers set.SafeSet[string]
 
+	// priorityClass is the highest priority class requested by the peers of task.
+	priorityClass *atomic.Int32
+
 	// Task state machine.
 	FSM *fsm.FSM
 
 	// Piece sync map.
 	Pieces *sync.Map
 
+	// piecePeers is the ids of peers holding each finished piece, keyed by piece number,
+	// so that the pieces finished by peers are reused before the peers finish the task.
+	piecePeers *sync.Map
+
 	// DAG is directed acyclic graph of peers.
 	DAG dag.DAG[*Peer]
 
@@ -184,7 +216,9 @@ func NewTask(id, url, tag, application string, typ commonv2.TaskType, filters []
 		TotalPieceCount:   atomic.NewInt32(0),
 		BackToSourceLimit: atomic.NewInt32(backToSourceLimit),
 		BackToSourcePeers: set.NewSafeSet[string](),
+		priorityClass:     atomic.NewInt32(int32(types.PriorityClassNormal)),
 		Pieces:            &sync.Map{},
+		piecePeers:        &sync.Map{},
 		DAG:               dag.NewDAG[*Peer](),
 		PeerFailedCount:   atomic.NewInt32(0),
 		CreatedAt:         atomic.NewTime(time.Now()),
@@ -264,6 +298,7 @@ func (t *Task) DeletePeer(key string) {
 		t.Log.Error(err)
 	}
 
+	t.DeletePiecePeer(key)
 	t.DAG.DeleteVertex(key)
 }
 
@@ -433,6 +468,47 @@ func (t *Task) DeletePiece(key int32) {
 	t.Pieces.Delete(key)
 }
 
+// StorePiecePeer records that the peer holds the finished piece.
+func (t *Task) StorePiecePeer(number int32, peerID string) {
+	rawPeerIDs, _ := t.piecePeers.LoadOrStore(number, set.NewSafeSet[string]())
+	rawPeerIDs.(set.SafeSet[string]).Add(peerID)
+}
+
+// LoadPiecePeers returns ids of the peers holding the finished piece.
+func (t *Task) LoadPiecePeers(number int32) []string {
+	rawPeerIDs, loaded := t.piecePeers.Load(number)
+	if !loaded {
+		return nil
+	}
+
+	return rawPeerIDs.(set.SafeSet[string]).Values()
+}
+
+// DeletePiecePeer deletes the peer from the holders of finished pieces,
+// e.g. the peer leaves or dies mid-download.
+func (t *Task) DeletePiecePeer(peerID string) {
+	t.piecePeers.Range(func(_, value any) bool {
+		value.(set.SafeSet[string]).Delete(peerID)
+		return true
+	})
+}
+
+// HoldsMissingPieces returns whether the peer of holderID holds the finished
+// pieces which have not been downloaded by the peer.
+func (t *Task) HoldsMissingPieces(holderID string, peer *Peer) bool {
+	var holds bool
+	t.piecePeers.Range(func(key, value any) bool {
+		if value.(set.SafeSet[string]).Contains(holderID) && !peer.FinishedPieces.Test(uint(key.(int32))) {
+			holds = true
+			return false
+		}
+
+		return true
+	})
+
+	return holds
+}
+
 // SizeScope return task size scope type.
 func (t *Task) SizeScope() commonv2.SizeScope {
 	if t.ContentLength.Load() < 0 {
@@ -458,6 +534,22 @@ func (t *Task) SizeScope() commonv2.SizeScope {
 	return commonv2.SizeScope_NORMAL
 }
 
+// PriorityClass returns the priority class of task.
+func (t *Task) PriorityClass() types.PriorityClass {
+	return types.PriorityClass(t.priorityClass.Load())
+}
+
+// RaisePriorityClass raises the priority class of task to class,
+// the priority class is not lowered by the peers of lower class.
+func (t *Task) RaisePriorityClass(class types.PriorityClass) {
+	for {
+		current := t.priorityClass.Load()
+		if int32(class) <= current || t.priorityClass.CompareAndSwap(current, int32(class)) {
+			return
+		}
+	}
+}
+
 // CanBackToSource represents whether task can back-to-source.
 func (t *Task) CanBackToSource() bool {
 	return int32(t.BackToSourcePeers.Len()) <= t.BackToSourceLimit.Load() && (t.Type == commonv2.TaskType_DFDAEMON || t.Type == commonv2.TaskType_DFSTORE)
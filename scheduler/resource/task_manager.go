@@ -19,8 +19,12 @@
 package resource
 
 import (
+	"context"
+	"sort"
 	"sync"
+	"time"
 
+	logger "d7y.io/dragonfly/v2/internal/dflog"
 	pkggc "d7y.io/dragonfly/v2/pkg/gc"
 	"d7y.io/dragonfly/v2/scheduler/config"
 )
@@ -46,20 +50,31 @@ type TaskManager interface {
 	// Delete deletes task for a key.
 	Delete(string)
 
+	// Range calls f sequentially for each key and task present in the task manager,
+	// if f returns false, range stops the iteration.
+	Range(f func(any, any) bool)
+
 	// Try to reclaim task.
 	RunGC() error
+
+	// DryRunGC returns the ids of tasks reclaimed by the policy without reclaiming them.
+	DryRunGC(GCPolicy) []string
 }
 
 // taskManager contains content for task manager.
 type taskManager struct {
 	// Task sync map.
 	*sync.Map
+
+	// gcPolicy is the policy of reclaiming task.
+	gcPolicy *gcPolicy
 }
 
 // New task manager interface.
-func newTaskManager(cfg *config.GCConfig, gc pkggc.GC) (TaskManager, error) {
+func newTaskManager(cfg *config.GCConfig, gc pkggc.GC, gcPolicy *gcPolicy) (TaskManager, error) {
 	t := &taskManager{
-		Map: &sync.Map{},
+		Map:      &sync.Map{},
+		gcPolicy: gcPolicy,
 	}
 
 	if err := gc.Add(pkggc.Task{
@@ -104,21 +119,105 @@ func (t *taskManager) Delete(key string) {
 
 // Try to reclaim task.
 func (t *taskManager) RunGC() error {
+	for _, task := range t.reclaimableTasks(t.gcPolicy.Load()) {
+		// If there is no peer then task will be reclaimed.
+		if task.PeerCount() == 0 {
+			task.Log.Info("task has been reclaimed")
+			t.Delete(task.ID)
+			continue
+		}
+
+		// If the idle task exceeds the task ttl or the max count of tasks,
+		// then set the peers state to PeerStateLeave, and the task will be
+		// reclaimed after the peers have been reclaimed.
+		task.Log.Info("task exceeds the gc policy, causing the peers to leave")
+		for _, vertex := range task.DAG.GetVertices() {
+			peer := vertex.Value
+			if peer == nil || peer.FSM.Is(PeerStateLeave) {
+				continue
+			}
+
+			if err := peer.FSM.Event(context.Background(), PeerEventLeave); err != nil {
+				peer.Log.Errorf("peer fsm event failed: %s", err.Error())
+			}
+		}
+	}
+
+	return nil
+}
+
+// DryRunGC returns the ids of tasks reclaimed by the policy without reclaiming them.
+func (t *taskManager) DryRunGC(policy GCPolicy) []string {
+	var ids []string
+	for _, task := range t.reclaimableTasks(policy) {
+		ids = append(ids, task.ID)
+	}
+
+	return ids
+}
+
+// reclaimableTasks returns the tasks reclaimed by the policy. The task without peers is reclaimable,
+// and the idle task is reclaimable if it exceeds the task ttl or the max count of tasks,
+// the least recently updated idle tasks are reclaimed first.
+func (t *taskManager) reclaimableTasks(policy GCPolicy) []*Task {
+	var (
+		count     int
+		tasks     []*Task
+		idleTasks []*Task
+	)
 	t.Map.Range(func(_, value any) bool {
 		task, ok := value.(*Task)
 		if !ok {
-			task.Log.Error("invalid task")
+			logger.Error("invalid task")
 			return true
 		}
 
-		// If there is no peer then task will be reclaimed.
+		count++
 		if task.PeerCount() == 0 {
-			task.Log.Info("task has been reclaimed")
-			t.Delete(task.ID)
+			tasks = append(tasks, task)
+			return true
+		}
+
+		if isTaskIdle(task) {
+			idleTasks = append(idleTasks, task)
 		}
 
 		return true
 	})
 
-	return nil
+	sort.Slice(idleTasks, func(i, j int) bool {
+		return idleTasks[i].UpdatedAt.Load().Before(idleTasks[j].UpdatedAt.Load())
+	})
+
+	// Tasks without peers are reclaimed, so they are not counted in the max count of tasks.
+	exceeded := count - len(tasks) - policy.MaxTaskCount
+	for _, task := range idleTasks {
+		if policy.MaxTaskCount > 0 && exceeded > 0 {
+			tasks = append(tasks, task)
+			exceeded--
+			continue
+		}
+
+		if policy.TaskTTL > 0 && time.Since(task.UpdatedAt.Load()) > policy.TaskTTL {
+			tasks = append(tasks, task)
+		}
+	}
+
+	return tasks
+}
+
+// isTaskIdle returns whether the task has no downloading peers.
+func isTaskIdle(task *Task) bool {
+	for _, vertex := range task.DAG.GetVertices() {
+		peer := vertex.Value
+		if peer == nil {
+			continue
+		}
+
+		if !peer.FSM.Is(PeerStateSucceeded) && !peer.FSM.Is(PeerStateFailed) && !peer.FSM.Is(PeerStateLeave) {
+			return false
+		}
+	}
+
+	return true
 }
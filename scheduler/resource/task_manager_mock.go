@@ -45,6 +45,20 @@ func (mr *MockTaskManagerMockRecorder) Delete(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockTaskManager)(nil).Delete), arg0)
 }
 
+// DryRunGC mocks base method.
+func (m *MockTaskManager) DryRunGC(arg0 GCPolicy) []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DryRunGC", arg0)
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// DryRunGC indicates an expected call of DryRunGC.
+func (mr *MockTaskManagerMockRecorder) DryRunGC(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DryRunGC", reflect.TypeOf((*MockTaskManager)(nil).DryRunGC), arg0)
+}
+
 // Load mocks base method.
 func (m *MockTaskManager) Load(arg0 string) (*Task, bool) {
 	m.ctrl.T.Helper()
@@ -75,6 +89,18 @@ func (mr *MockTaskManagerMockRecorder) LoadOrStore(arg0 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadOrStore", reflect.TypeOf((*MockTaskManager)(nil).LoadOrStore), arg0)
 }
 
+// Range mocks base method.
+func (m *MockTaskManager) Range(f func(any, any) bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Range", f)
+}
+
+// Range indicates an expected call of Range.
+func (mr *MockTaskManagerMockRecorder) Range(f interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Range", reflect.TypeOf((*MockTaskManager)(nil).Range), f)
+}
+
 // RunGC mocks base method.
 func (m *MockTaskManager) RunGC() error {
 	m.ctrl.T.Helper()
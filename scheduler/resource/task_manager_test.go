@@ -72,7 +72,7 @@ func TestTaskManager_newTaskManager(t *testing.T) {
 			gc := gc.NewMockGC(ctl)
 			tc.mock(gc.EXPECT())
 
-			taskManager, err := newTaskManager(mockTaskGCConfig, gc)
+			taskManager, err := newTaskManager(mockTaskGCConfig, gc, newGCPolicy(mockTaskGCConfig))
 			tc.expect(t, taskManager, err)
 		})
 	}
@@ -132,7 +132,7 @@ func TestTaskManager_Load(t *testing.T) {
 			tc.mock(gc.EXPECT())
 
 			mockTask := NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_DFDAEMON, mockTaskFilters, mockTaskHeader, mockTaskBackToSourceLimit, WithDigest(mockTaskDigest))
-			taskManager, err := newTaskManager(mockTaskGCConfig, gc)
+			taskManager, err := newTaskManager(mockTaskGCConfig, gc, newGCPolicy(mockTaskGCConfig))
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -185,7 +185,7 @@ func TestTaskManager_Store(t *testing.T) {
 			tc.mock(gc.EXPECT())
 
 			mockTask := NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_DFDAEMON, mockTaskFilters, mockTaskHeader, mockTaskBackToSourceLimit, WithDigest(mockTaskDigest))
-			taskManager, err := newTaskManager(mockTaskGCConfig, gc)
+			taskManager, err := newTaskManager(mockTaskGCConfig, gc, newGCPolicy(mockTaskGCConfig))
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -236,7 +236,7 @@ func TestTaskManager_LoadOrStore(t *testing.T) {
 			tc.mock(gc.EXPECT())
 
 			mockTask := NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_DFDAEMON, mockTaskFilters, mockTaskHeader, mockTaskBackToSourceLimit, WithDigest(mockTaskDigest))
-			taskManager, err := newTaskManager(mockTaskGCConfig, gc)
+			taskManager, err := newTaskManager(mockTaskGCConfig, gc, newGCPolicy(mockTaskGCConfig))
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -289,7 +289,7 @@ func TestTaskManager_Delete(t *testing.T) {
 			tc.mock(gc.EXPECT())
 
 			mockTask := NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_DFDAEMON, mockTaskFilters, mockTaskHeader, mockTaskBackToSourceLimit, WithDigest(mockTaskDigest))
-			taskManager, err := newTaskManager(mockTaskGCConfig, gc)
+			taskManager, err := newTaskManager(mockTaskGCConfig, gc, newGCPolicy(mockTaskGCConfig))
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -301,12 +301,14 @@ func TestTaskManager_Delete(t *testing.T) {
 
 func TestTaskManager_RunGC(t *testing.T) {
 	tests := []struct {
-		name   string
-		mock   func(m *gc.MockGCMockRecorder)
-		expect func(t *testing.T, taskManager TaskManager, mockTask *Task, mockPeer *Peer)
+		name     string
+		gcConfig *config.GCConfig
+		mock     func(m *gc.MockGCMockRecorder)
+		expect   func(t *testing.T, taskManager TaskManager, mockTask *Task, mockPeer *Peer)
 	}{
 		{
-			name: "task reclaimed",
+			name:     "task reclaimed",
+			gcConfig: mockTaskGCConfig,
 			mock: func(m *gc.MockGCMockRecorder) {
 				m.Add(gomock.Any()).Return(nil).Times(1)
 			},
@@ -320,7 +322,8 @@ func TestTaskManager_RunGC(t *testing.T) {
 			},
 		},
 		{
-			name: "task has peers",
+			name:     "task has peers",
+			gcConfig: mockTaskGCConfig,
 			mock: func(m *gc.MockGCMockRecorder) {
 				m.Add(gomock.Any()).Return(nil).Times(1)
 			},
@@ -337,6 +340,84 @@ func TestTaskManager_RunGC(t *testing.T) {
 				assert.Equal(task.FSM.Current(), TaskStatePending)
 			},
 		},
+		{
+			name: "idle task exceeds the task ttl",
+			gcConfig: &config.GCConfig{
+				TaskGCInterval: 1 * time.Second,
+				TaskTTL:        1 * time.Microsecond,
+			},
+			mock: func(m *gc.MockGCMockRecorder) {
+				m.Add(gomock.Any()).Return(nil).Times(1)
+			},
+			expect: func(t *testing.T, taskManager TaskManager, mockTask *Task, mockPeer *Peer) {
+				assert := assert.New(t)
+				taskManager.Store(mockTask)
+				mockTask.StorePeer(mockPeer)
+				mockPeer.FSM.SetState(PeerStateSucceeded)
+				err := taskManager.RunGC()
+				assert.NoError(err)
+
+				_, loaded := taskManager.Load(mockTask.ID)
+				assert.Equal(loaded, true)
+				assert.Equal(mockPeer.FSM.Current(), PeerStateLeave)
+
+				mockTask.DeletePeer(mockPeer.ID)
+				err = taskManager.RunGC()
+				assert.NoError(err)
+
+				_, loaded = taskManager.Load(mockTask.ID)
+				assert.Equal(loaded, false)
+			},
+		},
+		{
+			name: "task exceeds the task ttl and has running peers",
+			gcConfig: &config.GCConfig{
+				TaskGCInterval: 1 * time.Second,
+				TaskTTL:        1 * time.Microsecond,
+			},
+			mock: func(m *gc.MockGCMockRecorder) {
+				m.Add(gomock.Any()).Return(nil).Times(1)
+			},
+			expect: func(t *testing.T, taskManager TaskManager, mockTask *Task, mockPeer *Peer) {
+				assert := assert.New(t)
+				taskManager.Store(mockTask)
+				mockTask.StorePeer(mockPeer)
+				mockPeer.FSM.SetState(PeerStateRunning)
+				err := taskManager.RunGC()
+				assert.NoError(err)
+
+				_, loaded := taskManager.Load(mockTask.ID)
+				assert.Equal(loaded, true)
+				assert.Equal(mockPeer.FSM.Current(), PeerStateRunning)
+			},
+		},
+		{
+			name: "idle tasks exceed the max task count",
+			gcConfig: &config.GCConfig{
+				TaskGCInterval: 1 * time.Second,
+				MaxTaskCount:   1,
+			},
+			mock: func(m *gc.MockGCMockRecorder) {
+				m.Add(gomock.Any()).Return(nil).Times(1)
+			},
+			expect: func(t *testing.T, taskManager TaskManager, mockTask *Task, mockPeer *Peer) {
+				assert := assert.New(t)
+				taskManager.Store(mockTask)
+				mockTask.StorePeer(mockPeer)
+				mockPeer.FSM.SetState(PeerStateSucceeded)
+
+				newTask := NewTask("bar", mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_DFDAEMON, mockTaskFilters, mockTaskHeader, mockTaskBackToSourceLimit)
+				newPeer := NewPeer("bar", newTask, mockPeer.Host)
+				newPeer.FSM.SetState(PeerStateSucceeded)
+				newTask.StorePeer(newPeer)
+				taskManager.Store(newTask)
+
+				err := taskManager.RunGC()
+				assert.NoError(err)
+				assert.Equal(mockPeer.FSM.Current(), PeerStateLeave)
+				assert.Equal(newPeer.FSM.Current(), PeerStateSucceeded)
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -351,7 +432,7 @@ func TestTaskManager_RunGC(t *testing.T) {
 				mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
 			mockTask := NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_DFDAEMON, mockTaskFilters, mockTaskHeader, mockTaskBackToSourceLimit, WithDigest(mockTaskDigest))
 			mockPeer := NewPeer(mockPeerID, mockTask, mockHost)
-			taskManager, err := newTaskManager(mockTaskGCConfig, gc)
+			taskManager, err := newTaskManager(tc.gcConfig, gc, newGCPolicy(tc.gcConfig))
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -360,3 +441,84 @@ func TestTaskManager_RunGC(t *testing.T) {
 		})
 	}
 }
+
+func TestTaskManager_DryRunGC(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy GCPolicy
+		mock   func(mockTask *Task, mockPeer *Peer)
+		expect func(t *testing.T, ids []string)
+	}{
+		{
+			name:   "task without peers",
+			policy: GCPolicy{},
+			mock:   func(mockTask *Task, mockPeer *Peer) {},
+			expect: func(t *testing.T, ids []string) {
+				assert := assert.New(t)
+				assert.Equal(ids, []string{mockTaskID})
+			},
+		},
+		{
+			name:   "idle task exceeds the task ttl",
+			policy: GCPolicy{TaskTTL: 1 * time.Microsecond},
+			mock: func(mockTask *Task, mockPeer *Peer) {
+				mockTask.StorePeer(mockPeer)
+				mockPeer.FSM.SetState(PeerStateSucceeded)
+			},
+			expect: func(t *testing.T, ids []string) {
+				assert := assert.New(t)
+				assert.Equal(ids, []string{mockTaskID})
+			},
+		},
+		{
+			name:   "idle task does not exceed the task ttl",
+			policy: GCPolicy{TaskTTL: 1 * time.Hour},
+			mock: func(mockTask *Task, mockPeer *Peer) {
+				mockTask.StorePeer(mockPeer)
+				mockPeer.FSM.SetState(PeerStateSucceeded)
+			},
+			expect: func(t *testing.T, ids []string) {
+				assert := assert.New(t)
+				assert.Empty(ids)
+			},
+		},
+		{
+			name:   "task has running peers",
+			policy: GCPolicy{TaskTTL: 1 * time.Microsecond, MaxTaskCount: 1},
+			mock: func(mockTask *Task, mockPeer *Peer) {
+				mockTask.StorePeer(mockPeer)
+				mockPeer.FSM.SetState(PeerStateRunning)
+			},
+			expect: func(t *testing.T, ids []string) {
+				assert := assert.New(t)
+				assert.Empty(ids)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+			gc := gc.NewMockGC(ctl)
+			gc.EXPECT().Add(gomock.Any()).Return(nil).Times(1)
+
+			mockHost := NewHost(
+				mockRawHost.ID, mockRawHost.IP, mockRawHost.Hostname,
+				mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
+			mockTask := NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_DFDAEMON, mockTaskFilters, mockTaskHeader, mockTaskBackToSourceLimit, WithDigest(mockTaskDigest))
+			mockPeer := NewPeer(mockPeerID, mockTask, mockHost)
+			taskManager, err := newTaskManager(mockTaskGCConfig, gc, newGCPolicy(mockTaskGCConfig))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			taskManager.Store(mockTask)
+			tc.mock(mockTask, mockPeer)
+			ids := taskManager.DryRunGC(tc.policy)
+			_, loaded := taskManager.Load(mockTask.ID)
+			assert.True(t, loaded)
+			tc.expect(t, ids)
+		})
+	}
+}
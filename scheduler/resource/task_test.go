@@ -1321,6 +1321,80 @@ func TestTask_DeletePiece(t *testing.T) {
 	}
 }
 
+func TestTask_PiecePeers(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   func(task *Task, holder, peer *Peer)
+		expect func(t *testing.T, task *Task, holder, peer *Peer)
+	}{
+		{
+			name: "peer holds missing pieces",
+			mock: func(task *Task, holder, peer *Peer) {
+				holder.FinishedPieces.Set(0)
+				holder.FinishedPieces.Set(1)
+				task.StorePiecePeer(0, holder.ID)
+				task.StorePiecePeer(1, holder.ID)
+				peer.FinishedPieces.Set(0)
+				task.StorePiecePeer(0, peer.ID)
+			},
+			expect: func(t *testing.T, task *Task, holder, peer *Peer) {
+				assert := assert.New(t)
+				assert.ElementsMatch([]string{holder.ID, peer.ID}, task.LoadPiecePeers(0))
+				assert.Equal([]string{holder.ID}, task.LoadPiecePeers(1))
+				assert.True(task.HoldsMissingPieces(holder.ID, peer))
+				assert.False(task.HoldsMissingPieces(peer.ID, holder))
+			},
+		},
+		{
+			name: "peer holds finished pieces only",
+			mock: func(task *Task, holder, peer *Peer) {
+				task.StorePiecePeer(0, holder.ID)
+				peer.FinishedPieces.Set(0)
+			},
+			expect: func(t *testing.T, task *Task, holder, peer *Peer) {
+				assert := assert.New(t)
+				assert.False(task.HoldsMissingPieces(holder.ID, peer))
+			},
+		},
+		{
+			name: "pieces of deleted peer are not held",
+			mock: func(task *Task, holder, peer *Peer) {
+				task.StorePeer(holder)
+				task.StorePiecePeer(0, holder.ID)
+				task.DeletePeer(holder.ID)
+			},
+			expect: func(t *testing.T, task *Task, holder, peer *Peer) {
+				assert := assert.New(t)
+				assert.Empty(task.LoadPiecePeers(0))
+				assert.False(task.HoldsMissingPieces(holder.ID, peer))
+			},
+		},
+		{
+			name: "piece does not exist",
+			mock: func(task *Task, holder, peer *Peer) {},
+			expect: func(t *testing.T, task *Task, holder, peer *Peer) {
+				assert := assert.New(t)
+				assert.Nil(task.LoadPiecePeers(0))
+				assert.False(task.HoldsMissingPieces(holder.ID, peer))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			mockHost := NewHost(
+				mockRawHost.ID, mockRawHost.IP, mockRawHost.Hostname,
+				mockRawHost.Port, mockRawHost.DownloadPort, mockRawHost.Type)
+			task := NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_DFDAEMON, mockTaskFilters, mockTaskHeader, mockTaskBackToSourceLimit)
+			holder := NewPeer(mockSeedPeerID, task, mockHost)
+			peer := NewPeer(mockPeerID, task, mockHost)
+
+			tc.mock(task, holder, peer)
+			tc.expect(t, task, holder, peer)
+		})
+	}
+}
+
 func TestTask_SizeScope(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -1400,6 +1474,97 @@ func TestTask_SizeScope(t *testing.T) {
 	}
 }
 
+func TestValidatePieceLength(t *testing.T) {
+	tests := []struct {
+		name        string
+		pieceLength int32
+		expect      func(t *testing.T, err error)
+	}{
+		{
+			name:        "piece length is not preferred",
+			pieceLength: 0,
+			expect: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:        "piece length is in range",
+			pieceLength: 16 * 1024 * 1024,
+			expect: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:        "piece length is too small",
+			pieceLength: MinPieceLength - 1,
+			expect: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "piece length 1048575 is out of range [1048576, 67108864]")
+			},
+		},
+		{
+			name:        "piece length is too large",
+			pieceLength: MaxPieceLength + 1,
+			expect: func(t *testing.T, err error) {
+				assert.EqualError(t, err, "piece length 67108865 is out of range [1048576, 67108864]")
+			},
+		},
+		{
+			name:        "piece length is negative",
+			pieceLength: -1,
+			expect: func(t *testing.T, err error) {
+				assert.Error(t, err)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expect(t, ValidatePieceLength(tc.pieceLength))
+		})
+	}
+}
+
+func TestTask_PriorityClass(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []TaskOption
+		raises  []types.PriorityClass
+		expect  types.PriorityClass
+	}{
+		{
+			name:   "task has default priority class",
+			expect: types.PriorityClassNormal,
+		},
+		{
+			name:    "task has priority class by option",
+			options: []TaskOption{WithPriorityClass(types.PriorityClassBestEffort)},
+			expect:  types.PriorityClassBestEffort,
+		},
+		{
+			name:    "task raises priority class",
+			options: []TaskOption{WithPriorityClass(types.PriorityClassBestEffort)},
+			raises:  []types.PriorityClass{types.PriorityClassCritical},
+			expect:  types.PriorityClassCritical,
+		},
+		{
+			name:   "task can not lower priority class",
+			raises: []types.PriorityClass{types.PriorityClassCritical, types.PriorityClassBestEffort, types.PriorityClassNormal},
+			expect: types.PriorityClassCritical,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			task := NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_DFDAEMON, mockTaskFilters, mockTaskHeader, mockTaskBackToSourceLimit, tc.options...)
+			for _, class := range tc.raises {
+				task.RaisePriorityClass(class)
+			}
+
+			assert.Equal(t, tc.expect, task.PriorityClass())
+		})
+	}
+}
+
 func TestTask_CanBackToSource(t *testing.T) {
 	tests := []struct {
 		name              string
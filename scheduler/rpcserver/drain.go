@@ -0,0 +1,140 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpcserver
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/atomic"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"d7y.io/dragonfly/v2/scheduler/resource"
+)
+
+// drainedMethods are the grpc methods registering new peers,
+// they are rejected when the scheduler is draining.
+var drainedMethods = map[string]struct{}{
+	"/scheduler.Scheduler/RegisterPeerTask": {},
+	"/scheduler.v2.Scheduler/AnnouncePeer":  {},
+}
+
+// Drainer stops the scheduler from accepting new peers and
+// waits for the in-flight peers to finish.
+type Drainer struct {
+	// peerManager is the peer manager of resource.
+	peerManager resource.PeerManager
+
+	// draining indicates whether the scheduler is draining.
+	draining *atomic.Bool
+}
+
+// NewDrainer returns a new drainer.
+func NewDrainer(peerManager resource.PeerManager) *Drainer {
+	return &Drainer{
+		peerManager: peerManager,
+		draining:    atomic.NewBool(false),
+	}
+}
+
+// Drain rejects the new peers registering to the scheduler.
+func (d *Drainer) Drain() {
+	d.draining.Store(true)
+}
+
+// IsDraining returns whether the scheduler is draining.
+func (d *Drainer) IsDraining() bool {
+	return d.draining.Load()
+}
+
+// UnaryServerInterceptor returns the unary interceptor rejecting new peers when the scheduler is draining.
+func (d *Drainer) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if d.reject(info.FullMethod) {
+			return nil, status.Error(codes.Unavailable, "scheduler is draining")
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns the stream interceptor rejecting new peers when the scheduler is draining.
+func (d *Drainer) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if d.reject(info.FullMethod) {
+			return status.Error(codes.Unavailable, "scheduler is draining")
+		}
+
+		return handler(srv, stream)
+	}
+}
+
+// reject returns whether the grpc method is rejected.
+func (d *Drainer) reject(method string) bool {
+	if !d.IsDraining() {
+		return false
+	}
+
+	_, ok := drainedMethods[method]
+	return ok
+}
+
+// RunningPeerCount returns the count of peers which have not finished downloading.
+func (d *Drainer) RunningPeerCount() int {
+	var count int
+	d.peerManager.Range(func(_, value any) bool {
+		peer, ok := value.(*resource.Peer)
+		if !ok {
+			return true
+		}
+
+		if peer.FSM.Is(resource.PeerStatePending) ||
+			peer.FSM.Is(resource.PeerStateReceivedEmpty) ||
+			peer.FSM.Is(resource.PeerStateReceivedTiny) ||
+			peer.FSM.Is(resource.PeerStateReceivedSmall) ||
+			peer.FSM.Is(resource.PeerStateReceivedNormal) ||
+			peer.FSM.Is(resource.PeerStateRunning) ||
+			peer.FSM.Is(resource.PeerStateBackToSource) {
+			count++
+		}
+
+		return true
+	})
+
+	return count
+}
+
+// Wait checks the running peers by interval until all of them finish,
+// it returns the error of ctx if ctx is done before.
+func (d *Drainer) Wait(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if d.RunningPeerCount() == 0 {
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
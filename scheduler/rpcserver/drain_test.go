@@ -0,0 +1,165 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpcserver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	commonv2 "d7y.io/api/pkg/apis/common/v2"
+
+	"d7y.io/dragonfly/v2/pkg/types"
+	"d7y.io/dragonfly/v2/scheduler/resource"
+)
+
+func newDrainTestPeer(id, state string) *resource.Peer {
+	host := resource.NewHost("foo", "127.0.0.1", "foo", 8003, 8001, types.HostTypeNormal)
+	task := resource.NewTask("bar", "http://example.com", "", "", commonv2.TaskType_DFDAEMON, nil, nil, 3)
+	peer := resource.NewPeer(id, task, host)
+	peer.FSM.SetState(state)
+	return peer
+}
+
+func TestDrainer_Interceptors(t *testing.T) {
+	tests := []struct {
+		name     string
+		draining bool
+		method   string
+		expect   func(t *testing.T, err error)
+	}{
+		{
+			name:   "accept register peer task when scheduler is not draining",
+			method: "/scheduler.Scheduler/RegisterPeerTask",
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+			},
+		},
+		{
+			name:     "reject register peer task when scheduler is draining",
+			draining: true,
+			method:   "/scheduler.Scheduler/RegisterPeerTask",
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.Equal(codes.Unavailable, status.Code(err))
+			},
+		},
+		{
+			name:     "reject announce peer when scheduler is draining",
+			draining: true,
+			method:   "/scheduler.v2.Scheduler/AnnouncePeer",
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.Equal(codes.Unavailable, status.Code(err))
+			},
+		},
+		{
+			name:     "accept report piece result of in-flight peers when scheduler is draining",
+			draining: true,
+			method:   "/scheduler.Scheduler/ReportPieceResult",
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			d := NewDrainer(resource.NewMockPeerManager(ctl))
+			if tc.draining {
+				d.Drain()
+			}
+			assert.Equal(t, tc.draining, d.IsDraining())
+
+			_, err := d.UnaryServerInterceptor()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: tc.method},
+				func(context.Context, any) (any, error) { return nil, nil })
+			tc.expect(t, err)
+
+			err = d.StreamServerInterceptor()(nil, nil, &grpc.StreamServerInfo{FullMethod: tc.method},
+				func(any, grpc.ServerStream) error { return nil })
+			tc.expect(t, err)
+		})
+	}
+}
+
+func TestDrainer_Wait(t *testing.T) {
+	tests := []struct {
+		name   string
+		peers  []*resource.Peer
+		expect func(t *testing.T, d *Drainer, err error)
+	}{
+		{
+			name: "peers have finished",
+			peers: []*resource.Peer{
+				newDrainTestPeer("foo", resource.PeerStateSucceeded),
+				newDrainTestPeer("bar", resource.PeerStateFailed),
+				newDrainTestPeer("baz", resource.PeerStateLeave),
+			},
+			expect: func(t *testing.T, d *Drainer, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(0, d.RunningPeerCount())
+			},
+		},
+		{
+			name: "peers are running",
+			peers: []*resource.Peer{
+				newDrainTestPeer("foo", resource.PeerStateSucceeded),
+				newDrainTestPeer("bar", resource.PeerStateRunning),
+				newDrainTestPeer("baz", resource.PeerStateBackToSource),
+			},
+			expect: func(t *testing.T, d *Drainer, err error) {
+				assert := assert.New(t)
+				assert.ErrorIs(err, context.DeadlineExceeded)
+				assert.Equal(2, d.RunningPeerCount())
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctl := gomock.NewController(t)
+			defer ctl.Finish()
+
+			peerManager := resource.NewMockPeerManager(ctl)
+			peerManager.EXPECT().Range(gomock.Any()).Do(func(f func(any, any) bool) {
+				for _, peer := range tc.peers {
+					if !f(peer.ID, peer) {
+						return
+					}
+				}
+			}).AnyTimes()
+
+			d := NewDrainer(peerManager)
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			tc.expect(t, d, d.Wait(ctx, 10*time.Millisecond))
+		})
+	}
+}
@@ -0,0 +1,171 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpcserver
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
+	schedulerv2 "d7y.io/api/pkg/apis/scheduler/v2"
+
+	"d7y.io/dragonfly/v2/pkg/cache"
+	"d7y.io/dragonfly/v2/scheduler/config"
+)
+
+const (
+	// rateLimiterTTL is the time to live of the token bucket of an idle client.
+	rateLimiterTTL = 10 * time.Minute
+
+	// rateLimiterCleanupInterval is the interval of reclaiming the token buckets of idle clients.
+	rateLimiterCleanupInterval = time.Minute
+)
+
+// rateLimitedMethods are the grpc methods registering new peers, they are
+// limited by the token bucket of client.
+var rateLimitedMethods = map[string]struct{}{
+	"/scheduler.Scheduler/RegisterPeerTask": {},
+	"/scheduler.v2.Scheduler/AnnouncePeer":  {},
+}
+
+// RateLimiter limits the rate of registering peers per client by token bucket,
+// so that one misbehaving client can not starve the scheduler.
+type RateLimiter struct {
+	// limit is the number of tokens refilled per second for each client.
+	limit rate.Limit
+
+	// burst is the size of token bucket for each client.
+	burst int
+
+	// limiters is the token buckets of clients, the bucket of an idle client is reclaimed.
+	limiters cache.Cache
+}
+
+// NewRateLimiter returns a new rate limiter.
+func NewRateLimiter(cfg config.RateLimitConfig) *RateLimiter {
+	return &RateLimiter{
+		limit:    rate.Limit(cfg.Limit),
+		burst:    cfg.Burst,
+		limiters: cache.New(rateLimiterTTL, rateLimiterCleanupInterval),
+	}
+}
+
+// UnaryServerInterceptor returns the unary interceptor rejecting the requests of client exceeding the rate.
+func (r *RateLimiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if _, ok := rateLimitedMethods[info.FullMethod]; ok {
+			if key := clientKey(ctx, req); !r.Allow(key) {
+				return nil, status.Errorf(codes.ResourceExhausted, "client %s exceeds rate limit", key)
+			}
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns the stream interceptor rejecting the streams of client exceeding the rate,
+// the client is identified by the first request of stream.
+func (r *RateLimiter) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if _, ok := rateLimitedMethods[info.FullMethod]; ok {
+			stream = &rateLimitedServerStream{ServerStream: stream, rateLimiter: r}
+		}
+
+		return handler(srv, stream)
+	}
+}
+
+// Allow returns whether the client is allowed to register a peer, it takes a token from
+// the bucket of client. Requests without client key are always allowed.
+func (r *RateLimiter) Allow(key string) bool {
+	if key == "" {
+		return true
+	}
+
+	limiter := rate.NewLimiter(r.limit, r.burst)
+	if err := r.limiters.Add(key, limiter, cache.DefaultExpiration); err != nil {
+		if rawLimiter, ok := r.limiters.Get(key); ok {
+			limiter = rawLimiter.(*rate.Limiter)
+		}
+	}
+
+	// Keep the bucket of active client alive.
+	r.limiters.SetDefault(key, limiter)
+	return limiter.Allow()
+}
+
+// rateLimitedServerStream limits the stream by the client of the first request.
+type rateLimitedServerStream struct {
+	grpc.ServerStream
+
+	// rateLimiter is the rate limiter of clients.
+	rateLimiter *RateLimiter
+
+	// admitted indicates whether the first request of stream is received.
+	admitted bool
+}
+
+// RecvMsg receives the request of stream, it returns error if the first request exceeds the rate of client.
+func (s *rateLimitedServerStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	if s.admitted {
+		return nil
+	}
+
+	s.admitted = true
+	if key := clientKey(s.Context(), m); !s.rateLimiter.Allow(key) {
+		return status.Errorf(codes.ResourceExhausted, "client %s exceeds rate limit", key)
+	}
+
+	return nil
+}
+
+// clientKey returns the host id of request, or the ip of client if the request has no host id.
+func clientKey(ctx context.Context, req any) string {
+	switch req := req.(type) {
+	case *schedulerv1.PeerTaskRequest:
+		if id := req.GetPeerHost().GetId(); id != "" {
+			return id
+		}
+	case *schedulerv2.AnnouncePeerRequest:
+		if id := req.GetHostId(); id != "" {
+			return id
+		}
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+
+	return host
+}
@@ -0,0 +1,165 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package rpcserver
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	schedulerv1 "d7y.io/api/pkg/apis/scheduler/v1"
+	schedulerv2 "d7y.io/api/pkg/apis/scheduler/v2"
+
+	"d7y.io/dragonfly/v2/scheduler/config"
+)
+
+var mockRateLimitConfig = config.RateLimitConfig{
+	Enable: true,
+	Limit:  0.001,
+	Burst:  2,
+}
+
+// mockServerStream is the server stream receiving the requests in order.
+type mockServerStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	reqs []*schedulerv2.AnnouncePeerRequest
+}
+
+func (s *mockServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *mockServerStream) RecvMsg(m any) error {
+	req := s.reqs[0]
+	s.reqs = s.reqs[1:]
+	m.(*schedulerv2.AnnouncePeerRequest).HostId = req.HostId
+	return nil
+}
+
+func TestRateLimiter_UnaryServerInterceptor(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		reqs   []any
+		expect func(t *testing.T, errs []error)
+	}{
+		{
+			name:   "limit register peer task by host id",
+			method: "/scheduler.Scheduler/RegisterPeerTask",
+			reqs: []any{
+				&schedulerv1.PeerTaskRequest{PeerHost: &schedulerv1.PeerHost{Id: "foo"}},
+				&schedulerv1.PeerTaskRequest{PeerHost: &schedulerv1.PeerHost{Id: "foo"}},
+				&schedulerv1.PeerTaskRequest{PeerHost: &schedulerv1.PeerHost{Id: "foo"}},
+				&schedulerv1.PeerTaskRequest{PeerHost: &schedulerv1.PeerHost{Id: "bar"}},
+			},
+			expect: func(t *testing.T, errs []error) {
+				assert := assert.New(t)
+				assert.NoError(errs[0])
+				assert.NoError(errs[1])
+				assert.Equal(codes.ResourceExhausted, status.Code(errs[2]))
+				assert.NoError(errs[3])
+			},
+		},
+		{
+			name:   "limit register peer task by client ip",
+			method: "/scheduler.Scheduler/RegisterPeerTask",
+			reqs: []any{
+				&schedulerv1.PeerTaskRequest{},
+				&schedulerv1.PeerTaskRequest{},
+				&schedulerv1.PeerTaskRequest{},
+			},
+			expect: func(t *testing.T, errs []error) {
+				assert := assert.New(t)
+				assert.NoError(errs[0])
+				assert.NoError(errs[1])
+				assert.EqualError(errs[2], "rpc error: code = ResourceExhausted desc = client 127.0.0.1 exceeds rate limit")
+			},
+		},
+		{
+			name:   "accept the methods which are not limited",
+			method: "/scheduler.Scheduler/ReportPeerResult",
+			reqs: []any{
+				&schedulerv1.PeerResult{},
+				&schedulerv1.PeerResult{},
+				&schedulerv1.PeerResult{},
+			},
+			expect: func(t *testing.T, errs []error) {
+				assert := assert.New(t)
+				for _, err := range errs {
+					assert.NoError(err)
+				}
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := NewRateLimiter(mockRateLimitConfig)
+			ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 65001}})
+
+			var errs []error
+			for _, req := range tc.reqs {
+				_, err := r.UnaryServerInterceptor()(ctx, req, &grpc.UnaryServerInfo{FullMethod: tc.method},
+					func(context.Context, any) (any, error) { return nil, nil })
+				errs = append(errs, err)
+			}
+
+			tc.expect(t, errs)
+		})
+	}
+}
+
+func TestRateLimiter_StreamServerInterceptor(t *testing.T) {
+	assert := assert.New(t)
+	r := NewRateLimiter(mockRateLimitConfig)
+
+	announcePeer := func(reqs ...*schedulerv2.AnnouncePeerRequest) []error {
+		var errs []error
+		err := r.StreamServerInterceptor()(nil, &mockServerStream{ctx: context.Background(), reqs: reqs},
+			&grpc.StreamServerInfo{FullMethod: "/scheduler.v2.Scheduler/AnnouncePeer"},
+			func(_ any, stream grpc.ServerStream) error {
+				for range reqs {
+					errs = append(errs, stream.RecvMsg(&schedulerv2.AnnouncePeerRequest{}))
+				}
+
+				return nil
+			})
+		assert.NoError(err)
+		return errs
+	}
+
+	// Only the first request of stream takes token.
+	errs := announcePeer(&schedulerv2.AnnouncePeerRequest{HostId: "foo"}, &schedulerv2.AnnouncePeerRequest{HostId: "foo"},
+		&schedulerv2.AnnouncePeerRequest{HostId: "foo"})
+	assert.Equal([]error{nil, nil, nil}, errs)
+
+	errs = announcePeer(&schedulerv2.AnnouncePeerRequest{HostId: "foo"})
+	assert.NoError(errs[0])
+
+	errs = announcePeer(&schedulerv2.AnnouncePeerRequest{HostId: "foo"})
+	assert.Equal(codes.ResourceExhausted, status.Code(errs[0]))
+
+	errs = announcePeer(&schedulerv2.AnnouncePeerRequest{HostId: "bar"})
+	assert.NoError(errs[0])
+}
@@ -19,15 +19,18 @@ package scheduler
 import (
 	"context"
 	"crypto/tls"
+	"database/sql"
 	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/johanbrandhorst/certify"
+	_ "github.com/mattn/go-sqlite3"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
@@ -39,18 +42,23 @@ import (
 	"d7y.io/dragonfly/v2/pkg/dfpath"
 	"d7y.io/dragonfly/v2/pkg/gc"
 	"d7y.io/dragonfly/v2/pkg/issuer"
+	"d7y.io/dragonfly/v2/pkg/net/fqdn"
 	"d7y.io/dragonfly/v2/pkg/net/ip"
 	pkgredis "d7y.io/dragonfly/v2/pkg/redis"
 	"d7y.io/dragonfly/v2/pkg/rpc"
+	healthclient "d7y.io/dragonfly/v2/pkg/rpc/health/client"
 	managerclient "d7y.io/dragonfly/v2/pkg/rpc/manager/client"
 	securityclient "d7y.io/dragonfly/v2/pkg/rpc/security/client"
 	trainerclient "d7y.io/dragonfly/v2/pkg/rpc/trainer/client"
 	"d7y.io/dragonfly/v2/pkg/types"
 	"d7y.io/dragonfly/v2/scheduler/announcer"
 	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/faultinject"
+	"d7y.io/dragonfly/v2/scheduler/inventory"
 	"d7y.io/dragonfly/v2/scheduler/job"
 	"d7y.io/dragonfly/v2/scheduler/metrics"
 	"d7y.io/dragonfly/v2/scheduler/networktopology"
+	"d7y.io/dragonfly/v2/scheduler/peerexchange"
 	"d7y.io/dragonfly/v2/scheduler/resource"
 	"d7y.io/dragonfly/v2/scheduler/rpcserver"
 	"d7y.io/dragonfly/v2/scheduler/scheduling"
@@ -61,6 +69,10 @@ const (
 	// gracefulStopTimeout specifies a time limit for
 	// grpc server to complete a graceful shutdown.
 	gracefulStopTimeout = 10 * time.Minute
+
+	// drainCheckInterval is the interval of checking the in-flight peers
+	// when the scheduler is draining.
+	drainCheckInterval = time.Second
 )
 
 type Server struct {
@@ -70,6 +82,12 @@ type Server struct {
 	// GRPC server.
 	grpcServer *grpc.Server
 
+	// Drainer rejects new peers when the scheduler is draining.
+	drainer *rpcserver.Drainer
+
+	// drainOnce ensures the scheduler is drained once.
+	drainOnce sync.Once
+
 	// Metrics server.
 	metricsServer *http.Server
 
@@ -82,6 +100,9 @@ type Server struct {
 	// Trainer client.
 	trainerClient trainerclient.V1
 
+	// Upload sink of trainer http upload endpoint, it replaces trainer client.
+	uploadSink *announcer.HTTPUploadSink
+
 	// Resource interface.
 	resource resource.Resource
 
@@ -94,18 +115,76 @@ type Server struct {
 	// Storage interface.
 	storage storage.Storage
 
+	// Database of storage, it is nil unless storage driver is sqlite.
+	storageDB *sql.DB
+
 	// Announcer interface.
 	announcer announcer.Announcer
 
 	// Network topology interface.
 	networkTopology networktopology.NetworkTopology
 
+	// Peer exchange interface.
+	peerExchange peerexchange.PeerExchange
+
+	// Seed peer saturation service.
+	seedPeerSaturation *metrics.SeedPeerSaturationService
+
+	// Inventory interface.
+	inventory inventory.Inventory
+
 	// GC service.
 	gc gc.GC
 }
 
-func New(ctx context.Context, cfg *config.Config, d dfpath.Dfpath) (*Server, error) {
+// Option is a functional option for configuring the scheduler server.
+type Option func(o *options)
+
+// options provides the options of scheduler server.
+type options struct {
+	// unaryClientInterceptors are chained after the default unary interceptors of manager and trainer clients.
+	unaryClientInterceptors []grpc.UnaryClientInterceptor
+
+	// streamClientInterceptors are chained after the default stream interceptors of manager and trainer clients.
+	streamClientInterceptors []grpc.StreamClientInterceptor
+}
+
+// WithUnaryClientInterceptors sets the unary interceptors of manager and trainer clients,
+// e.g. injects tracing and auth metadata into UpdateScheduler.
+func WithUnaryClientInterceptors(interceptors ...grpc.UnaryClientInterceptor) Option {
+	return func(o *options) {
+		o.unaryClientInterceptors = append(o.unaryClientInterceptors, interceptors...)
+	}
+}
+
+// WithStreamClientInterceptors sets the stream interceptors of manager and trainer clients,
+// e.g. injects tracing and auth metadata into KeepAlive and Train.
+func WithStreamClientInterceptors(interceptors ...grpc.StreamClientInterceptor) Option {
+	return func(o *options) {
+		o.streamClientInterceptors = append(o.streamClientInterceptors, interceptors...)
+	}
+}
+
+// clientDialOptions returns the dial options of manager and trainer clients.
+func (o *options) clientDialOptions() []grpc.DialOption {
+	var dialOptions []grpc.DialOption
+	if len(o.unaryClientInterceptors) > 0 {
+		dialOptions = append(dialOptions, grpc.WithChainUnaryInterceptor(o.unaryClientInterceptors...))
+	}
+
+	if len(o.streamClientInterceptors) > 0 {
+		dialOptions = append(dialOptions, grpc.WithChainStreamInterceptor(o.streamClientInterceptors...))
+	}
+
+	return dialOptions
+}
+
+func New(ctx context.Context, cfg *config.Config, d dfpath.Dfpath, opts ...Option) (*Server, error) {
 	s := &Server{config: cfg}
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
 
 	// Initialize redis client.
 	rdb, err := pkgredis.NewRedis(&redis.UniversalOptions{
@@ -120,19 +199,14 @@ func New(ctx context.Context, cfg *config.Config, d dfpath.Dfpath) (*Server, err
 	}
 
 	// Initialize Storage.
-	storage, err := storage.New(
-		d.DataDir(),
-		cfg.Storage.MaxSize,
-		cfg.Storage.MaxBackups,
-		cfg.Storage.BufferSize,
-	)
+	storage, storageDB, err := newStorage(cfg, d.DataDir())
 	if err != nil {
 		return nil, err
 	}
-	s.storage = storage
+	s.storage, s.storageDB = storage, storageDB
 
 	// Initialize dial options of manager grpc client.
-	managerDialOptions := []grpc.DialOption{}
+	managerDialOptions := o.clientDialOptions()
 	if cfg.Security.AutoIssueCert {
 		clientTransportCredentials, err := rpc.NewClientCredentials(cfg.Security.TLSPolicy, nil, []byte(cfg.Security.CACert))
 		if err != nil {
@@ -152,8 +226,8 @@ func New(ctx context.Context, cfg *config.Config, d dfpath.Dfpath) (*Server, err
 	s.managerClient = managerClient
 
 	// Initialize dial options of trainer grpc client.
+	trainerDialOptions := o.clientDialOptions()
 	if cfg.Trainer.Enable {
-		trainerDialOptions := []grpc.DialOption{}
 		if cfg.Security.AutoIssueCert {
 			clientTransportCredentials, err := rpc.NewClientCredentials(cfg.Security.TLSPolicy, nil, []byte(cfg.Security.CACert))
 			if err != nil {
@@ -165,18 +239,64 @@ func New(ctx context.Context, cfg *config.Config, d dfpath.Dfpath) (*Server, err
 			trainerDialOptions = append(trainerDialOptions, grpc.WithTransportCredentials(insecure.NewCredentials()))
 		}
 
-		// Initialize trainer client.
-		trainerClient, err := trainerclient.GetV1ByAddr(ctx, cfg.Trainer.Addr, trainerDialOptions...)
-		if err != nil {
-			return nil, err
+		// Initialize trainer client, the datasets are uploaded by the grpc client or the http upload sink.
+		switch cfg.Trainer.UploadSink {
+		case config.TrainerUploadSinkHTTP:
+			sink, err := announcer.NewHTTPUploadSink(cfg.Trainer.HTTPUpload.Endpoint,
+				announcer.WithHTTPUploadChunkRetries(cfg.Trainer.HTTPUpload.ChunkRetries))
+			if err != nil {
+				return nil, err
+			}
+			s.uploadSink = sink
+		default:
+			trainerClient, err := trainerclient.GetV1ByAddr(ctx, cfg.Trainer.Addr, trainerDialOptions...)
+			if err != nil {
+				return nil, err
+			}
+			s.trainerClient = trainerClient
 		}
-		s.trainerClient = trainerClient
 	}
 
 	// Initialize dial options of announcer.
-	announcerOptions := []announcer.Option{}
+	announcerOptions := []announcer.Option{
+		announcer.WithHostStatsCollector(announcer.NewHostStatsCollector(d.DataDir())),
+		announcer.WithUpdateSchedulerRetry(cfg.Manager.Registration.MaxAttempts,
+			cfg.Manager.Registration.InitBackoff, cfg.Manager.Registration.MaxBackoff),
+		announcer.WithUpdateSchedulerRetryJitter(cfg.Manager.Registration.Jitter),
+		announcer.WithDegradedStart(cfg.Manager.Registration.Degraded),
+		announcer.WithManagerDialer(func(ctx context.Context, addr string) (managerclient.V2, error) {
+			return managerclient.GetV2ByAddr(ctx, addr, managerDialOptions...)
+		}),
+	}
+
+	if len(cfg.Manager.Addrs) > 0 {
+		announcerOptions = append(announcerOptions, announcer.WithManagerEndpoints(
+			append([]string{cfg.Manager.Addr}, cfg.Manager.Addrs...),
+			func(ctx context.Context, addr string) error {
+				return healthclient.Check(ctx, addr, managerDialOptions...)
+			}))
+	}
 	if s.trainerClient != nil {
-		announcerOptions = append(announcerOptions, announcer.WithTrainerClient(s.trainerClient))
+		announcerOptions = append(announcerOptions,
+			announcer.WithTrainerClient(s.trainerClient),
+			announcer.WithTrainerDialer(func(ctx context.Context, addr string) (trainerclient.V1, error) {
+				return trainerclient.GetV1ByAddr(ctx, addr, trainerDialOptions...)
+			}),
+		)
+	}
+
+	// The trainers discovered from manager are grpc services, so they are not dialed by the http upload sink.
+	if s.uploadSink != nil {
+		announcerOptions = append(announcerOptions, announcer.WithUploadSink(s.uploadSink))
+	}
+
+	if s.trainerClient != nil || s.uploadSink != nil {
+		announcerOptions = append(announcerOptions, announcer.WithCheckpointDir(d.DataDir()))
+
+		if cfg.Trainer.RedactIPs {
+			announcerOptions = append(announcerOptions,
+				announcer.WithUploadTransforms(announcer.NewIPRedactionTransforms([]byte(cfg.Trainer.RedactIPsSalt))...))
+		}
 	}
 
 	// Initialize announcer.
@@ -234,11 +354,16 @@ func New(ctx context.Context, cfg *config.Config, d dfpath.Dfpath) (*Server, err
 	}
 	s.dynconfig = dynconfig
 
+	// Report the versions of cluster configurations running in scheduler to manager.
+	dynconfig.Register(config.NewConfigVersionReporter(cfg.Server.Host, cfg.Server.AdvertiseIP.String(), rdb))
+
 	// Initialize GC.
 	s.gc = gc.New(gc.WithLogger(logger.GCLogger))
 
 	// Initialize resource.
-	resource, err := resource.New(cfg, s.gc, dynconfig, resource.WithTransportCredentials(clientTransportCredentials))
+	resource, err := resource.New(cfg, s.gc, dynconfig,
+		resource.WithTransportCredentials(clientTransportCredentials),
+		resource.WithSnapshotDir(d.DataDir()))
 	if err != nil {
 		return nil, err
 	}
@@ -260,6 +385,22 @@ func New(ctx context.Context, cfg *config.Config, d dfpath.Dfpath) (*Server, err
 		schedulerServerOptions = append(schedulerServerOptions, grpc.Creds(insecure.NewCredentials()))
 	}
 
+	// Reject new peers after the default interceptors when the scheduler is draining.
+	s.drainer = rpcserver.NewDrainer(resource.PeerManager())
+	schedulerServerOptions = append(schedulerServerOptions,
+		grpc.ChainUnaryInterceptor(s.drainer.UnaryServerInterceptor()),
+		grpc.ChainStreamInterceptor(s.drainer.StreamServerInterceptor()),
+	)
+
+	// Limit the rate of registering peers per client after draining.
+	if cfg.Scheduler.RateLimit.Enable {
+		rateLimiter := rpcserver.NewRateLimiter(cfg.Scheduler.RateLimit)
+		schedulerServerOptions = append(schedulerServerOptions,
+			grpc.ChainUnaryInterceptor(rateLimiter.UnaryServerInterceptor()),
+			grpc.ChainStreamInterceptor(rateLimiter.StreamServerInterceptor()),
+		)
+	}
+
 	svr := rpcserver.New(cfg, resource, scheduling, dynconfig, s.storage, schedulerServerOptions...)
 	s.grpcServer = svr
 
@@ -273,7 +414,7 @@ func New(ctx context.Context, cfg *config.Config, d dfpath.Dfpath) (*Server, err
 
 	// Initialize metrics.
 	if cfg.Metrics.Enable {
-		s.metricsServer = metrics.New(&cfg.Metrics, s.grpcServer)
+		s.metricsServer = metrics.New(&cfg.Metrics, s.grpcServer, http.HandlerFunc(s.serveDrain), faultinject.Handler())
 	}
 
 	// Initialize network topology service.
@@ -284,6 +425,37 @@ func New(ctx context.Context, cfg *config.Config, d dfpath.Dfpath) (*Server, err
 		}
 	}
 
+	// Initialize peer exchange service.
+	if cfg.PeerExchange.Enable {
+		s.peerExchange, err = peerexchange.New(cfg, rdb, resource)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Initialize seed peer saturation service.
+	if cfg.Metrics.SeedPeerSaturation.Enable {
+		hostname := cfg.Server.Host
+		if hostname == "" {
+			hostname = fqdn.FQDNHostname
+		}
+
+		s.seedPeerSaturation = metrics.NewSeedPeerSaturationService(cfg, hostname, rdb, newSeedPeerSampler(resource))
+	}
+
+	// Initialize inventory.
+	if cfg.Inventory.Enable {
+		hostname := cfg.Server.Host
+		if hostname == "" {
+			hostname = fqdn.FQDNHostname
+		}
+
+		s.inventory, err = inventory.New(cfg, hostname, rdb, resource)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return s, nil
 }
 
@@ -327,6 +499,32 @@ func (s *Server) Serve() error {
 		logger.Info("announcer start successfully")
 	}()
 
+	// Serve peer exchange.
+	if s.peerExchange != nil {
+		go s.peerExchange.Serve()
+		logger.Info("peer exchange start successfully")
+	}
+
+	// Serve seed peer saturation.
+	if s.seedPeerSaturation != nil {
+		go s.seedPeerSaturation.Serve()
+		logger.Info("seed peer saturation start successfully")
+	}
+
+	// Serve inventory.
+	if s.inventory != nil {
+		go s.inventory.Serve()
+		logger.Info("inventory start successfully")
+	}
+
+	// Exit on the unrecoverable error of announcer, e.g. the manager is lost permanently,
+	// so that the scheduler is restarted cleanly by orchestrator.
+	go func() {
+		if err := <-s.announcer.Err(); err != nil {
+			logger.Fatalf("announcer failed unrecoverably: %s", err.Error())
+		}
+	}()
+
 	// Generate GRPC limit listener.
 	ip, ok := ip.FormatIP(s.config.Server.ListenIP.String())
 	if !ok {
@@ -357,6 +555,24 @@ func (s *Server) Stop() {
 		logger.Info("stop dynconfig closed")
 	}
 
+	// Stop peer exchange.
+	if s.peerExchange != nil {
+		s.peerExchange.Stop()
+		logger.Info("peer exchange closed")
+	}
+
+	// Stop seed peer saturation.
+	if s.seedPeerSaturation != nil {
+		s.seedPeerSaturation.Stop()
+		logger.Info("seed peer saturation closed")
+	}
+
+	// Stop inventory.
+	if s.inventory != nil {
+		s.inventory.Stop()
+		logger.Info("inventory closed")
+	}
+
 	// Stop resource.
 	if err := s.resource.Stop(); err != nil {
 		logger.Errorf("stop resource failed %s", err.Error())
@@ -364,18 +580,21 @@ func (s *Server) Stop() {
 		logger.Info("stop resource closed")
 	}
 
-	// Clean download storage.
-	if err := s.storage.ClearDownload(); err != nil {
-		logger.Errorf("clean download storage failed %s", err.Error())
-	} else {
-		logger.Info("clean download storage completed")
-	}
+	// The records of sqlite storage are kept across restarts.
+	if s.storageDB == nil {
+		// Clean download storage.
+		if err := s.storage.ClearDownload(); err != nil {
+			logger.Errorf("clean download storage failed %s", err.Error())
+		} else {
+			logger.Info("clean download storage completed")
+		}
 
-	// Clean network topology storage.
-	if err := s.storage.ClearNetworkTopology(); err != nil {
-		logger.Errorf("clean network topology storage failed %s", err.Error())
-	} else {
-		logger.Info("clean network topology storage completed")
+		// Clean network topology storage.
+		if err := s.storage.ClearNetworkTopology(); err != nil {
+			logger.Errorf("clean network topology storage failed %s", err.Error())
+		} else {
+			logger.Info("clean network topology storage completed")
+		}
 	}
 
 	// Stop GC.
@@ -416,6 +635,15 @@ func (s *Server) Stop() {
 		}
 	}
 
+	// Stop upload sink.
+	if s.uploadSink != nil {
+		if err := s.uploadSink.Close(); err != nil {
+			logger.Errorf("upload sink failed to stop: %s", err.Error())
+		} else {
+			logger.Info("upload sink closed")
+		}
+	}
+
 	// Stop security client.
 	if s.securityClient != nil {
 		if err := s.securityClient.Close(); err != nil {
@@ -440,4 +668,179 @@ func (s *Server) Stop() {
 	case <-stopped:
 		t.Stop()
 	}
+
+	// Close storage database after the records are no longer written.
+	if s.storageDB != nil {
+		if err := s.storageDB.Close(); err != nil {
+			logger.Errorf("close storage database failed %s", err.Error())
+		} else {
+			logger.Info("storage database closed")
+		}
+	}
+}
+
+// newStorage returns the storage of driver in the data directory, the database of sqlite
+// driver is returned to be closed when scheduler stops.
+func newStorage(cfg *config.Config, dataDir string) (storage.Storage, *sql.DB, error) {
+	sampler, err := newSampler(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if cfg.Storage.Driver == config.StorageDriverSQLite {
+		// The WAL journal allows reading records while inserting.
+		db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?_journal_mode=WAL&_busy_timeout=5000", filepath.Join(dataDir, storage.SQLiteFilename)))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var sqlOptions []storage.SQLOption
+		if cfg.Storage.MaxRecords > 0 {
+			sqlOptions = append(sqlOptions, storage.WithSQLMaxRecords(cfg.Storage.MaxRecords))
+		}
+
+		if sampler != nil {
+			sqlOptions = append(sqlOptions, storage.WithSQLSampler(sampler))
+		}
+
+		sqlStorage, err := storage.NewSQL(db, sqlOptions...)
+		if err != nil {
+			db.Close()
+			return nil, nil, err
+		}
+
+		return sqlStorage, db, nil
+	}
+
+	// Initialize encryption and retention options of storage.
+	storageOptions := []storage.Option{}
+	if cfg.Storage.Encryption.Enable {
+		var keys []storage.EncryptionKey
+		for _, key := range cfg.Storage.Encryption.Keys {
+			secret, err := storage.ReadEncryptionKey(key.File, key.Env)
+			if err != nil {
+				return nil, nil, fmt.Errorf("read storage encryption key %s: %w", key.ID, err)
+			}
+
+			keys = append(keys, storage.EncryptionKey{ID: key.ID, Secret: secret})
+		}
+
+		storageOptions = append(storageOptions, storage.WithEncryption(keys, cfg.Storage.Encryption.KeyID))
+	}
+
+	if cfg.Storage.MaxRecords > 0 {
+		storageOptions = append(storageOptions, storage.WithMaxRecords(cfg.Storage.MaxRecords))
+	}
+
+	if sampler != nil {
+		storageOptions = append(storageOptions, storage.WithSampler(sampler))
+	}
+
+	fileStorage, err := storage.New(
+		dataDir,
+		cfg.Storage.MaxSize,
+		cfg.Storage.MaxBackups,
+		cfg.Storage.BufferSize,
+		storageOptions...,
+	)
+	return fileStorage, nil, err
+}
+
+// newSampler returns the sampler of download records for training, nil is returned
+// if all records are written.
+func newSampler(cfg *config.Config) (storage.Sampler, error) {
+	if !cfg.Trainer.Enable || cfg.Trainer.SampleRate >= 1 {
+		return nil, nil
+	}
+
+	if cfg.Trainer.SamplePolicy == config.TrainerSamplePolicyStratified {
+		return storage.NewStratifiedSampler(cfg.Trainer.SampleRate)
+	}
+
+	return storage.NewRateSampler(cfg.Trainer.SampleRate)
+}
+
+// newSeedPeerSampler returns the sampler of seed peer hosts and normal peers in resource.
+func newSeedPeerSampler(res resource.Resource) metrics.SeedPeerSampler {
+	return func() ([]metrics.SeedPeerHostSample, []metrics.PeerSample) {
+		var (
+			activeTaskCounts = make(map[string]int)
+			peers            []metrics.PeerSample
+		)
+
+		res.PeerManager().Range(func(_, value any) bool {
+			peer, ok := value.(*resource.Peer)
+			if !ok {
+				return true
+			}
+
+			downloading := peer.FSM.Is(resource.PeerStateRunning) || peer.FSM.Is(resource.PeerStateBackToSource)
+			if peer.Host.Type != types.HostTypeNormal {
+				if downloading {
+					activeTaskCounts[peer.Host.ID]++
+				}
+
+				return true
+			}
+
+			if downloading || peer.FSM.Is(resource.PeerStateSucceeded) {
+				peers = append(peers, metrics.PeerSample{
+					BackToSource: peer.FSM.Is(resource.PeerStateBackToSource) || peer.Task.BackToSourcePeers.Contains(peer.ID),
+				})
+			}
+
+			return true
+		})
+
+		var hosts []metrics.SeedPeerHostSample
+		res.HostManager().Range(func(_, value any) bool {
+			host, ok := value.(*resource.Host)
+			if !ok || host.Type == types.HostTypeNormal {
+				return true
+			}
+
+			hosts = append(hosts, metrics.SeedPeerHostSample{
+				ActiveTaskCount:       activeTaskCounts[host.ID],
+				ConcurrentUploadCount: host.ConcurrentUploadCount.Load(),
+				ConcurrentUploadLimit: host.ConcurrentUploadLimit.Load(),
+			})
+
+			return true
+		})
+
+		return hosts, peers
+	}
+}
+
+// Drain stops the scheduler from accepting new peers and waits for the in-flight peers
+// to finish until the drain timeout, then stops the scheduler, which deregisters
+// from manager by stopping the keepalive of announcer.
+func (s *Server) Drain() {
+	s.drainOnce.Do(func() {
+		s.drainer.Drain()
+		logger.Infof("scheduler is draining, %d peers are running", s.drainer.RunningPeerCount())
+
+		ctx, cancel := context.WithTimeout(context.Background(), s.config.Server.DrainTimeout)
+		defer cancel()
+
+		if err := s.drainer.Wait(ctx, drainCheckInterval); err != nil {
+			logger.Warnf("drain scheduler timeout, %d peers are running", s.drainer.RunningPeerCount())
+		} else {
+			logger.Info("scheduler is drained")
+		}
+
+		s.Stop()
+	})
+}
+
+// serveDrain serves the admin endpoint of draining the scheduler,
+// the scheduler exits after it is drained.
+func (s *Server) serveDrain(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	go s.Drain()
+	w.WriteHeader(http.StatusAccepted)
 }
@@ -17,6 +17,10 @@
 package evaluator
 
 import (
+	"fmt"
+	"sync"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
 	"d7y.io/dragonfly/v2/scheduler/resource"
 )
 
@@ -39,16 +43,70 @@ type Evaluator interface {
 	IsBadNode(peer *resource.Peer) bool
 }
 
+// Factory returns a new evaluator of the registered algorithm.
+type Factory func() Evaluator
+
+var (
+	// factoriesMu guards factories.
+	factoriesMu sync.RWMutex
+
+	// factories is the evaluator factories keyed by the registered algorithm.
+	factories = map[string]Factory{}
+)
+
+// Register registers the evaluator factory of algorithm, so that the evaluator is selected
+// by config scheduler.algorithm without forking the scheduler, e.g. a rack-aware evaluator
+// registered in the init function of its package. It panics if algorithm is built in or
+// already registered.
+func Register(algorithm string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	switch algorithm {
+	case DefaultAlgorithm, MLAlgorithm, PluginAlgorithm:
+		panic(fmt.Sprintf("evaluator algorithm %s is built in", algorithm))
+	}
+
+	if _, ok := factories[algorithm]; ok {
+		panic(fmt.Sprintf("duplicate evaluator algorithm: %s", algorithm))
+	}
+
+	factories[algorithm] = factory
+}
+
+// Unregister unregisters the evaluator factory of algorithm.
+func Unregister(algorithm string) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+
+	delete(factories, algorithm)
+}
+
+// New returns the evaluator of algorithm. The plugin algorithm loads the evaluator plugin from
+// pluginDir, and the other algorithms are built in or registered by Register. It falls back to
+// the default evaluator if the algorithm is unknown or the plugin can not be loaded.
 func New(algorithm string, pluginDir string) Evaluator {
 	switch algorithm {
 	case PluginAlgorithm:
-		if plugin, err := LoadPlugin(pluginDir); err == nil {
+		plugin, err := LoadPlugin(pluginDir)
+		if err == nil {
 			return plugin
 		}
+
+		logger.Warnf("load evaluator plugin from %s failed, use default algorithm: %s", pluginDir, err.Error())
+		return NewEvaluatorBase()
 	// TODO Implement MLAlgorithm.
 	case MLAlgorithm, DefaultAlgorithm:
 		return NewEvaluatorBase()
 	}
 
+	factoriesMu.RLock()
+	factory, ok := factories[algorithm]
+	factoriesMu.RUnlock()
+	if ok {
+		return factory()
+	}
+
+	logger.Warnf("evaluator algorithm %s is not registered, use default algorithm", algorithm)
 	return NewEvaluatorBase()
 }
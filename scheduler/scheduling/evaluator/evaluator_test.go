@@ -70,3 +70,62 @@ func TestEvaluator_New(t *testing.T) {
 		})
 	}
 }
+
+// rackAwareEvaluator is the registered evaluator of tests.
+type rackAwareEvaluator struct {
+	*evaluatorBase
+}
+
+func TestEvaluator_Register(t *testing.T) {
+	Register("rack-aware", func() Evaluator {
+		return &rackAwareEvaluator{evaluatorBase: NewEvaluatorBase().(*evaluatorBase)}
+	})
+	defer Unregister("rack-aware")
+
+	tests := []struct {
+		name   string
+		run    func()
+		expect func(t *testing.T, run func())
+	}{
+		{
+			name: "new evaluator with registered algorithm",
+			expect: func(t *testing.T, run func()) {
+				assert := assert.New(t)
+				assert.Equal(reflect.TypeOf(New("rack-aware", ".")).Elem().Name(), "rackAwareEvaluator")
+			},
+		},
+		{
+			name: "new evaluator with unregistered algorithm",
+			expect: func(t *testing.T, run func()) {
+				assert := assert.New(t)
+				assert.Equal(reflect.TypeOf(New("cost-aware", ".")).Elem().Name(), "evaluatorBase")
+			},
+		},
+		{
+			name: "register duplicate algorithm",
+			run: func() {
+				Register("rack-aware", func() Evaluator { return NewEvaluatorBase() })
+			},
+			expect: func(t *testing.T, run func()) {
+				assert := assert.New(t)
+				assert.PanicsWithValue("duplicate evaluator algorithm: rack-aware", run)
+			},
+		},
+		{
+			name: "register built-in algorithm",
+			run: func() {
+				Register(DefaultAlgorithm, func() Evaluator { return NewEvaluatorBase() })
+			},
+			expect: func(t *testing.T, run func()) {
+				assert := assert.New(t)
+				assert.PanicsWithValue("evaluator algorithm default is built in", run)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expect(t, tc.run)
+		})
+	}
+}
@@ -21,6 +21,8 @@ package scheduling
 import (
 	"context"
 	"fmt"
+	"math"
+	"net"
 	"sort"
 	"time"
 
@@ -36,7 +38,9 @@ import (
 
 	"d7y.io/dragonfly/v2/pkg/container/set"
 	"d7y.io/dragonfly/v2/pkg/types"
+	"d7y.io/dragonfly/v2/pkg/unit"
 	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/faultinject"
 	"d7y.io/dragonfly/v2/scheduler/resource"
 	"d7y.io/dragonfly/v2/scheduler/scheduling/evaluator"
 )
@@ -79,7 +83,10 @@ func New(cfg *config.SchedulerConfig, dynconfig config.DynconfigInterface, plugi
 // ScheduleCandidateParents schedules candidate parents to the normal peer.
 // Used only in v2 version of the grpc.
 func (s *scheduling) ScheduleCandidateParents(ctx context.Context, peer *resource.Peer, blocklist set.SafeSet[string]) error {
+	faultinject.Delay()
+
 	var n int
+	retryLimit, retryBackToSourceLimit := s.retryLimits()
 	for {
 		select {
 		case <-ctx.Done():
@@ -120,7 +127,7 @@ func (s *scheduling) ScheduleCandidateParents(ctx context.Context, peer *resourc
 
 			// Check condition 2:
 			// The number of retry scheduling is greater than RetryBackToSourceLimit
-			if n >= s.config.RetryBackToSourceLimit {
+			if n >= retryBackToSourceLimit {
 				stream, loaded := peer.LoadAnnouncePeerStream()
 				if !loaded {
 					peer.Log.Error("load stream failed")
@@ -128,7 +135,7 @@ func (s *scheduling) ScheduleCandidateParents(ctx context.Context, peer *resourc
 				}
 
 				// Send NeedBackToSourceResponse to peer.
-				peer.Log.Infof("send NeedBackToSourceResponse, because of scheduling exceeded RetryBackToSourceLimit %d", s.config.RetryBackToSourceLimit)
+				peer.Log.Infof("send NeedBackToSourceResponse, because of scheduling exceeded RetryBackToSourceLimit %d", retryBackToSourceLimit)
 				if err := stream.Send(&schedulerv2.AnnouncePeerResponse{
 					Response: &schedulerv2.AnnouncePeerResponse_NeedBackToSourceResponse{
 						NeedBackToSourceResponse: &schedulerv2.NeedBackToSourceResponse{
@@ -147,8 +154,8 @@ func (s *scheduling) ScheduleCandidateParents(ctx context.Context, peer *resourc
 		// Scheduling will return schedule failed.
 		//
 		// Condition 1: Scheduling exceeds the RetryLimit.
-		if n >= s.config.RetryLimit {
-			peer.Log.Errorf("scheduling failed, because of scheduling exceeded RetryLimit %d", s.config.RetryLimit)
+		if n >= retryLimit {
+			peer.Log.Errorf("scheduling failed, because of scheduling exceeded RetryLimit %d", retryLimit)
 			return status.Error(codes.FailedPrecondition, "scheduling exceeded RetryLimit")
 		}
 
@@ -209,7 +216,10 @@ func (s *scheduling) ScheduleCandidateParents(ctx context.Context, peer *resourc
 // ScheduleParentAndCandidateParents schedules a parent and candidate parents to a peer.
 // Used only in v1 version of the grpc.
 func (s *scheduling) ScheduleParentAndCandidateParents(ctx context.Context, peer *resource.Peer, blocklist set.SafeSet[string]) {
+	faultinject.Delay()
+
 	var n int
+	retryLimit, retryBackToSourceLimit := s.retryLimits()
 	for {
 		select {
 		case <-ctx.Done():
@@ -258,7 +268,7 @@ func (s *scheduling) ScheduleParentAndCandidateParents(ctx context.Context, peer
 
 			// Check condition 2:
 			// The number of retry scheduling is greater than RetryBackToSourceLimit
-			if n >= s.config.RetryBackToSourceLimit {
+			if n >= retryBackToSourceLimit {
 				stream, loaded := peer.LoadReportPieceResultStream()
 				if !loaded {
 					peer.Log.Error("load stream failed")
@@ -270,7 +280,7 @@ func (s *scheduling) ScheduleParentAndCandidateParents(ctx context.Context, peer
 					peer.Log.Error(err)
 					return
 				}
-				peer.Log.Infof("send Code_SchedNeedBackSource to peer, because of scheduling exceeded RetryLimit %d", s.config.RetryLimit)
+				peer.Log.Infof("send Code_SchedNeedBackSource to peer, because of scheduling exceeded RetryLimit %d", retryLimit)
 
 				if err := peer.FSM.Event(ctx, resource.PeerEventDownloadBackToSource); err != nil {
 					peer.Log.Errorf("peer fsm event failed: %s", err.Error())
@@ -293,7 +303,7 @@ func (s *scheduling) ScheduleParentAndCandidateParents(ctx context.Context, peer
 		// Scheduling will send Code_SchedTaskStatusError to peer.
 		//
 		// Condition 1: Scheduling exceeds the RetryLimit.
-		if n >= s.config.RetryLimit {
+		if n >= retryLimit {
 			stream, loaded := peer.LoadReportPieceResultStream()
 			if !loaded {
 				peer.Log.Error("load stream failed")
@@ -306,7 +316,7 @@ func (s *scheduling) ScheduleParentAndCandidateParents(ctx context.Context, peer
 				return
 			}
 
-			peer.Log.Errorf("send SchedulePeerFailed to peer, because of scheduling exceeded RetryLimit %d", s.config.RetryLimit)
+			peer.Log.Errorf("send SchedulePeerFailed to peer, because of scheduling exceeded RetryLimit %d", retryLimit)
 			return
 		}
 
@@ -391,11 +401,16 @@ func (s *scheduling) FindCandidateParents(ctx context.Context, peer *resource.Pe
 		return []*resource.Peer{}, false
 	}
 
-	// Sort candidate parents by evaluation score.
+	// Sort candidate parents by address family and evaluation score, candidate parents
+	// in the address family of peer are preferred on dual-stack clusters.
 	taskTotalPieceCount := peer.Task.TotalPieceCount.Load()
 	sort.Slice(
 		candidateParents,
 		func(i, j int) bool {
+			if iSame, jSame := sameIPFamily(candidateParents[i].Host, peer.Host), sameIPFamily(candidateParents[j].Host, peer.Host); iSame != jSame {
+				return iSame
+			}
+
 			return s.evaluator.Evaluate(candidateParents[i], peer, taskTotalPieceCount) > s.evaluator.Evaluate(candidateParents[j], peer, taskTotalPieceCount)
 		},
 	)
@@ -445,11 +460,15 @@ func (s *scheduling) FindSuccessParent(ctx context.Context, peer *resource.Peer,
 		}
 	}
 
-	// Sort candidate parents by evaluation score.
+	// Sort candidate parents by address family and evaluation score.
 	taskTotalPieceCount := peer.Task.TotalPieceCount.Load()
 	sort.Slice(
 		successParents,
 		func(i, j int) bool {
+			if iSame, jSame := sameIPFamily(successParents[i].Host, peer.Host), sameIPFamily(successParents[j].Host, peer.Host); iSame != jSame {
+				return iSame
+			}
+
 			return s.evaluator.Evaluate(successParents[i], peer, taskTotalPieceCount) > s.evaluator.Evaluate(successParents[j], peer, taskTotalPieceCount)
 		},
 	)
@@ -470,6 +489,7 @@ func (s *scheduling) filterCandidateParents(peer *resource.Peer, blocklist set.S
 	var (
 		candidateParents   []*resource.Peer
 		candidateParentIDs []string
+		priorityClass      = peer.Task.PriorityClass()
 	)
 	for _, candidateParent := range peer.Task.LoadRandomPeers(uint(filterParentLimit)) {
 		// Candidate parent is in blocklist.
@@ -510,8 +530,10 @@ func (s *scheduling) filterCandidateParents(peer *resource.Peer, blocklist set.S
 		// Condition 2: Parent has been back-to-source.
 		// Condition 3: Parent has been succeeded.
 		// Condition 4: Parent is seed peer.
+		// Condition 5: Parent is running and holds the finished pieces which the peer has not downloaded,
+		// e.g. the parent of the running peer died mid-download.
 		if candidateParent.Host.Type == types.HostTypeNormal && inDegree == 0 && !candidateParent.FSM.Is(resource.PeerStateBackToSource) &&
-			!candidateParent.FSM.Is(resource.PeerStateSucceeded) {
+			!candidateParent.FSM.Is(resource.PeerStateSucceeded) && !holdsMissingPieces(candidateParent, peer) {
 			peer.Log.Debugf("parent %s is not selected, because its download state is %d %d %s",
 				candidateParent.ID, inDegree, int(candidateParent.Host.Type), candidateParent.FSM.Current())
 			continue
@@ -524,6 +546,23 @@ func (s *scheduling) filterCandidateParents(peer *resource.Peer, blocklist set.S
 			continue
 		}
 
+		// Candidate parent's free upload is reserved for the higher priority class,
+		// so that the critical peers preempt the best-effort peers when parents are scarce.
+		if reservedUploadCount := s.reservedUploadCount(priorityClass, candidateParent.Host); candidateParent.Host.FreeUploadCount() <= reservedUploadCount {
+			peer.Log.Debugf("parent %s is not selected because its free upload is reserved for the higher priority class than %s, reserved upload count is %d, upload count is %d",
+				candidateParent.ID, priorityClass.Name(), reservedUploadCount, candidateParent.Host.ConcurrentUploadCount.Load())
+			continue
+		}
+
+		// Candidate parent's upload bandwidth exceeds the watermark.
+		if watermark := s.uploadBandwidthWatermark(candidateParent.Host); watermark > 0 {
+			if uploadBandwidth := candidateParent.Host.UploadBandwidth.Rate(); uploadBandwidth > float64(watermark) {
+				peer.Log.Debugf("parent %s is not selected because its upload bandwidth %.0fB/s exceeds the watermark %s",
+					candidateParent.ID, uploadBandwidth, watermark)
+				continue
+			}
+		}
+
 		candidateParents = append(candidateParents, candidateParent)
 		candidateParentIDs = append(candidateParentIDs, candidateParent.ID)
 	}
@@ -532,6 +571,64 @@ func (s *scheduling) filterCandidateParents(peer *resource.Peer, blocklist set.S
 	return candidateParents
 }
 
+// retryLimits returns the limits of scheduling retries, the limits in the scheduler cluster config
+// of manager take precedence over the scheduler config, so that they are changed without restart.
+func (s *scheduling) retryLimits() (int, int) {
+	retryLimit, retryBackToSourceLimit := s.config.RetryLimit, s.config.RetryBackToSourceLimit
+	if config, err := s.dynconfig.GetSchedulerClusterConfig(); err == nil {
+		if config.RetryLimit > 0 {
+			retryLimit = int(config.RetryLimit)
+		}
+
+		if config.RetryBackToSourceLimit > 0 {
+			retryBackToSourceLimit = int(config.RetryBackToSourceLimit)
+		}
+	}
+
+	return retryLimit, retryBackToSourceLimit
+}
+
+// holdsMissingPieces returns whether the running candidate parent holds the finished pieces
+// which have not been downloaded by the peer.
+func holdsMissingPieces(candidateParent, peer *resource.Peer) bool {
+	return candidateParent.FSM.Is(resource.PeerStateRunning) && peer.Task.HoldsMissingPieces(candidateParent.ID, peer)
+}
+
+// reservedUploadCount returns the upload count of host reserved for the higher priority class than class.
+func (s *scheduling) reservedUploadCount(class types.PriorityClass, host *resource.Host) int32 {
+	var ratio float64
+	switch class {
+	case types.PriorityClassBestEffort:
+		ratio = s.config.PriorityClass.BestEffortReservedUploadRatio
+	case types.PriorityClassNormal:
+		ratio = s.config.PriorityClass.NormalReservedUploadRatio
+	default:
+		return 0
+	}
+
+	return int32(math.Ceil(float64(host.ConcurrentUploadLimit.Load()) * ratio))
+}
+
+// sameIPFamily returns whether the ips of hosts are in the same address family,
+// hosts with invalid ip are regarded as in the same address family of any host.
+func sameIPFamily(host, other *resource.Host) bool {
+	ip, otherIP := net.ParseIP(host.IP), net.ParseIP(other.IP)
+	if ip == nil || otherIP == nil {
+		return true
+	}
+
+	return (ip.To4() == nil) == (otherIP.To4() == nil)
+}
+
+// uploadBandwidthWatermark returns the upload bandwidth watermark of host, zero means no watermark.
+func (s *scheduling) uploadBandwidthWatermark(host *resource.Host) unit.Bytes {
+	if host.Type == types.HostTypeNormal {
+		return s.config.UploadBandwidth.PeerWatermark
+	}
+
+	return s.config.UploadBandwidth.SeedPeerWatermark
+}
+
 // ConstructSuccessSmallTaskResponse constructs scheduling successful response of the small task.
 // Used only in v2 version of the grpc.
 func ConstructSuccessSmallTaskResponse(candidateParent *resource.Peer) *schedulerv2.AnnouncePeerResponse_SmallTaskResponse {
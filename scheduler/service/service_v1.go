@@ -41,6 +41,7 @@ import (
 	"d7y.io/dragonfly/v2/pkg/rpc/common"
 	"d7y.io/dragonfly/v2/pkg/types"
 	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/faultinject"
 	"d7y.io/dragonfly/v2/scheduler/metrics"
 	"d7y.io/dragonfly/v2/scheduler/resource"
 	"d7y.io/dragonfly/v2/scheduler/scheduling"
@@ -86,8 +87,18 @@ func NewV1(
 func (v *V1) RegisterPeerTask(ctx context.Context, req *schedulerv1.PeerTaskRequest) (*schedulerv1.RegisterResult, error) {
 	logger.WithPeer(req.PeerHost.Id, req.TaskId, req.PeerId).Infof("register peer task request: %#v", req)
 
+	// Validate the preferred piece length of task.
+	pieceLength, err := common.PieceLengthFromIncomingContext(ctx)
+	if err != nil {
+		return nil, dferrors.New(commonv1.Code_BadRequest, err.Error())
+	}
+
+	if err := resource.ValidatePieceLength(pieceLength); err != nil {
+		return nil, dferrors.New(commonv1.Code_BadRequest, err.Error())
+	}
+
 	// Store resource.
-	task := v.storeTask(ctx, req, commonv2.TaskType_DFDAEMON)
+	task := v.storeTask(ctx, req, commonv2.TaskType_DFDAEMON, pieceLength)
 	host := v.storeHost(ctx, req.PeerHost)
 	peer := v.storePeer(ctx, req.PeerId, req.UrlMeta.Priority, req.UrlMeta.Range, task, host)
 
@@ -226,6 +237,13 @@ func (v *V1) ReportPieceResult(stream schedulerv1.Scheduler_ReportPieceResultSer
 			}
 		}
 
+		// Handle piece download successfully as failed by fault injection.
+		if piece.Success && faultinject.FailPiece() {
+			peer.Log.Warnf("fail piece by fault injection: %#v", piece.PieceInfo)
+			piece.Success = false
+			piece.Code = commonv1.Code_ClientPieceDownloadFail
+		}
+
 		// Handle piece download successfully.
 		if piece.Success {
 			peer.Log.Infof("receive success piece: %#v %#v", piece, piece.PieceInfo)
@@ -334,6 +352,11 @@ func (v *V1) AnnounceTask(ctx context.Context, req *schedulerv1.AnnounceTaskRequ
 		req, req.UrlMeta, req.PeerHost, req.PiecePacket,
 	)
 
+	if faultinject.DropAnnouncement() {
+		logger.WithPeer(req.PeerHost.Id, req.TaskId, req.PiecePacket.DstPid).Warn("drop announce task request by fault injection")
+		return nil
+	}
+
 	taskID := req.TaskId
 	peerID := req.PiecePacket.DstPid
 	options := []resource.TaskOption{}
@@ -342,7 +365,7 @@ func (v *V1) AnnounceTask(ctx context.Context, req *schedulerv1.AnnounceTaskRequ
 	}
 
 	task := resource.NewTask(taskID, req.Url, req.UrlMeta.Tag, req.UrlMeta.Application, types.TaskTypeV1ToV2(req.TaskType),
-		strings.Split(req.UrlMeta.Filter, idgen.URLFilterSeparator), req.UrlMeta.Header, int32(v.config.Scheduler.BackToSourceCount), options...)
+		strings.Split(req.UrlMeta.Filter, idgen.URLFilterSeparator), req.UrlMeta.Header, v.backToSourceCount(), options...)
 	task, _ = v.resource.TaskManager().LoadOrStore(task)
 	host := v.storeHost(ctx, req.PeerHost)
 	peer := v.storePeer(ctx, peerID, req.UrlMeta.Priority, req.UrlMeta.Range, task, host)
@@ -371,11 +394,12 @@ func (v *V1) AnnounceTask(ctx context.Context, req *schedulerv1.AnnounceTaskRequ
 			}
 
 			if len(pieceInfo.PieceMd5) > 0 {
-				piece.Digest = digest.New(digest.AlgorithmMD5, pieceInfo.PieceMd5)
+				piece.Digest = digest.ParsePiece(pieceInfo.PieceMd5)
 			}
 
 			peer.StorePiece(piece)
 			peer.FinishedPieces.Set(uint(pieceInfo.PieceNum))
+			peer.Task.StorePiecePeer(pieceInfo.PieceNum, peer.ID)
 			peer.AppendPieceCost(piece.Cost)
 			task.StorePiece(piece)
 		}
@@ -739,18 +763,19 @@ func (v *V1) triggerSeedPeerTask(ctx context.Context, rg *http.Range, task *reso
 }
 
 // storeTask stores a new task or reuses a previous task.
-func (v *V1) storeTask(ctx context.Context, req *schedulerv1.PeerTaskRequest, typ commonv2.TaskType) *resource.Task {
+func (v *V1) storeTask(ctx context.Context, req *schedulerv1.PeerTaskRequest, typ commonv2.TaskType, pieceLength int32) *resource.Task {
 	filters := strings.Split(req.UrlMeta.Filter, idgen.URLFilterSeparator)
+	priorityClass := common.PriorityClassFromIncomingContext(ctx)
 
 	task, loaded := v.resource.TaskManager().Load(req.TaskId)
 	if !loaded {
-		options := []resource.TaskOption{}
+		options := []resource.TaskOption{resource.WithPieceLength(pieceLength), resource.WithPriorityClass(priorityClass)}
 		if d, err := digest.Parse(req.UrlMeta.Digest); err == nil {
 			options = append(options, resource.WithDigest(d))
 		}
 
 		task := resource.NewTask(req.TaskId, req.Url, req.UrlMeta.Tag, req.UrlMeta.Application,
-			typ, filters, req.UrlMeta.Header, int32(v.config.Scheduler.BackToSourceCount), options...)
+			typ, filters, req.UrlMeta.Header, v.backToSourceCount(), options...)
 		v.resource.TaskManager().Store(task)
 		task.Log.Info("create new task")
 		return task
@@ -761,6 +786,12 @@ func (v *V1) storeTask(ctx context.Context, req *schedulerv1.PeerTaskRequest, ty
 	task.URL = req.Url
 	task.Filters = filters
 	task.Header = req.UrlMeta.Header
+	task.RaisePriorityClass(priorityClass)
+
+	// The piece length can be changed only if the task has not been downloaded.
+	if pieceLength > 0 && task.FSM.Is(resource.TaskStatePending) {
+		task.PieceLength = pieceLength
+	}
 	task.Log.Info("task already exists")
 	return task
 }
@@ -901,7 +932,7 @@ func (v *V1) registerSmallTask(ctx context.Context, peer *resource.Peer) (*sched
 	}
 
 	if piece.Digest != nil {
-		pieceInfo.PieceMd5 = piece.Digest.Encoded
+		pieceInfo.PieceMd5 = piece.Digest.PieceString()
 	}
 
 	return &schedulerv1.RegisterResult{
@@ -999,11 +1030,12 @@ func (v *V1) handlePieceSuccess(ctx context.Context, peer *resource.Peer, pieceR
 	}
 
 	if len(pieceResult.PieceInfo.PieceMd5) > 0 {
-		piece.Digest = digest.New(digest.AlgorithmMD5, pieceResult.PieceInfo.PieceMd5)
+		piece.Digest = digest.ParsePiece(pieceResult.PieceInfo.PieceMd5)
 	}
 
 	peer.StorePiece(piece)
 	peer.FinishedPieces.Set(uint(piece.Number))
+	peer.Task.StorePiecePeer(piece.Number, peer.ID)
 	peer.AppendPieceCost(piece.Cost)
 
 	// When the piece is downloaded successfully,
@@ -1019,6 +1051,9 @@ func (v *V1) handlePieceSuccess(ctx context.Context, peer *resource.Peer, pieceR
 		if destPeer, loaded := v.resource.PeerManager().Load(pieceResult.DstPid); loaded {
 			destPeer.UpdatedAt.Store(time.Now())
 			destPeer.Host.UpdatedAt.Store(time.Now())
+
+			// Account the upload bandwidth of the dst peer's host.
+			destPeer.Host.UploadBandwidth.Add(int64(piece.Length))
 		}
 	}
 
@@ -1445,3 +1480,14 @@ func (v *V1) createDownloadRecord(peer *resource.Peer, parents []*resource.Peer,
 		peer.Log.Error(err)
 	}
 }
+
+// backToSourceCount returns the back-to-source count of the new task, the count in the scheduler
+// cluster config of manager takes precedence over the scheduler config, so that it is changed
+// without restart.
+func (v *V1) backToSourceCount() int32 {
+	if config, err := v.dynconfig.GetSchedulerClusterConfig(); err == nil && config.BackToSourceCount > 0 {
+		return int32(config.BackToSourceCount)
+	}
+
+	return int32(v.config.Scheduler.BackToSourceCount)
+}
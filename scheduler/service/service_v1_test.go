@@ -35,6 +35,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/atomic"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	commonv1 "d7y.io/api/pkg/apis/common/v1"
@@ -1707,6 +1708,7 @@ func TestServiceV1_AnnounceTask(t *testing.T) {
 			mockTask := resource.NewTask(mockTaskID, mockTaskURL, mockTaskTag, mockTaskApplication, commonv2.TaskType_DFDAEMON, mockTaskFilters, mockTaskHeader, mockTaskBackToSourceLimit, resource.WithDigest(mockTaskDigest), resource.WithPieceLength(mockTaskPieceLength))
 			mockPeer := resource.NewPeer(mockPeerID, mockTask, mockHost)
 
+			dynconfig.EXPECT().GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{}, errors.New("foo")).Times(1)
 			tc.mock(mockHost, mockTask, mockPeer, hostManager, taskManager, peerManager, res.EXPECT(), hostManager.EXPECT(), taskManager.EXPECT(), peerManager.EXPECT())
 			tc.expect(t, mockTask, mockPeer, svc.AnnounceTask(context.Background(), tc.req))
 		})
@@ -3025,11 +3027,11 @@ func TestServiceV1_triggerTask(t *testing.T) {
 func TestServiceV1_storeTask(t *testing.T) {
 	tests := []struct {
 		name string
-		run  func(t *testing.T, svc *V1, taskManager resource.TaskManager, mr *resource.MockResourceMockRecorder, mt *resource.MockTaskManagerMockRecorder)
+		run  func(t *testing.T, svc *V1, taskManager resource.TaskManager, mr *resource.MockResourceMockRecorder, mt *resource.MockTaskManagerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder)
 	}{
 		{
 			name: "task already exists",
-			run: func(t *testing.T, svc *V1, taskManager resource.TaskManager, mr *resource.MockResourceMockRecorder, mt *resource.MockTaskManagerMockRecorder) {
+			run: func(t *testing.T, svc *V1, taskManager resource.TaskManager, mr *resource.MockResourceMockRecorder, mt *resource.MockTaskManagerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder) {
 				mockTask := resource.NewTask(mockTaskID, "", mockTaskTag, mockTaskApplication, commonv2.TaskType_DFDAEMON, nil, nil, mockTaskBackToSourceLimit)
 
 				gomock.InOrder(
@@ -3046,7 +3048,7 @@ func TestServiceV1_storeTask(t *testing.T) {
 						Header:   mockTaskHeader,
 					},
 					PeerHost: mockPeerHost,
-				}, commonv2.TaskType_DFDAEMON)
+				}, commonv2.TaskType_DFDAEMON, 0)
 
 				assert := assert.New(t)
 				assert.EqualValues(task, mockTask)
@@ -3054,10 +3056,11 @@ func TestServiceV1_storeTask(t *testing.T) {
 		},
 		{
 			name: "task does not exist",
-			run: func(t *testing.T, svc *V1, taskManager resource.TaskManager, mr *resource.MockResourceMockRecorder, mt *resource.MockTaskManagerMockRecorder) {
+			run: func(t *testing.T, svc *V1, taskManager resource.TaskManager, mr *resource.MockResourceMockRecorder, mt *resource.MockTaskManagerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder) {
 				gomock.InOrder(
 					mr.TaskManager().Return(taskManager).Times(1),
 					mt.Load(gomock.Eq(mockTaskID)).Return(nil, false).Times(1),
+					md.GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{}, errors.New("foo")).Times(1),
 					mr.TaskManager().Return(taskManager).Times(1),
 					mt.Store(gomock.Any()).Return().Times(1),
 				)
@@ -3074,7 +3077,7 @@ func TestServiceV1_storeTask(t *testing.T) {
 						Header:      mockTaskHeader,
 					},
 					PeerHost: mockPeerHost,
-				}, commonv2.TaskType_DFCACHE)
+				}, commonv2.TaskType_DFCACHE, mockTaskPieceLength)
 
 				assert := assert.New(t)
 				assert.Equal(task.ID, mockTaskID)
@@ -3085,7 +3088,7 @@ func TestServiceV1_storeTask(t *testing.T) {
 				assert.Equal(task.Application, mockTaskApplication)
 				assert.EqualValues(task.Filters, mockTaskFilters)
 				assert.EqualValues(task.Header, mockTaskHeader)
-				assert.Equal(task.PieceLength, int32(0))
+				assert.Equal(task.PieceLength, mockTaskPieceLength)
 				assert.Empty(task.DirectPiece)
 				assert.Equal(task.ContentLength.Load(), int64(-1))
 				assert.Equal(task.TotalPieceCount.Load(), int32(0))
@@ -3097,6 +3100,117 @@ func TestServiceV1_storeTask(t *testing.T) {
 				assert.NotEqual(task.CreatedAt.Load(), 0)
 				assert.NotEqual(task.UpdatedAt.Load(), 0)
 				assert.NotNil(task.Log)
+				assert.Equal(task.PriorityClass(), pkgtypes.PriorityClassNormal)
+			},
+		},
+		{
+			name: "task already exists and running task keeps piece length",
+			run: func(t *testing.T, svc *V1, taskManager resource.TaskManager, mr *resource.MockResourceMockRecorder, mt *resource.MockTaskManagerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder) {
+				mockTask := resource.NewTask(mockTaskID, "", mockTaskTag, mockTaskApplication, commonv2.TaskType_DFDAEMON, nil, nil, mockTaskBackToSourceLimit,
+					resource.WithPieceLength(mockTaskPieceLength))
+				mockTask.FSM.SetState(resource.TaskStateRunning)
+
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Load(gomock.Eq(mockTaskID)).Return(mockTask, true).Times(1),
+				)
+
+				task := svc.storeTask(context.Background(), &schedulerv1.PeerTaskRequest{
+					TaskId: mockTaskID,
+					Url:    mockTaskURL,
+					UrlMeta: &commonv1.UrlMeta{
+						Priority: commonv1.Priority_LEVEL0,
+						Filter:   strings.Join(mockTaskFilters, idgen.URLFilterSeparator),
+						Header:   mockTaskHeader,
+					},
+					PeerHost: mockPeerHost,
+				}, commonv2.TaskType_DFDAEMON, resource.MaxPieceLength)
+
+				assert := assert.New(t)
+				assert.Equal(task.PieceLength, mockTaskPieceLength)
+			},
+		},
+		{
+			name: "task does not exist and request has priority class",
+			run: func(t *testing.T, svc *V1, taskManager resource.TaskManager, mr *resource.MockResourceMockRecorder, mt *resource.MockTaskManagerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder) {
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Load(gomock.Eq(mockTaskID)).Return(nil, false).Times(1),
+					md.GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{}, errors.New("foo")).Times(1),
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Store(gomock.Any()).Return().Times(1),
+				)
+
+				ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(pkgtypes.PriorityClassMetadataKey, pkgtypes.PriorityClassBestEffortName))
+				task := svc.storeTask(ctx, &schedulerv1.PeerTaskRequest{
+					TaskId: mockTaskID,
+					Url:    mockTaskURL,
+					UrlMeta: &commonv1.UrlMeta{
+						Priority: commonv1.Priority_LEVEL0,
+						Filter:   strings.Join(mockTaskFilters, idgen.URLFilterSeparator),
+						Header:   mockTaskHeader,
+					},
+					PeerHost: mockPeerHost,
+				}, commonv2.TaskType_DFDAEMON, 0)
+
+				assert := assert.New(t)
+				assert.Equal(task.ID, mockTaskID)
+				assert.Equal(task.PriorityClass(), pkgtypes.PriorityClassBestEffort)
+			},
+		},
+		{
+			name: "task does not exist and scheduler cluster config has back-to-source count",
+			run: func(t *testing.T, svc *V1, taskManager resource.TaskManager, mr *resource.MockResourceMockRecorder, mt *resource.MockTaskManagerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder) {
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Load(gomock.Eq(mockTaskID)).Return(nil, false).Times(1),
+					md.GetSchedulerClusterConfig().Return(types.SchedulerClusterConfig{BackToSourceCount: 10}, nil).Times(1),
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Store(gomock.Any()).Return().Times(1),
+				)
+
+				task := svc.storeTask(context.Background(), &schedulerv1.PeerTaskRequest{
+					TaskId: mockTaskID,
+					Url:    mockTaskURL,
+					UrlMeta: &commonv1.UrlMeta{
+						Priority: commonv1.Priority_LEVEL0,
+						Filter:   strings.Join(mockTaskFilters, idgen.URLFilterSeparator),
+						Header:   mockTaskHeader,
+					},
+					PeerHost: mockPeerHost,
+				}, commonv2.TaskType_DFDAEMON, 0)
+
+				assert := assert.New(t)
+				assert.Equal(task.ID, mockTaskID)
+				assert.Equal(task.BackToSourceLimit.Load(), int32(10))
+			},
+		},
+		{
+			name: "task already exists and request raises priority class",
+			run: func(t *testing.T, svc *V1, taskManager resource.TaskManager, mr *resource.MockResourceMockRecorder, mt *resource.MockTaskManagerMockRecorder, md *configmocks.MockDynconfigInterfaceMockRecorder) {
+				mockTask := resource.NewTask(mockTaskID, "", mockTaskTag, mockTaskApplication, commonv2.TaskType_DFDAEMON, nil, nil, mockTaskBackToSourceLimit,
+					resource.WithPriorityClass(pkgtypes.PriorityClassBestEffort))
+
+				gomock.InOrder(
+					mr.TaskManager().Return(taskManager).Times(1),
+					mt.Load(gomock.Eq(mockTaskID)).Return(mockTask, true).Times(1),
+				)
+
+				ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(pkgtypes.PriorityClassMetadataKey, pkgtypes.PriorityClassCriticalName))
+				task := svc.storeTask(ctx, &schedulerv1.PeerTaskRequest{
+					TaskId: mockTaskID,
+					Url:    mockTaskURL,
+					UrlMeta: &commonv1.UrlMeta{
+						Priority: commonv1.Priority_LEVEL0,
+						Filter:   strings.Join(mockTaskFilters, idgen.URLFilterSeparator),
+						Header:   mockTaskHeader,
+					},
+					PeerHost: mockPeerHost,
+				}, commonv2.TaskType_DFDAEMON, 0)
+
+				assert := assert.New(t)
+				assert.EqualValues(task, mockTask)
+				assert.Equal(task.PriorityClass(), pkgtypes.PriorityClassCritical)
 			},
 		},
 	}
@@ -3111,7 +3225,7 @@ func TestServiceV1_storeTask(t *testing.T) {
 			storage := storagemocks.NewMockStorage(ctl)
 			svc := NewV1(&config.Config{Scheduler: mockSchedulerConfig}, res, scheduling, dynconfig, storage)
 			taskManager := resource.NewMockTaskManager(ctl)
-			tc.run(t, svc, taskManager, res.EXPECT(), taskManager.EXPECT())
+			tc.run(t, svc, taskManager, res.EXPECT(), taskManager.EXPECT(), dynconfig.EXPECT())
 		})
 	}
 }
@@ -34,8 +34,10 @@ import (
 	"d7y.io/dragonfly/v2/pkg/container/set"
 	"d7y.io/dragonfly/v2/pkg/digest"
 	"d7y.io/dragonfly/v2/pkg/net/http"
+	"d7y.io/dragonfly/v2/pkg/rpc/common"
 	"d7y.io/dragonfly/v2/pkg/types"
 	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/faultinject"
 	"d7y.io/dragonfly/v2/scheduler/metrics"
 	"d7y.io/dragonfly/v2/scheduler/resource"
 	"d7y.io/dragonfly/v2/scheduler/scheduling"
@@ -101,6 +103,11 @@ func (v *V2) AnnouncePeer(stream schedulerv2.Scheduler_AnnouncePeerServer) error
 		}
 
 		logger := logger.WithPeer(req.HostId, req.TaskId, req.PeerId)
+		if faultinject.DropAnnouncement() {
+			logger.Warnf("drop AnnouncePeerRequest by fault injection: %T", req.GetRequest())
+			continue
+		}
+
 		switch announcePeerRequest := req.GetRequest().(type) {
 		case *schedulerv2.AnnouncePeerRequest_RegisterPeerRequest:
 			logger.Infof("receive AnnouncePeerRequest_RegisterPeerRequest: %s", announcePeerRequest.RegisterPeerRequest.Download.Url)
@@ -152,6 +159,19 @@ func (v *V2) AnnouncePeer(stream schedulerv2.Scheduler_AnnouncePeerServer) error
 			}
 		case *schedulerv2.AnnouncePeerRequest_DownloadPieceFinishedRequest:
 			logger.Infof("receive AnnouncePeerRequest_DownloadPieceFinishedRequest: %#v", announcePeerRequest.DownloadPieceFinishedRequest)
+			if faultinject.FailPiece() {
+				logger.Warn("fail piece by fault injection")
+				if err := v.handleDownloadPieceFailedRequest(ctx, req.PeerId, &schedulerv2.DownloadPieceFailedRequest{
+					Piece:     announcePeerRequest.DownloadPieceFinishedRequest.Piece,
+					Temporary: true,
+				}); err != nil {
+					logger.Error(err)
+					return err
+				}
+
+				continue
+			}
+
 			if err := v.handleDownloadPieceFinishedRequest(ctx, req.PeerId, announcePeerRequest.DownloadPieceFinishedRequest); err != nil {
 				logger.Error(err)
 				return err
@@ -939,6 +959,7 @@ func (v *V2) handleDownloadPieceFinishedRequest(ctx context.Context, peerID stri
 	// to be updated to prevent the peer from being GC during the download process.
 	peer.StorePiece(piece)
 	peer.FinishedPieces.Set(uint(piece.Number))
+	peer.Task.StorePiecePeer(piece.Number, peer.ID)
 	peer.AppendPieceCost(piece.Cost)
 	peer.PieceUpdatedAt.Store(time.Now())
 	peer.UpdatedAt.Store(time.Now())
@@ -949,6 +970,9 @@ func (v *V2) handleDownloadPieceFinishedRequest(ctx context.Context, peerID stri
 	if loadedParent {
 		parent.UpdatedAt.Store(time.Now())
 		parent.Host.UpdatedAt.Store(time.Now())
+
+		// Account the upload bandwidth of the parent's host.
+		parent.Host.UploadBandwidth.Add(int64(piece.Length))
 	}
 
 	// Handle task with piece finished request.
@@ -1002,6 +1026,7 @@ func (v *V2) handleDownloadPieceBackToSourceFinishedRequest(ctx context.Context,
 	// needs to be updated to prevent the peer from being GC during the download process.
 	peer.StorePiece(piece)
 	peer.FinishedPieces.Set(uint(piece.Number))
+	peer.Task.StorePiecePeer(piece.Number, peer.ID)
 	peer.AppendPieceCost(piece.Cost)
 	peer.PieceUpdatedAt.Store(time.Now())
 	peer.UpdatedAt.Store(time.Now())
@@ -1092,10 +1117,16 @@ func (v *V2) handleResource(ctx context.Context, stream schedulerv2.Scheduler_An
 		return nil, nil, nil, status.Errorf(codes.NotFound, "host %s not found", hostID)
 	}
 
+	// Validate the preferred piece length of task.
+	if err := resource.ValidatePieceLength(download.PieceLength); err != nil {
+		return nil, nil, nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
 	// Store new task or update task.
+	priorityClass := common.PriorityClassFromIncomingContext(ctx)
 	task, loaded := v.resource.TaskManager().Load(taskID)
 	if !loaded {
-		options := []resource.TaskOption{resource.WithPieceLength(download.PieceLength)}
+		options := []resource.TaskOption{resource.WithPieceLength(download.PieceLength), resource.WithPriorityClass(priorityClass)}
 		if download.Digest != "" {
 			d, err := digest.Parse(download.Digest)
 			if err != nil {
@@ -1107,12 +1138,18 @@ func (v *V2) handleResource(ctx context.Context, stream schedulerv2.Scheduler_An
 		}
 
 		task = resource.NewTask(taskID, download.Url, download.Tag, download.Application, download.Type,
-			download.Filters, download.Header, int32(v.config.Scheduler.BackToSourceCount), options...)
+			download.Filters, download.Header, v.backToSourceCount(), options...)
 		v.resource.TaskManager().Store(task)
 	} else {
 		task.URL = download.Url
 		task.Filters = download.Filters
 		task.Header = download.Header
+		task.RaisePriorityClass(priorityClass)
+
+		// The piece length can be changed only if the task has not been downloaded.
+		if download.PieceLength > 0 && task.FSM.Is(resource.TaskStatePending) {
+			task.PieceLength = download.PieceLength
+		}
 	}
 
 	// Store new peer or load peer.
@@ -1306,3 +1343,14 @@ func (v *V2) schedule(ctx context.Context, peer *resource.Peer) error {
 
 	return nil
 }
+
+// backToSourceCount returns the back-to-source count of the new task, the count in the scheduler
+// cluster config of manager takes precedence over the scheduler config, so that it is changed
+// without restart.
+func (v *V2) backToSourceCount() int32 {
+	if config, err := v.dynconfig.GetSchedulerClusterConfig(); err == nil && config.BackToSourceCount > 0 {
+		return int32(config.BackToSourceCount)
+	}
+
+	return int32(v.config.Scheduler.BackToSourceCount)
+}
@@ -0,0 +1,284 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+const (
+	// ArchiveSchemaVersion is the schema version of archive.
+	ArchiveSchemaVersion = 1
+
+	// ArchiveManifestName is the entry name of manifest in archive.
+	ArchiveManifestName = "manifest.json"
+
+	// importBatchSize is the number of records inserted in a batch when importing archive.
+	importBatchSize = 1000
+)
+
+var (
+	// archiveDownloadName is the entry name of downloads in archive.
+	archiveDownloadName = fmt.Sprintf("%s.%s", DownloadFilePrefix, CSVFileExt)
+
+	// archiveNetworkTopologyName is the entry name of network topologies in archive.
+	archiveNetworkTopologyName = fmt.Sprintf("%s.%s", NetworkTopologyFilePrefix, CSVFileExt)
+
+	// gzipMagic is the magic header of gzip.
+	gzipMagic = []byte{0x1f, 0x8b}
+)
+
+// ArchiveManifest is the manifest of archive.
+type ArchiveManifest struct {
+	// SchemaVersion is the schema version of archive.
+	SchemaVersion int `json:"schemaVersion"`
+
+	// DownloadCount is the count of downloads in archive.
+	DownloadCount int64 `json:"downloadCount"`
+
+	// NetworkTopologyCount is the count of network topologies in archive.
+	NetworkTopologyCount int64 `json:"networkTopologyCount"`
+
+	// CreatedAt is the time of exporting archive.
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ArchiveOption is a functional option for configuring the archive.
+type ArchiveOption func(o *archiveOptions)
+
+// archiveOptions is the options of archive.
+type archiveOptions struct {
+	gzip bool
+}
+
+// WithArchiveGzip compresses the archive with gzip.
+func WithArchiveGzip() ArchiveOption {
+	return func(o *archiveOptions) {
+		o.gzip = true
+	}
+}
+
+// writeArchive writes the downloads and network topologies of csv format into a tar archive with manifest.
+// The records are spooled into temporary files first, because the size of tar entry must be known in advance.
+func writeArchive(w io.Writer, download, networkTopology io.Reader, options ...ArchiveOption) error {
+	o := &archiveOptions{}
+	for _, opt := range options {
+		opt(o)
+	}
+
+	downloadFile, downloadCount, err := spool(download)
+	if err != nil {
+		return fmt.Errorf("spool download: %w", err)
+	}
+	defer os.Remove(downloadFile.Name())
+	defer downloadFile.Close()
+
+	networkTopologyFile, networkTopologyCount, err := spool(networkTopology)
+	if err != nil {
+		return fmt.Errorf("spool network topology: %w", err)
+	}
+	defer os.Remove(networkTopologyFile.Name())
+	defer networkTopologyFile.Close()
+
+	manifest, err := json.Marshal(ArchiveManifest{
+		SchemaVersion:        ArchiveSchemaVersion,
+		DownloadCount:        downloadCount,
+		NetworkTopologyCount: networkTopologyCount,
+		CreatedAt:            time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	var gw *gzip.Writer
+	if o.gzip {
+		gw = gzip.NewWriter(w)
+		w = gw
+	}
+
+	tw := tar.NewWriter(w)
+	if err := writeArchiveEntry(tw, ArchiveManifestName, bytes.NewReader(manifest), int64(len(manifest))); err != nil {
+		return err
+	}
+
+	for _, entry := range []struct {
+		name string
+		file *os.File
+	}{
+		{archiveDownloadName, downloadFile},
+		{archiveNetworkTopologyName, networkTopologyFile},
+	} {
+		fileInfo, err := entry.file.Stat()
+		if err != nil {
+			return err
+		}
+
+		if err := writeArchiveEntry(tw, entry.name, entry.file, fileInfo.Size()); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	if gw != nil {
+		return gw.Close()
+	}
+
+	return nil
+}
+
+// writeArchiveEntry writes the entry into tar archive.
+func writeArchiveEntry(tw *tar.Writer, name string, r io.Reader, size int64) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    size,
+		ModTime: time.Now(),
+	}); err != nil {
+		return err
+	}
+
+	_, err := io.Copy(tw, r)
+	return err
+}
+
+// spool copies the records of csv format into a temporary file and counts the records,
+// the returned file is rewound to the beginning.
+func spool(r io.Reader) (*os.File, int64, error) {
+	file, err := os.CreateTemp("", "scheduler-archive-*")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	count, err := copyLines(file, r)
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return nil, 0, err
+	}
+
+	return file, count, nil
+}
+
+// copyLines copies r to w and returns the number of non-empty lines.
+func copyLines(w io.Writer, r io.Reader) (int64, error) {
+	var count int64
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			count++
+		}
+
+		if _, err := w.Write(line); err != nil {
+			return 0, err
+		}
+
+		if err != nil {
+			if err == io.EOF {
+				return count, nil
+			}
+
+			return 0, err
+		}
+	}
+}
+
+// readArchive reads the tar archive which may be compressed with gzip, and imports the
+// downloads and network topologies. The manifest must be the first entry of archive,
+// and the counts of imported records must match the manifest.
+func readArchive(r io.Reader, importDownload, importNetworkTopology func(io.Reader) (int64, error)) error {
+	reader := bufio.NewReader(r)
+	if magic, err := reader.Peek(len(gzipMagic)); err == nil && bytes.Equal(magic, gzipMagic) {
+		gr, err := gzip.NewReader(reader)
+		if err != nil {
+			return err
+		}
+		defer gr.Close()
+		r = gr
+	} else {
+		r = reader
+	}
+
+	tr := tar.NewReader(r)
+	header, err := tr.Next()
+	if err != nil {
+		return fmt.Errorf("read manifest: %w", err)
+	}
+
+	if header.Name != ArchiveManifestName {
+		return fmt.Errorf("invalid archive entry %s, manifest must be the first entry", header.Name)
+	}
+
+	var manifest ArchiveManifest
+	if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+		return fmt.Errorf("decode manifest: %w", err)
+	}
+
+	if manifest.SchemaVersion != ArchiveSchemaVersion {
+		return fmt.Errorf("unsupported archive schema version %d", manifest.SchemaVersion)
+	}
+
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		switch header.Name {
+		case archiveDownloadName:
+			count, err := importDownload(tr)
+			if err != nil {
+				return fmt.Errorf("import download: %w", err)
+			}
+
+			if count != manifest.DownloadCount {
+				return fmt.Errorf("imported %d downloads, but manifest has %d", count, manifest.DownloadCount)
+			}
+		case archiveNetworkTopologyName:
+			count, err := importNetworkTopology(tr)
+			if err != nil {
+				return fmt.Errorf("import network topology: %w", err)
+			}
+
+			if count != manifest.NetworkTopologyCount {
+				return fmt.Errorf("imported %d network topologies, but manifest has %d", count, manifest.NetworkTopologyCount)
+			}
+		default:
+			return fmt.Errorf("invalid archive entry %s", header.Name)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	// EncryptionKeySize is the size of AES-256 key.
+	EncryptionKeySize = 32
+
+	// maxKeyIDLength is the maximum length of key id.
+	maxKeyIDLength = 255
+
+	// segmentHeaderSize is the size of segment length header.
+	segmentHeaderSize = 4
+)
+
+var (
+	// ErrDecrypt is returned when the segment can not be decrypted,
+	// e.g. it is encrypted with a different key.
+	ErrDecrypt = errors.New("decrypt segment failed")
+
+	// ErrUnknownEncryptionKey is returned when the key id of segment is not found.
+	ErrUnknownEncryptionKey = errors.New("unknown encryption key")
+)
+
+// EncryptionKey is the AES-GCM key used for encryption at rest.
+type EncryptionKey struct {
+	// ID is the key id tagged on each encrypted segment, used for key rotation.
+	ID string
+
+	// Secret is the AES-256 key.
+	Secret []byte
+}
+
+// ReadEncryptionKey reads the base64 encoded key from file, or from environment variable if file is empty.
+func ReadEncryptionKey(file, env string) ([]byte, error) {
+	var encoded string
+	switch {
+	case file != "":
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		encoded = string(data)
+	case env != "":
+		value, ok := os.LookupEnv(env)
+		if !ok {
+			return nil, fmt.Errorf("environment variable %s is not set", env)
+		}
+
+		encoded = value
+	default:
+		return nil, errors.New("encryption key requires file or env")
+	}
+
+	secret, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(secret) != EncryptionKeySize {
+		return nil, fmt.Errorf("invalid encryption key size %d", len(secret))
+	}
+
+	return secret, nil
+}
+
+// encryptor encrypts records into segments and decrypts segments into records.
+// Each segment is framed as:
+// | length (4 bytes) | key id length (1 byte) | key id | nonce | ciphertext |.
+type encryptor struct {
+	currentKeyID string
+	aeads        map[string]cipher.AEAD
+}
+
+// newEncryptor returns a new encryptor, the segments are encrypted with the key of currentKeyID
+// and decrypted with the key tagged on the segment.
+func newEncryptor(keys []EncryptionKey, currentKeyID string) (*encryptor, error) {
+	e := &encryptor{
+		currentKeyID: currentKeyID,
+		aeads:        make(map[string]cipher.AEAD, len(keys)),
+	}
+
+	for _, key := range keys {
+		if key.ID == "" || len(key.ID) > maxKeyIDLength {
+			return nil, fmt.Errorf("invalid encryption key id %q", key.ID)
+		}
+
+		block, err := aes.NewCipher(key.Secret)
+		if err != nil {
+			return nil, err
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+
+		e.aeads[key.ID] = aead
+	}
+
+	if _, ok := e.aeads[currentKeyID]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEncryptionKey, currentKeyID)
+	}
+
+	return e, nil
+}
+
+// encrypt encrypts the plaintext into a segment.
+func (e *encryptor) encrypt(plaintext []byte) ([]byte, error) {
+	aead := e.aeads[e.currentKeyID]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	body := make([]byte, 0, 1+len(e.currentKeyID)+len(nonce)+len(plaintext)+aead.Overhead())
+	body = append(body, byte(len(e.currentKeyID)))
+	body = append(body, e.currentKeyID...)
+	body = append(body, nonce...)
+	body = aead.Seal(body, nonce, plaintext, []byte(e.currentKeyID))
+
+	segment := make([]byte, segmentHeaderSize, segmentHeaderSize+len(body))
+	binary.BigEndian.PutUint32(segment, uint32(len(body)))
+	return append(segment, body...), nil
+}
+
+// decrypt decrypts the body of segment.
+func (e *encryptor) decrypt(body []byte) ([]byte, error) {
+	if len(body) < 1 || len(body) < 1+int(body[0]) {
+		return nil, fmt.Errorf("%w: invalid segment", ErrDecrypt)
+	}
+
+	keyID := string(body[1 : 1+int(body[0])])
+	aead, ok := e.aeads[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEncryptionKey, keyID)
+	}
+
+	body = body[1+len(keyID):]
+	if len(body) < aead.NonceSize() {
+		return nil, fmt.Errorf("%w: invalid segment", ErrDecrypt)
+	}
+
+	plaintext, err := aead.Open(nil, body[:aead.NonceSize()], body[aead.NonceSize():], []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrDecrypt, err.Error())
+	}
+
+	return plaintext, nil
+}
+
+// newReader returns a reader decrypting the segments of r.
+func (e *encryptor) newReader(r io.Reader) io.Reader {
+	return &decryptReader{encryptor: e, reader: r}
+}
+
+// decryptReader decrypts the segments of reader.
+type decryptReader struct {
+	encryptor *encryptor
+	reader    io.Reader
+	buf       bytes.Buffer
+	err       error
+}
+
+// Read reads the decrypted records.
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for r.buf.Len() == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+
+		r.err = r.next()
+	}
+
+	return r.buf.Read(p)
+}
+
+// next reads and decrypts the next segment into buffer.
+func (r *decryptReader) next() error {
+	header := make([]byte, segmentHeaderSize)
+	if _, err := io.ReadFull(r.reader, header); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return fmt.Errorf("%w: truncated segment", ErrDecrypt)
+		}
+
+		return err
+	}
+
+	body := make([]byte, binary.BigEndian.Uint32(header))
+	if _, err := io.ReadFull(r.reader, body); err != nil {
+		return fmt.Errorf("%w: truncated segment", ErrDecrypt)
+	}
+
+	plaintext, err := r.encryptor.decrypt(body)
+	if err != nil {
+		return err
+	}
+
+	r.buf.Write(plaintext)
+	return nil
+}
@@ -0,0 +1,199 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gocarina/gocsv"
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/scheduler/config"
+)
+
+var (
+	mockEncryptionKey = EncryptionKey{
+		ID:     "foo",
+		Secret: bytes.Repeat([]byte{1}, EncryptionKeySize),
+	}
+
+	mockRotatedEncryptionKey = EncryptionKey{
+		ID:     "bar",
+		Secret: bytes.Repeat([]byte{2}, EncryptionKeySize),
+	}
+)
+
+func TestStorage_Encryption(t *testing.T) {
+	tests := []struct {
+		name   string
+		expect func(t *testing.T, baseDir string)
+	}{
+		{
+			name: "round trip of encrypted records",
+			expect: func(t *testing.T, baseDir string) {
+				assert := assert.New(t)
+				s, err := New(baseDir, config.DefaultStorageMaxSize, config.DefaultStorageMaxBackups, 0,
+					WithEncryption([]EncryptionKey{mockEncryptionKey}, mockEncryptionKey.ID))
+				assert.NoError(err)
+
+				assert.NoError(s.CreateDownload(mockDownload))
+				assert.NoError(s.CreateDownload(Download{ID: "1"}))
+				assert.NoError(s.CreateNetworkTopology(mockNetworkTopology))
+
+				data, err := os.ReadFile(filepath.Join(baseDir, "download.csv"))
+				assert.NoError(err)
+				assert.NotContains(string(data), mockDownload.Host.IP)
+
+				downloads, err := s.ListDownload()
+				assert.NoError(err)
+				assert.Equal(2, len(downloads))
+				assert.EqualValues(mockDownload, downloads[0])
+				assert.Equal("1", downloads[1].ID)
+
+				readCloser, err := s.OpenDownload()
+				assert.NoError(err)
+				defer readCloser.Close()
+
+				downloads = nil
+				assert.NoError(gocsv.UnmarshalWithoutHeaders(readCloser, &downloads))
+				assert.Equal(2, len(downloads))
+
+				networkTopologies, err := s.ListNetworkTopology()
+				assert.NoError(err)
+				assert.Equal(1, len(networkTopologies))
+				assert.Equal(mockNetworkTopology.ID, networkTopologies[0].ID)
+			},
+		},
+		{
+			name: "read records encrypted with rotated key",
+			expect: func(t *testing.T, baseDir string) {
+				assert := assert.New(t)
+				s, err := New(baseDir, config.DefaultStorageMaxSize, config.DefaultStorageMaxBackups, 0,
+					WithEncryption([]EncryptionKey{mockRotatedEncryptionKey}, mockRotatedEncryptionKey.ID))
+				assert.NoError(err)
+				assert.NoError(s.CreateDownload(Download{ID: "1"}))
+
+				// Rotate the key, the records encrypted with old key are still readable.
+				s.(*storage).encryptor, err = newEncryptor([]EncryptionKey{mockEncryptionKey, mockRotatedEncryptionKey}, mockEncryptionKey.ID)
+				assert.NoError(err)
+				assert.NoError(s.CreateDownload(Download{ID: "2"}))
+
+				downloads, err := s.ListDownload()
+				assert.NoError(err)
+				assert.Equal(2, len(downloads))
+				assert.Equal("1", downloads[0].ID)
+				assert.Equal("2", downloads[1].ID)
+			},
+		},
+		{
+			name: "read records with wrong key",
+			expect: func(t *testing.T, baseDir string) {
+				assert := assert.New(t)
+				s, err := New(baseDir, config.DefaultStorageMaxSize, config.DefaultStorageMaxBackups, 0,
+					WithEncryption([]EncryptionKey{mockEncryptionKey}, mockEncryptionKey.ID))
+				assert.NoError(err)
+				assert.NoError(s.CreateDownload(mockDownload))
+
+				s.(*storage).encryptor, err = newEncryptor([]EncryptionKey{{ID: mockEncryptionKey.ID, Secret: mockRotatedEncryptionKey.Secret}}, mockEncryptionKey.ID)
+				assert.NoError(err)
+
+				_, err = s.ListDownload()
+				assert.ErrorIs(err, ErrDecrypt)
+
+				readCloser, err := s.OpenDownload()
+				assert.NoError(err)
+				defer readCloser.Close()
+
+				_, err = io.ReadAll(readCloser)
+				assert.ErrorIs(err, ErrDecrypt)
+			},
+		},
+		{
+			name: "read records with unknown key id",
+			expect: func(t *testing.T, baseDir string) {
+				assert := assert.New(t)
+				s, err := New(baseDir, config.DefaultStorageMaxSize, config.DefaultStorageMaxBackups, 0,
+					WithEncryption([]EncryptionKey{mockEncryptionKey}, mockEncryptionKey.ID))
+				assert.NoError(err)
+				assert.NoError(s.CreateDownload(mockDownload))
+
+				s.(*storage).encryptor, err = newEncryptor([]EncryptionKey{mockRotatedEncryptionKey}, mockRotatedEncryptionKey.ID)
+				assert.NoError(err)
+
+				_, err = s.ListDownload()
+				assert.ErrorIs(err, ErrUnknownEncryptionKey)
+			},
+		},
+		{
+			name: "current key is not found",
+			expect: func(t *testing.T, baseDir string) {
+				assert := assert.New(t)
+				_, err := New(baseDir, config.DefaultStorageMaxSize, config.DefaultStorageMaxBackups, 0,
+					WithEncryption([]EncryptionKey{mockEncryptionKey}, mockRotatedEncryptionKey.ID))
+				assert.ErrorIs(err, ErrUnknownEncryptionKey)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expect(t, t.TempDir())
+		})
+	}
+}
+
+func TestStorage_ReadEncryptionKey(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString(mockEncryptionKey.Secret)
+
+	t.Run("read key from file", func(t *testing.T) {
+		assert := assert.New(t)
+		file := filepath.Join(t.TempDir(), "key")
+		assert.NoError(os.WriteFile(file, []byte(encoded+"\n"), 0600))
+
+		secret, err := ReadEncryptionKey(file, "")
+		assert.NoError(err)
+		assert.Equal(mockEncryptionKey.Secret, secret)
+	})
+
+	t.Run("read key from env", func(t *testing.T) {
+		assert := assert.New(t)
+		t.Setenv("DRAGONFLY_STORAGE_KEY", encoded)
+
+		secret, err := ReadEncryptionKey("", "DRAGONFLY_STORAGE_KEY")
+		assert.NoError(err)
+		assert.Equal(mockEncryptionKey.Secret, secret)
+	})
+
+	t.Run("key has invalid size", func(t *testing.T) {
+		assert := assert.New(t)
+		t.Setenv("DRAGONFLY_STORAGE_KEY", base64.StdEncoding.EncodeToString([]byte("foo")))
+
+		_, err := ReadEncryptionKey("", "DRAGONFLY_STORAGE_KEY")
+		assert.Error(err)
+	})
+
+	t.Run("key source is empty", func(t *testing.T) {
+		assert := assert.New(t)
+		_, err := ReadEncryptionKey("", "")
+		assert.Error(err)
+	})
+}
@@ -0,0 +1,275 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// recordFiles tracks the records in the files of a dataset, so that the oldest records are
+// evicted without reading the files on each append. The records are evicted one by one, but
+// the files are dropped as a whole once all their records are evicted.
+type recordFiles struct {
+	// stats are the record counts and sizes of files by file name, the stats of a file are
+	// counted by reading the file once if they are unknown.
+	stats map[string]fileStats
+
+	// records is the number of records in files, including the evicted records.
+	records int64
+
+	// origin is the epoch and the size of dropped files in the history of dataset, it is
+	// saved into originFilename before the files are dropped.
+	origin         origin
+	originFilename string
+
+	// evicted is the number of the oldest records evicted from the oldest file, they are
+	// skipped when reading, because the file is not dropped until all its records are evicted.
+	evicted int64
+}
+
+// fileStats is the record count and size of file, the size is counted before encryption.
+type fileStats struct {
+	records int64
+	size    int64
+}
+
+// newRecordFiles returns a new recordFiles with the empty current file of filename, and the
+// origin saved in originFilename.
+func newRecordFiles(filename, originFilename string, origin origin) *recordFiles {
+	return &recordFiles{
+		stats:          map[string]fileStats{filename: {}},
+		origin:         origin,
+		originFilename: originFilename,
+	}
+}
+
+// add adds n records of size bytes written into the file of filename.
+func (r *recordFiles) add(filename string, n, size int64) {
+	stats := r.stats[filename]
+	stats.records += n
+	stats.size += size
+	r.stats[filename] = stats
+	r.records += n
+}
+
+// rename moves the stats of file after the file is rotated.
+func (r *recordFiles) rename(oldFilename, newFilename string) {
+	if stats, ok := r.stats[oldFilename]; ok {
+		r.stats[newFilename] = stats
+		delete(r.stats, oldFilename)
+	}
+}
+
+// remove removes the records of file after the file is dropped, the evicted records of the
+// file are no longer skipped.
+func (r *recordFiles) remove(filename string, stats fileStats) {
+	delete(r.stats, filename)
+	r.records -= stats.records
+	r.evicted -= stats.records
+	if r.evicted < 0 {
+		r.evicted = 0
+	}
+}
+
+// retained returns the number of records not evicted.
+func (r *recordFiles) retained() int64 {
+	return r.records - r.evicted
+}
+
+// evictDownload evicts the oldest downloads in files beyond maxRecords.
+func (s *storage) evictDownload() error {
+	if err := s.evict(s.downloadFilename, s.downloadBackups, s.downloadRecords); err != nil {
+		return err
+	}
+
+	if s.maxRecords > 0 && s.downloadCount > s.downloadRecords.retained() {
+		s.downloadCount = s.downloadRecords.retained()
+	}
+
+	return nil
+}
+
+// evictNetworkTopology evicts the oldest network topologies in files beyond maxRecords.
+func (s *storage) evictNetworkTopology() error {
+	if err := s.evict(s.networkTopologyFilename, s.networkTopologyBackups, s.networkTopologyRecords); err != nil {
+		return err
+	}
+
+	if s.maxRecords > 0 && s.networkTopologyCount > s.networkTopologyRecords.retained() {
+		s.networkTopologyCount = s.networkTopologyRecords.retained()
+	}
+
+	return nil
+}
+
+// evict evicts the oldest records in the files returned by backups until at most maxRecords
+// records are retained, then drops the oldest files whose records are all evicted. The files
+// are never rewritten, so that eviction costs no more than reading the unknown record counts.
+func (s *storage) evict(filename string, backups func() ([]fs.FileInfo, error), files *recordFiles) error {
+	if s.maxRecords <= 0 {
+		return nil
+	}
+
+	if retained := files.retained(); retained > s.maxRecords {
+		files.evicted += retained - s.maxRecords
+	}
+
+	if files.evicted == 0 {
+		return nil
+	}
+
+	fileInfos, err := backups()
+	if err != nil {
+		return err
+	}
+
+	for _, fileInfo := range fileInfos {
+		name := filepath.Join(s.baseDir, fileInfo.Name())
+		stats, err := s.statFile(files, name)
+		if err != nil {
+			return err
+		}
+
+		if stats.records > files.evicted {
+			return nil
+		}
+
+		if err := s.dropFile(filename, name, files); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dropFile drops the file of name and its records, the current file of filename is
+// truncated instead of being removed. The origin is advanced by the size of file and
+// saved before dropping, so that the origin never falls behind the files after crashes.
+func (s *storage) dropFile(filename, name string, files *recordFiles) error {
+	stats, err := s.statFile(files, name)
+	if err != nil {
+		return err
+	}
+
+	origin := files.origin
+	origin.Dropped += stats.size
+	if err := saveOrigin(files.originFilename, origin); err != nil {
+		return err
+	}
+	files.origin = origin
+
+	if name == filename {
+		err = os.Truncate(name, 0)
+	} else {
+		err = os.Remove(name)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.removeManifest(name); err != nil {
+		return err
+	}
+
+	files.remove(name, stats)
+	if name == filename {
+		files.stats[filename] = fileStats{}
+	}
+
+	return nil
+}
+
+// statFile returns the stats of file, it counts the stats by reading the file if they are unknown.
+func (s *storage) statFile(files *recordFiles, name string) (fileStats, error) {
+	if stats, ok := files.stats[name]; ok {
+		return stats, nil
+	}
+
+	file, err := os.Open(name)
+	if err != nil {
+		return fileStats{}, err
+	}
+	defer file.Close()
+
+	var size countWriter
+	records, err := copyLines(&size, s.newReader(file))
+	if err != nil {
+		return fileStats{}, err
+	}
+
+	stats := fileStats{records: records, size: int64(size)}
+	files.stats[name] = stats
+	return stats, nil
+}
+
+// countBackupRecords counts the records in the files returned by backups into files.
+func (s *storage) countBackupRecords(backups func() ([]fs.FileInfo, error), files *recordFiles) error {
+	fileInfos, err := backups()
+	if err != nil {
+		return err
+	}
+
+	for _, fileInfo := range fileInfos {
+		stats, err := s.statFile(files, filepath.Join(s.baseDir, fileInfo.Name()))
+		if err != nil {
+			return err
+		}
+
+		files.records += stats.records
+	}
+
+	return nil
+}
+
+// skipRecords discards the first n records of r, the rest of records are read from the returned
+// reader. It also returns the size of the discarded records.
+func skipRecords(r io.Reader, n int64) (io.Reader, int64, error) {
+	var size int64
+	reader := bufio.NewReader(r)
+	for n > 0 {
+		line, err := reader.ReadBytes('\n')
+		size += int64(len(line))
+		if len(bytes.TrimSpace(line)) > 0 {
+			n--
+		}
+
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, 0, err
+		}
+	}
+
+	return reader, size, nil
+}
+
+// countWriter counts the bytes written.
+type countWriter int64
+
+// Write counts the bytes of p.
+func (w *countWriter) Write(p []byte) (int, error) {
+	*w += countWriter(len(p))
+	return len(p), nil
+}
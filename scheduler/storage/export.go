@@ -0,0 +1,124 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/gocarina/gocsv"
+)
+
+// NetworkTopologyEdge is the edge from source host to destination host of network topology.
+type NetworkTopologyEdge struct {
+	// ID is network topology id.
+	ID string `json:"id"`
+
+	// SrcHostID is probe source host id.
+	SrcHostID string `json:"srcHostID"`
+
+	// SrcHostname is probe source hostname.
+	SrcHostname string `json:"srcHostname"`
+
+	// SrcIP is probe source host ip.
+	SrcIP string `json:"srcIP"`
+
+	// DestHostID is probe destination host id.
+	DestHostID string `json:"destHostID"`
+
+	// DestHostname is probe destination hostname.
+	DestHostname string `json:"destHostname"`
+
+	// DestIP is probe destination host ip.
+	DestIP string `json:"destIP"`
+
+	// AverageRTT is the average round-trip time of probes.
+	AverageRTT int64 `json:"averageRTT"`
+
+	// CreatedAt is probe create nanosecond time.
+	CreatedAt int64 `json:"createdAt"`
+
+	// UpdatedAt is probe update nanosecond time.
+	UpdatedAt int64 `json:"updatedAt"`
+}
+
+// exportNetworkTopologyJSON decodes the network topologies of csv format from r and writes
+// a JSON array of edges to w. Records are decoded and written one by one without buffering
+// the whole dataset.
+func exportNetworkTopologyJSON(r io.Reader, w io.Writer) error {
+	networkTopologies := make(chan NetworkTopology)
+	errCh := make(chan error, 1)
+	go func() {
+		// The channel is closed by gocsv after decoding.
+		errCh <- gocsv.UnmarshalToChanWithoutHeaders(r, networkTopologies)
+	}()
+
+	var (
+		writeErr error
+		first    = true
+	)
+	write := func(p []byte) {
+		if writeErr == nil {
+			_, writeErr = w.Write(p)
+		}
+	}
+
+	write([]byte("["))
+	for networkTopology := range networkTopologies {
+		// Keep draining the channel after failure, otherwise the decoder blocks.
+		if writeErr != nil {
+			continue
+		}
+
+		for _, destHost := range networkTopology.DestHosts {
+			// Destination hosts are padded to a fixed size in csv format.
+			if destHost.Host.ID == "" {
+				continue
+			}
+
+			data, err := json.Marshal(NetworkTopologyEdge{
+				ID:           networkTopology.ID,
+				SrcHostID:    networkTopology.Host.ID,
+				SrcHostname:  networkTopology.Host.Hostname,
+				SrcIP:        networkTopology.Host.IP,
+				DestHostID:   destHost.Host.ID,
+				DestHostname: destHost.Host.Hostname,
+				DestIP:       destHost.Host.IP,
+				AverageRTT:   destHost.Probes.AverageRTT,
+				CreatedAt:    destHost.Probes.CreatedAt,
+				UpdatedAt:    destHost.Probes.UpdatedAt,
+			})
+			if err != nil {
+				writeErr = err
+				break
+			}
+
+			if !first {
+				write([]byte(","))
+			}
+			write(data)
+			first = false
+		}
+	}
+	write([]byte("]"))
+
+	if err := <-errCh; err != nil {
+		return err
+	}
+
+	return writeErr
+}
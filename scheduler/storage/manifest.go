@@ -0,0 +1,196 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// ManifestDirName is the name of directory of checksum manifests in base directory.
+	ManifestDirName = "manifests"
+
+	// ManifestFileExt is extension of checksum manifest file name.
+	ManifestFileExt = "manifest"
+)
+
+// castagnoliTable is the crc32 table of segment checksum.
+var castagnoliTable = crc32.MakeTable(crc32.Castagnoli)
+
+// SegmentChecksum is the checksum of a segment appended to storage file, each write
+// of records into file is a segment.
+type SegmentChecksum struct {
+	// Offset is the offset of segment in file.
+	Offset int64 `json:"offset"`
+
+	// Length is the length of segment.
+	Length int64 `json:"length"`
+
+	// Checksum is the crc32 checksum of segment with castagnoli polynomial.
+	Checksum uint32 `json:"checksum"`
+}
+
+// CorruptSegment is the segment whose checksum does not match the manifest.
+type CorruptSegment struct {
+	// Filename is the name of storage file.
+	Filename string
+
+	// Offset is the offset of segment in file.
+	Offset int64
+
+	// Length is the length of segment.
+	Length int64
+}
+
+// CorruptionError describes the corrupt segments found by Verify.
+type CorruptionError struct {
+	// Segments are the corrupt segments.
+	Segments []CorruptSegment
+}
+
+// Error returns the description of corrupt segments.
+func (e *CorruptionError) Error() string {
+	segments := make([]string, 0, len(e.Segments))
+	for _, segment := range e.Segments {
+		segments = append(segments, fmt.Sprintf("%s[%d:%d]", segment.Filename, segment.Offset, segment.Offset+segment.Length))
+	}
+
+	return fmt.Sprintf("corrupt segments: %s", strings.Join(segments, ", "))
+}
+
+// manifestFilename returns the checksum manifest file name of storage file.
+func (s *storage) manifestFilename(filename string) string {
+	return filepath.Join(s.baseDir, ManifestDirName, fmt.Sprintf("%s.%s", filepath.Base(filename), ManifestFileExt))
+}
+
+// appendManifest appends the checksum of segment into the manifest of storage file.
+func (s *storage) appendManifest(filename string, offset int64, segment []byte) error {
+	data, err := json.Marshal(SegmentChecksum{
+		Offset:   offset,
+		Length:   int64(len(segment)),
+		Checksum: crc32.Checksum(segment, castagnoliTable),
+	})
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(s.manifestFilename(filename), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// renameManifest renames the manifest of storage file after the file is rotated.
+func (s *storage) renameManifest(oldFilename, newFilename string) error {
+	if err := os.Rename(s.manifestFilename(oldFilename), s.manifestFilename(newFilename)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// removeManifest removes the manifest of storage file after the file is removed or truncated.
+func (s *storage) removeManifest(filename string) error {
+	if err := os.Remove(s.manifestFilename(filename)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// verifyFile recomputes the checksums of segments in storage file and returns the corrupt segments,
+// the file without manifest is not verified.
+func (s *storage) verifyFile(filename string) ([]CorruptSegment, error) {
+	manifest, err := os.Open(s.manifestFilename(filename))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+	defer manifest.Close()
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var corruptSegments []CorruptSegment
+	scanner := bufio.NewScanner(manifest)
+	for scanner.Scan() {
+		var checksum SegmentChecksum
+		if err := json.Unmarshal(scanner.Bytes(), &checksum); err != nil {
+			return nil, fmt.Errorf("decode manifest of %s: %w", filepath.Base(filename), err)
+		}
+
+		segment := make([]byte, checksum.Length)
+		if _, err := file.ReadAt(segment, checksum.Offset); err != nil {
+			if !errors.Is(err, io.EOF) {
+				return nil, err
+			}
+
+			// The segment is truncated.
+			corruptSegments = append(corruptSegments, CorruptSegment{filepath.Base(filename), checksum.Offset, checksum.Length})
+			continue
+		}
+
+		if crc32.Checksum(segment, castagnoliTable) != checksum.Checksum {
+			corruptSegments = append(corruptSegments, CorruptSegment{filepath.Base(filename), checksum.Offset, checksum.Length})
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return corruptSegments, nil
+}
+
+// verify verifies the storage files returned by backups.
+func (s *storage) verify(backups func() ([]fs.FileInfo, error)) ([]CorruptSegment, error) {
+	fileInfos, err := backups()
+	if err != nil {
+		return nil, err
+	}
+
+	var corruptSegments []CorruptSegment
+	for _, fileInfo := range fileInfos {
+		segments, err := s.verifyFile(filepath.Join(s.baseDir, fileInfo.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		corruptSegments = append(corruptSegments, segments...)
+	}
+
+	return corruptSegments, nil
+}
@@ -0,0 +1,296 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/gocarina/gocsv"
+)
+
+// MemoryStorage is the in-memory Storage, the records are kept in csv format
+// as the file-backed storage does. It is intended for tests.
+type MemoryStorage struct {
+	downloadMu    sync.RWMutex
+	download      []byte
+	downloadCount int64
+
+	networkTopologyMu    sync.RWMutex
+	networkTopology      []byte
+	networkTopologyCount int64
+}
+
+// NewMemory returns a new empty MemoryStorage instance.
+func NewMemory() *MemoryStorage {
+	return &MemoryStorage{}
+}
+
+// SetDownload replaces the downloads with the csv data, each line is counted as a download.
+func (m *MemoryStorage) SetDownload(data []byte) {
+	m.downloadMu.Lock()
+	defer m.downloadMu.Unlock()
+
+	m.download = append([]byte(nil), data...)
+	m.downloadCount = countLines(data)
+}
+
+// SetNetworkTopology replaces the network topologies with the csv data, each line is counted as a network topology.
+func (m *MemoryStorage) SetNetworkTopology(data []byte) {
+	m.networkTopologyMu.Lock()
+	defer m.networkTopologyMu.Unlock()
+
+	m.networkTopology = append([]byte(nil), data...)
+	m.networkTopologyCount = countLines(data)
+}
+
+// CreateDownload inserts the download into memory.
+func (m *MemoryStorage) CreateDownload(download Download) error {
+	m.downloadMu.Lock()
+	defer m.downloadMu.Unlock()
+
+	var buf bytes.Buffer
+	if err := gocsv.MarshalWithoutHeaders([]Download{download}, &buf); err != nil {
+		return err
+	}
+
+	m.download = append(m.download, buf.Bytes()...)
+	m.downloadCount++
+	return nil
+}
+
+// CreateNetworkTopology inserts the network topology into memory.
+func (m *MemoryStorage) CreateNetworkTopology(networkTopology NetworkTopology) error {
+	m.networkTopologyMu.Lock()
+	defer m.networkTopologyMu.Unlock()
+
+	var buf bytes.Buffer
+	if err := gocsv.MarshalWithoutHeaders([]NetworkTopology{networkTopology}, &buf); err != nil {
+		return err
+	}
+
+	m.networkTopology = append(m.networkTopology, buf.Bytes()...)
+	m.networkTopologyCount++
+	return nil
+}
+
+// ListDownload returns all downloads in memory.
+func (m *MemoryStorage) ListDownload() ([]Download, error) {
+	m.downloadMu.RLock()
+	defer m.downloadMu.RUnlock()
+
+	var downloads []Download
+	if err := gocsv.UnmarshalWithoutHeaders(bytes.NewReader(m.download), &downloads); err != nil {
+		return nil, err
+	}
+
+	return downloads, nil
+}
+
+// ListDownloadByTaskID returns the downloads of the task in memory.
+func (m *MemoryStorage) ListDownloadByTaskID(taskID string) ([]Download, error) {
+	downloads, err := m.ListDownload()
+	if err != nil {
+		if errors.Is(err, gocsv.ErrEmptyCSVFile) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return filterDownloadsByTaskID(downloads, taskID), nil
+}
+
+// ListNetworkTopology returns all network topologies in memory.
+func (m *MemoryStorage) ListNetworkTopology() ([]NetworkTopology, error) {
+	m.networkTopologyMu.RLock()
+	defer m.networkTopologyMu.RUnlock()
+
+	var networkTopologies []NetworkTopology
+	if err := gocsv.UnmarshalWithoutHeaders(bytes.NewReader(m.networkTopology), &networkTopologies); err != nil {
+		return nil, err
+	}
+
+	return networkTopologies, nil
+}
+
+// DownloadCount returns the count of downloads.
+func (m *MemoryStorage) DownloadCount() int64 {
+	m.downloadMu.RLock()
+	defer m.downloadMu.RUnlock()
+
+	return m.downloadCount
+}
+
+// NetworkTopologyCount returns the count of network topologies.
+func (m *MemoryStorage) NetworkTopologyCount() int64 {
+	m.networkTopologyMu.RLock()
+	defer m.networkTopologyMu.RUnlock()
+
+	return m.networkTopologyCount
+}
+
+// DownloadSize returns the size in bytes of downloads.
+func (m *MemoryStorage) DownloadSize() int64 {
+	m.downloadMu.RLock()
+	defer m.downloadMu.RUnlock()
+
+	return int64(len(m.download))
+}
+
+// NetworkTopologySize returns the size in bytes of network topologies.
+func (m *MemoryStorage) NetworkTopologySize() int64 {
+	m.networkTopologyMu.RLock()
+	defer m.networkTopologyMu.RUnlock()
+
+	return int64(len(m.networkTopology))
+}
+
+// OpenDownload returns io.ReadCloser of the snapshot of downloads.
+func (m *MemoryStorage) OpenDownload() (io.ReadCloser, error) {
+	m.downloadMu.RLock()
+	defer m.downloadMu.RUnlock()
+
+	return io.NopCloser(bytes.NewReader(append([]byte(nil), m.download...))), nil
+}
+
+// OpenDownloadReverse returns io.ReadCloser of the snapshot of downloads from newest to oldest.
+func (m *MemoryStorage) OpenDownloadReverse() (io.ReadCloser, error) {
+	m.downloadMu.RLock()
+	defer m.downloadMu.RUnlock()
+
+	var buf bytes.Buffer
+	lines := bytes.SplitAfter(m.download, []byte("\n"))
+	for i := len(lines) - 1; i >= 0; i-- {
+		if len(lines[i]) == 0 {
+			continue
+		}
+
+		buf.Write(lines[i])
+		if lines[i][len(lines[i])-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+	}
+
+	return io.NopCloser(&buf), nil
+}
+
+// OpenNetworkTopology returns io.ReadCloser of the snapshot of network topologies.
+func (m *MemoryStorage) OpenNetworkTopology() (io.ReadCloser, error) {
+	m.networkTopologyMu.RLock()
+	defer m.networkTopologyMu.RUnlock()
+
+	return io.NopCloser(bytes.NewReader(append([]byte(nil), m.networkTopology...))), nil
+}
+
+// ExportNetworkTopologyJSON writes the network topologies as a JSON array of edges.
+func (m *MemoryStorage) ExportNetworkTopologyJSON(w io.Writer) error {
+	m.networkTopologyMu.RLock()
+	defer m.networkTopologyMu.RUnlock()
+
+	return exportNetworkTopologyJSON(bytes.NewReader(m.networkTopology), w)
+}
+
+// ExportArchive writes the downloads and network topologies into a tar archive with manifest.
+func (m *MemoryStorage) ExportArchive(w io.Writer, options ...ArchiveOption) error {
+	download, err := m.OpenDownload()
+	if err != nil {
+		return err
+	}
+
+	networkTopology, err := m.OpenNetworkTopology()
+	if err != nil {
+		return err
+	}
+
+	return writeArchive(w, download, networkTopology, options...)
+}
+
+// ImportArchive appends the downloads and network topologies of the archive written by ExportArchive.
+func (m *MemoryStorage) ImportArchive(r io.Reader) error {
+	return readArchive(r, func(r io.Reader) (int64, error) {
+		m.downloadMu.Lock()
+		defer m.downloadMu.Unlock()
+
+		var buf bytes.Buffer
+		count, err := copyLines(&buf, r)
+		if err != nil {
+			return 0, err
+		}
+
+		m.download = append(m.download, buf.Bytes()...)
+		m.downloadCount += count
+		return count, nil
+	}, func(r io.Reader) (int64, error) {
+		m.networkTopologyMu.Lock()
+		defer m.networkTopologyMu.Unlock()
+
+		var buf bytes.Buffer
+		count, err := copyLines(&buf, r)
+		if err != nil {
+			return 0, err
+		}
+
+		m.networkTopology = append(m.networkTopology, buf.Bytes()...)
+		m.networkTopologyCount += count
+		return count, nil
+	})
+}
+
+// ClearDownload removes all downloads.
+func (m *MemoryStorage) ClearDownload() error {
+	m.downloadMu.Lock()
+	defer m.downloadMu.Unlock()
+
+	m.download = nil
+	m.downloadCount = 0
+	return nil
+}
+
+// ClearNetworkTopology removes all network topologies.
+func (m *MemoryStorage) ClearNetworkTopology() error {
+	m.networkTopologyMu.Lock()
+	defer m.networkTopologyMu.Unlock()
+
+	m.networkTopology = nil
+	m.networkTopologyCount = 0
+	return nil
+}
+
+// Verify does nothing, the records in memory are not verified.
+func (m *MemoryStorage) Verify() error {
+	return nil
+}
+
+// Sync does nothing, the records are never buffered.
+func (m *MemoryStorage) Sync() error {
+	return nil
+}
+
+// countLines returns the number of non-empty lines of data.
+func countLines(data []byte) int64 {
+	var count int64
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) > 0 {
+			count++
+		}
+	}
+
+	return count
+}
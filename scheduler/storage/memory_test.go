@@ -0,0 +1,106 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStorage_Download(t *testing.T) {
+	assert := assert.New(t)
+	var s Storage = NewMemory()
+
+	for i := 0; i < 2; i++ {
+		assert.NoError(s.CreateDownload(mockDownload))
+	}
+	assert.NoError(s.Sync())
+	assert.Equal(int64(2), s.DownloadCount())
+
+	downloads, err := s.ListDownload()
+	assert.NoError(err)
+	assert.Len(downloads, 2)
+	assert.EqualValues(mockDownload.ID, downloads[1].ID)
+
+	downloads, err = s.ListDownloadByTaskID(mockDownload.Task.ID)
+	assert.NoError(err)
+	assert.Len(downloads, 2)
+	downloads, err = s.ListDownloadByTaskID("foo")
+	assert.NoError(err)
+	assert.Empty(downloads)
+
+	readCloser, err := s.OpenDownload()
+	assert.NoError(err)
+	data, err := io.ReadAll(readCloser)
+	assert.NoError(err)
+	assert.NoError(readCloser.Close())
+
+	// Records are seeded as csv data.
+	m := NewMemory()
+	m.SetDownload(data)
+	assert.Equal(int64(2), m.DownloadCount())
+	downloads, err = m.ListDownload()
+	assert.NoError(err)
+	assert.Len(downloads, 2)
+
+	// Records are read from newest to oldest.
+	m.SetDownload([]byte("1\n2\n3"))
+	readCloser, err = m.OpenDownloadReverse()
+	assert.NoError(err)
+	data, err = io.ReadAll(readCloser)
+	assert.NoError(err)
+	assert.Equal("3\n2\n1\n", string(data))
+
+	assert.NoError(m.ClearDownload())
+	assert.Equal(int64(0), m.DownloadCount())
+	readCloser, err = m.OpenDownload()
+	assert.NoError(err)
+	data, err = io.ReadAll(readCloser)
+	assert.NoError(err)
+	assert.Empty(data)
+}
+
+func TestMemoryStorage_NetworkTopology(t *testing.T) {
+	assert := assert.New(t)
+	var s Storage = NewMemory()
+
+	assert.NoError(s.CreateNetworkTopology(mockNetworkTopology))
+	assert.Equal(int64(1), s.NetworkTopologyCount())
+
+	networkTopologies, err := s.ListNetworkTopology()
+	assert.NoError(err)
+	assert.Len(networkTopologies, 1)
+	assert.EqualValues(mockNetworkTopology.ID, networkTopologies[0].ID)
+
+	// Readers opened before new records are inserted read the snapshot.
+	readCloser, err := s.OpenNetworkTopology()
+	assert.NoError(err)
+	assert.NoError(s.CreateNetworkTopology(mockNetworkTopology))
+	data, err := io.ReadAll(readCloser)
+	assert.NoError(err)
+
+	m := NewMemory()
+	m.SetNetworkTopology(data)
+	assert.Equal(int64(1), m.NetworkTopologyCount())
+
+	assert.NoError(s.ClearNetworkTopology())
+	assert.Equal(int64(0), s.NetworkTopologyCount())
+	_, err = s.ListNetworkTopology()
+	assert.Error(err)
+}
@@ -105,6 +105,67 @@ func (mr *MockStorageMockRecorder) DownloadCount() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadCount", reflect.TypeOf((*MockStorage)(nil).DownloadCount))
 }
 
+// DownloadSize mocks base method.
+func (m *MockStorage) DownloadSize() int64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DownloadSize")
+	ret0, _ := ret[0].(int64)
+	return ret0
+}
+
+// DownloadSize indicates an expected call of DownloadSize.
+func (mr *MockStorageMockRecorder) DownloadSize() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadSize", reflect.TypeOf((*MockStorage)(nil).DownloadSize))
+}
+
+// ExportArchive mocks base method.
+func (m *MockStorage) ExportArchive(w io.Writer, options ...storage.ArchiveOption) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{w}
+	for _, a := range options {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ExportArchive", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportArchive indicates an expected call of ExportArchive.
+func (mr *MockStorageMockRecorder) ExportArchive(w interface{}, options ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{w}, options...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportArchive", reflect.TypeOf((*MockStorage)(nil).ExportArchive), varargs...)
+}
+
+// ExportNetworkTopologyJSON mocks base method.
+func (m *MockStorage) ExportNetworkTopologyJSON(w io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportNetworkTopologyJSON", w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportNetworkTopologyJSON indicates an expected call of ExportNetworkTopologyJSON.
+func (mr *MockStorageMockRecorder) ExportNetworkTopologyJSON(w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportNetworkTopologyJSON", reflect.TypeOf((*MockStorage)(nil).ExportNetworkTopologyJSON), w)
+}
+
+// ImportArchive mocks base method.
+func (m *MockStorage) ImportArchive(r io.Reader) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportArchive", r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ImportArchive indicates an expected call of ImportArchive.
+func (mr *MockStorageMockRecorder) ImportArchive(r interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportArchive", reflect.TypeOf((*MockStorage)(nil).ImportArchive), r)
+}
+
 // ListDownload mocks base method.
 func (m *MockStorage) ListDownload() ([]storage.Download, error) {
 	m.ctrl.T.Helper()
@@ -120,6 +181,21 @@ func (mr *MockStorageMockRecorder) ListDownload() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDownload", reflect.TypeOf((*MockStorage)(nil).ListDownload))
 }
 
+// ListDownloadByTaskID mocks base method.
+func (m *MockStorage) ListDownloadByTaskID(taskID string) ([]storage.Download, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDownloadByTaskID", taskID)
+	ret0, _ := ret[0].([]storage.Download)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDownloadByTaskID indicates an expected call of ListDownloadByTaskID.
+func (mr *MockStorageMockRecorder) ListDownloadByTaskID(taskID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDownloadByTaskID", reflect.TypeOf((*MockStorage)(nil).ListDownloadByTaskID), taskID)
+}
+
 // ListNetworkTopology mocks base method.
 func (m *MockStorage) ListNetworkTopology() ([]storage.NetworkTopology, error) {
 	m.ctrl.T.Helper()
@@ -149,6 +225,20 @@ func (mr *MockStorageMockRecorder) NetworkTopologyCount() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NetworkTopologyCount", reflect.TypeOf((*MockStorage)(nil).NetworkTopologyCount))
 }
 
+// NetworkTopologySize mocks base method.
+func (m *MockStorage) NetworkTopologySize() int64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NetworkTopologySize")
+	ret0, _ := ret[0].(int64)
+	return ret0
+}
+
+// NetworkTopologySize indicates an expected call of NetworkTopologySize.
+func (mr *MockStorageMockRecorder) NetworkTopologySize() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NetworkTopologySize", reflect.TypeOf((*MockStorage)(nil).NetworkTopologySize))
+}
+
 // OpenDownload mocks base method.
 func (m *MockStorage) OpenDownload() (io.ReadCloser, error) {
 	m.ctrl.T.Helper()
@@ -164,6 +254,21 @@ func (mr *MockStorageMockRecorder) OpenDownload() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenDownload", reflect.TypeOf((*MockStorage)(nil).OpenDownload))
 }
 
+// OpenDownloadReverse mocks base method.
+func (m *MockStorage) OpenDownloadReverse() (io.ReadCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenDownloadReverse")
+	ret0, _ := ret[0].(io.ReadCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenDownloadReverse indicates an expected call of OpenDownloadReverse.
+func (mr *MockStorageMockRecorder) OpenDownloadReverse() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenDownloadReverse", reflect.TypeOf((*MockStorage)(nil).OpenDownloadReverse))
+}
+
 // OpenNetworkTopology mocks base method.
 func (m *MockStorage) OpenNetworkTopology() (io.ReadCloser, error) {
 	m.ctrl.T.Helper()
@@ -178,3 +283,31 @@ func (mr *MockStorageMockRecorder) OpenNetworkTopology() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenNetworkTopology", reflect.TypeOf((*MockStorage)(nil).OpenNetworkTopology))
 }
+
+// Sync mocks base method.
+func (m *MockStorage) Sync() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Sync")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Sync indicates an expected call of Sync.
+func (mr *MockStorageMockRecorder) Sync() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Sync", reflect.TypeOf((*MockStorage)(nil).Sync))
+}
+
+// Verify mocks base method.
+func (m *MockStorage) Verify() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockStorageMockRecorder) Verify() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockStorage)(nil).Verify))
+}
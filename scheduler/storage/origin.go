@@ -0,0 +1,190 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+
+	pkgio "d7y.io/dragonfly/v2/pkg/io"
+)
+
+const (
+	// OriginFileExt is extension of the origin file name in the directory of manifests.
+	OriginFileExt = "origin"
+)
+
+// Origin is the position of the first record read from storage in the history of dataset,
+// the history of dataset is all the records written into storage in order, including the
+// records evicted or dropped with rotated files.
+type Origin struct {
+	// Epoch identifies the history of dataset, it changes when the history is lost, e.g. the
+	// dataset is cleared or the origin file is lost. The offsets of different epochs are not comparable.
+	Epoch string
+
+	// Offset is the size of the records before the first record in the history.
+	Offset int64
+}
+
+// OriginStorage is implemented by the storage locating the records in the history of dataset,
+// so that an offset in the history saved before the records are evicted, rotated or cleared
+// still locates the same record.
+type OriginStorage interface {
+	// DownloadEpoch returns the epoch of the history of downloads.
+	DownloadEpoch() string
+
+	// NetworkTopologyEpoch returns the epoch of the history of network topologies.
+	NetworkTopologyEpoch() string
+
+	// OpenDownloadWithOrigin opens download files for read like OpenDownload, it also returns the origin of downloads.
+	OpenDownloadWithOrigin() (io.ReadCloser, Origin, error)
+
+	// OpenNetworkTopologyWithOrigin opens network topology files for read like OpenNetworkTopology, it also
+	// returns the origin of network topologies.
+	OpenNetworkTopologyWithOrigin() (io.ReadCloser, Origin, error)
+}
+
+var _ OriginStorage = (*storage)(nil)
+
+// origin is the persisted origin of dataset, the offset of origin is the size of dropped files
+// plus the size of evicted records in the oldest file.
+type origin struct {
+	// Epoch is the epoch of the history of dataset.
+	Epoch string `json:"epoch"`
+
+	// Dropped is the size of files dropped from the history of dataset.
+	Dropped int64 `json:"dropped"`
+}
+
+// newOrigin returns the origin of a new history.
+func newOrigin() origin {
+	return origin{Epoch: uuid.NewString()}
+}
+
+// loadOrigin reads the origin from file, it starts a new history if the file does not exist.
+func loadOrigin(filename string) (origin, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			o := newOrigin()
+			return o, saveOrigin(filename, o)
+		}
+
+		return origin{}, err
+	}
+
+	var o origin
+	if err := json.Unmarshal(data, &o); err != nil || o.Epoch == "" || o.Dropped < 0 {
+		o = newOrigin()
+		return o, saveOrigin(filename, o)
+	}
+
+	return o, nil
+}
+
+// saveOrigin writes the origin to file atomically.
+func saveOrigin(filename string, o origin) error {
+	data, err := json.Marshal(o)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filename)
+}
+
+// originFilename returns the origin file name of dataset with the prefix.
+func (s *storage) originFilename(prefix string) string {
+	return filepath.Join(s.baseDir, ManifestDirName, fmt.Sprintf("%s.%s", prefix, OriginFileExt))
+}
+
+// DownloadEpoch returns the epoch of the history of downloads.
+func (s *storage) DownloadEpoch() string {
+	s.downloadMu.RLock()
+	defer s.downloadMu.RUnlock()
+
+	return s.downloadRecords.origin.Epoch
+}
+
+// NetworkTopologyEpoch returns the epoch of the history of network topologies.
+func (s *storage) NetworkTopologyEpoch() string {
+	s.networkTopologyMu.RLock()
+	defer s.networkTopologyMu.RUnlock()
+
+	return s.networkTopologyRecords.origin.Epoch
+}
+
+// OpenDownloadWithOrigin opens download files for read, it also returns the origin of downloads.
+func (s *storage) OpenDownloadWithOrigin() (io.ReadCloser, Origin, error) {
+	s.downloadMu.RLock()
+	defer s.downloadMu.RUnlock()
+
+	return s.openWithOrigin(s.downloadBackups, s.downloadRecords)
+}
+
+// OpenNetworkTopologyWithOrigin opens network topology files for read, it also returns the origin of network topologies.
+func (s *storage) OpenNetworkTopologyWithOrigin() (io.ReadCloser, Origin, error) {
+	s.networkTopologyMu.RLock()
+	defer s.networkTopologyMu.RUnlock()
+
+	return s.openWithOrigin(s.networkTopologyBackups, s.networkTopologyRecords)
+}
+
+// openWithOrigin opens the files returned by backups for read, the origin is located after
+// the evicted records of the oldest file.
+func (s *storage) openWithOrigin(backups func() ([]fs.FileInfo, error), files *recordFiles) (io.ReadCloser, Origin, error) {
+	fileInfos, err := backups()
+	if err != nil {
+		return nil, Origin{}, err
+	}
+
+	readClosers, evictedSize, err := s.openFiles(fileInfos, files.evicted)
+	if err != nil {
+		return nil, Origin{}, err
+	}
+
+	return pkgio.MultiReadCloser(readClosers...), Origin{
+		Epoch:  files.origin.Epoch,
+		Offset: files.origin.Dropped + evictedSize,
+	}, nil
+}
@@ -0,0 +1,160 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/scheduler/config"
+)
+
+func TestStorage_OpenDownloadWithOrigin(t *testing.T) {
+	assert := assert.New(t)
+	baseDir := t.TempDir()
+
+	// open returns the ids of downloads and the origin.
+	open := func(s Storage) ([]string, Origin) {
+		readCloser, origin, err := s.(OriginStorage).OpenDownloadWithOrigin()
+		assert.NoError(err)
+		defer readCloser.Close()
+
+		data, err := io.ReadAll(readCloser)
+		assert.NoError(err)
+
+		var ids []string
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			if line != "" {
+				ids = append(ids, strings.SplitN(line, ",", 2)[0])
+			}
+		}
+		return ids, origin
+	}
+
+	create := func(s Storage, ids ...int) {
+		download := mockDownload
+		for _, id := range ids {
+			download.ID = fmt.Sprint(id)
+			assert.NoError(s.CreateDownload(download))
+		}
+	}
+
+	s, err := New(baseDir, config.DefaultStorageMaxSize, config.DefaultStorageMaxBackups, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	create(s, 0, 1)
+	ids, origin := open(s)
+	assert.Equal([]string{"0", "1"}, ids)
+	assert.NotEmpty(origin.Epoch)
+	assert.Equal(int64(0), origin.Offset)
+	epoch := origin.Epoch
+	size := s.DownloadSize()
+
+	// The current file is rotated instead of truncated after restarting.
+	s, err = New(baseDir, config.DefaultStorageMaxSize, config.DefaultStorageMaxBackups, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	create(s, 2)
+	ids, origin = open(s)
+	assert.Equal([]string{"0", "1", "2"}, ids)
+	assert.Equal(Origin{Epoch: epoch}, origin)
+
+	// The evicted downloads advance the origin.
+	s, err = New(baseDir, config.DefaultStorageMaxSize, config.DefaultStorageMaxBackups, 0, WithMaxRecords(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids, origin = open(s)
+	assert.Equal([]string{"1", "2"}, ids)
+	assert.Equal(Origin{Epoch: epoch, Offset: size / 2}, origin)
+
+	// The dropped files advance the origin, and the origin is kept after restarting.
+	create(s, 3, 4)
+	ids, origin = open(s)
+	assert.Equal([]string{"3", "4"}, ids)
+	assert.Equal(Origin{Epoch: epoch, Offset: 3 * size / 2}, origin)
+
+	s, err = New(baseDir, config.DefaultStorageMaxSize, config.DefaultStorageMaxBackups, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids, origin = open(s)
+	assert.Equal([]string{"3", "4"}, ids)
+	assert.Equal(Origin{Epoch: epoch, Offset: 3 * size / 2}, origin)
+
+	// Clearing starts a new history.
+	assert.NoError(s.ClearDownload())
+	assert.NotEqual(epoch, s.(OriginStorage).DownloadEpoch())
+	assert.NoError(os.WriteFile(filepath.Join(baseDir, "download.csv"), nil, 0600))
+	create(s, 5)
+	ids, origin = open(s)
+	assert.Equal([]string{"5"}, ids)
+	assert.Equal(Origin{Epoch: s.(OriginStorage).DownloadEpoch()}, origin)
+}
+
+func TestStorage_OriginFileLost(t *testing.T) {
+	assert := assert.New(t)
+	baseDir := t.TempDir()
+	s, err := New(baseDir, config.DefaultStorageMaxSize, config.DefaultStorageMaxBackups, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	epoch := s.(OriginStorage).DownloadEpoch()
+
+	tests := []struct {
+		name string
+		mock func(filename string)
+	}{
+		{
+			name: "origin file is removed",
+			mock: func(filename string) {
+				assert.NoError(os.Remove(filename))
+			},
+		},
+		{
+			name: "origin file is corrupt",
+			mock: func(filename string) {
+				assert.NoError(os.WriteFile(filename, []byte("foo"), 0600))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.mock(s.(*storage).originFilename(DownloadFilePrefix))
+			s, err = New(baseDir, config.DefaultStorageMaxSize, config.DefaultStorageMaxBackups, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.NotEqual(epoch, s.(OriginStorage).DownloadEpoch())
+			epoch = s.(OriginStorage).DownloadEpoch()
+		})
+	}
+}
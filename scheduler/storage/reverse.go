@@ -0,0 +1,183 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"os"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+// reverseFile is the file of records read in reverse, temporary files are removed after reading.
+type reverseFile struct {
+	file      *os.File
+	temporary bool
+
+	// skip is the number of the first records of file not read.
+	skip int64
+}
+
+// close closes the file and removes it if it is temporary.
+func (f reverseFile) close() error {
+	err := f.file.Close()
+	if f.temporary {
+		if removeErr := os.Remove(f.file.Name()); err == nil {
+			err = removeErr
+		}
+	}
+
+	return err
+}
+
+// reverseReadCloser reads the records of files from newest to oldest. The files are
+// read one at a time, the offsets of records in the current file are indexed by
+// scanning it once, then each record is read by its offset, so that only the index
+// of the current file is kept in memory instead of the records.
+type reverseReadCloser struct {
+	// files are the files not read yet, from newest to oldest.
+	files []reverseFile
+
+	// current is the file being read, offsets are the start offsets of its records
+	// followed by the end offset of file, index is the count of records not read yet.
+	current *reverseFile
+	offsets []int64
+	index   int
+
+	buf []byte
+}
+
+// newReverseReadCloser returns a new reverseReadCloser of files ordered from newest to oldest.
+func newReverseReadCloser(files []reverseFile) *reverseReadCloser {
+	return &reverseReadCloser{files: files}
+}
+
+// Read reads the records in reverse order.
+func (r *reverseReadCloser) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.index == 0 {
+			if len(r.files) == 0 {
+				return 0, io.EOF
+			}
+
+			if err := r.next(); err != nil {
+				return 0, err
+			}
+
+			continue
+		}
+
+		r.index--
+		start, end := r.offsets[r.index], r.offsets[r.index+1]
+		r.buf = make([]byte, end-start, end-start+1)
+		if _, err := r.current.file.ReadAt(r.buf, start); err != nil {
+			r.buf = nil
+			return 0, err
+		}
+
+		// The last record of file may not be terminated.
+		if r.buf[len(r.buf)-1] != '\n' {
+			r.buf = append(r.buf, '\n')
+		}
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// next closes the current file, and indexes the offsets of records in the next file.
+func (r *reverseReadCloser) next() error {
+	if r.current != nil {
+		current := r.current
+		r.current = nil
+		if err := current.close(); err != nil {
+			return err
+		}
+	}
+
+	file := r.files[0]
+	r.files = r.files[1:]
+	r.current = &file
+
+	offsets, err := indexLines(file.file)
+	if err != nil {
+		return err
+	}
+
+	// The offsets of the skipped records are dropped, the end offset of file is kept.
+	skip := int(file.skip)
+	if skip > len(offsets)-1 {
+		skip = len(offsets) - 1
+	}
+
+	r.offsets = offsets[skip:]
+	r.index = len(r.offsets) - 1
+	return nil
+}
+
+// Close closes all files not read yet.
+func (r *reverseReadCloser) Close() error {
+	var merr error
+	if r.current != nil {
+		if err := r.current.close(); err != nil {
+			merr = multierror.Append(merr, err)
+		}
+		r.current = nil
+	}
+
+	for _, file := range r.files {
+		if err := file.close(); err != nil {
+			merr = multierror.Append(merr, err)
+		}
+	}
+	r.files = nil
+
+	return merr
+}
+
+// indexLines returns the start offsets of lines in file followed by the end offset of file.
+func indexLines(file *os.File) ([]int64, error) {
+	var (
+		offsets   []int64
+		offset    int64
+		lineStart = true
+	)
+
+	reader := bufio.NewReader(io.NewSectionReader(file, 0, math.MaxInt64))
+	for {
+		line, err := reader.ReadSlice('\n')
+		if len(line) > 0 && lineStart {
+			offsets = append(offsets, offset)
+		}
+		offset += int64(len(line))
+
+		switch err {
+		case nil:
+			lineStart = true
+		case bufio.ErrBufferFull:
+			// The line is longer than the buffer, the rest of line is read next.
+			lineStart = false
+		case io.EOF:
+			return append(offsets, offset), nil
+		default:
+			return nil, err
+		}
+	}
+}
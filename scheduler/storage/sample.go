@@ -0,0 +1,141 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+)
+
+const (
+	// smallTaskContentLength is the max content length of the small tasks in the strata of sampling.
+	smallTaskContentLength = 1 << 20
+
+	// mediumTaskContentLength is the max content length of the medium tasks in the strata of sampling.
+	mediumTaskContentLength = 128 << 20
+)
+
+// Sampler decides whether the download is written into storage, so that the records written
+// from busy clusters do not overwhelm the trainer.
+type Sampler interface {
+	// Sample returns whether the download is kept.
+	Sample(download Download) bool
+}
+
+// rateSampler keeps each download independently with the probability of rate.
+type rateSampler struct {
+	rate float64
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// NewRateSampler returns a sampler keeping each download with the probability of rate in (0, 1].
+func NewRateSampler(rate float64) (Sampler, error) {
+	if rate <= 0 || rate > 1 {
+		return nil, fmt.Errorf("invalid sample rate %f", rate)
+	}
+
+	return &rateSampler{
+		rate: rate,
+		rand: rand.New(rand.NewSource(rand.Int63())),
+	}, nil
+}
+
+// Sample returns whether the download is kept.
+func (r *rateSampler) Sample(download Download) bool {
+	if r.rate >= 1 {
+		return true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rand.Float64() < r.rate
+}
+
+// stratum is the stratum of download by the size of task and the result of download.
+type stratum struct {
+	size  string
+	state string
+}
+
+// stratifiedSampler keeps the rate of downloads in each stratum systematically, so that the
+// proportion of strata is preserved and the rare strata, e.g. the failed downloads of large
+// tasks, are kept even if the downloads are few.
+type stratifiedSampler struct {
+	rate float64
+
+	mu sync.Mutex
+	// credits is the accumulated rate of each stratum, a download is kept
+	// when the credit of its stratum reaches one.
+	credits map[stratum]float64
+}
+
+// NewStratifiedSampler returns a sampler keeping the rate in (0, 1] of downloads in each stratum
+// of task size and download state, the first download of each stratum is always kept.
+func NewStratifiedSampler(rate float64) (Sampler, error) {
+	if rate <= 0 || rate > 1 {
+		return nil, fmt.Errorf("invalid sample rate %f", rate)
+	}
+
+	return &stratifiedSampler{
+		rate:    rate,
+		credits: make(map[stratum]float64),
+	}, nil
+}
+
+// Sample returns whether the download is kept.
+func (s *stratifiedSampler) Sample(download Download) bool {
+	if s.rate >= 1 {
+		return true
+	}
+
+	key := stratum{size: taskSizeClass(download.Task.ContentLength), state: download.State}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	credit, ok := s.credits[key]
+	if !ok {
+		credit = 1
+	} else {
+		credit += s.rate
+	}
+
+	if credit >= 1 {
+		s.credits[key] = credit - 1
+		return true
+	}
+
+	s.credits[key] = credit
+	return false
+}
+
+// taskSizeClass returns the size class of task by content length.
+func taskSizeClass(contentLength int64) string {
+	switch {
+	case contentLength < 0:
+		return "unknown"
+	case contentLength <= smallTaskContentLength:
+		return "small"
+	case contentLength <= mediumTaskContentLength:
+		return "medium"
+	default:
+		return "large"
+	}
+}
@@ -0,0 +1,187 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"d7y.io/dragonfly/v2/scheduler/config"
+)
+
+func TestRateSampler(t *testing.T) {
+	tests := []struct {
+		name   string
+		rate   float64
+		expect func(t *testing.T, sampled int, err error)
+	}{
+		{
+			name: "keep all downloads",
+			rate: 1,
+			expect: func(t *testing.T, sampled int, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal(10000, sampled)
+			},
+		},
+		{
+			name: "keep downloads by rate",
+			rate: 0.1,
+			expect: func(t *testing.T, sampled int, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.InDelta(1000, sampled, 200)
+			},
+		},
+		{
+			name: "invalid sample rate",
+			rate: 0,
+			expect: func(t *testing.T, sampled int, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "invalid sample rate 0.000000")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			sampler, err := NewRateSampler(tc.rate)
+			if err != nil {
+				tc.expect(t, 0, err)
+				return
+			}
+
+			var sampled int
+			for i := 0; i < 10000; i++ {
+				if sampler.Sample(mockDownload) {
+					sampled++
+				}
+			}
+			tc.expect(t, sampled, nil)
+		})
+	}
+}
+
+func TestStratifiedSampler(t *testing.T) {
+	newDownload := func(contentLength int64, state string) Download {
+		download := mockDownload
+		download.Task.ContentLength = contentLength
+		download.State = state
+		return download
+	}
+
+	tests := []struct {
+		name      string
+		rate      float64
+		downloads []Download
+		expect    func(t *testing.T, sampled []bool, err error)
+	}{
+		{
+			name: "keep downloads by rate in stratum",
+			rate: 0.25,
+			downloads: []Download{
+				newDownload(1024, "Succeeded"), newDownload(1024, "Succeeded"), newDownload(1024, "Succeeded"),
+				newDownload(1024, "Succeeded"), newDownload(1024, "Succeeded"),
+			},
+			expect: func(t *testing.T, sampled []bool, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal([]bool{true, false, false, false, true}, sampled)
+			},
+		},
+		{
+			name: "keep the first download of each stratum",
+			rate: 0.25,
+			downloads: []Download{
+				newDownload(1024, "Succeeded"), newDownload(1024, "Failed"), newDownload(1<<30, "Succeeded"),
+				newDownload(1<<30, "Failed"), newDownload(1024, "Succeeded"), newDownload(1<<30, "Failed"),
+			},
+			expect: func(t *testing.T, sampled []bool, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal([]bool{true, true, true, true, false, false}, sampled)
+			},
+		},
+		{
+			name:      "keep all downloads",
+			rate:      1,
+			downloads: []Download{mockDownload, mockDownload, mockDownload},
+			expect: func(t *testing.T, sampled []bool, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Equal([]bool{true, true, true}, sampled)
+			},
+		},
+		{
+			name: "invalid sample rate",
+			rate: 1.5,
+			expect: func(t *testing.T, sampled []bool, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "invalid sample rate 1.500000")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			sampler, err := NewStratifiedSampler(tc.rate)
+			if err != nil {
+				tc.expect(t, nil, err)
+				return
+			}
+
+			var sampled []bool
+			for _, download := range tc.downloads {
+				sampled = append(sampled, sampler.Sample(download))
+			}
+			tc.expect(t, sampled, nil)
+		})
+	}
+}
+
+func TestStorage_Sampler(t *testing.T) {
+	assert := assert.New(t)
+	sampler, err := NewStratifiedSampler(0.5)
+	assert.NoError(err)
+
+	s, err := New(t.TempDir(), config.DefaultStorageMaxSize, config.DefaultStorageMaxBackups, 0, WithSampler(sampler))
+	assert.NoError(err)
+
+	sqlSampler, err := NewStratifiedSampler(0.5)
+	assert.NoError(err)
+
+	sqlStorage, err := NewSQL(newMemoryDB(t), WithSQLSampler(sqlSampler))
+	assert.NoError(err)
+
+	for _, s := range []Storage{s, sqlStorage} {
+		for i := 0; i < 4; i++ {
+			download := mockDownload
+			download.ID = fmt.Sprint(i)
+			assert.NoError(s.CreateDownload(download))
+			assert.NoError(s.CreateNetworkTopology(mockNetworkTopology))
+		}
+
+		downloads, err := s.ListDownload()
+		assert.NoError(err)
+		assert.Len(downloads, 2)
+		assert.Equal("0", downloads[0].ID)
+		assert.Equal("2", downloads[1].ID)
+		assert.Equal(int64(4), s.NetworkTopologyCount())
+	}
+}
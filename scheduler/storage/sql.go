@@ -0,0 +1,499 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/gocarina/gocsv"
+
+	logger "d7y.io/dragonfly/v2/internal/dflog"
+)
+
+const (
+	// DownloadTableName is the name of table of downloads in database.
+	DownloadTableName = "download"
+
+	// NetworkTopologyTableName is the name of table of network topologies in database.
+	NetworkTopologyTableName = "network_topology"
+
+	// SQLiteFilename is the file name of sqlite database of storage in the data directory.
+	SQLiteFilename = "storage.db"
+)
+
+// SQLStorage is the database-backed Storage, each record is kept in a row in csv format,
+// and the rows are ordered by the auto-increment id. The records are streamed from
+// database by cursor when opening, instead of being loaded into memory. The downloads
+// are indexed by task id. The statements are written in the SQLite dialect.
+type SQLStorage struct {
+	db *sql.DB
+
+	// maxRecords is the max number of newest records kept in each table. Zero means unlimited.
+	maxRecords int64
+
+	// sampler samples downloads before they are inserted, all downloads are inserted if it is nil.
+	sampler Sampler
+}
+
+// SQLOption is a functional option for configuring the SQLStorage.
+type SQLOption func(s *SQLStorage) error
+
+// WithSQLMaxRecords keeps at most maxRecords newest records for each of downloads and network
+// topologies in database, the oldest records are deleted after inserting.
+func WithSQLMaxRecords(maxRecords int) SQLOption {
+	return func(s *SQLStorage) error {
+		if maxRecords < 0 {
+			return fmt.Errorf("invalid max records %d", maxRecords)
+		}
+
+		s.maxRecords = int64(maxRecords)
+		return nil
+	}
+}
+
+// WithSQLSampler inserts only the downloads kept by sampler, the network topologies are not sampled.
+func WithSQLSampler(sampler Sampler) SQLOption {
+	return func(s *SQLStorage) error {
+		s.sampler = sampler
+		return nil
+	}
+}
+
+// NewSQL returns a new SQLStorage instance, the tables are created if they do not exist.
+func NewSQL(db *sql.DB, options ...SQLOption) (*SQLStorage, error) {
+	s := &SQLStorage{db: db}
+	for _, opt := range options {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, table := range []string{DownloadTableName, NetworkTopologyTableName} {
+		if _, err := db.Exec(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id INTEGER PRIMARY KEY, record BLOB NOT NULL, task_id TEXT NOT NULL DEFAULT '')", table)); err != nil {
+			return nil, fmt.Errorf("create table %s: %w", table, err)
+		}
+
+		if err := s.migrateTaskID(table); err != nil {
+			return nil, fmt.Errorf("migrate table %s: %w", table, err)
+		}
+	}
+
+	if _, err := db.Exec(fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_task_id ON %s (task_id)", DownloadTableName, DownloadTableName)); err != nil {
+		return nil, fmt.Errorf("create index of table %s: %w", DownloadTableName, err)
+	}
+
+	return s, nil
+}
+
+// CreateDownload inserts the download into database.
+func (s *SQLStorage) CreateDownload(download Download) error {
+	if s.sampler != nil && !s.sampler.Sample(download) {
+		return nil
+	}
+
+	return s.create(DownloadTableName, []Download{download}, download.Task.ID)
+}
+
+// CreateNetworkTopology inserts the network topology into database.
+func (s *SQLStorage) CreateNetworkTopology(networkTopology NetworkTopology) error {
+	return s.create(NetworkTopologyTableName, []NetworkTopology{networkTopology}, "")
+}
+
+// ListDownloadByTaskID returns the downloads of the task from oldest to newest by the index of task id.
+func (s *SQLStorage) ListDownloadByTaskID(taskID string) ([]Download, error) {
+	rows, err := s.db.Query(fmt.Sprintf("SELECT record FROM %s WHERE task_id = ? ORDER BY id ASC", DownloadTableName), taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	readCloser := &rowsReadCloser{rows: rows, cancel: func() {}}
+	defer readCloser.Close()
+
+	var downloads []Download
+	if err := gocsv.UnmarshalWithoutHeaders(readCloser, &downloads); err != nil {
+		if errors.Is(err, gocsv.ErrEmptyCSVFile) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return downloads, nil
+}
+
+// ListDownload returns all downloads in database.
+func (s *SQLStorage) ListDownload() ([]Download, error) {
+	readCloser, err := s.OpenDownload()
+	if err != nil {
+		return nil, err
+	}
+	defer readCloser.Close()
+
+	var downloads []Download
+	if err := gocsv.UnmarshalWithoutHeaders(readCloser, &downloads); err != nil {
+		return nil, err
+	}
+
+	return downloads, nil
+}
+
+// ListNetworkTopology returns all network topologies in database.
+func (s *SQLStorage) ListNetworkTopology() ([]NetworkTopology, error) {
+	readCloser, err := s.OpenNetworkTopology()
+	if err != nil {
+		return nil, err
+	}
+	defer readCloser.Close()
+
+	var networkTopologies []NetworkTopology
+	if err := gocsv.UnmarshalWithoutHeaders(readCloser, &networkTopologies); err != nil {
+		return nil, err
+	}
+
+	return networkTopologies, nil
+}
+
+// DownloadCount returns the count of downloads, it returns zero if the query fails.
+func (s *SQLStorage) DownloadCount() int64 {
+	return s.queryInt64(fmt.Sprintf("SELECT COUNT(*) FROM %s", DownloadTableName))
+}
+
+// NetworkTopologyCount returns the count of network topologies, it returns zero if the query fails.
+func (s *SQLStorage) NetworkTopologyCount() int64 {
+	return s.queryInt64(fmt.Sprintf("SELECT COUNT(*) FROM %s", NetworkTopologyTableName))
+}
+
+// DownloadSize returns the size in bytes of downloads in csv format, it returns zero if the query fails.
+func (s *SQLStorage) DownloadSize() int64 {
+	return s.queryInt64(fmt.Sprintf("SELECT COALESCE(SUM(LENGTH(record)), 0) FROM %s", DownloadTableName))
+}
+
+// NetworkTopologySize returns the size in bytes of network topologies in csv format, it returns zero if the query fails.
+func (s *SQLStorage) NetworkTopologySize() int64 {
+	return s.queryInt64(fmt.Sprintf("SELECT COALESCE(SUM(LENGTH(record)), 0) FROM %s", NetworkTopologyTableName))
+}
+
+// OpenDownload returns io.ReadCloser of downloads from oldest to newest, the downloads
+// inserted after opening are not read.
+func (s *SQLStorage) OpenDownload() (io.ReadCloser, error) {
+	return s.open(DownloadTableName, "ASC")
+}
+
+// OpenDownloadReverse returns io.ReadCloser of downloads from newest to oldest, the downloads
+// inserted after opening are not read.
+func (s *SQLStorage) OpenDownloadReverse() (io.ReadCloser, error) {
+	return s.open(DownloadTableName, "DESC")
+}
+
+// OpenNetworkTopology returns io.ReadCloser of network topologies from oldest to newest, the
+// network topologies inserted after opening are not read.
+func (s *SQLStorage) OpenNetworkTopology() (io.ReadCloser, error) {
+	return s.open(NetworkTopologyTableName, "ASC")
+}
+
+// ClearDownload removes all downloads.
+func (s *SQLStorage) ClearDownload() error {
+	_, err := s.db.Exec(fmt.Sprintf("DELETE FROM %s", DownloadTableName))
+	return err
+}
+
+// ClearNetworkTopology removes all network topologies.
+func (s *SQLStorage) ClearNetworkTopology() error {
+	_, err := s.db.Exec(fmt.Sprintf("DELETE FROM %s", NetworkTopologyTableName))
+	return err
+}
+
+// Sync does nothing, the records are committed by database when inserting.
+func (s *SQLStorage) Sync() error {
+	return nil
+}
+
+// ExportNetworkTopologyJSON writes the network topologies as a JSON array of edges.
+func (s *SQLStorage) ExportNetworkTopologyJSON(w io.Writer) error {
+	readCloser, err := s.OpenNetworkTopology()
+	if err != nil {
+		return err
+	}
+	defer readCloser.Close()
+
+	return exportNetworkTopologyJSON(readCloser, w)
+}
+
+// ExportArchive writes the downloads and network topologies into a tar archive with manifest.
+func (s *SQLStorage) ExportArchive(w io.Writer, options ...ArchiveOption) error {
+	download, err := s.OpenDownload()
+	if err != nil {
+		return err
+	}
+	defer download.Close()
+
+	networkTopology, err := s.OpenNetworkTopology()
+	if err != nil {
+		return err
+	}
+	defer networkTopology.Close()
+
+	return writeArchive(w, download, networkTopology, options...)
+}
+
+// ImportArchive inserts the downloads and network topologies of the archive written by ExportArchive.
+func (s *SQLStorage) ImportArchive(r io.Reader) error {
+	return readArchive(r, func(r io.Reader) (int64, error) {
+		return s.importRecords(DownloadTableName, r)
+	}, func(r io.Reader) (int64, error) {
+		return s.importRecords(NetworkTopologyTableName, r)
+	})
+}
+
+// Verify does nothing, the integrity of records is guaranteed by database.
+func (s *SQLStorage) Verify() error {
+	return nil
+}
+
+// create inserts the records of task into table, the task id of network topologies is empty.
+func (s *SQLStorage) create(table string, records any, taskID string) error {
+	var buf bytes.Buffer
+	if err := gocsv.MarshalWithoutHeaders(records, &buf); err != nil {
+		return err
+	}
+
+	if _, err := s.db.Exec(fmt.Sprintf("INSERT INTO %s (record, task_id) VALUES (?, ?)", table), buf.Bytes(), taskID); err != nil {
+		return err
+	}
+
+	return s.evict(table)
+}
+
+// evict deletes the oldest records of table exceeding the max records. The ids of records are
+// consecutive, because the records are only inserted at the end or deleted from the beginning.
+func (s *SQLStorage) evict(table string) error {
+	if s.maxRecords <= 0 {
+		return nil
+	}
+
+	_, err := s.db.Exec(fmt.Sprintf("DELETE FROM %s WHERE id <= (SELECT MAX(id) FROM %s) - ?", table, table), s.maxRecords)
+	return err
+}
+
+// migrateTaskID adds the task id column to the table created before the downloads are indexed
+// by task id, and fills the task ids of the existing downloads.
+func (s *SQLStorage) migrateTaskID(table string) error {
+	var columns int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = 'task_id'", table).Scan(&columns); err != nil {
+		return err
+	}
+
+	if columns > 0 {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN task_id TEXT NOT NULL DEFAULT ''", table)); err != nil {
+		return err
+	}
+
+	if table == DownloadTableName {
+		rows, err := tx.Query(fmt.Sprintf("SELECT id, record FROM %s", table))
+		if err != nil {
+			return err
+		}
+
+		taskIDs := map[int64]string{}
+		for rows.Next() {
+			var (
+				id     int64
+				record []byte
+			)
+			if err := rows.Scan(&id, &record); err != nil {
+				rows.Close()
+				return err
+			}
+
+			taskID, err := recordTaskID(table, record)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+
+			taskIDs[id] = taskID
+		}
+
+		if err := rows.Close(); err != nil {
+			return err
+		}
+
+		for id, taskID := range taskIDs {
+			if _, err := tx.Exec(fmt.Sprintf("UPDATE %s SET task_id = ? WHERE id = ?", table), taskID, id); err != nil {
+				return err
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+// recordTaskID returns the task id of the record in table, it is empty for network topologies.
+func recordTaskID(table string, record []byte) (string, error) {
+	if table != DownloadTableName {
+		return "", nil
+	}
+
+	var downloads []Download
+	if err := gocsv.UnmarshalWithoutHeaders(bytes.NewReader(record), &downloads); err != nil {
+		return "", err
+	}
+
+	if len(downloads) == 0 {
+		return "", nil
+	}
+
+	return downloads[0].Task.ID, nil
+}
+
+// importRecords inserts each non-empty line of r into table in a transaction, it returns
+// the number of inserted records.
+func (s *SQLStorage) importRecords(table string, r io.Reader) (int64, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(fmt.Sprintf("INSERT INTO %s (record, task_id) VALUES (?, ?)", table))
+	if err != nil {
+		return 0, err
+	}
+	defer stmt.Close()
+
+	var count int64
+	reader := bufio.NewReader(r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			// The last line of archive entry may not be terminated.
+			if line[len(line)-1] != '\n' {
+				line = append(line, '\n')
+			}
+
+			taskID, err := recordTaskID(table, line)
+			if err != nil {
+				return 0, err
+			}
+
+			if _, err := stmt.Exec(line, taskID); err != nil {
+				return 0, err
+			}
+			count++
+		}
+
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				return 0, err
+			}
+
+			break
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	if err := s.evict(table); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// queryInt64 returns the int64 result of query, it returns zero if the query fails.
+func (s *SQLStorage) queryInt64(query string) int64 {
+	var n int64
+	if err := s.db.QueryRow(query).Scan(&n); err != nil {
+		logger.Errorf("query %s failed: %s", query, err.Error())
+		return 0
+	}
+
+	return n
+}
+
+// open returns io.ReadCloser of the records in table ordered by id in order. The records
+// are bounded by the max id when opening, so that the records inserted while reading
+// are not read.
+func (s *SQLStorage) open(table, order string) (io.ReadCloser, error) {
+	var maxID sql.NullInt64
+	if err := s.db.QueryRow(fmt.Sprintf("SELECT MAX(id) FROM %s", table)).Scan(&maxID); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf("SELECT record FROM %s WHERE id <= ? ORDER BY id %s", table, order), maxID.Int64)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	return &rowsReadCloser{rows: rows, cancel: cancel}, nil
+}
+
+// rowsReadCloser reads the records of rows one at a time.
+type rowsReadCloser struct {
+	rows   *sql.Rows
+	cancel context.CancelFunc
+	buf    []byte
+}
+
+// Read reads the records of rows.
+func (r *rowsReadCloser) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if !r.rows.Next() {
+			if err := r.rows.Err(); err != nil {
+				return 0, err
+			}
+
+			return 0, io.EOF
+		}
+
+		var record []byte
+		if err := r.rows.Scan(&record); err != nil {
+			return 0, err
+		}
+
+		r.buf = record
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// Close closes the rows and cancels the query.
+func (r *rowsReadCloser) Close() error {
+	defer r.cancel()
+	return r.rows.Close()
+}
@@ -0,0 +1,260 @@
+/*
+ *     Copyright 2023 The Dragonfly Authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *      http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/gocarina/gocsv"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+// newMemoryDB returns the in-memory sqlite database shared by the connections of test.
+func newMemoryDB(t *testing.T) *sql.DB {
+	name := strings.NewReplacer("/", "_", " ", "_").Replace(t.Name())
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?mode=memory&cache=shared", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The in-memory database is removed when the last connection is closed.
+	db.SetMaxIdleConns(1)
+	db.SetConnMaxLifetime(0)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestSQLStorage_Download(t *testing.T) {
+	assert := assert.New(t)
+	s, err := NewSQL(newMemoryDB(t))
+	assert.NoError(err)
+
+	var _ Storage = s
+	assert.Equal(int64(0), s.DownloadCount())
+	assert.Equal(int64(0), s.DownloadSize())
+
+	for i := 0; i < 3; i++ {
+		download := mockDownload
+		download.ID = fmt.Sprint(i)
+		assert.NoError(s.CreateDownload(download))
+	}
+	assert.NoError(s.Sync())
+	assert.Equal(int64(3), s.DownloadCount())
+
+	downloads, err := s.ListDownload()
+	assert.NoError(err)
+	assert.Len(downloads, 3)
+	assert.Equal("0", downloads[0].ID)
+	assert.Equal("2", downloads[2].ID)
+
+	readCloser, err := s.OpenDownload()
+	assert.NoError(err)
+	data, err := io.ReadAll(readCloser)
+	assert.NoError(err)
+	assert.NoError(readCloser.Close())
+	assert.Equal(int64(len(data)), s.DownloadSize())
+
+	// The streamed downloads match the memory storage seeded with the same records.
+	m := NewMemory()
+	m.SetDownload(data)
+	assert.Equal(int64(3), m.DownloadCount())
+	memoryDownloads, err := m.ListDownload()
+	assert.NoError(err)
+	assert.Equal(memoryDownloads, downloads)
+
+	readCloser, err = s.OpenDownloadReverse()
+	assert.NoError(err)
+	var reversed []Download
+	assert.NoError(gocsv.UnmarshalWithoutHeaders(readCloser, &reversed))
+	assert.NoError(readCloser.Close())
+	assert.Len(reversed, 3)
+	assert.Equal("2", reversed[0].ID)
+	assert.Equal("0", reversed[2].ID)
+
+	// Readers closed before reading all records release the cursor.
+	readCloser, err = s.OpenDownload()
+	assert.NoError(err)
+	_, err = readCloser.Read(make([]byte, 1))
+	assert.NoError(err)
+	assert.NoError(readCloser.Close())
+
+	assert.NoError(s.ClearDownload())
+	assert.Equal(int64(0), s.DownloadCount())
+	readCloser, err = s.OpenDownload()
+	assert.NoError(err)
+	data, err = io.ReadAll(readCloser)
+	assert.NoError(err)
+	assert.NoError(readCloser.Close())
+	assert.Empty(data)
+}
+
+func TestSQLStorage_NetworkTopology(t *testing.T) {
+	assert := assert.New(t)
+	s, err := NewSQL(newMemoryDB(t))
+	assert.NoError(err)
+
+	assert.NoError(s.CreateNetworkTopology(mockNetworkTopology))
+	assert.Equal(int64(1), s.NetworkTopologyCount())
+
+	networkTopologies, err := s.ListNetworkTopology()
+	assert.NoError(err)
+	assert.Len(networkTopologies, 1)
+	assert.EqualValues(mockNetworkTopology.ID, networkTopologies[0].ID)
+
+	readCloser, err := s.OpenNetworkTopology()
+	assert.NoError(err)
+	data, err := io.ReadAll(readCloser)
+	assert.NoError(err)
+	assert.NoError(readCloser.Close())
+	assert.Equal(int64(len(data)), s.NetworkTopologySize())
+
+	var buf bytes.Buffer
+	assert.NoError(s.ExportNetworkTopologyJSON(&buf))
+	assert.Contains(buf.String(), mockNetworkTopology.ID)
+
+	assert.NoError(s.ClearNetworkTopology())
+	assert.Equal(int64(0), s.NetworkTopologyCount())
+	assert.Equal(int64(0), s.NetworkTopologySize())
+}
+
+func TestSQLStorage_Archive(t *testing.T) {
+	assert := assert.New(t)
+	s, err := NewSQL(newMemoryDB(t))
+	assert.NoError(err)
+
+	for i := 0; i < 2; i++ {
+		assert.NoError(s.CreateDownload(mockDownload))
+	}
+	assert.NoError(s.CreateNetworkTopology(mockNetworkTopology))
+
+	var buf bytes.Buffer
+	assert.NoError(s.ExportArchive(&buf))
+
+	// The archive written by database is imported by memory storage, and vice versa.
+	m := NewMemory()
+	assert.NoError(m.ImportArchive(bytes.NewReader(buf.Bytes())))
+	assert.Equal(int64(2), m.DownloadCount())
+	assert.Equal(int64(1), m.NetworkTopologyCount())
+
+	buf.Reset()
+	assert.NoError(m.ExportArchive(&buf))
+	assert.NoError(s.ClearDownload())
+	assert.NoError(s.ClearNetworkTopology())
+	assert.NoError(s.ImportArchive(&buf))
+	assert.Equal(int64(2), s.DownloadCount())
+	assert.Equal(int64(1), s.NetworkTopologyCount())
+	assert.Equal(m.DownloadSize(), s.DownloadSize())
+	assert.Equal(m.NetworkTopologySize(), s.NetworkTopologySize())
+
+	downloads, err := s.ListDownload()
+	assert.NoError(err)
+	assert.Len(downloads, 2)
+	assert.EqualValues(mockDownload.ID, downloads[1].ID)
+}
+
+func TestSQLStorage_ListDownloadByTaskID(t *testing.T) {
+	assert := assert.New(t)
+	s, err := NewSQL(newMemoryDB(t))
+	assert.NoError(err)
+
+	downloads, err := s.ListDownloadByTaskID("foo")
+	assert.NoError(err)
+	assert.Empty(downloads)
+
+	for i, taskID := range []string{"foo", "bar", "foo"} {
+		download := mockDownload
+		download.ID = fmt.Sprint(i)
+		download.Task.ID = taskID
+		assert.NoError(s.CreateDownload(download))
+	}
+
+	downloads, err = s.ListDownloadByTaskID("foo")
+	assert.NoError(err)
+	assert.Len(downloads, 2)
+	assert.Equal("0", downloads[0].ID)
+	assert.Equal("2", downloads[1].ID)
+
+	// The downloads imported from archive are indexed by task id.
+	var buf bytes.Buffer
+	assert.NoError(s.ExportArchive(&buf))
+	assert.NoError(s.ClearDownload())
+	assert.NoError(s.ImportArchive(&buf))
+	downloads, err = s.ListDownloadByTaskID("bar")
+	assert.NoError(err)
+	assert.Len(downloads, 1)
+	assert.Equal("1", downloads[0].ID)
+}
+
+func TestSQLStorage_MaxRecords(t *testing.T) {
+	const maxRecords = 3
+
+	assert := assert.New(t)
+	s, err := NewSQL(newMemoryDB(t), WithSQLMaxRecords(maxRecords))
+	assert.NoError(err)
+
+	for i := 0; i < 2*maxRecords; i++ {
+		download := mockDownload
+		download.ID = fmt.Sprint(i)
+		assert.NoError(s.CreateDownload(download))
+		assert.NoError(s.CreateNetworkTopology(mockNetworkTopology))
+	}
+	assert.Equal(int64(maxRecords), s.DownloadCount())
+	assert.Equal(int64(maxRecords), s.NetworkTopologyCount())
+
+	downloads, err := s.ListDownload()
+	assert.NoError(err)
+	assert.Len(downloads, maxRecords)
+	for i, download := range downloads {
+		assert.Equal(fmt.Sprint(maxRecords+i), download.ID)
+	}
+
+	_, err = NewSQL(newMemoryDB(t), WithSQLMaxRecords(-1))
+	assert.EqualError(err, "invalid max records -1")
+}
+
+func TestSQLStorage_MigrateTaskID(t *testing.T) {
+	assert := assert.New(t)
+	db := newMemoryDB(t)
+
+	// The tables created before the downloads are indexed by task id.
+	var buf bytes.Buffer
+	assert.NoError(gocsv.MarshalWithoutHeaders([]Download{mockDownload}, &buf))
+	_, err := db.Exec(fmt.Sprintf("CREATE TABLE %s (id INTEGER PRIMARY KEY, record BLOB NOT NULL)", DownloadTableName))
+	assert.NoError(err)
+	_, err = db.Exec(fmt.Sprintf("CREATE TABLE %s (id INTEGER PRIMARY KEY, record BLOB NOT NULL)", NetworkTopologyTableName))
+	assert.NoError(err)
+	_, err = db.Exec(fmt.Sprintf("INSERT INTO %s (record) VALUES (?)", DownloadTableName), buf.Bytes())
+	assert.NoError(err)
+
+	s, err := NewSQL(db)
+	assert.NoError(err)
+	downloads, err := s.ListDownloadByTaskID(mockDownload.Task.ID)
+	assert.NoError(err)
+	assert.Len(downloads, 1)
+	assert.Equal(mockDownload.ID, downloads[0].ID)
+
+	// The migrated tables are not migrated again.
+	_, err = NewSQL(db)
+	assert.NoError(err)
+	assert.Equal(int64(1), s.DownloadCount())
+}
@@ -19,6 +19,7 @@
 package storage
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
@@ -35,6 +36,7 @@ import (
 
 	logger "d7y.io/dragonfly/v2/internal/dflog"
 	pkgio "d7y.io/dragonfly/v2/pkg/io"
+	"d7y.io/dragonfly/v2/scheduler/metrics"
 )
 
 const (
@@ -52,33 +54,52 @@ const (
 	// megabyte is the converted factor of MaxSize and bytes.
 	megabyte = 1024 * 1024
 
-	// backupTimeFormat is the timestamp format of backup filename.
-	backupTimeFormat = "2006-01-02T15-04-05.000"
+	// backupTimeFormat is the timestamp format of backup filename, it is precise to nanoseconds
+	// so that the files rotated in the same millisecond, e.g. by restarting, are not overwritten.
+	backupTimeFormat = "2006-01-02T15-04-05.000000000"
 )
 
 // Storage is the interface used for storage.
 type Storage interface {
-	// CreateDownload inserts the download into csv file.
+	// CreateDownload inserts the download into storage.
 	CreateDownload(Download) error
 
-	// CreateNetworkTopology inserts the network topology into csv file.
+	// CreateNetworkTopology inserts the network topology into storage.
 	CreateNetworkTopology(NetworkTopology) error
 
-	// ListDownload returns all downloads in csv file.
+	// ListDownload returns all downloads in storage.
 	ListDownload() ([]Download, error)
 
-	// ListNetworkTopology returns all network topologies in csv file.
+	// ListNetworkTopology returns all network topologies in storage.
 	ListNetworkTopology() ([]NetworkTopology, error)
 
+	// ListDownloadByTaskID returns the downloads of the task in storage.
+	ListDownloadByTaskID(taskID string) ([]Download, error)
+
 	// DownloadCount returns the count of downloads.
 	DownloadCount() int64
 
 	// NetworkTopologyCount returns the count of network topologies.
 	NetworkTopologyCount() int64
 
+	// DownloadSize returns the size in bytes of downloads written into files since the storage is
+	// created or cleared, the size is counted before encryption and does not include the buffered downloads.
+	DownloadSize() int64
+
+	// NetworkTopologySize returns the size in bytes of network topologies written into files since the storage is
+	// created or cleared, the size is counted before encryption and does not include the buffered network topologies.
+	NetworkTopologySize() int64
+
 	// OpenDownload opens download files for read, it returns io.ReadCloser of download files.
 	OpenDownload() (io.ReadCloser, error)
 
+	// OpenDownloadReverse opens download files for read, it returns io.ReadCloser of downloads
+	// from newest to oldest. The offsets of downloads in each file are indexed before reading
+	// the file, so it costs an extra pass over the file and memory of one offset per download
+	// in the file being read, and each download is read by a separate positioned read. If
+	// encryption is enabled, each file is decrypted into a temporary file when opening.
+	OpenDownloadReverse() (io.ReadCloser, error)
+
 	// OpenNetworkTopology opens network topology files for read, it returns io.ReadCloser of network topology files.
 	OpenNetworkTopology() (io.ReadCloser, error)
 
@@ -87,6 +108,22 @@ type Storage interface {
 
 	// ClearNetworkTopology removes all network topology files.
 	ClearNetworkTopology() error
+
+	// Sync writes the buffered records into files and commits the files to stable storage.
+	Sync() error
+
+	// ExportNetworkTopologyJSON writes the network topologies as a JSON array of edges.
+	ExportNetworkTopologyJSON(w io.Writer) error
+
+	// ExportArchive writes the downloads and network topologies into a tar archive with manifest.
+	ExportArchive(w io.Writer, options ...ArchiveOption) error
+
+	// ImportArchive inserts the downloads and network topologies of the archive written by ExportArchive.
+	ImportArchive(r io.Reader) error
+
+	// Verify recomputes the checksums of segments in files and compares them with the manifests,
+	// it returns *CorruptionError describing the corrupt segments.
+	Verify() error
 }
 
 // storage provides storage function.
@@ -100,15 +137,69 @@ type storage struct {
 	downloadFilename string
 	downloadBuffer   []Download
 	downloadCount    int64
+	downloadSize     int64
+	downloadRecords  *recordFiles
 
 	networkTopologyMu       *sync.RWMutex
 	networkTopologyFilename string
 	networkTopologyBuffer   []NetworkTopology
 	networkTopologyCount    int64
+	networkTopologySize     int64
+	networkTopologyRecords  *recordFiles
+
+	// encryptor encrypts records at rest, encryption is disabled if it is nil.
+	encryptor *encryptor
+
+	// maxRecords is the maximum number of records retained in files, the records in files
+	// are tracked by downloadRecords and networkTopologyRecords. Zero means unlimited.
+	maxRecords int64
+
+	// sampler samples downloads before they are written, all downloads are written if it is nil.
+	sampler Sampler
+}
+
+// Option is a functional option for configuring the storage.
+type Option func(s *storage) error
+
+// WithEncryption enables AES-GCM encryption at rest. Records are encrypted with the key of
+// currentKeyID, and decrypted with the key tagged on each segment, so that the records
+// encrypted with rotated keys can still be read if their keys are given.
+func WithEncryption(keys []EncryptionKey, currentKeyID string) Option {
+	return func(s *storage) error {
+		encryptor, err := newEncryptor(keys, currentKeyID)
+		if err != nil {
+			return err
+		}
+
+		s.encryptor = encryptor
+		return nil
+	}
+}
+
+// WithMaxRecords keeps at most maxRecords newest records for each of downloads and network
+// topologies in files, the oldest records are evicted after the records are written into files.
+// The evicted records are skipped when reading, and the file is removed once all its records are evicted.
+func WithMaxRecords(maxRecords int) Option {
+	return func(s *storage) error {
+		if maxRecords < 0 {
+			return fmt.Errorf("invalid max records %d", maxRecords)
+		}
+
+		s.maxRecords = int64(maxRecords)
+		return nil
+	}
+}
+
+// WithSampler writes only the downloads kept by sampler, the network topologies are not sampled.
+func WithSampler(sampler Sampler) Option {
+	return func(s *storage) error {
+		s.sampler = sampler
+		return nil
+	}
 }
 
 // New returns a new Storage instance.
-func New(baseDir string, maxSize, maxBackups, bufferSize int) (Storage, error) {
+func New(baseDir string, maxSize, maxBackups, bufferSize int, options ...Option) (Storage, error) {
 	s := &storage{
 		baseDir:    baseDir,
 		maxSize:    int64(maxSize * megabyte),
@@ -123,36 +214,90 @@ func New(baseDir string, maxSize, maxBackups, bufferSize int) (Storage, error) {
 		networkTopologyFilename: filepath.Join(baseDir, fmt.Sprintf("%s.%s", NetworkTopologyFilePrefix, CSVFileExt)),
 		networkTopologyBuffer:   make([]NetworkTopology, 0, bufferSize),
 	}
+	for _, opt := range options {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := os.Mkdir(filepath.Join(baseDir, ManifestDirName), 0700); err != nil && !errors.Is(err, fs.ErrExist) {
+		return nil, err
+	}
+
+	var err error
+	if s.downloadRecords, err = s.openRecordFiles(s.downloadFilename, s.downloadBackupFilename(), DownloadFilePrefix); err != nil {
+		return nil, err
+	}
+
+	if s.networkTopologyRecords, err = s.openRecordFiles(s.networkTopologyFilename, s.networkTopologyBackupFilename(), NetworkTopologyFilePrefix); err != nil {
+		return nil, err
+	}
+
+	// Evict the oldest records in the backups retained from the previous run.
+	if s.maxRecords > 0 {
+		if err := s.countBackupRecords(s.downloadBackups, s.downloadRecords); err != nil {
+			return nil, err
+		}
+
+		if err := s.evictDownload(); err != nil {
+			return nil, err
+		}
+
+		if err := s.countBackupRecords(s.networkTopologyBackups, s.networkTopologyRecords); err != nil {
+			return nil, err
+		}
+
+		if err := s.evictNetworkTopology(); err != nil {
+			return nil, err
+		}
+	}
 
-	downloadFile, err := os.OpenFile(s.downloadFilename, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	return s, nil
+}
+
+// openRecordFiles creates the current file of filename and loads the origin of dataset with the prefix.
+// The current file retained from the previous run is rotated into the backup file instead of being
+// truncated, so that the history of dataset is only appended to.
+func (s *storage) openRecordFiles(filename, backupFilename, prefix string) (*recordFiles, error) {
+	origin, err := loadOrigin(s.originFilename(prefix))
 	if err != nil {
 		return nil, err
 	}
-	downloadFile.Close()
 
-	networkTopologyFile, err := os.OpenFile(s.networkTopologyFilename, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
+	files := newRecordFiles(filename, s.originFilename(prefix), origin)
+	if fileInfo, err := os.Stat(filename); err == nil && fileInfo.Size() > 0 {
+		// The stats of the retained file are counted when needed.
+		delete(files.stats, filename)
+		if err := s.rotate(filename, backupFilename, files); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := os.OpenFile(filename, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0600)
 	if err != nil {
 		return nil, err
 	}
-	networkTopologyFile.Close()
+	file.Close()
 
-	return s, nil
+	if err := s.removeManifest(filename); err != nil {
+		return nil, err
+	}
+
+	return files, nil
 }
 
-// CreateDownload inserts the download into csv file.
+// CreateDownload inserts the download into storage.
 func (s *storage) CreateDownload(download Download) error {
+	if s.sampler != nil && !s.sampler.Sample(download) {
+		return nil
+	}
+
 	s.downloadMu.Lock()
 	defer s.downloadMu.Unlock()
 
 	// Write without buffer.
 	if s.bufferSize == 0 {
-		if err := s.createDownload(download); err != nil {
-			return err
-		}
-
-		// Update download count.
-		s.downloadCount++
-		return nil
+		return s.createDownload(download)
 	}
 
 	// Write downloads to file.
@@ -161,9 +306,6 @@ func (s *storage) CreateDownload(download Download) error {
 			return err
 		}
 
-		// Update download count.
-		s.downloadCount += int64(s.bufferSize)
-
 		// Keep allocated memory.
 		s.downloadBuffer = s.downloadBuffer[:0]
 	}
@@ -173,20 +315,14 @@ func (s *storage) CreateDownload(download Download) error {
 	return nil
 }
 
-// CreateNetworkTopology inserts the network topology into csv file.
+// CreateNetworkTopology inserts the network topology into storage.
 func (s *storage) CreateNetworkTopology(networkTopology NetworkTopology) error {
 	s.networkTopologyMu.Lock()
 	defer s.networkTopologyMu.Unlock()
 
 	// Write without buffer.
 	if s.bufferSize == 0 {
-		if err := s.createNetworkTopology(networkTopology); err != nil {
-			return err
-		}
-
-		// Update network topology count.
-		s.networkTopologyCount++
-		return nil
+		return s.createNetworkTopology(networkTopology)
 	}
 
 	// Write network topologies to file.
@@ -195,9 +331,6 @@ func (s *storage) CreateNetworkTopology(networkTopology NetworkTopology) error {
 			return err
 		}
 
-		// Update network topology count.
-		s.networkTopologyCount += int64(s.bufferSize)
-
 		// Keep allocated memory.
 		s.networkTopologyBuffer = s.networkTopologyBuffer[:0]
 	}
@@ -207,7 +340,7 @@ func (s *storage) CreateNetworkTopology(networkTopology NetworkTopology) error {
 	return nil
 }
 
-// ListDownload returns all downloads in csv file.
+// ListDownload returns all downloads in storage.
 func (s *storage) ListDownload() ([]Download, error) {
 	s.downloadMu.RLock()
 	defer s.downloadMu.RUnlock()
@@ -217,8 +350,10 @@ func (s *storage) ListDownload() ([]Download, error) {
 		return nil, err
 	}
 
-	var readers []io.Reader
-	var readClosers []io.ReadCloser
+	readClosers, _, err := s.openFiles(fileInfos, s.downloadRecords.evicted)
+	if err != nil {
+		return nil, err
+	}
 	defer func() {
 		for _, readCloser := range readClosers {
 			if err := readCloser.Close(); err != nil {
@@ -227,14 +362,9 @@ func (s *storage) ListDownload() ([]Download, error) {
 		}
 	}()
 
-	for _, fileInfo := range fileInfos {
-		file, err := os.Open(filepath.Join(s.baseDir, fileInfo.Name()))
-		if err != nil {
-			return nil, err
-		}
-
-		readers = append(readers, file)
-		readClosers = append(readClosers, file)
+	readers := make([]io.Reader, 0, len(readClosers))
+	for _, readCloser := range readClosers {
+		readers = append(readers, readCloser)
 	}
 
 	var downloads []Download
@@ -245,7 +375,33 @@ func (s *storage) ListDownload() ([]Download, error) {
 	return downloads, nil
 }
 
-// ListNetworkTopology returns all network topologies in csv file.
+// ListDownloadByTaskID returns the downloads of the task in storage, it scans all download files.
+func (s *storage) ListDownloadByTaskID(taskID string) ([]Download, error) {
+	downloads, err := s.ListDownload()
+	if err != nil {
+		if errors.Is(err, gocsv.ErrEmptyCSVFile) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return filterDownloadsByTaskID(downloads, taskID), nil
+}
+
+// filterDownloadsByTaskID returns the downloads of the task in order.
+func filterDownloadsByTaskID(downloads []Download, taskID string) []Download {
+	var filtered []Download
+	for _, download := range downloads {
+		if download.Task.ID == taskID {
+			filtered = append(filtered, download)
+		}
+	}
+
+	return filtered
+}
+
+// ListNetworkTopology returns all network topologies in storage.
 func (s *storage) ListNetworkTopology() ([]NetworkTopology, error) {
 	s.networkTopologyMu.RLock()
 	defer s.networkTopologyMu.RUnlock()
@@ -255,8 +411,10 @@ func (s *storage) ListNetworkTopology() ([]NetworkTopology, error) {
 		return nil, err
 	}
 
-	var readers []io.Reader
-	var readClosers []io.ReadCloser
+	readClosers, _, err := s.openFiles(fileInfos, s.networkTopologyRecords.evicted)
+	if err != nil {
+		return nil, err
+	}
 	defer func() {
 		for _, readCloser := range readClosers {
 			if err := readCloser.Close(); err != nil {
@@ -265,14 +423,9 @@ func (s *storage) ListNetworkTopology() ([]NetworkTopology, error) {
 		}
 	}()
 
-	for _, fileInfo := range fileInfos {
-		file, err := os.Open(filepath.Join(s.baseDir, fileInfo.Name()))
-		if err != nil {
-			return nil, err
-		}
-
-		readers = append(readers, file)
-		readClosers = append(readClosers, file)
+	readers := make([]io.Reader, 0, len(readClosers))
+	for _, readCloser := range readClosers {
+		readers = append(readers, readCloser)
 	}
 
 	var networkTopologies []NetworkTopology
@@ -293,6 +446,22 @@ func (s *storage) NetworkTopologyCount() int64 {
 	return s.networkTopologyCount
 }
 
+// DownloadSize returns the size in bytes of downloads written into files.
+func (s *storage) DownloadSize() int64 {
+	s.downloadMu.RLock()
+	defer s.downloadMu.RUnlock()
+
+	return s.downloadSize
+}
+
+// NetworkTopologySize returns the size in bytes of network topologies written into files.
+func (s *storage) NetworkTopologySize() int64 {
+	s.networkTopologyMu.RLock()
+	defer s.networkTopologyMu.RUnlock()
+
+	return s.networkTopologySize
+}
+
 // OpenDownload opens download files for read, it returns io.ReadCloser of download files.
 func (s *storage) OpenDownload() (io.ReadCloser, error) {
 	s.downloadMu.RLock()
@@ -303,17 +472,41 @@ func (s *storage) OpenDownload() (io.ReadCloser, error) {
 		return nil, err
 	}
 
-	var readClosers []io.ReadCloser
-	for _, fileInfo := range fileInfos {
-		file, err := os.Open(filepath.Join(s.baseDir, fileInfo.Name()))
+	readClosers, _, err := s.openFiles(fileInfos, s.downloadRecords.evicted)
+	if err != nil {
+		return nil, err
+	}
+
+	return pkgio.MultiReadCloser(readClosers...), nil
+}
+
+// OpenDownloadReverse opens download files for read, it returns io.ReadCloser of downloads from newest to oldest.
+func (s *storage) OpenDownloadReverse() (io.ReadCloser, error) {
+	s.downloadMu.RLock()
+	defer s.downloadMu.RUnlock()
+
+	fileInfos, err := s.downloadBackups()
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]reverseFile, 0, len(fileInfos))
+	for i := len(fileInfos) - 1; i >= 0; i-- {
+		file, err := s.openReverseFile(filepath.Join(s.baseDir, fileInfos[i].Name()))
 		if err != nil {
+			newReverseReadCloser(files).Close()
 			return nil, err
 		}
 
-		readClosers = append(readClosers, file)
+		// The evicted downloads of the oldest file are skipped.
+		if i == 0 {
+			file.skip = s.downloadRecords.evicted
+		}
+
+		files = append(files, file)
 	}
 
-	return pkgio.MultiReadCloser(readClosers...), nil
+	return newReverseReadCloser(files), nil
 }
 
 // OpenNetworkTopology opens network topology files for read, it returns io.ReadCloser of network topology files.
@@ -326,17 +519,168 @@ func (s *storage) OpenNetworkTopology() (io.ReadCloser, error) {
 		return nil, err
 	}
 
-	var readClosers []io.ReadCloser
-	for _, fileInfo := range fileInfos {
-		file, err := os.Open(filepath.Join(s.baseDir, fileInfo.Name()))
-		if err != nil {
-			return nil, err
+	readClosers, _, err := s.openFiles(fileInfos, s.networkTopologyRecords.evicted)
+	if err != nil {
+		return nil, err
+	}
+
+	return pkgio.MultiReadCloser(readClosers...), nil
+}
+
+// ExportNetworkTopologyJSON writes the network topologies as a JSON array of edges.
+func (s *storage) ExportNetworkTopologyJSON(w io.Writer) error {
+	s.networkTopologyMu.RLock()
+	defer s.networkTopologyMu.RUnlock()
+
+	fileInfos, err := s.networkTopologyBackups()
+	if err != nil {
+		return err
+	}
+
+	readClosers, _, err := s.openFiles(fileInfos, s.networkTopologyRecords.evicted)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		for _, readCloser := range readClosers {
+			if err := readCloser.Close(); err != nil {
+				logger.Error(err)
+			}
 		}
+	}()
 
-		readClosers = append(readClosers, file)
+	readers := make([]io.Reader, 0, len(readClosers))
+	for _, readCloser := range readClosers {
+		readers = append(readers, readCloser)
 	}
 
-	return pkgio.MultiReadCloser(readClosers...), nil
+	return exportNetworkTopologyJSON(io.MultiReader(readers...), w)
+}
+
+// ExportArchive writes the downloads and network topologies into a tar archive with manifest,
+// the buffered records are written into files before exporting.
+func (s *storage) ExportArchive(w io.Writer, options ...ArchiveOption) error {
+	if err := s.Sync(); err != nil {
+		return err
+	}
+
+	download, err := s.OpenDownload()
+	if err != nil {
+		return err
+	}
+	defer download.Close()
+
+	networkTopology, err := s.OpenNetworkTopology()
+	if err != nil {
+		return err
+	}
+	defer networkTopology.Close()
+
+	return writeArchive(w, download, networkTopology, options...)
+}
+
+// ImportArchive inserts the downloads and network topologies of the archive written by ExportArchive.
+func (s *storage) ImportArchive(r io.Reader) error {
+	return readArchive(r, s.importDownload, s.importNetworkTopology)
+}
+
+// importDownload inserts the downloads of csv format into file in batches, it returns the count of downloads.
+func (s *storage) importDownload(r io.Reader) (int64, error) {
+	s.downloadMu.Lock()
+	defer s.downloadMu.Unlock()
+
+	downloads := make(chan Download)
+	errCh := make(chan error, 1)
+	go func() {
+		// The channel is closed by gocsv after decoding.
+		errCh <- gocsv.UnmarshalToChanWithoutHeaders(r, downloads)
+	}()
+
+	var (
+		count     int64
+		batch     = make([]Download, 0, importBatchSize)
+		createErr error
+	)
+	for download := range downloads {
+		// Keep draining the channel after failure, otherwise the decoder blocks.
+		if createErr != nil {
+			continue
+		}
+
+		batch = append(batch, download)
+		if len(batch) >= importBatchSize {
+			createErr = s.createDownload(batch...)
+			count += int64(len(batch))
+			batch = batch[:0]
+		}
+	}
+
+	if err := <-errCh; err != nil {
+		return 0, err
+	}
+
+	if createErr != nil {
+		return 0, createErr
+	}
+
+	if len(batch) > 0 {
+		if err := s.createDownload(batch...); err != nil {
+			return 0, err
+		}
+		count += int64(len(batch))
+	}
+
+	return count, nil
+}
+
+// importNetworkTopology inserts the network topologies of csv format into file in batches,
+// it returns the count of network topologies.
+func (s *storage) importNetworkTopology(r io.Reader) (int64, error) {
+	s.networkTopologyMu.Lock()
+	defer s.networkTopologyMu.Unlock()
+
+	networkTopologies := make(chan NetworkTopology)
+	errCh := make(chan error, 1)
+	go func() {
+		// The channel is closed by gocsv after decoding.
+		errCh <- gocsv.UnmarshalToChanWithoutHeaders(r, networkTopologies)
+	}()
+
+	var (
+		count     int64
+		batch     = make([]NetworkTopology, 0, importBatchSize)
+		createErr error
+	)
+	for networkTopology := range networkTopologies {
+		// Keep draining the channel after failure, otherwise the decoder blocks.
+		if createErr != nil {
+			continue
+		}
+
+		batch = append(batch, networkTopology)
+		if len(batch) >= importBatchSize {
+			createErr = s.createNetworkTopology(batch...)
+			count += int64(len(batch))
+			batch = batch[:0]
+		}
+	}
+
+	if err := <-errCh; err != nil {
+		return 0, err
+	}
+
+	if createErr != nil {
+		return 0, createErr
+	}
+
+	if len(batch) > 0 {
+		if err := s.createNetworkTopology(batch...); err != nil {
+			return 0, err
+		}
+		count += int64(len(batch))
+	}
+
+	return count, nil
 }
 
 // ClearDownload removes all downloads.
@@ -349,13 +693,27 @@ func (s *storage) ClearDownload() error {
 		return err
 	}
 
+	// The history of downloads is lost, the downloads written after clearing start a new history.
+	origin := newOrigin()
+	if err := saveOrigin(s.originFilename(DownloadFilePrefix), origin); err != nil {
+		return err
+	}
+	s.downloadRecords = newRecordFiles(s.downloadFilename, s.originFilename(DownloadFilePrefix), origin)
+
+	defer s.updateSizeMetrics(DownloadFilePrefix, s.downloadFilename, s.downloadBackups)
+
 	for _, fileInfo := range fileInfos {
 		filename := filepath.Join(s.baseDir, fileInfo.Name())
 		if err := os.Remove(filename); err != nil {
 			return err
 		}
+
+		if err := s.removeManifest(filename); err != nil {
+			return err
+		}
 	}
 
+	s.downloadSize = 0
 	return nil
 }
 
@@ -369,11 +727,128 @@ func (s *storage) ClearNetworkTopology() error {
 		return err
 	}
 
+	// The history of network topologies is lost, the network topologies written after clearing start a new history.
+	origin := newOrigin()
+	if err := saveOrigin(s.originFilename(NetworkTopologyFilePrefix), origin); err != nil {
+		return err
+	}
+	s.networkTopologyRecords = newRecordFiles(s.networkTopologyFilename, s.originFilename(NetworkTopologyFilePrefix), origin)
+
+	defer s.updateSizeMetrics(NetworkTopologyFilePrefix, s.networkTopologyFilename, s.networkTopologyBackups)
+
 	for _, fileInfo := range fileInfos {
 		filename := filepath.Join(s.baseDir, fileInfo.Name())
 		if err := os.Remove(filename); err != nil {
 			return err
 		}
+
+		if err := s.removeManifest(filename); err != nil {
+			return err
+		}
+	}
+
+	s.networkTopologySize = 0
+	return nil
+}
+
+// Verify recomputes the checksums of segments in files and compares them with the manifests,
+// it returns *CorruptionError describing the corrupt segments. The buffered records are not verified.
+func (s *storage) Verify() error {
+	s.downloadMu.RLock()
+	corruptSegments, err := s.verify(s.downloadBackups)
+	s.downloadMu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("verify download: %w", err)
+	}
+
+	s.networkTopologyMu.RLock()
+	segments, err := s.verify(s.networkTopologyBackups)
+	s.networkTopologyMu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("verify network topology: %w", err)
+	}
+
+	corruptSegments = append(corruptSegments, segments...)
+	if len(corruptSegments) > 0 {
+		return &CorruptionError{Segments: corruptSegments}
+	}
+
+	return nil
+}
+
+// Sync writes the buffered records into files and commits the files to stable storage.
+func (s *storage) Sync() error {
+	if err := s.syncDownload(); err != nil {
+		return fmt.Errorf("sync download: %w", err)
+	}
+
+	if err := s.syncNetworkTopology(); err != nil {
+		return fmt.Errorf("sync network topology: %w", err)
+	}
+
+	return nil
+}
+
+// syncDownload writes the buffered downloads into file and commits the download files to stable storage.
+func (s *storage) syncDownload() error {
+	s.downloadMu.Lock()
+	defer s.downloadMu.Unlock()
+
+	if len(s.downloadBuffer) > 0 {
+		if err := s.createDownload(s.downloadBuffer...); err != nil {
+			return err
+		}
+
+		// Keep allocated memory.
+		s.downloadBuffer = s.downloadBuffer[:0]
+	}
+
+	fileInfos, err := s.downloadBackups()
+	if err != nil {
+		return err
+	}
+
+	return s.syncFiles(fileInfos)
+}
+
+// syncNetworkTopology writes the buffered network topologies into file and commits the network topology files to stable storage.
+func (s *storage) syncNetworkTopology() error {
+	s.networkTopologyMu.Lock()
+	defer s.networkTopologyMu.Unlock()
+
+	if len(s.networkTopologyBuffer) > 0 {
+		if err := s.createNetworkTopology(s.networkTopologyBuffer...); err != nil {
+			return err
+		}
+
+		// Keep allocated memory.
+		s.networkTopologyBuffer = s.networkTopologyBuffer[:0]
+	}
+
+	fileInfos, err := s.networkTopologyBackups()
+	if err != nil {
+		return err
+	}
+
+	return s.syncFiles(fileInfos)
+}
+
+// syncFiles commits the files to stable storage.
+func (s *storage) syncFiles(fileInfos []fs.FileInfo) error {
+	for _, fileInfo := range fileInfos {
+		file, err := os.OpenFile(filepath.Join(s.baseDir, fileInfo.Name()), os.O_RDWR, 0600)
+		if err != nil {
+			return err
+		}
+
+		if err := file.Sync(); err != nil {
+			file.Close()
+			return err
+		}
+
+		if err := file.Close(); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -386,11 +861,27 @@ func (s *storage) createDownload(downloads ...Download) error {
 		return err
 	}
 	defer file.Close()
+	defer s.updateSizeMetrics(DownloadFilePrefix, s.downloadFilename, s.downloadBackups)
+
+	var buf bytes.Buffer
+	if err := gocsv.MarshalWithoutHeaders(downloads, &buf); err != nil {
+		return err
+	}
 
-	if err := gocsv.MarshalWithoutHeaders(downloads, file); err != nil {
+	if err := s.writeSegment(file, buf.Bytes()); err != nil {
 		return err
 	}
 
+	// Update download size and count.
+	s.downloadSize += int64(buf.Len())
+	s.downloadCount += int64(len(downloads))
+	s.downloadRecords.add(s.downloadFilename, int64(len(downloads)), int64(buf.Len()))
+
+	// The written downloads are not rolled back if eviction fails, it is retried in the next writing.
+	if err := s.evictDownload(); err != nil {
+		logger.Errorf("evict downloads failed: %s", err.Error())
+	}
+
 	return nil
 }
 
@@ -401,14 +892,128 @@ func (s *storage) createNetworkTopology(networkTopologies ...NetworkTopology) er
 		return err
 	}
 	defer file.Close()
+	defer s.updateSizeMetrics(NetworkTopologyFilePrefix, s.networkTopologyFilename, s.networkTopologyBackups)
+
+	var buf bytes.Buffer
+	if err := gocsv.MarshalWithoutHeaders(networkTopologies, &buf); err != nil {
+		return err
+	}
 
-	if err := gocsv.MarshalWithoutHeaders(networkTopologies, file); err != nil {
+	if err := s.writeSegment(file, buf.Bytes()); err != nil {
 		return err
 	}
 
+	// Update network topology size and count.
+	s.networkTopologySize += int64(buf.Len())
+	s.networkTopologyCount += int64(len(networkTopologies))
+	s.networkTopologyRecords.add(s.networkTopologyFilename, int64(len(networkTopologies)), int64(buf.Len()))
+
+	// The written network topologies are not rolled back if eviction fails, it is retried in the next writing.
+	if err := s.evictNetworkTopology(); err != nil {
+		logger.Errorf("evict network topologies failed: %s", err.Error())
+	}
+
 	return nil
 }
 
+// writeSegment writes the records into file as a segment, which is encrypted if encryption
+// is enabled, and appends the checksum of segment into the manifest of file.
+func (s *storage) writeSegment(file *os.File, plaintext []byte) error {
+	fileInfo, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	segment := plaintext
+	if s.encryptor != nil {
+		if segment, err = s.encryptor.encrypt(plaintext); err != nil {
+			return err
+		}
+	}
+
+	if _, err := file.Write(segment); err != nil {
+		return err
+	}
+
+	return s.appendManifest(file.Name(), fileInfo.Size(), segment)
+}
+
+// openFiles opens the files for reading records, the evicted records of the oldest file are skipped.
+// It also returns the size of the skipped records.
+func (s *storage) openFiles(fileInfos []fs.FileInfo, evicted int64) ([]io.ReadCloser, int64, error) {
+	var evictedSize int64
+	readClosers := make([]io.ReadCloser, 0, len(fileInfos))
+	for i, fileInfo := range fileInfos {
+		file, err := os.Open(filepath.Join(s.baseDir, fileInfo.Name()))
+		if err != nil {
+			pkgio.MultiReadCloser(readClosers...).Close()
+			return nil, 0, err
+		}
+
+		readCloser := s.newReadCloser(file)
+		if i == 0 && evicted > 0 {
+			var reader io.Reader
+			reader, evictedSize, err = skipRecords(readCloser, evicted)
+			if err != nil {
+				readCloser.Close()
+				pkgio.MultiReadCloser(readClosers...).Close()
+				return nil, 0, err
+			}
+
+			readCloser = struct {
+				io.Reader
+				io.Closer
+			}{reader, file}
+		}
+
+		readClosers = append(readClosers, readCloser)
+	}
+
+	return readClosers, evictedSize, nil
+}
+
+// newReader returns a reader of records in file, which decrypts the records if encryption is enabled.
+func (s *storage) newReader(file *os.File) io.Reader {
+	if s.encryptor == nil {
+		return file
+	}
+
+	return s.encryptor.newReader(file)
+}
+
+// newReadCloser returns a read closer of records in file, which decrypts the records if encryption is enabled.
+func (s *storage) newReadCloser(file *os.File) io.ReadCloser {
+	if s.encryptor == nil {
+		return file
+	}
+
+	return struct {
+		io.Reader
+		io.Closer
+	}{s.encryptor.newReader(file), file}
+}
+
+// openReverseFile opens the file for reading records in reverse, the encrypted
+// file is decrypted into a temporary file which supports positioned reads.
+func (s *storage) openReverseFile(name string) (reverseFile, error) {
+	file, err := os.Open(name)
+	if err != nil {
+		return reverseFile{}, err
+	}
+
+	if s.encryptor == nil {
+		return reverseFile{file: file}, nil
+	}
+	defer file.Close()
+
+	plaintext, _, err := spool(s.newReader(file))
+	if err != nil {
+		return reverseFile{}, err
+	}
+
+	return reverseFile{file: plaintext, temporary: true}, nil
+}
+
 // openDownloadFile opens the download file and removes download files that exceed the total size.
 func (s *storage) openDownloadFile() (*os.File, error) {
 	fileInfo, err := os.Stat(s.downloadFilename)
@@ -417,7 +1022,7 @@ func (s *storage) openDownloadFile() (*os.File, error) {
 	}
 
 	if s.maxSize <= fileInfo.Size() {
-		if err := os.Rename(s.downloadFilename, s.downloadBackupFilename()); err != nil {
+		if err := s.rotate(s.downloadFilename, s.downloadBackupFilename(), s.downloadRecords); err != nil {
 			return nil, err
 		}
 	}
@@ -428,8 +1033,7 @@ func (s *storage) openDownloadFile() (*os.File, error) {
 	}
 
 	if s.maxBackups < len(fileInfos)+1 {
-		filename := filepath.Join(s.baseDir, fileInfos[0].Name())
-		if err := os.Remove(filename); err != nil {
+		if err := s.dropFile(s.downloadFilename, filepath.Join(s.baseDir, fileInfos[0].Name()), s.downloadRecords); err != nil {
 			return nil, err
 		}
 	}
@@ -450,7 +1054,7 @@ func (s *storage) openNetworkTopologyFile() (*os.File, error) {
 	}
 
 	if s.maxSize <= fileInfo.Size() {
-		if err := os.Rename(s.networkTopologyFilename, s.networkTopologyBackupFilename()); err != nil {
+		if err := s.rotate(s.networkTopologyFilename, s.networkTopologyBackupFilename(), s.networkTopologyRecords); err != nil {
 			return nil, err
 		}
 	}
@@ -461,8 +1065,7 @@ func (s *storage) openNetworkTopologyFile() (*os.File, error) {
 	}
 
 	if s.maxBackups < len(fileInfos)+1 {
-		filename := filepath.Join(s.baseDir, fileInfos[0].Name())
-		if err := os.Remove(filename); err != nil {
+		if err := s.dropFile(s.networkTopologyFilename, filepath.Join(s.baseDir, fileInfos[0].Name()), s.networkTopologyRecords); err != nil {
 			return nil, err
 		}
 	}
@@ -475,6 +1078,44 @@ func (s *storage) openNetworkTopologyFile() (*os.File, error) {
 	return file, nil
 }
 
+// rotate renames the current file of filename into the backup file, the current file is
+// created again when writing.
+func (s *storage) rotate(filename, backupFilename string, files *recordFiles) error {
+	if err := os.Rename(filename, backupFilename); err != nil {
+		return err
+	}
+
+	if err := s.renameManifest(filename, backupFilename); err != nil {
+		return err
+	}
+
+	files.rename(filename, backupFilename)
+	files.stats[filename] = fileStats{}
+	return nil
+}
+
+// updateSizeMetrics updates the size metrics of the storage files with the given prefix.
+func (s *storage) updateSizeMetrics(prefix, filename string, backups func() ([]fs.FileInfo, error)) {
+	var size int64
+	if fileInfo, err := os.Stat(filename); err == nil {
+		size = fileInfo.Size()
+	}
+	metrics.StorageFileSizeGauge.WithLabelValues(prefix).Set(float64(size))
+
+	// Backups returns error if files do not exist, e.g. storage is cleared.
+	fileInfos, err := backups()
+	if err != nil {
+		metrics.StorageTotalSizeGauge.WithLabelValues(prefix).Set(float64(size))
+		return
+	}
+
+	var totalSize int64
+	for _, fileInfo := range fileInfos {
+		totalSize += fileInfo.Size()
+	}
+	metrics.StorageTotalSizeGauge.WithLabelValues(prefix).Set(float64(totalSize))
+}
+
 // downloadBackupFilename generates download file name of backup files.
 func (s *storage) downloadBackupFilename() string {
 	timestamp := time.Now().Format(backupTimeFormat)
@@ -506,13 +1147,23 @@ func (s *storage) downloadBackups() ([]fs.FileInfo, error) {
 		return nil, errors.New("download files backup does not exist")
 	}
 
-	sort.Slice(backups, func(i, j int) bool {
-		return backups[i].ModTime().Before(backups[j].ModTime())
-	})
+	sortBackups(backups)
 
 	return backups, nil
 }
 
+// sortBackups sorts the backups by modification time, the backups modified at the same time are
+// sorted by name, i.e. the timestamp of rotation, and the current file is the last.
+func sortBackups(backups []fs.FileInfo) {
+	sort.SliceStable(backups, func(i, j int) bool {
+		if !backups[i].ModTime().Equal(backups[j].ModTime()) {
+			return backups[i].ModTime().Before(backups[j].ModTime())
+		}
+
+		return backups[i].Name() < backups[j].Name()
+	})
+}
+
 // networkTopologyBackups returns network topology backup file information.
 func (s *storage) networkTopologyBackups() ([]fs.FileInfo, error) {
 	fileInfos, err := ioutil.ReadDir(s.baseDir)
@@ -532,9 +1183,7 @@ func (s *storage) networkTopologyBackups() ([]fs.FileInfo, error) {
 		return nil, errors.New("network topology files backup does not exist")
 	}
 
-	sort.Slice(backups, func(i, j int) bool {
-		return backups[i].ModTime().Before(backups[j].ModTime())
-	})
+	sortBackups(backups)
 
 	return backups, nil
 }
@@ -17,6 +17,10 @@
 package storage
 
 import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
 	"io/ioutil"
@@ -24,13 +28,16 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gocarina/gocsv"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
 
 	"d7y.io/dragonfly/v2/scheduler/config"
+	"d7y.io/dragonfly/v2/scheduler/metrics"
 	"d7y.io/dragonfly/v2/scheduler/resource"
 )
 
@@ -579,6 +586,72 @@ func TestStorage_ListDownload(t *testing.T) {
 	}
 }
 
+func TestStorage_ListDownloadByTaskID(t *testing.T) {
+	tests := []struct {
+		name    string
+		taskIDs []string
+		taskID  string
+		expect  func(t *testing.T, downloads []Download, err error)
+	}{
+		{
+			name:   "empty storage",
+			taskID: "foo",
+			expect: func(t *testing.T, downloads []Download, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Empty(downloads)
+			},
+		},
+		{
+			name:    "list downloads of task",
+			taskIDs: []string{"foo", "bar", "foo"},
+			taskID:  "foo",
+			expect: func(t *testing.T, downloads []Download, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Len(downloads, 2)
+				assert.Equal("0", downloads[0].ID)
+				assert.Equal("2", downloads[1].ID)
+			},
+		},
+		{
+			name:    "task has no download",
+			taskIDs: []string{"foo", "bar"},
+			taskID:  "baz",
+			expect: func(t *testing.T, downloads []Download, err error) {
+				assert := assert.New(t)
+				assert.NoError(err)
+				assert.Empty(downloads)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := New(t.TempDir(), config.DefaultStorageMaxSize, config.DefaultStorageMaxBackups, config.DefaultStorageBufferSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i, taskID := range tc.taskIDs {
+				download := mockDownload
+				download.ID = fmt.Sprint(i)
+				download.Task.ID = taskID
+				if err := s.CreateDownload(download); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := s.Sync(); err != nil {
+				t.Fatal(err)
+			}
+
+			downloads, err := s.ListDownloadByTaskID(tc.taskID)
+			tc.expect(t, downloads, err)
+		})
+	}
+}
+
 func TestStorage_ListNetworkTopology(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -823,6 +896,205 @@ func TestStorage_OpenDownload(t *testing.T) {
 	}
 }
 
+func TestStorage_OpenDownloadReverse(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []Option
+	}{
+		{
+			name: "open storage in reverse",
+		},
+		{
+			name:    "open encrypted storage in reverse",
+			options: []Option{WithEncryption([]EncryptionKey{mockEncryptionKey}, mockEncryptionKey.ID)},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+			baseDir := t.TempDir()
+			s, err := New(baseDir, config.DefaultStorageMaxSize, config.DefaultStorageMaxBackups, 2, tc.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Write downloads into a backup file and the current file, the
+			// downloads are longer than the buffer of reader.
+			download := mockDownload
+			for i := 0; i < 5; i++ {
+				download.ID = fmt.Sprint(i)
+				assert.NoError(s.CreateDownload(download))
+			}
+			assert.NoError(s.Sync())
+
+			backupFilename := filepath.Join(baseDir, "download-test.csv")
+			assert.NoError(os.Rename(s.(*storage).downloadFilename, backupFilename))
+			assert.NoError(os.Chtimes(backupFilename, time.Now().Add(-time.Hour), time.Now().Add(-time.Hour)))
+			assert.NoError(os.WriteFile(s.(*storage).downloadFilename, nil, 0600))
+			for i := 5; i < 10; i++ {
+				download.ID = fmt.Sprint(i)
+				assert.NoError(s.CreateDownload(download))
+			}
+			assert.NoError(s.Sync())
+
+			readCloser, err := s.OpenDownload()
+			assert.NoError(err)
+			var downloads []Download
+			assert.NoError(gocsv.UnmarshalWithoutHeaders(readCloser, &downloads))
+			assert.NoError(readCloser.Close())
+			assert.Len(downloads, 10)
+
+			readCloser, err = s.OpenDownloadReverse()
+			assert.NoError(err)
+			var reversed []Download
+			assert.NoError(gocsv.UnmarshalWithoutHeaders(readCloser, &reversed))
+			assert.NoError(readCloser.Close())
+			assert.Len(reversed, 10)
+
+			for i := range downloads {
+				assert.Equal(downloads[i].ID, reversed[len(reversed)-1-i].ID)
+			}
+		})
+	}
+}
+
+func TestStorage_MaxRecords(t *testing.T) {
+	const maxRecords = 5
+
+	tests := []struct {
+		name       string
+		bufferSize int
+		backup     bool
+		options    []Option
+	}{
+		{
+			name: "drop oldest downloads on append",
+		},
+		{
+			name:       "drop oldest buffered downloads on sync",
+			bufferSize: 3,
+		},
+		{
+			name:   "drop oldest downloads across backup file",
+			backup: true,
+		},
+		{
+			name:    "drop oldest encrypted downloads across backup file",
+			backup:  true,
+			options: []Option{WithEncryption([]EncryptionKey{mockEncryptionKey}, mockEncryptionKey.ID)},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+			baseDir := t.TempDir()
+			s, err := New(baseDir, config.DefaultStorageMaxSize, config.DefaultStorageMaxBackups, tc.bufferSize, append(tc.options, WithMaxRecords(maxRecords))...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			download := mockDownload
+			for i := 0; i < 2*maxRecords; i++ {
+				download.ID = fmt.Sprint(i)
+				assert.NoError(s.CreateDownload(download))
+
+				// Move the first downloads into an older backup file.
+				if tc.backup && i == 2 {
+					backupFilename := filepath.Join(baseDir, "download-test.csv")
+					assert.NoError(s.(*storage).rotate(s.(*storage).downloadFilename, backupFilename, s.(*storage).downloadRecords))
+					assert.NoError(os.Chtimes(backupFilename, time.Now().Add(-time.Hour), time.Now().Add(-time.Hour)))
+					assert.NoError(os.WriteFile(s.(*storage).downloadFilename, nil, 0600))
+				}
+			}
+			assert.NoError(s.Sync())
+			assert.Equal(int64(maxRecords), s.DownloadCount())
+
+			readCloser, err := s.OpenDownload()
+			assert.NoError(err)
+			var downloads []Download
+			assert.NoError(gocsv.UnmarshalWithoutHeaders(readCloser, &downloads))
+			assert.NoError(readCloser.Close())
+			assert.Len(downloads, maxRecords)
+			for i, download := range downloads {
+				assert.Equal(fmt.Sprint(maxRecords+i), download.ID)
+			}
+
+			readCloser, err = s.OpenDownloadReverse()
+			assert.NoError(err)
+			var reversed []Download
+			assert.NoError(gocsv.UnmarshalWithoutHeaders(readCloser, &reversed))
+			assert.NoError(readCloser.Close())
+			assert.Len(reversed, maxRecords)
+			for i, download := range reversed {
+				assert.Equal(fmt.Sprint(2*maxRecords-1-i), download.ID)
+			}
+
+			// The manifests of files are consistent with the files.
+			assert.NoError(s.Verify())
+		})
+	}
+}
+
+func TestStorage_EvictFiles(t *testing.T) {
+	assert := assert.New(t)
+	baseDir := t.TempDir()
+	s, err := New(baseDir, config.DefaultStorageMaxSize, config.DefaultStorageMaxBackups, 0, WithMaxRecords(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	download := mockDownload
+	create := func(ids ...int) {
+		for _, id := range ids {
+			download.ID = fmt.Sprint(id)
+			assert.NoError(s.CreateDownload(download))
+		}
+	}
+
+	list := func() []string {
+		downloads, err := s.ListDownload()
+		assert.NoError(err)
+
+		var ids []string
+		for _, download := range downloads {
+			ids = append(ids, download.ID)
+		}
+		return ids
+	}
+
+	// Move the first downloads into an older backup file.
+	create(0, 1, 2)
+	backupFilename := filepath.Join(baseDir, "download-test.csv")
+	assert.NoError(s.(*storage).rotate(s.(*storage).downloadFilename, backupFilename, s.(*storage).downloadRecords))
+	assert.NoError(os.Chtimes(backupFilename, time.Now().Add(-time.Hour), time.Now().Add(-time.Hour)))
+	assert.NoError(os.WriteFile(s.(*storage).downloadFilename, nil, 0600))
+	backup, err := os.Stat(backupFilename)
+	assert.NoError(err)
+
+	// The evicted downloads are skipped, the backup file is not rewritten.
+	create(3, 4)
+	assert.Equal([]string{"2", "3", "4"}, list())
+	fileInfo, err := os.Stat(backupFilename)
+	assert.NoError(err)
+	assert.Equal(backup.Size(), fileInfo.Size())
+	assert.Equal(int64(2), s.(*storage).downloadRecords.evicted)
+
+	// The backup file is dropped once all its downloads are evicted.
+	create(5)
+	assert.Equal([]string{"3", "4", "5"}, list())
+	_, err = os.Stat(backupFilename)
+	assert.ErrorIs(err, os.ErrNotExist)
+	assert.Equal(int64(3), s.(*storage).downloadRecords.records)
+	assert.Equal(int64(0), s.(*storage).downloadRecords.evicted)
+
+	// The evicted downloads of the current file are skipped too.
+	create(6)
+	assert.Equal([]string{"4", "5", "6"}, list())
+	assert.NoError(s.Verify())
+}
+
 func TestStorage_OpenNetworkTopology(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -1053,6 +1325,251 @@ func TestStorage_ClearNetworkTopology(t *testing.T) {
 	}
 }
 
+func TestStorage_ExportNetworkTopologyJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   func(t *testing.T, s Storage)
+		expect func(t *testing.T, s Storage)
+	}{
+		{
+			name: "export network topologies",
+			mock: func(t *testing.T, s Storage) {
+				networkTopology := NetworkTopology{
+					ID:   "6",
+					Host: Host{ID: "1", Hostname: "foo", IP: "127.0.0.1"},
+					DestHosts: append(make([]DestHost, 9), DestHost{
+						Host:   Host{ID: "2", Hostname: "bar", IP: "127.0.0.2"},
+						Probes: Probes{AverageRTT: 10, CreatedAt: 1, UpdatedAt: 2},
+					}),
+				}
+
+				for i := 0; i < 2; i++ {
+					if err := s.CreateNetworkTopology(networkTopology); err != nil {
+						t.Fatal(err)
+					}
+				}
+			},
+			expect: func(t *testing.T, s Storage) {
+				assert := assert.New(t)
+				var buf bytes.Buffer
+				assert.NoError(s.ExportNetworkTopologyJSON(&buf))
+
+				edge := `{"id":"6","srcHostID":"1","srcHostname":"foo","srcIP":"127.0.0.1","destHostID":"2",` +
+					`"destHostname":"bar","destIP":"127.0.0.2","averageRTT":10,"createdAt":1,"updatedAt":2}`
+				assert.JSONEq("["+edge+","+edge+"]", buf.String())
+			},
+		},
+		{
+			name: "export empty network topologies",
+			mock: func(t *testing.T, s Storage) {},
+			expect: func(t *testing.T, s Storage) {
+				assert := assert.New(t)
+				var buf bytes.Buffer
+				assert.NoError(s.ExportNetworkTopologyJSON(&buf))
+				assert.Equal("[]", buf.String())
+			},
+		},
+		{
+			name: "write failed",
+			mock: func(t *testing.T, s Storage) {
+				if err := s.CreateNetworkTopology(mockNetworkTopology); err != nil {
+					t.Fatal(err)
+				}
+			},
+			expect: func(t *testing.T, s Storage) {
+				assert := assert.New(t)
+				assert.Error(s.ExportNetworkTopologyJSON(errWriter{}))
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := New(t.TempDir(), config.DefaultStorageMaxSize, config.DefaultStorageMaxBackups, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tc.mock(t, s)
+			tc.expect(t, s)
+
+			// MemoryStorage exports the same JSON.
+			m := NewMemory()
+			tc.mock(t, m)
+			tc.expect(t, m)
+		})
+	}
+}
+
+func TestStorage_Archive(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []ArchiveOption
+	}{
+		{
+			name: "export and import tar archive",
+		},
+		{
+			name:    "export and import gzip archive",
+			options: []ArchiveOption{WithArchiveGzip()},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+			s, err := New(t.TempDir(), config.DefaultStorageMaxSize, config.DefaultStorageMaxBackups, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for i := 0; i < 3; i++ {
+				assert.NoError(s.CreateDownload(mockDownload))
+			}
+			assert.NoError(s.CreateNetworkTopology(mockNetworkTopology))
+
+			var buf bytes.Buffer
+			assert.NoError(s.ExportArchive(&buf, tc.options...))
+
+			// Import into a fresh storage.
+			fresh, err := New(t.TempDir(), config.DefaultStorageMaxSize, config.DefaultStorageMaxBackups, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.NoError(fresh.ImportArchive(bytes.NewReader(buf.Bytes())))
+			assert.Equal(int64(3), fresh.DownloadCount())
+			assert.Equal(int64(1), fresh.NetworkTopologyCount())
+
+			downloads, err := fresh.ListDownload()
+			assert.NoError(err)
+			assert.Len(downloads, 3)
+			assert.EqualValues(mockDownload.ID, downloads[0].ID)
+
+			networkTopologies, err := fresh.ListNetworkTopology()
+			assert.NoError(err)
+			assert.Len(networkTopologies, 1)
+
+			// Import into memory storage.
+			m := NewMemory()
+			assert.NoError(m.ImportArchive(bytes.NewReader(buf.Bytes())))
+			assert.Equal(int64(3), m.DownloadCount())
+			assert.Equal(int64(1), m.NetworkTopologyCount())
+		})
+	}
+}
+
+func TestStorage_ImportArchive(t *testing.T) {
+	tests := []struct {
+		name   string
+		mock   func(t *testing.T) []byte
+		expect func(t *testing.T, err error)
+	}{
+		{
+			name: "manifest is not the first entry",
+			mock: func(t *testing.T) []byte {
+				var buf bytes.Buffer
+				tw := tar.NewWriter(&buf)
+				if err := writeArchiveEntry(tw, archiveDownloadName, bytes.NewReader(nil), 0); err != nil {
+					t.Fatal(err)
+				}
+				tw.Close()
+				return buf.Bytes()
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.ErrorContains(err, "manifest must be the first entry")
+			},
+		},
+		{
+			name: "unsupported schema version",
+			mock: func(t *testing.T) []byte {
+				var buf bytes.Buffer
+				tw := tar.NewWriter(&buf)
+				manifest := []byte(`{"schemaVersion":2}`)
+				if err := writeArchiveEntry(tw, ArchiveManifestName, bytes.NewReader(manifest), int64(len(manifest))); err != nil {
+					t.Fatal(err)
+				}
+				tw.Close()
+				return buf.Bytes()
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "unsupported archive schema version 2")
+			},
+		},
+		{
+			name: "count mismatches manifest",
+			mock: func(t *testing.T) []byte {
+				var buf bytes.Buffer
+				if err := writeArchive(&buf, strings.NewReader(""), strings.NewReader("")); err != nil {
+					t.Fatal(err)
+				}
+
+				// Patch the manifest, the size of entry is unchanged.
+				return bytes.Replace(buf.Bytes(), []byte(`"downloadCount":0`), []byte(`"downloadCount":1`), 1)
+			},
+			expect: func(t *testing.T, err error) {
+				assert := assert.New(t)
+				assert.EqualError(err, "imported 0 downloads, but manifest has 1")
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := New(t.TempDir(), config.DefaultStorageMaxSize, config.DefaultStorageMaxBackups, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tc.expect(t, s.ImportArchive(bytes.NewReader(tc.mock(t))))
+		})
+	}
+}
+
+func TestStorage_Size(t *testing.T) {
+	assert := assert.New(t)
+	s, err := New(t.TempDir(), config.DefaultStorageMaxSize, config.DefaultStorageMaxBackups, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := gocsv.MarshalWithoutHeaders([]Download{mockDownload}, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// Buffered downloads are not counted.
+	assert.NoError(s.CreateDownload(mockDownload))
+	assert.Equal(int64(0), s.DownloadSize())
+	assert.NoError(s.Sync())
+	assert.Equal(int64(buf.Len()), s.DownloadSize())
+
+	buf.Reset()
+	if err := gocsv.MarshalWithoutHeaders([]NetworkTopology{mockNetworkTopology}, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NoError(s.CreateNetworkTopology(mockNetworkTopology))
+	assert.NoError(s.Sync())
+	assert.Equal(int64(buf.Len()), s.NetworkTopologySize())
+
+	// Size is reset when storage is cleared.
+	assert.NoError(s.ClearDownload())
+	assert.Equal(int64(0), s.DownloadSize())
+	assert.NoError(s.ClearNetworkTopology())
+	assert.Equal(int64(0), s.NetworkTopologySize())
+}
+
+// errWriter is the writer always returns error.
+type errWriter struct{}
+
+// Write returns error.
+func (errWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("foo")
+}
+
 func TestStorage_createDownload(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -1460,3 +1977,150 @@ func TestStorage_networkTopologyBackups(t *testing.T) {
 		})
 	}
 }
+
+func TestStorage_updateSizeMetrics(t *testing.T) {
+	assert := assert.New(t)
+	baseDir := t.TempDir()
+	s, err := New(baseDir, config.DefaultStorageMaxSize, config.DefaultStorageMaxBackups, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a backup file of downloads.
+	if err := os.WriteFile(filepath.Join(baseDir, "download-test.csv"), []byte("foo\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.CreateDownload(mockDownload); err != nil {
+		t.Fatal(err)
+	}
+
+	fileInfo, err := os.Stat(filepath.Join(baseDir, "download.csv"))
+	assert.NoError(err)
+	assert.Equal(float64(fileInfo.Size()), testutil.ToFloat64(metrics.StorageFileSizeGauge.WithLabelValues(DownloadFilePrefix)))
+	assert.Equal(float64(fileInfo.Size()+4), testutil.ToFloat64(metrics.StorageTotalSizeGauge.WithLabelValues(DownloadFilePrefix)))
+
+	if err := s.CreateDownload(mockDownload); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(float64(2*fileInfo.Size()), testutil.ToFloat64(metrics.StorageFileSizeGauge.WithLabelValues(DownloadFilePrefix)))
+
+	if err := s.CreateNetworkTopology(mockNetworkTopology); err != nil {
+		t.Fatal(err)
+	}
+
+	fileInfo, err = os.Stat(filepath.Join(baseDir, "networktopology.csv"))
+	assert.NoError(err)
+	assert.Equal(float64(fileInfo.Size()), testutil.ToFloat64(metrics.StorageFileSizeGauge.WithLabelValues(NetworkTopologyFilePrefix)))
+	assert.Equal(float64(fileInfo.Size()), testutil.ToFloat64(metrics.StorageTotalSizeGauge.WithLabelValues(NetworkTopologyFilePrefix)))
+
+	if err := s.ClearDownload(); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(float64(0), testutil.ToFloat64(metrics.StorageFileSizeGauge.WithLabelValues(DownloadFilePrefix)))
+	assert.Equal(float64(0), testutil.ToFloat64(metrics.StorageTotalSizeGauge.WithLabelValues(DownloadFilePrefix)))
+}
+
+func TestStorage_Sync(t *testing.T) {
+	assert := assert.New(t)
+	baseDir := t.TempDir()
+	s, err := New(baseDir, config.DefaultStorageMaxSize, config.DefaultStorageMaxBackups, config.DefaultStorageBufferSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.CreateDownload(mockDownload); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.CreateNetworkTopology(mockNetworkTopology); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = s.ListDownload()
+	assert.Error(err)
+
+	assert.NoError(s.Sync())
+	assert.Equal(0, len(s.(*storage).downloadBuffer))
+	assert.Equal(int64(1), s.DownloadCount())
+	assert.Equal(0, len(s.(*storage).networkTopologyBuffer))
+	assert.Equal(int64(1), s.NetworkTopologyCount())
+
+	downloads, err := s.ListDownload()
+	assert.NoError(err)
+	assert.Equal(1, len(downloads))
+	assert.Equal(mockDownload.ID, downloads[0].ID)
+
+	networkTopologies, err := s.ListNetworkTopology()
+	assert.NoError(err)
+	assert.Equal(1, len(networkTopologies))
+
+	s.(*storage).baseDir = "foo"
+	assert.Error(s.Sync())
+}
+
+func TestStorage_Verify(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []Option
+	}{
+		{
+			name: "verify storage",
+		},
+		{
+			name:    "verify encrypted storage",
+			options: []Option{WithEncryption([]EncryptionKey{mockEncryptionKey}, mockEncryptionKey.ID)},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := assert.New(t)
+			baseDir := t.TempDir()
+			s, err := New(baseDir, config.DefaultStorageMaxSize, config.DefaultStorageMaxBackups, 1, tc.options...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Each download is written as a segment.
+			for i := 0; i < 3; i++ {
+				assert.NoError(s.CreateDownload(Download{ID: fmt.Sprint(i)}))
+			}
+			assert.NoError(s.CreateNetworkTopology(mockNetworkTopology))
+			assert.NoError(s.Sync())
+			assert.NoError(s.Verify())
+
+			data, err := os.ReadFile(s.(*storage).manifestFilename(s.(*storage).downloadFilename))
+			assert.NoError(err)
+			lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+			assert.Len(lines, 3)
+
+			var checksum SegmentChecksum
+			assert.NoError(json.Unmarshal([]byte(lines[1]), &checksum))
+
+			// Corrupt the second segment.
+			file, err := os.OpenFile(s.(*storage).downloadFilename, os.O_RDWR, 0600)
+			assert.NoError(err)
+			b := make([]byte, 1)
+			_, err = file.ReadAt(b, checksum.Offset)
+			assert.NoError(err)
+			_, err = file.WriteAt([]byte{b[0] ^ 0xff}, checksum.Offset)
+			assert.NoError(err)
+			assert.NoError(file.Close())
+
+			err = s.Verify()
+			var corruptionErr *CorruptionError
+			assert.ErrorAs(err, &corruptionErr)
+			assert.Equal([]CorruptSegment{{
+				Filename: filepath.Base(s.(*storage).downloadFilename),
+				Offset:   checksum.Offset,
+				Length:   checksum.Length,
+			}}, corruptionErr.Segments)
+
+			// Manifests are removed with files.
+			assert.NoError(s.ClearDownload())
+			_, err = os.Stat(s.(*storage).manifestFilename(s.(*storage).downloadFilename))
+			assert.True(os.IsNotExist(err))
+		})
+	}
+}